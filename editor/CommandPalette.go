@@ -0,0 +1,301 @@
+package editor
+
+import (
+	"fmt"
+
+	"github.com/inkyblackness/shocked-client/editor/actions"
+	"github.com/inkyblackness/shocked-client/editor/modes"
+	"github.com/inkyblackness/shocked-client/env"
+	"github.com/inkyblackness/shocked-client/env/keys"
+	"github.com/inkyblackness/shocked-client/graphics"
+	"github.com/inkyblackness/shocked-client/graphics/controls"
+	"github.com/inkyblackness/shocked-client/ui"
+	"github.com/inkyblackness/shocked-client/ui/events"
+)
+
+const commandPaletteEntryCount = 10
+
+// commandPalette is an overlay listing all available actions.
+// Typing narrows the list by fuzzy matching, Enter performs the highlighted action.
+type commandPalette struct {
+	context modes.Context
+	keymap  *actions.Keymap
+
+	area        *ui.Area
+	entriesTop  ui.Anchor
+	entryHeight float32
+	queryLabel  *controls.Label
+	entryLabels []*controls.Label
+
+	query            string
+	entries          []*actions.Action
+	startIndex       int
+	highlightedIndex int
+}
+
+func newCommandPalette(context modes.Context, keymap *actions.Keymap, parent *ui.Area) *commandPalette {
+	palette := &commandPalette{context: context, keymap: keymap}
+	scaled := func(value float32) float32 {
+		return value * context.ControlFactory().Scale()
+	}
+	palette.entryHeight = scaled(25)
+
+	{
+		top := ui.NewOffsetAnchor(parent.Top(), scaled(60))
+		builder := ui.NewAreaBuilder()
+		builder.SetParent(parent)
+		builder.SetLeft(ui.NewRelativeAnchor(parent.Left(), parent.Right(), 0.25))
+		builder.SetTop(top)
+		builder.SetRight(ui.NewRelativeAnchor(parent.Left(), parent.Right(), 0.75))
+		builder.SetBottom(ui.NewOffsetAnchor(top, palette.entryHeight*(commandPaletteEntryCount+1)+scaled(8)))
+		builder.SetVisible(false)
+		builder.OnRender(palette.onRender)
+		builder.OnEvent(events.MouseMoveEventType, palette.onMouseMove)
+		builder.OnEvent(events.MouseButtonDownEventType, palette.onMouseDown)
+		builder.OnEvent(events.MouseButtonUpEventType, palette.onMouseUp)
+		builder.OnEvent(events.MouseButtonClickedEventType, palette.onMouseClicked)
+		builder.OnEvent(events.MouseScrollEventType, palette.onMouseScroll)
+		builder.OnEvent(events.KeyEventType, palette.onKey)
+		builder.OnEvent(events.CharEventType, palette.onChar)
+		builder.OnEvent(events.FocusLostEventType, palette.onFocusLost)
+		palette.area = builder.Build()
+	}
+	{
+		builder := context.ControlFactory().ForLabel()
+		builder.SetParent(palette.area)
+		builder.SetLeft(ui.NewOffsetAnchor(palette.area.Left(), scaled(6)))
+		builder.SetRight(ui.NewOffsetAnchor(palette.area.Right(), scaled(-6)))
+		builder.AlignedHorizontallyBy(controls.LeftAligner)
+
+		lastBottom := ui.NewOffsetAnchor(palette.area.Top(), scaled(2))
+		nextBottom := ui.NewOffsetAnchor(lastBottom, palette.entryHeight)
+		builder.SetTop(lastBottom)
+		builder.SetBottom(nextBottom)
+		palette.queryLabel = builder.Build()
+
+		lastBottom = ui.NewOffsetAnchor(nextBottom, scaled(4))
+		palette.entriesTop = lastBottom
+		palette.entryLabels = make([]*controls.Label, commandPaletteEntryCount)
+		for index := range palette.entryLabels {
+			nextBottom = ui.NewOffsetAnchor(lastBottom, palette.entryHeight)
+			builder.SetTop(lastBottom)
+			builder.SetBottom(nextBottom)
+			palette.entryLabels[index] = builder.Build()
+			lastBottom = nextBottom
+		}
+	}
+
+	return palette
+}
+
+// IsVisible returns true if the palette is currently shown.
+func (palette *commandPalette) IsVisible() bool {
+	return palette.area.IsVisible()
+}
+
+// Show opens the palette with an empty query.
+func (palette *commandPalette) Show() {
+	palette.area.SetVisible(true)
+	palette.area.RequestFocus()
+	palette.setQuery("")
+}
+
+// Hide closes the palette and releases the keyboard focus.
+func (palette *commandPalette) Hide() {
+	if palette.area.IsVisible() {
+		palette.area.SetVisible(false)
+	}
+	palette.area.ReleaseFocus()
+}
+
+func (palette *commandPalette) setQuery(query string) {
+	palette.query = query
+	palette.entries = nil
+	for _, action := range palette.context.Actions().Search(query) {
+		if action.Name != commandPaletteActionName {
+			palette.entries = append(palette.entries, action)
+		}
+	}
+	palette.startIndex = 0
+	palette.highlightedIndex = 0
+	palette.queryLabel.SetText("> " + query)
+	palette.updateEntryLabels()
+}
+
+func (palette *commandPalette) entryText(action *actions.Action) string {
+	text := action.Title
+	if text == "" {
+		text = action.Name
+	}
+	if shortcut := palette.keymap.ShortcutText(action.Name); shortcut != "" {
+		text = fmt.Sprintf("%v (%v)", text, shortcut)
+	}
+	return text
+}
+
+func (palette *commandPalette) updateEntryLabels() {
+	for listIndex, label := range palette.entryLabels {
+		entryIndex := palette.startIndex + listIndex
+		if entryIndex < len(palette.entries) {
+			label.SetText(palette.entryText(palette.entries[entryIndex]))
+		} else {
+			label.SetText("")
+		}
+	}
+}
+
+func (palette *commandPalette) moveHighlight(delta int) {
+	count := len(palette.entries)
+	if count > 0 {
+		palette.highlightedIndex += delta
+		if palette.highlightedIndex < 0 {
+			palette.highlightedIndex = 0
+		} else if palette.highlightedIndex >= count {
+			palette.highlightedIndex = count - 1
+		}
+		if palette.highlightedIndex < palette.startIndex {
+			palette.startIndex = palette.highlightedIndex
+		} else if palette.highlightedIndex >= palette.startIndex+commandPaletteEntryCount {
+			palette.startIndex = palette.highlightedIndex - commandPaletteEntryCount + 1
+		}
+		palette.updateEntryLabels()
+	}
+}
+
+// perform closes the palette and then performs the action at given index.
+// The actions themselves are responsible to route data changes through the command stack.
+func (palette *commandPalette) perform(entryIndex int) {
+	if (entryIndex >= 0) && (entryIndex < len(palette.entries)) {
+		action := palette.entries[entryIndex]
+		palette.Hide()
+		palette.context.Actions().Perform(action.Name)
+	}
+}
+
+// entryIndexAt returns the index of the entry at the given vertical position, or -1 if none.
+func (palette *commandPalette) entryIndexAt(y float32) int {
+	offset := y - palette.entriesTop.Value()
+	if offset < 0 {
+		return -1
+	}
+	listIndex := int(offset / palette.entryHeight)
+	if listIndex >= commandPaletteEntryCount {
+		return -1
+	}
+	return palette.startIndex + listIndex
+}
+
+func (palette *commandPalette) contains(event events.PositionalEvent) bool {
+	x, y := event.Position()
+	area := palette.area
+
+	return (x >= area.Left().Value()) && (x < area.Right().Value()) &&
+		(y >= area.Top().Value()) && (y < area.Bottom().Value())
+}
+
+func (palette *commandPalette) onRender(area *ui.Area) {
+	renderer := palette.context.ForGraphics().RectangleRenderer()
+	areaLeft, areaTop := area.Left().Value(), area.Top().Value()
+	areaRight, areaBottom := area.Right().Value(), area.Bottom().Value()
+	renderer.Fill(areaLeft, areaTop, areaRight, areaBottom, graphics.RGBA(0.1, 0.2, 0.1, 0.9))
+	renderer.Fill(areaLeft, areaTop, areaRight, palette.entriesTop.Value(), graphics.RGBA(0.31, 0.56, 0.34, 0.8))
+
+	listIndex := palette.highlightedIndex - palette.startIndex
+	if (palette.highlightedIndex < len(palette.entries)) && (listIndex >= 0) && (listIndex < commandPaletteEntryCount) {
+		entryTop := palette.entriesTop.Value() + palette.entryHeight*float32(listIndex)
+		renderer.Fill(areaLeft, entryTop, areaRight, entryTop+palette.entryHeight, graphics.RGBA(0.56, 0.69, 0.36, 0.5))
+	}
+}
+
+func (palette *commandPalette) onMouseMove(area *ui.Area, event events.Event) bool {
+	mouseEvent := event.(*events.MouseMoveEvent)
+	if !palette.contains(mouseEvent) {
+		return false
+	}
+	_, y := mouseEvent.Position()
+	if entryIndex := palette.entryIndexAt(y); (entryIndex >= 0) && (entryIndex < len(palette.entries)) {
+		palette.highlightedIndex = entryIndex
+	}
+	return true
+}
+
+func (palette *commandPalette) onMouseDown(area *ui.Area, event events.Event) bool {
+	mouseEvent := event.(*events.MouseButtonEvent)
+	if !palette.contains(mouseEvent) {
+		palette.Hide()
+		return false
+	}
+	return true
+}
+
+func (palette *commandPalette) onMouseUp(area *ui.Area, event events.Event) bool {
+	mouseEvent := event.(*events.MouseButtonEvent)
+	if !palette.contains(mouseEvent) {
+		return false
+	}
+	if mouseEvent.AffectedButtons() == env.MousePrimary {
+		_, y := mouseEvent.Position()
+		palette.perform(palette.entryIndexAt(y))
+	}
+	return true
+}
+
+func (palette *commandPalette) onMouseClicked(area *ui.Area, event events.Event) bool {
+	return palette.contains(event.(*events.MouseButtonEvent))
+}
+
+func (palette *commandPalette) onMouseScroll(area *ui.Area, event events.Event) bool {
+	mouseEvent := event.(*events.MouseScrollEvent)
+	if !palette.contains(mouseEvent) {
+		return false
+	}
+	_, dy := mouseEvent.Deltas()
+	maxStartIndex := len(palette.entries) - commandPaletteEntryCount
+	if (dy < 0) && (palette.startIndex > 0) {
+		palette.startIndex--
+	} else if (dy > 0) && (palette.startIndex < maxStartIndex) {
+		palette.startIndex++
+	}
+	palette.updateEntryLabels()
+	return true
+}
+
+// onKey consumes all keys while the palette is open, so that no shortcut
+// reaches the modes underneath.
+func (palette *commandPalette) onKey(area *ui.Area, event events.Event) (consumed bool) {
+	keyEvent := event.(*events.KeyEvent)
+
+	consumed = true
+	switch keys.Key(keyEvent.Key()) {
+	case keys.KeyUp:
+		palette.moveHighlight(-1)
+	case keys.KeyDown:
+		palette.moveHighlight(1)
+	case keys.KeyPageUp:
+		palette.moveHighlight(-commandPaletteEntryCount)
+	case keys.KeyPageDown:
+		palette.moveHighlight(commandPaletteEntryCount)
+	case keys.KeyEnter:
+		palette.perform(palette.highlightedIndex)
+	case keys.KeyEscape:
+		palette.Hide()
+	case keys.KeyBackspace:
+		if palette.query != "" {
+			queryRunes := []rune(palette.query)
+			palette.setQuery(string(queryRunes[:len(queryRunes)-1]))
+		}
+	}
+
+	return
+}
+
+func (palette *commandPalette) onChar(area *ui.Area, event events.Event) bool {
+	charEvent := event.(*events.CharEvent)
+	palette.setQuery(palette.query + string(charEvent.Char()))
+	return true
+}
+
+func (palette *commandPalette) onFocusLost(area *ui.Area, event events.Event) bool {
+	palette.Hide()
+	return true
+}
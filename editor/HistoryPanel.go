@@ -0,0 +1,199 @@
+package editor
+
+import (
+	"github.com/inkyblackness/shocked-client/editor/cmd"
+	"github.com/inkyblackness/shocked-client/editor/modes"
+	"github.com/inkyblackness/shocked-client/env"
+	"github.com/inkyblackness/shocked-client/graphics"
+	"github.com/inkyblackness/shocked-client/graphics/controls"
+	"github.com/inkyblackness/shocked-client/ui"
+	"github.com/inkyblackness/shocked-client/ui/events"
+)
+
+const historyPanelEntryCount = 20
+
+// commandHistory provides access to the performed commands.
+type commandHistory interface {
+	historyEntries() (undoList, redoList []cmd.Command)
+	undoSteps(steps int)
+	redoSteps(steps int)
+	onHistoryChanged(callback func())
+}
+
+// historyPanel lists the commands on the undo and redo lists in chronological order.
+// Clicking an entry undoes or redoes all commands up to it.
+type historyPanel struct {
+	context modes.Context
+	history commandHistory
+
+	area        *ui.Area
+	entriesTop  ui.Anchor
+	entryHeight float32
+	entryLabels []*controls.Label
+
+	entries      []string
+	currentIndex int
+	startIndex   int
+}
+
+func newHistoryPanel(context modes.Context, history commandHistory, parent *ui.Area, top ui.Anchor) *historyPanel {
+	panel := &historyPanel{context: context, history: history}
+	scaled := func(value float32) float32 {
+		return value * context.ControlFactory().Scale()
+	}
+	panel.entryHeight = scaled(25)
+
+	{
+		builder := ui.NewAreaBuilder()
+		builder.SetParent(parent)
+		builder.SetLeft(ui.NewOffsetAnchor(parent.Right(), scaled(-300)))
+		builder.SetTop(top)
+		builder.SetRight(parent.Right())
+		builder.SetBottom(ui.NewOffsetAnchor(top, panel.entryHeight*historyPanelEntryCount+scaled(4)))
+		builder.SetVisible(false)
+		builder.OnRender(panel.onRender)
+		builder.OnEvent(events.MouseMoveEventType, ui.SilentConsumer)
+		builder.OnEvent(events.MouseButtonDownEventType, ui.SilentConsumer)
+		builder.OnEvent(events.MouseButtonUpEventType, panel.onMouseUp)
+		builder.OnEvent(events.MouseButtonClickedEventType, ui.SilentConsumer)
+		builder.OnEvent(events.MouseScrollEventType, panel.onMouseScroll)
+		panel.area = builder.Build()
+	}
+	{
+		builder := context.ControlFactory().ForLabel()
+		builder.SetParent(panel.area)
+		builder.SetLeft(ui.NewOffsetAnchor(panel.area.Left(), scaled(6)))
+		builder.SetRight(ui.NewOffsetAnchor(panel.area.Right(), scaled(-6)))
+		builder.AlignedHorizontallyBy(controls.LeftAligner)
+
+		lastBottom := ui.NewOffsetAnchor(panel.area.Top(), scaled(2))
+		panel.entriesTop = lastBottom
+		panel.entryLabels = make([]*controls.Label, historyPanelEntryCount)
+		for index := range panel.entryLabels {
+			nextBottom := ui.NewOffsetAnchor(lastBottom, panel.entryHeight)
+			builder.SetTop(lastBottom)
+			builder.SetBottom(nextBottom)
+			panel.entryLabels[index] = builder.Build()
+			lastBottom = nextBottom
+		}
+	}
+	history.onHistoryChanged(panel.onHistoryChanged)
+	panel.onHistoryChanged()
+
+	return panel
+}
+
+// IsVisible returns true if the panel is currently shown.
+func (panel *historyPanel) IsVisible() bool {
+	return panel.area.IsVisible()
+}
+
+// Toggle shows or hides the panel.
+func (panel *historyPanel) Toggle() {
+	panel.area.SetVisible(!panel.area.IsVisible())
+}
+
+// onHistoryChanged rebuilds the entries. The first entry represents the state before
+// any command. It is followed by all undoable commands, oldest first, and then
+// all redoable commands, next first.
+func (panel *historyPanel) onHistoryChanged() {
+	undoList, redoList := panel.history.historyEntries()
+
+	panel.entries = make([]string, 0, 1+len(undoList)+len(redoList))
+	panel.entries = append(panel.entries, "(Initial state)")
+	for index := len(undoList) - 1; index >= 0; index-- {
+		panel.entries = append(panel.entries, cmd.Describe(undoList[index]))
+	}
+	for _, command := range redoList {
+		panel.entries = append(panel.entries, cmd.Describe(command))
+	}
+	panel.currentIndex = len(undoList)
+	panel.scrollToCurrent()
+	panel.updateEntryLabels()
+}
+
+func (panel *historyPanel) scrollToCurrent() {
+	if panel.currentIndex < panel.startIndex {
+		panel.startIndex = panel.currentIndex
+	} else if panel.currentIndex >= panel.startIndex+historyPanelEntryCount {
+		panel.startIndex = panel.currentIndex - historyPanelEntryCount + 1
+	}
+	panel.limitStartIndex()
+}
+
+func (panel *historyPanel) limitStartIndex() {
+	maxStartIndex := len(panel.entries) - historyPanelEntryCount
+	if panel.startIndex > maxStartIndex {
+		panel.startIndex = maxStartIndex
+	}
+	if panel.startIndex < 0 {
+		panel.startIndex = 0
+	}
+}
+
+func (panel *historyPanel) updateEntryLabels() {
+	for listIndex, label := range panel.entryLabels {
+		entryIndex := panel.startIndex + listIndex
+		if entryIndex < len(panel.entries) {
+			label.SetText(panel.entries[entryIndex])
+		} else {
+			label.SetText("")
+		}
+	}
+}
+
+// jumpTo undoes or redoes commands until the entry at given index is the current one.
+func (panel *historyPanel) jumpTo(entryIndex int) {
+	if (entryIndex >= 0) && (entryIndex < len(panel.entries)) {
+		if entryIndex < panel.currentIndex {
+			panel.history.undoSteps(panel.currentIndex - entryIndex)
+		} else if entryIndex > panel.currentIndex {
+			panel.history.redoSteps(entryIndex - panel.currentIndex)
+		}
+	}
+}
+
+func (panel *historyPanel) onRender(area *ui.Area) {
+	renderer := panel.context.ForGraphics().RectangleRenderer()
+	areaLeft, areaTop := area.Left().Value(), area.Top().Value()
+	areaRight, areaBottom := area.Right().Value(), area.Bottom().Value()
+	renderer.Fill(areaLeft, areaTop, areaRight, areaBottom, graphics.RGBA(0.1, 0.2, 0.1, 0.9))
+
+	for listIndex := 0; listIndex < historyPanelEntryCount; listIndex++ {
+		entryIndex := panel.startIndex + listIndex
+		entryTop := panel.entriesTop.Value() + panel.entryHeight*float32(listIndex)
+		if entryIndex == panel.currentIndex {
+			renderer.Fill(areaLeft, entryTop, areaRight, entryTop+panel.entryHeight, graphics.RGBA(0.56, 0.69, 0.36, 0.5))
+		} else if (entryIndex > panel.currentIndex) && (entryIndex < len(panel.entries)) {
+			renderer.Fill(areaLeft, entryTop, areaRight, entryTop+panel.entryHeight, graphics.RGBA(0.0, 0.0, 0.0, 0.5))
+		}
+	}
+}
+
+func (panel *historyPanel) onMouseUp(area *ui.Area, event events.Event) bool {
+	mouseEvent := event.(*events.MouseButtonEvent)
+
+	if mouseEvent.AffectedButtons() == env.MousePrimary {
+		_, y := mouseEvent.Position()
+		offset := y - panel.entriesTop.Value()
+		listIndex := int(offset / panel.entryHeight)
+		if (offset >= 0) && (listIndex < historyPanelEntryCount) {
+			panel.jumpTo(panel.startIndex + listIndex)
+		}
+	}
+	return true
+}
+
+func (panel *historyPanel) onMouseScroll(area *ui.Area, event events.Event) bool {
+	mouseEvent := event.(*events.MouseScrollEvent)
+	_, dy := mouseEvent.Deltas()
+
+	if dy < 0 {
+		panel.startIndex--
+	} else if dy > 0 {
+		panel.startIndex++
+	}
+	panel.limitStartIndex()
+	panel.updateEntryLabels()
+	return true
+}
@@ -7,8 +7,10 @@ import (
 
 	mgl "github.com/go-gl/mathgl/mgl32"
 
+	"github.com/inkyblackness/shocked-client/editor/actions"
 	"github.com/inkyblackness/shocked-client/editor/cmd"
 	"github.com/inkyblackness/shocked-client/editor/model"
+	"github.com/inkyblackness/shocked-client/editor/modes"
 	"github.com/inkyblackness/shocked-client/env"
 	"github.com/inkyblackness/shocked-client/env/keys"
 	"github.com/inkyblackness/shocked-client/graphics"
@@ -19,12 +21,24 @@ import (
 	dataModel "github.com/inkyblackness/shocked-model"
 )
 
+// journalSaveDelayMSec is the time without changes to the history, after which the journal is saved.
+const journalSaveDelayMSec = 2000
+
 // MainApplication represents the core intelligence of the editor.
 type MainApplication struct {
 	lastElapsedTick time.Time
 	elapsedMSec     int64
 
-	commandStack cmd.Stack
+	commandStack          *cmd.Stack
+	projectStacks         map[string]*cmd.Stack
+	historyChangeHandlers []func()
+	journals              map[string]*cmd.JournalFile
+	journalWriters        map[string]*cmd.JournalWriter
+	journalProjectID      string
+	journalSavePending    bool
+	journalSaveAtMSec     int64
+	actions               *actions.Registry
+	keymap                *actions.Keymap
 
 	store        dataModel.DataStore
 	modelAdapter *model.Adapter
@@ -56,8 +70,18 @@ type MainApplication struct {
 }
 
 // NewMainApplication returns a new instance of MainApplication.
-func NewMainApplication(store dataModel.DataStore, scale float32, invertedSliderScroll bool) *MainApplication {
+// The keymap determines the shortcuts of the editor actions.
+// The optional journals keep the undo histories across sessions. They are mapped by the
+// identifier of the project they belong to. Projects without journal start with an empty history.
+func NewMainApplication(store dataModel.DataStore, scale float32, invertedSliderScroll bool,
+	keymap *actions.Keymap, journals map[string]*cmd.JournalFile) *MainApplication {
 	app := &MainApplication{
+		commandStack:         &cmd.Stack{},
+		projectStacks:        make(map[string]*cmd.Stack),
+		actions:              actions.NewRegistry(),
+		keymap:               keymap,
+		journals:             journals,
+		journalWriters:       make(map[string]*cmd.JournalWriter),
 		projectionMatrix:     mgl.Ident4(),
 		lastElapsedTick:      time.Now(),
 		store:                store,
@@ -77,12 +101,96 @@ func (app *MainApplication) Init(glWindow env.OpenGlWindow) {
 	app.initOpenGl()
 
 	app.initResources()
+	app.initActions()
 	app.initInterface()
 
 	app.onWindowResize(glWindow.Size())
+	for _, name := range app.keymap.UnknownActions(app.actions) {
+		fmt.Fprintf(os.Stderr, "Keymap refers to unknown action <%v>\n", name)
+	}
 
 	app.modelAdapter.SetMessage("Ready.")
-	app.modelAdapter.RequestProject("(inplace)")
+	app.modelAdapter.OnProjectChanged(app.onProjectChanged)
+	app.modelAdapter.RequestProject(app.modelAdapter.AvailableProjectIDs()[0])
+	app.onHistoryChanged(app.scheduleJournalSave)
+}
+
+// onProjectChanged switches to the undo history of the now active project. Each project has its own
+// history, as commands always modify the active project. The history of a project is taken from
+// its journal when the project becomes active for the first time.
+func (app *MainApplication) onProjectChanged() {
+	projectID := app.modelAdapter.ActiveProjectID()
+	stack, existing := app.projectStacks[projectID]
+	if !existing {
+		stack = &cmd.Stack{}
+		app.projectStacks[projectID] = stack
+		app.loadJournal(projectID, stack)
+	}
+	app.commandStack = stack
+	app.notifyHistoryChanged()
+}
+
+// loadJournal restores the history of given project from its journal, if it has one.
+// Further changes of the history are written to the same journal.
+func (app *MainApplication) loadJournal(projectID string, stack *cmd.Stack) {
+	file := app.journals[projectID]
+	if file == nil {
+		return
+	}
+	journal, err := file.Load()
+	if err == nil {
+		err = stack.Load(journal, modes.NewSetterResolver(app.modelAdapter))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Undo journal of project %v could not be loaded: %v\n", projectID, err)
+	}
+	app.journalWriters[projectID] = cmd.NewJournalWriter(file, func(err error) {
+		fmt.Fprintf(os.Stderr, "Undo journal of project %v could not be saved: %v\n", projectID, err)
+	})
+}
+
+// scheduleJournalSave lets the journal of the active project be saved once the history has not
+// changed for a while. A save still scheduled for another project is done right away.
+func (app *MainApplication) scheduleJournalSave() {
+	projectID := app.modelAdapter.ActiveProjectID()
+	if app.journalWriters[projectID] == nil {
+		return
+	}
+	if app.journalSavePending && (app.journalProjectID != projectID) {
+		app.saveJournal()
+	}
+	app.updateElapsedNano()
+	app.journalProjectID = projectID
+	app.journalSavePending = true
+	app.journalSaveAtMSec = app.elapsedMSec + journalSaveDelayMSec
+}
+
+// saveJournalIfDue saves the journal if it is scheduled and the delay has passed.
+// The save waits while added objects have not yet received their index, as such commands can't be recorded.
+func (app *MainApplication) saveJournalIfDue() {
+	if app.journalSavePending && (app.elapsedMSec >= app.journalSaveAtMSec) &&
+		!app.projectStacks[app.journalProjectID].ObjectIndicesPending() {
+		app.saveJournal()
+	}
+}
+
+// saveJournal queues the undo history of the project the save was scheduled for.
+// The journal is written in the background.
+func (app *MainApplication) saveJournal() {
+	app.journalSavePending = false
+	app.journalWriters[app.journalProjectID].Queue(app.projectStacks[app.journalProjectID].Journal())
+}
+
+// Close saves any scheduled journal and waits until all journals are written.
+// It is called when the window was closed.
+func (app *MainApplication) Close() {
+	if app.journalSavePending {
+		app.saveJournal()
+	}
+	for projectID, writer := range app.journalWriters {
+		writer.Close()
+		delete(app.journalWriters, projectID)
+	}
 }
 
 func (app *MainApplication) setWindow(glWindow env.OpenGlWindow) {
@@ -202,6 +310,40 @@ func (app *MainApplication) initWorldPalette() {
 	})
 }
 
+func (app *MainApplication) initActions() {
+	app.registerAction("project.save", "Save project", app.modelAdapter.SaveProject)
+	app.registerAction("project.next", "Switch to next project", app.switchToNextProject)
+	app.registerAction("edit.undo", "Undo", app.undo)
+	app.registerAction("edit.redo", "Redo", app.redo)
+	app.registerAction("edit.copy", "Copy to clipboard", func() {
+		app.rootArea.DispatchPositionalEvent(events.NewClipboardEvent(events.ClipboardCopyEventType,
+			app.mouseX, app.mouseY, app.glWindow.Clipboard()))
+	})
+	app.registerAction("edit.paste", "Paste from clipboard", func() {
+		app.rootArea.DispatchPositionalEvent(events.NewClipboardEvent(events.ClipboardPasteEventType,
+			app.mouseX, app.mouseY, app.glWindow.Clipboard()))
+	})
+}
+
+// switchToNextProject makes the project after the active one active, in the order the store lists them.
+func (app *MainApplication) switchToNextProject() {
+	projectIDs := app.modelAdapter.AvailableProjectIDs()
+	next := 0
+	for index, projectID := range projectIDs {
+		if projectID == app.modelAdapter.ActiveProjectID() {
+			next = (index + 1) % len(projectIDs)
+		}
+	}
+	if projectIDs[next] != app.modelAdapter.ActiveProjectID() {
+		app.modelAdapter.RequestProject(projectIDs[next])
+		app.modelAdapter.SetMessage(fmt.Sprintf("Switched to project %s", projectIDs[next]))
+	}
+}
+
+func (app *MainApplication) registerAction(name, title string, handler actions.Handler) {
+	app.actions.Register(actions.Action{Name: name, Title: title, Handler: handler})
+}
+
 func (app *MainApplication) initInterface() {
 	app.rectRenderer = graphics.NewRectangleRenderer(app.gl, &app.projectionMatrix)
 
@@ -222,7 +364,7 @@ func (app *MainApplication) initInterface() {
 	app.uiTextRenderer = graphics.NewBitmapTextureRenderer(uiRenderContext, app.uiTextPalette)
 	app.worldTextureRenderer = graphics.NewBitmapTextureRenderer(uiRenderContext, app.worldPalette)
 
-	app.root, app.rootArea = newRootArea(app)
+	app.root, app.rootArea = newRootArea(app, app.keymap, app)
 }
 
 func (app *MainApplication) updateElapsedNano() {
@@ -249,6 +391,7 @@ func (app *MainApplication) render() {
 	gl.Clear(opengl.COLOR_BUFFER_BIT)
 
 	app.updateElapsedNano()
+	app.saveJournalIfDue()
 	app.rootArea.Render()
 }
 
@@ -283,21 +426,11 @@ func (app *MainApplication) onMouseScroll(dx float32, dy float32) {
 
 func (app *MainApplication) onKey(key keys.Key, modifier keys.Modifier) {
 	app.keyModifier = modifier
-	if key == keys.KeySave {
-		app.modelAdapter.SaveProject()
-	} else if key == keys.KeyCopy {
-		app.rootArea.DispatchPositionalEvent(events.NewClipboardEvent(events.ClipboardCopyEventType,
-			app.mouseX, app.mouseY, app.glWindow.Clipboard()))
-	} else if key == keys.KeyPaste {
-		app.rootArea.DispatchPositionalEvent(events.NewClipboardEvent(events.ClipboardPasteEventType,
-			app.mouseX, app.mouseY, app.glWindow.Clipboard()))
-	} else if key == keys.KeyUndo {
-		app.undo()
-	} else if key == keys.KeyRedo {
-		app.redo()
-	} else if (key >= keys.KeyF1) && (key <= keys.KeyF9) {
-		modeIndex := key - keys.KeyF1
-		app.root.RequestActiveMode(app.root.ModeNames()[modeIndex])
+	if app.rootArea.HandleEvent(events.NewKeyEvent(int(key), uint32(modifier))) {
+		return
+	}
+	if action, bound := app.keymap.Action(keys.Shortcut{Key: key, Modifier: modifier}); bound {
+		app.actions.Perform(action)
 	}
 }
 
@@ -306,6 +439,7 @@ func (app *MainApplication) onModifier(modifier keys.Modifier) {
 }
 
 func (app *MainApplication) onChar(char rune) {
+	app.rootArea.HandleEvent(events.NewCharEvent(char))
 }
 
 func (app *MainApplication) onFileDrop(filePaths []string) {
@@ -318,16 +452,54 @@ func (app *MainApplication) sendFileDropEvent(filePaths []string) {
 }
 
 func (app *MainApplication) undo() {
-	err := app.commandStack.Undo()
-	if err != nil {
-		app.modelAdapter.SetMessage("Failed to undo command.")
-	}
+	app.undoSteps(1)
 }
 
 func (app *MainApplication) redo() {
-	err := app.commandStack.Redo()
-	if err != nil {
-		app.modelAdapter.SetMessage("Failed to redo command.")
+	app.redoSteps(1)
+}
+
+// undoSteps undoes up to the given amount of commands. It stops at the first failing command.
+func (app *MainApplication) undoSteps(steps int) {
+	for step, command := range app.commandStack.UndoEntries() {
+		if step >= steps {
+			break
+		}
+		err := app.commandStack.Undo()
+		if err != nil {
+			app.modelAdapter.SetMessage(fmt.Sprintf("Failed to undo <%v>: %v", cmd.Describe(command), err))
+			break
+		}
+	}
+	app.notifyHistoryChanged()
+}
+
+// redoSteps redoes up to the given amount of commands. It stops at the first failing command.
+func (app *MainApplication) redoSteps(steps int) {
+	for step, command := range app.commandStack.RedoEntries() {
+		if step >= steps {
+			break
+		}
+		err := app.commandStack.Redo()
+		if err != nil {
+			app.modelAdapter.SetMessage(fmt.Sprintf("Failed to redo <%v>: %v", cmd.Describe(command), err))
+			break
+		}
+	}
+	app.notifyHistoryChanged()
+}
+
+func (app *MainApplication) historyEntries() (undoList, redoList []cmd.Command) {
+	return app.commandStack.UndoEntries(), app.commandStack.RedoEntries()
+}
+
+func (app *MainApplication) onHistoryChanged(callback func()) {
+	app.historyChangeHandlers = append(app.historyChangeHandlers, callback)
+}
+
+func (app *MainApplication) notifyHistoryChanged() {
+	for _, handler := range app.historyChangeHandlers {
+		handler()
 	}
 }
 
@@ -335,8 +507,14 @@ func (app *MainApplication) redo() {
 func (app *MainApplication) Perform(command cmd.Command) {
 	err := app.commandStack.Perform(command)
 	if err != nil {
-		app.modelAdapter.SetMessage("Failed to perform command.")
+		app.modelAdapter.SetMessage(fmt.Sprintf("Failed to perform <%v>: %v", cmd.Describe(command), err))
 	}
+	app.notifyHistoryChanged()
+}
+
+// Actions implements the Context interface.
+func (app *MainApplication) Actions() *actions.Registry {
+	return app.actions
 }
 
 // ModelAdapter implements the Context interface.
@@ -411,7 +589,7 @@ func (app *MainApplication) Scale() float32 {
 
 // ForLabel implements the controls.Factory interface.
 func (app *MainApplication) ForLabel() *controls.LabelBuilder {
-	builder := controls.NewLabelBuilder(app.defaultFontPainter, app.Texturize, app.uiTextRenderer)
+	builder := controls.NewLabelBuilder(app.defaultFontPainter, app.Texturize, app.uiTextRenderer, app.rectRenderer)
 	builder.SetScale(2.0 * app.Scale())
 	return builder
 }
@@ -0,0 +1,610 @@
+package editor
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/editor/actions"
+	"github.com/inkyblackness/shocked-client/editor/cmd"
+	"github.com/inkyblackness/shocked-client/editor/model"
+	"github.com/inkyblackness/shocked-client/editor/model/memstore"
+	"github.com/inkyblackness/shocked-client/editor/model/multistore"
+	"github.com/inkyblackness/shocked-client/env"
+	"github.com/inkyblackness/shocked-client/env/headless"
+	"github.com/inkyblackness/shocked-client/env/keys"
+	"github.com/inkyblackness/shocked-client/opengl/software"
+	dataModel "github.com/inkyblackness/shocked-model"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden images in testdata")
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type MainApplicationSuite struct {
+	store   *memstore.DataStore
+	app     *MainApplication
+	session *headless.Session
+}
+
+var _ = check.Suite(&MainApplicationSuite{})
+
+func (suite *MainApplicationSuite) SetUpTest(c *check.C) {
+	deferrer := make(chan func(), 100)
+	suite.store = memstore.NewDataStore(deferrer)
+	project := suite.store.Project("(inplace)")
+	project.AddLevel("archive", 0)
+	project.AddLevel("archive", 1)
+	suite.app = NewMainApplication(suite.store, 1.0, false, actions.DefaultKeymap(), nil)
+	suite.session = headless.NewSession(suite.app, 320, 240, deferrer)
+}
+
+func (suite *MainApplicationSuite) TestInitLoadsInplaceProject(c *check.C) {
+	adapter := suite.app.ModelAdapter()
+
+	c.Check(adapter.ActiveProjectID(), check.Equals, "(inplace)")
+	c.Check(adapter.AvailableLevelIDs(), check.DeepEquals, []int{0, 1})
+	c.Check(suite.store.Pending(), check.Equals, 0)
+}
+
+func (suite *MainApplicationSuite) TestSaveShortcutSavesProject(c *check.C) {
+	suite.session.Key(keys.CharKey('s'), keys.ModControl)
+
+	c.Check(suite.store.Project("(inplace)").SaveCount(), check.Equals, 1)
+}
+
+func (suite *MainApplicationSuite) TestModeShortcutSwitchesModeUndoably(c *check.C) {
+	suite.session.Key(keys.KeyF3, keys.ModNone)
+
+	c.Check(suite.app.root.activeMode, check.Equals, suite.app.root.levelMapMode)
+	c.Check(suite.app.root.levelMapMode.String(), check.Equals, "Level Map (F3)")
+
+	suite.session.Key(keys.CharKey('z'), keys.ModControl)
+
+	c.Check(suite.app.root.activeMode, check.Equals, suite.app.root.welcomeMode)
+}
+
+func (suite *MainApplicationSuite) TestPaletteKeepsKeysUntilClosedByEscape(c *check.C) {
+	suite.session.Key(keys.CharKey('p'), keys.ModControl)
+	c.Assert(suite.app.root.palette.IsVisible(), check.Equals, true)
+	suite.session.Key(keys.KeyF3, keys.ModNone)
+
+	suite.session.Key(keys.KeyEscape, keys.ModNone)
+
+	c.Check(suite.app.root.activeMode, check.Equals, suite.app.root.welcomeMode)
+	c.Check(suite.app.root.palette.IsVisible(), check.Equals, false)
+	c.Check(suite.app.root.palette.area.HasFocus(), check.Equals, false)
+}
+
+func (suite *MainApplicationSuite) TestPalettePerformsHighlightedAction(c *check.C) {
+	suite.session.Key(keys.CharKey('p'), keys.ModControl)
+	suite.session.Type("level map")
+	suite.session.Key(keys.KeyEnter, keys.ModNone)
+
+	c.Check(suite.app.root.palette.IsVisible(), check.Equals, false)
+	c.Check(suite.app.root.activeMode, check.Equals, suite.app.root.levelMapMode)
+}
+
+func (suite *MainApplicationSuite) TestHistoryPanelJumpsSeveralSteps(c *check.C) {
+	suite.session.Key(keys.KeyF3, keys.ModNone)
+	suite.session.Key(keys.KeyF4, keys.ModNone)
+	suite.session.Key(keys.CharKey('h'), keys.ModControl)
+	panel := suite.app.root.historyPanel
+	c.Assert(panel.IsVisible(), check.Equals, true)
+
+	c.Check(panel.entries, check.DeepEquals,
+		[]string{"(Initial state)", "Switch to mode Level Map", "Switch to mode Level Objects"})
+
+	suite.session.Move(200, panel.entriesTop.Value()+panel.entryHeight/2).Click(env.MousePrimary, keys.ModNone)
+	c.Check(suite.app.root.activeMode, check.Equals, suite.app.root.welcomeMode)
+	c.Check(panel.currentIndex, check.Equals, 0)
+
+	suite.session.Move(200, panel.entriesTop.Value()+panel.entryHeight*2.5).Click(env.MousePrimary, keys.ModNone)
+	c.Check(suite.app.root.activeMode, check.Equals, suite.app.root.levelObjectsMode)
+}
+
+type pixel struct {
+	x, y float32
+}
+
+func (suite *MainApplicationSuite) givenLevelMap(levelID int) {
+	suite.session.Key(keys.KeyF3, keys.ModNone)
+	suite.givenLevelLoaded(levelID)
+}
+
+func (suite *MainApplicationSuite) givenLevelLoaded(levelID int) {
+	suite.app.ModelAdapter().RequestActiveLevel(levelID)
+	suite.session.Settle()
+}
+
+func (suite *MainApplicationSuite) givenOpenTile(levelID int, at pixel, floorHeight int) {
+	x, y := suite.tileAt(at)
+	suite.givenOpenTileAt(levelID, x, y, floorHeight)
+}
+
+func (suite *MainApplicationSuite) givenOpenTileAt(levelID int, x, y int, floorHeight int) {
+	level := suite.store.Project("(inplace)").Level("archive", levelID)
+	properties := level.Tile(x, y)
+	tileType := dataModel.Open
+	height := dataModel.HeightUnit(floorHeight)
+	properties.Type = &tileType
+	properties.FloorHeight = &height
+	level.SetTile(x, y, properties)
+}
+
+func (suite *MainApplicationSuite) tileAt(at pixel) (x, y int) {
+	worldX, worldY := suite.app.root.mapDisplay.WorldCoordinatesForPixel(at.x, at.y)
+	return int(worldX) >> 8, int(worldY) >> 8
+}
+
+func (suite *MainApplicationSuite) TestLevelMapRendersTilesAndSelection(c *check.C) {
+	suite.session.Resize(640, 480)
+	suite.givenLevelMap(0)
+	suite.session.Frame()
+	x, y := suite.tileAt(pixel{400, 200})
+	level := suite.store.Project("(inplace)").Level("archive", 0)
+	for offset := 0; offset < 4; offset++ {
+		for _, coord := range [][2]int{{x + offset, y}, {x, y - offset - 1}} {
+			suite.givenOpenTileAt(0, coord[0], coord[1], offset*4)
+			properties := level.Tile(coord[0], coord[1])
+			properties.CalculatedWallHeights = &dataModel.CalculatedWallHeights{North: 1.0, South: 0.5, West: 0.25}
+			level.SetTile(coord[0], coord[1], properties)
+		}
+	}
+	suite.givenLevelLoaded(0)
+	suite.session.Move(400, 200).Click(env.MousePrimary, keys.ModNone)
+	suite.session.Move(0, 0)
+
+	c.Check(software.CheckGoldenImage(suite.session.Frame(), "testdata/MapDisplay_Render.png", *updateGolden), check.IsNil)
+}
+
+func (suite *MainApplicationSuite) copyTileRegion(from, to pixel) {
+	suite.session.Move(from.x, from.y).Click(env.MousePrimary, keys.ModNone)
+	suite.session.Move(to.x, to.y).Click(env.MousePrimary, keys.ModShift)
+	suite.session.Key(keys.CharKey('c'), keys.ModControl)
+}
+
+func (suite *MainApplicationSuite) pasteTiles(at pixel) {
+	suite.session.Move(at.x, at.y).Key(keys.CharKey('v'), keys.ModControl)
+	suite.session.Click(env.MousePrimary, keys.ModNone).Settle()
+}
+
+func (suite *MainApplicationSuite) storedTile(levelID, x, y int) dataModel.TileProperties {
+	return suite.store.Project("(inplace)").Level("archive", levelID).Tile(x, y)
+}
+
+func (suite *MainApplicationSuite) TestPastedTilesAreUndoneInOneStep(c *check.C) {
+	suite.givenLevelMap(0)
+	suite.givenOpenTile(0, pixel{250, 100}, 5)
+	suite.givenLevelLoaded(0)
+	suite.copyTileRegion(pixel{250, 100}, pixel{290, 100})
+	suite.pasteTiles(pixel{250, 200})
+	fromX, _ := suite.tileAt(pixel{250, 100})
+	toX, _ := suite.tileAt(pixel{290, 100})
+
+	suite.session.Key(keys.CharKey('z'), keys.ModControl).Settle()
+
+	anchorX, anchorY := suite.tileAt(pixel{250, 200})
+	c.Check(*suite.storedTile(0, anchorX, anchorY).Type, check.Equals, dataModel.Solid)
+	c.Check(suite.app.root.historyPanel.entries[1:], check.DeepEquals,
+		[]string{"Switch to mode Level Map", fmt.Sprintf("Paste %d tiles", toX-fromX+1)})
+}
+
+func (suite *MainApplicationSuite) givenObjectAt(at pixel, classData []byte) {
+	worldX, worldY := suite.app.root.mapDisplay.WorldCoordinatesForPixel(at.x, at.y)
+	tileX, fineX := int(worldX)>>8, int(worldX)&0xFF
+	tileY, fineY := int(worldY)>>8, int(worldY)&0xFF
+	suite.store.Project("(inplace)").Level("archive", 0).AddObject(1, dataModel.LevelObjectProperties{
+		TileX: &tileX, FineX: &fineX, TileY: &tileY, FineY: &fineY, ClassData: classData})
+}
+
+func (suite *MainApplicationSuite) givenLevelObjects() {
+	suite.session.Resize(640, 480)
+	suite.session.Key(keys.KeyF4, keys.ModNone)
+	suite.givenLevelLoaded(0)
+}
+
+func (suite *MainApplicationSuite) storedObjects() []dataModel.LevelObject {
+	level := suite.store.Project("(inplace)").Level("archive", 0)
+	var objects []dataModel.LevelObject
+	for _, id := range level.ObjectIDs() {
+		object, _ := level.Object(id)
+		objects = append(objects, object)
+	}
+	return objects
+}
+
+func (suite *MainApplicationSuite) TestPastedObjectsAreUndoneInOneStep(c *check.C) {
+	suite.givenLevelObjects()
+	suite.givenObjectAt(pixel{350, 100}, []byte{1, 2})
+	suite.givenObjectAt(pixel{390, 130}, []byte{3, 4})
+	suite.givenLevelLoaded(0)
+	suite.session.Move(350, 100).Click(env.MousePrimary, keys.ModNone)
+	suite.session.Move(390, 130).Click(env.MousePrimary, keys.ModControl)
+	suite.session.Key(keys.CharKey('c'), keys.ModControl)
+	suite.session.Move(350, 180).Key(keys.CharKey('v'), keys.ModControl).Settle()
+	c.Assert(suite.storedObjects(), check.HasLen, 4)
+	c.Check(suite.app.root.historyPanel.entries[1:], check.DeepEquals,
+		[]string{"Switch to mode Level Objects", "Paste 2 objects"})
+
+	suite.session.Key(keys.CharKey('z'), keys.ModControl).Settle()
+
+	c.Check(suite.storedObjects(), check.HasLen, 2)
+
+	suite.session.Key(keys.CharKey('y'), keys.ModControl).Settle()
+
+	objects := suite.storedObjects()
+	c.Assert(objects, check.HasLen, 4)
+	c.Check(objects[3].Properties.ClassData, check.DeepEquals, []byte{3, 4})
+}
+
+func (suite *MainApplicationSuite) TestPastedObjectsAreNotUndoneBeforeTheyAreAdded(c *check.C) {
+	suite.givenLevelObjects()
+	suite.givenObjectAt(pixel{350, 100}, []byte{1, 2})
+	suite.givenLevelLoaded(0)
+	suite.session.Move(350, 100).Click(env.MousePrimary, keys.ModNone)
+	suite.session.Key(keys.CharKey('c'), keys.ModControl)
+	suite.session.Move(350, 180)
+
+	suite.app.Actions().Perform("edit.paste")
+	suite.app.Actions().Perform("edit.undo")
+
+	c.Check(suite.app.ModelAdapter().Message(), check.Equals,
+		"Failed to undo <Paste 1 objects>: objects are still being added")
+	suite.session.Settle()
+	c.Check(suite.storedObjects(), check.HasLen, 2)
+
+	suite.session.Key(keys.CharKey('z'), keys.ModControl).Settle()
+
+	c.Check(suite.storedObjects(), check.HasLen, 1)
+}
+
+func (suite *MainApplicationSuite) TestMovedObjectsAreUndoneInOneStep(c *check.C) {
+	suite.givenLevelObjects()
+	suite.givenObjectAt(pixel{350, 100}, nil)
+	suite.givenObjectAt(pixel{390, 130}, nil)
+	suite.givenLevelLoaded(0)
+	suite.session.Move(350, 100).Click(env.MousePrimary, keys.ModNone)
+	suite.session.Move(390, 130).Click(env.MousePrimary, keys.ModControl)
+	before := suite.storedObjects()
+	suite.session.Drag(env.MousePrimary, keys.ModAlt, 420, 180).Settle()
+
+	suite.session.Key(keys.CharKey('z'), keys.ModControl).Settle()
+
+	c.Check(suite.storedObjects(), check.DeepEquals, before)
+	c.Check(suite.app.root.historyPanel.entries[1:], check.DeepEquals,
+		[]string{"Switch to mode Level Objects", "Move 2 objects"})
+}
+
+// copiedCount copies the current selection and returns the amount of copied entries.
+func (suite *MainApplicationSuite) copiedCount() int {
+	var data struct {
+		Tiles   []interface{} `json:"tiles"`
+		Objects []interface{} `json:"objects"`
+	}
+	suite.session.Window().SetText("")
+	suite.session.Key(keys.CharKey('c'), keys.ModControl)
+	json.Unmarshal([]byte(suite.session.Window().Text()), &data)
+	return len(data.Tiles) + len(data.Objects)
+}
+
+func (suite *MainApplicationSuite) TestBrushStrokeIsUndoneInOneStep(c *check.C) {
+	suite.givenLevelMap(0)
+	fromX, fromY := suite.tileAt(pixel{250, 100})
+	toX, toY := suite.tileAt(pixel{290, 130})
+	suite.app.Actions().Perform("levelMap.brushRectangle")
+	suite.session.Move(250, 100).Drag(env.MousePrimary, keys.ModNone, 290, 130).Settle()
+	c.Check(*suite.storedTile(0, toX, toY).Type, check.Equals, dataModel.Open)
+
+	suite.session.Key(keys.CharKey('z'), keys.ModControl).Settle()
+
+	c.Check(*suite.storedTile(0, fromX, fromY).Type, check.Equals, dataModel.Solid)
+	c.Check(*suite.storedTile(0, toX, toY).Type, check.Equals, dataModel.Solid)
+	c.Check(suite.app.root.historyPanel.entries[1:], check.DeepEquals,
+		[]string{"Switch to mode Level Map", fmt.Sprintf("Paint %d tiles", (toX-fromX+1)*(fromY-toY+1))})
+}
+
+// givenRampStrip prepares three open tiles at given pixel and north of it, with open tiles
+// of given heights to the south and north of them. The strip is selected.
+func (suite *MainApplicationSuite) givenRampStrip(at pixel, southHeight, northHeight int) (x, y int) {
+	suite.givenLevelMap(0)
+	x, y = suite.tileAt(at)
+	suite.givenOpenTileAt(0, x, y-1, southHeight)
+	for offset := 0; offset < 3; offset++ {
+		suite.givenOpenTileAt(0, x, y+offset, 0)
+	}
+	suite.givenOpenTileAt(0, x, y+3, northHeight)
+	suite.givenLevelLoaded(0)
+	suite.session.Move(at.x, at.y).Drag(env.MouseSecondary, keys.ModNone, at.x, at.y-64).Settle()
+	return
+}
+
+func (suite *MainApplicationSuite) TestRampIsUndoneInOneStep(c *check.C) {
+	x, y := suite.givenRampStrip(pixel{250, 130}, 8, 2)
+	suite.app.Actions().Perform("levelMap.buildRamp")
+	suite.session.Settle()
+	c.Check(*suite.storedTile(0, x, y).Type, check.Equals, dataModel.SlopeNorthToSouth)
+
+	suite.session.Key(keys.CharKey('z'), keys.ModControl).Settle()
+
+	c.Check(*suite.storedTile(0, x, y).Type, check.Equals, dataModel.Open)
+	c.Check(suite.app.root.historyPanel.entries[1:], check.DeepEquals,
+		[]string{"Switch to mode Level Map", "Build ramp on 3 tiles"})
+}
+
+func (suite *MainApplicationSuite) givenObjectInTile(x, y int, z int) int {
+	fine := 0x80
+	return suite.store.Project("(inplace)").Level("archive", 0).AddObject(1, dataModel.LevelObjectProperties{
+		TileX: &x, FineX: &fine, TileY: &y, FineY: &fine, Z: &z})
+}
+
+func (suite *MainApplicationSuite) givenLevelValidation() {
+	suite.session.Key(keys.KeyF10, keys.ModNone)
+	suite.givenLevelLoaded(0)
+}
+
+func (suite *MainApplicationSuite) nextFinding() string {
+	suite.app.Actions().Perform("levelValidation.nextFinding")
+	return suite.app.ModelAdapter().Message()
+}
+
+func (suite *MainApplicationSuite) TestSelectedFindingIsCenteredOnMap(c *check.C) {
+	suite.givenObjectInTile(10, 12, 0)
+	suite.givenLevelValidation()
+
+	suite.nextFinding()
+	suite.session.Frame()
+
+	worldX, worldY := suite.app.root.mapDisplay.WorldCoordinatesForPixel(160, 120)
+	c.Check(int(worldX)>>8, check.Equals, 10)
+	c.Check(int(worldY)>>8, check.Equals, 12)
+}
+
+func (suite *MainApplicationSuite) givenObjectSearch(query string) {
+	suite.session.Key(keys.CharKey('f'), keys.ModControl)
+	suite.session.Type(query)
+	suite.session.Key(keys.KeyEnter, keys.ModNone)
+	suite.session.Move(400, 200)
+}
+
+func (suite *MainApplicationSuite) TestSearchedObjectsCanBeSelected(c *check.C) {
+	suite.givenObjectInTile(10, 10, 2)
+	suite.givenObjectInTile(12, 10, 4)
+	suite.givenObjectInTile(14, 10, 8)
+	suite.givenLevelObjects()
+
+	suite.givenObjectSearch("class=ammoclips z>=4")
+	suite.app.Actions().Perform("levelObjects.selectMatches")
+
+	c.Check(suite.copiedCount(), check.Equals, 2)
+}
+
+func (suite *MainApplicationSuite) TestSteppingThroughMatchesSelectsSingleObject(c *check.C) {
+	suite.givenObjectInTile(10, 10, 2)
+	suite.givenObjectInTile(12, 10, 4)
+	suite.givenLevelObjects()
+
+	suite.givenObjectSearch("class=1")
+	suite.session.Key(keys.CharKey('m'), keys.ModAlt)
+
+	c.Check(suite.copiedCount(), check.Equals, 1)
+}
+
+func (suite *MainApplicationSuite) TestInvalidSearchIsReported(c *check.C) {
+	suite.givenLevelObjects()
+
+	suite.givenObjectSearch("z>>3")
+
+	c.Check(suite.app.ModelAdapter().Message(), check.Equals, "Invalid search: invalid term <z>>3>")
+}
+
+func (suite *MainApplicationSuite) TestLevelMapIsExportedIntoDroppedFolder(c *check.C) {
+	dir := c.MkDir()
+	suite.givenOpenTileAt(0, 2, 61, 0)
+	objectIndex := suite.givenObjectInTile(2, 61, 0)
+	suite.store.Project("(inplace)").SetGameObjectIcon(1, 0, 0, &dataModel.RawBitmap{Width: 1, Height: 1, Pixels: "AQ=="})
+	suite.givenLevelMap(0)
+	x, y := suite.tileAt(pixel{250, 130})
+	suite.session.Move(250, 130).Click(env.MousePrimary, keys.ModNone)
+	suite.session.Frame()
+	suite.session.Settle()
+	before := suite.session.Frame()
+
+	suite.session.Drop(dir)
+
+	imageFileName, vectorFileName := filepath.Join(dir, "level_00.png"), filepath.Join(dir, "level_00.svg")
+	c.Check(suite.app.ModelAdapter().Message(), check.Equals, fmt.Sprintf("Exported %s and %s", imageFileName, vectorFileName))
+	c.Check(suite.session.Window().Framebuffer().Image().Pix, check.DeepEquals, before.Pix)
+	imageFile, err := os.Open(imageFileName)
+	c.Assert(err, check.IsNil)
+	defer imageFile.Close()
+	img, err := png.Decode(imageFile)
+	c.Assert(err, check.IsNil)
+	c.Check(img.Bounds(), check.Equals, image.Rect(0, 0, 1024, 1024))
+	selected := color.NRGBAModel.Convert(img.At(x*16+8, (63-y)*16+8)).(color.NRGBA)
+	c.Check(selected.G > selected.R, check.Equals, true)
+	c.Check(color.NRGBAModel.Convert(img.At(8, 8)), check.Not(check.Equals), selected)
+
+	vectors, err := ioutil.ReadFile(vectorFileName)
+	c.Assert(err, check.IsNil)
+	c.Check(strings.Count(string(vectors), "<polygon "), check.Equals, 1)
+	c.Check(string(vectors), check.Matches, fmt.Sprintf(`(?s).*<polygon points="512,768 768,768 768,512 512,512".*data-index="%d".*`, objectIndex))
+}
+
+func (suite *MainApplicationSuite) TestLevelMapIsExportedAgainByAction(c *check.C) {
+	dir := c.MkDir()
+	suite.givenLevelMap(0)
+	suite.session.Move(250, 130).Drop(dir)
+	imageFileName, vectorFileName := filepath.Join(dir, "level_00.png"), filepath.Join(dir, "level_00.svg")
+	c.Assert(os.Remove(imageFileName), check.IsNil)
+	c.Assert(os.Remove(vectorFileName), check.IsNil)
+
+	suite.session.Key(keys.CharKey('e'), keys.ModControl)
+
+	c.Check(suite.app.ModelAdapter().Message(), check.Equals, fmt.Sprintf("Exported %s and %s", imageFileName, vectorFileName))
+	_, imageErr := os.Stat(imageFileName)
+	c.Check(imageErr, check.IsNil)
+	_, vectorErr := os.Stat(vectorFileName)
+	c.Check(vectorErr, check.IsNil)
+}
+
+func (suite *MainApplicationSuite) TestLevelFileImportCanBeUndone(c *check.C) {
+	dir := c.MkDir()
+	level := suite.store.Project("(inplace)").Level("archive", 0)
+	suite.givenOpenTileAt(0, 5, 6, 0)
+	suite.session.Key(keys.KeyF2, keys.ModNone)
+	suite.givenLevelLoaded(0)
+	suite.session.Move(50, 100).Drop(dir)
+	levelFileName := filepath.Join(dir, "level_00.json")
+	c.Check(suite.app.ModelAdapter().Message(), check.Equals, fmt.Sprintf("Exported %s", levelFileName))
+	solid := dataModel.Solid
+	level.SetTile(5, 6, dataModel.TileProperties{Type: &solid})
+	movedIndex := suite.givenObjectInTile(5, 6, 0)
+	suite.givenLevelLoaded(0)
+
+	suite.session.Drop(levelFileName)
+	c.Check(suite.app.ModelAdapter().Message(), check.Equals, "Imported level_00.json")
+	c.Check(*level.Tile(5, 6).Type, check.Equals, dataModel.Open)
+	c.Check(len(level.ObjectIDs()), check.Equals, 0)
+
+	suite.session.Key(keys.CharKey('z'), keys.ModControl)
+	c.Check(*level.Tile(5, 6).Type, check.Equals, dataModel.Solid)
+	c.Check(level.ObjectIDs(), check.DeepEquals, []int{movedIndex})
+	c.Check(suite.app.ModelAdapter().Message(), check.Equals, "Restored level 0")
+
+	suite.session.Key(keys.CharKey('y'), keys.ModControl)
+	c.Check(*level.Tile(5, 6).Type, check.Equals, dataModel.Open)
+}
+
+func (suite *MainApplicationSuite) TestLevelDifferencesAreAppliedAsOneCommand(c *check.C) {
+	dir := c.MkDir()
+	level := suite.store.Project("(inplace)").Level("archive", 0)
+	suite.givenOpenTileAt(0, 5, 6, 0)
+	objectIndex := suite.givenObjectInTile(5, 6, 0)
+	suite.store.Project("(inplace)").SetGameObjectIcon(1, 0, 0, &dataModel.RawBitmap{Width: 1, Height: 1, Pixels: "AQ=="})
+	suite.givenLevelLoaded(0)
+	otherFileName := filepath.Join(dir, "other.json")
+	otherFile, err := os.Create(otherFileName)
+	c.Assert(err, check.IsNil)
+	c.Assert(suite.app.ModelAdapter().ActiveLevel().Export().Write(otherFile), check.IsNil)
+	otherFile.Close()
+	solid := dataModel.Solid
+	level.SetTile(5, 6, dataModel.TileProperties{Type: &solid})
+	level.RemoveObject(objectIndex)
+	suite.givenLevelLoaded(1)
+	suite.givenLevelLoaded(0)
+	suite.app.Actions().Perform("mode.levelDiff")
+	suite.session.Frame()
+	suite.session.Settle()
+
+	suite.session.Move(50, 100)
+	suite.session.Drop(otherFileName)
+	suite.app.Actions().Perform("levelDiff.apply")
+	suite.session.Settle()
+
+	c.Check(*level.Tile(5, 6).Type, check.Equals, dataModel.Open)
+	c.Check(level.ObjectIDs(), check.DeepEquals, []int{objectIndex})
+	c.Check(suite.app.root.historyPanel.entries[len(suite.app.root.historyPanel.entries)-1], check.Equals,
+		"Apply 2 differences to level 0")
+
+	suite.session.Key(keys.CharKey('z'), keys.ModControl)
+	suite.session.Settle()
+
+	c.Check(*level.Tile(5, 6).Type, check.Equals, dataModel.Solid)
+	c.Check(level.ObjectIDs(), check.HasLen, 0)
+}
+
+func (suite *MainApplicationSuite) TestJournalIsSavedAfterChangesOnClose(c *check.C) {
+	fileName := filepath.Join(c.MkDir(), "journal.json")
+	deferrer := make(chan func(), 100)
+	store := memstore.NewDataStore(deferrer)
+	store.Project(model.InplaceProjectID).AddLevel("archive", 0)
+	app := NewMainApplication(store, 1.0, false, actions.DefaultKeymap(),
+		map[string]*cmd.JournalFile{model.InplaceProjectID: cmd.NewJournalFile(fileName, 1024)})
+	headless.NewSession(app, 320, 240, deferrer)
+
+	app.Perform(cmd.SetIntPropertyCommand{Target: "level/0/heightShift", Setter: func(int) error { return nil },
+		OldValue: 3, NewValue: 4})
+	_, statErr := os.Stat(fileName)
+	c.Check(os.IsNotExist(statErr), check.Equals, true)
+	app.Close()
+
+	journal, err := cmd.NewJournalFile(fileName, 1024).Load()
+	c.Assert(err, check.IsNil)
+	c.Assert(journal.Undo, check.HasLen, 1)
+	c.Check(journal.Undo[0].Target, check.Equals, "level/0/heightShift")
+}
+
+func (suite *MainApplicationSuite) TestEachProjectHasItsOwnJournal(c *check.C) {
+	dir := c.MkDir()
+	modFileName, otherFileName := filepath.Join(dir, "mod.json"), filepath.Join(dir, "other.json")
+	c.Assert(cmd.NewJournalFile(otherFileName, 1024).Save(cmd.Journal{
+		Undo: []cmd.Record{{Type: "int", Target: "level/0/heightShift", OldValue: []byte("1"), NewValue: []byte("2")}}}),
+		check.IsNil)
+	deferrer := make(chan func(), 100)
+	mod := memstore.NewDataStore(deferrer)
+	mod.Project(model.InplaceProjectID).SetPalette("game", [256]dataModel.Color{}).AddLevel("archive", 0)
+	other := memstore.NewDataStore(deferrer)
+	other.Project(model.InplaceProjectID).SetPalette("game", [256]dataModel.Color{}).AddLevel("archive", 0)
+	store := multistore.NewDataStore()
+	store.Add("mod", mod, model.InplaceProjectID, false)
+	store.Add("other", other, model.InplaceProjectID, false)
+	app := NewMainApplication(store, 1.0, false, actions.DefaultKeymap(), map[string]*cmd.JournalFile{
+		"mod":   cmd.NewJournalFile(modFileName, 1024),
+		"other": cmd.NewJournalFile(otherFileName, 1024)})
+	session := headless.NewSession(app, 320, 240, deferrer)
+
+	app.Perform(cmd.SetIntPropertyCommand{Target: "level/0/ceilingEffectLevel", Setter: func(int) error { return nil },
+		OldValue: 3, NewValue: 4})
+	app.Actions().Perform("project.next")
+	session.Settle()
+	c.Check(app.root.historyPanel.entries, check.HasLen, 2)
+	app.Close()
+
+	modJournal, modErr := cmd.NewJournalFile(modFileName, 1024).Load()
+	c.Assert(modErr, check.IsNil)
+	c.Assert(modJournal.Undo, check.HasLen, 1)
+	c.Check(modJournal.Undo[0].Target, check.Equals, "level/0/ceilingEffectLevel")
+	otherJournal, otherErr := cmd.NewJournalFile(otherFileName, 1024).Load()
+	c.Assert(otherErr, check.IsNil)
+	c.Assert(otherJournal.Undo, check.HasLen, 1)
+	c.Check(otherJournal.Undo[0].Target, check.Equals, "level/0/heightShift")
+}
+
+func (suite *MainApplicationSuite) TestProjectsKeepSeparateUndoHistories(c *check.C) {
+	deferrer := make(chan func(), 100)
+	mod := memstore.NewDataStore(deferrer)
+	mod.Project(model.InplaceProjectID).SetPalette("game", [256]dataModel.Color{}).AddLevel("archive", 0)
+	original := memstore.NewDataStore(deferrer)
+	original.Project(model.InplaceProjectID).SetPalette("game", [256]dataModel.Color{}).AddLevel("archive", 0)
+	store := multistore.NewDataStore()
+	store.Add("mod", mod, model.InplaceProjectID, false)
+	store.Add("original", original, model.InplaceProjectID, true)
+	app := NewMainApplication(store, 1.0, false, actions.DefaultKeymap(), nil)
+	session := headless.NewSession(app, 320, 240, deferrer)
+	c.Assert(app.ModelAdapter().ActiveProjectID(), check.Equals, "mod")
+
+	session.Key(keys.KeyF3, keys.ModNone)
+	app.Actions().Perform("project.next")
+	session.Settle()
+
+	c.Check(app.ModelAdapter().ActiveProjectID(), check.Equals, "original")
+	c.Check(app.ModelAdapter().AvailableLevelIDs(), check.DeepEquals, []int{0})
+	c.Check(app.root.historyPanel.entries, check.DeepEquals, []string{"(Initial state)"})
+
+	app.Actions().Perform("project.next")
+	session.Settle()
+
+	c.Check(app.ModelAdapter().ActiveProjectID(), check.Equals, "mod")
+	c.Check(app.root.historyPanel.entries, check.DeepEquals, []string{"(Initial state)", "Switch to mode Level Map"})
+}
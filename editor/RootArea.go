@@ -1,6 +1,9 @@
 package editor
 
 import (
+	"fmt"
+
+	"github.com/inkyblackness/shocked-client/editor/actions"
 	"github.com/inkyblackness/shocked-client/editor/cmd"
 	"github.com/inkyblackness/shocked-client/editor/display"
 	"github.com/inkyblackness/shocked-client/editor/modes"
@@ -8,28 +11,42 @@ import (
 	"github.com/inkyblackness/shocked-client/ui"
 )
 
+const commandPaletteActionName = "palette.open"
+
 type modeSelector struct {
-	mode Mode
-	name string
+	mode     Mode
+	name     string
+	shortcut string
 }
 
 func (selector *modeSelector) String() string {
+	if selector.shortcut != "" {
+		return fmt.Sprintf("%v (%v)", selector.name, selector.shortcut)
+	}
 	return selector.name
 }
 
 type rootArea struct {
 	context modes.Context
+	keymap  *actions.Keymap
 	area    *ui.Area
 
-	modeArea *ui.Area
+	modeArea   *ui.Area
+	mapDisplay *display.MapDisplay
 
 	modeBox      *controls.ComboBox
 	messageLabel *controls.Label
+	palette      *commandPalette
+	historyPanel *historyPanel
 
 	welcomeMode            *modeSelector
+	projectsMode           *modeSelector
 	levelControlMode       *modeSelector
 	levelMapMode           *modeSelector
 	levelObjectsMode       *modeSelector
+	levelValidationMode    *modeSelector
+	levelStatisticsMode    *modeSelector
+	levelDiffMode          *modeSelector
 	gameObjectsMode        *modeSelector
 	gameTexturesMode       *modeSelector
 	bitmapsMode            *modeSelector
@@ -39,8 +56,8 @@ type rootArea struct {
 	activeMode             *modeSelector
 }
 
-func newRootArea(context modes.Context) (*rootArea, *ui.Area) {
-	root := &rootArea{context: context}
+func newRootArea(context modes.Context, keymap *actions.Keymap, history commandHistory) (*rootArea, *ui.Area) {
+	root := &rootArea{context: context, keymap: keymap}
 	areaBuilder := ui.NewAreaBuilder()
 
 	areaBuilder.SetRight(ui.NewAbsoluteAnchor(0.0))
@@ -52,7 +69,7 @@ func newRootArea(context modes.Context) (*rootArea, *ui.Area) {
 	}
 	var topLine *ui.Area
 
-	mapDisplay := display.NewMapDisplay(context, root.area, context.ControlFactory().Scale())
+	root.mapDisplay = display.NewMapDisplay(context, root.area, context.ControlFactory().Scale())
 
 	topLineBottom := ui.NewOffsetAnchor(root.area.Top(), scaled(25+4))
 	{
@@ -74,15 +91,19 @@ func newRootArea(context modes.Context) (*rootArea, *ui.Area) {
 		topLine = builder.Build()
 	}
 
-	root.welcomeMode = root.addMode(modes.NewWelcomeMode(context, root.modeArea), "Welcome (F1)")
-	root.levelControlMode = root.addMode(modes.NewLevelControlMode(context, root.modeArea, mapDisplay), "Level Control (F2)")
-	root.levelMapMode = root.addMode(modes.NewLevelMapMode(context, root.modeArea, mapDisplay), "Level Map (F3)")
-	root.levelObjectsMode = root.addMode(modes.NewLevelObjectsMode(context, root.modeArea, mapDisplay), "Level Objects (F4)")
-	root.electronicMessagesMode = root.addMode(modes.NewElectronicMessagesMode(context, root.modeArea), "Electronic Messages (F5)")
-	root.gameObjectsMode = root.addMode(modes.NewGameObjectsMode(context, root.modeArea), "Game Objects (F6)")
-	root.gameTexturesMode = root.addMode(modes.NewGameTexturesMode(context, root.modeArea), "Game Textures (F7)")
-	root.bitmapsMode = root.addMode(modes.NewGameBitmapsMode(context, root.modeArea), "Bitmaps (F8)")
-	root.textsMode = root.addMode(modes.NewGameTextsMode(context, root.modeArea), "Texts (F9)")
+	root.welcomeMode = root.addMode(modes.NewWelcomeMode(context, root.modeArea), "Welcome", "welcome")
+	root.projectsMode = root.addMode(modes.NewProjectsMode(context, root.modeArea), "Projects", "projects")
+	root.levelControlMode = root.addMode(modes.NewLevelControlMode(context, root.modeArea, root.mapDisplay), "Level Control", "levelControl")
+	root.levelMapMode = root.addMode(modes.NewLevelMapMode(context, root.modeArea, root.mapDisplay), "Level Map", "levelMap")
+	root.levelObjectsMode = root.addMode(modes.NewLevelObjectsMode(context, root.modeArea, root.mapDisplay), "Level Objects", "levelObjects")
+	root.levelValidationMode = root.addMode(modes.NewLevelValidationMode(context, root.modeArea, root.mapDisplay), "Level Validation", "levelValidation")
+	root.levelStatisticsMode = root.addMode(modes.NewLevelStatisticsMode(context, root.modeArea), "Level Statistics", "levelStatistics")
+	root.levelDiffMode = root.addMode(modes.NewLevelDiffMode(context, root.modeArea, root.mapDisplay), "Level Diff", "levelDiff")
+	root.electronicMessagesMode = root.addMode(modes.NewElectronicMessagesMode(context, root.modeArea), "Electronic Messages", "electronicMessages")
+	root.gameObjectsMode = root.addMode(modes.NewGameObjectsMode(context, root.modeArea), "Game Objects", "gameObjects")
+	root.gameTexturesMode = root.addMode(modes.NewGameTexturesMode(context, root.modeArea), "Game Textures", "gameTextures")
+	root.bitmapsMode = root.addMode(modes.NewGameBitmapsMode(context, root.modeArea), "Bitmaps", "bitmaps")
+	root.textsMode = root.addMode(modes.NewGameTextsMode(context, root.modeArea), "Texts", "texts")
 
 	boxMessageSeparator := ui.NewOffsetAnchor(topLine.Left(), scaled(250))
 	{
@@ -116,25 +137,36 @@ func newRootArea(context modes.Context) (*rootArea, *ui.Area) {
 		})
 	}
 
+	root.historyPanel = newHistoryPanel(context, history, root.area, topLineBottom)
+	context.Actions().Register(actions.Action{
+		Name:    "edit.history",
+		Title:   "Toggle undo history",
+		Handler: root.historyPanel.Toggle})
+
+	root.palette = newCommandPalette(context, keymap, root.area)
+	context.Actions().Register(actions.Action{
+		Name:    commandPaletteActionName,
+		Title:   "Show command palette",
+		Handler: root.palette.Show})
+
 	root.setActiveMode(root.welcomeMode.name)
 
 	return root, root.area
 }
 
-func (root *rootArea) ModeNames() []string {
-	names := make([]string, len(root.allModes))
-	for index, mode := range root.allModes {
-		names[index] = mode.name
-	}
-	return names
-}
-
-func (root *rootArea) addMode(mode Mode, name string) *modeSelector {
+// addMode registers the given mode, together with an action named "mode.<id>" to switch to it.
+func (root *rootArea) addMode(mode Mode, name string, id string) *modeSelector {
+	actionName := "mode." + id
 	selector := &modeSelector{
-		mode: mode,
-		name: name}
+		mode:     mode,
+		name:     name,
+		shortcut: root.keymap.ShortcutText(actionName)}
 
 	root.allModes = append(root.allModes, selector)
+	root.context.Actions().Register(actions.Action{
+		Name:    actionName,
+		Title:   "Switch to mode " + name,
+		Handler: func() { root.RequestActiveMode(name) }})
 
 	return selector
 }
@@ -0,0 +1,22 @@
+package actions
+
+// Handler performs an action.
+type Handler func()
+
+// Action is a named operation of the editor, which can be bound to shortcuts.
+type Action struct {
+	// Name uniquely identifies the action, such as "edit.undo". Keymaps refer to this name.
+	Name string
+	// Title is a human readable description of the action.
+	Title string
+	// Handler performs the action.
+	Handler Handler
+	// Available is an optional check whether the action can currently be performed.
+	// Actions of modes, for example, are typically only available while the mode is active.
+	Available func() bool
+}
+
+// IsAvailable returns true if the action can currently be performed.
+func (action *Action) IsAvailable() bool {
+	return (action.Available == nil) || action.Available()
+}
@@ -0,0 +1,61 @@
+package actions
+
+import (
+	"github.com/inkyblackness/shocked-client/env/keys"
+)
+
+// DefaultKeymap returns a new keymap with the built-in bindings.
+func DefaultKeymap() *Keymap {
+	keymap := NewKeymap()
+	bind := func(action string, texts ...string) {
+		shortcuts := make([]keys.Shortcut, len(texts))
+		for index, text := range texts {
+			shortcut, err := keys.ParseShortcut(text)
+			if err != nil {
+				panic(err)
+			}
+			shortcuts[index] = shortcut
+		}
+		keymap.Bind(action, shortcuts...)
+	}
+
+	bind("project.save", "Ctrl+S")
+	bind("edit.undo", "Ctrl+Z")
+	bind("edit.redo", "Ctrl+Y", "Ctrl+Shift+Z")
+	bind("edit.copy", "Ctrl+C")
+	bind("edit.paste", "Ctrl+V")
+	bind("edit.history", "Ctrl+H")
+	bind("palette.open", "Ctrl+P")
+
+	bind("mode.welcome", "F1")
+	bind("mode.levelControl", "F2")
+	bind("mode.levelMap", "F3")
+	bind("mode.levelObjects", "F4")
+	bind("mode.electronicMessages", "F5")
+	bind("mode.gameObjects", "F6")
+	bind("mode.gameTextures", "F7")
+	bind("mode.bitmaps", "F8")
+	bind("mode.texts", "F9")
+	bind("mode.levelValidation", "F10")
+	bind("mode.levelStatistics", "F11")
+
+	bind("levelMap.cancelPaste", "Escape")
+	bind("levelMap.brushPencil", "Alt+B")
+	bind("levelMap.brushRectangle", "Alt+R")
+	bind("levelMap.brushLine", "Alt+L")
+	bind("levelMap.brushFloodFill", "Alt+F")
+	bind("levelMap.exportMap", "Ctrl+E")
+
+	bind("levelObjects.deleteSelected", "Delete")
+	bind("levelObjects.duplicateSelected", "Ctrl+D")
+	bind("levelObjects.highlightNext", "Tab")
+	bind("levelObjects.highlightPrevious", "Shift+Tab")
+	bind("levelObjects.search", "Ctrl+F")
+	bind("levelObjects.nextMatch", "Alt+M")
+	bind("levelObjects.previousMatch", "Alt+Shift+M")
+
+	bind("levelValidation.nextFinding", "Alt+N")
+	bind("levelValidation.previousFinding", "Alt+Shift+N")
+
+	return keymap
+}
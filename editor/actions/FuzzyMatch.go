@@ -0,0 +1,43 @@
+package actions
+
+import (
+	"unicode"
+)
+
+// FuzzyMatch checks whether all characters of the pattern appear in the given text,
+// in order, ignoring case. The returned score is higher the better the pattern fits:
+// Characters following each other directly and characters at the start of words
+// count more than scattered ones.
+func FuzzyMatch(pattern, text string) (score int, matched bool) {
+	patternRunes := []rune(pattern)
+	patternIndex := 0
+	lastMatch := -2
+	previous := ' '
+
+	for textIndex, char := range []rune(text) {
+		if (patternIndex < len(patternRunes)) &&
+			(unicode.ToLower(char) == unicode.ToLower(patternRunes[patternIndex])) {
+			score++
+			if lastMatch == textIndex-1 {
+				score += 2
+			}
+			if isWordStart(previous, char) {
+				score += 3
+			}
+			lastMatch = textIndex
+			patternIndex++
+		}
+		previous = char
+	}
+	matched = patternIndex == len(patternRunes)
+	if !matched {
+		score = 0
+	}
+
+	return
+}
+
+func isWordStart(previous, char rune) bool {
+	return (!unicode.IsLetter(previous) && !unicode.IsDigit(previous)) ||
+		(unicode.IsLower(previous) && unicode.IsUpper(char))
+}
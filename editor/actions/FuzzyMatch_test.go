@@ -0,0 +1,43 @@
+package actions
+
+import (
+	check "gopkg.in/check.v1"
+)
+
+type FuzzyMatchSuite struct{}
+
+var _ = check.Suite(&FuzzyMatchSuite{})
+
+func (suite *FuzzyMatchSuite) TestEmptyPatternMatchesEverything(c *check.C) {
+	_, matched := FuzzyMatch("", "anything")
+
+	c.Check(matched, check.Equals, true)
+}
+
+func (suite *FuzzyMatchSuite) TestCharactersMustAppearInOrder(c *check.C) {
+	_, inOrder := FuzzyMatch("svp", "Save project")
+	_, reversed := FuzzyMatch("ps", "Save project")
+
+	c.Check(inOrder, check.Equals, true)
+	c.Check(reversed, check.Equals, false)
+}
+
+func (suite *FuzzyMatchSuite) TestMatchIgnoresCase(c *check.C) {
+	_, matched := FuzzyMatch("UNDO", "Undo")
+
+	c.Check(matched, check.Equals, true)
+}
+
+func (suite *FuzzyMatchSuite) TestConsecutiveCharactersScoreHigher(c *check.C) {
+	consecutive, _ := FuzzyMatch("map", "Level Map")
+	scattered, _ := FuzzyMatch("map", "Message parameters")
+
+	c.Check(consecutive > scattered, check.Equals, true)
+}
+
+func (suite *FuzzyMatchSuite) TestWordStartsScoreHigher(c *check.C) {
+	wordStarts, _ := FuzzyMatch("lm", "Level Map")
+	inner, _ := FuzzyMatch("lm", "Helmet")
+
+	c.Check(wordStarts > inner, check.Equals, true)
+}
@@ -0,0 +1,139 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/inkyblackness/shocked-client/env/keys"
+)
+
+// Keymap binds shortcuts to the names of actions.
+// An action can have several shortcuts, while a shortcut should refer to only one action.
+type Keymap struct {
+	bindings map[string][]keys.Shortcut
+}
+
+// NewKeymap returns a new, empty keymap.
+func NewKeymap() *Keymap {
+	return &Keymap{bindings: make(map[string][]keys.Shortcut)}
+}
+
+// LoadKeymap returns a copy of the given base keymap, updated with the bindings read
+// from given reader. The data is a JSON object with action names as keys, each having
+// a list of shortcuts, such as {"edit.undo": ["Ctrl+Z"]}. Listed actions replace the
+// bindings of the base; An empty list removes all shortcuts of an action.
+// An error is returned for malformed data and conflicting bindings.
+func LoadKeymap(reader io.Reader, base *Keymap) (*Keymap, error) {
+	var data map[string][]string
+	err := json.NewDecoder(reader).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	keymap := base.Copy()
+	for action, texts := range data {
+		shortcuts := make([]keys.Shortcut, 0, len(texts))
+		for _, text := range texts {
+			shortcut, parseErr := keys.ParseShortcut(text)
+			if parseErr != nil {
+				return nil, fmt.Errorf("action <%v>: %v", action, parseErr)
+			}
+			shortcuts = append(shortcuts, shortcut)
+		}
+		keymap.Bind(action, shortcuts...)
+	}
+	return keymap, keymap.Validate()
+}
+
+// Copy returns an independent copy of the keymap.
+func (keymap *Keymap) Copy() *Keymap {
+	result := NewKeymap()
+	for action, shortcuts := range keymap.bindings {
+		result.Bind(action, shortcuts...)
+	}
+	return result
+}
+
+// Bind sets the shortcuts of the named action, replacing any previous ones.
+func (keymap *Keymap) Bind(action string, shortcuts ...keys.Shortcut) {
+	if len(shortcuts) > 0 {
+		keymap.bindings[action] = append([]keys.Shortcut{}, shortcuts...)
+	} else {
+		delete(keymap.bindings, action)
+	}
+}
+
+// Shortcuts returns the shortcuts bound to the named action.
+func (keymap *Keymap) Shortcuts(action string) []keys.Shortcut {
+	return keymap.bindings[action]
+}
+
+// ShortcutText returns the first shortcut of given action in textual form.
+// Returns an empty string for unbound actions.
+func (keymap *Keymap) ShortcutText(action string) string {
+	shortcuts := keymap.bindings[action]
+	if len(shortcuts) == 0 {
+		return ""
+	}
+	return shortcuts[0].String()
+}
+
+// Action returns the name of the action bound to the given shortcut.
+// Should the shortcut be bound to several actions, the first name in order is returned.
+func (keymap *Keymap) Action(shortcut keys.Shortcut) (action string, bound bool) {
+	for _, name := range keymap.actionNames() {
+		for _, other := range keymap.bindings[name] {
+			if other == shortcut {
+				return name, true
+			}
+		}
+	}
+	return
+}
+
+func (keymap *Keymap) actionNames() []string {
+	names := make([]string, 0, len(keymap.bindings))
+	for name := range keymap.bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate returns an error describing all shortcuts that are bound to more than one action.
+func (keymap *Keymap) Validate() error {
+	actionsByShortcut := make(map[keys.Shortcut][]string)
+	var conflicting []keys.Shortcut
+	for _, name := range keymap.actionNames() {
+		for _, shortcut := range keymap.bindings[name] {
+			previous := actionsByShortcut[shortcut]
+			if (len(previous) > 0) && (previous[len(previous)-1] == name) {
+				continue
+			}
+			if len(previous) == 1 {
+				conflicting = append(conflicting, shortcut)
+			}
+			actionsByShortcut[shortcut] = append(previous, name)
+		}
+	}
+	if len(conflicting) == 0 {
+		return nil
+	}
+	messages := make([]string, len(conflicting))
+	for index, shortcut := range conflicting {
+		messages[index] = fmt.Sprintf("%v is bound to %v", shortcut, strings.Join(actionsByShortcut[shortcut], ", "))
+	}
+	return fmt.Errorf("conflicting shortcuts: %v", strings.Join(messages, "; "))
+}
+
+// UnknownActions returns the names of bound actions that are not registered.
+func (keymap *Keymap) UnknownActions(registry *Registry) (names []string) {
+	for _, name := range keymap.actionNames() {
+		if registry.Action(name) == nil {
+			names = append(names, name)
+		}
+	}
+	return
+}
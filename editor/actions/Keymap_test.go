@@ -0,0 +1,63 @@
+package actions
+
+import (
+	"strings"
+
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/env/keys"
+)
+
+type KeymapSuite struct{}
+
+var _ = check.Suite(&KeymapSuite{})
+
+func (suite *KeymapSuite) TestDefaultKeymapHasNoConflicts(c *check.C) {
+	c.Check(DefaultKeymap().Validate(), check.IsNil)
+}
+
+func (suite *KeymapSuite) TestActionReturnsBoundAction(c *check.C) {
+	action, bound := DefaultKeymap().Action(keys.Shortcut{Key: keys.CharKey('z'), Modifier: keys.ModControl.With(keys.ModShift)})
+
+	c.Check(bound, check.Equals, true)
+	c.Check(action, check.Equals, "edit.redo")
+}
+
+func (suite *KeymapSuite) TestLoadKeymapReplacesBindingsOfListedActions(c *check.C) {
+	keymap, err := LoadKeymap(strings.NewReader(`{"project.save": ["F12"], "mode.welcome": []}`), DefaultKeymap())
+
+	c.Assert(err, check.IsNil)
+	c.Check(keymap.ShortcutText("project.save"), check.Equals, "F12")
+	c.Check(keymap.Shortcuts("mode.welcome"), check.HasLen, 0)
+	c.Check(keymap.ShortcutText("edit.undo"), check.Equals, "Ctrl+Z")
+}
+
+func (suite *KeymapSuite) TestLoadKeymapLeavesBaseUnchanged(c *check.C) {
+	base := DefaultKeymap()
+	LoadKeymap(strings.NewReader(`{"project.save": ["F12"]}`), base)
+
+	c.Check(base.ShortcutText("project.save"), check.Equals, "Ctrl+S")
+}
+
+func (suite *KeymapSuite) TestLoadKeymapReportsConflicts(c *check.C) {
+	_, err := LoadKeymap(strings.NewReader(`{"edit.undo": ["Ctrl+S"]}`), DefaultKeymap())
+
+	c.Assert(err, check.NotNil)
+	c.Check(err.Error(), check.Equals, "conflicting shortcuts: Ctrl+S is bound to edit.undo, project.save")
+}
+
+func (suite *KeymapSuite) TestLoadKeymapReportsInvalidShortcuts(c *check.C) {
+	_, err := LoadKeymap(strings.NewReader(`{"edit.undo": ["Ctrl+Banana"]}`), DefaultKeymap())
+
+	c.Check(err, check.ErrorMatches, "action <edit.undo>: unknown key .*")
+}
+
+func (suite *KeymapSuite) TestUnknownActionsAreReported(c *check.C) {
+	registry := NewRegistry()
+	registry.Register(Action{Name: "edit.undo", Handler: func() {}})
+	keymap := NewKeymap()
+	keymap.Bind("edit.undo", keys.Shortcut{Key: keys.KeyF1})
+	keymap.Bind("edit.unknown", keys.Shortcut{Key: keys.KeyF2})
+
+	c.Check(keymap.UnknownActions(registry), check.DeepEquals, []string{"edit.unknown"})
+}
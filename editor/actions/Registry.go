@@ -0,0 +1,96 @@
+package actions
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Registry keeps track of all named actions.
+type Registry struct {
+	actions map[string]*Action
+}
+
+// NewRegistry returns a new, empty registry.
+func NewRegistry() *Registry {
+	return &Registry{actions: make(map[string]*Action)}
+}
+
+// Register adds the given action. Names must be unique.
+func (registry *Registry) Register(action Action) error {
+	if action.Name == "" {
+		return fmt.Errorf("action has no name")
+	}
+	if _, existing := registry.actions[action.Name]; existing {
+		return fmt.Errorf("action <%v> is already registered", action.Name)
+	}
+	registry.actions[action.Name] = &action
+	return nil
+}
+
+// Action returns the action with given name, or nil if not registered.
+func (registry *Registry) Action(name string) *Action {
+	return registry.actions[name]
+}
+
+// Actions returns all registered actions, sorted by name.
+func (registry *Registry) Actions() []*Action {
+	result := make([]*Action, 0, len(registry.actions))
+	for _, action := range registry.actions {
+		result = append(result, action)
+	}
+	sort.Slice(result, func(a, b int) bool { return result[a].Name < result[b].Name })
+	return result
+}
+
+// Perform calls the handler of the named action, if it is registered and available.
+// Returns true if the action was performed.
+func (registry *Registry) Perform(name string) bool {
+	action := registry.actions[name]
+	if (action == nil) || !action.IsAvailable() {
+		return false
+	}
+	action.Handler()
+	return true
+}
+
+// Search returns all currently available actions matching the given query.
+// The query is fuzzy matched against both title and name of the actions.
+// The result is sorted by relevance. An empty query returns all available
+// actions, sorted by title.
+func (registry *Registry) Search(query string) []*Action {
+	type scoredAction struct {
+		action *Action
+		score  int
+	}
+	var scored []scoredAction
+
+	for _, action := range registry.actions {
+		if !action.IsAvailable() {
+			continue
+		}
+		titleScore, titleMatched := FuzzyMatch(query, action.Title)
+		nameScore, nameMatched := FuzzyMatch(query, action.Name)
+		if titleMatched || nameMatched {
+			score := titleScore
+			if nameScore > score {
+				score = nameScore
+			}
+			scored = append(scored, scoredAction{action: action, score: score})
+		}
+	}
+	sort.Slice(scored, func(a, b int) bool {
+		if scored[a].score != scored[b].score {
+			return scored[a].score > scored[b].score
+		}
+		if scored[a].action.Title != scored[b].action.Title {
+			return scored[a].action.Title < scored[b].action.Title
+		}
+		return scored[a].action.Name < scored[b].action.Name
+	})
+
+	result := make([]*Action, len(scored))
+	for index, entry := range scored {
+		result[index] = entry.action
+	}
+	return result
+}
@@ -0,0 +1,77 @@
+package actions
+
+import (
+	check "gopkg.in/check.v1"
+)
+
+type RegistrySuite struct {
+	registry *Registry
+}
+
+var _ = check.Suite(&RegistrySuite{})
+
+func (suite *RegistrySuite) SetUpTest(c *check.C) {
+	suite.registry = NewRegistry()
+}
+
+func (suite *RegistrySuite) TestRegisterRejectsDuplicateNames(c *check.C) {
+	c.Check(suite.registry.Register(Action{Name: "a", Handler: func() {}}), check.IsNil)
+	c.Check(suite.registry.Register(Action{Name: "a", Handler: func() {}}), check.NotNil)
+}
+
+func (suite *RegistrySuite) TestPerformCallsHandler(c *check.C) {
+	called := 0
+	suite.registry.Register(Action{Name: "a", Handler: func() { called++ }})
+
+	c.Check(suite.registry.Perform("a"), check.Equals, true)
+	c.Check(called, check.Equals, 1)
+}
+
+func (suite *RegistrySuite) TestPerformIgnoresUnknownAndUnavailableActions(c *check.C) {
+	called := 0
+	suite.registry.Register(Action{Name: "a", Handler: func() { called++ }, Available: func() bool { return false }})
+
+	c.Check(suite.registry.Perform("a"), check.Equals, false)
+	c.Check(suite.registry.Perform("b"), check.Equals, false)
+	c.Check(called, check.Equals, 0)
+}
+
+func (suite *RegistrySuite) TestActionsAreSortedByName(c *check.C) {
+	suite.registry.Register(Action{Name: "b", Handler: func() {}})
+	suite.registry.Register(Action{Name: "a", Handler: func() {}})
+
+	actions := suite.registry.Actions()
+	c.Assert(len(actions), check.Equals, 2)
+	c.Check(actions[0].Name, check.Equals, "a")
+	c.Check(actions[1].Name, check.Equals, "b")
+}
+
+func (suite *RegistrySuite) TestSearchReturnsMatchingAvailableActions(c *check.C) {
+	suite.registry.Register(Action{Name: "project.save", Title: "Save project", Handler: func() {}})
+	suite.registry.Register(Action{Name: "edit.undo", Title: "Undo", Handler: func() {}})
+	suite.registry.Register(Action{Name: "edit.hidden", Title: "Save hidden", Handler: func() {},
+		Available: func() bool { return false }})
+
+	actions := suite.registry.Search("sav")
+	c.Assert(len(actions), check.Equals, 1)
+	c.Check(actions[0].Name, check.Equals, "project.save")
+}
+
+func (suite *RegistrySuite) TestSearchSortsByRelevance(c *check.C) {
+	suite.registry.Register(Action{Name: "a", Title: "Show map", Handler: func() {}})
+	suite.registry.Register(Action{Name: "b", Title: "Save project", Handler: func() {}})
+
+	actions := suite.registry.Search("sp")
+	c.Assert(len(actions), check.Equals, 2)
+	c.Check(actions[0].Name, check.Equals, "b")
+}
+
+func (suite *RegistrySuite) TestSearchWithEmptyQuerySortsByTitle(c *check.C) {
+	suite.registry.Register(Action{Name: "a", Title: "Zoom", Handler: func() {}})
+	suite.registry.Register(Action{Name: "b", Title: "Apply", Handler: func() {}})
+
+	actions := suite.registry.Search("")
+	c.Assert(len(actions), check.Equals, 2)
+	c.Check(actions[0].Name, check.Equals, "b")
+	c.Check(actions[1].Name, check.Equals, "a")
+}
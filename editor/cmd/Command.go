@@ -1,5 +1,9 @@
 package cmd
 
+import (
+	"fmt"
+)
+
 // Command describes an action that can be performed, undone, and redone.
 type Command interface {
 	// Do performs the action.
@@ -11,3 +15,64 @@ type Command interface {
 	// environment may not be in the state as before.
 	Undo() error
 }
+
+// Describer is implemented by commands that can describe themselves for the user.
+type Describer interface {
+	// Description returns a short, human readable text of what the command does.
+	Description() string
+}
+
+// Describe returns the description of given command. Commands not implementing
+// the Describer interface are reported by their type.
+func Describe(command Command) string {
+	if describer, isDescriber := command.(Describer); isDescriber {
+		return describer.Description()
+	}
+	return fmt.Sprintf("%T", command)
+}
+
+type describedCommand struct {
+	Command
+	description string
+}
+
+func (cmd describedCommand) Description() string {
+	return cmd.description
+}
+
+// ObjectIndicesPending returns whether the wrapped command waits for the index of added objects.
+func (cmd describedCommand) ObjectIndicesPending() bool {
+	return objectIndicesPending(cmd.Command)
+}
+
+// ReassignedObjectIndices returns the index changes of the wrapped command.
+func (cmd describedCommand) ReassignedObjectIndices() []ObjectIndexChange {
+	return reassignedObjectIndices(cmd.Command)
+}
+
+// RemapObjectIndices remaps the wrapped command, keeping the description.
+func (cmd describedCommand) RemapObjectIndices(changes []ObjectIndexChange) (Command, []ObjectIndexChange) {
+	remapped, remaining := remapObjectIndices(cmd.Command, changes)
+	return describedCommand{Command: remapped, description: cmd.description}, remaining
+}
+
+// Described returns a command that performs the given one, with an explicit description.
+func Described(command Command, description string) Command {
+	return describedCommand{Command: command, description: description}
+}
+
+func propertyName(name, fallback string) string {
+	if name == "" {
+		return fallback
+	}
+	return name
+}
+
+// Record returns the record of the wrapped command, grouped under the description.
+func (cmd describedCommand) Record() (Record, bool) {
+	nested, ok := RecordOf(cmd.Command)
+	if !ok {
+		return nested, false
+	}
+	return Record{Type: compoundRecordType, Name: cmd.description, Commands: []Record{nested}}, true
+}
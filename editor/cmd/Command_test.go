@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribeReturnsDescriptionOfCommand(t *testing.T) {
+	command := SetIntPropertyCommand{Name: "height", OldValue: 1, NewValue: 2}
+
+	assert.Equal(t, "Set height from 1 to 2", Describe(command))
+}
+
+func TestDescribeReturnsTypeForCommandsWithoutDescription(t *testing.T) {
+	assert.Equal(t, "*cmd.TestCommand", Describe(&TestCommand{}))
+}
+
+func TestDescribedCommandCarriesDescription(t *testing.T) {
+	inner := &TestCommand{}
+	command := Described(inner, "Paste tiles")
+
+	command.Do()
+	assert.Equal(t, "Paste tiles", Describe(command))
+	assert.Equal(t, 1, inner.executed)
+}
+
+func TestCompoundCommandDescribesFirstCommand(t *testing.T) {
+	command := CompoundCommand{Commands: []Command{
+		SetEditorModeCommand{NewMode: "Level Map"},
+		SetActiveLevelCommand{NewValue: 1}}}
+
+	assert.Equal(t, "Switch to mode Level Map (and 1 more)", Describe(command))
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+)
+
+// CompoundCommand groups several commands into one. The contained commands
+// are performed in sequence and undone in reverse order.
+// If one of the commands fails, the already processed ones are reverted,
+// leaving the environment as it was before.
+// Only errors returned by the contained commands themselves are covered: The setters of the
+// editor forward their changes to the data store and return before the store processed them.
+// Failing store requests are reported as message and are not reverted.
+// The optional Name describes the group for the user.
+type CompoundCommand struct {
+	Name     string
+	Commands []Command
+}
+
+// Description returns the name of the group, or a summary of the contained commands.
+func (cmd CompoundCommand) Description() string {
+	if cmd.Name != "" {
+		return cmd.Name
+	}
+	if len(cmd.Commands) == 0 {
+		return "No change"
+	}
+	text := Describe(cmd.Commands[0])
+	if len(cmd.Commands) > 1 {
+		text = fmt.Sprintf("%v (and %d more)", text, len(cmd.Commands)-1)
+	}
+	return text
+}
+
+// Do performs all contained commands in sequence.
+func (cmd CompoundCommand) Do() error {
+	for index, nested := range cmd.Commands {
+		err := nested.Do()
+		if err != nil {
+			undoInReverse(cmd.Commands[:index])
+			return err
+		}
+	}
+	return nil
+}
+
+// Undo reverts all contained commands in reverse order.
+func (cmd CompoundCommand) Undo() error {
+	for index := len(cmd.Commands) - 1; index >= 0; index-- {
+		err := cmd.Commands[index].Undo()
+		if err != nil {
+			for _, nested := range cmd.Commands[index+1:] {
+				nested.Do()
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// ObjectIndicesPending returns true if any of the contained commands waits for the index of added objects.
+func (cmd CompoundCommand) ObjectIndicesPending() bool {
+	for _, nested := range cmd.Commands {
+		if objectIndicesPending(nested) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReassignedObjectIndices returns the index changes of all contained commands.
+func (cmd CompoundCommand) ReassignedObjectIndices() []ObjectIndexChange {
+	var changes []ObjectIndexChange
+	for _, nested := range cmd.Commands {
+		changes = append(changes, reassignedObjectIndices(nested)...)
+	}
+	return changes
+}
+
+// RemapObjectIndices remaps all contained commands. A change ends with the group if
+// any of the contained commands ends it.
+func (cmd CompoundCommand) RemapObjectIndices(changes []ObjectIndexChange) (Command, []ObjectIndexChange) {
+	remapped := CompoundCommand{Name: cmd.Name, Commands: make([]Command, len(cmd.Commands))}
+	remaining := changes
+	for index, nested := range cmd.Commands {
+		var nestedRemaining []ObjectIndexChange
+		remapped.Commands[index], nestedRemaining = remapObjectIndices(nested, changes)
+		remaining = intersectedChanges(remaining, nestedRemaining)
+	}
+	return remapped, remaining
+}
+
+// intersectedChanges returns the changes that are part of both lists.
+func intersectedChanges(first, second []ObjectIndexChange) []ObjectIndexChange {
+	var result []ObjectIndexChange
+	for _, change := range first {
+		for _, other := range second {
+			if change == other {
+				result = append(result, change)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// undoInReverse reverts the given commands, starting with the last one.
+// Errors are ignored, as this is already used as a recovery mechanism.
+func undoInReverse(commands []Command) {
+	for index := len(commands) - 1; index >= 0; index-- {
+		commands[index].Undo()
+	}
+}
+
+// Record returns the serializable form of the command. A compound command
+// can only be recorded if all of its commands can be recorded.
+func (cmd CompoundCommand) Record() (record Record, ok bool) {
+	nested := make([]Record, len(cmd.Commands))
+	for index, command := range cmd.Commands {
+		nested[index], ok = RecordOf(command)
+		if !ok {
+			return
+		}
+	}
+	return Record{Type: compoundRecordType, Name: cmd.Name, Commands: nested}, true
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CompoundCommandSuite struct {
+	suite.Suite
+
+	log []string
+}
+
+func TestCompoundCommandSuite(t *testing.T) {
+	suite.Run(t, new(CompoundCommandSuite))
+}
+
+func (suite *CompoundCommandSuite) SetupTest() {
+	suite.log = nil
+}
+
+func (suite *CompoundCommandSuite) TestDoPerformsCommandsInSequence() {
+	compound := CompoundCommand{Commands: []Command{suite.aCommand("cmd1"), suite.aCommand("cmd2")}}
+
+	err := compound.Do()
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"do cmd1", "do cmd2"}, suite.log)
+}
+
+func (suite *CompoundCommandSuite) TestUndoRevertsCommandsInReverseOrder() {
+	compound := CompoundCommand{Commands: []Command{suite.aCommand("cmd1"), suite.aCommand("cmd2")}}
+
+	err := compound.Undo()
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"undo cmd2", "undo cmd1"}, suite.log)
+}
+
+func (suite *CompoundCommandSuite) TestDoRevertsPerformedCommandsOnFailure() {
+	failing := suite.aCommand("cmd2")
+	failing.pendingError = fmt.Errorf("failing")
+	compound := CompoundCommand{Commands: []Command{suite.aCommand("cmd1"), failing, suite.aCommand("cmd3")}}
+
+	err := compound.Do()
+	assert.NotNil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"do cmd1", "do cmd2", "undo cmd1"}, suite.log)
+}
+
+func (suite *CompoundCommandSuite) TestUndoRestoresRevertedCommandsOnFailure() {
+	failing := suite.aCommand("cmd1")
+	compound := CompoundCommand{Commands: []Command{suite.aCommand("cmd0"), failing, suite.aCommand("cmd2")}}
+	failing.pendingError = fmt.Errorf("failing")
+
+	err := compound.Undo()
+	assert.NotNil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"undo cmd2", "undo cmd1", "do cmd2"}, suite.log)
+}
+
+func (suite *CompoundCommandSuite) aCommand(name string) *loggingCommand {
+	return &loggingCommand{name: name, log: &suite.log}
+}
+
+type loggingCommand struct {
+	name         string
+	log          *[]string
+	pendingError error
+}
+
+func (cmd *loggingCommand) Do() error {
+	*cmd.log = append(*cmd.log, "do "+cmd.name)
+	return cmd.result()
+}
+
+func (cmd *loggingCommand) Undo() error {
+	*cmd.log = append(*cmd.log, "undo "+cmd.name)
+	return cmd.result()
+}
+
+func (cmd *loggingCommand) result() (err error) {
+	err = cmd.pendingError
+	cmd.pendingError = nil
+	return
+}
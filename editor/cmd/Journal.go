@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Journal is the serializable form of the lists of a command stack.
+// Commands that only change the view, such as switching the editor mode, are skipped.
+// All other commands must be recordable: the lists end before the first one that is not.
+type Journal struct {
+	// Undo lists the commands to undo, starting with the most recent one.
+	Undo []Record `json:"undo"`
+	// Redo lists the commands to redo, starting with the next one.
+	Redo []Record `json:"redo"`
+}
+
+// Journal returns the journal of the stack.
+// Commands beyond one that can not be recorded are dropped, as they would be applied
+// without the changes of the missing command.
+func (stack *Stack) Journal() (journal Journal) {
+	stack.settleObjectIndices()
+	journal.Undo = recordsOf(stack.UndoEntries())
+	journal.Redo = recordsOf(stack.RedoEntries())
+	return
+}
+
+// recordsOf returns the records of the given commands, up to the first one that can not be recorded.
+func recordsOf(commands []Command) []Record {
+	records := []Record{}
+	for _, command := range commands {
+		if _, isViewCommand := command.(ViewCommand); isViewCommand {
+			continue
+		}
+		record, ok := RecordOf(command)
+		if !ok {
+			break
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// Load replaces the lists of the stack with the commands of given journal.
+// Should a record not be restorable, it and all records further away from the current
+// position are dropped. The returned error reports the first problem.
+// Load is not possible during a transaction.
+func (stack *Stack) Load(journal Journal, resolver SetterResolver) error {
+	stack.lock("Load")
+	defer stack.unlock()
+
+	if stack.transaction != nil {
+		return errTransactionInProgress
+	}
+	undoList, undoErr := restoreList(journal.Undo, resolver)
+	redoList, redoErr := restoreList(journal.Redo, resolver)
+	stack.undoList = undoList
+	stack.redoList = redoList
+	stack.assignments = nil
+	if undoErr != nil {
+		return undoErr
+	}
+	return redoErr
+}
+
+// restoreList creates a linked list of the given records, keeping their order.
+func restoreList(records []Record, resolver SetterResolver) (list *stackEntry, err error) {
+	commands := make([]Command, 0, len(records))
+	for _, record := range records {
+		command, restoreErr := record.Restore(resolver)
+		if restoreErr != nil {
+			err = fmt.Errorf("command <%v> could not be restored: %v", record.Target, restoreErr)
+			break
+		}
+		commands = append(commands, command)
+	}
+	for index := len(commands) - 1; index >= 0; index-- {
+		list = &stackEntry{list, commands[index]}
+	}
+	return
+}
+
+// Limit drops records until the serialized journal fits into the given amount of bytes.
+// The records furthest away from the current position are dropped first, beginning
+// with the redo list.
+func (journal *Journal) Limit(sizeLimit int) {
+	size := journal.size()
+	for (size > sizeLimit) && (len(journal.Redo) > 0) {
+		last := len(journal.Redo) - 1
+		size -= recordSize(journal.Redo[last])
+		journal.Redo = journal.Redo[:last]
+	}
+	for (size > sizeLimit) && (len(journal.Undo) > 0) {
+		last := len(journal.Undo) - 1
+		size -= recordSize(journal.Undo[last])
+		journal.Undo = journal.Undo[:last]
+	}
+}
+
+// size returns the approximate amount of bytes the journal needs when serialized.
+func (journal *Journal) size() (size int) {
+	for _, record := range journal.Undo {
+		size += recordSize(record)
+	}
+	for _, record := range journal.Redo {
+		size += recordSize(record)
+	}
+	return
+}
+
+func recordSize(record Record) int {
+	data, _ := json.Marshal(record)
+	return len(data) + 1
+}
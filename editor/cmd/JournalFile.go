@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+)
+
+// JournalFile stores a journal in a file, limited in size.
+type JournalFile struct {
+	fileName  string
+	sizeLimit int
+}
+
+// NewJournalFile returns a journal file for the given name. Journals are limited
+// to the given amount of bytes when saved.
+func NewJournalFile(fileName string, sizeLimit int) *JournalFile {
+	return &JournalFile{fileName: fileName, sizeLimit: sizeLimit}
+}
+
+// Load reads the journal from the file. A missing file results in an empty journal.
+func (file *JournalFile) Load() (journal Journal, err error) {
+	data, err := ioutil.ReadFile(file.fileName)
+	if os.IsNotExist(err) {
+		return journal, nil
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &journal)
+	}
+	return
+}
+
+// Save writes the journal to the file. The file is first written under a temporary
+// name and then renamed, so that a failed save does not destroy the previous journal.
+func (file *JournalFile) Save(journal Journal) error {
+	journal.Limit(file.sizeLimit)
+	data, err := json.Marshal(journal)
+	if err != nil {
+		return err
+	}
+	tempName := file.fileName + ".tmp"
+	err = ioutil.WriteFile(tempName, data, 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tempName, file.fileName)
+}
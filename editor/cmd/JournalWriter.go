@@ -0,0 +1,51 @@
+package cmd
+
+// JournalWriter saves journals to a journal file in the background.
+// Journals queued while a previous one is still being written replace each other:
+// only the most recent one is written.
+type JournalWriter struct {
+	file    *JournalFile
+	onError func(err error)
+	pending chan Journal
+	done    chan struct{}
+}
+
+// NewJournalWriter returns a writer for given file. Errors of saving are reported
+// to the given handler, from the background.
+func NewJournalWriter(file *JournalFile, onError func(err error)) *JournalWriter {
+	writer := &JournalWriter{
+		file:    file,
+		onError: onError,
+		pending: make(chan Journal, 1),
+		done:    make(chan struct{})}
+
+	go writer.run()
+
+	return writer
+}
+
+func (writer *JournalWriter) run() {
+	for journal := range writer.pending {
+		if err := writer.file.Save(journal); err != nil {
+			writer.onError(err)
+		}
+	}
+	close(writer.done)
+}
+
+// Queue requests to save the given journal. It replaces a journal that is queued
+// and not yet being written. Queue must always be called from the same goroutine.
+func (writer *JournalWriter) Queue(journal Journal) {
+	select {
+	case <-writer.pending:
+	default:
+	}
+	writer.pending <- journal
+}
+
+// Close writes the queued journal and waits until it is saved.
+// The writer can not be used afterwards.
+func (writer *JournalWriter) Close() {
+	close(writer.pending)
+	<-writer.done
+}
@@ -0,0 +1,483 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/inkyblackness/res/audio"
+	"github.com/inkyblackness/shocked-model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type testingSetterResolver struct {
+	intValues map[string]int
+	changes   []string
+}
+
+func (resolver *testingSetterResolver) IntSetter(target string) (func(value int) error, error) {
+	if target == "unknown" {
+		return nil, fmt.Errorf("unknown target")
+	}
+	return func(value int) error {
+		resolver.intValues[target] = value
+		resolver.changes = append(resolver.changes, fmt.Sprintf("%v=%v", target, value))
+		return nil
+	}, nil
+}
+
+func (resolver *testingSetterResolver) BooleanSetter(target string) (func(value bool) error, error) {
+	return func(value bool) error {
+		resolver.changes = append(resolver.changes, fmt.Sprintf("%v=%v", target, value))
+		return nil
+	}, nil
+}
+
+func (resolver *testingSetterResolver) StringSetter(target string) (func(value string) error, error) {
+	return func(value string) error {
+		resolver.changes = append(resolver.changes, fmt.Sprintf("%v=%v", target, value))
+		return nil
+	}, nil
+}
+
+func (resolver *testingSetterResolver) BitmapSetter(target string) (func(bmp *model.RawBitmap) error, error) {
+	return func(bmp *model.RawBitmap) error { return nil }, nil
+}
+
+func (resolver *testingSetterResolver) AudioSetter(target string) (func(data audio.SoundData) error, error) {
+	return func(data audio.SoundData) error { return nil }, nil
+}
+
+func (resolver *testingSetterResolver) LevelTexturesSetter(target string) (func(textureIDs []int) error, error) {
+	return func(textureIDs []int) error { return nil }, nil
+}
+
+func (resolver *testingSetterResolver) TilesSetter(target string) (func(x, y int, properties model.TileProperties) error, error) {
+	return func(x, y int, properties model.TileProperties) error {
+		resolver.changes = append(resolver.changes, fmt.Sprintf("%v[%d/%d]=%v", target, x, y, *properties.FloorHeight))
+		return nil
+	}, nil
+}
+
+func (resolver *testingSetterResolver) LevelObjectsSetter(target string) (func(objectIndex int, properties model.LevelObjectProperties) error, error) {
+	return func(objectIndex int, properties model.LevelObjectProperties) error {
+		resolver.changes = append(resolver.changes, fmt.Sprintf("%v[%d]=%v", target, objectIndex, *properties.Z))
+		return nil
+	}, nil
+}
+
+func (resolver *testingSetterResolver) LevelObjectAdder(target string) (func(class int, properties model.LevelObjectProperties, onAdded func(objectIndex int), onFailed func()) error, error) {
+	return func(class int, properties model.LevelObjectProperties, onAdded func(objectIndex int), onFailed func()) error {
+		resolver.changes = append(resolver.changes, fmt.Sprintf("%v+%d", target, class))
+		onAdded(len(resolver.changes))
+		return nil
+	}, nil
+}
+
+func (resolver *testingSetterResolver) LevelObjectRemover(target string) (func(objectIndex int) error, error) {
+	return func(objectIndex int) error {
+		resolver.changes = append(resolver.changes, fmt.Sprintf("%v-%d", target, objectIndex))
+		return nil
+	}, nil
+}
+
+func (resolver *testingSetterResolver) ElectronicMessageStore(target string) (ElectronicMessageStore, error) {
+	return &testingMessageStore{resolver: resolver, target: target}, nil
+}
+
+type testingMessageStore struct {
+	resolver *testingSetterResolver
+	target   string
+}
+
+func (store *testingMessageStore) RequestRemove() {
+	store.resolver.changes = append(store.resolver.changes, fmt.Sprintf("%v-", store.target))
+}
+
+func (store *testingMessageStore) RequestMessageChange(message model.ElectronicMessage) {
+	store.resolver.changes = append(store.resolver.changes, fmt.Sprintf("%v+%v", store.target, *message.Title[0]))
+}
+
+func (store *testingMessageStore) RequestAudioChange(language model.ResourceLanguage, data audio.SoundData) {
+	store.resolver.changes = append(store.resolver.changes,
+		fmt.Sprintf("%v[%d]=%v", store.target, language.ToIndex(), data.Samples(0, data.SampleCount())))
+}
+
+type JournalSuite struct {
+	suite.Suite
+
+	resolver *testingSetterResolver
+	stack    *Stack
+	journal  Journal
+	err      error
+}
+
+func TestJournalSuite(t *testing.T) {
+	suite.Run(t, new(JournalSuite))
+}
+
+func (suite *JournalSuite) SetupTest() {
+	suite.resolver = &testingSetterResolver{intValues: make(map[string]int)}
+	suite.stack = new(Stack)
+	suite.journal = Journal{}
+	suite.err = nil
+}
+
+func (suite *JournalSuite) TestJournalContainsRecordableCommands() {
+	suite.givenPerformed(suite.anIntCommand("a", 1, 2))
+	suite.givenPerformed(suite.anIntCommand("b", 3, 4))
+
+	suite.whenJournalIsTaken()
+
+	suite.thenJournalShouldHaveTargets([]string{"b", "a"}, []string{})
+}
+
+func (suite *JournalSuite) TestJournalEndsBeforeFirstUnrecordableCommand() {
+	suite.givenPerformed(suite.anIntCommand("a", 1, 2))
+	suite.givenPerformed(&TestCommand{name: "unrecorded"})
+	suite.givenPerformed(suite.anIntCommand("b", 3, 4))
+	suite.givenPerformed(suite.anIntCommand("c", 5, 6))
+	suite.givenPerformed(&TestCommand{name: "unrecorded"})
+	suite.givenPerformed(suite.anIntCommand("d", 7, 8))
+	suite.givenUndone(2)
+
+	suite.whenJournalIsTaken()
+
+	suite.thenJournalShouldHaveTargets([]string{"c", "b"}, []string{})
+}
+
+func (suite *JournalSuite) TestJournalSkipsViewCommands() {
+	suite.givenPerformed(suite.anIntCommand("a", 1, 2))
+	suite.givenPerformed(SetEditorModeCommand{Activator: func(string) {}, OldMode: "x", NewMode: "y"})
+	suite.givenPerformed(SetActiveLevelCommand{Setter: func(int) error { return nil }, OldValue: 1, NewValue: 2})
+	suite.givenPerformed(suite.anIntCommand("b", 3, 4))
+
+	suite.whenJournalIsTaken()
+
+	suite.thenJournalShouldHaveTargets([]string{"b", "a"}, []string{})
+}
+
+func (suite *JournalSuite) TestTileAndIntCommandsKeepTheirOrderThroughJournalFile() {
+	dir, err := ioutil.TempDir("", "journal")
+	assert.Nil(suite.T(), err)
+	defer os.RemoveAll(dir)
+	file := NewJournalFile(filepath.Join(dir, "journal.json"), 1024*1024)
+	suite.givenPerformed(suite.anIntCommand("a", 1, 2))
+	suite.givenPerformed(suite.aTilesCommand("tiles", 3, 4))
+	suite.givenPerformed(suite.anIntCommand("b", 5, 6))
+	suite.givenPerformed(suite.aTilesCommand("tiles", 4, 7))
+	suite.givenUndone(1)
+	suite.givenJournalIsTaken()
+	assert.Nil(suite.T(), file.Save(suite.journal))
+	suite.journal, suite.err = file.Load()
+	assert.Nil(suite.T(), suite.err)
+
+	suite.whenJournalIsLoadedIntoNewStack()
+	suite.givenUndone(3)
+	suite.stack.Redo()
+	suite.stack.Redo()
+	suite.stack.Redo()
+	suite.stack.Redo()
+
+	assert.Nil(suite.T(), suite.err)
+	assert.Equal(suite.T(), []string{"b=5", "tiles[2/3]=3", "a=1", "a=2", "tiles[2/3]=4", "b=6", "tiles[2/3]=7"},
+		suite.resolver.changes)
+}
+
+func (suite *JournalSuite) TestJournalContainsRedoCommands() {
+	suite.givenPerformed(suite.anIntCommand("a", 1, 2))
+	suite.givenPerformed(suite.anIntCommand("b", 3, 4))
+	suite.givenUndone(1)
+
+	suite.whenJournalIsTaken()
+
+	suite.thenJournalShouldHaveTargets([]string{"a"}, []string{"b"})
+}
+
+func (suite *JournalSuite) TestCommandsWithoutTargetAreNotRecorded() {
+	suite.givenPerformed(SetIntPropertyCommand{Setter: func(int) error { return nil }})
+
+	suite.whenJournalIsTaken()
+
+	suite.thenJournalShouldHaveTargets([]string{}, []string{})
+}
+
+func (suite *JournalSuite) TestLoadedCommandsCanBeUndone() {
+	suite.givenPerformed(suite.anIntCommand("a", 1, 2))
+	suite.givenJournalIsTaken()
+
+	suite.whenJournalIsLoadedIntoNewStack()
+	suite.stack.Undo()
+
+	suite.thenIntValueShouldBe("a", 1)
+}
+
+func (suite *JournalSuite) TestLoadedCommandsCanBeRedone() {
+	suite.givenPerformed(suite.anIntCommand("a", 1, 2))
+	suite.givenUndone(1)
+	suite.givenJournalIsTaken()
+
+	suite.whenJournalIsLoadedIntoNewStack()
+	suite.stack.Redo()
+
+	suite.thenIntValueShouldBe("a", 2)
+}
+
+func (suite *JournalSuite) TestCompoundCommandsAreRestored() {
+	suite.givenPerformed(CompoundCommand{Name: "both", Commands: []Command{
+		suite.anIntCommand("a", 1, 2), suite.anIntCommand("b", 3, 4)}})
+	suite.givenJournalIsTaken()
+
+	suite.whenJournalIsLoadedIntoNewStack()
+	suite.stack.Undo()
+
+	suite.thenIntValueShouldBe("a", 1)
+	suite.thenIntValueShouldBe("b", 3)
+}
+
+func (suite *JournalSuite) TestLoadDropsRecordsFromFirstUnrestorableOne() {
+	suite.givenPerformed(suite.anIntCommand("a", 1, 2))
+	suite.givenPerformed(suite.anIntCommand("unknown", 3, 4))
+	suite.givenPerformed(suite.anIntCommand("c", 5, 6))
+	suite.givenJournalIsTaken()
+
+	suite.whenJournalIsLoadedIntoNewStack()
+
+	assert.NotNil(suite.T(), suite.err)
+	suite.whenJournalIsTaken()
+	suite.thenJournalShouldHaveTargets([]string{"c"}, []string{})
+}
+
+func (suite *JournalSuite) TestLevelObjectChangesAreRestored() {
+	oldZ, newZ := 10, 20
+	suite.givenPerformed(SetLevelObjectsCommand{
+		Target: "objects",
+		Setter: func(int, model.LevelObjectProperties) error { return nil },
+		Changes: []LevelObjectChange{{ObjectIndex: 7,
+			OldProperties: model.LevelObjectProperties{Z: &oldZ},
+			NewProperties: model.LevelObjectProperties{Z: &newZ}}}})
+	suite.givenJournalIsTaken()
+
+	suite.whenJournalIsLoadedIntoNewStack()
+	suite.stack.Undo()
+	suite.stack.Redo()
+
+	assert.Nil(suite.T(), suite.err)
+	assert.Equal(suite.T(), []string{"objects[7]=10", "objects[7]=20"}, suite.resolver.changes)
+}
+
+func (suite *JournalSuite) TestLevelObjectPresenceIsRestored() {
+	suite.givenPerformed(LevelObjectPresenceCommand{
+		Target:  "objects",
+		Adder:   func(int, model.LevelObjectProperties, func(int), func()) error { return nil },
+		Remover: func(int) error { return nil },
+		Class:   3,
+		Index:   NewObjectIndex(7)})
+	suite.givenJournalIsTaken()
+
+	suite.whenJournalIsLoadedIntoNewStack()
+	suite.stack.Undo()
+	suite.stack.Redo()
+	suite.stack.Undo()
+
+	assert.Nil(suite.T(), suite.err)
+	assert.Equal(suite.T(), []string{"objects+3", "objects-1", "objects+3"}, suite.resolver.changes)
+}
+
+func (suite *JournalSuite) TestBooleanCommandsAreRestored() {
+	suite.givenPerformed(SetBooleanPropertyCommand{Target: "flag", Setter: func(bool) error { return nil },
+		OldValue: false, NewValue: true})
+	suite.givenJournalIsTaken()
+
+	suite.whenJournalIsLoadedIntoNewStack()
+	suite.stack.Undo()
+	suite.stack.Redo()
+
+	assert.Nil(suite.T(), suite.err)
+	assert.Equal(suite.T(), []string{"flag=false", "flag=true"}, suite.resolver.changes)
+}
+
+func (suite *JournalSuite) TestStringCommandsAreRestored() {
+	suite.givenPerformed(SetStringPropertyCommand{Target: "text", Setter: func(string) error { return nil },
+		OldValue: "old", NewValue: "new"})
+	suite.givenJournalIsTaken()
+
+	suite.whenJournalIsLoadedIntoNewStack()
+	suite.stack.Undo()
+	suite.stack.Redo()
+
+	assert.Nil(suite.T(), suite.err)
+	assert.Equal(suite.T(), []string{"text=old", "text=new"}, suite.resolver.changes)
+}
+
+func (suite *JournalSuite) TestElectronicMessageRemovalIsRestored() {
+	title := "hello"
+	command := RemoveElectronicMessageCommand{Target: "message", RestoreState: func() {},
+		Store: &testingMessageStore{resolver: suite.resolver, target: "original"}}
+	command.Properties.Title[0] = &title
+	command.Audio[1] = restoredSoundData{&recordedSound{SampleRate: 22050, Samples: []byte{1, 2}}}
+	suite.givenPerformed(command)
+	suite.resolver.changes = nil
+	suite.givenJournalIsTaken()
+
+	suite.whenJournalIsLoadedIntoNewStack()
+	suite.stack.Undo()
+	suite.stack.Redo()
+
+	assert.Nil(suite.T(), suite.err)
+	assert.Equal(suite.T(), []string{"message+hello", "message[1]=[1 2]", "message-"}, suite.resolver.changes)
+}
+
+func (suite *JournalSuite) TestAudioSurvivesSerialization() {
+	command := SetAudioCommand{Target: "sound", Setter: func(audio.SoundData) error { return nil },
+		NewValue: restoredSoundData{&recordedSound{SampleRate: 22050, Samples: []byte{1, 2, 3}}}}
+	record, _ := RecordOf(command)
+
+	restored, err := record.Restore(suite.resolver)
+
+	assert.Nil(suite.T(), err)
+	newValue := restored.(SetAudioCommand).NewValue
+	assert.Equal(suite.T(), float32(22050), newValue.SampleRate())
+	assert.Equal(suite.T(), []byte{1, 2, 3}, newValue.Samples(0, newValue.SampleCount()))
+	assert.Nil(suite.T(), restored.(SetAudioCommand).OldValue)
+}
+
+func (suite *JournalSuite) TestLimitDropsRedoEntriesFirst() {
+	suite.givenPerformed(suite.anIntCommand("a", 1, 2))
+	suite.givenPerformed(suite.anIntCommand("b", 3, 4))
+	suite.givenUndone(1)
+	suite.givenJournalIsTaken()
+
+	suite.journal.Limit(recordSize(suite.journal.Undo[0]))
+
+	suite.thenJournalShouldHaveTargets([]string{"a"}, []string{})
+}
+
+func (suite *JournalSuite) TestLimitDropsOldestUndoEntries() {
+	suite.givenPerformed(suite.anIntCommand("a", 1, 2))
+	suite.givenPerformed(suite.anIntCommand("b", 3, 4))
+	suite.givenJournalIsTaken()
+
+	suite.journal.Limit(recordSize(suite.journal.Undo[0]))
+
+	suite.thenJournalShouldHaveTargets([]string{"b"}, []string{})
+}
+
+func (suite *JournalSuite) TestJournalFileKeepsJournal() {
+	dir, err := ioutil.TempDir("", "journal")
+	assert.Nil(suite.T(), err)
+	defer os.RemoveAll(dir)
+	file := NewJournalFile(filepath.Join(dir, "journal.json"), 1024)
+	suite.givenPerformed(suite.anIntCommand("a", 1, 2))
+	suite.givenJournalIsTaken()
+
+	assert.Nil(suite.T(), file.Save(suite.journal))
+	suite.journal, suite.err = file.Load()
+
+	assert.Nil(suite.T(), suite.err)
+	suite.thenJournalShouldHaveTargets([]string{"a"}, []string{})
+}
+
+func (suite *JournalSuite) TestJournalWriterWritesLastQueuedJournalOnClose() {
+	dir, err := ioutil.TempDir("", "journal")
+	assert.Nil(suite.T(), err)
+	defer os.RemoveAll(dir)
+	file := NewJournalFile(filepath.Join(dir, "journal.json"), 1024)
+	writer := NewJournalWriter(file, func(err error) { assert.Fail(suite.T(), err.Error()) })
+	suite.givenPerformed(suite.anIntCommand("a", 1, 2))
+	writer.Queue(suite.stack.Journal())
+	suite.givenPerformed(suite.anIntCommand("b", 3, 4))
+	writer.Queue(suite.stack.Journal())
+
+	writer.Close()
+	suite.journal, suite.err = file.Load()
+
+	assert.Nil(suite.T(), suite.err)
+	suite.thenJournalShouldHaveTargets([]string{"b", "a"}, []string{})
+}
+
+func (suite *JournalSuite) TestJournalWriterReportsErrors() {
+	file := NewJournalFile(filepath.Join(os.TempDir(), "nonexisting", "journal.json"), 1024)
+	var reported error
+	writer := NewJournalWriter(file, func(err error) { reported = err })
+
+	writer.Queue(Journal{})
+	writer.Close()
+
+	assert.NotNil(suite.T(), reported)
+}
+
+func (suite *JournalSuite) TestMissingJournalFileIsEmpty() {
+	file := NewJournalFile(filepath.Join(os.TempDir(), "nonexisting", "journal.json"), 1024)
+
+	suite.journal, suite.err = file.Load()
+
+	assert.Nil(suite.T(), suite.err)
+	assert.Equal(suite.T(), Journal{}, suite.journal)
+}
+
+func (suite *JournalSuite) anIntCommand(target string, oldValue, newValue int) SetIntPropertyCommand {
+	return SetIntPropertyCommand{
+		Target:   target,
+		OldValue: oldValue,
+		NewValue: newValue,
+		Setter: func(value int) error {
+			suite.resolver.intValues[target] = value
+			return nil
+		}}
+}
+
+func (suite *JournalSuite) aTilesCommand(target string, oldHeight, newHeight int) SetTilesCommand {
+	oldFloor, newFloor := model.HeightUnit(oldHeight), model.HeightUnit(newHeight)
+	return SetTilesCommand{
+		Target: target,
+		Setter: func(x, y int, properties model.TileProperties) error { return nil },
+		Changes: []TileChange{{X: 2, Y: 3,
+			OldProperties: model.TileProperties{FloorHeight: &oldFloor},
+			NewProperties: model.TileProperties{FloorHeight: &newFloor}}}}
+}
+
+func (suite *JournalSuite) givenPerformed(command Command) {
+	suite.stack.Perform(command)
+}
+
+func (suite *JournalSuite) givenUndone(steps int) {
+	for step := 0; step < steps; step++ {
+		suite.stack.Undo()
+	}
+}
+
+func (suite *JournalSuite) givenJournalIsTaken() {
+	suite.whenJournalIsTaken()
+}
+
+func (suite *JournalSuite) whenJournalIsTaken() {
+	suite.journal = suite.stack.Journal()
+}
+
+func (suite *JournalSuite) whenJournalIsLoadedIntoNewStack() {
+	suite.stack = new(Stack)
+	suite.resolver.intValues = make(map[string]int)
+	suite.resolver.changes = nil
+	suite.err = suite.stack.Load(suite.journal, suite.resolver)
+}
+
+func (suite *JournalSuite) thenJournalShouldHaveTargets(undoTargets, redoTargets []string) {
+	targetsOf := func(records []Record) []string {
+		targets := []string{}
+		for _, record := range records {
+			targets = append(targets, record.Target)
+		}
+		return targets
+	}
+	assert.Equal(suite.T(), undoTargets, targetsOf(suite.journal.Undo), "undo")
+	assert.Equal(suite.T(), redoTargets, targetsOf(suite.journal.Redo), "redo")
+}
+
+func (suite *JournalSuite) thenIntValueShouldBe(target string, expected int) {
+	assert.Equal(suite.T(), expected, suite.resolver.intValues[target])
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/inkyblackness/shocked-model"
+)
+
+// LevelObjectPresenceCommand adds or removes a level object, and does the opposite when undone.
+// The store assigns the index of added objects: the Adder reports it through its callback,
+// and the command keeps it in Index for removing the object again. Until then, the index is
+// pending and the command can neither be undone nor recorded. Should the store fail to add the
+// object, the Adder reports this through its other callback; There is then no object to remove.
+// The optional Name identifies the object for the user, the optional Target identifies
+// the objects of the level for the journal.
+type LevelObjectPresenceCommand struct {
+	Name    string
+	Target  string
+	Adder   func(class int, properties model.LevelObjectProperties, onAdded func(objectIndex int), onFailed func()) error
+	Remover func(objectIndex int) error
+
+	Class      int
+	Properties model.LevelObjectProperties
+	// Index is the index of the object while it exists. It is shared by all copies of the command.
+	Index *ObjectIndex
+	// Add is set if the command adds the object, and clear if it removes it.
+	Add bool
+}
+
+// Do adds or removes the object.
+func (cmd LevelObjectPresenceCommand) Do() error {
+	return cmd.setPresent(cmd.Add)
+}
+
+// Undo removes or adds the object.
+func (cmd LevelObjectPresenceCommand) Undo() error {
+	return cmd.setPresent(!cmd.Add)
+}
+
+func (cmd LevelObjectPresenceCommand) setPresent(present bool) error {
+	if cmd.Index.Pending() {
+		return errObjectIndicesPending
+	}
+	if present {
+		cmd.Index.request()
+		err := cmd.Adder(cmd.Class, cmd.Properties, cmd.Index.assign, cmd.Index.fail)
+		if err != nil {
+			cmd.Index.cancelRequest()
+		}
+		return err
+	}
+	if !cmd.Index.assigned {
+		return nil
+	}
+	return cmd.Remover(cmd.Index.Value())
+}
+
+// ObjectIndicesPending returns true while the index of the added object is not yet known.
+func (cmd LevelObjectPresenceCommand) ObjectIndicesPending() bool {
+	return cmd.Index.Pending()
+}
+
+// ReassignedObjectIndices returns the change of the index if the object was added again with another index.
+func (cmd LevelObjectPresenceCommand) ReassignedObjectIndices() []ObjectIndexChange {
+	return cmd.Index.takeReassignments(cmd.Target)
+}
+
+// RemapObjectIndices takes over the changed index of the object. The change ends with this command.
+func (cmd LevelObjectPresenceCommand) RemapObjectIndices(changes []ObjectIndexChange) (Command, []ObjectIndexChange) {
+	var remaining []ObjectIndexChange
+	current := cmd.Index.Value()
+	for _, change := range changes {
+		if (change.Target == cmd.Target) && (change.From == current) {
+			cmd.Index.value = change.To
+		} else {
+			remaining = append(remaining, change)
+		}
+	}
+	return cmd, remaining
+}
+
+// Description returns a text of the change.
+func (cmd LevelObjectPresenceCommand) Description() string {
+	verb := "Remove"
+	if cmd.Add {
+		verb = "Add"
+	}
+	return fmt.Sprintf("%v %v", verb, propertyName(cmd.Name, fmt.Sprintf("object of class %d", cmd.Class)))
+}
+
+// Record returns the serializable form of the command. The object is the new value
+// when it is added, and the old value when it is removed.
+func (cmd LevelObjectPresenceCommand) Record() (record Record, ok bool) {
+	if cmd.Index.Pending() || !cmd.Index.assigned {
+		return
+	}
+	object := &recordedPresentObject{Class: cmd.Class, Index: cmd.Index.Value(), Properties: cmd.Properties}
+	if cmd.Add {
+		return newValueRecord(levelObjectPresenceRecordType, cmd.Name, cmd.Target, nil, object)
+	}
+	return newValueRecord(levelObjectPresenceRecordType, cmd.Name, cmd.Target, object, nil)
+}
+
+// recordedPresentObject is the serializable form of an added or removed level object.
+type recordedPresentObject struct {
+	Class      int                         `json:"class"`
+	Index      int                         `json:"index"`
+	Properties model.LevelObjectProperties `json:"properties"`
+}
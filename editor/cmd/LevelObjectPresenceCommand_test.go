@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/inkyblackness/shocked-model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type testingObjectStore struct {
+	nextIndex int
+	objects   map[int]bool
+	deferred  bool
+	failing   bool
+	callbacks []func()
+}
+
+func (store *testingObjectStore) add(class int, properties model.LevelObjectProperties, onAdded func(objectIndex int), onFailed func()) error {
+	callback := onFailed
+	if !store.failing {
+		index := store.nextIndex
+		store.nextIndex++
+		store.objects[index] = true
+		callback = func() { onAdded(index) }
+	}
+	if store.deferred {
+		store.callbacks = append(store.callbacks, callback)
+	} else {
+		callback()
+	}
+	return nil
+}
+
+func (store *testingObjectStore) remove(objectIndex int) error {
+	delete(store.objects, objectIndex)
+	return nil
+}
+
+func (store *testingObjectStore) settle() {
+	callbacks := store.callbacks
+	store.callbacks = nil
+	for _, callback := range callbacks {
+		callback()
+	}
+}
+
+func (store *testingObjectStore) indices() []int {
+	result := []int{}
+	for index := range store.objects {
+		result = append(result, index)
+	}
+	return result
+}
+
+type LevelObjectPresenceCommandSuite struct {
+	suite.Suite
+
+	store *testingObjectStore
+	stack *Stack
+}
+
+func TestLevelObjectPresenceCommandSuite(t *testing.T) {
+	suite.Run(t, new(LevelObjectPresenceCommandSuite))
+}
+
+func (suite *LevelObjectPresenceCommandSuite) SetupTest() {
+	suite.store = &testingObjectStore{nextIndex: 1, objects: make(map[int]bool)}
+	suite.stack = new(Stack)
+}
+
+func (suite *LevelObjectPresenceCommandSuite) TestUndoIsRefusedWhileIndexIsPending() {
+	suite.store.deferred = true
+	suite.stack.Perform(suite.anAddition())
+
+	err := suite.stack.Undo()
+
+	assert.Equal(suite.T(), errObjectIndicesPending, err)
+	assert.Equal(suite.T(), []int{1}, suite.store.indices())
+	assert.True(suite.T(), suite.stack.ObjectIndicesPending())
+	suite.store.settle()
+	assert.Nil(suite.T(), suite.stack.Undo())
+	assert.Equal(suite.T(), []int{}, suite.store.indices())
+}
+
+func (suite *LevelObjectPresenceCommandSuite) TestFailedAdditionIsUndoneWithoutRemoving() {
+	suite.store.objects[0] = true
+	suite.store.deferred = true
+	suite.store.failing = true
+	suite.stack.Perform(suite.anAddition())
+	suite.store.settle()
+
+	err := suite.stack.Undo()
+
+	assert.Nil(suite.T(), err)
+	assert.False(suite.T(), suite.stack.ObjectIndicesPending())
+	assert.Equal(suite.T(), []int{0}, suite.store.indices())
+}
+
+func (suite *LevelObjectPresenceCommandSuite) TestCommandIsNotRecordedWhileIndexIsPending() {
+	suite.store.deferred = true
+	command := suite.anAddition()
+	command.Target = "objects"
+	suite.stack.Perform(command)
+
+	_, pendingOk := RecordOf(command)
+	suite.store.settle()
+	_, settledOk := RecordOf(command)
+
+	assert.False(suite.T(), pendingOk)
+	assert.True(suite.T(), settledOk)
+}
+
+func (suite *LevelObjectPresenceCommandSuite) TestOlderCommandsFollowObjectAddedAgainByUndo() {
+	suite.stack.Perform(suite.anAddition())
+	suite.stack.Perform(Described(CompoundCommand{Commands: []Command{suite.aRemovalOf(1)}}, "remove"))
+
+	suite.stack.Undo()
+	suite.stack.Undo()
+
+	assert.Equal(suite.T(), []int{}, suite.store.indices())
+}
+
+func (suite *LevelObjectPresenceCommandSuite) TestPropertyChangesFollowObjectAddedAgainByUndo() {
+	var changedIndices []int
+	setter := func(objectIndex int, properties model.LevelObjectProperties) error {
+		changedIndices = append(changedIndices, objectIndex)
+		return nil
+	}
+	suite.stack.Perform(suite.anAddition())
+	suite.stack.Perform(SetLevelObjectsCommand{Setter: setter, Changes: []LevelObjectChange{{ObjectIndex: 1}}})
+	suite.stack.Perform(suite.aRemovalOf(1))
+
+	suite.stack.Undo()
+	suite.stack.Undo()
+
+	assert.Equal(suite.T(), []int{1, 2}, changedIndices)
+}
+
+func (suite *LevelObjectPresenceCommandSuite) TestFurtherCommandsFollowObjectAddedAgainByRedo() {
+	suite.stack.Perform(suite.anAddition())
+	suite.stack.Perform(suite.aRemovalOf(1))
+	suite.stack.Undo()
+	suite.stack.Undo()
+
+	suite.stack.Redo()
+	suite.stack.Redo()
+
+	assert.Equal(suite.T(), []int{}, suite.store.indices())
+	assert.Equal(suite.T(), 4, suite.store.nextIndex)
+}
+
+func (suite *LevelObjectPresenceCommandSuite) TestRemappingEndsWithCommandThatAddedTheObject() {
+	suite.store.objects[2] = true
+	suite.store.nextIndex = 2
+	removalOfOther := suite.aRemovalOf(2)
+	suite.stack.Perform(removalOfOther)
+	suite.stack.Perform(suite.anAddition())
+	suite.stack.Perform(suite.aRemovalOf(2))
+
+	suite.stack.Undo()
+	suite.stack.Undo()
+
+	assert.Equal(suite.T(), []int{}, suite.store.indices())
+	assert.Equal(suite.T(), 2, removalOfOther.Index.Value())
+}
+
+func (suite *LevelObjectPresenceCommandSuite) anAddition() LevelObjectPresenceCommand {
+	return LevelObjectPresenceCommand{Adder: suite.store.add, Remover: suite.store.remove,
+		Index: new(ObjectIndex), Add: true}
+}
+
+func (suite *LevelObjectPresenceCommandSuite) aRemovalOf(index int) LevelObjectPresenceCommand {
+	return LevelObjectPresenceCommand{Adder: suite.store.add, Remover: suite.store.remove,
+		Index: NewObjectIndex(index)}
+}
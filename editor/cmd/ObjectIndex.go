@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+)
+
+var errObjectIndicesPending = fmt.Errorf("objects are still being added")
+
+// ObjectIndex keeps the index of a level object that is added and removed by commands.
+// The store assigns the index when the object is added, and reports it asynchronously.
+// An object that is added again may receive another index than it had before.
+// The zero value is an index that is not yet assigned.
+type ObjectIndex struct {
+	value      int
+	assigned   bool
+	pending    bool
+	reassigned []ObjectIndexChange
+}
+
+// NewObjectIndex returns an object index for an object that already exists.
+func NewObjectIndex(value int) *ObjectIndex {
+	return &ObjectIndex{value: value, assigned: true}
+}
+
+// Value returns the current index of the object. It is only valid if the index is assigned and not pending.
+func (index *ObjectIndex) Value() int {
+	return index.value
+}
+
+// Pending returns true while the store has not yet reported the index of the added object.
+func (index *ObjectIndex) Pending() bool {
+	return index.pending
+}
+
+func (index *ObjectIndex) request() {
+	index.pending = true
+}
+
+func (index *ObjectIndex) cancelRequest() {
+	index.pending = false
+}
+
+// fail forgets the index, as the object could not be added and does not exist.
+func (index *ObjectIndex) fail() {
+	index.pending = false
+	index.assigned = false
+}
+
+// takeReassignments returns the changes of the index since the last call, for given target.
+func (index *ObjectIndex) takeReassignments(target string) []ObjectIndexChange {
+	changes := index.reassigned
+	index.reassigned = nil
+	for position := range changes {
+		changes[position].Target = target
+	}
+	return changes
+}
+
+func (index *ObjectIndex) assign(value int) {
+	if index.assigned && (index.value != value) {
+		index.reassigned = append(index.reassigned, ObjectIndexChange{From: index.value, To: value})
+	}
+	index.value = value
+	index.assigned = true
+	index.pending = false
+}
+
+// ObjectIndexChange describes that an object of the target received another index.
+type ObjectIndexChange struct {
+	Target string
+	From   int
+	To     int
+}
+
+// ObjectIndexAssigner is implemented by commands that add level objects.
+type ObjectIndexAssigner interface {
+	// ObjectIndicesPending returns true while the index of an added object is not yet known.
+	ObjectIndicesPending() bool
+	// ReassignedObjectIndices returns the changes of the objects that were added again with another
+	// index, and forgets them.
+	ReassignedObjectIndices() []ObjectIndexChange
+}
+
+// ObjectIndexRemapper is implemented by commands that refer to level objects by their index.
+type ObjectIndexRemapper interface {
+	// RemapObjectIndices returns the command referring to the objects by their changed index.
+	// It also returns the changes that apply beyond this command. A change ends with the command
+	// that adds or removes the object, as its previous index refers to another object beyond.
+	RemapObjectIndices(changes []ObjectIndexChange) (Command, []ObjectIndexChange)
+}
+
+func objectIndicesPending(command Command) bool {
+	if assigner, isAssigner := command.(ObjectIndexAssigner); isAssigner {
+		return assigner.ObjectIndicesPending()
+	}
+	return false
+}
+
+func reassignedObjectIndices(command Command) []ObjectIndexChange {
+	if assigner, isAssigner := command.(ObjectIndexAssigner); isAssigner {
+		return assigner.ReassignedObjectIndices()
+	}
+	return nil
+}
+
+func remapObjectIndices(command Command, changes []ObjectIndexChange) (Command, []ObjectIndexChange) {
+	if remapper, isRemapper := command.(ObjectIndexRemapper); isRemapper {
+		return remapper.RemapObjectIndices(changes)
+	}
+	return command, changes
+}
+
+// remappedObjectIndex returns the index the object of given target and index has after the changes.
+func remappedObjectIndex(changes []ObjectIndexChange, target string, index int) int {
+	for _, change := range changes {
+		if (change.Target == target) && (change.From == index) {
+			return change.To
+		}
+	}
+	return index
+}
+
+// indexAssignment is a command that adds objects again, together with the stack entries that
+// may refer to these objects by their previous index.
+type indexAssignment struct {
+	command  Command
+	affected *stackEntry
+}
+
+// remapEntries lets the entries of the list refer to the objects by their changed index,
+// until all changes ended.
+func remapEntries(list *stackEntry, changes []ObjectIndexChange) {
+	for entry := list; (entry != nil) && (len(changes) > 0); entry = entry.link {
+		entry.cmd, changes = remapObjectIndices(entry.cmd, changes)
+	}
+}
@@ -0,0 +1,305 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/inkyblackness/res/audio"
+	"github.com/inkyblackness/shocked-model"
+)
+
+// Record is the serializable form of a command.
+// Instead of the setter functions, a record refers to the changed data by a target
+// identifier. The meaning of the target is up to the SetterResolver.
+type Record struct {
+	Type     string          `json:"type"`
+	Name     string          `json:"name,omitempty"`
+	Target   string          `json:"target,omitempty"`
+	OldValue json.RawMessage `json:"oldValue,omitempty"`
+	NewValue json.RawMessage `json:"newValue,omitempty"`
+	Commands []Record        `json:"commands,omitempty"`
+}
+
+const (
+	intRecordType                      = "int"
+	boolRecordType                     = "bool"
+	stringRecordType                   = "string"
+	bitmapRecordType                   = "bitmap"
+	audioRecordType                    = "audio"
+	levelTexturesRecordType            = "levelTextures"
+	tilesRecordType                    = "tiles"
+	levelObjectsRecordType             = "levelObjects"
+	levelObjectPresenceRecordType      = "levelObjectPresence"
+	electronicMessageRemovalRecordType = "electronicMessageRemoval"
+	compoundRecordType                 = "compound"
+)
+
+// Recorder is implemented by commands that can be stored in a journal.
+type Recorder interface {
+	// Record returns the serializable form of the command. It returns false if
+	// the command can not be recorded, for example because it has no target.
+	Record() (Record, bool)
+}
+
+// ViewCommand is implemented by commands that only change what the editor shows,
+// such as the active level. They do not change data and are not recorded.
+type ViewCommand interface {
+	Command
+	// ChangesViewOnly marks the command as view command.
+	ChangesViewOnly()
+}
+
+// SetterResolver provides the setter functions for the targets of recorded commands.
+type SetterResolver interface {
+	IntSetter(target string) (func(value int) error, error)
+	BooleanSetter(target string) (func(value bool) error, error)
+	StringSetter(target string) (func(value string) error, error)
+	BitmapSetter(target string) (func(bmp *model.RawBitmap) error, error)
+	AudioSetter(target string) (func(data audio.SoundData) error, error)
+	LevelTexturesSetter(target string) (func(textureIDs []int) error, error)
+	TilesSetter(target string) (func(x, y int, properties model.TileProperties) error, error)
+	LevelObjectsSetter(target string) (func(objectIndex int, properties model.LevelObjectProperties) error, error)
+	LevelObjectAdder(target string) (func(class int, properties model.LevelObjectProperties, onAdded func(objectIndex int), onFailed func()) error, error)
+	LevelObjectRemover(target string) (func(objectIndex int) error, error)
+	ElectronicMessageStore(target string) (ElectronicMessageStore, error)
+}
+
+// RecordOf returns the record of given command, if the command can be recorded.
+func RecordOf(command Command) (Record, bool) {
+	if recorder, isRecorder := command.(Recorder); isRecorder {
+		return recorder.Record()
+	}
+	return Record{}, false
+}
+
+func newValueRecord(recordType, name, target string, oldValue, newValue interface{}) (record Record, ok bool) {
+	if target == "" {
+		return
+	}
+	oldData, oldErr := json.Marshal(oldValue)
+	newData, newErr := json.Marshal(newValue)
+	if (oldErr != nil) || (newErr != nil) {
+		return
+	}
+	return Record{Type: recordType, Name: name, Target: target, OldValue: oldData, NewValue: newData}, true
+}
+
+// Restore creates a command from the record, with setters provided by the given resolver.
+func (record Record) Restore(resolver SetterResolver) (Command, error) {
+	switch record.Type {
+	case intRecordType:
+		return record.restoreInt(resolver)
+	case boolRecordType:
+		return record.restoreBoolean(resolver)
+	case stringRecordType:
+		return record.restoreString(resolver)
+	case bitmapRecordType:
+		return record.restoreBitmap(resolver)
+	case audioRecordType:
+		return record.restoreAudio(resolver)
+	case levelTexturesRecordType:
+		return record.restoreLevelTextures(resolver)
+	case tilesRecordType:
+		return record.restoreTiles(resolver)
+	case levelObjectsRecordType:
+		return record.restoreLevelObjects(resolver)
+	case levelObjectPresenceRecordType:
+		return record.restoreLevelObjectPresence(resolver)
+	case electronicMessageRemovalRecordType:
+		return record.restoreElectronicMessageRemoval(resolver)
+	case compoundRecordType:
+		return record.restoreCompound(resolver)
+	}
+	return nil, fmt.Errorf("unknown record type <%v>", record.Type)
+}
+
+func (record Record) decodeValues(oldValue, newValue interface{}) error {
+	err := json.Unmarshal(record.OldValue, oldValue)
+	if err == nil {
+		err = json.Unmarshal(record.NewValue, newValue)
+	}
+	return err
+}
+
+func (record Record) restoreInt(resolver SetterResolver) (Command, error) {
+	command := SetIntPropertyCommand{Name: record.Name, Target: record.Target}
+	err := record.decodeValues(&command.OldValue, &command.NewValue)
+	if err == nil {
+		command.Setter, err = resolver.IntSetter(record.Target)
+	}
+	return command, err
+}
+
+func (record Record) restoreBoolean(resolver SetterResolver) (Command, error) {
+	command := SetBooleanPropertyCommand{Name: record.Name, Target: record.Target}
+	err := record.decodeValues(&command.OldValue, &command.NewValue)
+	if err == nil {
+		command.Setter, err = resolver.BooleanSetter(record.Target)
+	}
+	return command, err
+}
+
+func (record Record) restoreString(resolver SetterResolver) (Command, error) {
+	command := SetStringPropertyCommand{Name: record.Name, Target: record.Target}
+	err := record.decodeValues(&command.OldValue, &command.NewValue)
+	if err == nil {
+		command.Setter, err = resolver.StringSetter(record.Target)
+	}
+	return command, err
+}
+
+func (record Record) restoreBitmap(resolver SetterResolver) (Command, error) {
+	command := SetBitmapCommand{Name: record.Name, Target: record.Target}
+	err := record.decodeValues(&command.OldValue, &command.NewValue)
+	if err == nil {
+		command.Setter, err = resolver.BitmapSetter(record.Target)
+	}
+	return command, err
+}
+
+func (record Record) restoreAudio(resolver SetterResolver) (Command, error) {
+	command := SetAudioCommand{Name: record.Name, Target: record.Target}
+	var oldSound, newSound *recordedSound
+	err := record.decodeValues(&oldSound, &newSound)
+	if err == nil {
+		command.OldValue = oldSound.soundData()
+		command.NewValue = newSound.soundData()
+		command.Setter, err = resolver.AudioSetter(record.Target)
+	}
+	return command, err
+}
+
+func (record Record) restoreLevelTextures(resolver SetterResolver) (Command, error) {
+	command := SetLevelTexturesCommand{Target: record.Target}
+	err := record.decodeValues(&command.OldTextureIDs, &command.NewTextureIDs)
+	if err == nil {
+		command.Setter, err = resolver.LevelTexturesSetter(record.Target)
+	}
+	return command, err
+}
+
+func (record Record) restoreTiles(resolver SetterResolver) (Command, error) {
+	command := SetTilesCommand{Name: record.Name, Target: record.Target}
+	var oldTiles, newTiles []recordedTile
+	err := record.decodeValues(&oldTiles, &newTiles)
+	if (err == nil) && (len(oldTiles) != len(newTiles)) {
+		err = fmt.Errorf("mismatching tile lists")
+	}
+	if err == nil {
+		command.Changes = make([]TileChange, len(newTiles))
+		for index, newTile := range newTiles {
+			oldTile := oldTiles[index]
+			command.Changes[index] = TileChange{X: newTile.X, Y: newTile.Y,
+				OldProperties: oldTile.Properties, NewProperties: newTile.Properties}
+		}
+		command.Setter, err = resolver.TilesSetter(record.Target)
+	}
+	return command, err
+}
+
+func (record Record) restoreLevelObjects(resolver SetterResolver) (Command, error) {
+	command := SetLevelObjectsCommand{Name: record.Name, Target: record.Target}
+	var oldObjects, newObjects []recordedLevelObject
+	err := record.decodeValues(&oldObjects, &newObjects)
+	if (err == nil) && (len(oldObjects) != len(newObjects)) {
+		err = fmt.Errorf("mismatching object lists")
+	}
+	if err == nil {
+		command.Changes = make([]LevelObjectChange, len(newObjects))
+		for index, newObject := range newObjects {
+			command.Changes[index] = LevelObjectChange{ObjectIndex: newObject.Index,
+				OldProperties: oldObjects[index].Properties, NewProperties: newObject.Properties}
+		}
+		command.Setter, err = resolver.LevelObjectsSetter(record.Target)
+	}
+	return command, err
+}
+
+func (record Record) restoreLevelObjectPresence(resolver SetterResolver) (Command, error) {
+	command := LevelObjectPresenceCommand{Name: record.Name, Target: record.Target}
+	var oldObject, newObject *recordedPresentObject
+	err := record.decodeValues(&oldObject, &newObject)
+	if (err == nil) && ((oldObject == nil) == (newObject == nil)) {
+		err = fmt.Errorf("object must be either added or removed")
+	}
+	if err == nil {
+		object := oldObject
+		if newObject != nil {
+			object = newObject
+			command.Add = true
+		}
+		command.Class = object.Class
+		command.Properties = object.Properties
+		command.Index = NewObjectIndex(object.Index)
+		command.Adder, err = resolver.LevelObjectAdder(record.Target)
+	}
+	if err == nil {
+		command.Remover, err = resolver.LevelObjectRemover(record.Target)
+	}
+	return command, err
+}
+
+func (record Record) restoreElectronicMessageRemoval(resolver SetterResolver) (Command, error) {
+	command := RemoveElectronicMessageCommand{Target: record.Target, RestoreState: func() {}}
+	var oldMessage, newMessage *recordedElectronicMessage
+	err := record.decodeValues(&oldMessage, &newMessage)
+	if (err == nil) && (oldMessage == nil) {
+		err = fmt.Errorf("removed message is missing")
+	}
+	if err == nil {
+		command.Properties = oldMessage.Properties
+		for lang, sound := range oldMessage.Audio {
+			command.Audio[lang] = sound.soundData()
+		}
+		command.Store, err = resolver.ElectronicMessageStore(record.Target)
+	}
+	return command, err
+}
+
+func (record Record) restoreCompound(resolver SetterResolver) (Command, error) {
+	command := CompoundCommand{Name: record.Name, Commands: make([]Command, len(record.Commands))}
+	for index, nested := range record.Commands {
+		restored, err := nested.Restore(resolver)
+		if err != nil {
+			return nil, err
+		}
+		command.Commands[index] = restored
+	}
+	return command, nil
+}
+
+// recordedSound is the serializable form of sound data.
+type recordedSound struct {
+	SampleRate float32 `json:"sampleRate"`
+	Samples    []byte  `json:"samples"`
+}
+
+func newRecordedSound(data audio.SoundData) *recordedSound {
+	if data == nil {
+		return nil
+	}
+	return &recordedSound{SampleRate: data.SampleRate(), Samples: data.Samples(0, data.SampleCount())}
+}
+
+func (sound *recordedSound) soundData() audio.SoundData {
+	if sound == nil {
+		return nil
+	}
+	return restoredSoundData{sound}
+}
+
+type restoredSoundData struct {
+	sound *recordedSound
+}
+
+func (data restoredSoundData) SampleRate() float32 {
+	return data.sound.SampleRate
+}
+
+func (data restoredSoundData) SampleCount() int {
+	return len(data.sound.Samples)
+}
+
+func (data restoredSoundData) Samples(from, to int) []byte {
+	return data.sound.Samples[from:to]
+}
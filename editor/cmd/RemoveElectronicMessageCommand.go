@@ -13,7 +13,9 @@ type ElectronicMessageStore interface {
 }
 
 // RemoveElectronicMessageCommand removes and restores an electronic message.
+// The optional Target identifies the removed message for journaling.
 type RemoveElectronicMessageCommand struct {
+	Target       string
 	RestoreState func()
 	Store        ElectronicMessageStore
 
@@ -39,3 +41,24 @@ func (cmd RemoveElectronicMessageCommand) Undo() error {
 	}
 	return nil
 }
+
+// Description returns a text of the change.
+func (cmd RemoveElectronicMessageCommand) Description() string {
+	return "Remove electronic message"
+}
+
+// Record returns the serializable form of the command. The removed message is the old value.
+// Commands without target can not be recorded.
+func (cmd RemoveElectronicMessageCommand) Record() (Record, bool) {
+	message := &recordedElectronicMessage{Properties: cmd.Properties}
+	for lang := 0; lang < model.LanguageCount; lang++ {
+		message.Audio[lang] = newRecordedSound(cmd.Audio[lang])
+	}
+	return newValueRecord(electronicMessageRemovalRecordType, "", cmd.Target, message, nil)
+}
+
+// recordedElectronicMessage is the serializable form of a removed electronic message.
+type recordedElectronicMessage struct {
+	Properties model.ElectronicMessage             `json:"properties"`
+	Audio      [model.LanguageCount]*recordedSound `json:"audio"`
+}
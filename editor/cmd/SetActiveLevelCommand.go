@@ -1,5 +1,9 @@
 package cmd
 
+import (
+	"fmt"
+)
+
 // SetActiveLevelCommand sets the currently active level.
 type SetActiveLevelCommand struct {
 	Setter   func(levelID int) error
@@ -16,3 +20,11 @@ func (cmd SetActiveLevelCommand) Do() error {
 func (cmd SetActiveLevelCommand) Undo() error {
 	return cmd.Setter(cmd.OldValue)
 }
+
+// Description returns a text of the change.
+func (cmd SetActiveLevelCommand) Description() string {
+	return fmt.Sprintf("Select level %v", cmd.NewValue)
+}
+
+// ChangesViewOnly marks the command as view command, it is not recorded in a journal.
+func (cmd SetActiveLevelCommand) ChangesViewOnly() {}
@@ -1,9 +1,17 @@
 package cmd
 
-import "github.com/inkyblackness/res/audio"
+import (
+	"fmt"
+
+	"github.com/inkyblackness/res/audio"
+)
 
 // SetAudioCommand changes an audio clip.
+// The optional Name identifies the changed property for the user, the optional
+// Target identifies the changed data for journaling.
 type SetAudioCommand struct {
+	Name     string
+	Target   string
 	Setter   func(data audio.SoundData) error
 	OldValue audio.SoundData
 	NewValue audio.SoundData
@@ -18,3 +26,13 @@ func (cmd SetAudioCommand) Do() error {
 func (cmd SetAudioCommand) Undo() error {
 	return cmd.Setter(cmd.OldValue)
 }
+
+// Description returns a text of the change.
+func (cmd SetAudioCommand) Description() string {
+	return fmt.Sprintf("Change %v", propertyName(cmd.Name, "audio"))
+}
+
+// Record returns the serializable form of the command. Commands without target can not be recorded.
+func (cmd SetAudioCommand) Record() (Record, bool) {
+	return newValueRecord(audioRecordType, cmd.Name, cmd.Target, newRecordedSound(cmd.OldValue), newRecordedSound(cmd.NewValue))
+}
@@ -1,9 +1,17 @@
 package cmd
 
-import "github.com/inkyblackness/shocked-model"
+import (
+	"fmt"
 
-// SetBitmapCommand changes an audio clip.
+	"github.com/inkyblackness/shocked-model"
+)
+
+// SetBitmapCommand changes a bitmap.
+// The optional Name identifies the changed property for the user, the optional
+// Target identifies the changed data for journaling.
 type SetBitmapCommand struct {
+	Name     string
+	Target   string
 	Setter   func(bmp *model.RawBitmap) error
 	OldValue *model.RawBitmap
 	NewValue *model.RawBitmap
@@ -18,3 +26,13 @@ func (cmd SetBitmapCommand) Do() error {
 func (cmd SetBitmapCommand) Undo() error {
 	return cmd.Setter(cmd.OldValue)
 }
+
+// Description returns a text of the change.
+func (cmd SetBitmapCommand) Description() string {
+	return fmt.Sprintf("Change %v", propertyName(cmd.Name, "bitmap"))
+}
+
+// Record returns the serializable form of the command. Commands without target can not be recorded.
+func (cmd SetBitmapCommand) Record() (Record, bool) {
+	return newValueRecord(bitmapRecordType, cmd.Name, cmd.Target, cmd.OldValue, cmd.NewValue)
+}
@@ -1,7 +1,15 @@
 package cmd
 
+import (
+	"fmt"
+)
+
 // SetBooleanPropertyCommand changes a boolean property.
+// The optional Name identifies the changed property for the user, the optional
+// Target identifies the changed data for journaling.
 type SetBooleanPropertyCommand struct {
+	Name     string
+	Target   string
 	Setter   func(value bool) error
 	OldValue bool
 	NewValue bool
@@ -16,3 +24,13 @@ func (cmd SetBooleanPropertyCommand) Do() error {
 func (cmd SetBooleanPropertyCommand) Undo() error {
 	return cmd.Setter(cmd.OldValue)
 }
+
+// Description returns a text of the change.
+func (cmd SetBooleanPropertyCommand) Description() string {
+	return fmt.Sprintf("Set %v to %v", propertyName(cmd.Name, "value"), cmd.NewValue)
+}
+
+// Record returns the serializable form of the command. Commands without target can not be recorded.
+func (cmd SetBooleanPropertyCommand) Record() (Record, bool) {
+	return newValueRecord(boolRecordType, cmd.Name, cmd.Target, cmd.OldValue, cmd.NewValue)
+}
@@ -1,5 +1,9 @@
 package cmd
 
+import (
+	"fmt"
+)
+
 // SetEditorModeCommand changes the current editor mode.
 type SetEditorModeCommand struct {
 	Activator func(name string)
@@ -18,3 +22,11 @@ func (cmd SetEditorModeCommand) Undo() error {
 	cmd.Activator(cmd.OldMode)
 	return nil
 }
+
+// Description returns a text of the change.
+func (cmd SetEditorModeCommand) Description() string {
+	return fmt.Sprintf("Switch to mode %v", cmd.NewMode)
+}
+
+// ChangesViewOnly marks the command as view command, it is not recorded in a journal.
+func (cmd SetEditorModeCommand) ChangesViewOnly() {}
@@ -1,7 +1,15 @@
 package cmd
 
+import (
+	"fmt"
+)
+
 // SetIntPropertyCommand changes an integer property.
+// The optional Name identifies the changed property for the user, the optional
+// Target identifies the changed data for journaling.
 type SetIntPropertyCommand struct {
+	Name     string
+	Target   string
 	Setter   func(value int) error
 	OldValue int
 	NewValue int
@@ -16,3 +24,13 @@ func (cmd SetIntPropertyCommand) Do() error {
 func (cmd SetIntPropertyCommand) Undo() error {
 	return cmd.Setter(cmd.OldValue)
 }
+
+// Description returns a text of the change.
+func (cmd SetIntPropertyCommand) Description() string {
+	return fmt.Sprintf("Set %v from %v to %v", propertyName(cmd.Name, "value"), cmd.OldValue, cmd.NewValue)
+}
+
+// Record returns the serializable form of the command. Commands without target can not be recorded.
+func (cmd SetIntPropertyCommand) Record() (Record, bool) {
+	return newValueRecord(intRecordType, cmd.Name, cmd.Target, cmd.OldValue, cmd.NewValue)
+}
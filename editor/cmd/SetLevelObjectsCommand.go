@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/inkyblackness/shocked-model"
+)
+
+// LevelObjectChange describes the properties of one level object before and after a change.
+type LevelObjectChange struct {
+	ObjectIndex   int
+	OldProperties model.LevelObjectProperties
+	NewProperties model.LevelObjectProperties
+}
+
+// SetLevelObjectsCommand changes the properties of several level objects at once.
+// The optional Name identifies the changed property for the user, the optional
+// Target identifies the changed level for journaling.
+type SetLevelObjectsCommand struct {
+	Name    string
+	Target  string
+	Setter  func(objectIndex int, properties model.LevelObjectProperties) error
+	Changes []LevelObjectChange
+}
+
+// Do sets the new properties of all objects.
+func (cmd SetLevelObjectsCommand) Do() (err error) {
+	for index := 0; (err == nil) && (index < len(cmd.Changes)); index++ {
+		change := &cmd.Changes[index]
+		err = cmd.Setter(change.ObjectIndex, change.NewProperties)
+	}
+	return
+}
+
+// Undo sets the old properties of all objects, in reverse order.
+func (cmd SetLevelObjectsCommand) Undo() (err error) {
+	for index := len(cmd.Changes) - 1; (err == nil) && (index >= 0); index-- {
+		change := &cmd.Changes[index]
+		err = cmd.Setter(change.ObjectIndex, change.OldProperties)
+	}
+	return
+}
+
+// RemapObjectIndices returns the command changing the objects by their changed index.
+// The changes apply beyond this command, as it doesn't add or remove objects.
+func (cmd SetLevelObjectsCommand) RemapObjectIndices(changes []ObjectIndexChange) (Command, []ObjectIndexChange) {
+	remapped := cmd
+	remapped.Changes = make([]LevelObjectChange, len(cmd.Changes))
+	for index, change := range cmd.Changes {
+		change.ObjectIndex = remappedObjectIndex(changes, cmd.Target, change.ObjectIndex)
+		remapped.Changes[index] = change
+	}
+	return remapped, changes
+}
+
+// Description returns a text of the change.
+func (cmd SetLevelObjectsCommand) Description() string {
+	return fmt.Sprintf("Change %v", propertyName(cmd.Name, fmt.Sprintf("%d objects", len(cmd.Changes))))
+}
+
+// Record returns the serializable form of the command. Commands without target can not be recorded.
+func (cmd SetLevelObjectsCommand) Record() (Record, bool) {
+	oldObjects := make([]recordedLevelObject, len(cmd.Changes))
+	newObjects := make([]recordedLevelObject, len(cmd.Changes))
+	for index, change := range cmd.Changes {
+		oldObjects[index] = recordedLevelObject{Index: change.ObjectIndex, Properties: change.OldProperties}
+		newObjects[index] = recordedLevelObject{Index: change.ObjectIndex, Properties: change.NewProperties}
+	}
+	return newValueRecord(levelObjectsRecordType, cmd.Name, cmd.Target, oldObjects, newObjects)
+}
+
+// recordedLevelObject is the serializable form of the properties of one level object.
+type recordedLevelObject struct {
+	Index      int                         `json:"index"`
+	Properties model.LevelObjectProperties `json:"properties"`
+}
@@ -1,7 +1,9 @@
 package cmd
 
 // SetLevelTexturesCommand sets the textures of a level.
+// The optional Target identifies the changed data for journaling.
 type SetLevelTexturesCommand struct {
+	Target        string
 	Setter        func(textureIDs []int) error
 	OldTextureIDs []int
 	NewTextureIDs []int
@@ -16,3 +18,13 @@ func (cmd SetLevelTexturesCommand) Do() error {
 func (cmd SetLevelTexturesCommand) Undo() error {
 	return cmd.Setter(cmd.OldTextureIDs)
 }
+
+// Description returns a text of the change.
+func (cmd SetLevelTexturesCommand) Description() string {
+	return "Change level textures"
+}
+
+// Record returns the serializable form of the command. Commands without target can not be recorded.
+func (cmd SetLevelTexturesCommand) Record() (Record, bool) {
+	return newValueRecord(levelTexturesRecordType, "", cmd.Target, cmd.OldTextureIDs, cmd.NewTextureIDs)
+}
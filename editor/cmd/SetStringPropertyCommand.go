@@ -1,7 +1,15 @@
 package cmd
 
+import (
+	"fmt"
+)
+
 // SetStringPropertyCommand changes a textual property.
+// The optional Name identifies the changed property for the user, the optional
+// Target identifies the changed data for journaling.
 type SetStringPropertyCommand struct {
+	Name     string
+	Target   string
 	Setter   func(value string) error
 	OldValue string
 	NewValue string
@@ -16,3 +24,13 @@ func (cmd SetStringPropertyCommand) Do() error {
 func (cmd SetStringPropertyCommand) Undo() error {
 	return cmd.Setter(cmd.OldValue)
 }
+
+// Description returns a text of the change.
+func (cmd SetStringPropertyCommand) Description() string {
+	return fmt.Sprintf("Change %v", propertyName(cmd.Name, "text"))
+}
+
+// Record returns the serializable form of the command. Commands without target can not be recorded.
+func (cmd SetStringPropertyCommand) Record() (Record, bool) {
+	return newValueRecord(stringRecordType, cmd.Name, cmd.Target, cmd.OldValue, cmd.NewValue)
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/inkyblackness/shocked-model"
+)
+
+// TileChange describes the properties of one tile before and after a change.
+type TileChange struct {
+	X             int
+	Y             int
+	OldProperties model.TileProperties
+	NewProperties model.TileProperties
+}
+
+// SetTilesCommand changes the properties of several tiles at once.
+// The optional Name identifies the changed property for the user, the optional
+// Target identifies the changed level for journaling.
+type SetTilesCommand struct {
+	Name    string
+	Target  string
+	Setter  func(x, y int, properties model.TileProperties) error
+	Changes []TileChange
+}
+
+// Do sets the new properties of all tiles.
+func (cmd SetTilesCommand) Do() (err error) {
+	for index := 0; (err == nil) && (index < len(cmd.Changes)); index++ {
+		change := &cmd.Changes[index]
+		err = cmd.Setter(change.X, change.Y, change.NewProperties)
+	}
+	return
+}
+
+// Undo sets the old properties of all tiles, in reverse order.
+func (cmd SetTilesCommand) Undo() (err error) {
+	for index := len(cmd.Changes) - 1; (err == nil) && (index >= 0); index-- {
+		change := &cmd.Changes[index]
+		err = cmd.Setter(change.X, change.Y, change.OldProperties)
+	}
+	return
+}
+
+// Description returns a text of the change.
+func (cmd SetTilesCommand) Description() string {
+	return fmt.Sprintf("Change %v", propertyName(cmd.Name, fmt.Sprintf("%d tiles", len(cmd.Changes))))
+}
+
+// Record returns the serializable form of the command. Commands without target can not be recorded.
+func (cmd SetTilesCommand) Record() (Record, bool) {
+	oldTiles := make([]recordedTile, len(cmd.Changes))
+	newTiles := make([]recordedTile, len(cmd.Changes))
+	for index, change := range cmd.Changes {
+		oldTiles[index] = recordedTile{X: change.X, Y: change.Y, Properties: change.OldProperties}
+		newTiles[index] = recordedTile{X: change.X, Y: change.Y, Properties: change.NewProperties}
+	}
+	return newValueRecord(tilesRecordType, cmd.Name, cmd.Target, oldTiles, newTiles)
+}
+
+// recordedTile is the serializable form of the properties of one tile.
+type recordedTile struct {
+	X          int                  `json:"x"`
+	Y          int                  `json:"y"`
+	Properties model.TileProperties `json:"properties"`
+}
@@ -1,34 +1,150 @@
 package cmd
 
+import (
+	"fmt"
+)
+
+var errTransactionInProgress = fmt.Errorf("transaction in progress")
+
 type stackEntry struct {
 	link *stackEntry
 	cmd  Command
 }
 
+func (entry *stackEntry) commands() []Command {
+	var result []Command
+	for ; entry != nil; entry = entry.link {
+		result = append(result, entry.cmd)
+	}
+	return result
+}
+
 // Stack describes a list of commands. The stack allows to sequentially
 // undo and redo stacked commands.
 // It essentially stores two lists: a list of commands to undo, and
 // another of commands to redo.
 // Modifying stack functions will panic if they are called while already in use.
+//
+// Several commands can be grouped into one undo step with a transaction:
+// Commands performed between Begin() and Commit() are put on the stack
+// as one CompoundCommand. As with CompoundCommand, a transaction only fails for
+// errors the commands return, not for failing requests to the data store.
+//
+// Commands that add level objects learn the index of the objects only once the store reported it.
+// Such commands can not be undone or redone before. Should an object receive another index when it
+// is added again, the commands referring to its previous index are remapped, see ObjectIndexRemapper.
 type Stack struct {
-	lockedBy string
-	undoList *stackEntry
-	redoList *stackEntry
+	lockedBy    string
+	undoList    *stackEntry
+	redoList    *stackEntry
+	transaction *transaction
+	assignments []indexAssignment
+}
+
+type transaction struct {
+	performed []Command
+	err       error
 }
 
 // Perform executes the given command and puts it on the stack
 // if the command was successful.
 // This function also clears the list of commands to be redone.
+//
+// During a transaction, the command is only remembered for the transaction.
+// Should the command fail, all commands performed so far within the transaction
+// are undone, and the transaction fails as a whole. Any further command of a
+// failed transaction is ignored and returns the original error.
 func (stack *Stack) Perform(cmd Command) error {
 	stack.lock("Perform")
 	defer stack.unlock()
 
+	stack.settleObjectIndices()
+	if stack.transaction != nil {
+		return stack.transaction.perform(cmd)
+	}
 	err := cmd.Do()
 	if err != nil {
 		return err
 	}
+	stack.push(cmd)
+	return nil
+}
+
+func (stack *Stack) push(cmd Command) {
 	stack.undoList = &stackEntry{stack.undoList, cmd}
 	stack.redoList = nil
+}
+
+// InTransaction returns true if a transaction was begun and not yet finished.
+func (stack *Stack) InTransaction() bool {
+	return stack.transaction != nil
+}
+
+// Begin starts a transaction. Until either Commit() or Rollback() is called,
+// all performed commands are collected to be one single undo step.
+// Transactions can not be nested; Begin panics if a transaction is already in progress.
+func (stack *Stack) Begin() {
+	stack.lock("Begin")
+	defer stack.unlock()
+
+	if stack.transaction != nil {
+		panic("Transaction already in progress")
+	}
+	stack.transaction = &transaction{}
+}
+
+// Commit finishes the current transaction and puts all performed commands
+// as one entry on the stack. If no command was performed, the stack remains unchanged.
+// If the transaction failed, the error of the failing command is returned.
+// Commit panics if there is no transaction in progress.
+func (stack *Stack) Commit() error {
+	stack.lock("Commit")
+	defer stack.unlock()
+
+	current := stack.finishTransaction()
+	if current.err != nil {
+		return current.err
+	}
+	if len(current.performed) == 1 {
+		stack.push(current.performed[0])
+	} else if len(current.performed) > 1 {
+		stack.push(CompoundCommand{Commands: current.performed})
+	}
+	return nil
+}
+
+// Rollback finishes the current transaction by undoing all commands performed
+// so far within it. The stack remains unchanged.
+// Rollback panics if there is no transaction in progress.
+func (stack *Stack) Rollback() {
+	stack.lock("Rollback")
+	defer stack.unlock()
+
+	current := stack.finishTransaction()
+	undoInReverse(current.performed)
+}
+
+func (stack *Stack) finishTransaction() *transaction {
+	current := stack.transaction
+	if current == nil {
+		panic("No transaction in progress")
+	}
+	stack.transaction = nil
+	return current
+}
+
+func (current *transaction) perform(cmd Command) error {
+	if current.err != nil {
+		return current.err
+	}
+	err := cmd.Do()
+	if err != nil {
+		undoInReverse(current.performed)
+		current.performed = nil
+		current.err = err
+		return err
+	}
+	current.performed = append(current.performed, cmd)
 	return nil
 }
 
@@ -41,14 +157,22 @@ func (stack *Stack) CanUndo() bool {
 // If there is no further command to undo, nothing happens.
 // An error is returned if the command failed. In this case, the stack is
 // unchanged and a further attempt to undo will try the same command again.
+// Undo is not possible during a transaction, nor while the command waits for the index of added objects.
 func (stack *Stack) Undo() error {
 	stack.lock("Undo")
 	defer stack.unlock()
 
+	if stack.transaction != nil {
+		return errTransactionInProgress
+	}
+	stack.settleObjectIndices()
 	if stack.undoList == nil {
 		return nil
 	}
 	entry := stack.undoList
+	if objectIndicesPending(entry.cmd) {
+		return errObjectIndicesPending
+	}
 	err := entry.cmd.Undo()
 	if err != nil {
 		return err
@@ -56,9 +180,20 @@ func (stack *Stack) Undo() error {
 	stack.undoList = entry.link
 	entry.link = stack.redoList
 	stack.redoList = entry
+	stack.trackObjectIndices(entry.cmd, stack.undoList)
 	return nil
 }
 
+// UndoEntries returns the commands that can be undone, starting with the most recent one.
+func (stack *Stack) UndoEntries() []Command {
+	return stack.undoList.commands()
+}
+
+// RedoEntries returns the commands that can be redone, starting with the next one.
+func (stack *Stack) RedoEntries() []Command {
+	return stack.redoList.commands()
+}
+
 // CanRedo returns true if there is at least one more command that can be redone.
 func (stack *Stack) CanRedo() bool {
 	return stack.redoList != nil
@@ -68,14 +203,22 @@ func (stack *Stack) CanRedo() bool {
 // If there is no further command to redo, nothing happens.
 // An error is returned if the command failed. In this case, the stack is
 // unchanged and a further attempt to redo will try the same command again.
+// Redo is not possible during a transaction, nor while the command waits for the index of added objects.
 func (stack *Stack) Redo() error {
 	stack.lock("Redo")
 	defer stack.unlock()
 
+	if stack.transaction != nil {
+		return errTransactionInProgress
+	}
+	stack.settleObjectIndices()
 	if stack.redoList == nil {
 		return nil
 	}
 	entry := stack.redoList
+	if objectIndicesPending(entry.cmd) {
+		return errObjectIndicesPending
+	}
 	err := entry.cmd.Do()
 	if err != nil {
 		return err
@@ -83,9 +226,44 @@ func (stack *Stack) Redo() error {
 	stack.redoList = entry.link
 	entry.link = stack.undoList
 	stack.undoList = entry
+	stack.trackObjectIndices(entry.cmd, stack.redoList)
 	return nil
 }
 
+// ObjectIndicesPending returns true while any command waits for the index of objects it added.
+func (stack *Stack) ObjectIndicesPending() bool {
+	for _, list := range []*stackEntry{stack.undoList, stack.redoList} {
+		for entry := list; entry != nil; entry = entry.link {
+			if objectIndicesPending(entry.cmd) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// trackObjectIndices remembers a command that may have added objects again, with the entries that
+// may refer to them by their previous index: the older ones when undone, the further ones when redone.
+func (stack *Stack) trackObjectIndices(command Command, affected *stackEntry) {
+	if _, isAssigner := command.(ObjectIndexAssigner); isAssigner {
+		stack.assignments = append(stack.assignments, indexAssignment{command: command, affected: affected})
+	}
+}
+
+// settleObjectIndices remaps the entries referring to objects that were added again,
+// for all commands of which the store reported the new indices.
+func (stack *Stack) settleObjectIndices() {
+	var pending []indexAssignment
+	for _, assignment := range stack.assignments {
+		if objectIndicesPending(assignment.command) {
+			pending = append(pending, assignment)
+		} else {
+			remapEntries(assignment.affected, reassignedObjectIndices(assignment.command))
+		}
+	}
+	stack.assignments = pending
+}
+
 func (stack *Stack) lock(by string) {
 	if stack.lockedBy != "" {
 		panic("Stack already in use by <" + stack.lockedBy + ">")
@@ -221,6 +221,91 @@ func (suite *StackSuite) TestRedoPanicsIfStackIsInUse() {
 	suite.assertPanics(callRedo)
 }
 
+func (suite *StackSuite) TestCommitPutsTransactionAsOneEntryOnStack() {
+	suite.givenTransactionWasBegun()
+	suite.givenCommandWasPerformed("cmd1")
+	suite.givenCommandWasPerformed("cmd2")
+	suite.whenCommitting()
+
+	suite.whenUndoing()
+	suite.thenCommandShouldHaveBeenReverted("cmd1")
+	suite.thenCommandShouldHaveBeenReverted("cmd2")
+	suite.thenStackShouldNotSupportUndo()
+}
+
+func (suite *StackSuite) TestCommandsOfTransactionAreExecutedImmediately() {
+	suite.givenTransactionWasBegun()
+	suite.whenPerforming(suite.aCommand("cmd1"))
+	suite.thenCommandShouldHaveBeenExecuted("cmd1")
+}
+
+func (suite *StackSuite) TestUndoIsNotPossibleDuringTransaction() {
+	suite.givenCommandWasPerformed("cmd1")
+	suite.givenTransactionWasBegun()
+
+	err := suite.stack.Undo()
+	assert.NotNil(suite.T(), err, "Error expected")
+	suite.thenCommandShouldHaveBeenRevertedTimes("cmd1", 0)
+}
+
+func (suite *StackSuite) TestRollbackUndoesCommandsOfTransaction() {
+	suite.givenTransactionWasBegun()
+	suite.givenCommandWasPerformed("cmd1")
+	suite.whenRollingBack()
+
+	suite.thenCommandShouldHaveBeenReverted("cmd1")
+	suite.thenStackShouldNotSupportUndo()
+}
+
+func (suite *StackSuite) TestFailingCommandUndoesTransactionAutomatically() {
+	suite.givenTransactionWasBegun()
+	suite.givenCommandWasPerformed("cmd1")
+	suite.whenPerforming(suite.aCommandReturningError())
+
+	suite.thenCommandShouldHaveBeenReverted("cmd1")
+}
+
+func (suite *StackSuite) TestCommitOfFailedTransactionReturnsErrorAndLeavesStackUnchanged() {
+	err := fmt.Errorf("failing")
+	suite.givenTransactionWasBegun()
+	suite.givenCommandWasPerformed("cmd1")
+	suite.whenPerforming(suite.aCommandReturning(err))
+	suite.whenPerforming(suite.aCommand("cmd2"))
+
+	assert.Equal(suite.T(), err, suite.stack.Commit())
+	suite.thenCommandShouldHaveBeenExecutedTimes("cmd2", 0)
+	suite.thenStackShouldNotSupportUndo()
+}
+
+func (suite *StackSuite) TestCommitOfEmptyTransactionKeepsRedoList() {
+	suite.givenCommandWasPerformed("cmd1")
+	suite.givenUndoWasCalledTimes(1)
+	suite.givenTransactionWasBegun()
+	suite.whenCommitting()
+
+	suite.thenStackShouldSupportRedo()
+}
+
+func (suite *StackSuite) TestBeginPanicsIfTransactionIsInProgress() {
+	suite.givenTransactionWasBegun()
+
+	assert.Panics(suite.T(), func() { suite.stack.Begin() })
+}
+
+func (suite *StackSuite) TestCommitPanicsWithoutTransaction() {
+	assert.Panics(suite.T(), func() { suite.stack.Commit() })
+}
+
+func (suite *StackSuite) TestEntriesListCommandsFromCurrentPosition() {
+	suite.givenCommandWasPerformed("cmd1")
+	suite.givenCommandWasPerformed("cmd2")
+	suite.givenCommandWasPerformed("cmd3")
+	suite.givenUndoWasCalledTimes(1)
+
+	assert.Equal(suite.T(), []Command{suite.pastCommand("cmd2"), suite.pastCommand("cmd1")}, suite.stack.UndoEntries())
+	assert.Equal(suite.T(), []Command{suite.pastCommand("cmd3")}, suite.stack.RedoEntries())
+}
+
 func (suite *StackSuite) assertPanics(taskFor func(string) func()) {
 	cmd1 := suite.aCommandExecuting("cmd1", taskFor("Perform"))
 	suite.whenPerforming(cmd1)
@@ -260,6 +345,18 @@ func (suite *StackSuite) givenCommandExecutes(name string, task func()) {
 	cmd.task = task
 }
 
+func (suite *StackSuite) givenTransactionWasBegun() {
+	suite.stack.Begin()
+}
+
+func (suite *StackSuite) whenCommitting() {
+	suite.stack.Commit()
+}
+
+func (suite *StackSuite) whenRollingBack() {
+	suite.stack.Rollback()
+}
+
 func (suite *StackSuite) whenUndoing() {
 	suite.stack.Undo()
 }
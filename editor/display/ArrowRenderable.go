@@ -0,0 +1,132 @@
+package display
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/inkyblackness/shocked-client/graphics"
+	"github.com/inkyblackness/shocked-client/opengl"
+)
+
+var arrowVertexShaderSource = `
+#version 150
+precision mediump float;
+
+in vec3 vertexPosition;
+
+uniform mat4 viewMatrix;
+uniform mat4 projectionMatrix;
+
+void main(void) {
+	gl_Position = projectionMatrix * viewMatrix * vec4(vertexPosition, 1.0);
+}
+`
+
+var arrowFragmentShaderSource = `
+#version 150
+precision mediump float;
+
+uniform vec4 inColor;
+out vec4 fragColor;
+
+void main(void) {
+	fragColor = inColor;
+}
+`
+
+// Arrow describes a line on the map, pointing from its start to its end.
+type Arrow interface {
+	Ends() (fromX, fromY, toX, toY float32)
+}
+
+// ArrowRenderable draws arrows as lines with a head at their end.
+type ArrowRenderable struct {
+	context *graphics.RenderContext
+
+	program                 uint32
+	vao                     *opengl.VertexArrayObject
+	vertexPositionBuffer    uint32
+	vertexPositionAttrib    int32
+	viewMatrixUniform       opengl.Matrix4Uniform
+	projectionMatrixUniform opengl.Matrix4Uniform
+	inColorUniform          opengl.Vector4Uniform
+}
+
+// NewArrowRenderable returns a new instance of ArrowRenderable.
+func NewArrowRenderable(context *graphics.RenderContext) *ArrowRenderable {
+	gl := context.OpenGl()
+	program, programErr := opengl.LinkNewStandardProgram(gl, arrowVertexShaderSource, arrowFragmentShaderSource)
+
+	if programErr != nil {
+		panic(fmt.Errorf("ArrowRenderable shader failed: %v", programErr))
+	}
+	renderable := &ArrowRenderable{
+		context:                 context,
+		program:                 program,
+		vao:                     opengl.NewVertexArrayObject(gl, program),
+		vertexPositionBuffer:    gl.GenBuffers(1)[0],
+		vertexPositionAttrib:    gl.GetAttribLocation(program, "vertexPosition"),
+		viewMatrixUniform:       opengl.Matrix4Uniform(gl.GetUniformLocation(program, "viewMatrix")),
+		projectionMatrixUniform: opengl.Matrix4Uniform(gl.GetUniformLocation(program, "projectionMatrix")),
+		inColorUniform:          opengl.Vector4Uniform(gl.GetUniformLocation(program, "inColor"))}
+
+	renderable.vao.WithSetter(func(gl opengl.OpenGl) {
+		gl.EnableVertexAttribArray(uint32(renderable.vertexPositionAttrib))
+		gl.BindBuffer(opengl.ARRAY_BUFFER, renderable.vertexPositionBuffer)
+		gl.VertexAttribOffset(uint32(renderable.vertexPositionAttrib), 3, opengl.FLOAT, false, 0, 0)
+		gl.BindBuffer(opengl.ARRAY_BUFFER, 0)
+	})
+
+	return renderable
+}
+
+// Dispose releases any internal resources
+func (renderable *ArrowRenderable) Dispose() {
+	gl := renderable.context.OpenGl()
+	gl.DeleteProgram(renderable.program)
+	gl.DeleteBuffers([]uint32{renderable.vertexPositionBuffer})
+	renderable.vao.Dispose()
+}
+
+// Render renders the given arrows in one color. The heads end at the border of the
+// icon of the target, so that the target stays visible.
+func (renderable *ArrowRenderable) Render(arrows []Arrow, color graphics.Color) {
+	gl := renderable.context.OpenGl()
+	headLength := float64(iconSize / 3.0)
+	headAngle := math.Pi / 8.0
+	vertices := make([]float32, 0, len(arrows)*3*2*3)
+
+	for _, arrow := range arrows {
+		fromX, fromY, toX, toY := arrow.Ends()
+		dx, dy := float64(toX-fromX), float64(toY-fromY)
+		length := math.Hypot(dx, dy)
+
+		if length > float64(iconSize) {
+			angle := math.Atan2(dy, dx)
+			tipX := toX - float32(math.Cos(angle)*float64(iconSize/2.0))
+			tipY := toY - float32(math.Sin(angle)*float64(iconSize/2.0))
+			leftX := tipX - float32(math.Cos(angle-headAngle)*headLength)
+			leftY := tipY - float32(math.Sin(angle-headAngle)*headLength)
+			rightX := tipX - float32(math.Cos(angle+headAngle)*headLength)
+			rightY := tipY - float32(math.Sin(angle+headAngle)*headLength)
+
+			vertices = append(vertices,
+				fromX, fromY, 0.0, tipX, tipY, 0.0,
+				tipX, tipY, 0.0, leftX, leftY, 0.0,
+				tipX, tipY, 0.0, rightX, rightY, 0.0)
+		}
+	}
+
+	if len(vertices) > 0 {
+		renderable.vao.OnShader(func() {
+			renderable.viewMatrixUniform.Set(gl, renderable.context.ViewMatrix())
+			renderable.projectionMatrixUniform.Set(gl, renderable.context.ProjectionMatrix())
+			renderable.inColorUniform.Set(gl, color.AsVector())
+
+			gl.BindBuffer(opengl.ARRAY_BUFFER, renderable.vertexPositionBuffer)
+			gl.BufferData(opengl.ARRAY_BUFFER, len(vertices)*4, vertices, opengl.STATIC_DRAW)
+			gl.DrawArrays(opengl.LINES, 0, int32(len(vertices)/3))
+			gl.BindBuffer(opengl.ARRAY_BUFFER, 0)
+		})
+	}
+}
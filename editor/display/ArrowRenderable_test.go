@@ -0,0 +1,50 @@
+package display
+
+import (
+	mgl "github.com/go-gl/mathgl/mgl32"
+
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/graphics"
+	"github.com/inkyblackness/shocked-client/opengl"
+	"github.com/inkyblackness/shocked-client/opengl/software"
+)
+
+type testingArrow [4]float32
+
+func (arrow testingArrow) Ends() (fromX, fromY, toX, toY float32) {
+	return arrow[0], arrow[1], arrow[2], arrow[3]
+}
+
+type ArrowRenderableSuite struct {
+	gl         *software.OpenGl
+	projection mgl.Mat4
+	view       mgl.Mat4
+	renderable *ArrowRenderable
+}
+
+var _ = check.Suite(&ArrowRenderableSuite{})
+
+func (suite *ArrowRenderableSuite) SetUpTest(c *check.C) {
+	suite.gl = software.NewOpenGl(64, 64)
+	suite.gl.ClearColor(0.0, 0.0, 0.0, 1.0)
+	suite.gl.Clear(opengl.COLOR_BUFFER_BIT)
+	suite.projection = mgl.Ortho2D(0, 512, 512, 0)
+	suite.view = mgl.Ident4()
+	suite.renderable = NewArrowRenderable(graphics.NewBasicRenderContext(suite.gl, &suite.projection, &suite.view))
+}
+
+func (suite *ArrowRenderableSuite) TearDownTest(c *check.C) {
+	suite.renderable.Dispose()
+}
+
+func (suite *ArrowRenderableSuite) TestRenderDrawsArrowsEndingBeforeTheirTarget(c *check.C) {
+	arrows := []Arrow{
+		testingArrow{64, 64, 448, 256},
+		testingArrow{448, 448, 64, 448},
+		testingArrow{100, 300, 150, 300}}
+
+	suite.renderable.Render(arrows, graphics.RGBA(1.0, 1.0, 0.0, 1.0))
+
+	c.Check(software.CheckGoldenImage(suite.gl.Image(), "testdata/ArrowRenderable_Render.png", *updateGolden), check.IsNil)
+}
@@ -6,12 +6,39 @@ import (
 	"github.com/inkyblackness/shocked-client/editor/camera"
 	"github.com/inkyblackness/shocked-client/editor/model"
 	"github.com/inkyblackness/shocked-client/env"
+	"github.com/inkyblackness/shocked-client/env/keys"
 	"github.com/inkyblackness/shocked-client/graphics"
 	"github.com/inkyblackness/shocked-client/ui"
 	"github.com/inkyblackness/shocked-client/ui/events"
 	dataModel "github.com/inkyblackness/shocked-model"
 )
 
+// SelectionBoxHandler is called for a selection box drawn on the map.
+// The coordinates are the world coordinates of two opposing corners of the box.
+// The modifier is the one held while releasing the mouse button.
+type SelectionBoxHandler func(fromX, fromY, toX, toY float32, modifier keys.Modifier)
+
+// DiffMarkKind identifies how a marked part of the map differs from another version of the level.
+type DiffMarkKind int
+
+const (
+	// DiffChanged marks parts that are different in the other version.
+	DiffChanged DiffMarkKind = iota
+	// DiffAdded marks parts that only exist in the other version.
+	DiffAdded
+	// DiffRemoved marks parts that only exist in the shown version.
+	DiffRemoved
+)
+
+// DiffMark marks a tile or an object position that differs from another version of the level.
+type DiffMark struct {
+	Kind DiffMarkKind
+	// X and Y are the world coordinates of the center of the mark.
+	X, Y float32
+	// Tile is set for marks of a whole tile. Other marks have the size of an object icon.
+	Tile bool
+}
+
 // MapDisplay is a display for a level map
 type MapDisplay struct {
 	context      Context
@@ -23,7 +50,9 @@ type MapDisplay struct {
 	viewMatrix    mgl.Mat4
 	renderContext *graphics.RenderContext
 
-	paletteTexture *graphics.PaletteTexture
+	paletteTexture    *graphics.PaletteTexture
+	textureIndexQuery TextureIndexQuery
+	colorQuery        ColorQuery
 
 	highlighter *BasicHighlighter
 	background  *GridRenderable
@@ -32,15 +61,29 @@ type MapDisplay struct {
 	colors      *TileColorMapRenderable
 	slopeGrid   *TileSlopeMapRenderable
 	objects     *PlacedIconsRenderable
+	arrows      *ArrowRenderable
 
 	selectedTileAreas   []Area
 	highlightedTileArea Area
+	previewTileAreas    []Area
+	diffMarkAreas       [3][]Area
 
 	displayedObjectAreas  []Area
 	displayedObjectIcons  []PlacedIcon
+	markedObjectAreas     []Area
 	selectedObjectAreas   []Area
 	highlightedObjectArea Area
 	highlightedObjectIcon PlacedIcon
+	movedObjectAreas      []Area
+	movedObjectIcons      []PlacedIcon
+
+	referenceArrows           []Arrow
+	highlightedReferenceArrow Arrow
+
+	selectionBoxHandler SelectionBoxHandler
+	selectionBoxActive  bool
+	selectionBoxFrom    [2]float32
+	selectionBoxTo      [2]float32
 
 	moveCapture func(pixelX, pixelY float32)
 }
@@ -58,7 +101,9 @@ func NewMapDisplay(context Context, parent *ui.Area, scale float32) *MapDisplay
 		context:      context,
 		levelAdapter: context.ModelAdapter().ActiveLevel(),
 		camera:       camera.NewLimited(zoomLevelMin, zoomLevelMax, -tileBaseHalf, camLimit),
-		moveCapture:  func(float32, float32) {}}
+		moveCapture:  func(float32, float32) {},
+
+		textureIndexQuery: FloorTexture}
 
 	centerX, centerY := float32(tilesPerMapSide*tileBaseLength)/-2.0, float32(tilesPerMapSide*tileBaseLength)/-2.0
 	display.camera.ZoomAt(-3+zoomShift, centerX, centerY)
@@ -85,7 +130,7 @@ func NewMapDisplay(context Context, parent *ui.Area, scale float32) *MapDisplay
 		display.paletteTexture.Update()
 	})
 
-	display.renderContext = context.NewRenderContext(display.camera.ViewMatrix())
+	display.renderContext = context.NewRenderContext(&display.viewMatrix)
 	display.highlighter = NewBasicHighlighter(display.renderContext)
 	display.background = NewGridRenderable(display.renderContext)
 	display.mapGrid = NewTileGridMapRenderable(display.renderContext)
@@ -96,6 +141,7 @@ func NewMapDisplay(context Context, parent *ui.Area, scale float32) *MapDisplay
 	display.colors = NewTileColorMapRenderable(display.renderContext)
 	display.slopeGrid = NewTileSlopeMapRenderable(display.renderContext)
 	display.objects = NewPlacedIconsRenderable(display.renderContext, display.paletteTexture)
+	display.arrows = NewArrowRenderable(display.renderContext)
 
 	linkTileProperties := func(coord model.TileCoordinate) {
 		tile := display.levelAdapter.TileMap().Tile(coord)
@@ -137,8 +183,14 @@ func (display *MapDisplay) SetVisible(visible bool) {
 	display.area.SetVisible(visible)
 }
 
+// CenterOn moves the camera so that the given world coordinates are in the center of the view.
+func (display *MapDisplay) CenterOn(x, y float32) {
+	display.camera.MoveTo(-x, -y)
+}
+
 // SetTextureIndexQuery sets which texture shall be shown.
 func (display *MapDisplay) SetTextureIndexQuery(query TextureIndexQuery) {
+	display.textureIndexQuery = query
 	display.textures.SetTextureIndexQuery(query)
 }
 
@@ -163,6 +215,39 @@ func (display *MapDisplay) SetSelectedTiles(tiles []model.TileCoordinate) {
 	}
 }
 
+// SetPreviewTiles requests to show the given set of tiles as the target of a pending change,
+// such as a paste or a brush stroke.
+func (display *MapDisplay) SetPreviewTiles(tiles []model.TileCoordinate) {
+	display.previewTileAreas = make([]Area, len(tiles))
+
+	for index, coord := range tiles {
+		tileX, tileY := coord.XY()
+		display.previewTileAreas[index] = NewSimpleArea(float32(tileX<<8+128), float32(tileY<<8+128), 256.0, 256.0)
+	}
+}
+
+// SetDiffMarks requests to show the given differences to another version of the level.
+func (display *MapDisplay) SetDiffMarks(marks []DiffMark) {
+	display.diffMarkAreas = [3][]Area{}
+
+	for _, mark := range marks {
+		size := float32(iconSize)
+		if mark.Tile {
+			size = fineCoordinatesPerTileSide
+		}
+		display.diffMarkAreas[mark.Kind] = append(display.diffMarkAreas[mark.Kind], NewSimpleArea(mark.X, mark.Y, size, size))
+	}
+}
+
+// SetSlopePreview requests to show the slopes of the given tile properties instead of the current ones.
+func (display *MapDisplay) SetSlopePreview(tiles map[model.TileCoordinate]*dataModel.TileProperties) {
+	display.slopeGrid.ClearPreview()
+	for coord, properties := range tiles {
+		x, y := coord.XY()
+		display.slopeGrid.SetPreviewTile(x, y, properties)
+	}
+}
+
 // SetDisplayedObjects requests to show the given set of objects.
 func (display *MapDisplay) SetDisplayedObjects(objects []*model.LevelObject) {
 	display.displayedObjectIcons = make([]PlacedIcon, len(objects))
@@ -175,6 +260,15 @@ func (display *MapDisplay) SetDisplayedObjects(objects []*model.LevelObject) {
 	}
 }
 
+// SetMarkedObjects requests to show the given set of objects as marked, such as the results of a search.
+func (display *MapDisplay) SetMarkedObjects(objects []*model.LevelObject) {
+	display.markedObjectAreas = make([]Area, len(objects))
+
+	for index, object := range objects {
+		display.markedObjectAreas[index] = display.iconForObject(object)
+	}
+}
+
 // SetSelectedObjects requests to show the given set of objects as selected.
 func (display *MapDisplay) SetSelectedObjects(objects []*model.LevelObject) {
 	display.selectedObjectAreas = make([]Area, len(objects))
@@ -199,14 +293,51 @@ func (display *MapDisplay) SetHighlightedObject(object *model.LevelObject) {
 	}
 }
 
+// SetMovedObjects requests to show the given set of objects at a preview position.
+// The position function is queried while rendering, so the preview follows any changes.
+func (display *MapDisplay) SetMovedObjects(objects []*model.LevelObject, position func(*model.LevelObject) (x, y float32)) {
+	display.movedObjectAreas = make([]Area, len(objects))
+	display.movedObjectIcons = make([]PlacedIcon, len(objects))
+
+	for index, object := range objects {
+		icon := display.iconForObjectAt(object, func(object *model.LevelObject) func() (float32, float32) {
+			return func() (float32, float32) { return position(object) }
+		}(object))
+		display.movedObjectAreas[index] = icon
+		display.movedObjectIcons[index] = icon
+	}
+}
+
+// SetReferenceArrows requests to show the given arrows, such as references between objects.
+func (display *MapDisplay) SetReferenceArrows(arrows []Arrow) {
+	display.referenceArrows = arrows
+}
+
+// SetHighlightedReferenceArrow registers an arrow that shall be highlighted. nil removes the highlight.
+func (display *MapDisplay) SetHighlightedReferenceArrow(arrow Arrow) {
+	display.highlightedReferenceArrow = arrow
+}
+
+// SetSelectionBoxHandler sets the handler for selection boxes.
+// Selection boxes are drawn by dragging with the secondary mouse button, and only
+// if a handler is set.
+func (display *MapDisplay) SetSelectionBoxHandler(handler SelectionBoxHandler) {
+	display.selectionBoxHandler = handler
+}
+
 // SetTileColoring sets the query function for coloring tiles.
 func (display *MapDisplay) SetTileColoring(colorQuery ColorQuery) {
+	display.colorQuery = colorQuery
 	display.colors.SetColorQuery(colorQuery)
 }
 
 func (display *MapDisplay) iconForObject(object *model.LevelObject) *referringPlacedIcon {
+	return display.iconForObjectAt(object, object.Center)
+}
+
+func (display *MapDisplay) iconForObjectAt(object *model.LevelObject, center func() (float32, float32)) *referringPlacedIcon {
 	return &referringPlacedIcon{
-		center: func() (float32, float32) { return object.Center() },
+		center: center,
 		texture: func() *graphics.BitmapTexture {
 			return display.context.ForGraphics().GameObjectIconsStore().Texture(graphics.TextureKeyFromInt(object.ID().ToInt()))
 		}}
@@ -215,6 +346,13 @@ func (display *MapDisplay) iconForObject(object *model.LevelObject) *referringPl
 func (display *MapDisplay) render() {
 	root := display.area.Root()
 	display.camera.SetViewportSize(root.Right().Value(), root.Bottom().Value())
+	display.viewMatrix = *display.camera.ViewMatrix()
+	display.renderComposition(display.displayedObjectAreas, display.displayedObjectIcons, true)
+}
+
+// renderComposition renders all layers of the map with the current view matrix.
+// Interactive layers, such as highlights and previews, are only rendered if requested.
+func (display *MapDisplay) renderComposition(objectAreas []Area, objectIcons []PlacedIcon, interactive bool) {
 	display.background.Render()
 	if !display.levelAdapter.IsCyberspace() {
 		display.textures.Render()
@@ -222,19 +360,49 @@ func (display *MapDisplay) render() {
 	display.colors.Render()
 	display.slopeGrid.Render()
 	display.highlighter.Render(display.selectedTileAreas, graphics.RGBA(0.0, 0.8, 0.2, 0.5))
-	if display.highlightedTileArea != nil {
-		display.highlighter.Render([]Area{display.highlightedTileArea}, graphics.RGBA(0.0, 0.2, 0.8, 0.3))
+	if interactive {
+		if display.highlightedTileArea != nil {
+			display.highlighter.Render([]Area{display.highlightedTileArea}, graphics.RGBA(0.0, 0.2, 0.8, 0.3))
+		}
+		display.highlighter.Render(display.previewTileAreas, graphics.RGBA(0.8, 0.6, 0.0, 0.5))
 	}
 	display.mapGrid.Render()
-	display.highlighter.Render(display.displayedObjectAreas, graphics.RGBA(1.0, 1.0, 1.0, 0.3))
+	display.highlighter.Render(display.diffMarkAreas[DiffChanged], graphics.RGBA(1.0, 0.5, 0.0, 0.5))
+	display.highlighter.Render(display.diffMarkAreas[DiffAdded], graphics.RGBA(0.0, 0.8, 0.8, 0.5))
+	display.highlighter.Render(display.diffMarkAreas[DiffRemoved], graphics.RGBA(0.9, 0.1, 0.1, 0.5))
+	display.highlighter.Render(objectAreas, graphics.RGBA(1.0, 1.0, 1.0, 0.3))
+	display.highlighter.Render(display.markedObjectAreas, graphics.RGBA(0.9, 0.9, 0.0, 0.5))
 	display.highlighter.Render(display.selectedObjectAreas, graphics.RGBA(0.0, 0.8, 0.2, 0.5))
-	if display.highlightedObjectArea != nil {
+	if interactive && (display.highlightedObjectArea != nil) {
 		display.highlighter.Render([]Area{display.highlightedObjectArea}, graphics.RGBA(0.0, 0.2, 0.8, 0.3))
 	}
-	display.objects.Render(display.displayedObjectIcons)
-	if display.highlightedObjectIcon != nil {
+	display.objects.Render(objectIcons)
+	if interactive && (display.highlightedObjectIcon != nil) {
 		display.objects.Render([]PlacedIcon{display.highlightedObjectIcon})
 	}
+	display.arrows.Render(display.referenceArrows, graphics.RGBA(1.0, 0.5, 0.0, 0.8))
+	if interactive {
+		if display.highlightedReferenceArrow != nil {
+			display.arrows.Render([]Arrow{display.highlightedReferenceArrow}, graphics.RGBA(0.2, 0.6, 1.0, 1.0))
+		}
+		display.highlighter.Render(display.movedObjectAreas, graphics.RGBA(0.8, 0.6, 0.0, 0.5))
+		display.objects.Render(display.movedObjectIcons)
+		if display.selectionBoxActive {
+			display.highlighter.Render([]Area{display.selectionBoxArea()}, graphics.RGBA(0.56, 0.69, 0.36, 0.3))
+		}
+	}
+}
+
+func (display *MapDisplay) selectionBoxArea() Area {
+	abs := func(value float32) float32 {
+		if value < 0 {
+			return -value
+		}
+		return value
+	}
+	from, to := display.selectionBoxFrom, display.selectionBoxTo
+
+	return NewSimpleArea((from[0]+to[0])/2.0, (from[1]+to[1])/2.0, abs(to[0]-from[0]), abs(to[1]-from[1]))
 }
 
 // WorldCoordinatesForPixel returns the world coordinates at the given pixel position.
@@ -287,6 +455,16 @@ func (display *MapDisplay) onMouseButtonDown(area *ui.Area, event events.Event)
 			display.camera.MoveBy(worldX-lastWorldX, worldY-lastWorldY)
 			lastPixelX, lastPixelY = pixelX, pixelY
 		}
+	} else if (mouseEvent.Buttons() == env.MouseSecondary) && (display.selectionBoxHandler != nil) {
+		startPixelX, startPixelY := mouseEvent.Position()
+
+		display.area.RequestFocus()
+		display.selectionBoxFrom[0], display.selectionBoxFrom[1] = display.unprojectPixel(startPixelX, startPixelY)
+		display.selectionBoxTo = display.selectionBoxFrom
+		display.moveCapture = func(pixelX, pixelY float32) {
+			display.selectionBoxActive = (pixelX != startPixelX) || (pixelY != startPixelY)
+			display.selectionBoxTo[0], display.selectionBoxTo[1] = display.unprojectPixel(pixelX, pixelY)
+		}
 	}
 
 	return true
@@ -300,6 +478,16 @@ func (display *MapDisplay) onMouseButtonUp(area *ui.Area, event events.Event) bo
 			display.area.ReleaseFocus()
 		}
 		display.moveCapture = func(float32, float32) {}
+	} else if mouseEvent.AffectedButtons() == env.MouseSecondary {
+		if display.area.HasFocus() {
+			display.area.ReleaseFocus()
+		}
+		if display.selectionBoxActive && (display.selectionBoxHandler != nil) {
+			from, to := display.selectionBoxFrom, display.selectionBoxTo
+			display.selectionBoxHandler(from[0], from[1], to[0], to[1], keys.Modifier(mouseEvent.Modifier()))
+		}
+		display.selectionBoxActive = false
+		display.moveCapture = func(float32, float32) {}
 	}
 
 	return true
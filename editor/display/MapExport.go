@@ -0,0 +1,215 @@
+package display
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+
+	mgl "github.com/go-gl/mathgl/mgl32"
+
+	"github.com/inkyblackness/shocked-client/editor/model"
+	"github.com/inkyblackness/shocked-client/opengl"
+	dataModel "github.com/inkyblackness/shocked-model"
+)
+
+const mapSideLength = fineCoordinatesPerTileSide * tilesPerMapSide
+
+// RenderImage renders the whole map into an image of given size per side, with north on top.
+// The image shows the same layers as the display, including the current selections, and
+// the icons of the given objects. Highlights and previews are not part of the image.
+//
+// The map is rendered in parts of the size of the window into an offscreen frame buffer,
+// from which they are read back. The frame buffer of the window is not touched.
+func (display *MapDisplay) RenderImage(size int, objects []*model.LevelObject) (*image.NRGBA, error) {
+	gl := display.renderContext.OpenGl()
+	root := display.area.Root()
+	viewWidth, viewHeight := int(root.Right().Value()), int(root.Bottom().Value())
+	if (viewWidth <= 0) || (viewHeight <= 0) {
+		return nil, fmt.Errorf("no area to render in")
+	}
+
+	texture := gl.GenTextures(1)[0]
+	gl.BindTexture(opengl.TEXTURE_2D, texture)
+	gl.TexImage2D(opengl.TEXTURE_2D, 0, opengl.RGBA, int32(viewWidth), int32(viewHeight), 0, opengl.RGBA, opengl.UNSIGNED_BYTE, nil)
+	gl.TexParameteri(opengl.TEXTURE_2D, opengl.TEXTURE_MIN_FILTER, opengl.NEAREST)
+	gl.TexParameteri(opengl.TEXTURE_2D, opengl.TEXTURE_MAG_FILTER, opengl.NEAREST)
+	gl.BindTexture(opengl.TEXTURE_2D, 0)
+	defer gl.DeleteTextures([]uint32{texture})
+	framebuffer := gl.GenFramebuffers(1)[0]
+	defer gl.DeleteFramebuffers([]uint32{framebuffer})
+	gl.BindFramebuffer(opengl.FRAMEBUFFER, framebuffer)
+	defer gl.BindFramebuffer(opengl.FRAMEBUFFER, 0)
+	gl.FramebufferTexture2D(opengl.FRAMEBUFFER, opengl.COLOR_ATTACHMENT0, opengl.TEXTURE_2D, texture, 0)
+	if status := gl.CheckFramebufferStatus(opengl.FRAMEBUFFER); status != opengl.FRAMEBUFFER_COMPLETE {
+		return nil, fmt.Errorf("offscreen frame buffer is not complete: 0x%04X", status)
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	scale := float32(size) / mapSideLength
+	pixels := make([]byte, viewWidth*viewHeight*4)
+	objectAreas := make([]Area, len(objects))
+	objectIcons := make([]PlacedIcon, len(objects))
+	savedViewMatrix := display.viewMatrix
+
+	for index, object := range objects {
+		icon := display.iconForObject(object)
+		objectAreas[index] = icon
+		objectIcons[index] = icon
+	}
+	for top := 0; top < size; top += viewHeight {
+		height := viewHeight
+		if top+height > size {
+			height = size - top
+		}
+		for left := 0; left < size; left += viewWidth {
+			width := viewWidth
+			if left+width > size {
+				width = size - left
+			}
+			display.viewMatrix = mgl.Translate3D(float32(-left), float32(size-top), 0.0).
+				Mul4(mgl.Scale3D(scale, -scale, 1.0))
+			gl.Clear(opengl.COLOR_BUFFER_BIT)
+			display.renderComposition(objectAreas, objectIcons, false)
+			gl.ReadPixels(0, int32(viewHeight-height), int32(width), int32(height), opengl.RGBA, opengl.UNSIGNED_BYTE, pixels)
+			rowSize := width * 4
+			for row := 0; row < height; row++ {
+				source := (height - 1 - row) * rowSize
+				copy(img.Pix[(top+row)*img.Stride+left*4:], pixels[source:source+rowSize])
+			}
+		}
+	}
+	display.viewMatrix = savedViewMatrix
+
+	for index := 3; index < len(img.Pix); index += 4 {
+		img.Pix[index] = 0xFF
+	}
+
+	return img, nil
+}
+
+// WriteVectorMap writes the map as SVG document of given size per side, with north on top.
+// Each tile that is not solid is a shape, filled with the current tile coloring and carrying
+// the index of the currently shown texture. Walls are lines of the same opacity as on the display.
+// The given objects are circles at their position.
+func (display *MapDisplay) WriteVectorMap(writer io.Writer, size int, objects []*model.LevelObject) error {
+	buffered := bufio.NewWriter(writer)
+	tileMap := display.levelAdapter.TileMap()
+	isCyberspace := display.levelAdapter.IsCyberspace()
+	tileQuery := func(x, y int) *dataModel.TileProperties {
+		if (x < 0) || (x >= int(tilesPerMapSide)) || (y < 0) || (y >= int(tilesPerMapSide)) {
+			return nil
+		}
+		return tileMap.Tile(model.TileCoordinateOf(x, y)).Properties()
+	}
+	point := func(x, y float32) string {
+		return fmt.Sprintf("%v,%v", x, mapSideLength-y)
+	}
+
+	fmt.Fprintf(buffered, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %v %v\">\n",
+		size, size, mapSideLength, mapSideLength)
+	fmt.Fprintf(buffered, "<rect width=\"%v\" height=\"%v\" fill=\"black\"/>\n", mapSideLength, mapSideLength)
+	fmt.Fprintf(buffered, "<g id=\"tiles\">\n")
+	for y := 0; y < int(tilesPerMapSide); y++ {
+		for x := 0; x < int(tilesPerMapSide); x++ {
+			properties := tileQuery(x, y)
+			if (properties == nil) || (properties.Type == nil) || (*properties.Type == dataModel.Solid) {
+				continue
+			}
+			left, bottom := float32(x)*fineCoordinatesPerTileSide, float32(y)*fineCoordinatesPerTileSide
+			fmt.Fprintf(buffered, "<polygon points=\"")
+			for index, corner := range tileOutline(*properties.Type) {
+				if index > 0 {
+					fmt.Fprintf(buffered, " ")
+				}
+				fmt.Fprintf(buffered, "%s", point(left+corner[0]*fineCoordinatesPerTileSide, bottom+corner[1]*fineCoordinatesPerTileSide))
+			}
+			fmt.Fprintf(buffered, "\" fill=\"%s\"", display.vectorTileFill(x, y, properties, tileQuery))
+			if !isCyberspace && (properties.RealWorld != nil) {
+				textureIndex, _ := display.textureIndexQuery(properties.RealWorld)
+				fmt.Fprintf(buffered, " data-texture=\"%d\"", textureIndex)
+			}
+			fmt.Fprintf(buffered, "/>\n")
+		}
+	}
+	fmt.Fprintf(buffered, "</g>\n")
+
+	fmt.Fprintf(buffered, "<g id=\"walls\" stroke=\"#00cc00\" stroke-width=\"8\">\n")
+	line := func(fromX, fromY, toX, toY, opacity float32) {
+		if opacity > 0 {
+			fmt.Fprintf(buffered, "<line x1=\"%v\" y1=\"%v\" x2=\"%v\" y2=\"%v\" stroke-opacity=\"%v\"/>\n",
+				fromX, mapSideLength-fromY, toX, mapSideLength-toY, opacity)
+		}
+	}
+	for y := 0; y < int(tilesPerMapSide); y++ {
+		for x := 0; x < int(tilesPerMapSide); x++ {
+			properties := tileQuery(x, y)
+			if (properties == nil) || (properties.Type == nil) {
+				continue
+			}
+			left := float32(x) * fineCoordinatesPerTileSide
+			right := left + fineCoordinatesPerTileSide
+			bottom := float32(y) * fineCoordinatesPerTileSide
+			top := bottom + fineCoordinatesPerTileSide
+			heights := properties.CalculatedWallHeights
+
+			line(left, top, right, top, heights.North)
+			line(left, bottom, right, bottom, heights.South)
+			line(left, top, left, bottom, heights.West)
+			line(right, top, right, bottom, heights.East)
+			if *properties.Type == dataModel.DiagonalOpenNorthEast || *properties.Type == dataModel.DiagonalOpenSouthWest {
+				line(left, top, right, bottom, 1.0)
+			}
+			if *properties.Type == dataModel.DiagonalOpenNorthWest || *properties.Type == dataModel.DiagonalOpenSouthEast {
+				line(left, bottom, right, top, 1.0)
+			}
+		}
+	}
+	fmt.Fprintf(buffered, "</g>\n")
+
+	fmt.Fprintf(buffered, "<g id=\"objects\" fill=\"white\" fill-opacity=\"0.6\" stroke=\"white\" stroke-width=\"4\">\n")
+	for _, object := range objects {
+		x, y := object.Center()
+		fmt.Fprintf(buffered, "<circle cx=\"%v\" cy=\"%v\" r=\"%v\" data-index=\"%d\" data-id=\"%v\"><title>%d: %v</title></circle>\n",
+			x, mapSideLength-y, iconSize/4.0, object.Index(), object.ID(), object.Index(), object.ID())
+	}
+	fmt.Fprintf(buffered, "</g>\n")
+	fmt.Fprintf(buffered, "</svg>\n")
+
+	return buffered.Flush()
+}
+
+// vectorTileFill returns the SVG fill color of a tile. It is gray, blended with the average
+// of the colors of the current tile coloring.
+func (display *MapDisplay) vectorTileFill(x, y int, properties *dataModel.TileProperties, query TilePropertiesQuery) string {
+	fill := [3]float32{0.5, 0.5, 0.5}
+
+	if display.colorQuery != nil {
+		colors := display.colorQuery(x, y, properties, query)
+		weight := 1.0 / float32(len(colors))
+		for _, color := range colors {
+			vector := color.AsVector()
+			for index := range fill {
+				fill[index] += (vector[index] - 0.5) * vector[3] * weight
+			}
+		}
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x", int(fill[0]*255), int(fill[1]*255), int(fill[2]*255))
+}
+
+// tileOutline returns the corners of the floor of given tile type, in tile units.
+func tileOutline(tileType dataModel.TileType) [][2]float32 {
+	switch tileType {
+	case dataModel.DiagonalOpenNorthEast:
+		return [][2]float32{{0, 1}, {1, 1}, {1, 0}}
+	case dataModel.DiagonalOpenNorthWest:
+		return [][2]float32{{0, 1}, {1, 1}, {0, 0}}
+	case dataModel.DiagonalOpenSouthEast:
+		return [][2]float32{{1, 1}, {1, 0}, {0, 0}}
+	case dataModel.DiagonalOpenSouthWest:
+		return [][2]float32{{0, 1}, {1, 0}, {0, 0}}
+	default:
+		return [][2]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	}
+}
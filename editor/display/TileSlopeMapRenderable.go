@@ -61,7 +61,8 @@ type TileSlopeMapRenderable struct {
 	viewMatrixUniform       opengl.Matrix4Uniform
 	projectionMatrixUniform opengl.Matrix4Uniform
 
-	tiles [][]*model.TileProperties
+	tiles        [][]*model.TileProperties
+	previewTiles [][]*model.TileProperties
 }
 
 // NewTileSlopeMapRenderable returns a new instance of a renderable for tile slopes.
@@ -82,10 +83,12 @@ func NewTileSlopeMapRenderable(context *graphics.RenderContext) *TileSlopeMapRen
 		viewMatrixUniform:       opengl.Matrix4Uniform(gl.GetUniformLocation(program, "viewMatrix")),
 		projectionMatrixUniform: opengl.Matrix4Uniform(gl.GetUniformLocation(program, "projectionMatrix")),
 
-		tiles: make([][]*model.TileProperties, int(tilesPerMapSide))}
+		tiles:        make([][]*model.TileProperties, int(tilesPerMapSide)),
+		previewTiles: make([][]*model.TileProperties, int(tilesPerMapSide))}
 
 	for i := 0; i < len(renderable.tiles); i++ {
 		renderable.tiles[i] = make([]*model.TileProperties, int(tilesPerMapSide))
+		renderable.previewTiles[i] = make([]*model.TileProperties, int(tilesPerMapSide))
 	}
 
 	renderable.vao.OnShader(func() {
@@ -155,6 +158,21 @@ func (renderable *TileSlopeMapRenderable) Clear() {
 	}
 }
 
+// SetPreviewTile sets the properties to show for the specified tile coordinate
+// instead of the current ones. nil properties remove the preview.
+func (renderable *TileSlopeMapRenderable) SetPreviewTile(x, y int, properties *model.TileProperties) {
+	renderable.previewTiles[y][x] = properties
+}
+
+// ClearPreview removes all previewed tiles.
+func (renderable *TileSlopeMapRenderable) ClearPreview() {
+	for _, row := range renderable.previewTiles {
+		for index := 0; index < len(row); index++ {
+			row[index] = nil
+		}
+	}
+}
+
 var slopeTicksByType = map[model.TileType][]int{
 	model.SlopeSouthToNorth: []int{0, 1},
 	model.SlopeWestToEast:   []int{1, 2},
@@ -200,6 +218,9 @@ func (renderable *TileSlopeMapRenderable) Render() {
 
 		for y, row := range renderable.tiles {
 			for x, tile := range row {
+				if preview := renderable.previewTiles[y][x]; preview != nil {
+					tile = preview
+				}
 				if tile != nil && (*tile.SlopeHeight > 0) {
 					modelMatrix := mgl.Ident4().
 						Mul4(mgl.Translate3D((float32(x)+0.5)*fineCoordinatesPerTileSide, (float32(y)+0.5)*fineCoordinatesPerTileSide, 0.0)).
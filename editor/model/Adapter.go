@@ -6,6 +6,17 @@ import (
 	"github.com/inkyblackness/shocked-model"
 )
 
+// InplaceProjectID identifies the project of stores that work on a single project.
+const InplaceProjectID = "(inplace)"
+
+// ProjectCatalog is implemented by stores that provide more than one project.
+type ProjectCatalog interface {
+	// ProjectIDs returns the identifiers of all projects.
+	ProjectIDs() []string
+	// IsReadOnly returns true for projects that can not be modified.
+	IsReadOnly(projectID string) bool
+}
+
 // Adapter is the central model adapter.
 type Adapter struct {
 	store model.DataStore
@@ -86,6 +97,23 @@ func (adapter *Adapter) OnProjectChanged(callback func()) {
 	adapter.activeProjectID.addObserver(callback)
 }
 
+// AvailableProjectIDs returns the identifiers of the projects of the store. Stores that are no
+// ProjectCatalog provide only the inplace project.
+func (adapter *Adapter) AvailableProjectIDs() []string {
+	if catalog, isCatalog := adapter.store.(ProjectCatalog); isCatalog {
+		return catalog.ProjectIDs()
+	}
+	return []string{InplaceProjectID}
+}
+
+// IsProjectReadOnly returns true if the identified project can not be modified.
+func (adapter *Adapter) IsProjectReadOnly(projectID string) bool {
+	if catalog, isCatalog := adapter.store.(ProjectCatalog); isCatalog {
+		return catalog.IsReadOnly(projectID)
+	}
+	return false
+}
+
 // RequestProject sets the project to work on.
 func (adapter *Adapter) RequestProject(projectID string) {
 	adapter.bitmapsAdapter.clear()
@@ -195,7 +223,7 @@ func (adapter *Adapter) RequestActiveLevel(levelID int) {
 
 // AvailableLevelIDs returns the list of identifier of available levels.
 func (adapter *Adapter) AvailableLevelIDs() []int {
-	return adapter.availableLevelIDs.get().([]int)
+	return adapter.availableLevelIDs.orDefault([]int(nil)).([]int)
 }
 
 // OnAvailableLevelsChanged registers a callback for changes of available levels.
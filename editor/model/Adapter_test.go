@@ -0,0 +1,67 @@
+package model
+
+import (
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/editor/model/memstore"
+	"github.com/inkyblackness/shocked-model"
+)
+
+type AdapterSuite struct {
+	store   *memstore.DataStore
+	adapter *Adapter
+}
+
+var _ = check.Suite(&AdapterSuite{})
+
+func (suite *AdapterSuite) SetUpTest(c *check.C) {
+	suite.store = memstore.NewDataStore(nil)
+	project := suite.store.Project("project")
+	project.AddLevel("archive", 0)
+	project.AddLevel("archive", 1)
+	project.SetPalette("game", [256]model.Color{{Red: 10, Green: 20, Blue: 30}})
+	suite.adapter = NewAdapter(suite.store)
+}
+
+func (suite *AdapterSuite) TestRequestProjectLoadsAvailableLevels(c *check.C) {
+	notified := 0
+	suite.adapter.OnAvailableLevelsChanged(func() { notified++ })
+
+	suite.adapter.RequestProject("project")
+	suite.store.Flush()
+
+	c.Check(suite.adapter.AvailableLevelIDs(), check.DeepEquals, []int{0, 1})
+	c.Check(notified, check.Equals, 1)
+}
+
+func (suite *AdapterSuite) TestRequestProjectLoadsGamePalette(c *check.C) {
+	suite.adapter.RequestProject("project")
+	suite.store.Flush()
+
+	c.Check(suite.adapter.GamePalette()[0], check.Equals, model.Color{Red: 10, Green: 20, Blue: 30})
+}
+
+func (suite *AdapterSuite) TestFailedQueriesAreReportedAsMessage(c *check.C) {
+	var messages []string
+	suite.adapter.OnMessageChanged(func() { messages = append(messages, suite.adapter.Message()) })
+	suite.store.Fail("Palette", 1)
+
+	suite.adapter.RequestProject("project")
+	suite.store.Flush()
+
+	c.Check(messages, check.DeepEquals, []string{"Failed to process store query <Palette>"})
+}
+
+func (suite *AdapterSuite) TestUnknownProjectReportsFailures(c *check.C) {
+	suite.adapter.RequestProject("unknown")
+	suite.store.Flush()
+
+	c.Check(suite.adapter.Message(), check.Matches, "Failed to process store query <.*>")
+}
+
+func (suite *AdapterSuite) TestSaveProjectForwardsToStore(c *check.C) {
+	suite.adapter.RequestProject("project")
+	suite.adapter.SaveProject()
+
+	c.Check(suite.store.Project("project").SaveCount(), check.Equals, 1)
+}
@@ -47,3 +47,12 @@ func (object *GameObject) SpecificData() []byte {
 func (object *GameObject) CommonHitpoints() int {
 	return int(gameobj.CommonProperties(object.CommonData()).Get("DefaultHitpoints"))
 }
+
+// BitmapCount returns the number of bitmaps of the object.
+func (object *GameObject) BitmapCount() int {
+	return gameObjectBitmapCount(object.CommonData())
+}
+
+func gameObjectBitmapCount(commonData []byte) int {
+	return 3 + int(gameobj.CommonProperties(commonData).Get("Extra")>>4)
+}
@@ -294,6 +294,17 @@ func (adapter *LevelAdapter) onLevelObjects(objects *model.LevelObjects) {
 	adapter.levelObjects.set(&newMap)
 }
 
+// SnapFineToGrid returns the grid position closest to the given fine coordinate within a tile.
+// Objects on the grid are placed at the border (0x00 or 0xFF) or in the center (0x80) of a tile.
+func SnapFineToGrid(fine int) int {
+	if fine < 0x40 {
+		return 0x00
+	} else if fine >= 0xC0 {
+		return 0xFF
+	}
+	return 0x80
+}
+
 // RequestNewObject requests to add a new object at the given coordinate.
 func (adapter *LevelAdapter) RequestNewObject(worldX, worldY float32, objectID ObjectID, atGrid bool) {
 	levelID := adapter.ID()
@@ -302,23 +313,14 @@ func (adapter *LevelAdapter) RequestNewObject(worldX, worldY float32, objectID O
 	tileY, fineY := integerY>>8, integerY&0xFF
 
 	if atGrid {
-		clipToGrid := func(value int) int {
-			if value < 0x40 {
-				value = 0x00
-			} else if value >= 0xC0 {
-				value = 0xFF
-			} else {
-				value = 0x80
-			}
-			return value
-		}
-		fineX = clipToGrid(fineX)
-		fineY = clipToGrid(fineY)
+		fineX = SnapFineToGrid(fineX)
+		fineY = SnapFineToGrid(fineY)
 	}
 
 	if (tileX >= 0) && (tileX < 64) && (tileY >= 0) && (tileY < 64) && (levelID >= 0) {
 		tile := adapter.tileMap.Tile(TileCoordinateOf(tileX, tileY))
-		z := int(*tile.Properties().FloorHeight) // TODO: take level.heightShift into account
+		// Object heights have eight times the resolution of tile heights, at any height shift.
+		z := int(*tile.Properties().FloorHeight) << 3
 
 		template := model.LevelObjectTemplate{
 			Class:    objectID.Class(),
@@ -339,6 +341,91 @@ func (adapter *LevelAdapter) RequestNewObject(worldX, worldY float32, objectID O
 	}
 }
 
+// RequestNewObjectWithProperties requests to add a new object of given class, having all the given properties.
+// The object is first added based on its basic properties and then modified to have all the others,
+// including class and extra data. The optional callback is called with the completed object.
+// Should only the modification fail, the callback is called with the object as it was added.
+// The optional onFailed callback is called instead if the object could not be added, or if the level
+// was no longer active when the store answered.
+func (adapter *LevelAdapter) RequestNewObjectWithProperties(class int, properties model.LevelObjectProperties,
+	onCompleted func(object *LevelObject), onFailed func()) {
+	levelID := adapter.ID()
+	failed := func() {
+		if onFailed != nil {
+			onFailed()
+		}
+	}
+
+	if levelID < 0 {
+		failed()
+	} else {
+		template := objectTemplate(class, properties)
+		completed := func(objectIndex int, newProperties *model.LevelObjectProperties) {
+			object, existing := adapter.levelObjectsMap()[objectIndex]
+			if !existing || (adapter.ID() != levelID) {
+				failed()
+				return
+			}
+			if newProperties != nil {
+				object.onPropertiesChanged(newProperties)
+				adapter.levelObjects.notifyObservers()
+			}
+			if onCompleted != nil {
+				onCompleted(object)
+			}
+		}
+		propertiesChangedHandler := func(objectIndex int) func(newProperties *model.LevelObjectProperties) {
+			return func(newProperties *model.LevelObjectProperties) {
+				completed(objectIndex, newProperties)
+			}
+		}
+		propertiesFailedHandler := func(objectIndex int) func() {
+			return func() {
+				adapter.context.simpleStoreFailure(fmt.Sprintf("SetLevelObject %v", objectIndex))()
+				completed(objectIndex, nil)
+			}
+		}
+
+		adapter.store.AddLevelObject(adapter.context.ActiveProjectID(), adapter.context.ActiveArchiveID(), levelID,
+			template, func(object model.LevelObject) {
+				if adapter.ID() == levelID {
+					adapter.onLevelObjectAdded(object)
+					adapter.store.SetLevelObject(adapter.context.ActiveProjectID(), adapter.context.ActiveArchiveID(), levelID,
+						object.ID, &properties, propertiesChangedHandler(object.ID), propertiesFailedHandler(object.ID))
+				} else {
+					failed()
+				}
+			},
+			func() {
+				adapter.context.simpleStoreFailure("AddLevelObject")()
+				failed()
+			})
+	}
+}
+
+// objectTemplate returns the template to add an object of given class, based on the basic properties.
+func objectTemplate(class int, properties model.LevelObjectProperties) model.LevelObjectTemplate {
+	valueOf := func(value *int) int {
+		if value == nil {
+			return 0
+		}
+		return *value
+	}
+
+	return model.LevelObjectTemplate{
+		Class:    class,
+		Subclass: valueOf(properties.Subclass),
+		Type:     valueOf(properties.Type),
+
+		TileX: valueOf(properties.TileX),
+		FineX: valueOf(properties.FineX),
+		TileY: valueOf(properties.TileY),
+		FineY: valueOf(properties.FineY),
+		Z:     valueOf(properties.Z),
+
+		Hitpoints: valueOf(properties.Hitpoints)}
+}
+
 func (adapter *LevelAdapter) onLevelObjectAdded(object model.LevelObject) {
 	objects := adapter.levelObjectsMap()
 	obj := newLevelObject(&object)
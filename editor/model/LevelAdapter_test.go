@@ -0,0 +1,204 @@
+package model
+
+import (
+	"time"
+
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/editor/model/memstore"
+	"github.com/inkyblackness/shocked-model"
+)
+
+type LevelAdapterSuite struct {
+	store   *memstore.DataStore
+	level   *memstore.Level
+	adapter *Adapter
+}
+
+var _ = check.Suite(&LevelAdapterSuite{})
+
+func (suite *LevelAdapterSuite) SetUpTest(c *check.C) {
+	suite.store = memstore.NewDataStore(nil)
+	suite.level = suite.store.Project("project").AddLevel("archive", 1)
+	suite.adapter = NewAdapter(suite.store)
+	suite.adapter.RequestProject("project")
+	suite.store.Flush()
+}
+
+func (suite *LevelAdapterSuite) heightOf(x, y int) model.HeightUnit {
+	return *suite.adapter.ActiveLevel().TileMap().Tile(TileCoordinateOf(x, y)).Properties().FloorHeight
+}
+
+func (suite *LevelAdapterSuite) TestRequestActiveLevelLoadsLevelData(c *check.C) {
+	height := model.HeightUnit(5)
+	suite.level.SetTile(10, 20, model.TileProperties{FloorHeight: &height})
+	suite.level.SetTextures([]int{7, 8})
+	propertiesNotified := 0
+	suite.adapter.ActiveLevel().OnLevelPropertiesChanged(func() { propertiesNotified++ })
+
+	suite.adapter.RequestActiveLevel(1)
+	suite.store.Flush()
+
+	c.Check(suite.adapter.ActiveLevel().ID(), check.Equals, 1)
+	c.Check(suite.adapter.ActiveLevel().HeightShift(), check.Equals, 3)
+	c.Check(suite.heightOf(10, 20), check.Equals, model.HeightUnit(5))
+	c.Check(suite.adapter.ActiveLevel().LevelTextureIDs(), check.DeepEquals, []int{7, 8})
+	c.Check(suite.adapter.ActiveLevel().ObjectSurveillanceCount(), check.Equals, 8)
+	c.Check(propertiesNotified, check.Equals, 1)
+}
+
+func (suite *LevelAdapterSuite) TestTilePropertyChangeQueriesTileAndNeighbours(c *check.C) {
+	height := model.HeightUnit(4)
+	suite.adapter.RequestActiveLevel(1)
+	suite.store.Flush()
+	suite.store.ClearRequests()
+
+	suite.adapter.ActiveLevel().RequestTilePropertyChange([]TileCoordinate{TileCoordinateOf(0, 5)},
+		&model.TileProperties{FloorHeight: &height})
+
+	c.Check(suite.store.Requests(), check.DeepEquals, []string{"SetTile", "Tile", "Tile", "Tile", "Tile"})
+}
+
+func (suite *LevelAdapterSuite) TestTilePropertyChangeNotifiesTileObservers(c *check.C) {
+	height := model.HeightUnit(4)
+	suite.adapter.RequestActiveLevel(1)
+	suite.store.Flush()
+	notified := 0
+	suite.adapter.ActiveLevel().TileMap().Tile(TileCoordinateOf(3, 4)).OnPropertiesChanged(func() { notified++ })
+
+	suite.adapter.ActiveLevel().RequestTilePropertyChange([]TileCoordinate{TileCoordinateOf(3, 4)},
+		&model.TileProperties{FloorHeight: &height})
+	suite.store.Flush()
+
+	c.Check(suite.heightOf(3, 4), check.Equals, model.HeightUnit(4))
+	c.Check(notified, check.Equals, 1)
+}
+
+func (suite *LevelAdapterSuite) TestTileQueriesDependOnChangeBeingProcessedFirst(c *check.C) {
+	height := model.HeightUnit(4)
+	suite.adapter.RequestActiveLevel(1)
+	suite.store.Flush()
+	suite.store.SetLatency("SetTile", 100*time.Millisecond)
+
+	suite.adapter.ActiveLevel().RequestTilePropertyChange([]TileCoordinate{TileCoordinateOf(3, 4)},
+		&model.TileProperties{FloorHeight: &height})
+	suite.store.Flush()
+
+	c.Check(suite.heightOf(3, 4), check.Equals, model.HeightUnit(0))
+	c.Check(*suite.level.Tile(3, 4).FloorHeight, check.Equals, model.HeightUnit(4))
+}
+
+func (suite *LevelAdapterSuite) TestResultsOfPreviousLevelAreIgnoredForTiles(c *check.C) {
+	height := model.HeightUnit(9)
+	suite.store.Project("project").AddLevel("archive", 2).SetTile(1, 1, model.TileProperties{FloorHeight: &height})
+	suite.store.SetLatency("Tiles", 100*time.Millisecond)
+
+	suite.adapter.RequestActiveLevel(1)
+	suite.store.Advance(10 * time.Millisecond)
+	suite.adapter.RequestActiveLevel(2)
+	suite.store.Flush()
+
+	c.Check(suite.adapter.ActiveLevel().ID(), check.Equals, 2)
+	c.Check(suite.heightOf(1, 1), check.Equals, model.HeightUnit(9))
+}
+
+func (suite *LevelAdapterSuite) TestRemoveObjectsNotifiesPerRemovedObject(c *check.C) {
+	suite.level.AddObject(1, model.LevelObjectProperties{})
+	suite.level.AddObject(1, model.LevelObjectProperties{})
+	suite.adapter.RequestActiveLevel(1)
+	suite.store.Flush()
+	notified := 0
+	suite.adapter.ActiveLevel().OnLevelObjectsChanged(func() { notified++ })
+
+	suite.adapter.ActiveLevel().RequestRemoveObjects([]int{1, 2})
+	suite.store.Flush()
+
+	c.Check(notified, check.Equals, 2)
+	c.Check(suite.adapter.ActiveLevel().LevelObject(1), check.IsNil)
+	c.Check(suite.level.ObjectIDs(), check.DeepEquals, []int{})
+}
+
+func (suite *LevelAdapterSuite) TestFailedObjectRemovalKeepsObject(c *check.C) {
+	suite.level.AddObject(1, model.LevelObjectProperties{})
+	suite.adapter.RequestActiveLevel(1)
+	suite.store.Flush()
+	suite.store.Fail("RemoveLevelObject", 1)
+
+	suite.adapter.ActiveLevel().RequestRemoveObjects([]int{1})
+	suite.store.Flush()
+
+	c.Check(suite.adapter.ActiveLevel().LevelObject(1), check.NotNil)
+	c.Check(suite.adapter.Message(), check.Equals, "Failed to process store query <RemoveLevelObject 1>")
+}
+
+func (suite *LevelAdapterSuite) TestObjectPropertiesChangeUpdatesObject(c *check.C) {
+	suite.level.AddObject(1, model.LevelObjectProperties{})
+	suite.adapter.RequestActiveLevel(1)
+	suite.store.Flush()
+	tileX := 12
+
+	suite.adapter.ActiveLevel().RequestObjectPropertiesChange([]int{1}, &model.LevelObjectProperties{TileX: &tileX})
+	suite.store.Flush()
+
+	c.Check(suite.adapter.ActiveLevel().LevelObject(1).TileX(), check.Equals, 12)
+}
+
+func (suite *LevelAdapterSuite) TestNewObjectWithPropertiesIsAddedAndModified(c *check.C) {
+	suite.adapter.RequestActiveLevel(1)
+	suite.store.Flush()
+	suite.store.ClearRequests()
+	subclass, objType, tileX, rotationZ := 2, 3, 12, 64
+	var completed *LevelObject
+
+	suite.adapter.ActiveLevel().RequestNewObjectWithProperties(1, model.LevelObjectProperties{
+		Subclass:  &subclass,
+		Type:      &objType,
+		TileX:     &tileX,
+		RotationZ: &rotationZ,
+		ClassData: []byte{1, 2, 3}},
+		func(object *LevelObject) { completed = object }, nil)
+	suite.store.Flush()
+
+	c.Check(suite.store.Requests(), check.DeepEquals, []string{"AddLevelObject", "SetLevelObject"})
+	c.Assert(completed, check.NotNil)
+	c.Check(completed.ID(), check.Equals, MakeObjectID(1, 2, 3))
+	c.Check(completed.TileX(), check.Equals, 12)
+	c.Check(completed.RotationZ(), check.Equals, 64)
+	c.Check(completed.ClassData(), check.DeepEquals, []byte{1, 2, 3})
+	c.Check(suite.adapter.ActiveLevel().LevelObject(completed.Index()), check.Equals, completed)
+}
+
+func (suite *LevelAdapterSuite) TestNewObjectWithPropertiesReportsFailedAddition(c *check.C) {
+	suite.adapter.RequestActiveLevel(1)
+	suite.store.Flush()
+	suite.store.Fail("AddLevelObject", 1)
+	failed := false
+
+	suite.adapter.ActiveLevel().RequestNewObjectWithProperties(1, model.LevelObjectProperties{},
+		func(object *LevelObject) { c.Errorf("unexpected completion") }, func() { failed = true })
+	suite.store.Flush()
+
+	c.Check(failed, check.Equals, true)
+	c.Check(suite.adapter.Message(), check.Equals, "Failed to process store query <AddLevelObject>")
+}
+
+func (suite *LevelAdapterSuite) TestNewObjectWithPropertiesIsCompletedIfOnlyModificationFails(c *check.C) {
+	suite.adapter.RequestActiveLevel(1)
+	suite.store.Flush()
+	suite.store.Fail("SetLevelObject", 1)
+	var completed *LevelObject
+
+	suite.adapter.ActiveLevel().RequestNewObjectWithProperties(1, model.LevelObjectProperties{},
+		func(object *LevelObject) { completed = object }, func() { c.Errorf("unexpected failure") })
+	suite.store.Flush()
+
+	c.Assert(completed, check.NotNil)
+	c.Check(suite.adapter.ActiveLevel().LevelObject(completed.Index()), check.Equals, completed)
+}
+
+func (suite *LevelAdapterSuite) TestSnapFineToGridUsesBordersAndCenter(c *check.C) {
+	c.Check(SnapFineToGrid(0x3F), check.Equals, 0x00)
+	c.Check(SnapFineToGrid(0x40), check.Equals, 0x80)
+	c.Check(SnapFineToGrid(0xBF), check.Equals, 0x80)
+	c.Check(SnapFineToGrid(0xC0), check.Equals, 0xFF)
+}
@@ -0,0 +1,237 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/inkyblackness/shocked-model"
+)
+
+// LevelDiffKind identifies what a hunk of a level diff is about.
+type LevelDiffKind int
+
+const (
+	// LevelPropertyDiff is a changed property of the level.
+	LevelPropertyDiff LevelDiffKind = iota
+	// TileDiff is a tile with changed properties.
+	TileDiff
+	// ObjectAddedDiff is an object that only exists in the other version.
+	ObjectAddedDiff
+	// ObjectRemovedDiff is an object that only exists in the base version.
+	ObjectRemovedDiff
+	// ObjectModifiedDiff is an object with changed properties.
+	ObjectModifiedDiff
+)
+
+// LevelDiffHunk is one difference between a base and an other version of a level.
+// Applying a hunk changes a level to have the content of the other version for this difference.
+type LevelDiffHunk struct {
+	Kind LevelDiffKind
+
+	// Property is the name of a changed level property.
+	Property string
+	// BaseValue and OtherValue are the values of the changed level property, either of type int or bool.
+	BaseValue  interface{}
+	OtherValue interface{}
+
+	// X and Y are the coordinates of a changed tile, or of the tile an object is in.
+	X, Y int
+	// OtherTile are the properties of a changed tile in the other version.
+	OtherTile model.TileProperties
+
+	// BaseObject and OtherObject are the object in either version; Only one is set for added and removed objects.
+	BaseObject  *LevelFileObject
+	OtherObject *LevelFileObject
+
+	// Fields are the names of the changed properties of a tile or an object.
+	Fields []string
+}
+
+// String returns a short description of the difference.
+func (hunk *LevelDiffHunk) String() string {
+	switch hunk.Kind {
+	case LevelPropertyDiff:
+		return fmt.Sprintf("Level %v: %v -> %v", hunk.Property, hunk.BaseValue, hunk.OtherValue)
+	case TileDiff:
+		return fmt.Sprintf("Tile %d/%d: %v", hunk.X, hunk.Y, strings.Join(hunk.Fields, ", "))
+	case ObjectAddedDiff:
+		return fmt.Sprintf("Object %d added: %v", hunk.ObjectIndex(), levelFileObjectID(hunk.OtherObject))
+	case ObjectRemovedDiff:
+		return fmt.Sprintf("Object %d removed: %v", hunk.ObjectIndex(), levelFileObjectID(hunk.BaseObject))
+	default:
+		return fmt.Sprintf("Object %d modified: %v", hunk.ObjectIndex(), strings.Join(hunk.Fields, ", "))
+	}
+}
+
+// ObjectIndex returns the index of the object of object hunks, or -1 for other hunks.
+func (hunk *LevelDiffHunk) ObjectIndex() int {
+	if hunk.OtherObject != nil {
+		return hunk.OtherObject.Index
+	}
+	if hunk.BaseObject != nil {
+		return hunk.BaseObject.Index
+	}
+	return -1
+}
+
+// Center returns the world coordinates of the difference. These are the position of objects and the center
+// of tiles. Level properties are centered on the map.
+func (hunk *LevelDiffHunk) Center() (x, y float32) {
+	object := hunk.OtherObject
+	if object == nil {
+		object = hunk.BaseObject
+	}
+	if object != nil {
+		return float32(safeInt(object.Properties.TileX, 0)<<8 + safeInt(object.Properties.FineX, 0)),
+			float32(safeInt(object.Properties.TileY, 0)<<8 + safeInt(object.Properties.FineY, 0))
+	}
+	if hunk.Kind == TileDiff {
+		return float32(hunk.X<<8 + 128), float32(hunk.Y<<8 + 128)
+	}
+	return 32 * 256, 32 * 256
+}
+
+func levelFileObjectID(object *LevelFileObject) ObjectID {
+	return MakeObjectID(object.Class, safeInt(object.Properties.Subclass, 0), safeInt(object.Properties.Type, 0))
+}
+
+// levelDiffProperties are the level properties that are compared, with their names.
+var levelDiffProperties = []struct {
+	name  string
+	value func(properties *model.LevelProperties) interface{}
+}{
+	{"cyberspace", func(properties *model.LevelProperties) interface{} { return boolOrNil(properties.CyberspaceFlag) }},
+	{"heightShift", func(properties *model.LevelProperties) interface{} { return intOrNil(properties.HeightShift) }},
+	{"ceilingRadiation", func(properties *model.LevelProperties) interface{} { return boolOrNil(properties.CeilingHasRadiation) }},
+	{"ceilingEffectLevel", func(properties *model.LevelProperties) interface{} { return intOrNil(properties.CeilingEffectLevel) }},
+	{"floorBiohazard", func(properties *model.LevelProperties) interface{} { return boolOrNil(properties.FloorHasBiohazard) }},
+	{"floorGravity", func(properties *model.LevelProperties) interface{} { return boolOrNil(properties.FloorHasGravity) }},
+	{"floorEffectLevel", func(properties *model.LevelProperties) interface{} { return intOrNil(properties.FloorEffectLevel) }}}
+
+// LevelPropertyValue returns the value of the named level property, as it is named in level diffs.
+// nil is returned for unknown and for unset properties.
+func LevelPropertyValue(properties *model.LevelProperties, name string) interface{} {
+	for _, property := range levelDiffProperties {
+		if property.name == name {
+			return property.value(properties)
+		}
+	}
+	return nil
+}
+
+func boolOrNil(value *bool) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+func intOrNil(value *int) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+// DiffLevels returns the differences from the base to the other version of a level, ordered by level properties,
+// tiles from south-west to north-east, and objects by their index.
+//
+// Sections missing in either version are not compared. Properties that are not set in the other version are
+// no difference, which allows comparing with partial level files. Objects are compared by their index;
+// An object of another class at the same index is reported as removed and added.
+func DiffLevels(base, other *LevelFile) []*LevelDiffHunk {
+	var hunks []*LevelDiffHunk
+
+	if (base.Properties != nil) && (other.Properties != nil) {
+		for _, property := range levelDiffProperties {
+			baseValue, otherValue := property.value(base.Properties), property.value(other.Properties)
+			if (otherValue != nil) && (baseValue != otherValue) {
+				hunks = append(hunks, &LevelDiffHunk{Kind: LevelPropertyDiff,
+					Property: property.name, BaseValue: baseValue, OtherValue: otherValue})
+			}
+		}
+	}
+	if (base.Tiles != nil) && (other.Tiles != nil) {
+		for y, row := range other.Tiles {
+			for x, otherTile := range row {
+				otherTile.CalculatedWallHeights = nil
+				if fields := changedFields(base.Tiles[y][x], otherTile); len(fields) > 0 {
+					hunks = append(hunks, &LevelDiffHunk{Kind: TileDiff, X: x, Y: y, OtherTile: otherTile, Fields: fields})
+				}
+			}
+		}
+	}
+	if (base.Objects != nil) && (other.Objects != nil) {
+		hunks = append(hunks, diffLevelObjects(base.Objects, other.Objects)...)
+	}
+
+	return hunks
+}
+
+func diffLevelObjects(baseObjects, otherObjects []LevelFileObject) []*LevelDiffHunk {
+	var hunks []*LevelDiffHunk
+	objectsByIndex := func(objects []LevelFileObject) map[int]*LevelFileObject {
+		result := make(map[int]*LevelFileObject)
+		for index := range objects {
+			result[objects[index].Index] = &objects[index]
+		}
+		return result
+	}
+	bases, others := objectsByIndex(baseObjects), objectsByIndex(otherObjects)
+	var indices []int
+	for index := range bases {
+		indices = append(indices, index)
+	}
+	for index := range others {
+		if _, inBase := bases[index]; !inBase {
+			indices = append(indices, index)
+		}
+	}
+	sort.Ints(indices)
+
+	objectHunk := func(kind LevelDiffKind, base, other *LevelFileObject) *LevelDiffHunk {
+		object := other
+		if object == nil {
+			object = base
+		}
+		return &LevelDiffHunk{Kind: kind, BaseObject: base, OtherObject: other,
+			X: safeInt(object.Properties.TileX, 0), Y: safeInt(object.Properties.TileY, 0)}
+	}
+	for _, index := range indices {
+		base, other := bases[index], others[index]
+		if (base != nil) && (other != nil) && (base.Class == other.Class) {
+			if fields := changedFields(base.Properties, other.Properties); len(fields) > 0 {
+				hunk := objectHunk(ObjectModifiedDiff, base, other)
+				hunk.Fields = fields
+				hunks = append(hunks, hunk)
+			}
+		} else {
+			if base != nil {
+				hunks = append(hunks, objectHunk(ObjectRemovedDiff, base, nil))
+			}
+			if other != nil {
+				hunks = append(hunks, objectHunk(ObjectAddedDiff, nil, other))
+			}
+		}
+	}
+
+	return hunks
+}
+
+// changedFields returns the names of the fields that are set in other and differ from base.
+// Both must be structs of the same type, with fields of nillable types.
+func changedFields(base, other interface{}) []string {
+	var fields []string
+	baseValue, otherValue := reflect.ValueOf(base), reflect.ValueOf(other)
+
+	for index := 0; index < otherValue.NumField(); index++ {
+		otherField := otherValue.Field(index)
+		if !otherField.IsNil() && !reflect.DeepEqual(baseValue.Field(index).Interface(), otherField.Interface()) {
+			fields = append(fields, otherValue.Type().Field(index).Name)
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,133 @@
+package model
+
+import (
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-model"
+)
+
+type LevelDiffSuite struct {
+	base  *LevelFile
+	other *LevelFile
+}
+
+var _ = check.Suite(&LevelDiffSuite{})
+
+func (suite *LevelDiffSuite) SetUpTest(c *check.C) {
+	suite.base = suite.aLevelFile()
+	suite.other = suite.aLevelFile()
+}
+
+func (suite *LevelDiffSuite) aLevelFile() *LevelFile {
+	heightShift, gravity := 3, false
+	file := &LevelFile{
+		Format:     LevelFileFormat,
+		Version:    LevelFileVersion,
+		Properties: &model.LevelProperties{HeightShift: &heightShift, FloorHasGravity: &gravity},
+		Tiles:      make([][]model.TileProperties, 64),
+		Objects:    []LevelFileObject{}}
+	for y := range file.Tiles {
+		file.Tiles[y] = make([]model.TileProperties, 64)
+		for x := range file.Tiles[y] {
+			solid, height := model.Solid, model.HeightUnit(0)
+			file.Tiles[y][x] = model.TileProperties{Type: &solid, FloorHeight: &height}
+		}
+	}
+	return file
+}
+
+func (suite *LevelDiffSuite) anObject(index, class, tileX int) LevelFileObject {
+	subclass, objType, tileY := 0, 0, 0
+	return LevelFileObject{Index: index, Class: class, Properties: model.LevelObjectProperties{
+		Subclass: &subclass, Type: &objType, TileX: &tileX, TileY: &tileY}}
+}
+
+func (suite *LevelDiffSuite) TestEqualLevelsHaveNoDifferences(c *check.C) {
+	c.Check(DiffLevels(suite.base, suite.other), check.HasLen, 0)
+}
+
+func (suite *LevelDiffSuite) TestChangedLevelPropertiesAreListed(c *check.C) {
+	heightShift, gravity := 5, true
+	suite.other.Properties = &model.LevelProperties{HeightShift: &heightShift, FloorHasGravity: &gravity}
+
+	hunks := DiffLevels(suite.base, suite.other)
+
+	c.Assert(hunks, check.HasLen, 2)
+	c.Check(hunks[0].Kind, check.Equals, LevelPropertyDiff)
+	c.Check(hunks[0].Property, check.Equals, "heightShift")
+	c.Check(hunks[0].BaseValue, check.Equals, 3)
+	c.Check(hunks[0].OtherValue, check.Equals, 5)
+	c.Check(hunks[1].Property, check.Equals, "floorGravity")
+	c.Check(hunks[1].String(), check.Equals, "Level floorGravity: false -> true")
+}
+
+func (suite *LevelDiffSuite) TestChangedTilesAreListedWithTheirFields(c *check.C) {
+	open, height := model.Open, model.HeightUnit(4)
+	suite.other.Tiles[20][10] = model.TileProperties{Type: &open, FloorHeight: &height}
+
+	hunks := DiffLevels(suite.base, suite.other)
+
+	c.Assert(hunks, check.HasLen, 1)
+	c.Check(hunks[0].Kind, check.Equals, TileDiff)
+	c.Check(hunks[0].X, check.Equals, 10)
+	c.Check(hunks[0].Y, check.Equals, 20)
+	c.Check(hunks[0].Fields, check.DeepEquals, []string{"Type", "FloorHeight"})
+	c.Check(*hunks[0].OtherTile.Type, check.Equals, model.Open)
+}
+
+func (suite *LevelDiffSuite) TestUnsetPropertiesOfOtherAreNoDifference(c *check.C) {
+	open := model.Open
+	suite.other.Properties = &model.LevelProperties{}
+	suite.other.Tiles[20][10] = model.TileProperties{Type: &open}
+
+	hunks := DiffLevels(suite.base, suite.other)
+
+	c.Assert(hunks, check.HasLen, 1)
+	c.Check(hunks[0].Fields, check.DeepEquals, []string{"Type"})
+}
+
+func (suite *LevelDiffSuite) TestMissingSectionsAreNotCompared(c *check.C) {
+	suite.other.Properties = nil
+	suite.other.Tiles = nil
+	suite.other.Objects = nil
+	suite.base.Objects = append(suite.base.Objects, suite.anObject(1, 1, 0))
+
+	c.Check(DiffLevels(suite.base, suite.other), check.HasLen, 0)
+}
+
+func (suite *LevelDiffSuite) TestObjectsAreComparedByIndex(c *check.C) {
+	suite.base.Objects = []LevelFileObject{suite.anObject(1, 1, 2), suite.anObject(2, 1, 3), suite.anObject(3, 1, 4)}
+	suite.other.Objects = []LevelFileObject{suite.anObject(2, 1, 5), suite.anObject(3, 1, 4), suite.anObject(4, 2, 6)}
+
+	hunks := DiffLevels(suite.base, suite.other)
+
+	c.Assert(hunks, check.HasLen, 3)
+	c.Check(hunks[0].Kind, check.Equals, ObjectRemovedDiff)
+	c.Check(hunks[0].ObjectIndex(), check.Equals, 1)
+	c.Check(hunks[1].Kind, check.Equals, ObjectModifiedDiff)
+	c.Check(hunks[1].ObjectIndex(), check.Equals, 2)
+	c.Check(hunks[1].Fields, check.DeepEquals, []string{"TileX"})
+	c.Check(hunks[2].Kind, check.Equals, ObjectAddedDiff)
+	c.Check(hunks[2].ObjectIndex(), check.Equals, 4)
+	c.Check(hunks[2].X, check.Equals, 6)
+}
+
+func (suite *LevelDiffSuite) TestObjectOfOtherClassIsRemovedAndAdded(c *check.C) {
+	suite.base.Objects = []LevelFileObject{suite.anObject(1, 1, 2)}
+	suite.other.Objects = []LevelFileObject{suite.anObject(1, 3, 2)}
+
+	hunks := DiffLevels(suite.base, suite.other)
+
+	c.Assert(hunks, check.HasLen, 2)
+	c.Check(hunks[0].Kind, check.Equals, ObjectRemovedDiff)
+	c.Check(hunks[1].Kind, check.Equals, ObjectAddedDiff)
+	c.Check(hunks[1].String(), check.Equals, "Object 1 added:  3/0/ 0")
+}
+
+func (suite *LevelDiffSuite) TestCenterOfTileDifferenceIsTileCenter(c *check.C) {
+	hunk := &LevelDiffHunk{Kind: TileDiff, X: 2, Y: 3}
+	x, y := hunk.Center()
+
+	c.Check(x, check.Equals, float32(2*256+128))
+	c.Check(y, check.Equals, float32(3*256+128))
+}
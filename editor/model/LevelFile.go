@@ -0,0 +1,340 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/inkyblackness/shocked-model"
+)
+
+// LevelFileFormat is the identifier of level files, stored in their format field.
+const LevelFileFormat = "shocked-level"
+
+// LevelFileVersion is the version of level files that is written.
+const LevelFileVersion = 1
+
+// LevelFile is the content of a whole level, as it is stored in a level file.
+//
+// Level files are JSON documents with the fields named as tagged below. The properties of the
+// level, the tiles, the objects and the surveillance entries are in the JSON format of the data
+// model; Byte arrays, such as class and extra data of objects, are Base64 encoded.
+//
+// Any section may be missing or null, in which case an import keeps the corresponding data
+// of the level as it is. This allows partial files, such as sketched layouts of only the tile types.
+type LevelFile struct {
+	// Format is always LevelFileFormat.
+	Format string `json:"format"`
+	// Version is the version of the format the file was written with.
+	Version int `json:"version"`
+
+	// Properties are the general properties of the level.
+	Properties *model.LevelProperties `json:"properties"`
+	// Tiles are the properties of all tiles, as 64 rows of 64 tiles. The first row is the southern-most one,
+	// the first tile of a row is the western-most one. Calculated wall heights are neither written nor imported.
+	Tiles [][]model.TileProperties `json:"tiles"`
+	// Textures are the identifier of the world textures used as level textures.
+	Textures []int `json:"textures"`
+	// TextureAnimations are the properties of the texture animation groups.
+	TextureAnimations []model.TextureAnimation `json:"textureAnimations"`
+	// Objects are all objects of the level, sorted by their index.
+	Objects []LevelFileObject `json:"objects"`
+	// Surveillance are the surveillance entries of the level.
+	Surveillance []model.SurveillanceObject `json:"surveillance"`
+}
+
+// LevelFileObject is an object of a level file.
+type LevelFileObject struct {
+	// Index is the index of the object within the level. Objects and surveillance entries refer to objects by it.
+	Index int `json:"index"`
+	// Class is the class of the object.
+	Class int `json:"class"`
+	// Properties are all properties of the object, including subclass, type, position, class and extra data.
+	Properties model.LevelObjectProperties `json:"properties"`
+}
+
+// ReadLevelFile decodes a level file from given reader.
+func ReadLevelFile(reader io.Reader) (*LevelFile, error) {
+	var file LevelFile
+	err := json.NewDecoder(reader).Decode(&file)
+
+	if err != nil {
+		return nil, err
+	}
+	if file.Format != LevelFileFormat {
+		return nil, fmt.Errorf("not a level file")
+	}
+	if file.Version > LevelFileVersion {
+		return nil, fmt.Errorf("unsupported level file version %d", file.Version)
+	}
+	if file.Tiles != nil {
+		valid := len(file.Tiles) == 64
+		for _, row := range file.Tiles {
+			valid = valid && (len(row) == 64)
+		}
+		if !valid {
+			return nil, fmt.Errorf("level file must have 64x64 tiles")
+		}
+	}
+
+	return &file, nil
+}
+
+// Write encodes the level file as indented JSON, which keeps differences between versions readable.
+func (file *LevelFile) Write(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(file)
+}
+
+// Export returns the content of the level as it is currently loaded.
+func (adapter *LevelAdapter) Export() *LevelFile {
+	file := &LevelFile{
+		Format:  LevelFileFormat,
+		Version: LevelFileVersion,
+		Tiles:   make([][]model.TileProperties, 64)}
+
+	if properties := adapter.properties(); properties != nil {
+		levelProperties := *properties
+		file.Properties = &levelProperties
+	}
+	for y := range file.Tiles {
+		row := make([]model.TileProperties, 64)
+		for x := range row {
+			if properties := adapter.tileMap.Tile(TileCoordinateOf(x, y)).Properties(); properties != nil {
+				row[x] = *properties
+				row[x].CalculatedWallHeights = nil
+			}
+		}
+		file.Tiles[y] = row
+	}
+	file.Textures = append([]int{}, adapter.LevelTextureIDs()...)
+	file.TextureAnimations = []model.TextureAnimation{}
+	for _, group := range adapter.levelTextureAnimationGroupList() {
+		file.TextureAnimations = append(file.TextureAnimations, group.properties)
+	}
+	file.Objects = []LevelFileObject{}
+	for _, object := range adapter.LevelObjects(func(*LevelObject) bool { return true }) {
+		file.Objects = append(file.Objects, LevelFileObject{
+			Index:      object.Index(),
+			Class:      object.class,
+			Properties: object.Properties()})
+	}
+	file.Surveillance = []model.SurveillanceObject{}
+	if value := adapter.levelSurveillance.get(); value != nil {
+		file.Surveillance = append(file.Surveillance, *value.(*[]model.SurveillanceObject)...)
+	}
+
+	return file
+}
+
+// RequestLevelImport requests to replace the content of the identified level of the active archive
+// with the content of given file. The file is replayed section by section through the setters of the
+// store; Sections missing in the file are kept.
+//
+// All objects of the level are replaced. New objects receive their index from the store, which is
+// expected to assign the lowest free index. Gaps between the indices of the file are therefore filled
+// with temporary objects, which are removed afterwards. Should an object still receive another index,
+// the surveillance entries are adapted - references within class data are not.
+// Texture animations and surveillance entries beyond the ones of the file are cleared.
+//
+// The level is reloaded if it is the active one. The callback is called once all is done, with the
+// amount of objects that received another index. Should any store request have failed, the level
+// is only partially imported and the callback receives an error.
+func (adapter *Adapter) RequestLevelImport(levelID int, file *LevelFile, onDone func(movedObjects int, err error)) {
+	levelImport := &levelImport{
+		adapter:       adapter,
+		projectID:     adapter.ActiveProjectID(),
+		archiveID:     adapter.ActiveArchiveID(),
+		levelID:       levelID,
+		file:          file,
+		objectIndices: make(map[int]int)}
+
+	levelImport.start(func() {
+		if adapter.ActiveLevel().ID() == levelID {
+			adapter.RequestActiveLevel(levelID)
+		}
+		if onDone != nil {
+			var err error
+			if levelImport.failed {
+				err = fmt.Errorf("level %d was only partially imported", levelID)
+			}
+			onDone(levelImport.movedObjects, err)
+		}
+	})
+}
+
+type levelImport struct {
+	adapter   *Adapter
+	projectID string
+	archiveID string
+	levelID   int
+	file      *LevelFile
+
+	objectIndices map[int]int
+	movedObjects  int
+	failed        bool
+}
+
+func (levelImport *levelImport) store() model.DataStore {
+	return levelImport.adapter.store
+}
+
+// newBatch returns a store batch that marks the import as failed if any of its requests failed.
+func (levelImport *levelImport) newBatch(onCompleted func()) *storeBatch {
+	var batch *storeBatch
+	batch = newStoreBatch(levelImport.adapter, func() {
+		levelImport.failed = levelImport.failed || batch.failed
+		onCompleted()
+	})
+	return batch
+}
+
+func (levelImport *levelImport) start(onCompleted func()) {
+	batch := levelImport.newBatch(func() { levelImport.replaySurveillance(onCompleted) })
+	file := levelImport.file
+
+	if file.Properties != nil {
+		levelImport.store().SetLevelProperties(levelImport.projectID, levelImport.archiveID, levelImport.levelID,
+			*file.Properties, func(model.LevelProperties) { batch.done() }, batch.request("SetLevelProperties"))
+	}
+	for y, row := range file.Tiles {
+		for x, properties := range row {
+			properties.CalculatedWallHeights = nil
+			levelImport.store().SetTile(levelImport.projectID, levelImport.archiveID, levelImport.levelID,
+				x, y, properties, func(model.TileProperties) { batch.done() }, batch.request("SetTile"))
+		}
+	}
+	if file.Textures != nil {
+		levelImport.store().SetLevelTextures(levelImport.projectID, levelImport.archiveID, levelImport.levelID,
+			file.Textures, func([]int) { batch.done() }, batch.request("SetLevelTextures"))
+	}
+	for index, animation := range file.TextureAnimations {
+		levelImport.store().SetLevelTextureAnimation(levelImport.projectID, levelImport.archiveID, levelImport.levelID,
+			index, animation, func([]model.TextureAnimation) { batch.done() }, batch.request("SetLevelTextureAnimation"))
+	}
+	if file.TextureAnimations != nil {
+		levelImport.clearTextureAnimations(len(file.TextureAnimations), batch)
+	}
+	if file.Objects != nil {
+		levelImport.replayObjects(batch)
+	}
+	batch.done()
+}
+
+// replayObjects removes all current objects and then adds the objects of the file.
+func (levelImport *levelImport) replayObjects(outer *storeBatch) {
+	onFailure := outer.request("LevelObjects")
+
+	levelImport.store().LevelObjects(levelImport.projectID, levelImport.archiveID, levelImport.levelID,
+		func(existing *model.LevelObjects) {
+			removals := levelImport.newBatch(func() { levelImport.addObjects(outer) })
+			for _, object := range existing.Table {
+				levelImport.removeObject(object.ID, removals)
+			}
+			removals.done()
+		}, onFailure)
+}
+
+func (levelImport *levelImport) addObjects(outer *storeBatch) {
+	var placeholders []int
+	fileObjects := make(map[int]LevelFileObject)
+	maxIndex := 0
+	for _, object := range levelImport.file.Objects {
+		fileObjects[object.Index] = object
+		if object.Index > maxIndex {
+			maxIndex = object.Index
+		}
+	}
+	additions := levelImport.newBatch(func() {
+		removals := levelImport.newBatch(outer.done)
+		for _, index := range placeholders {
+			levelImport.removeObject(index, removals)
+		}
+		removals.done()
+	})
+
+	for index := 1; index <= maxIndex; index++ {
+		object, existing := fileObjects[index]
+		fileIndex := index
+		onAdded := func(added model.LevelObject) {
+			if !existing {
+				placeholders = append(placeholders, added.ID)
+				additions.done()
+				return
+			}
+			levelImport.objectIndices[fileIndex] = added.ID
+			if added.ID != fileIndex {
+				levelImport.movedObjects++
+			}
+			properties := object.Properties
+			levelImport.store().SetLevelObject(levelImport.projectID, levelImport.archiveID, levelImport.levelID,
+				added.ID, &properties, func(*model.LevelObjectProperties) { additions.done() },
+				additions.request(fmt.Sprintf("SetLevelObject %v", added.ID)))
+			additions.done()
+		}
+		levelImport.store().AddLevelObject(levelImport.projectID, levelImport.archiveID, levelImport.levelID,
+			objectTemplate(object.Class, object.Properties), onAdded, additions.request("AddLevelObject"))
+	}
+	additions.done()
+}
+
+func (levelImport *levelImport) removeObject(index int, batch *storeBatch) {
+	levelImport.store().RemoveLevelObject(levelImport.projectID, levelImport.archiveID, levelImport.levelID,
+		index, batch.done, batch.request(fmt.Sprintf("RemoveLevelObject %v", index)))
+}
+
+// replaySurveillance sets the surveillance entries, with the object indices they refer to adapted to the added objects.
+func (levelImport *levelImport) replaySurveillance(onCompleted func()) {
+	batch := levelImport.newBatch(onCompleted)
+	mapped := func(index *int) *int {
+		if index != nil {
+			if newIndex, known := levelImport.objectIndices[*index]; known {
+				return &newIndex
+			}
+		}
+		return index
+	}
+
+	for index, entry := range levelImport.file.Surveillance {
+		data := model.SurveillanceObject{
+			SourceIndex:     mapped(entry.SourceIndex),
+			DeathwatchIndex: mapped(entry.DeathwatchIndex)}
+		levelImport.store().SetLevelSurveillanceObject(levelImport.projectID, levelImport.archiveID, levelImport.levelID,
+			index, data, func([]model.SurveillanceObject) { batch.done() }, batch.request("SetLevelSurveillanceObject"))
+	}
+	if levelImport.file.Surveillance != nil {
+		levelImport.clearSurveillance(len(levelImport.file.Surveillance), batch)
+	}
+	batch.done()
+}
+
+// clearTextureAnimations resets the texture animations of the level from given index on.
+func (levelImport *levelImport) clearTextureAnimations(from int, batch *storeBatch) {
+	onFailure := batch.request("LevelTextureAnimations")
+	levelImport.store().LevelTextureAnimations(levelImport.projectID, levelImport.archiveID, levelImport.levelID,
+		func(animations []model.TextureAnimation) {
+			zero := 0
+			cleared := model.TextureAnimation{FrameTime: &zero, FrameCount: &zero, LoopType: &zero}
+			for index := from; index < len(animations); index++ {
+				levelImport.store().SetLevelTextureAnimation(levelImport.projectID, levelImport.archiveID, levelImport.levelID,
+					index, cleared, func([]model.TextureAnimation) { batch.done() }, batch.request("SetLevelTextureAnimation"))
+			}
+			batch.done()
+		}, onFailure)
+}
+
+// clearSurveillance resets the surveillance entries of the level from given index on.
+func (levelImport *levelImport) clearSurveillance(from int, batch *storeBatch) {
+	onFailure := batch.request("LevelSurveillanceObjects")
+	levelImport.store().LevelSurveillanceObjects(levelImport.projectID, levelImport.archiveID, levelImport.levelID,
+		func(entries []model.SurveillanceObject) {
+			zero := 0
+			cleared := model.SurveillanceObject{SourceIndex: &zero, DeathwatchIndex: &zero}
+			for index := from; index < len(entries); index++ {
+				levelImport.store().SetLevelSurveillanceObject(levelImport.projectID, levelImport.archiveID, levelImport.levelID,
+					index, cleared, func([]model.SurveillanceObject) { batch.done() }, batch.request("SetLevelSurveillanceObject"))
+			}
+			batch.done()
+		}, onFailure)
+}
@@ -0,0 +1,297 @@
+package model
+
+import (
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/inkyblackness/shocked-model"
+)
+
+// LevelLayoutTilesetImage is the file name of the tileset image the maps for the Tiled editor refer to.
+// It is expected in the same directory as the map and has the content of TileTypesImage().
+const LevelLayoutTilesetImage = "tiletypes.png"
+
+// tmxTileSize is the size of one tile, in pixels, in maps for the Tiled editor.
+const tmxTileSize = 32
+
+// tmxTileTypeProperty is the name of the tile property that identifies the tile type of a tileset tile.
+const tmxTileTypeProperty = "tileType"
+
+// tmxFlipFlags are the upper bits of global tile IDs, which Tiled uses to mark flipped tiles.
+const tmxFlipFlags = 0xE0000000
+
+type tmxMap struct {
+	XMLName      xml.Name         `xml:"map"`
+	Version      string           `xml:"version,attr"`
+	Orientation  string           `xml:"orientation,attr"`
+	RenderOrder  string           `xml:"renderorder,attr"`
+	Width        int              `xml:"width,attr"`
+	Height       int              `xml:"height,attr"`
+	TileWidth    int              `xml:"tilewidth,attr"`
+	TileHeight   int              `xml:"tileheight,attr"`
+	Tilesets     []tmxTileset     `xml:"tileset"`
+	Layers       []tmxLayer       `xml:"layer"`
+	ObjectGroups []tmxObjectGroup `xml:"objectgroup"`
+}
+
+type tmxTileset struct {
+	FirstGID   uint32    `xml:"firstgid,attr"`
+	Name       string    `xml:"name,attr"`
+	TileWidth  int       `xml:"tilewidth,attr"`
+	TileHeight int       `xml:"tileheight,attr"`
+	TileCount  int       `xml:"tilecount,attr"`
+	Columns    int       `xml:"columns,attr"`
+	Image      *tmxImage `xml:"image"`
+	Tiles      []tmxTile `xml:"tile"`
+}
+
+type tmxImage struct {
+	Source string `xml:"source,attr"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+}
+
+type tmxTile struct {
+	ID         uint32        `xml:"id,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+type tmxProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type tmxLayer struct {
+	Name   string  `xml:"name,attr"`
+	Width  int     `xml:"width,attr"`
+	Height int     `xml:"height,attr"`
+	Data   tmxData `xml:"data"`
+}
+
+type tmxData struct {
+	Encoding string `xml:"encoding,attr"`
+	Content  string `xml:",chardata"`
+}
+
+type tmxObjectGroup struct {
+	Name    string      `xml:"name,attr"`
+	Objects []tmxObject `xml:"object"`
+}
+
+type tmxObject struct {
+	ID    int       `xml:"id,attr"`
+	Name  string    `xml:"name,attr"`
+	X     float32   `xml:"x,attr"`
+	Y     float32   `xml:"y,attr"`
+	Point *tmxPoint `xml:"point"`
+}
+
+type tmxPoint struct{}
+
+// WriteTMX writes the layout of the level as map for the Tiled editor. The map has one layer with the
+// tile types, with north on top, and an object group with the positions of the objects. The tileset has
+// one tile per tile type, identified by its property "tileType", with the image LevelLayoutTilesetImage.
+func (file *LevelFile) WriteTMX(writer io.Writer) error {
+	tileTypes := model.TileTypes()
+	tileset := tmxTileset{
+		FirstGID:   1,
+		Name:       "Tile Types",
+		TileWidth:  tmxTileSize,
+		TileHeight: tmxTileSize,
+		TileCount:  len(tileTypes),
+		Columns:    len(tileTypes),
+		Image:      &tmxImage{Source: LevelLayoutTilesetImage, Width: len(tileTypes) * tmxTileSize, Height: tmxTileSize}}
+	typeGIDs := make(map[model.TileType]uint32)
+	for index, tileType := range tileTypes {
+		tileset.Tiles = append(tileset.Tiles, tmxTile{
+			ID:         uint32(index),
+			Properties: []tmxProperty{{Name: tmxTileTypeProperty, Value: string(tileType)}}})
+		typeGIDs[tileType] = tileset.FirstGID + uint32(index)
+	}
+	rows := make([]string, len(file.Tiles))
+	for y, row := range file.Tiles {
+		gids := make([]string, len(row))
+		for x, properties := range row {
+			gid := uint32(0)
+			if properties.Type != nil {
+				gid = typeGIDs[*properties.Type]
+			}
+			gids[x] = fmt.Sprintf("%d", gid)
+		}
+		rows[len(rows)-1-y] = strings.Join(gids, ",")
+	}
+	objects := tmxObjectGroup{Name: "Objects"}
+	mapHeight := float32(len(file.Tiles) * tmxTileSize)
+	for _, object := range file.Objects {
+		properties := object.Properties
+		if (properties.Subclass == nil) || (properties.Type == nil) || (properties.TileX == nil) || (properties.FineX == nil) ||
+			(properties.TileY == nil) || (properties.FineY == nil) {
+			continue
+		}
+		objects.Objects = append(objects.Objects, tmxObject{
+			ID:    object.Index,
+			Name:  MakeObjectID(object.Class, *properties.Subclass, *properties.Type).String(),
+			X:     float32((*properties.TileX<<8)+*properties.FineX) * tmxTileSize / 256,
+			Y:     mapHeight - float32((*properties.TileY<<8)+*properties.FineY)*tmxTileSize/256,
+			Point: &tmxPoint{}})
+	}
+	tmx := tmxMap{
+		Version:      "1.0",
+		Orientation:  "orthogonal",
+		RenderOrder:  "right-down",
+		Width:        64,
+		Height:       len(file.Tiles),
+		TileWidth:    tmxTileSize,
+		TileHeight:   tmxTileSize,
+		Tilesets:     []tmxTileset{tileset},
+		Layers:       []tmxLayer{{Name: "Tile Types", Width: 64, Height: len(file.Tiles), Data: tmxData{Encoding: "csv", Content: "\n" + strings.Join(rows, ",\n") + "\n"}}},
+		ObjectGroups: []tmxObjectGroup{objects}}
+
+	io.WriteString(writer, xml.Header)
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", " ")
+	err := encoder.Encode(&tmx)
+	if err == nil {
+		_, err = io.WriteString(writer, "\n")
+	}
+	return err
+}
+
+// ReadTMXLevelFile reads a map of the Tiled editor and returns a level file that only has tiles,
+// with the tile types of the first tile layer of the map. Tiles are identified by their property "tileType",
+// as in the maps that WriteTMX creates. Tiles without this property, and empty tiles, keep their type.
+// Only maps of 64x64 tiles with CSV encoded layers are supported.
+func ReadTMXLevelFile(reader io.Reader) (*LevelFile, error) {
+	var tmx tmxMap
+	err := xml.NewDecoder(reader).Decode(&tmx)
+
+	if err != nil {
+		return nil, err
+	}
+	if (len(tmx.Layers) == 0) || (tmx.Layers[0].Width != 64) || (tmx.Layers[0].Height != 64) {
+		return nil, fmt.Errorf("map must have a tile layer of 64x64 tiles")
+	}
+	layer := tmx.Layers[0]
+	if layer.Data.Encoding != "csv" {
+		return nil, fmt.Errorf("tile layer must be CSV encoded")
+	}
+	typesByGID := make(map[uint32]model.TileType)
+	for _, tileset := range tmx.Tilesets {
+		for _, tile := range tileset.Tiles {
+			for _, property := range tile.Properties {
+				if property.Name == tmxTileTypeProperty {
+					typesByGID[tileset.FirstGID+tile.ID] = model.TileType(property.Value)
+				}
+			}
+		}
+	}
+	values := strings.Split(strings.TrimSpace(layer.Data.Content), ",")
+	if len(values) != 64*64 {
+		return nil, fmt.Errorf("tile layer must have 64x64 tiles")
+	}
+	file := &LevelFile{
+		Format:  LevelFileFormat,
+		Version: LevelFileVersion,
+		Tiles:   make([][]model.TileProperties, 64)}
+	for y := range file.Tiles {
+		file.Tiles[y] = make([]model.TileProperties, 64)
+	}
+	for index, value := range values {
+		gid, parseErr := strconv.ParseUint(strings.TrimSpace(value), 10, 32)
+		if parseErr != nil {
+			return nil, fmt.Errorf("invalid tile <%v>", value)
+		}
+		if tileType, known := typesByGID[uint32(gid)&^tmxFlipFlags]; known {
+			file.Tiles[63-index/64][index%64].Type = &tileType
+		}
+	}
+
+	return file, nil
+}
+
+// TileTypesImage returns the image of the tileset for maps of the Tiled editor. It has one tile per
+// tile type, in the order of model.TileTypes(). Solid parts are dark, the floor is brighter the higher it is.
+func TileTypesImage() image.Image {
+	tileTypes := model.TileTypes()
+	img := image.NewGray(image.Rect(0, 0, len(tileTypes)*tmxTileSize, tmxTileSize))
+
+	for index, tileType := range tileTypes {
+		for row := 0; row < tmxTileSize; row++ {
+			for column := 0; column < tmxTileSize; column++ {
+				u := (float32(column) + 0.5) / tmxTileSize
+				v := 1.0 - (float32(row)+0.5)/tmxTileSize
+				brightness := uint8(32)
+				if height, open := tileFloorHeight(tileType, u, v); open {
+					brightness = uint8(96 + 128*height)
+				}
+				img.SetGray(index*tmxTileSize+column, row, color.Gray{Y: brightness})
+			}
+		}
+	}
+
+	return img
+}
+
+// tileFloorHeight returns the relative height of the floor at given position within a tile, for
+// a tile type. The position is in the range [0..1], from the south-west corner. Closed parts of a tile
+// are reported as not open.
+func tileFloorHeight(tileType model.TileType, u, v float32) (height float32, open bool) {
+	min := func(a, b float32) float32 {
+		if a < b {
+			return a
+		}
+		return b
+	}
+	max := func(a, b float32) float32 {
+		if a > b {
+			return a
+		}
+		return b
+	}
+
+	open = true
+	height = 0.5
+	switch tileType {
+	case model.Solid:
+		open = false
+	case model.DiagonalOpenSouthEast:
+		open = u >= v
+	case model.DiagonalOpenSouthWest:
+		open = u+v <= 1
+	case model.DiagonalOpenNorthWest:
+		open = v >= u
+	case model.DiagonalOpenNorthEast:
+		open = u+v >= 1
+	case model.SlopeSouthToNorth:
+		height = v
+	case model.SlopeWestToEast:
+		height = u
+	case model.SlopeNorthToSouth:
+		height = 1 - v
+	case model.SlopeEastToWest:
+		height = 1 - u
+	case model.ValleySouthEastToNorthWest:
+		height = min(1-u, v)
+	case model.ValleySouthWestToNorthEast:
+		height = min(u, v)
+	case model.ValleyNorthWestToSouthEast:
+		height = min(u, 1-v)
+	case model.ValleyNorthEastToSouthWest:
+		height = min(1-u, 1-v)
+	case model.RidgeNorthWestToSouthEast:
+		height = max(u, 1-v)
+	case model.RidgeNorthEastToSouthWest:
+		height = max(1-u, 1-v)
+	case model.RidgeSouthEastToNorthWest:
+		height = max(1-u, v)
+	case model.RidgeSouthWestToNorthEast:
+		height = max(u, v)
+	}
+
+	return
+}
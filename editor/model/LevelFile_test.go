@@ -0,0 +1,244 @@
+package model
+
+import (
+	"bytes"
+	"fmt"
+
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/editor/model/memstore"
+	"github.com/inkyblackness/shocked-model"
+)
+
+type LevelFileSuite struct {
+	store   *memstore.DataStore
+	source  *memstore.Level
+	target  *memstore.Level
+	adapter *Adapter
+}
+
+var _ = check.Suite(&LevelFileSuite{})
+
+func (suite *LevelFileSuite) SetUpTest(c *check.C) {
+	suite.store = memstore.NewDataStore(nil)
+	project := suite.store.Project("project")
+	suite.source = project.AddLevel("archive", 1)
+	suite.target = project.AddLevel("archive", 2)
+	suite.adapter = NewAdapter(suite.store)
+	suite.adapter.RequestProject("project")
+	suite.store.Flush()
+}
+
+func (suite *LevelFileSuite) givenSourceContent() {
+	heightShift := 5
+	suite.source.SetProperties(model.LevelProperties{HeightShift: &heightShift})
+	open := model.Open
+	slope := model.SlopeSouthToNorth
+	floor := 3
+	suite.source.SetTile(10, 20, model.TileProperties{Type: &open,
+		RealWorld: &model.RealWorldTileProperties{FloorTexture: &floor}})
+	suite.source.SetTile(11, 20, model.TileProperties{Type: &slope})
+	suite.source.SetTextures([]int{7, 8, 9})
+	frames := 4
+	suite.source.SetAnimation(1, model.TextureAnimation{FrameCount: &frames})
+
+	subclass, tileX := 2, 10
+	suite.source.AddObject(1, model.LevelObjectProperties{})
+	suite.source.AddObject(1, model.LevelObjectProperties{})
+	suite.source.AddObject(3, model.LevelObjectProperties{Subclass: &subclass, TileX: &tileX, ExtraData: []byte{1, 2, 3}})
+	suite.source.RemoveObject(2)
+	sourceIndex := 3
+	suite.source.SetSurveillance(1, model.SurveillanceObject{SourceIndex: &sourceIndex})
+}
+
+func (suite *LevelFileSuite) exportSource() *LevelFile {
+	suite.adapter.RequestActiveLevel(1)
+	suite.store.Flush()
+	return suite.adapter.ActiveLevel().Export()
+}
+
+func (suite *LevelFileSuite) TestExportOfUnloadedLevelIsEmpty(c *check.C) {
+	file := suite.adapter.ActiveLevel().Export()
+
+	c.Check(file.Surveillance, check.DeepEquals, []model.SurveillanceObject{})
+	c.Check(len(file.Objects), check.Equals, 0)
+}
+
+func (suite *LevelFileSuite) TestExportContainsAllSections(c *check.C) {
+	suite.givenSourceContent()
+	file := suite.exportSource()
+
+	c.Check(file.Format, check.Equals, LevelFileFormat)
+	c.Assert(file.Properties, check.NotNil)
+	c.Check(*file.Properties.HeightShift, check.Equals, 5)
+	c.Check(*file.Tiles[20][10].Type, check.Equals, model.Open)
+	c.Check(*file.Tiles[20][10].RealWorld.FloorTexture, check.Equals, 3)
+	c.Check(file.Tiles[20][10].CalculatedWallHeights, check.IsNil)
+	c.Check(file.Textures, check.DeepEquals, []int{7, 8, 9})
+	c.Check(*file.TextureAnimations[1].FrameCount, check.Equals, 4)
+	c.Assert(len(file.Objects), check.Equals, 2)
+	c.Check(file.Objects[1].Index, check.Equals, 3)
+	c.Check(file.Objects[1].Class, check.Equals, 3)
+	c.Check(file.Objects[1].Properties.ExtraData, check.DeepEquals, []byte{1, 2, 3})
+	c.Check(*file.Surveillance[1].SourceIndex, check.Equals, 3)
+}
+
+func (suite *LevelFileSuite) TestImportReplaysFileIntoOtherLevel(c *check.C) {
+	suite.givenSourceContent()
+	for i := 0; i < 4; i++ {
+		suite.target.AddObject(5, model.LevelObjectProperties{})
+	}
+	file := suite.exportSource()
+	movedObjects := -1
+
+	suite.adapter.RequestLevelImport(2, file, func(moved int, err error) {
+		movedObjects = moved
+		c.Check(err, check.IsNil)
+	})
+	suite.store.Flush()
+
+	c.Check(movedObjects, check.Equals, 0)
+	c.Check(*suite.target.Properties().HeightShift, check.Equals, 5)
+	c.Check(*suite.target.Tile(10, 20).Type, check.Equals, model.Open)
+	c.Check(*suite.target.Tile(10, 20).RealWorld.FloorTexture, check.Equals, 3)
+	c.Check(*suite.target.Tile(11, 20).Type, check.Equals, model.SlopeSouthToNorth)
+	c.Check(suite.target.Textures(), check.DeepEquals, []int{7, 8, 9})
+	c.Check(*suite.target.Animations()[1].FrameCount, check.Equals, 4)
+	c.Check(suite.target.ObjectIDs(), check.DeepEquals, []int{1, 3})
+	object, _ := suite.target.Object(3)
+	c.Check(object.Class, check.Equals, 3)
+	c.Check(*object.Properties.Subclass, check.Equals, 2)
+	c.Check(*object.Properties.TileX, check.Equals, 10)
+	c.Check(object.Properties.ExtraData, check.DeepEquals, []byte{1, 2, 3})
+	c.Check(*suite.target.Surveillance()[1].SourceIndex, check.Equals, 3)
+}
+
+func (suite *LevelFileSuite) TestImportReloadsActiveLevel(c *check.C) {
+	suite.givenSourceContent()
+	file := suite.exportSource()
+	suite.adapter.RequestActiveLevel(2)
+	suite.store.Flush()
+
+	suite.adapter.RequestLevelImport(2, file, nil)
+	suite.store.Flush()
+
+	c.Check(suite.adapter.ActiveLevel().LevelTextureIDs(), check.DeepEquals, []int{7, 8, 9})
+}
+
+func (suite *LevelFileSuite) TestImportKeepsMissingSections(c *check.C) {
+	suite.target.AddObject(5, model.LevelObjectProperties{})
+	suite.target.SetTextures([]int{1})
+
+	suite.adapter.RequestLevelImport(2, &LevelFile{Format: LevelFileFormat, Version: LevelFileVersion}, nil)
+	suite.store.Flush()
+
+	c.Check(suite.target.ObjectIDs(), check.DeepEquals, []int{1})
+	c.Check(suite.target.Textures(), check.DeepEquals, []int{1})
+}
+
+func (suite *LevelFileSuite) TestImportClearsTrailingEntries(c *check.C) {
+	frames, sourceIndex := 4, 7
+	suite.target.SetAnimation(2, model.TextureAnimation{FrameCount: &frames})
+	suite.target.SetSurveillance(3, model.SurveillanceObject{SourceIndex: &sourceIndex})
+	file := &LevelFile{Format: LevelFileFormat, Version: LevelFileVersion,
+		TextureAnimations: []model.TextureAnimation{{FrameCount: &frames}},
+		Surveillance:      []model.SurveillanceObject{{SourceIndex: &sourceIndex}}}
+
+	suite.adapter.RequestLevelImport(2, file, nil)
+	suite.store.Flush()
+
+	c.Check(*suite.target.Animations()[0].FrameCount, check.Equals, 4)
+	c.Check(*suite.target.Animations()[2].FrameCount, check.Equals, 0)
+	c.Check(*suite.target.Surveillance()[0].SourceIndex, check.Equals, 7)
+	c.Check(*suite.target.Surveillance()[3].SourceIndex, check.Equals, 0)
+}
+
+func (suite *LevelFileSuite) TestImportReportsFailedRequests(c *check.C) {
+	suite.givenSourceContent()
+	file := suite.exportSource()
+	suite.store.Fail("SetTile", 1)
+	calls := 0
+	var result error
+
+	suite.adapter.RequestLevelImport(2, file, func(moved int, err error) {
+		calls++
+		result = err
+	})
+	suite.store.Flush()
+
+	c.Check(calls, check.Equals, 1)
+	c.Check(result, check.NotNil)
+	c.Check(suite.target.ObjectIDs(), check.DeepEquals, []int{1, 3})
+}
+
+func (suite *LevelFileSuite) TestWrittenFileCanBeRead(c *check.C) {
+	suite.givenSourceContent()
+	file := suite.exportSource()
+	buffer := bytes.NewBuffer(nil)
+
+	err := file.Write(buffer)
+	c.Assert(err, check.IsNil)
+	read, err := ReadLevelFile(buffer)
+
+	c.Assert(err, check.IsNil)
+	c.Check(read, check.DeepEquals, file)
+}
+
+func (suite *LevelFileSuite) TestReadRejectsOtherDocuments(c *check.C) {
+	_, err := ReadLevelFile(bytes.NewBufferString(`{"format": "other"}`))
+	c.Check(err, check.NotNil)
+
+	_, err = ReadLevelFile(bytes.NewBufferString(`{"format": "shocked-level", "version": 1, "tiles": [[]]}`))
+	c.Check(err, check.NotNil)
+}
+
+func (suite *LevelFileSuite) TestTMXLayoutKeepsTileTypes(c *check.C) {
+	suite.givenSourceContent()
+	file := suite.exportSource()
+	buffer := bytes.NewBuffer(nil)
+
+	err := file.WriteTMX(buffer)
+	c.Assert(err, check.IsNil)
+	read, err := ReadTMXLevelFile(buffer)
+
+	c.Assert(err, check.IsNil)
+	c.Check(*read.Tiles[20][10].Type, check.Equals, model.Open)
+	c.Check(*read.Tiles[20][11].Type, check.Equals, model.SlopeSouthToNorth)
+	c.Check(*read.Tiles[0][0].Type, check.Equals, model.Solid)
+	c.Check(read.Tiles[20][10].RealWorld, check.IsNil)
+	c.Check(read.Properties, check.IsNil)
+	c.Check(read.Objects, check.IsNil)
+}
+
+func (suite *LevelFileSuite) TestTMXLayoutIdentifiesTilesByProperty(c *check.C) {
+	tmx := `<map version="1.0" orientation="orthogonal" width="64" height="64" tilewidth="32" tileheight="32">
+ <tileset firstgid="10" name="custom"><tile id="2"><properties><property name="tileType" value="open"/></properties></tile></tileset>
+ <layer name="sketch" width="64" height="64"><data encoding="csv">` + tmxCSV(map[int]uint32{63*64 + 1: 12, 0: 12 | 0x80000000}) + `</data></layer>
+</map>`
+
+	read, err := ReadTMXLevelFile(bytes.NewBufferString(tmx))
+
+	c.Assert(err, check.IsNil)
+	c.Check(*read.Tiles[0][1].Type, check.Equals, model.Open)
+	c.Check(*read.Tiles[63][0].Type, check.Equals, model.Open)
+	c.Check(read.Tiles[0][0].Type, check.IsNil)
+}
+
+func (suite *LevelFileSuite) TestTMXLayoutRequiresCSV(c *check.C) {
+	tmx := `<map><layer width="64" height="64"><data encoding="base64">AAAA</data></layer></map>`
+
+	_, err := ReadTMXLevelFile(bytes.NewBufferString(tmx))
+
+	c.Check(err, check.NotNil)
+}
+
+func tmxCSV(gids map[int]uint32) string {
+	buffer := bytes.NewBuffer(nil)
+	for index := 0; index < 64*64; index++ {
+		if index > 0 {
+			buffer.WriteString(",")
+		}
+		buffer.WriteString(fmt.Sprintf("%d", gids[index]))
+	}
+	return buffer.String()
+}
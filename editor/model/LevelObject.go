@@ -37,6 +37,14 @@ func (obj *LevelObject) ID() ObjectID {
 	return MakeObjectID(obj.class, *obj.properties.Subclass, *obj.properties.Type)
 }
 
+// Properties returns a copy of all properties of the object.
+func (obj *LevelObject) Properties() model.LevelObjectProperties {
+	properties := *obj.properties
+	properties.ClassData = append([]byte{}, obj.properties.ClassData...)
+	properties.ExtraData = append([]byte{}, obj.properties.ExtraData...)
+	return properties
+}
+
 // ClassData returns the raw data for the level object.
 func (obj *LevelObject) ClassData() []byte {
 	return obj.properties.ClassData
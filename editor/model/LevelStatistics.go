@@ -0,0 +1,158 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/inkyblackness/shocked-model"
+)
+
+// LevelStatistics summarizes the contents of a level.
+type LevelStatistics struct {
+	// ObjectCounts is the amount of objects per object ID.
+	ObjectCounts map[ObjectID]int
+	// TileTypeCounts is the amount of tiles per type.
+	TileTypeCounts map[model.TileType]int
+	// TextureUsageCounts is the amount of uses per level texture slot. Floors, ceilings and walls
+	// of all tiles that are not solid are counted. Cyberspace levels use no textures.
+	TextureUsageCounts map[int]int
+	// SurveillanceCount is the amount of surveillance entries that have a source object.
+	SurveillanceCount int
+	// AnimationGroupCount is the amount of texture animation groups that have frames.
+	AnimationGroupCount int
+}
+
+func newLevelStatistics() *LevelStatistics {
+	return &LevelStatistics{
+		ObjectCounts:       make(map[ObjectID]int),
+		TileTypeCounts:     make(map[model.TileType]int),
+		TextureUsageCounts: make(map[int]int)}
+}
+
+// ObjectCount returns the total amount of objects.
+func (stats *LevelStatistics) ObjectCount() (count int) {
+	for _, idCount := range stats.ObjectCounts {
+		count += idCount
+	}
+	return
+}
+
+// ObjectClassCount returns the amount of objects of given class.
+func (stats *LevelStatistics) ObjectClassCount(class int) (count int) {
+	for id, idCount := range stats.ObjectCounts {
+		if id.Class() == class {
+			count += idCount
+		}
+	}
+	return
+}
+
+func (stats *LevelStatistics) addTile(properties *model.TileProperties, isCyberspace bool) {
+	if (properties == nil) || (properties.Type == nil) {
+		return
+	}
+	stats.TileTypeCounts[*properties.Type]++
+	if !isCyberspace && (*properties.Type != model.Solid) && (properties.RealWorld != nil) {
+		for _, texture := range []*int{properties.RealWorld.FloorTexture, properties.RealWorld.CeilingTexture, properties.RealWorld.WallTexture} {
+			if texture != nil {
+				stats.TextureUsageCounts[*texture]++
+			}
+		}
+	}
+}
+
+func (stats *LevelStatistics) addObject(id ObjectID) {
+	stats.ObjectCounts[id]++
+}
+
+func (stats *LevelStatistics) addSurveillance(object model.SurveillanceObject) {
+	if (object.SourceIndex != nil) && (*object.SourceIndex != 0) {
+		stats.SurveillanceCount++
+	}
+}
+
+func (stats *LevelStatistics) addAnimation(animation model.TextureAnimation) {
+	if (animation.FrameCount != nil) && (*animation.FrameCount > 0) {
+		stats.AnimationGroupCount++
+	}
+}
+
+// Statistics returns the statistics of the level as it is currently loaded.
+func (adapter *LevelAdapter) Statistics() *LevelStatistics {
+	stats := newLevelStatistics()
+	isCyberspace := adapter.IsCyberspace()
+
+	for _, tile := range adapter.tileMap.tiles {
+		if properties, _ := tile.properties.get().(*model.TileProperties); properties != nil {
+			stats.addTile(properties, isCyberspace)
+		}
+	}
+	if value := adapter.levelObjects.get(); value != nil {
+		for _, object := range *value.(*map[int]*LevelObject) {
+			stats.addObject(object.ID())
+		}
+	}
+	if value := adapter.levelSurveillance.get(); value != nil {
+		for _, object := range *value.(*[]model.SurveillanceObject) {
+			stats.addSurveillance(object)
+		}
+	}
+	if value := adapter.levelTextureAnimationGroups.get(); value != nil {
+		for _, group := range *value.(*[]*LevelTextureAnimationGroup) {
+			stats.addAnimation(group.properties)
+		}
+	}
+
+	return stats
+}
+
+// RequestLevelStatistics requests the statistics of the identified level of the active archive.
+// The level is queried from the store without becoming the active level. The callback is
+// called once all data of the level has been received. Should a query fail, the callback is
+// called with an error instead of statistics.
+func (adapter *Adapter) RequestLevelStatistics(levelID int, onStatistics func(levelID int, stats *LevelStatistics, err error)) {
+	var properties model.LevelProperties
+	var tiles model.Tiles
+	var objects *model.LevelObjects
+	var animations []model.TextureAnimation
+	var surveillance []model.SurveillanceObject
+	var batch *storeBatch
+	batch = newStoreBatch(adapter, func() {
+		if batch.failed {
+			onStatistics(levelID, nil, fmt.Errorf("statistics of level %d could not be loaded", levelID))
+			return
+		}
+		stats := newLevelStatistics()
+		isCyberspace := (properties.CyberspaceFlag != nil) && *properties.CyberspaceFlag
+		for _, row := range tiles.Table {
+			for index := range row {
+				stats.addTile(&row[index], isCyberspace)
+			}
+		}
+		if objects != nil {
+			for _, object := range objects.Table {
+				stats.addObject(MakeObjectID(object.Class,
+					safeInt(object.Properties.Subclass, 0), safeInt(object.Properties.Type, 0)))
+			}
+		}
+		for _, object := range surveillance {
+			stats.addSurveillance(object)
+		}
+		for _, animation := range animations {
+			stats.addAnimation(animation)
+		}
+		onStatistics(levelID, stats, nil)
+	})
+	projectID, archiveID := adapter.ActiveProjectID(), adapter.ActiveArchiveID()
+
+	adapter.store.LevelProperties(projectID, archiveID, levelID,
+		func(value model.LevelProperties) { properties = value; batch.done() }, batch.request("LevelProperties"))
+	adapter.store.Tiles(projectID, archiveID, levelID,
+		func(value model.Tiles) { tiles = value; batch.done() }, batch.request("Tiles"))
+	adapter.store.LevelObjects(projectID, archiveID, levelID,
+		func(value *model.LevelObjects) { objects = value; batch.done() }, batch.request("LevelObjects"))
+	adapter.store.LevelTextureAnimations(projectID, archiveID, levelID,
+		func(value []model.TextureAnimation) { animations = value; batch.done() }, batch.request("LevelTextureAnimations"))
+	adapter.store.LevelSurveillanceObjects(projectID, archiveID, levelID,
+		func(value []model.SurveillanceObject) { surveillance = value; batch.done() }, batch.request("LevelSurveillance"))
+	batch.done()
+}
@@ -0,0 +1,108 @@
+package model
+
+import (
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/editor/model/memstore"
+	"github.com/inkyblackness/shocked-model"
+)
+
+type LevelStatisticsSuite struct {
+	store   *memstore.DataStore
+	level   *memstore.Level
+	adapter *Adapter
+}
+
+var _ = check.Suite(&LevelStatisticsSuite{})
+
+func (suite *LevelStatisticsSuite) SetUpTest(c *check.C) {
+	suite.store = memstore.NewDataStore(nil)
+	project := suite.store.Project("project")
+	project.AddLevel("archive", 0)
+	suite.level = project.AddLevel("archive", 1)
+	suite.adapter = NewAdapter(suite.store)
+	suite.adapter.RequestProject("project")
+	suite.store.Flush()
+}
+
+func (suite *LevelStatisticsSuite) givenLevelContent() {
+	open := model.Open
+	floor, ceiling, wall := 1, 2, 1
+	for x := 0; x < 3; x++ {
+		suite.level.SetTile(x, 0, model.TileProperties{Type: &open,
+			RealWorld: &model.RealWorldTileProperties{FloorTexture: &floor, CeilingTexture: &ceiling, WallTexture: &wall}})
+	}
+	subclass := 2
+	suite.level.AddObject(1, model.LevelObjectProperties{})
+	suite.level.AddObject(1, model.LevelObjectProperties{})
+	suite.level.AddObject(1, model.LevelObjectProperties{Subclass: &subclass})
+	suite.level.AddObject(3, model.LevelObjectProperties{})
+	source := 1
+	suite.level.SetSurveillance(2, model.SurveillanceObject{SourceIndex: &source})
+	frames := 4
+	suite.level.SetAnimation(1, model.TextureAnimation{FrameCount: &frames})
+}
+
+func (suite *LevelStatisticsSuite) verifyStatistics(c *check.C, stats *LevelStatistics) {
+	c.Check(stats.ObjectCount(), check.Equals, 4)
+	c.Check(stats.ObjectClassCount(1), check.Equals, 3)
+	c.Check(stats.ObjectCounts[MakeObjectID(1, 2, 0)], check.Equals, 1)
+	c.Check(stats.TileTypeCounts, check.DeepEquals, map[model.TileType]int{model.Open: 3, model.Solid: 64*64 - 3})
+	c.Check(stats.TextureUsageCounts, check.DeepEquals, map[int]int{1: 6, 2: 3})
+	c.Check(stats.SurveillanceCount, check.Equals, 1)
+	c.Check(stats.AnimationGroupCount, check.Equals, 1)
+}
+
+func (suite *LevelStatisticsSuite) TestStatisticsOfActiveLevel(c *check.C) {
+	suite.givenLevelContent()
+	suite.adapter.RequestActiveLevel(1)
+	suite.store.Flush()
+
+	suite.verifyStatistics(c, suite.adapter.ActiveLevel().Statistics())
+}
+
+func (suite *LevelStatisticsSuite) TestStatisticsCanBeRequestedForInactiveLevels(c *check.C) {
+	suite.givenLevelContent()
+	suite.adapter.RequestActiveLevel(0)
+	suite.store.Flush()
+	var receivedID int
+	var received *LevelStatistics
+
+	suite.adapter.RequestLevelStatistics(1, func(levelID int, stats *LevelStatistics, err error) {
+		receivedID, received = levelID, stats
+	})
+	suite.store.Flush()
+
+	c.Check(suite.adapter.ActiveLevel().ID(), check.Equals, 0)
+	c.Assert(received, check.NotNil)
+	c.Check(receivedID, check.Equals, 1)
+	suite.verifyStatistics(c, received)
+}
+
+func (suite *LevelStatisticsSuite) TestStatisticsReportFailedQueries(c *check.C) {
+	suite.store.Fail("Tiles", 1)
+	var receivedErr error
+	called := 0
+
+	suite.adapter.RequestLevelStatistics(1, func(levelID int, stats *LevelStatistics, err error) {
+		called++
+		receivedErr = err
+	})
+	suite.store.Flush()
+
+	c.Check(called, check.Equals, 1)
+	c.Check(receivedErr, check.NotNil)
+}
+
+func (suite *LevelStatisticsSuite) TestStatisticsCountObjectsWithMissingProperties(c *check.C) {
+	suite.level.ReplaceObject(1, 2, model.LevelObjectProperties{})
+	var received *LevelStatistics
+
+	suite.adapter.RequestLevelStatistics(1, func(levelID int, stats *LevelStatistics, err error) {
+		received = stats
+	})
+	suite.store.Flush()
+
+	c.Assert(received, check.NotNil)
+	c.Check(received.ObjectCounts[MakeObjectID(2, 0, 0)], check.Equals, 1)
+}
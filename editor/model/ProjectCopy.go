@@ -0,0 +1,275 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/inkyblackness/shocked-model"
+)
+
+// The functions in this file copy resources from another project into the active project.
+// The resources are first read completely, from the other project and from the active project.
+// Only if this succeeded, the callback is called with the copy. It is written to the active
+// project only when applied, and can be reverted, so that copies can be performed as commands.
+// Resources keep their identification, they replace the resources of the same identifier.
+
+// ResourceCopy is a resource read from another project, together with the resource of the
+// active project it replaces.
+type ResourceCopy struct {
+	apply  func(onDone func())
+	revert func(onDone func())
+}
+
+// Apply writes the resource of the other project. The callback is called once it is written.
+func (resourceCopy ResourceCopy) Apply(onDone func()) {
+	resourceCopy.apply(onDone)
+}
+
+// Revert writes the replaced resource again. The callback is called once it is written.
+func (resourceCopy ResourceCopy) Revert(onDone func()) {
+	resourceCopy.revert(onDone)
+}
+
+// RequestLevelCopy requests the content of the identified level of another project, together with the
+// current content of the level of the active project. Both are provided as level files, which can be
+// imported like any other level file, see RequestLevelImport().
+func (adapter *Adapter) RequestLevelCopy(fromProjectID string, levelID int, onRead func(before, after *LevelFile)) {
+	adapter.readStoredLevel(adapter.ActiveProjectID(), levelID, func(before *LevelFile) {
+		adapter.readStoredLevel(fromProjectID, levelID, func(after *LevelFile) {
+			onRead(before, after)
+		})
+	})
+}
+
+// readStoredLevel reads all sections of a level of the active archive of given project into a level file.
+func (adapter *Adapter) readStoredLevel(projectID string, levelID int, onRead func(file *LevelFile)) {
+	archiveID := adapter.ActiveArchiveID()
+	file := &LevelFile{Format: LevelFileFormat, Version: LevelFileVersion}
+	var batch *storeBatch
+	batch = newStoreBatch(adapter, func() {
+		if !batch.failed {
+			onRead(file)
+		}
+	})
+
+	adapter.store.LevelProperties(projectID, archiveID, levelID, func(properties model.LevelProperties) {
+		file.Properties = &properties
+		batch.done()
+	}, batch.request("LevelProperties"))
+	adapter.store.Tiles(projectID, archiveID, levelID, func(tiles model.Tiles) {
+		file.Tiles = tiles.Table
+		batch.done()
+	}, batch.request("Tiles"))
+	adapter.store.LevelTextures(projectID, archiveID, levelID, func(textureIDs []int) {
+		file.Textures = textureIDs
+		batch.done()
+	}, batch.request("LevelTextures"))
+	adapter.store.LevelTextureAnimations(projectID, archiveID, levelID, func(animations []model.TextureAnimation) {
+		file.TextureAnimations = animations
+		batch.done()
+	}, batch.request("LevelTextureAnimations"))
+	adapter.store.LevelObjects(projectID, archiveID, levelID, func(objects *model.LevelObjects) {
+		file.Objects = []LevelFileObject{}
+		for _, object := range objects.Table {
+			file.Objects = append(file.Objects, LevelFileObject{
+				Index:      object.ID,
+				Class:      object.Class,
+				Properties: object.Properties})
+		}
+		batch.done()
+	}, batch.request("LevelObjects"))
+	adapter.store.LevelSurveillanceObjects(projectID, archiveID, levelID, func(objects []model.SurveillanceObject) {
+		file.Surveillance = objects
+		batch.done()
+	}, batch.request("LevelSurveillance"))
+	batch.done()
+}
+
+// RequestTextureCopy requests a copy of the properties and the bitmaps of all sizes of the identified
+// texture of another project. The texture must exist in both projects.
+func (adapter *Adapter) RequestTextureCopy(fromProjectID string, textureID int, onRead func(ResourceCopy)) {
+	if (textureID < 0) || (textureID >= adapter.textureAdapter.WorldTextureCount()) {
+		adapter.SetMessage(fmt.Sprintf("Texture %d does not exist in project %s", textureID, adapter.ActiveProjectID()))
+		return
+	}
+	var batch *storeBatch
+	var before, after *storedTexture
+	batch = newStoreBatch(adapter, func() {
+		if !batch.failed {
+			onRead(ResourceCopy{
+				apply:  adapter.textureWriter(textureID, after),
+				revert: adapter.textureWriter(textureID, before)})
+		}
+	})
+	before = adapter.readTexture(adapter.ActiveProjectID(), textureID, batch)
+	after = adapter.readTexture(fromProjectID, textureID, batch)
+	batch.done()
+}
+
+// storedTexture keeps the properties and bitmaps of a texture read from a project.
+type storedTexture struct {
+	properties *model.TextureProperties
+	bitmaps    map[model.TextureSize]*model.RawBitmap
+}
+
+func (adapter *Adapter) readTexture(projectID string, textureID int, batch *storeBatch) *storedTexture {
+	texture := &storedTexture{bitmaps: make(map[model.TextureSize]*model.RawBitmap)}
+	adapter.store.Textures(projectID, func(textures []model.TextureProperties) {
+		if textureID < len(textures) {
+			texture.properties = &textures[textureID]
+			adapter.requestTextureBitmaps(projectID, textureID, texture.bitmaps, batch)
+		} else {
+			batch.failed = true
+			adapter.SetMessage(fmt.Sprintf("Texture %d does not exist in project %s", textureID, projectID))
+		}
+		batch.done()
+	}, batch.request("Textures"))
+	return texture
+}
+
+func (adapter *Adapter) textureWriter(textureID int, texture *storedTexture) func(onDone func()) {
+	return func(onDone func()) {
+		adapter.textureAdapter.RequestTexturePropertiesChange(textureID, texture.properties)
+		for size, bmp := range texture.bitmaps {
+			adapter.textureAdapter.RequestTextureBitmapChange(textureID, size, bmp)
+		}
+		onDone()
+	}
+}
+
+func (adapter *Adapter) requestTextureBitmaps(projectID string, textureID int,
+	bitmaps map[model.TextureSize]*model.RawBitmap, batch *storeBatch) {
+	for _, size := range model.TextureSizes() {
+		textureSize := size
+		adapter.store.TextureBitmap(projectID, textureID, string(textureSize), func(bmp *model.RawBitmap) {
+			bitmaps[textureSize] = bmp
+			batch.done()
+		}, batch.request(fmt.Sprintf("WorldTexture[%v][%v]", textureSize, textureID)))
+	}
+}
+
+// RequestGameObjectCopy requests a copy of the properties and the bitmaps of the identified game object
+// of another project. The object must exist in both projects.
+// Bitmaps the other project does not have are kept.
+func (adapter *Adapter) RequestGameObjectCopy(fromProjectID string, id ObjectID, onRead func(ResourceCopy)) {
+	if adapter.objectsAdapter.Object(id) == nil {
+		adapter.SetMessage(fmt.Sprintf("Object %v does not exist in project %s", id, adapter.ActiveProjectID()))
+		return
+	}
+	var batch *storeBatch
+	var before, after *storedGameObject
+	batch = newStoreBatch(adapter, func() {
+		if !batch.failed {
+			onRead(ResourceCopy{
+				apply:  adapter.gameObjectWriter(id, after),
+				revert: adapter.gameObjectWriter(id, before)})
+		}
+	})
+	before = adapter.readGameObject(adapter.ActiveProjectID(), id, batch)
+	after = adapter.readGameObject(fromProjectID, id, batch)
+	batch.done()
+}
+
+// storedGameObject keeps the properties and the available bitmaps of a game object read from a project.
+type storedGameObject struct {
+	properties *model.GameObjectProperties
+	bitmaps    map[int]*model.RawBitmap
+}
+
+func (adapter *Adapter) readGameObject(projectID string, id ObjectID, batch *storeBatch) *storedGameObject {
+	object := &storedGameObject{bitmaps: make(map[int]*model.RawBitmap)}
+	adapter.store.GameObjects(projectID, func(objects []model.GameObject) {
+		for index, entry := range objects {
+			if MakeObjectID(entry.Class, entry.Subclass, entry.Type) == id {
+				object.properties = &objects[index].Properties
+			}
+		}
+		if object.properties == nil {
+			batch.failed = true
+			adapter.SetMessage(fmt.Sprintf("Object %v does not exist in project %s", id, projectID))
+		} else {
+			adapter.requestGameObjectBitmaps(projectID, id, gameObjectBitmapCount(object.properties.Data.Common),
+				object.bitmaps, batch)
+		}
+		batch.done()
+	}, batch.request("GameObjects"))
+	return object
+}
+
+func (adapter *Adapter) gameObjectWriter(id ObjectID, object *storedGameObject) func(onDone func()) {
+	return func(onDone func()) {
+		adapter.objectsAdapter.RequestObjectPropertiesChange(id, object.properties)
+		for index, bmp := range object.bitmaps {
+			adapter.objectsAdapter.RequestBitmapChange(ObjectBitmapID{ObjectID: id, Index: index}, bmp)
+		}
+		adapter.objectsAdapter.refresh()
+		onDone()
+	}
+}
+
+func (adapter *Adapter) requestGameObjectBitmaps(projectID string, id ObjectID, count int,
+	bitmaps map[int]*model.RawBitmap, batch *storeBatch) {
+	for index := 0; index < count; index++ {
+		bitmapIndex := index
+		adapter.store.GameObjectBitmap(projectID, id.Class(), id.Subclass(), id.Type(), bitmapIndex,
+			func(bmp *model.RawBitmap) {
+				bitmaps[bitmapIndex] = bmp
+				batch.done()
+			}, batch.optional())
+	}
+}
+
+// RequestTextCopy requests a copy of the text of given type and index, in all languages, of another
+// project. Languages the other project has no text for are kept. Reverting the copy clears the texts
+// of the languages the active project had no text for.
+func (adapter *Adapter) RequestTextCopy(fromProjectID string, resourceType model.ResourceType, index int,
+	onRead func(ResourceCopy)) {
+	toProjectID := adapter.ActiveProjectID()
+	before := make(map[model.ResourceKey]string)
+	after := make(map[model.ResourceKey]string)
+	batch := newStoreBatch(adapter, func() {
+		if len(after) == 0 {
+			adapter.SetMessage(fmt.Sprintf("Text %d does not exist in project %s", index, fromProjectID))
+			return
+		}
+		for key := range after {
+			if _, existing := before[key]; !existing {
+				before[key] = ""
+			}
+		}
+		onRead(ResourceCopy{
+			apply:  func(onDone func()) { adapter.writeTexts(toProjectID, after, onDone) },
+			revert: func(onDone func()) { adapter.writeTexts(toProjectID, before, onDone) }})
+	})
+	adapter.readTexts(toProjectID, resourceType, index, before, batch)
+	adapter.readTexts(fromProjectID, resourceType, index, after, batch)
+	batch.done()
+}
+
+func (adapter *Adapter) readTexts(projectID string, resourceType model.ResourceType, index int,
+	texts map[model.ResourceKey]string, batch *storeBatch) {
+	for _, language := range model.LocalLanguages() {
+		key := model.MakeLocalizedResourceKey(resourceType, language, uint16(index))
+		adapter.store.Text(projectID, key, func(resourceKey model.ResourceKey, text string) {
+			texts[resourceKey] = text
+			batch.done()
+		}, batch.optional())
+	}
+}
+
+func (adapter *Adapter) writeTexts(projectID string, texts map[model.ResourceKey]string, onDone func()) {
+	var batch *storeBatch
+	batch = newStoreBatch(adapter, func() {
+		if !batch.failed {
+			onDone()
+		}
+	})
+	for key, text := range texts {
+		adapter.store.SetText(projectID, key, text, func(resourceKey model.ResourceKey, text string) {
+			if resourceKey == adapter.textAdapter.ResourceKey() {
+				adapter.textAdapter.onText(resourceKey, text)
+			}
+			batch.done()
+		}, batch.request("SetText"))
+	}
+	batch.done()
+}
@@ -0,0 +1,263 @@
+package model
+
+import (
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/editor/model/memstore"
+	"github.com/inkyblackness/shocked-client/editor/model/multistore"
+	"github.com/inkyblackness/shocked-model"
+)
+
+type ProjectCopySuite struct {
+	mod      *memstore.DataStore
+	original *memstore.DataStore
+	adapter  *Adapter
+	copied   []ResourceCopy
+	done     int
+}
+
+var _ = check.Suite(&ProjectCopySuite{})
+
+func (suite *ProjectCopySuite) SetUpTest(c *check.C) {
+	suite.mod = memstore.NewDataStore(nil)
+	suite.original = memstore.NewDataStore(nil)
+	for _, store := range []*memstore.DataStore{suite.mod, suite.original} {
+		project := store.Project(InplaceProjectID)
+		project.AddLevel("archive", 1)
+		project.SetTextures(make([]model.TextureProperties, 2))
+		for _, size := range model.TextureSizes() {
+			project.SetTextureBitmap(1, size, &model.RawBitmap{Width: 1, Height: 1, Pixels: "AA=="})
+		}
+		project.SetGameObjects([]model.GameObject{suite.aGameObject(1, 2, 3)})
+	}
+	store := multistore.NewDataStore()
+	store.Add("mod", suite.mod, InplaceProjectID, false)
+	store.Add("original", suite.original, InplaceProjectID, true)
+	suite.adapter = NewAdapter(store)
+	suite.adapter.RequestProject("mod")
+	suite.flush()
+	suite.copied = nil
+	suite.done = 0
+}
+
+func (suite *ProjectCopySuite) aGameObject(class, subclass, objType int) model.GameObject {
+	object := model.GameObject{Class: class, Subclass: subclass, Type: objType}
+	for index := 0; index < model.LanguageCount; index++ {
+		name := "object"
+		object.Properties.ShortName[index] = &name
+		object.Properties.LongName[index] = &name
+	}
+	return object
+}
+
+func (suite *ProjectCopySuite) flush() {
+	for suite.mod.Flush()+suite.original.Flush() > 0 {
+	}
+}
+
+func (suite *ProjectCopySuite) onRead(resourceCopy ResourceCopy) {
+	suite.copied = append(suite.copied, resourceCopy)
+}
+
+func (suite *ProjectCopySuite) onDone() {
+	suite.done++
+}
+
+func (suite *ProjectCopySuite) applyCopy(c *check.C) {
+	c.Assert(suite.copied, check.HasLen, 1)
+	suite.copied[0].Apply(suite.onDone)
+	suite.flush()
+}
+
+func (suite *ProjectCopySuite) revertCopy() {
+	suite.copied[0].Revert(suite.onDone)
+	suite.flush()
+}
+
+func (suite *ProjectCopySuite) TestProjectsOfCatalogAreAvailable(c *check.C) {
+	c.Check(suite.adapter.AvailableProjectIDs(), check.DeepEquals, []string{"mod", "original"})
+	c.Check(suite.adapter.IsProjectReadOnly("mod"), check.Equals, false)
+	c.Check(suite.adapter.IsProjectReadOnly("original"), check.Equals, true)
+}
+
+func (suite *ProjectCopySuite) TestStoresWithoutCatalogProvideInplaceProject(c *check.C) {
+	adapter := NewAdapter(suite.mod)
+
+	c.Check(adapter.AvailableProjectIDs(), check.DeepEquals, []string{InplaceProjectID})
+	c.Check(adapter.IsProjectReadOnly(InplaceProjectID), check.Equals, false)
+}
+
+func (suite *ProjectCopySuite) TestLevelCopyProvidesContentOfBothProjects(c *check.C) {
+	source := suite.original.Project(InplaceProjectID).Level("archive", 1)
+	heightShift, open := 6, model.Open
+	source.SetProperties(model.LevelProperties{HeightShift: &heightShift})
+	source.SetTile(10, 20, model.TileProperties{Type: &open})
+	source.SetTextures([]int{4, 5})
+	source.AddObject(2, model.LevelObjectProperties{})
+	suite.mod.Project(InplaceProjectID).Level("archive", 1).AddObject(3, model.LevelObjectProperties{})
+	var before, after *LevelFile
+
+	suite.adapter.RequestLevelCopy("original", 1, func(beforeFile, afterFile *LevelFile) {
+		before, after = beforeFile, afterFile
+	})
+	suite.flush()
+
+	c.Assert(before, check.NotNil)
+	c.Assert(after, check.NotNil)
+	c.Check(*after.Properties.HeightShift, check.Equals, 6)
+	c.Check(*after.Tiles[20][10].Type, check.Equals, model.Open)
+	c.Check(after.Textures, check.DeepEquals, []int{4, 5})
+	c.Assert(after.Objects, check.HasLen, 1)
+	c.Check(after.Objects[0].Class, check.Equals, 2)
+	c.Assert(before.Objects, check.HasLen, 1)
+	c.Check(before.Objects[0].Class, check.Equals, 3)
+}
+
+func (suite *ProjectCopySuite) TestLevelCopyOfMissingLevelIsNotProvided(c *check.C) {
+	suite.mod.Project(InplaceProjectID).AddLevel("archive", 2).AddObject(3, model.LevelObjectProperties{})
+
+	suite.adapter.RequestLevelCopy("original", 2, func(*LevelFile, *LevelFile) { suite.onDone() })
+	suite.flush()
+
+	c.Check(suite.done, check.Equals, 0)
+	c.Check(suite.adapter.Message(), check.Matches, "Failed to process store query <.*>")
+	c.Check(suite.mod.Project(InplaceProjectID).Level("archive", 2).ObjectIDs(), check.DeepEquals, []int{1})
+}
+
+func (suite *ProjectCopySuite) TestTextureCopySetsPropertiesAndBitmaps(c *check.C) {
+	climbable := true
+	source := suite.original.Project(InplaceProjectID)
+	source.SetTextures([]model.TextureProperties{{}, {Climbable: &climbable}})
+	for _, size := range model.TextureSizes() {
+		source.SetTextureBitmap(1, size, &model.RawBitmap{Width: 1, Height: 1, Pixels: "Ag=="})
+	}
+
+	suite.adapter.RequestTextureCopy("original", 1, suite.onRead)
+	suite.flush()
+	suite.applyCopy(c)
+
+	target := suite.mod.Project(InplaceProjectID)
+	c.Check(suite.done, check.Equals, 1)
+	c.Check(*target.Textures()[1].Climbable, check.Equals, true)
+	c.Check(target.TextureBitmap(1, model.TextureLarge).Pixels, check.Equals, "Ag==")
+	c.Check(suite.adapter.TextureAdapter().GameTexture(1).Climbable(), check.Equals, true)
+}
+
+func (suite *ProjectCopySuite) TestTextureCopyIsNotWrittenBeforeItIsApplied(c *check.C) {
+	climbable := true
+	suite.original.Project(InplaceProjectID).SetTextures([]model.TextureProperties{{}, {Climbable: &climbable}})
+
+	suite.adapter.RequestTextureCopy("original", 1, suite.onRead)
+	suite.flush()
+
+	c.Check(suite.copied, check.HasLen, 1)
+	c.Check(suite.mod.Project(InplaceProjectID).Textures()[1].Climbable, check.IsNil)
+}
+
+func (suite *ProjectCopySuite) TestRevertedTextureCopyRestoresPreviousTexture(c *check.C) {
+	climbable, notClimbable := true, false
+	suite.original.Project(InplaceProjectID).SetTextures([]model.TextureProperties{{}, {Climbable: &climbable}})
+	target := suite.mod.Project(InplaceProjectID)
+	target.SetTextures([]model.TextureProperties{{}, {Climbable: &notClimbable}})
+	target.SetTextureBitmap(1, model.TextureLarge, &model.RawBitmap{Width: 1, Height: 1, Pixels: "AQ=="})
+	suite.adapter.RequestTextureCopy("original", 1, suite.onRead)
+	suite.flush()
+	suite.applyCopy(c)
+
+	suite.revertCopy()
+
+	c.Check(*target.Textures()[1].Climbable, check.Equals, false)
+	c.Check(target.TextureBitmap(1, model.TextureLarge).Pixels, check.Equals, "AQ==")
+}
+
+func (suite *ProjectCopySuite) TestTextureCopyRequiresTextureInBothProjects(c *check.C) {
+	suite.original.Project(InplaceProjectID).SetTextures(make([]model.TextureProperties, 1))
+
+	suite.adapter.RequestTextureCopy("original", 1, suite.onRead)
+	suite.flush()
+
+	c.Check(suite.copied, check.HasLen, 0)
+	c.Check(suite.adapter.Message(), check.Equals, "Texture 1 does not exist in project original")
+}
+
+func (suite *ProjectCopySuite) TestGameObjectCopySetsPropertiesAndAvailableBitmaps(c *check.C) {
+	source := suite.original.Project(InplaceProjectID)
+	object := suite.aGameObject(1, 2, 3)
+	object.Properties.Data.Generic = []byte{1, 2}
+	source.SetGameObjects([]model.GameObject{object})
+	source.SetGameObjectBitmap(1, 2, 3, 1, &model.RawBitmap{Width: 1, Height: 1, Pixels: "Aw=="})
+	target := suite.mod.Project(InplaceProjectID)
+	target.SetGameObjectBitmap(1, 2, 3, 0, &model.RawBitmap{Width: 1, Height: 1, Pixels: "AQ=="})
+
+	suite.adapter.RequestGameObjectCopy("original", MakeObjectID(1, 2, 3), suite.onRead)
+	suite.flush()
+	suite.applyCopy(c)
+
+	c.Check(suite.done, check.Equals, 1)
+	c.Check(target.GameObjects()[0].Properties.Data.Generic, check.DeepEquals, []byte{1, 2})
+	c.Check(target.GameObjectBitmap(1, 2, 3, 0).Pixels, check.Equals, "AQ==")
+	c.Check(target.GameObjectBitmap(1, 2, 3, 1).Pixels, check.Equals, "Aw==")
+}
+
+func (suite *ProjectCopySuite) textKey(language model.ResourceLanguage) model.ResourceKey {
+	return model.MakeLocalizedResourceKey(model.ResourceType(0x0867), language, 5)
+}
+
+func (suite *ProjectCopySuite) TestTextCopySetsTextsOfAllAvailableLanguages(c *check.C) {
+	source := suite.original.Project(InplaceProjectID)
+	source.SetText(suite.textKey(model.ResourceLanguageStandard), "standard")
+	source.SetText(suite.textKey(model.ResourceLanguageGerman), "german")
+	target := suite.mod.Project(InplaceProjectID)
+	target.SetText(suite.textKey(model.ResourceLanguageFrench), "french")
+
+	suite.adapter.RequestTextCopy("original", model.ResourceType(0x0867), 5, suite.onRead)
+	suite.flush()
+	suite.applyCopy(c)
+
+	c.Check(suite.done, check.Equals, 1)
+	for language, expected := range map[model.ResourceLanguage]string{
+		model.ResourceLanguageStandard: "standard",
+		model.ResourceLanguageFrench:   "french",
+		model.ResourceLanguageGerman:   "german"} {
+		text, _ := target.Text(suite.textKey(language))
+		c.Check(text, check.Equals, expected)
+	}
+}
+
+func (suite *ProjectCopySuite) TestRevertedTextCopyRestoresPreviousTexts(c *check.C) {
+	source := suite.original.Project(InplaceProjectID)
+	source.SetText(suite.textKey(model.ResourceLanguageStandard), "standard")
+	source.SetText(suite.textKey(model.ResourceLanguageGerman), "german")
+	target := suite.mod.Project(InplaceProjectID)
+	target.SetText(suite.textKey(model.ResourceLanguageStandard), "previous")
+	suite.adapter.RequestTextCopy("original", model.ResourceType(0x0867), 5, suite.onRead)
+	suite.flush()
+	suite.applyCopy(c)
+
+	suite.revertCopy()
+
+	standard, _ := target.Text(suite.textKey(model.ResourceLanguageStandard))
+	german, _ := target.Text(suite.textKey(model.ResourceLanguageGerman))
+	c.Check([]string{standard, german}, check.DeepEquals, []string{"previous", ""})
+}
+
+func (suite *ProjectCopySuite) TestTextCopyIsDoneOnceTextsAreWritten(c *check.C) {
+	suite.original.Project(InplaceProjectID).SetText(suite.textKey(model.ResourceLanguageStandard), "standard")
+	suite.adapter.RequestTextCopy("original", model.ResourceType(0x0867), 5, suite.onRead)
+	suite.flush()
+	c.Assert(suite.copied, check.HasLen, 1)
+
+	suite.copied[0].Apply(suite.onDone)
+
+	c.Check(suite.done, check.Equals, 0)
+	suite.flush()
+	c.Check(suite.done, check.Equals, 1)
+}
+
+func (suite *ProjectCopySuite) TestTextCopyOfMissingTextIsReported(c *check.C) {
+	suite.adapter.RequestTextCopy("original", model.ResourceType(0x0867), 5, suite.onRead)
+	suite.flush()
+
+	c.Check(suite.copied, check.HasLen, 0)
+	c.Check(suite.adapter.Message(), check.Equals, "Text 5 does not exist in project original")
+}
@@ -0,0 +1,684 @@
+package memstore
+
+import (
+	"sort"
+	"time"
+
+	"github.com/inkyblackness/res/audio"
+	"github.com/inkyblackness/shocked-model"
+)
+
+type response struct {
+	due     time.Duration
+	deliver func()
+}
+
+// DataStore is an in-memory implementation of the model.DataStore interface.
+// It is meant for tests of the adapters and the editor.
+//
+// Like a real store, all results are reported asynchronously: A request is
+// queued and only processed, including any modification, when the queue is
+// worked off. This happens either explicitly via Flush() and Advance(), or,
+// if the store was created with a deferrer channel, within the task the store
+// puts in that channel.
+//
+// The store runs on a virtual clock. Each request is due after the latency
+// configured for its operation; Requests are processed in the order they are
+// due, and in the order they were made for the same due time. Operations are
+// named after the methods of the interface, such as "SetTile".
+type DataStore struct {
+	deferrer   chan<- func()
+	pumpQueued bool
+
+	now     time.Duration
+	pending []response
+
+	defaultLatency time.Duration
+	latencies      map[string]time.Duration
+	failures       map[string]int
+	requests       []string
+
+	projects map[string]*Project
+}
+
+// NewDataStore returns a new, empty store. The deferrer is optional. If given,
+// the store queues one task in it whenever requests are pending, which then
+// processes all of them. The channel should be buffered; If the task can not
+// be queued, it is tried again with the next request.
+func NewDataStore(deferrer chan<- func()) *DataStore {
+	return &DataStore{
+		deferrer:  deferrer,
+		latencies: make(map[string]time.Duration),
+		failures:  make(map[string]int),
+		projects:  make(map[string]*Project)}
+}
+
+// Project returns the identified project. It is created if not yet existing.
+func (store *DataStore) Project(projectID string) *Project {
+	project, existing := store.projects[projectID]
+	if !existing {
+		project = newProject()
+		store.projects[projectID] = project
+	}
+	return project
+}
+
+// SetLatency sets the time an operation takes until its result is reported.
+// An empty operation name sets the latency of all operations without a specific one.
+func (store *DataStore) SetLatency(operation string, latency time.Duration) {
+	if operation == "" {
+		store.defaultLatency = latency
+	} else {
+		store.latencies[operation] = latency
+	}
+}
+
+// Fail lets the next count requests of given operation fail, without modifying
+// any data. A negative count lets all further requests fail. A count of zero
+// removes the failure.
+func (store *DataStore) Fail(operation string, count int) {
+	if count == 0 {
+		delete(store.failures, operation)
+	} else {
+		store.failures[operation] = count
+	}
+}
+
+// Requests returns the names of all operations requested so far, in order of the requests.
+func (store *DataStore) Requests() []string {
+	return append([]string{}, store.requests...)
+}
+
+// ClearRequests resets the list of requested operations.
+func (store *DataStore) ClearRequests() {
+	store.requests = nil
+}
+
+// Pending returns the number of requests that have not been processed yet.
+func (store *DataStore) Pending() int {
+	return len(store.pending)
+}
+
+// Now returns the current time of the virtual clock.
+func (store *DataStore) Now() time.Duration {
+	return store.now
+}
+
+// Advance moves the virtual clock forward and processes all requests that
+// become due within the given duration. The number of processed requests is returned.
+func (store *DataStore) Advance(duration time.Duration) int {
+	target := store.now + duration
+	processed := 0
+
+	for (len(store.pending) > 0) && (store.pending[0].due <= target) {
+		store.processNext()
+		processed++
+	}
+	store.now = target
+
+	return processed
+}
+
+// Flush processes all pending requests, including those made while processing,
+// and moves the virtual clock accordingly. The number of processed requests is returned.
+func (store *DataStore) Flush() int {
+	processed := 0
+
+	for len(store.pending) > 0 {
+		store.processNext()
+		processed++
+	}
+
+	return processed
+}
+
+func (store *DataStore) processNext() {
+	next := store.pending[0]
+	store.pending = store.pending[1:]
+	if next.due > store.now {
+		store.now = next.due
+	}
+	next.deliver()
+}
+
+func (store *DataStore) latencyOf(operation string) time.Duration {
+	latency, specific := store.latencies[operation]
+	if !specific {
+		latency = store.defaultLatency
+	}
+	return latency
+}
+
+func (store *DataStore) shallFail(operation string) bool {
+	count, existing := store.failures[operation]
+	if existing && (count > 0) {
+		count--
+		store.Fail(operation, count)
+	}
+	return existing
+}
+
+func (store *DataStore) schedule(operation string, deliver func()) {
+	entry := response{
+		due:     store.now + store.latencyOf(operation),
+		deliver: deliver}
+	position := len(store.pending)
+
+	for (position > 0) && (store.pending[position-1].due > entry.due) {
+		position--
+	}
+	store.pending = append(store.pending, response{})
+	copy(store.pending[position+1:], store.pending[position:])
+	store.pending[position] = entry
+
+	if (store.deferrer != nil) && !store.pumpQueued {
+		select {
+		case store.deferrer <- store.pump:
+			store.pumpQueued = true
+		default:
+		}
+	}
+}
+
+func (store *DataStore) pump() {
+	store.pumpQueued = false
+	store.Flush()
+}
+
+// request queues the processing of an operation on a project. The process
+// function is called when the request is due; It returns the function that
+// reports the result, or nil if the request can not be fulfilled.
+func (store *DataStore) request(operation string, projectID string, onFailure model.FailureFunc,
+	process func(project *Project) func()) {
+	failing := store.shallFail(operation)
+
+	store.requests = append(store.requests, operation)
+	store.schedule(operation, func() {
+		var report func()
+		project, existing := store.projects[projectID]
+
+		if existing && !failing {
+			report = process(project)
+		}
+		if report != nil {
+			report()
+		} else {
+			onFailure()
+		}
+	})
+}
+
+func (store *DataStore) levelRequest(operation string, projectID string, archiveID string, levelID int,
+	onFailure model.FailureFunc, process func(level *Level) func()) {
+	store.request(operation, projectID, onFailure, func(project *Project) (report func()) {
+		if level := project.Level(archiveID, levelID); level != nil {
+			report = process(level)
+		}
+		return
+	})
+}
+
+func (store *DataStore) tileRequest(operation string, projectID string, archiveID string, levelID int, x, y int,
+	onFailure model.FailureFunc, process func(level *Level) func()) {
+	store.levelRequest(operation, projectID, archiveID, levelID, onFailure, func(level *Level) (report func()) {
+		if level.isValidTile(x, y) {
+			report = process(level)
+		}
+		return
+	})
+}
+
+// Palette implements the model.DataStore interface.
+func (store *DataStore) Palette(projectID string, paletteID string,
+	onSuccess func(colors [256]model.Color), onFailure model.FailureFunc) {
+	store.request("Palette", projectID, onFailure, func(project *Project) (report func()) {
+		if colors, existing := project.Palette(paletteID); existing {
+			report = func() { onSuccess(colors) }
+		}
+		return
+	})
+}
+
+// SaveProject implements the model.DataStore interface.
+func (store *DataStore) SaveProject(projectID string) {
+	store.requests = append(store.requests, "SaveProject")
+	if project, existing := store.projects[projectID]; existing {
+		project.saveCount++
+	}
+}
+
+// Levels implements the model.DataStore interface.
+func (store *DataStore) Levels(projectID string, archiveID string,
+	onSuccess func(levels []model.Level), onFailure model.FailureFunc) {
+	store.request("Levels", projectID, onFailure, func(project *Project) (report func()) {
+		if archive, existing := project.archives[archiveID]; existing {
+			ids := make([]int, 0, len(archive))
+			for id := range archive {
+				ids = append(ids, id)
+			}
+			sort.Ints(ids)
+			levels := make([]model.Level, len(ids))
+			for index, id := range ids {
+				levels[index] = model.Level{ID: id, Properties: archive[id].Properties()}
+			}
+			report = func() { onSuccess(levels) }
+		}
+		return
+	})
+}
+
+// GameObjects implements the model.DataStore interface.
+func (store *DataStore) GameObjects(projectID string,
+	onSuccess func(objects []model.GameObject), onFailure model.FailureFunc) {
+	store.request("GameObjects", projectID, onFailure, func(project *Project) func() {
+		objects := project.GameObjects()
+		return func() { onSuccess(objects) }
+	})
+}
+
+// GameObjectIcon implements the model.DataStore interface.
+func (store *DataStore) GameObjectIcon(projectID string, class, subclass, objType int,
+	onSuccess func(bmp *model.RawBitmap), onFailure model.FailureFunc) {
+	store.request("GameObjectIcon", projectID, onFailure, func(project *Project) (report func()) {
+		if bmp, existing := project.objectIcons[objectKey{class, subclass, objType}]; existing {
+			result := copyOf(bmp).(*model.RawBitmap)
+			report = func() { onSuccess(result) }
+		}
+		return
+	})
+}
+
+// GameObjectBitmap implements the model.DataStore interface.
+func (store *DataStore) GameObjectBitmap(projectID string, class, subclass, objType int, index int,
+	onSuccess func(bmp *model.RawBitmap), onFailure model.FailureFunc) {
+	store.request("GameObjectBitmap", projectID, onFailure, func(project *Project) (report func()) {
+		if bmp := project.GameObjectBitmap(class, subclass, objType, index); bmp != nil {
+			result := copyOf(bmp).(*model.RawBitmap)
+			report = func() { onSuccess(result) }
+		}
+		return
+	})
+}
+
+// SetGameObjectBitmap implements the model.DataStore interface.
+func (store *DataStore) SetGameObjectBitmap(projectID string, class, subclass, objType int, index int, bmp *model.RawBitmap,
+	onSuccess func(), onFailure model.FailureFunc) {
+	data := copyOf(bmp).(*model.RawBitmap)
+	store.request("SetGameObjectBitmap", projectID, onFailure, func(project *Project) (report func()) {
+		if project.gameObject(class, subclass, objType) != nil {
+			project.SetGameObjectBitmap(class, subclass, objType, index, data)
+			report = onSuccess
+		}
+		return
+	})
+}
+
+// SetGameObject implements the model.DataStore interface.
+func (store *DataStore) SetGameObject(projectID string, class, subclass, objType int, properties *model.GameObjectProperties,
+	onSuccess func(properties *model.GameObjectProperties), onFailure model.FailureFunc) {
+	update := copyOf(*properties).(model.GameObjectProperties)
+	store.request("SetGameObject", projectID, onFailure, func(project *Project) (report func()) {
+		if object := project.gameObject(class, subclass, objType); object != nil {
+			merge(&object.Properties, update)
+			result := copyOf(object.Properties).(model.GameObjectProperties)
+			report = func() { onSuccess(&result) }
+		}
+		return
+	})
+}
+
+// Textures implements the model.DataStore interface.
+func (store *DataStore) Textures(projectID string,
+	onSuccess func(textures []model.TextureProperties), onFailure model.FailureFunc) {
+	store.request("Textures", projectID, onFailure, func(project *Project) func() {
+		textures := project.Textures()
+		return func() { onSuccess(textures) }
+	})
+}
+
+// SetTextureProperties implements the model.DataStore interface.
+func (store *DataStore) SetTextureProperties(projectID string, textureID int, properties *model.TextureProperties,
+	onSuccess func(properties *model.TextureProperties), onFailure model.FailureFunc) {
+	update := copyOf(*properties).(model.TextureProperties)
+	store.request("SetTextureProperties", projectID, onFailure, func(project *Project) (report func()) {
+		if (textureID >= 0) && (textureID < len(project.textures)) {
+			merge(&project.textures[textureID], update)
+			result := copyOf(project.textures[textureID]).(model.TextureProperties)
+			report = func() { onSuccess(&result) }
+		}
+		return
+	})
+}
+
+// TextureBitmap implements the model.DataStore interface.
+func (store *DataStore) TextureBitmap(projectID string, textureID int, size string,
+	onSuccess func(bmp *model.RawBitmap), onFailure model.FailureFunc) {
+	store.request("TextureBitmap", projectID, onFailure, func(project *Project) (report func()) {
+		if bmp := project.TextureBitmap(textureID, model.TextureSize(size)); bmp != nil {
+			result := copyOf(bmp).(*model.RawBitmap)
+			report = func() { onSuccess(result) }
+		}
+		return
+	})
+}
+
+// SetTextureBitmap implements the model.DataStore interface.
+func (store *DataStore) SetTextureBitmap(projectID string, textureID int, size string, rawBitmap *model.RawBitmap,
+	onSuccess func(bmp *model.RawBitmap), onFailure model.FailureFunc) {
+	data := copyOf(rawBitmap).(*model.RawBitmap)
+	store.request("SetTextureBitmap", projectID, onFailure, func(project *Project) (report func()) {
+		if (textureID >= 0) && (textureID < len(project.textures)) {
+			project.SetTextureBitmap(textureID, model.TextureSize(size), data)
+			result := copyOf(data).(*model.RawBitmap)
+			report = func() { onSuccess(result) }
+		}
+		return
+	})
+}
+
+// LevelProperties implements the model.DataStore interface.
+func (store *DataStore) LevelProperties(projectID string, archiveID string, levelID int,
+	onSuccess func(properties model.LevelProperties), onFailure model.FailureFunc) {
+	store.levelRequest("LevelProperties", projectID, archiveID, levelID, onFailure, func(level *Level) func() {
+		properties := level.Properties()
+		return func() { onSuccess(properties) }
+	})
+}
+
+// SetLevelProperties implements the model.DataStore interface.
+func (store *DataStore) SetLevelProperties(projectID string, archiveID string, levelID int, properties model.LevelProperties,
+	onSuccess func(properties model.LevelProperties), onFailure model.FailureFunc) {
+	update := copyOf(properties).(model.LevelProperties)
+	store.levelRequest("SetLevelProperties", projectID, archiveID, levelID, onFailure, func(level *Level) func() {
+		result := level.SetProperties(update).Properties()
+		return func() { onSuccess(result) }
+	})
+}
+
+// Tiles implements the model.DataStore interface.
+func (store *DataStore) Tiles(projectID string, archiveID string, levelID int,
+	onSuccess func(tiles model.Tiles), onFailure model.FailureFunc) {
+	store.levelRequest("Tiles", projectID, archiveID, levelID, onFailure, func(level *Level) func() {
+		tiles := model.Tiles{Table: copyOf(level.tiles).([][]model.TileProperties)}
+		return func() { onSuccess(tiles) }
+	})
+}
+
+// Tile implements the model.DataStore interface.
+func (store *DataStore) Tile(projectID string, archiveID string, levelID int, x, y int,
+	onSuccess func(properties model.TileProperties), onFailure model.FailureFunc) {
+	store.tileRequest("Tile", projectID, archiveID, levelID, x, y, onFailure, func(level *Level) func() {
+		properties := level.Tile(x, y)
+		return func() { onSuccess(properties) }
+	})
+}
+
+// SetTile implements the model.DataStore interface.
+func (store *DataStore) SetTile(projectID string, archiveID string, levelID int, x, y int, properties model.TileProperties,
+	onSuccess func(properties model.TileProperties), onFailure model.FailureFunc) {
+	update := copyOf(properties).(model.TileProperties)
+	store.tileRequest("SetTile", projectID, archiveID, levelID, x, y, onFailure, func(level *Level) func() {
+		result := level.SetTile(x, y, update).Tile(x, y)
+		return func() { onSuccess(result) }
+	})
+}
+
+// LevelTextures implements the model.DataStore interface.
+func (store *DataStore) LevelTextures(projectID string, archiveID string, levelID int,
+	onSuccess func(textureIDs []int), onFailure model.FailureFunc) {
+	store.levelRequest("LevelTextures", projectID, archiveID, levelID, onFailure, func(level *Level) func() {
+		textureIDs := level.Textures()
+		return func() { onSuccess(textureIDs) }
+	})
+}
+
+// SetLevelTextures implements the model.DataStore interface.
+func (store *DataStore) SetLevelTextures(projectID string, archiveID string, levelID int, textureIDs []int,
+	onSuccess func(textureIDs []int), onFailure model.FailureFunc) {
+	update := copyOf(textureIDs).([]int)
+	store.levelRequest("SetLevelTextures", projectID, archiveID, levelID, onFailure, func(level *Level) func() {
+		result := level.SetTextures(update).Textures()
+		return func() { onSuccess(result) }
+	})
+}
+
+// LevelTextureAnimations implements the model.DataStore interface.
+func (store *DataStore) LevelTextureAnimations(projectID string, archiveID string, levelID int,
+	onSuccess func(animations []model.TextureAnimation), onFailure model.FailureFunc) {
+	store.levelRequest("LevelTextureAnimations", projectID, archiveID, levelID, onFailure, func(level *Level) func() {
+		animations := level.Animations()
+		return func() { onSuccess(animations) }
+	})
+}
+
+// SetLevelTextureAnimation implements the model.DataStore interface.
+func (store *DataStore) SetLevelTextureAnimation(projectID string, archiveID string, levelID int, animationGroup int,
+	properties model.TextureAnimation,
+	onSuccess func(animations []model.TextureAnimation), onFailure model.FailureFunc) {
+	update := copyOf(properties).(model.TextureAnimation)
+	store.levelRequest("SetLevelTextureAnimation", projectID, archiveID, levelID, onFailure, func(level *Level) (report func()) {
+		if (animationGroup >= 0) && (animationGroup < len(level.animations)) {
+			result := level.SetAnimation(animationGroup, update).Animations()
+			report = func() { onSuccess(result) }
+		}
+		return
+	})
+}
+
+// LevelObjects implements the model.DataStore interface.
+func (store *DataStore) LevelObjects(projectID string, archiveID string, levelID int,
+	onSuccess func(objects *model.LevelObjects), onFailure model.FailureFunc) {
+	store.levelRequest("LevelObjects", projectID, archiveID, levelID, onFailure, func(level *Level) func() {
+		objects := level.objectTable()
+		return func() { onSuccess(objects) }
+	})
+}
+
+// AddLevelObject implements the model.DataStore interface.
+func (store *DataStore) AddLevelObject(projectID string, archiveID string, levelID int, template model.LevelObjectTemplate,
+	onSuccess func(object model.LevelObject), onFailure model.FailureFunc) {
+	store.levelRequest("AddLevelObject", projectID, archiveID, levelID, onFailure, func(level *Level) (report func()) {
+		id := level.AddObject(template.Class, model.LevelObjectProperties{
+			Subclass:  intPtr(template.Subclass),
+			Type:      intPtr(template.Type),
+			TileX:     intPtr(template.TileX),
+			FineX:     intPtr(template.FineX),
+			TileY:     intPtr(template.TileY),
+			FineY:     intPtr(template.FineY),
+			Z:         intPtr(template.Z),
+			Hitpoints: intPtr(template.Hitpoints)})
+		if id >= 0 {
+			object, _ := level.Object(id)
+			report = func() { onSuccess(object) }
+		}
+		return
+	})
+}
+
+// RemoveLevelObject implements the model.DataStore interface.
+func (store *DataStore) RemoveLevelObject(projectID string, archiveID string, levelID int, objectID int,
+	onSuccess func(), onFailure model.FailureFunc) {
+	store.levelRequest("RemoveLevelObject", projectID, archiveID, levelID, onFailure, func(level *Level) (report func()) {
+		if _, existing := level.objects[objectID]; existing {
+			level.RemoveObject(objectID)
+			report = onSuccess
+		}
+		return
+	})
+}
+
+// SetLevelObject implements the model.DataStore interface.
+func (store *DataStore) SetLevelObject(projectID string, archiveID string, levelID int, objectID int,
+	properties *model.LevelObjectProperties,
+	onSuccess func(properties *model.LevelObjectProperties), onFailure model.FailureFunc) {
+	update := copyOf(*properties).(model.LevelObjectProperties)
+	store.levelRequest("SetLevelObject", projectID, archiveID, levelID, onFailure, func(level *Level) (report func()) {
+		if object, existing := level.objects[objectID]; existing {
+			merge(&object.Properties, update)
+			result := copyOf(object.Properties).(model.LevelObjectProperties)
+			report = func() { onSuccess(&result) }
+		}
+		return
+	})
+}
+
+// LevelSurveillanceObjects implements the model.DataStore interface.
+func (store *DataStore) LevelSurveillanceObjects(projectID string, archiveID string, levelID int,
+	onSuccess func(objects []model.SurveillanceObject), onFailure model.FailureFunc) {
+	store.levelRequest("LevelSurveillanceObjects", projectID, archiveID, levelID, onFailure, func(level *Level) func() {
+		objects := level.Surveillance()
+		return func() { onSuccess(objects) }
+	})
+}
+
+// SetLevelSurveillanceObject implements the model.DataStore interface.
+func (store *DataStore) SetLevelSurveillanceObject(projectID string, archiveID string, levelID int, surveillanceIndex int,
+	data model.SurveillanceObject,
+	onSuccess func(objects []model.SurveillanceObject), onFailure model.FailureFunc) {
+	update := copyOf(data).(model.SurveillanceObject)
+	store.levelRequest("SetLevelSurveillanceObject", projectID, archiveID, levelID, onFailure, func(level *Level) (report func()) {
+		if (surveillanceIndex >= 0) && (surveillanceIndex < len(level.surveillance)) {
+			result := level.SetSurveillance(surveillanceIndex, update).Surveillance()
+			report = func() { onSuccess(result) }
+		}
+		return
+	})
+}
+
+// ElectronicMessage implements the model.DataStore interface.
+func (store *DataStore) ElectronicMessage(projectID string, messageType model.ElectronicMessageType, id int,
+	onSuccess func(message model.ElectronicMessage), onFailure model.FailureFunc) {
+	store.request("ElectronicMessage", projectID, onFailure, func(project *Project) (report func()) {
+		if message, existing := project.ElectronicMessage(messageType, id); existing {
+			report = func() { onSuccess(message) }
+		}
+		return
+	})
+}
+
+// SetElectronicMessage implements the model.DataStore interface.
+func (store *DataStore) SetElectronicMessage(projectID string, messageType model.ElectronicMessageType, id int,
+	message model.ElectronicMessage,
+	onSuccess func(message model.ElectronicMessage), onFailure model.FailureFunc) {
+	update := copyOf(message).(model.ElectronicMessage)
+	store.request("SetElectronicMessage", projectID, onFailure, func(project *Project) func() {
+		stored := project.messages[messageKey{messageType, id}]
+		merge(&stored, update)
+		project.messages[messageKey{messageType, id}] = stored
+		result, _ := project.ElectronicMessage(messageType, id)
+		return func() { onSuccess(result) }
+	})
+}
+
+// RemoveElectronicMessage implements the model.DataStore interface.
+func (store *DataStore) RemoveElectronicMessage(projectID string, messageType model.ElectronicMessageType, id int,
+	onSuccess func(), onFailure model.FailureFunc) {
+	store.request("RemoveElectronicMessage", projectID, onFailure, func(project *Project) (report func()) {
+		key := messageKey{messageType, id}
+		if _, existing := project.messages[key]; existing {
+			delete(project.messages, key)
+			for _, language := range model.LocalLanguages() {
+				delete(project.messageAudio, messageAudioKey{key, language})
+			}
+			report = onSuccess
+		}
+		return
+	})
+}
+
+// ElectronicMessageAudio implements the model.DataStore interface.
+func (store *DataStore) ElectronicMessageAudio(projectID string, messageType model.ElectronicMessageType, id int,
+	language model.ResourceLanguage,
+	onSuccess func(data audio.SoundData), onFailure model.FailureFunc) {
+	store.request("ElectronicMessageAudio", projectID, onFailure, func(project *Project) (report func()) {
+		if data := project.ElectronicMessageAudio(messageType, id, language); data != nil {
+			report = func() { onSuccess(data) }
+		}
+		return
+	})
+}
+
+// SetElectronicMessageAudio implements the model.DataStore interface.
+func (store *DataStore) SetElectronicMessageAudio(projectID string, messageType model.ElectronicMessageType, id int,
+	language model.ResourceLanguage, data audio.SoundData,
+	onSuccess func(), onFailure model.FailureFunc) {
+	store.request("SetElectronicMessageAudio", projectID, onFailure, func(project *Project) func() {
+		project.SetElectronicMessageAudio(messageType, id, language, data)
+		return onSuccess
+	})
+}
+
+// Font implements the model.DataStore interface.
+func (store *DataStore) Font(projectID string, fontID int,
+	onSuccess func(font *model.Font), onFailure model.FailureFunc) {
+	store.request("Font", projectID, onFailure, func(project *Project) (report func()) {
+		if font, existing := project.fonts[fontID]; existing {
+			result := copyOf(font).(*model.Font)
+			report = func() { onSuccess(result) }
+		}
+		return
+	})
+}
+
+// Text implements the model.DataStore interface.
+func (store *DataStore) Text(projectID string, key model.ResourceKey,
+	onSuccess func(resourceKey model.ResourceKey, text string), onFailure model.FailureFunc) {
+	store.request("Text", projectID, onFailure, func(project *Project) (report func()) {
+		if text, existing := project.Text(key); existing {
+			report = func() { onSuccess(key, text) }
+		}
+		return
+	})
+}
+
+// SetText implements the model.DataStore interface.
+func (store *DataStore) SetText(projectID string, key model.ResourceKey, text string,
+	onSuccess func(resourceKey model.ResourceKey, text string), onFailure model.FailureFunc) {
+	store.request("SetText", projectID, onFailure, func(project *Project) func() {
+		project.SetText(key, text)
+		return func() { onSuccess(key, text) }
+	})
+}
+
+// Audio implements the model.DataStore interface.
+func (store *DataStore) Audio(projectID string, key model.ResourceKey,
+	onSuccess func(resourceKey model.ResourceKey, data audio.SoundData), onFailure model.FailureFunc) {
+	store.request("Audio", projectID, onFailure, func(project *Project) (report func()) {
+		if data := project.Audio(key); data != nil {
+			report = func() { onSuccess(key, data) }
+		}
+		return
+	})
+}
+
+// SetAudio implements the model.DataStore interface.
+func (store *DataStore) SetAudio(projectID string, key model.ResourceKey, data audio.SoundData,
+	onSuccess func(resourceKey model.ResourceKey), onFailure model.FailureFunc) {
+	store.request("SetAudio", projectID, onFailure, func(project *Project) func() {
+		project.SetAudio(key, data)
+		return func() { onSuccess(key) }
+	})
+}
+
+// Bitmap implements the model.DataStore interface.
+func (store *DataStore) Bitmap(projectID string, key model.ResourceKey,
+	onSuccess func(resourceKey model.ResourceKey, bmp *model.RawBitmap), onFailure model.FailureFunc) {
+	store.request("Bitmap", projectID, onFailure, func(project *Project) (report func()) {
+		if bmp := project.Bitmap(key); bmp != nil {
+			result := copyOf(bmp).(*model.RawBitmap)
+			report = func() { onSuccess(key, result) }
+		}
+		return
+	})
+}
+
+// SetBitmap implements the model.DataStore interface.
+func (store *DataStore) SetBitmap(projectID string, key model.ResourceKey, bmp *model.RawBitmap,
+	onSuccess func(resourceKey model.ResourceKey, bmp *model.RawBitmap), onFailure model.FailureFunc) {
+	data := copyOf(bmp).(*model.RawBitmap)
+	store.request("SetBitmap", projectID, onFailure, func(project *Project) func() {
+		project.SetBitmap(key, data)
+		result := copyOf(data).(*model.RawBitmap)
+		return func() { onSuccess(key, result) }
+	})
+}
@@ -0,0 +1,217 @@
+package memstore
+
+import (
+	"time"
+
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-model"
+)
+
+type DataStoreSuite struct {
+	store *DataStore
+	level *Level
+	log   []string
+}
+
+var _ = check.Suite(&DataStoreSuite{})
+
+var _ model.DataStore = &DataStore{}
+
+func (suite *DataStoreSuite) SetUpTest(c *check.C) {
+	suite.store = NewDataStore(nil)
+	suite.level = suite.store.Project("project").AddLevel("archive", 1)
+	suite.log = nil
+}
+
+func (suite *DataStoreSuite) record(entry string) func() {
+	return func() { suite.log = append(suite.log, entry) }
+}
+
+func (suite *DataStoreSuite) tileRecorder(entry string) func(model.TileProperties) {
+	return func(model.TileProperties) { suite.log = append(suite.log, entry) }
+}
+
+func (suite *DataStoreSuite) TestRequestsAreNotProcessedImmediately(c *check.C) {
+	suite.store.Tile("project", "archive", 1, 0, 0, suite.tileRecorder("tile"), suite.record("failed"))
+
+	c.Check(suite.log, check.IsNil)
+	c.Check(suite.store.Pending(), check.Equals, 1)
+}
+
+func (suite *DataStoreSuite) TestFlushProcessesRequestsInOrder(c *check.C) {
+	suite.store.Tile("project", "archive", 1, 0, 0, suite.tileRecorder("first"), suite.record("failed"))
+	suite.store.Tile("project", "archive", 1, 1, 0, suite.tileRecorder("second"), suite.record("failed"))
+
+	processed := suite.store.Flush()
+
+	c.Check(processed, check.Equals, 2)
+	c.Check(suite.log, check.DeepEquals, []string{"first", "second"})
+}
+
+func (suite *DataStoreSuite) TestFlushProcessesRequestsMadeWhileProcessing(c *check.C) {
+	suite.store.Tile("project", "archive", 1, 0, 0, func(model.TileProperties) {
+		suite.store.Tile("project", "archive", 1, 1, 0, suite.tileRecorder("nested"), suite.record("failed"))
+	}, suite.record("failed"))
+
+	suite.store.Flush()
+
+	c.Check(suite.log, check.DeepEquals, []string{"nested"})
+}
+
+func (suite *DataStoreSuite) TestLatencyDeterminesOrderOfResults(c *check.C) {
+	suite.store.SetLatency("", 10*time.Millisecond)
+	suite.store.SetLatency("Tiles", 100*time.Millisecond)
+	suite.store.Tiles("project", "archive", 1, func(model.Tiles) { suite.log = append(suite.log, "tiles") }, suite.record("failed"))
+	suite.store.Tile("project", "archive", 1, 0, 0, suite.tileRecorder("tile"), suite.record("failed"))
+
+	suite.store.Flush()
+
+	c.Check(suite.log, check.DeepEquals, []string{"tile", "tiles"})
+	c.Check(suite.store.Now(), check.Equals, 100*time.Millisecond)
+}
+
+func (suite *DataStoreSuite) TestAdvanceProcessesOnlyDueRequests(c *check.C) {
+	suite.store.SetLatency("Tile", 50*time.Millisecond)
+	suite.store.Tile("project", "archive", 1, 0, 0, suite.tileRecorder("tile"), suite.record("failed"))
+
+	c.Check(suite.store.Advance(49*time.Millisecond), check.Equals, 0)
+	c.Check(suite.store.Advance(1*time.Millisecond), check.Equals, 1)
+	c.Check(suite.log, check.DeepEquals, []string{"tile"})
+}
+
+func (suite *DataStoreSuite) TestModificationsHappenWhenRequestIsDue(c *check.C) {
+	var result model.TileProperties
+	height := model.HeightUnit(12)
+	suite.store.SetLatency("SetTile", 20*time.Millisecond)
+	suite.store.SetTile("project", "archive", 1, 5, 6, model.TileProperties{FloorHeight: &height},
+		func(model.TileProperties) {}, suite.record("failed"))
+	suite.store.Tile("project", "archive", 1, 5, 6, func(properties model.TileProperties) { result = properties }, suite.record("failed"))
+
+	suite.store.Flush()
+
+	c.Check(*result.FloorHeight, check.Equals, model.HeightUnit(0))
+	c.Check(*suite.level.Tile(5, 6).FloorHeight, check.Equals, model.HeightUnit(12))
+}
+
+func (suite *DataStoreSuite) TestInjectedFailuresAffectGivenCountOfRequests(c *check.C) {
+	suite.store.Fail("Tile", 1)
+	suite.store.Tile("project", "archive", 1, 0, 0, suite.tileRecorder("first"), suite.record("first failed"))
+	suite.store.Tile("project", "archive", 1, 0, 0, suite.tileRecorder("second"), suite.record("second failed"))
+
+	suite.store.Flush()
+
+	c.Check(suite.log, check.DeepEquals, []string{"first failed", "second"})
+}
+
+func (suite *DataStoreSuite) TestFailedModificationsLeaveDataUnchanged(c *check.C) {
+	height := model.HeightUnit(12)
+	suite.store.Fail("SetTile", -1)
+	suite.store.SetTile("project", "archive", 1, 5, 6, model.TileProperties{FloorHeight: &height},
+		suite.tileRecorder("set"), suite.record("failed"))
+
+	suite.store.Flush()
+
+	c.Check(suite.log, check.DeepEquals, []string{"failed"})
+	c.Check(*suite.level.Tile(5, 6).FloorHeight, check.Equals, model.HeightUnit(0))
+}
+
+func (suite *DataStoreSuite) TestUnknownLevelsFail(c *check.C) {
+	suite.store.Tile("project", "archive", 2, 0, 0, suite.tileRecorder("tile"), suite.record("failed"))
+	suite.store.Tile("project", "archive", 1, 64, 0, suite.tileRecorder("tile"), suite.record("failed"))
+
+	suite.store.Flush()
+
+	c.Check(suite.log, check.DeepEquals, []string{"failed", "failed"})
+}
+
+func (suite *DataStoreSuite) TestSetTileMergesProperties(c *check.C) {
+	var result model.TileProperties
+	tileType := model.Open
+	shadow := 3
+	suite.store.SetTile("project", "archive", 1, 5, 6,
+		model.TileProperties{Type: &tileType, RealWorld: &model.RealWorldTileProperties{FloorShadow: &shadow}},
+		func(properties model.TileProperties) { result = properties }, suite.record("failed"))
+
+	suite.store.Flush()
+
+	c.Check(*result.Type, check.Equals, model.Open)
+	c.Check(*result.RealWorld.FloorShadow, check.Equals, 3)
+	c.Check(*result.RealWorld.CeilingShadow, check.Equals, 0)
+	c.Check(*result.FloorHeight, check.Equals, model.HeightUnit(0))
+}
+
+func (suite *DataStoreSuite) TestResultsAreCopies(c *check.C) {
+	var result model.TileProperties
+	suite.store.Tile("project", "archive", 1, 0, 0, func(properties model.TileProperties) { result = properties }, suite.record("failed"))
+
+	suite.store.Flush()
+	*result.FloorHeight = 20
+
+	c.Check(*suite.level.Tile(0, 0).FloorHeight, check.Equals, model.HeightUnit(0))
+}
+
+func (suite *DataStoreSuite) TestAddLevelObjectUsesFreeIDs(c *check.C) {
+	var ids []int
+	suite.level.AddObject(1, model.LevelObjectProperties{})
+	suite.level.AddObject(1, model.LevelObjectProperties{})
+	suite.level.RemoveObject(1)
+	onAdded := func(object model.LevelObject) { ids = append(ids, object.ID) }
+
+	suite.store.AddLevelObject("project", "archive", 1, model.LevelObjectTemplate{Class: 2}, onAdded, suite.record("failed"))
+	suite.store.AddLevelObject("project", "archive", 1, model.LevelObjectTemplate{Class: 2}, onAdded, suite.record("failed"))
+	suite.store.Flush()
+
+	c.Check(ids, check.DeepEquals, []int{1, 3})
+	c.Check(suite.level.ObjectIDs(), check.DeepEquals, []int{1, 2, 3})
+}
+
+func (suite *DataStoreSuite) TestSetLevelObjectReturnsMergedProperties(c *check.C) {
+	var result *model.LevelObjectProperties
+	id := suite.level.AddObject(3, model.LevelObjectProperties{TileX: intPtr(4)})
+	z := 7
+
+	suite.store.SetLevelObject("project", "archive", 1, id, &model.LevelObjectProperties{Z: &z},
+		func(properties *model.LevelObjectProperties) { result = properties }, suite.record("failed"))
+	suite.store.Flush()
+
+	c.Assert(result, check.NotNil)
+	c.Check(*result.TileX, check.Equals, 4)
+	c.Check(*result.Z, check.Equals, 7)
+}
+
+func (suite *DataStoreSuite) TestLevelsAreListedInOrder(c *check.C) {
+	var ids []int
+	suite.store.Project("project").AddLevel("archive", 0)
+
+	suite.store.Levels("project", "archive", func(levels []model.Level) {
+		for _, level := range levels {
+			ids = append(ids, level.ID)
+		}
+	}, suite.record("failed"))
+	suite.store.Flush()
+
+	c.Check(ids, check.DeepEquals, []int{0, 1})
+}
+
+func (suite *DataStoreSuite) TestRequestsAreRecorded(c *check.C) {
+	suite.store.SaveProject("project")
+	suite.store.Tile("project", "archive", 1, 0, 0, suite.tileRecorder("tile"), suite.record("failed"))
+
+	c.Check(suite.store.Requests(), check.DeepEquals, []string{"SaveProject", "Tile"})
+	c.Check(suite.store.Project("project").SaveCount(), check.Equals, 1)
+}
+
+func (suite *DataStoreSuite) TestDeferrerReceivesTaskProcessingPendingRequests(c *check.C) {
+	deferrer := make(chan func(), 1)
+	store := NewDataStore(deferrer)
+	store.Project("project").AddLevel("archive", 1)
+
+	store.Tile("project", "archive", 1, 0, 0, suite.tileRecorder("first"), suite.record("failed"))
+	store.Tile("project", "archive", 1, 0, 0, suite.tileRecorder("second"), suite.record("failed"))
+	c.Assert(len(deferrer), check.Equals, 1)
+	(<-deferrer)()
+
+	c.Check(suite.log, check.DeepEquals, []string{"first", "second"})
+	c.Check(len(deferrer), check.Equals, 0)
+}
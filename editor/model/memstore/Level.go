@@ -0,0 +1,239 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/inkyblackness/shocked-model"
+)
+
+// LevelObjectCapacity is the maximum number of objects a level can hold.
+// Object ID 0 is reserved, as in the original data.
+const LevelObjectCapacity = 872
+
+// Level holds the data of one level in the store.
+// New levels are filled with solid tiles and have no objects.
+type Level struct {
+	id         int
+	properties model.LevelProperties
+	tiles      [][]model.TileProperties
+	textures   []int
+	animations []model.TextureAnimation
+	objects    map[int]*model.LevelObject
+
+	surveillance []model.SurveillanceObject
+}
+
+func newLevel(id int) *Level {
+	level := &Level{
+		id:           id,
+		properties:   defaultLevelProperties(),
+		tiles:        make([][]model.TileProperties, 64),
+		textures:     []int{},
+		animations:   make([]model.TextureAnimation, 4),
+		objects:      make(map[int]*model.LevelObject),
+		surveillance: make([]model.SurveillanceObject, 8)}
+
+	for y := range level.tiles {
+		row := make([]model.TileProperties, 64)
+		for x := range row {
+			row[x] = defaultTileProperties()
+		}
+		level.tiles[y] = row
+	}
+	for index := range level.animations {
+		level.animations[index] = model.TextureAnimation{FrameTime: intPtr(0), FrameCount: intPtr(0), LoopType: intPtr(0)}
+	}
+	for index := range level.surveillance {
+		level.surveillance[index] = model.SurveillanceObject{SourceIndex: intPtr(0), DeathwatchIndex: intPtr(0)}
+	}
+
+	return level
+}
+
+func defaultLevelProperties() model.LevelProperties {
+	return model.LevelProperties{
+		CyberspaceFlag:      boolPtr(false),
+		HeightShift:         intPtr(3),
+		CeilingHasRadiation: boolPtr(false),
+		CeilingEffectLevel:  intPtr(0),
+		FloorHasBiohazard:   boolPtr(false),
+		FloorHasGravity:     boolPtr(false),
+		FloorEffectLevel:    intPtr(0)}
+}
+
+func defaultTileProperties() model.TileProperties {
+	tileType := model.Solid
+	slopeControl := model.SlopeCeilingInverted
+	wallTextureOffset := model.HeightUnit(0)
+
+	return model.TileProperties{
+		Type:                  &tileType,
+		FloorHeight:           heightPtr(0),
+		CeilingHeight:         heightPtr(0),
+		SlopeHeight:           heightPtr(0),
+		SlopeControl:          &slopeControl,
+		CalculatedWallHeights: &model.CalculatedWallHeights{},
+		MusicIndex:            intPtr(0),
+		RealWorld: &model.RealWorldTileProperties{
+			FloorTexture:            intPtr(0),
+			FloorTextureRotations:   intPtr(0),
+			CeilingTexture:          intPtr(0),
+			CeilingTextureRotations: intPtr(0),
+			WallTexture:             intPtr(0),
+			WallTextureOffset:       &wallTextureOffset,
+			WallTexturePattern:      intPtr(0),
+			UseAdjacentWallTexture:  boolPtr(false),
+			FloorShadow:             intPtr(0),
+			CeilingShadow:           intPtr(0),
+			SpookyMusic:             boolPtr(false),
+			FloorHazard:             boolPtr(false),
+			CeilingHazard:           boolPtr(false)},
+		Cyberspace: &model.CyberspaceTileProperties{
+			FloorColorIndex:   intPtr(0),
+			CeilingColorIndex: intPtr(0),
+			FlightPullType:    intPtr(0),
+			GameOfLifeSet:     boolPtr(false)}}
+}
+
+// ID returns the identifier of the level.
+func (level *Level) ID() int {
+	return level.id
+}
+
+// Properties returns a copy of the current level properties.
+func (level *Level) Properties() model.LevelProperties {
+	return copyOf(level.properties).(model.LevelProperties)
+}
+
+// SetProperties merges the set fields of given properties into the level.
+func (level *Level) SetProperties(properties model.LevelProperties) *Level {
+	merge(&level.properties, properties)
+	return level
+}
+
+func (level *Level) isValidTile(x, y int) bool {
+	return (y >= 0) && (y < len(level.tiles)) && (x >= 0) && (x < len(level.tiles[y]))
+}
+
+// Tile returns a copy of the properties of the tile at given coordinate.
+func (level *Level) Tile(x, y int) model.TileProperties {
+	return copyOf(level.tiles[y][x]).(model.TileProperties)
+}
+
+// SetTile merges the set fields of given properties into the tile at given coordinate.
+func (level *Level) SetTile(x, y int, properties model.TileProperties) *Level {
+	merge(&level.tiles[y][x], properties)
+	return level
+}
+
+// Textures returns the IDs of the level textures.
+func (level *Level) Textures() []int {
+	return copyOf(level.textures).([]int)
+}
+
+// SetTextures sets the IDs of the level textures.
+func (level *Level) SetTextures(textureIDs []int) *Level {
+	level.textures = copyOf(textureIDs).([]int)
+	return level
+}
+
+// Animations returns a copy of the texture animation groups.
+func (level *Level) Animations() []model.TextureAnimation {
+	return copyOf(level.animations).([]model.TextureAnimation)
+}
+
+// SetAnimation merges the set fields of given properties into identified animation group.
+func (level *Level) SetAnimation(group int, properties model.TextureAnimation) *Level {
+	merge(&level.animations[group], properties)
+	return level
+}
+
+// ObjectIDs returns the IDs of all objects in the level, in ascending order.
+func (level *Level) ObjectIDs() []int {
+	ids := make([]int, 0, len(level.objects))
+	for id := range level.objects {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Object returns a copy of identified object. The second return value is false
+// if the object does not exist.
+func (level *Level) Object(id int) (object model.LevelObject, existing bool) {
+	stored, existing := level.objects[id]
+	if existing {
+		object = copyOf(*stored).(model.LevelObject)
+	}
+	return
+}
+
+// AddObject places a new object with given properties in the level and returns its ID.
+// Unset properties default to zero. -1 is returned if the level is full.
+func (level *Level) AddObject(class int, properties model.LevelObjectProperties) int {
+	id := level.freeObjectID()
+	if id >= 0 {
+		object := &model.LevelObject{
+			ID:    id,
+			Class: class,
+			Properties: model.LevelObjectProperties{
+				Subclass:  intPtr(0),
+				Type:      intPtr(0),
+				TileX:     intPtr(0),
+				FineX:     intPtr(0),
+				TileY:     intPtr(0),
+				FineY:     intPtr(0),
+				Z:         intPtr(0),
+				RotationX: intPtr(0),
+				RotationY: intPtr(0),
+				RotationZ: intPtr(0),
+				Hitpoints: intPtr(0),
+				ClassData: []byte{},
+				ExtraData: []byte{}}}
+		merge(&object.Properties, properties)
+		level.objects[id] = object
+	}
+	return id
+}
+
+// ReplaceObject stores the object of given ID with exactly the given properties. Unlike for
+// AddObject, unset properties stay unset, as they may be in data of other stores.
+func (level *Level) ReplaceObject(id int, class int, properties model.LevelObjectProperties) *Level {
+	level.objects[id] = &model.LevelObject{ID: id, Class: class, Properties: properties}
+	return level
+}
+
+func (level *Level) freeObjectID() int {
+	for id := 1; id < LevelObjectCapacity; id++ {
+		if _, used := level.objects[id]; !used {
+			return id
+		}
+	}
+	return -1
+}
+
+// RemoveObject removes identified object from the level.
+func (level *Level) RemoveObject(id int) *Level {
+	delete(level.objects, id)
+	return level
+}
+
+func (level *Level) objectTable() *model.LevelObjects {
+	ids := level.ObjectIDs()
+	table := &model.LevelObjects{Table: make([]model.LevelObject, len(ids))}
+	for index, id := range ids {
+		table.Table[index], _ = level.Object(id)
+	}
+	return table
+}
+
+// Surveillance returns a copy of the surveillance objects.
+func (level *Level) Surveillance() []model.SurveillanceObject {
+	return copyOf(level.surveillance).([]model.SurveillanceObject)
+}
+
+// SetSurveillance merges the set fields of given data into identified surveillance entry.
+func (level *Level) SetSurveillance(index int, data model.SurveillanceObject) *Level {
+	merge(&level.surveillance[index], data)
+	return level
+}
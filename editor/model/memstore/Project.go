@@ -0,0 +1,232 @@
+package memstore
+
+import (
+	"github.com/inkyblackness/res/audio"
+	"github.com/inkyblackness/shocked-model"
+)
+
+type textureBitmapKey struct {
+	id   int
+	size string
+}
+
+type objectKey struct {
+	class, subclass, objType int
+}
+
+type objectBitmapKey struct {
+	objectKey
+	index int
+}
+
+type messageKey struct {
+	messageType model.ElectronicMessageType
+	id          int
+}
+
+type messageAudioKey struct {
+	messageKey
+	language model.ResourceLanguage
+}
+
+// Project holds all the data of one project in the store. Its methods are
+// meant to set up and inspect the content in tests; They bypass latency and
+// injected failures of the store.
+type Project struct {
+	saveCount int
+
+	palettes map[string][256]model.Color
+	archives map[string]map[int]*Level
+
+	textures       []model.TextureProperties
+	textureBitmaps map[textureBitmapKey]*model.RawBitmap
+
+	gameObjects   []model.GameObject
+	objectIcons   map[objectKey]*model.RawBitmap
+	objectBitmaps map[objectBitmapKey]*model.RawBitmap
+
+	messages     map[messageKey]model.ElectronicMessage
+	messageAudio map[messageAudioKey]audio.SoundData
+
+	fonts   map[int]*model.Font
+	texts   map[model.ResourceKey]string
+	audio   map[model.ResourceKey]audio.SoundData
+	bitmaps map[model.ResourceKey]*model.RawBitmap
+}
+
+func newProject() *Project {
+	return &Project{
+		palettes:       make(map[string][256]model.Color),
+		archives:       make(map[string]map[int]*Level),
+		textureBitmaps: make(map[textureBitmapKey]*model.RawBitmap),
+		objectIcons:    make(map[objectKey]*model.RawBitmap),
+		objectBitmaps:  make(map[objectBitmapKey]*model.RawBitmap),
+		messages:       make(map[messageKey]model.ElectronicMessage),
+		messageAudio:   make(map[messageAudioKey]audio.SoundData),
+		fonts:          make(map[int]*model.Font),
+		texts:          make(map[model.ResourceKey]string),
+		audio:          make(map[model.ResourceKey]audio.SoundData),
+		bitmaps:        make(map[model.ResourceKey]*model.RawBitmap)}
+}
+
+// SaveCount returns how often the project was requested to be saved.
+func (project *Project) SaveCount() int {
+	return project.saveCount
+}
+
+// Palette returns the colors of identified palette.
+func (project *Project) Palette(paletteID string) (colors [256]model.Color, existing bool) {
+	colors, existing = project.palettes[paletteID]
+	return
+}
+
+// SetPalette sets the colors of identified palette.
+func (project *Project) SetPalette(paletteID string, colors [256]model.Color) *Project {
+	project.palettes[paletteID] = colors
+	return project
+}
+
+// Level returns identified level of the given archive, or nil if not existing.
+func (project *Project) Level(archiveID string, levelID int) *Level {
+	return project.archives[archiveID][levelID]
+}
+
+// AddLevel creates a new level in the given archive. An existing level with the same ID is replaced.
+func (project *Project) AddLevel(archiveID string, levelID int) *Level {
+	levels, existing := project.archives[archiveID]
+	if !existing {
+		levels = make(map[int]*Level)
+		project.archives[archiveID] = levels
+	}
+	level := newLevel(levelID)
+	levels[levelID] = level
+	return level
+}
+
+// Textures returns a copy of the properties of all textures.
+func (project *Project) Textures() []model.TextureProperties {
+	return copyOf(project.textures).([]model.TextureProperties)
+}
+
+// SetTextures sets the properties of all textures. The texture ID is the index within the list.
+func (project *Project) SetTextures(textures []model.TextureProperties) *Project {
+	project.textures = copyOf(textures).([]model.TextureProperties)
+	return project
+}
+
+// TextureBitmap returns the bitmap of identified texture in given size.
+func (project *Project) TextureBitmap(textureID int, size model.TextureSize) *model.RawBitmap {
+	return project.textureBitmaps[textureBitmapKey{textureID, string(size)}]
+}
+
+// SetTextureBitmap sets the bitmap of identified texture in given size.
+func (project *Project) SetTextureBitmap(textureID int, size model.TextureSize, bmp *model.RawBitmap) *Project {
+	project.textureBitmaps[textureBitmapKey{textureID, string(size)}] = copyOf(bmp).(*model.RawBitmap)
+	return project
+}
+
+// GameObjects returns a copy of all game objects.
+func (project *Project) GameObjects() []model.GameObject {
+	return copyOf(project.gameObjects).([]model.GameObject)
+}
+
+// SetGameObjects sets the list of all game objects.
+func (project *Project) SetGameObjects(objects []model.GameObject) *Project {
+	project.gameObjects = copyOf(objects).([]model.GameObject)
+	return project
+}
+
+func (project *Project) gameObject(class, subclass, objType int) *model.GameObject {
+	for index := range project.gameObjects {
+		object := &project.gameObjects[index]
+		if (object.Class == class) && (object.Subclass == subclass) && (object.Type == objType) {
+			return object
+		}
+	}
+	return nil
+}
+
+// SetGameObjectIcon sets the icon of identified game object.
+func (project *Project) SetGameObjectIcon(class, subclass, objType int, bmp *model.RawBitmap) *Project {
+	project.objectIcons[objectKey{class, subclass, objType}] = copyOf(bmp).(*model.RawBitmap)
+	return project
+}
+
+// GameObjectBitmap returns the bitmap at given index of identified game object.
+func (project *Project) GameObjectBitmap(class, subclass, objType int, index int) *model.RawBitmap {
+	return project.objectBitmaps[objectBitmapKey{objectKey{class, subclass, objType}, index}]
+}
+
+// SetGameObjectBitmap sets the bitmap at given index of identified game object.
+func (project *Project) SetGameObjectBitmap(class, subclass, objType int, index int, bmp *model.RawBitmap) *Project {
+	project.objectBitmaps[objectBitmapKey{objectKey{class, subclass, objType}, index}] = copyOf(bmp).(*model.RawBitmap)
+	return project
+}
+
+// ElectronicMessage returns identified message.
+func (project *Project) ElectronicMessage(messageType model.ElectronicMessageType, id int) (message model.ElectronicMessage, existing bool) {
+	message, existing = project.messages[messageKey{messageType, id}]
+	if existing {
+		message = copyOf(message).(model.ElectronicMessage)
+	}
+	return
+}
+
+// SetElectronicMessage sets the content of identified message.
+func (project *Project) SetElectronicMessage(messageType model.ElectronicMessageType, id int, message model.ElectronicMessage) *Project {
+	project.messages[messageKey{messageType, id}] = copyOf(message).(model.ElectronicMessage)
+	return project
+}
+
+// ElectronicMessageAudio returns the audio of identified message in given language.
+func (project *Project) ElectronicMessageAudio(messageType model.ElectronicMessageType, id int,
+	language model.ResourceLanguage) audio.SoundData {
+	return project.messageAudio[messageAudioKey{messageKey{messageType, id}, language}]
+}
+
+// SetElectronicMessageAudio sets the audio of identified message in given language.
+func (project *Project) SetElectronicMessageAudio(messageType model.ElectronicMessageType, id int,
+	language model.ResourceLanguage, data audio.SoundData) *Project {
+	project.messageAudio[messageAudioKey{messageKey{messageType, id}, language}] = data
+	return project
+}
+
+// SetFont sets identified font.
+func (project *Project) SetFont(fontID int, font *model.Font) *Project {
+	project.fonts[fontID] = copyOf(font).(*model.Font)
+	return project
+}
+
+// Text returns the text stored under given key.
+func (project *Project) Text(key model.ResourceKey) (text string, existing bool) {
+	text, existing = project.texts[key]
+	return
+}
+
+// SetText sets the text stored under given key.
+func (project *Project) SetText(key model.ResourceKey, text string) *Project {
+	project.texts[key] = text
+	return project
+}
+
+// Audio returns the audio stored under given key.
+func (project *Project) Audio(key model.ResourceKey) audio.SoundData {
+	return project.audio[key]
+}
+
+// SetAudio sets the audio stored under given key.
+func (project *Project) SetAudio(key model.ResourceKey, data audio.SoundData) *Project {
+	project.audio[key] = data
+	return project
+}
+
+// Bitmap returns the bitmap stored under given key.
+func (project *Project) Bitmap(key model.ResourceKey) *model.RawBitmap {
+	return project.bitmaps[key]
+}
+
+// SetBitmap sets the bitmap stored under given key.
+func (project *Project) SetBitmap(key model.ResourceKey, bmp *model.RawBitmap) *Project {
+	project.bitmaps[key] = copyOf(bmp).(*model.RawBitmap)
+	return project
+}
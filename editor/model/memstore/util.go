@@ -0,0 +1,106 @@
+package memstore
+
+import (
+	"reflect"
+
+	"github.com/inkyblackness/shocked-model"
+)
+
+// copyOf returns a deep copy of given value. Pointers, slices and arrays are
+// duplicated, interfaces (such as sound data) are shared.
+func copyOf(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return copyValue(reflect.ValueOf(value)).Interface()
+}
+
+func copyValue(original reflect.Value) reflect.Value {
+	result := reflect.New(original.Type()).Elem()
+
+	switch original.Kind() {
+	case reflect.Ptr:
+		if !original.IsNil() {
+			result.Set(reflect.New(original.Type().Elem()))
+			result.Elem().Set(copyValue(original.Elem()))
+		}
+	case reflect.Slice:
+		if !original.IsNil() {
+			result.Set(reflect.MakeSlice(original.Type(), original.Len(), original.Len()))
+			for index := 0; index < original.Len(); index++ {
+				result.Index(index).Set(copyValue(original.Index(index)))
+			}
+		}
+	case reflect.Array:
+		for index := 0; index < original.Len(); index++ {
+			result.Index(index).Set(copyValue(original.Index(index)))
+		}
+	case reflect.Struct:
+		for index := 0; index < original.NumField(); index++ {
+			if result.Field(index).CanSet() {
+				result.Field(index).Set(copyValue(original.Field(index)))
+			}
+		}
+	default:
+		result.Set(original)
+	}
+
+	return result
+}
+
+// merge applies all set fields of update onto the structure target points to.
+// This follows the semantics of the data store: nil pointers and slices are
+// left unchanged; Nested structures are merged field by field.
+func merge(target interface{}, update interface{}) {
+	mergeValue(reflect.ValueOf(target).Elem(), reflect.ValueOf(update))
+}
+
+func mergeValue(target reflect.Value, update reflect.Value) {
+	switch update.Kind() {
+	case reflect.Ptr:
+		if !update.IsNil() {
+			if update.Elem().Kind() == reflect.Struct {
+				if target.IsNil() {
+					target.Set(reflect.New(update.Type().Elem()))
+				}
+				mergeValue(target.Elem(), update.Elem())
+			} else {
+				target.Set(copyValue(update))
+			}
+		}
+	case reflect.Slice:
+		if !update.IsNil() {
+			target.Set(copyValue(update))
+		}
+	case reflect.Array:
+		for index := 0; index < update.Len(); index++ {
+			mergeValue(target.Index(index), update.Index(index))
+		}
+	case reflect.Struct:
+		for index := 0; index < update.NumField(); index++ {
+			if target.Field(index).CanSet() {
+				mergeValue(target.Field(index), update.Field(index))
+			}
+		}
+	case reflect.Interface:
+		if !update.IsNil() {
+			target.Set(update)
+		}
+	default:
+		if update.Interface() != reflect.Zero(update.Type()).Interface() {
+			target.Set(update)
+		}
+	}
+}
+
+func intPtr(value int) *int {
+	return &value
+}
+
+func boolPtr(value bool) *bool {
+	return &value
+}
+
+func heightPtr(value model.HeightUnit) *model.HeightUnit {
+	return &value
+}
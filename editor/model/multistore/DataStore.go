@@ -0,0 +1,388 @@
+package multistore
+
+import (
+	"github.com/inkyblackness/res/audio"
+	"github.com/inkyblackness/shocked-model"
+)
+
+type project struct {
+	store          model.DataStore
+	storeProjectID string
+	readOnly       bool
+}
+
+// DataStore is a model.DataStore that combines the projects of several other stores.
+// This allows to work with more than one project at once, such as an original game
+// next to a modification of it.
+//
+// Each project is added under an own identifier and forwards all requests to the store
+// it was added with. Projects added as read-only reject all modifications: Their failure
+// functions are called right away, and saving them is ignored.
+type DataStore struct {
+	projects   map[string]*project
+	projectIDs []string
+}
+
+// NewDataStore returns a new store without any projects.
+func NewDataStore() *DataStore {
+	return &DataStore{projects: make(map[string]*project)}
+}
+
+// Add adds a project to the store. The project is known as storeProjectID within the given store.
+// An already existing project of the same identifier is replaced.
+func (store *DataStore) Add(projectID string, projectStore model.DataStore, storeProjectID string, readOnly bool) {
+	if _, existing := store.projects[projectID]; !existing {
+		store.projectIDs = append(store.projectIDs, projectID)
+	}
+	store.projects[projectID] = &project{store: projectStore, storeProjectID: storeProjectID, readOnly: readOnly}
+}
+
+// ProjectIDs returns the identifiers of all projects, in the order they were added.
+func (store *DataStore) ProjectIDs() []string {
+	return append([]string{}, store.projectIDs...)
+}
+
+// IsReadOnly returns true for projects that were added as read-only, and for unknown projects.
+func (store *DataStore) IsReadOnly(projectID string) bool {
+	entry, existing := store.projects[projectID]
+	return !existing || entry.readOnly
+}
+
+// readable returns the identified project. If the project is unknown, the failure function is called
+// and nil is returned.
+func (store *DataStore) readable(projectID string, onFailure model.FailureFunc) *project {
+	entry, existing := store.projects[projectID]
+	if !existing {
+		onFailure()
+		return nil
+	}
+	return entry
+}
+
+// writable returns the identified project. If the project is unknown, or read-only, the failure function
+// is called and nil is returned.
+func (store *DataStore) writable(projectID string, onFailure model.FailureFunc) *project {
+	entry := store.readable(projectID, onFailure)
+	if (entry != nil) && entry.readOnly {
+		onFailure()
+		return nil
+	}
+	return entry
+}
+
+// SaveProject implements the model.DataStore interface.
+func (store *DataStore) SaveProject(projectID string) {
+	if entry, existing := store.projects[projectID]; existing && !entry.readOnly {
+		entry.store.SaveProject(entry.storeProjectID)
+	}
+}
+
+// Palette implements the model.DataStore interface.
+func (store *DataStore) Palette(projectID string, paletteID string,
+	onSuccess func(colors [256]model.Color), onFailure model.FailureFunc) {
+	if entry := store.readable(projectID, onFailure); entry != nil {
+		entry.store.Palette(entry.storeProjectID, paletteID, onSuccess, onFailure)
+	}
+}
+
+// Levels implements the model.DataStore interface.
+func (store *DataStore) Levels(projectID string, archiveID string,
+	onSuccess func(levels []model.Level), onFailure model.FailureFunc) {
+	if entry := store.readable(projectID, onFailure); entry != nil {
+		entry.store.Levels(entry.storeProjectID, archiveID, onSuccess, onFailure)
+	}
+}
+
+// GameObjects implements the model.DataStore interface.
+func (store *DataStore) GameObjects(projectID string,
+	onSuccess func(objects []model.GameObject), onFailure model.FailureFunc) {
+	if entry := store.readable(projectID, onFailure); entry != nil {
+		entry.store.GameObjects(entry.storeProjectID, onSuccess, onFailure)
+	}
+}
+
+// GameObjectIcon implements the model.DataStore interface.
+func (store *DataStore) GameObjectIcon(projectID string, class, subclass, objType int,
+	onSuccess func(bmp *model.RawBitmap), onFailure model.FailureFunc) {
+	if entry := store.readable(projectID, onFailure); entry != nil {
+		entry.store.GameObjectIcon(entry.storeProjectID, class, subclass, objType, onSuccess, onFailure)
+	}
+}
+
+// GameObjectBitmap implements the model.DataStore interface.
+func (store *DataStore) GameObjectBitmap(projectID string, class, subclass, objType int, index int,
+	onSuccess func(bmp *model.RawBitmap), onFailure model.FailureFunc) {
+	if entry := store.readable(projectID, onFailure); entry != nil {
+		entry.store.GameObjectBitmap(entry.storeProjectID, class, subclass, objType, index, onSuccess, onFailure)
+	}
+}
+
+// SetGameObjectBitmap implements the model.DataStore interface.
+func (store *DataStore) SetGameObjectBitmap(projectID string, class, subclass, objType int, index int, bmp *model.RawBitmap,
+	onSuccess func(), onFailure model.FailureFunc) {
+	if entry := store.writable(projectID, onFailure); entry != nil {
+		entry.store.SetGameObjectBitmap(entry.storeProjectID, class, subclass, objType, index, bmp, onSuccess, onFailure)
+	}
+}
+
+// SetGameObject implements the model.DataStore interface.
+func (store *DataStore) SetGameObject(projectID string, class, subclass, objType int, properties *model.GameObjectProperties,
+	onSuccess func(properties *model.GameObjectProperties), onFailure model.FailureFunc) {
+	if entry := store.writable(projectID, onFailure); entry != nil {
+		entry.store.SetGameObject(entry.storeProjectID, class, subclass, objType, properties, onSuccess, onFailure)
+	}
+}
+
+// Textures implements the model.DataStore interface.
+func (store *DataStore) Textures(projectID string,
+	onSuccess func(textures []model.TextureProperties), onFailure model.FailureFunc) {
+	if entry := store.readable(projectID, onFailure); entry != nil {
+		entry.store.Textures(entry.storeProjectID, onSuccess, onFailure)
+	}
+}
+
+// SetTextureProperties implements the model.DataStore interface.
+func (store *DataStore) SetTextureProperties(projectID string, textureID int, properties *model.TextureProperties,
+	onSuccess func(properties *model.TextureProperties), onFailure model.FailureFunc) {
+	if entry := store.writable(projectID, onFailure); entry != nil {
+		entry.store.SetTextureProperties(entry.storeProjectID, textureID, properties, onSuccess, onFailure)
+	}
+}
+
+// TextureBitmap implements the model.DataStore interface.
+func (store *DataStore) TextureBitmap(projectID string, textureID int, size string,
+	onSuccess func(bmp *model.RawBitmap), onFailure model.FailureFunc) {
+	if entry := store.readable(projectID, onFailure); entry != nil {
+		entry.store.TextureBitmap(entry.storeProjectID, textureID, size, onSuccess, onFailure)
+	}
+}
+
+// SetTextureBitmap implements the model.DataStore interface.
+func (store *DataStore) SetTextureBitmap(projectID string, textureID int, size string, rawBitmap *model.RawBitmap,
+	onSuccess func(bmp *model.RawBitmap), onFailure model.FailureFunc) {
+	if entry := store.writable(projectID, onFailure); entry != nil {
+		entry.store.SetTextureBitmap(entry.storeProjectID, textureID, size, rawBitmap, onSuccess, onFailure)
+	}
+}
+
+// LevelProperties implements the model.DataStore interface.
+func (store *DataStore) LevelProperties(projectID string, archiveID string, levelID int,
+	onSuccess func(properties model.LevelProperties), onFailure model.FailureFunc) {
+	if entry := store.readable(projectID, onFailure); entry != nil {
+		entry.store.LevelProperties(entry.storeProjectID, archiveID, levelID, onSuccess, onFailure)
+	}
+}
+
+// SetLevelProperties implements the model.DataStore interface.
+func (store *DataStore) SetLevelProperties(projectID string, archiveID string, levelID int, properties model.LevelProperties,
+	onSuccess func(properties model.LevelProperties), onFailure model.FailureFunc) {
+	if entry := store.writable(projectID, onFailure); entry != nil {
+		entry.store.SetLevelProperties(entry.storeProjectID, archiveID, levelID, properties, onSuccess, onFailure)
+	}
+}
+
+// Tiles implements the model.DataStore interface.
+func (store *DataStore) Tiles(projectID string, archiveID string, levelID int,
+	onSuccess func(tiles model.Tiles), onFailure model.FailureFunc) {
+	if entry := store.readable(projectID, onFailure); entry != nil {
+		entry.store.Tiles(entry.storeProjectID, archiveID, levelID, onSuccess, onFailure)
+	}
+}
+
+// Tile implements the model.DataStore interface.
+func (store *DataStore) Tile(projectID string, archiveID string, levelID int, x, y int,
+	onSuccess func(properties model.TileProperties), onFailure model.FailureFunc) {
+	if entry := store.readable(projectID, onFailure); entry != nil {
+		entry.store.Tile(entry.storeProjectID, archiveID, levelID, x, y, onSuccess, onFailure)
+	}
+}
+
+// SetTile implements the model.DataStore interface.
+func (store *DataStore) SetTile(projectID string, archiveID string, levelID int, x, y int, properties model.TileProperties,
+	onSuccess func(properties model.TileProperties), onFailure model.FailureFunc) {
+	if entry := store.writable(projectID, onFailure); entry != nil {
+		entry.store.SetTile(entry.storeProjectID, archiveID, levelID, x, y, properties, onSuccess, onFailure)
+	}
+}
+
+// LevelTextures implements the model.DataStore interface.
+func (store *DataStore) LevelTextures(projectID string, archiveID string, levelID int,
+	onSuccess func(textureIDs []int), onFailure model.FailureFunc) {
+	if entry := store.readable(projectID, onFailure); entry != nil {
+		entry.store.LevelTextures(entry.storeProjectID, archiveID, levelID, onSuccess, onFailure)
+	}
+}
+
+// SetLevelTextures implements the model.DataStore interface.
+func (store *DataStore) SetLevelTextures(projectID string, archiveID string, levelID int, textureIDs []int,
+	onSuccess func(textureIDs []int), onFailure model.FailureFunc) {
+	if entry := store.writable(projectID, onFailure); entry != nil {
+		entry.store.SetLevelTextures(entry.storeProjectID, archiveID, levelID, textureIDs, onSuccess, onFailure)
+	}
+}
+
+// LevelTextureAnimations implements the model.DataStore interface.
+func (store *DataStore) LevelTextureAnimations(projectID string, archiveID string, levelID int,
+	onSuccess func(animations []model.TextureAnimation), onFailure model.FailureFunc) {
+	if entry := store.readable(projectID, onFailure); entry != nil {
+		entry.store.LevelTextureAnimations(entry.storeProjectID, archiveID, levelID, onSuccess, onFailure)
+	}
+}
+
+// SetLevelTextureAnimation implements the model.DataStore interface.
+func (store *DataStore) SetLevelTextureAnimation(projectID string, archiveID string, levelID int, animationGroup int,
+	properties model.TextureAnimation,
+	onSuccess func(animations []model.TextureAnimation), onFailure model.FailureFunc) {
+	if entry := store.writable(projectID, onFailure); entry != nil {
+		entry.store.SetLevelTextureAnimation(entry.storeProjectID, archiveID, levelID, animationGroup, properties, onSuccess, onFailure)
+	}
+}
+
+// LevelObjects implements the model.DataStore interface.
+func (store *DataStore) LevelObjects(projectID string, archiveID string, levelID int,
+	onSuccess func(objects *model.LevelObjects), onFailure model.FailureFunc) {
+	if entry := store.readable(projectID, onFailure); entry != nil {
+		entry.store.LevelObjects(entry.storeProjectID, archiveID, levelID, onSuccess, onFailure)
+	}
+}
+
+// AddLevelObject implements the model.DataStore interface.
+func (store *DataStore) AddLevelObject(projectID string, archiveID string, levelID int, template model.LevelObjectTemplate,
+	onSuccess func(object model.LevelObject), onFailure model.FailureFunc) {
+	if entry := store.writable(projectID, onFailure); entry != nil {
+		entry.store.AddLevelObject(entry.storeProjectID, archiveID, levelID, template, onSuccess, onFailure)
+	}
+}
+
+// RemoveLevelObject implements the model.DataStore interface.
+func (store *DataStore) RemoveLevelObject(projectID string, archiveID string, levelID int, objectID int,
+	onSuccess func(), onFailure model.FailureFunc) {
+	if entry := store.writable(projectID, onFailure); entry != nil {
+		entry.store.RemoveLevelObject(entry.storeProjectID, archiveID, levelID, objectID, onSuccess, onFailure)
+	}
+}
+
+// SetLevelObject implements the model.DataStore interface.
+func (store *DataStore) SetLevelObject(projectID string, archiveID string, levelID int, objectID int,
+	properties *model.LevelObjectProperties,
+	onSuccess func(properties *model.LevelObjectProperties), onFailure model.FailureFunc) {
+	if entry := store.writable(projectID, onFailure); entry != nil {
+		entry.store.SetLevelObject(entry.storeProjectID, archiveID, levelID, objectID, properties, onSuccess, onFailure)
+	}
+}
+
+// LevelSurveillanceObjects implements the model.DataStore interface.
+func (store *DataStore) LevelSurveillanceObjects(projectID string, archiveID string, levelID int,
+	onSuccess func(objects []model.SurveillanceObject), onFailure model.FailureFunc) {
+	if entry := store.readable(projectID, onFailure); entry != nil {
+		entry.store.LevelSurveillanceObjects(entry.storeProjectID, archiveID, levelID, onSuccess, onFailure)
+	}
+}
+
+// SetLevelSurveillanceObject implements the model.DataStore interface.
+func (store *DataStore) SetLevelSurveillanceObject(projectID string, archiveID string, levelID int, surveillanceIndex int,
+	data model.SurveillanceObject,
+	onSuccess func(objects []model.SurveillanceObject), onFailure model.FailureFunc) {
+	if entry := store.writable(projectID, onFailure); entry != nil {
+		entry.store.SetLevelSurveillanceObject(entry.storeProjectID, archiveID, levelID, surveillanceIndex, data, onSuccess, onFailure)
+	}
+}
+
+// ElectronicMessage implements the model.DataStore interface.
+func (store *DataStore) ElectronicMessage(projectID string, messageType model.ElectronicMessageType, id int,
+	onSuccess func(message model.ElectronicMessage), onFailure model.FailureFunc) {
+	if entry := store.readable(projectID, onFailure); entry != nil {
+		entry.store.ElectronicMessage(entry.storeProjectID, messageType, id, onSuccess, onFailure)
+	}
+}
+
+// SetElectronicMessage implements the model.DataStore interface.
+func (store *DataStore) SetElectronicMessage(projectID string, messageType model.ElectronicMessageType, id int,
+	message model.ElectronicMessage,
+	onSuccess func(message model.ElectronicMessage), onFailure model.FailureFunc) {
+	if entry := store.writable(projectID, onFailure); entry != nil {
+		entry.store.SetElectronicMessage(entry.storeProjectID, messageType, id, message, onSuccess, onFailure)
+	}
+}
+
+// RemoveElectronicMessage implements the model.DataStore interface.
+func (store *DataStore) RemoveElectronicMessage(projectID string, messageType model.ElectronicMessageType, id int,
+	onSuccess func(), onFailure model.FailureFunc) {
+	if entry := store.writable(projectID, onFailure); entry != nil {
+		entry.store.RemoveElectronicMessage(entry.storeProjectID, messageType, id, onSuccess, onFailure)
+	}
+}
+
+// ElectronicMessageAudio implements the model.DataStore interface.
+func (store *DataStore) ElectronicMessageAudio(projectID string, messageType model.ElectronicMessageType, id int,
+	language model.ResourceLanguage,
+	onSuccess func(data audio.SoundData), onFailure model.FailureFunc) {
+	if entry := store.readable(projectID, onFailure); entry != nil {
+		entry.store.ElectronicMessageAudio(entry.storeProjectID, messageType, id, language, onSuccess, onFailure)
+	}
+}
+
+// SetElectronicMessageAudio implements the model.DataStore interface.
+func (store *DataStore) SetElectronicMessageAudio(projectID string, messageType model.ElectronicMessageType, id int,
+	language model.ResourceLanguage, data audio.SoundData,
+	onSuccess func(), onFailure model.FailureFunc) {
+	if entry := store.writable(projectID, onFailure); entry != nil {
+		entry.store.SetElectronicMessageAudio(entry.storeProjectID, messageType, id, language, data, onSuccess, onFailure)
+	}
+}
+
+// Font implements the model.DataStore interface.
+func (store *DataStore) Font(projectID string, fontID int,
+	onSuccess func(font *model.Font), onFailure model.FailureFunc) {
+	if entry := store.readable(projectID, onFailure); entry != nil {
+		entry.store.Font(entry.storeProjectID, fontID, onSuccess, onFailure)
+	}
+}
+
+// Text implements the model.DataStore interface.
+func (store *DataStore) Text(projectID string, key model.ResourceKey,
+	onSuccess func(resourceKey model.ResourceKey, text string), onFailure model.FailureFunc) {
+	if entry := store.readable(projectID, onFailure); entry != nil {
+		entry.store.Text(entry.storeProjectID, key, onSuccess, onFailure)
+	}
+}
+
+// SetText implements the model.DataStore interface.
+func (store *DataStore) SetText(projectID string, key model.ResourceKey, text string,
+	onSuccess func(resourceKey model.ResourceKey, text string), onFailure model.FailureFunc) {
+	if entry := store.writable(projectID, onFailure); entry != nil {
+		entry.store.SetText(entry.storeProjectID, key, text, onSuccess, onFailure)
+	}
+}
+
+// Audio implements the model.DataStore interface.
+func (store *DataStore) Audio(projectID string, key model.ResourceKey,
+	onSuccess func(resourceKey model.ResourceKey, data audio.SoundData), onFailure model.FailureFunc) {
+	if entry := store.readable(projectID, onFailure); entry != nil {
+		entry.store.Audio(entry.storeProjectID, key, onSuccess, onFailure)
+	}
+}
+
+// SetAudio implements the model.DataStore interface.
+func (store *DataStore) SetAudio(projectID string, key model.ResourceKey, data audio.SoundData,
+	onSuccess func(resourceKey model.ResourceKey), onFailure model.FailureFunc) {
+	if entry := store.writable(projectID, onFailure); entry != nil {
+		entry.store.SetAudio(entry.storeProjectID, key, data, onSuccess, onFailure)
+	}
+}
+
+// Bitmap implements the model.DataStore interface.
+func (store *DataStore) Bitmap(projectID string, key model.ResourceKey,
+	onSuccess func(resourceKey model.ResourceKey, bmp *model.RawBitmap), onFailure model.FailureFunc) {
+	if entry := store.readable(projectID, onFailure); entry != nil {
+		entry.store.Bitmap(entry.storeProjectID, key, onSuccess, onFailure)
+	}
+}
+
+// SetBitmap implements the model.DataStore interface.
+func (store *DataStore) SetBitmap(projectID string, key model.ResourceKey, bmp *model.RawBitmap,
+	onSuccess func(resourceKey model.ResourceKey, bmp *model.RawBitmap), onFailure model.FailureFunc) {
+	if entry := store.writable(projectID, onFailure); entry != nil {
+		entry.store.SetBitmap(entry.storeProjectID, key, bmp, onSuccess, onFailure)
+	}
+}
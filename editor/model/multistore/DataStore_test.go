@@ -0,0 +1,95 @@
+package multistore
+
+import (
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/editor/model/memstore"
+	"github.com/inkyblackness/shocked-model"
+)
+
+type DataStoreSuite struct {
+	mod      *memstore.DataStore
+	original *memstore.DataStore
+	store    *DataStore
+	log      []string
+}
+
+var _ = check.Suite(&DataStoreSuite{})
+
+var _ model.DataStore = &DataStore{}
+
+func (suite *DataStoreSuite) SetUpTest(c *check.C) {
+	suite.mod = memstore.NewDataStore(nil)
+	suite.mod.Project("(inplace)").AddLevel("archive", 1)
+	suite.original = memstore.NewDataStore(nil)
+	suite.original.Project("(inplace)").AddLevel("archive", 1)
+	suite.store = NewDataStore()
+	suite.store.Add("mod", suite.mod, "(inplace)", false)
+	suite.store.Add("original", suite.original, "(inplace)", true)
+	suite.log = nil
+}
+
+func (suite *DataStoreSuite) record(entry string) func() {
+	return func() { suite.log = append(suite.log, entry) }
+}
+
+func (suite *DataStoreSuite) tileRecorder(entry string) func(model.TileProperties) {
+	return func(model.TileProperties) { suite.log = append(suite.log, entry) }
+}
+
+func (suite *DataStoreSuite) TestProjectsAreListedInOrderOfAddition(c *check.C) {
+	suite.store.Add("mod", suite.mod, "(inplace)", false)
+
+	c.Check(suite.store.ProjectIDs(), check.DeepEquals, []string{"mod", "original"})
+}
+
+func (suite *DataStoreSuite) TestRequestsAreForwardedToStoreOfProject(c *check.C) {
+	solid := model.Solid
+	suite.original.Project("(inplace)").Level("archive", 1).SetTile(2, 3, model.TileProperties{Type: &solid})
+	var result model.TileProperties
+
+	suite.store.Tile("original", "archive", 1, 2, 3, func(properties model.TileProperties) {
+		result = properties
+	}, suite.record("failed"))
+	suite.original.Flush()
+
+	c.Assert(result.Type, check.NotNil)
+	c.Check(*result.Type, check.Equals, model.Solid)
+	c.Check(suite.mod.Requests(), check.HasLen, 0)
+}
+
+func (suite *DataStoreSuite) TestModificationsOfWritableProjectsAreForwarded(c *check.C) {
+	open := model.Open
+
+	suite.store.SetTile("mod", "archive", 1, 2, 3, model.TileProperties{Type: &open},
+		suite.tileRecorder("set"), suite.record("failed"))
+	suite.mod.Flush()
+
+	c.Check(suite.log, check.DeepEquals, []string{"set"})
+	c.Check(*suite.mod.Project("(inplace)").Level("archive", 1).Tile(2, 3).Type, check.Equals, model.Open)
+}
+
+func (suite *DataStoreSuite) TestModificationsOfReadOnlyProjectsFail(c *check.C) {
+	open := model.Open
+
+	suite.store.SetTile("original", "archive", 1, 2, 3, model.TileProperties{Type: &open},
+		suite.tileRecorder("set"), suite.record("failed"))
+
+	c.Check(suite.log, check.DeepEquals, []string{"failed"})
+	c.Check(suite.original.Requests(), check.HasLen, 0)
+}
+
+func (suite *DataStoreSuite) TestRequestsForUnknownProjectsFail(c *check.C) {
+	suite.store.Tile("unknown", "archive", 1, 2, 3, suite.tileRecorder("tile"), suite.record("failed"))
+
+	c.Check(suite.log, check.DeepEquals, []string{"failed"})
+	c.Check(suite.store.IsReadOnly("unknown"), check.Equals, true)
+}
+
+func (suite *DataStoreSuite) TestSaveProjectIgnoresReadOnlyProjects(c *check.C) {
+	suite.store.SaveProject("mod")
+	suite.store.SaveProject("original")
+
+	c.Check(suite.mod.Project("(inplace)").SaveCount(), check.Equals, 1)
+	c.Check(suite.original.Project("(inplace)").SaveCount(), check.Equals, 0)
+}
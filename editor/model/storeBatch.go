@@ -0,0 +1,43 @@
+package model
+
+import (
+	"github.com/inkyblackness/shocked-model"
+)
+
+// storeBatch counts pending store requests and calls a function once all of them are completed.
+// It starts with one pending request for the issuing code, which has to call done() once it has
+// issued all requests.
+type storeBatch struct {
+	adapter     *Adapter
+	pending     int
+	failed      bool
+	onCompleted func()
+}
+
+func newStoreBatch(adapter *Adapter, onCompleted func()) *storeBatch {
+	return &storeBatch{adapter: adapter, pending: 1, onCompleted: onCompleted}
+}
+
+// request registers a further pending request and returns its failure handler.
+func (batch *storeBatch) request(info string) model.FailureFunc {
+	batch.pending++
+	report := batch.adapter.simpleStoreFailure(info)
+	return func() {
+		batch.failed = true
+		report()
+		batch.done()
+	}
+}
+
+// optional registers a further pending request, which may fail without being reported.
+func (batch *storeBatch) optional() model.FailureFunc {
+	batch.pending++
+	return batch.done
+}
+
+func (batch *storeBatch) done() {
+	batch.pending--
+	if batch.pending == 0 {
+		batch.onCompleted()
+	}
+}
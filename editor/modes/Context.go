@@ -3,6 +3,7 @@ package modes
 import (
 	mgl "github.com/go-gl/mathgl/mgl32"
 
+	"github.com/inkyblackness/shocked-client/editor/actions"
 	"github.com/inkyblackness/shocked-client/editor/cmd"
 	"github.com/inkyblackness/shocked-client/editor/model"
 	"github.com/inkyblackness/shocked-client/graphics"
@@ -12,6 +13,7 @@ import (
 // Context provides some global resources.
 type Context interface {
 	Perform(cmd.Command)
+	Actions() *actions.Registry
 	ModelAdapter() *model.Adapter
 	NewRenderContext(viewMatrix *mgl.Mat4) *graphics.RenderContext
 	ForGraphics() graphics.Context
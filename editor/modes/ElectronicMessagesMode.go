@@ -238,6 +238,7 @@ func NewElectronicMessagesMode(context Context, parent *ui.Area) *ElectronicMess
 		labelBuilder.AlignedHorizontallyBy(controls.LeftAligner)
 		labelBuilder.AlignedVerticallyBy(controls.LeftAligner)
 		labelBuilder.SetFitToWidth()
+		labelBuilder.SetMultiLine()
 		mode.textValue = labelBuilder.Build()
 		mode.textValue.AllowTextChange(mode.onMessageTextChangeRequested)
 	}
@@ -387,6 +388,8 @@ func (mode *ElectronicMessagesMode) importAudio(filePath string) {
 func (mode *ElectronicMessagesMode) requestAudioChange(newData audio.SoundData) {
 	restoreState := mode.stateSnapshot()
 	mode.context.Perform(&cmd.SetAudioCommand{
+		Name:   "message audio",
+		Target: messageAudioTarget(mode.selectedMessageType, mode.selectedMessageID, mode.selectedLanguage),
 		Setter: func(data audio.SoundData) error {
 			restoreState()
 			mode.messageAdapter.RequestAudioChange(mode.selectedLanguage, data)
@@ -413,6 +416,7 @@ func (mode *ElectronicMessagesMode) requestData() {
 func (mode *ElectronicMessagesMode) removeMessage() {
 	restoreState := mode.stateSnapshot()
 	command := &cmd.RemoveElectronicMessageCommand{
+		Target:       messageTarget(mode.selectedMessageType, mode.selectedMessageID),
 		RestoreState: restoreState,
 		Store:        mode.messageAdapter}
 
@@ -495,106 +499,88 @@ func (mode *ElectronicMessagesMode) updateMessageAudio() {
 }
 
 func (mode *ElectronicMessagesMode) onNextMessageChanged(newValue int64) {
-	mode.requestIntPropertyChange(func(properties *dataModel.ElectronicMessage, value *int) {
-		properties.NextMessage = value
-	}, int(newValue), mode.messageAdapter.NextMessage())
+	mode.requestIntPropertyChange("nextMessage", int(newValue), mode.messageAdapter.NextMessage())
 }
 
 func (mode *ElectronicMessagesMode) onIsInterruptChanged(boxItem controls.ComboBoxItem) {
 	item := boxItem.(*enumItem)
 	newValue := item.value != 0
-	mode.requestBooleanPropertyChange(func(properties *dataModel.ElectronicMessage, value *bool) {
-		properties.IsInterrupt = value
-	}, newValue, mode.messageAdapter.IsInterrupt())
+	mode.requestBooleanPropertyChange("interrupt", newValue, mode.messageAdapter.IsInterrupt())
 }
 
 func (mode *ElectronicMessagesMode) onColorIndexChanged(newValue int64) {
-	mode.requestIntPropertyChange(func(properties *dataModel.ElectronicMessage, value *int) {
-		properties.ColorIndex = value
-	}, int(newValue), mode.messageAdapter.ColorIndex())
+	mode.requestIntPropertyChange("colorIndex", int(newValue), mode.messageAdapter.ColorIndex())
 }
 
 func (mode *ElectronicMessagesMode) onLeftDisplayChanged(newValue int64) {
-	mode.requestIntPropertyChange(func(properties *dataModel.ElectronicMessage, value *int) {
-		properties.LeftDisplay = value
-	}, int(newValue), mode.messageAdapter.LeftDisplay())
+	mode.requestIntPropertyChange("leftDisplay", int(newValue), mode.messageAdapter.LeftDisplay())
 }
 
 func (mode *ElectronicMessagesMode) onRightDisplayChanged(newValue int64) {
-	mode.requestIntPropertyChange(func(properties *dataModel.ElectronicMessage, value *int) {
-		properties.RightDisplay = value
-	}, int(newValue), mode.messageAdapter.RightDisplay())
+	mode.requestIntPropertyChange("rightDisplay", int(newValue), mode.messageAdapter.RightDisplay())
 }
 
 func (mode *ElectronicMessagesMode) onMessageTextChangeRequested(newText string) {
-	var oldText string
 	languageIndex := mode.selectedLanguage.ToIndex()
+	property, oldText := "verboseText", mode.messageAdapter.VerboseText(languageIndex)
 	if mode.selectedVariant == textVariantTerse {
-		oldText = mode.messageAdapter.TerseText(languageIndex)
-	} else {
-		oldText = mode.messageAdapter.VerboseText(languageIndex)
+		property, oldText = "terseText", mode.messageAdapter.TerseText(languageIndex)
 	}
-	mode.requestStringPropertyChange(func(properties *dataModel.ElectronicMessage, value *string) {
-		if mode.selectedVariant == textVariantTerse {
-			properties.TerseText[languageIndex] = value
-		} else {
-			properties.VerboseText[languageIndex] = value
-		}
-	}, newText, oldText)
+	mode.requestStringPropertyChange(property, newText, oldText)
 }
 
 func (mode *ElectronicMessagesMode) onSubjectChangeRequested(newText string) {
-	languageIndex := mode.selectedLanguage.ToIndex()
-	mode.requestStringPropertyChange(func(properties *dataModel.ElectronicMessage, value *string) {
-		properties.Subject[languageIndex] = value
-	}, newText, mode.messageAdapter.Subject(languageIndex))
+	mode.requestStringPropertyChange("subject", newText, mode.messageAdapter.Subject(mode.selectedLanguage.ToIndex()))
 }
 
 func (mode *ElectronicMessagesMode) onSenderChangeRequested(newText string) {
-	languageIndex := mode.selectedLanguage.ToIndex()
-	mode.requestStringPropertyChange(func(properties *dataModel.ElectronicMessage, value *string) {
-		properties.Sender[languageIndex] = value
-	}, newText, mode.messageAdapter.Sender(languageIndex))
+	mode.requestStringPropertyChange("sender", newText, mode.messageAdapter.Sender(mode.selectedLanguage.ToIndex()))
 }
 
 func (mode *ElectronicMessagesMode) onTitleChangeRequested(newText string) {
-	languageIndex := mode.selectedLanguage.ToIndex()
-	mode.requestStringPropertyChange(func(properties *dataModel.ElectronicMessage, value *string) {
-		properties.Title[languageIndex] = value
-	}, newText, mode.messageAdapter.Title(languageIndex))
+	mode.requestStringPropertyChange("title", newText, mode.messageAdapter.Title(mode.selectedLanguage.ToIndex()))
 }
 
-func (mode *ElectronicMessagesMode) requestStringPropertyChange(modifier func(*dataModel.ElectronicMessage, *string),
-	newValue, oldValue string) {
+func (mode *ElectronicMessagesMode) requestStringPropertyChange(property string, newValue, oldValue string) {
 	restoreState := mode.stateSnapshot()
+	modifier := messageTextProperties[property]
+	language := mode.selectedLanguage
 
 	mode.context.Perform(&cmd.SetStringPropertyCommand{
+		Name:   "message property",
+		Target: messageTextTarget(mode.selectedMessageType, mode.selectedMessageID, property, language),
 		Setter: func(value string) error {
-			return mode.requestPropertyChange(restoreState, func(properties *dataModel.ElectronicMessage) { modifier(properties, &value) })
+			return mode.requestPropertyChange(restoreState, func(properties *dataModel.ElectronicMessage) {
+				modifier(properties, language.ToIndex(), value)
+			})
 		},
 		NewValue: newValue,
 		OldValue: oldValue})
 }
 
-func (mode *ElectronicMessagesMode) requestBooleanPropertyChange(modifier func(*dataModel.ElectronicMessage, *bool),
-	newValue, oldValue bool) {
+func (mode *ElectronicMessagesMode) requestBooleanPropertyChange(property string, newValue, oldValue bool) {
 	restoreState := mode.stateSnapshot()
+	modifier := messageBoolProperties[property]
 
 	mode.context.Perform(&cmd.SetBooleanPropertyCommand{
+		Name:   "message property",
+		Target: messagePropertyTarget(mode.selectedMessageType, mode.selectedMessageID, property),
 		Setter: func(value bool) error {
-			return mode.requestPropertyChange(restoreState, func(properties *dataModel.ElectronicMessage) { modifier(properties, &value) })
+			return mode.requestPropertyChange(restoreState, func(properties *dataModel.ElectronicMessage) { modifier(properties, value) })
 		},
 		NewValue: newValue,
 		OldValue: oldValue})
 }
 
-func (mode *ElectronicMessagesMode) requestIntPropertyChange(modifier func(*dataModel.ElectronicMessage, *int),
-	newValue, oldValue int) {
+func (mode *ElectronicMessagesMode) requestIntPropertyChange(property string, newValue, oldValue int) {
 	restoreState := mode.stateSnapshot()
+	modifier := messageIntProperties[property]
 
 	mode.context.Perform(&cmd.SetIntPropertyCommand{
+		Name:   "message property",
+		Target: messagePropertyTarget(mode.selectedMessageType, mode.selectedMessageID, property),
 		Setter: func(value int) error {
-			return mode.requestPropertyChange(restoreState, func(properties *dataModel.ElectronicMessage) { modifier(properties, &value) })
+			return mode.requestPropertyChange(restoreState, func(properties *dataModel.ElectronicMessage) { modifier(properties, value) })
 		},
 		NewValue: newValue,
 		OldValue: oldValue})
@@ -242,6 +242,7 @@ func (mode *GameBitmapsMode) requestBitmapChange(newBitmap *dataModel.RawBitmap)
 	restoreState := mode.stateSnapshot()
 	key := dataModel.MakeLocalizedResourceKey(mode.selectedResourceType, mode.selectedLanguage, uint16(mode.selectedBitmapID))
 	mode.context.Perform(&cmd.SetBitmapCommand{
+		Target: bitmapTarget(key),
 		Setter: func(bmp *dataModel.RawBitmap) error {
 			restoreState()
 			mode.bitmapsAdapter.RequestBitmapChange(key, bmp)
@@ -387,6 +387,8 @@ func (mode *GameObjectsMode) requestBitmapChange(newBitmap *dataModel.RawBitmap)
 	restoreState := mode.stateSnapshot()
 	key := model.ObjectBitmapID{ObjectID: mode.selectedObjectID, Index: mode.selectedBitmapIndex}
 	mode.context.Perform(&cmd.SetBitmapCommand{
+		Name:   "object bitmap",
+		Target: objectBitmapTarget(key),
 		Setter: func(bmp *dataModel.RawBitmap) error {
 			restoreState()
 			mode.objectsAdapter.RequestBitmapChange(key, bmp)
@@ -423,8 +425,7 @@ func (mode *GameObjectsMode) setState(objectID model.ObjectID, bitmapIndex int)
 		bitmapCount := 2
 
 		if object != nil {
-			commonProperties := gameobj.CommonProperties(object.CommonData())
-			bitmapCount = 3 + int(commonProperties.Get("Extra")>>4)
+			bitmapCount = object.BitmapCount()
 		}
 
 		mode.objectTypeBox.SetSelectedItem(selectedTypeItem)
@@ -95,17 +95,7 @@ func NewGameTextsMode(context Context, parent *ui.Area) *GameTextsMode {
 		panelBuilder := newControlPanelBuilder(mode.propertiesArea, context.ControlFactory())
 		{
 			mode.resourceTypeLabel, mode.resourceTypeBox = panelBuilder.addComboProperty("Text Type", mode.onResourceTypeChanged)
-			mode.resourceTypeItems = []*enumItem{
-				{uint32(dataModel.ResourceTypeTrapMessages), "Trap Messages"},
-				{uint32(dataModel.ResourceTypeWords), "Words"},
-				{uint32(dataModel.ResourceTypeLogCategories), "Log Categories"},
-				{uint32(dataModel.ResourceTypeVariousMessages), "Various Messages"},
-				{uint32(dataModel.ResourceTypeScreenMessages), "Screen Messages"},
-				{uint32(dataModel.ResourceTypeInfoNodeMessages), "Info Node Messages (8/5/6)"},
-				{uint32(dataModel.ResourceTypeAccessCardNames), "Access Card Names"},
-				{uint32(dataModel.ResourceTypeDataletMessages), "Datalet Messages (8/5/8)"},
-				{uint32(dataModel.ResourceTypePaperTexts), "Paper Texts"},
-				{uint32(dataModel.ResourceTypePanelNames), "Panel Names"}}
+			mode.resourceTypeItems = gameTextTypeItems()
 
 			mode.resourceTypeBox.SetItems(mode.resourceTypeItems.forComboBox())
 		}
@@ -214,6 +204,8 @@ func (mode *GameTextsMode) onTextChanged() {
 func (mode *GameTextsMode) requestTextChange(newText string) {
 	restoreState := mode.stateSnapshot()
 	mode.context.Perform(&cmd.SetStringPropertyCommand{
+		Name:   "text",
+		Target: textTarget(mode.textAdapter.ResourceKey()),
 		Setter: func(value string) error {
 			restoreState()
 			mode.textAdapter.RequestTextChange(value)
@@ -304,6 +296,7 @@ func (mode *GameTextsMode) importAudio(filePath string) {
 func (mode *GameTextsMode) requestAudioChange(newData audio.SoundData) {
 	restoreState := mode.stateSnapshot()
 	mode.context.Perform(&cmd.SetAudioCommand{
+		Target: audioTarget(mode.soundAdapter.ResourceKey()),
 		Setter: func(data audio.SoundData) error {
 			restoreState()
 			mode.soundAdapter.RequestAudioChange(data)
@@ -345,3 +338,18 @@ func (mode *GameTextsMode) setState(resourceType dataModel.ResourceType, languag
 	mode.textIDSlider.SetValue(int64(mode.selectedTextID))
 	mode.requestData()
 }
+
+// gameTextTypeItems returns the items of the resource types that contain game texts.
+func gameTextTypeItems() enumItems {
+	return enumItems{
+		{uint32(dataModel.ResourceTypeTrapMessages), "Trap Messages"},
+		{uint32(dataModel.ResourceTypeWords), "Words"},
+		{uint32(dataModel.ResourceTypeLogCategories), "Log Categories"},
+		{uint32(dataModel.ResourceTypeVariousMessages), "Various Messages"},
+		{uint32(dataModel.ResourceTypeScreenMessages), "Screen Messages"},
+		{uint32(dataModel.ResourceTypeInfoNodeMessages), "Info Node Messages (8/5/6)"},
+		{uint32(dataModel.ResourceTypeAccessCardNames), "Access Card Names"},
+		{uint32(dataModel.ResourceTypeDataletMessages), "Datalet Messages (8/5/8)"},
+		{uint32(dataModel.ResourceTypePaperTexts), "Paper Texts"},
+		{uint32(dataModel.ResourceTypePanelNames), "Panel Names"}}
+}
@@ -271,43 +271,31 @@ func (mode *GameTexturesMode) updateTextureText() {
 }
 
 func (mode *GameTexturesMode) onNameChangeRequested(newValue string) {
-	mode.requestStringPropertyChange(func(properties *dataModel.TextureProperties, value *string) {
-		properties.Name[mode.selectedLanguage.ToIndex()] = value
-	}, newValue, mode.textureAdapter.GameTexture(mode.selectedTextureID).Name(mode.selectedLanguage))
+	mode.requestStringPropertyChange("name", newValue, mode.textureAdapter.GameTexture(mode.selectedTextureID).Name(mode.selectedLanguage))
 }
 
 func (mode *GameTexturesMode) onUseTextChangeRequested(newValue string) {
-	mode.requestStringPropertyChange(func(properties *dataModel.TextureProperties, value *string) {
-		properties.CantBeUsed[mode.selectedLanguage.ToIndex()] = value
-	}, newValue, mode.textureAdapter.GameTexture(mode.selectedTextureID).UseText(mode.selectedLanguage))
+	mode.requestStringPropertyChange("useText", newValue, mode.textureAdapter.GameTexture(mode.selectedTextureID).UseText(mode.selectedLanguage))
 }
 
 func (mode *GameTexturesMode) onClimbableChanged(boxItem controls.ComboBoxItem) {
 	item := boxItem.(*enumItem)
 	newValue := item.value != 0
-	mode.requestBooleanPropertyChange(func(properties *dataModel.TextureProperties, value *bool) {
-		properties.Climbable = value
-	}, newValue, mode.textureAdapter.GameTexture(mode.selectedTextureID).Climbable())
+	mode.requestBooleanPropertyChange("climbable", newValue, mode.textureAdapter.GameTexture(mode.selectedTextureID).Climbable())
 }
 
 func (mode *GameTexturesMode) onTransparencyControlChanged(boxItem controls.ComboBoxItem) {
 	item := boxItem.(*enumItem)
 	newValue := int(item.value)
-	mode.requestIntPropertyChange(func(properties *dataModel.TextureProperties, value *int) {
-		properties.TransparencyControl = value
-	}, newValue, mode.textureAdapter.GameTexture(mode.selectedTextureID).TransparencyControl())
+	mode.requestIntPropertyChange("transparencyControl", newValue, mode.textureAdapter.GameTexture(mode.selectedTextureID).TransparencyControl())
 }
 
 func (mode *GameTexturesMode) onAnimationGroupChanged(newValue int64) {
-	mode.requestIntPropertyChange(func(properties *dataModel.TextureProperties, value *int) {
-		properties.AnimationGroup = value
-	}, int(newValue), mode.textureAdapter.GameTexture(mode.selectedTextureID).AnimationGroup())
+	mode.requestIntPropertyChange("animationGroup", int(newValue), mode.textureAdapter.GameTexture(mode.selectedTextureID).AnimationGroup())
 }
 
 func (mode *GameTexturesMode) onAnimationIndexChanged(newValue int64) {
-	mode.requestIntPropertyChange(func(properties *dataModel.TextureProperties, value *int) {
-		properties.AnimationIndex = value
-	}, int(newValue), mode.textureAdapter.GameTexture(mode.selectedTextureID).AnimationIndex())
+	mode.requestIntPropertyChange("animationIndex", int(newValue), mode.textureAdapter.GameTexture(mode.selectedTextureID).AnimationIndex())
 }
 
 func (mode *GameTexturesMode) textureDropHandler(textureSize dataModel.TextureSize) ui.EventHandler {
@@ -360,6 +348,8 @@ func (mode *GameTexturesMode) requestTextureBitmapChange(textureSize dataModel.T
 	restoreState := mode.stateSnapshot()
 
 	mode.context.Perform(&cmd.SetBitmapCommand{
+		Name:   "texture bitmap",
+		Target: textureBitmapTarget(mode.selectedTextureID, textureSize),
 		Setter: func(bmp *dataModel.RawBitmap) error {
 			restoreState()
 			mode.textureAdapter.RequestTextureBitmapChange(mode.selectedTextureID, textureSize, bmp)
@@ -369,42 +359,51 @@ func (mode *GameTexturesMode) requestTextureBitmapChange(textureSize dataModel.T
 		OldValue: mode.textureAdapter.TextureBitmap(mode.selectedTextureID, textureSize)})
 }
 
-func (mode *GameTexturesMode) requestStringPropertyChange(modifier func(*dataModel.TextureProperties, *string),
-	newValue, oldValue string) {
+func (mode *GameTexturesMode) requestStringPropertyChange(property string, newValue, oldValue string) {
 	if mode.existingTextureSelected() {
 		restoreState := mode.stateSnapshot()
+		modifier := textureTextProperties[property]
+		language := mode.selectedLanguage
 
 		mode.context.Perform(&cmd.SetStringPropertyCommand{
+			Name:   "texture property",
+			Target: textureTextTarget(mode.selectedTextureID, property, language),
 			Setter: func(value string) error {
-				return mode.requestPropertyChange(restoreState, func(properties *dataModel.TextureProperties) { modifier(properties, &value) })
+				return mode.requestPropertyChange(restoreState, func(properties *dataModel.TextureProperties) {
+					modifier(properties, language.ToIndex(), value)
+				})
 			},
 			NewValue: newValue,
 			OldValue: oldValue})
 	}
 }
 
-func (mode *GameTexturesMode) requestBooleanPropertyChange(modifier func(*dataModel.TextureProperties, *bool),
-	newValue, oldValue bool) {
+func (mode *GameTexturesMode) requestBooleanPropertyChange(property string, newValue, oldValue bool) {
 	if mode.existingTextureSelected() {
 		restoreState := mode.stateSnapshot()
+		modifier := textureBoolProperties[property]
 
 		mode.context.Perform(&cmd.SetBooleanPropertyCommand{
+			Name:   "texture property",
+			Target: textureTarget(mode.selectedTextureID, property),
 			Setter: func(value bool) error {
-				return mode.requestPropertyChange(restoreState, func(properties *dataModel.TextureProperties) { modifier(properties, &value) })
+				return mode.requestPropertyChange(restoreState, func(properties *dataModel.TextureProperties) { modifier(properties, value) })
 			},
 			NewValue: newValue,
 			OldValue: oldValue})
 	}
 }
 
-func (mode *GameTexturesMode) requestIntPropertyChange(modifier func(*dataModel.TextureProperties, *int),
-	newValue, oldValue int) {
+func (mode *GameTexturesMode) requestIntPropertyChange(property string, newValue, oldValue int) {
 	if mode.existingTextureSelected() {
 		restoreState := mode.stateSnapshot()
+		modifier := textureIntProperties[property]
 
 		mode.context.Perform(&cmd.SetIntPropertyCommand{
+			Name:   "texture property",
+			Target: textureTarget(mode.selectedTextureID, property),
 			Setter: func(value int) error {
-				return mode.requestPropertyChange(restoreState, func(properties *dataModel.TextureProperties) { modifier(properties, &value) })
+				return mode.requestPropertyChange(restoreState, func(properties *dataModel.TextureProperties) { modifier(properties, value) })
 			},
 			NewValue: newValue,
 			OldValue: oldValue})
@@ -0,0 +1,649 @@
+package modes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/inkyblackness/res/audio"
+
+	"github.com/inkyblackness/shocked-client/editor/cmd"
+	"github.com/inkyblackness/shocked-client/editor/model"
+
+	dataModel "github.com/inkyblackness/shocked-model"
+)
+
+// Targets identify the data changed by journaled commands. They are paths
+// of the form "<kind>/<parameter>/...".
+const (
+	levelTargetKind         = "level"
+	surveillanceTargetKind  = "surveillance"
+	animationTargetKind     = "textureAnimation"
+	textureTargetKind       = "texture"
+	messageTargetKind       = "message"
+	textTargetKind          = "text"
+	bitmapTargetKind        = "bitmap"
+	objectBitmapTargetKind  = "objectBitmap"
+	textureBitmapTargetKind = "textureBitmap"
+	audioTargetKind         = "audio"
+	messageAudioTargetKind  = "messageAudio"
+
+	levelTexturesProperty = "textures"
+	levelTilesProperty    = "tiles"
+	levelObjectsProperty  = "objects"
+)
+
+func levelPropertyTarget(levelID int, property string) string {
+	return fmt.Sprintf("%v/%d/%v", levelTargetKind, levelID, property)
+}
+
+func levelTexturesTarget(levelID int) string {
+	return levelPropertyTarget(levelID, levelTexturesProperty)
+}
+
+func levelTilesTarget(levelID int) string {
+	return levelPropertyTarget(levelID, levelTilesProperty)
+}
+
+func levelObjectsTarget(levelID int) string {
+	return levelPropertyTarget(levelID, levelObjectsProperty)
+}
+
+func surveillanceTarget(levelID int, surveillanceIndex int, property string) string {
+	return fmt.Sprintf("%v/%d/%d/%v", surveillanceTargetKind, levelID, surveillanceIndex, property)
+}
+
+func animationTarget(levelID int, group int, property string) string {
+	return fmt.Sprintf("%v/%d/%d/%v", animationTargetKind, levelID, group, property)
+}
+
+func textureTarget(textureID int, property string) string {
+	return fmt.Sprintf("%v/%d/%v", textureTargetKind, textureID, property)
+}
+
+func textureTextTarget(textureID int, property string, language dataModel.ResourceLanguage) string {
+	return fmt.Sprintf("%v/%d", textureTarget(textureID, property), language.ToIndex())
+}
+
+func messageTarget(messageType dataModel.ElectronicMessageType, id int) string {
+	return fmt.Sprintf("%v/%v/%d", messageTargetKind, messageType, id)
+}
+
+func messagePropertyTarget(messageType dataModel.ElectronicMessageType, id int, property string) string {
+	return fmt.Sprintf("%v/%v", messageTarget(messageType, id), property)
+}
+
+func messageTextTarget(messageType dataModel.ElectronicMessageType, id int, property string,
+	language dataModel.ResourceLanguage) string {
+	return fmt.Sprintf("%v/%d", messagePropertyTarget(messageType, id, property), language.ToIndex())
+}
+
+func textTarget(key dataModel.ResourceKey) string {
+	return fmt.Sprintf("%v/%d", textTargetKind, key.ToInt())
+}
+
+func bitmapTarget(key dataModel.ResourceKey) string {
+	return fmt.Sprintf("%v/%d", bitmapTargetKind, key.ToInt())
+}
+
+func objectBitmapTarget(id model.ObjectBitmapID) string {
+	return fmt.Sprintf("%v/%d", objectBitmapTargetKind, id.ToInt())
+}
+
+func textureBitmapTarget(textureID int, size dataModel.TextureSize) string {
+	return fmt.Sprintf("%v/%d/%v", textureBitmapTargetKind, textureID, size)
+}
+
+func audioTarget(key dataModel.ResourceKey) string {
+	return fmt.Sprintf("%v/%d", audioTargetKind, key.ToInt())
+}
+
+func messageAudioTarget(messageType dataModel.ElectronicMessageType, id int, language dataModel.ResourceLanguage) string {
+	return fmt.Sprintf("%v/%v/%d/%d", messageAudioTargetKind, messageType, id, language.ToIndex())
+}
+
+// levelIntProperties are the integer level properties that can be journaled.
+var levelIntProperties = map[string]func(properties *dataModel.LevelProperties, value int){
+	"heightShift":        func(properties *dataModel.LevelProperties, value int) { properties.HeightShift = &value },
+	"ceilingEffectLevel": func(properties *dataModel.LevelProperties, value int) { properties.CeilingEffectLevel = &value },
+	"floorEffectLevel":   func(properties *dataModel.LevelProperties, value int) { properties.FloorEffectLevel = &value },
+	"floorEffect": func(properties *dataModel.LevelProperties, value int) {
+		biohazard := value == floorEffectBiohazard
+		gravity := value == floorEffectGravity
+
+		properties.FloorHasBiohazard = &biohazard
+		properties.FloorHasGravity = &gravity
+	}}
+
+// levelBoolProperties are the boolean level properties that can be journaled, named as in level diffs.
+var levelBoolProperties = map[string]func(properties *dataModel.LevelProperties, value bool){
+	"cyberspace":       func(properties *dataModel.LevelProperties, value bool) { properties.CyberspaceFlag = &value },
+	"ceilingRadiation": func(properties *dataModel.LevelProperties, value bool) { properties.CeilingHasRadiation = &value },
+	"floorBiohazard":   func(properties *dataModel.LevelProperties, value bool) { properties.FloorHasBiohazard = &value },
+	"floorGravity":     func(properties *dataModel.LevelProperties, value bool) { properties.FloorHasGravity = &value }}
+
+// surveillanceProperties are the object references of a surveillance entry.
+var surveillanceProperties = map[string]func(value int) (sourceObject *int, deathwatchObject *int){
+	"source":     func(value int) (*int, *int) { return &value, nil },
+	"deathwatch": func(value int) (*int, *int) { return nil, &value }}
+
+// animationProperties are the properties of a texture animation group.
+var animationProperties = map[string]func(properties *dataModel.TextureAnimation, value int){
+	"frameTime":  func(properties *dataModel.TextureAnimation, value int) { properties.FrameTime = &value },
+	"frameCount": func(properties *dataModel.TextureAnimation, value int) { properties.FrameCount = &value },
+	"loopType":   func(properties *dataModel.TextureAnimation, value int) { properties.LoopType = &value }}
+
+// textureIntProperties are the integer properties of game textures.
+var textureIntProperties = map[string]func(properties *dataModel.TextureProperties, value int){
+	"transparencyControl": func(properties *dataModel.TextureProperties, value int) { properties.TransparencyControl = &value },
+	"animationGroup":      func(properties *dataModel.TextureProperties, value int) { properties.AnimationGroup = &value },
+	"animationIndex":      func(properties *dataModel.TextureProperties, value int) { properties.AnimationIndex = &value }}
+
+// textureBoolProperties are the boolean properties of game textures.
+var textureBoolProperties = map[string]func(properties *dataModel.TextureProperties, value bool){
+	"climbable": func(properties *dataModel.TextureProperties, value bool) { properties.Climbable = &value }}
+
+// textureTextProperties are the texts of game textures, which exist per language.
+var textureTextProperties = map[string]func(properties *dataModel.TextureProperties, language int, value string){
+	"name": func(properties *dataModel.TextureProperties, language int, value string) {
+		properties.Name[language] = &value
+	},
+	"useText": func(properties *dataModel.TextureProperties, language int, value string) {
+		properties.CantBeUsed[language] = &value
+	}}
+
+// messageIntProperties are the integer properties of electronic messages.
+var messageIntProperties = map[string]func(properties *dataModel.ElectronicMessage, value int){
+	"nextMessage":  func(properties *dataModel.ElectronicMessage, value int) { properties.NextMessage = &value },
+	"colorIndex":   func(properties *dataModel.ElectronicMessage, value int) { properties.ColorIndex = &value },
+	"leftDisplay":  func(properties *dataModel.ElectronicMessage, value int) { properties.LeftDisplay = &value },
+	"rightDisplay": func(properties *dataModel.ElectronicMessage, value int) { properties.RightDisplay = &value }}
+
+// messageBoolProperties are the boolean properties of electronic messages.
+var messageBoolProperties = map[string]func(properties *dataModel.ElectronicMessage, value bool){
+	"interrupt": func(properties *dataModel.ElectronicMessage, value bool) { properties.IsInterrupt = &value }}
+
+// messageTextProperties are the texts of electronic messages, which exist per language.
+var messageTextProperties = map[string]func(properties *dataModel.ElectronicMessage, language int, value string){
+	"title": func(properties *dataModel.ElectronicMessage, language int, value string) {
+		properties.Title[language] = &value
+	},
+	"sender": func(properties *dataModel.ElectronicMessage, language int, value string) {
+		properties.Sender[language] = &value
+	},
+	"subject": func(properties *dataModel.ElectronicMessage, language int, value string) {
+		properties.Subject[language] = &value
+	},
+	"verboseText": func(properties *dataModel.ElectronicMessage, language int, value string) {
+		properties.VerboseText[language] = &value
+	},
+	"terseText": func(properties *dataModel.ElectronicMessage, language int, value string) {
+		properties.TerseText[language] = &value
+	}}
+
+type setterResolver struct {
+	adapter *model.Adapter
+}
+
+// NewSetterResolver returns a resolver for the targets of journaled commands.
+// The returned setters request their changes through the given adapter.
+func NewSetterResolver(adapter *model.Adapter) cmd.SetterResolver {
+	return &setterResolver{adapter: adapter}
+}
+
+// parseTarget splits the target into its parameters, verifying the kind and the amount of parameters.
+func parseTarget(target string, kind string, parameterCount int) ([]string, error) {
+	parts := strings.Split(target, "/")
+	if (len(parts) != parameterCount+1) || (parts[0] != kind) {
+		return nil, fmt.Errorf("invalid target <%v>", target)
+	}
+	return parts[1:], nil
+}
+
+func parseTargetIntegers(target string, kind string, parameterCount int) ([]int, error) {
+	parameters, err := parseTarget(target, kind, parameterCount)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]int, len(parameters))
+	for index, parameter := range parameters {
+		values[index], err = strconv.Atoi(parameter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid target <%v>: %v", target, err)
+		}
+	}
+	return values, nil
+}
+
+func (resolver *setterResolver) IntSetter(target string) (func(value int) error, error) {
+	kind := strings.SplitN(target, "/", 2)[0]
+	switch kind {
+	case levelTargetKind:
+		levelID, property, err := parseLevelPropertyTarget(target)
+		modifier, known := levelIntProperties[property]
+		if (err == nil) && !known {
+			err = fmt.Errorf("unknown level property <%v>", property)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return func(value int) error {
+			requestLevelActive(resolver.adapter, levelID).RequestLevelPropertiesChange(func(properties *dataModel.LevelProperties) {
+				modifier(properties, value)
+			})
+			return nil
+		}, nil
+	case surveillanceTargetKind:
+		levelID, surveillanceIndex, property, err := parseIndexedLevelTarget(target, kind)
+		references, known := surveillanceProperties[property]
+		if (err == nil) && !known {
+			err = fmt.Errorf("unknown surveillance property <%v>", property)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return func(value int) error {
+			sourceObject, deathwatchObject := references(value)
+			requestLevelActive(resolver.adapter, levelID).RequestObjectSurveillance(surveillanceIndex, sourceObject, deathwatchObject)
+			return nil
+		}, nil
+	case animationTargetKind:
+		levelID, group, property, err := parseIndexedLevelTarget(target, kind)
+		modifier, known := animationProperties[property]
+		if (err == nil) && !known {
+			err = fmt.Errorf("unknown animation property <%v>", property)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return func(value int) error {
+			var properties dataModel.TextureAnimation
+			modifier(&properties, value)
+			requestLevelActive(resolver.adapter, levelID).RequestLevelTextureAnimationGroupChange(group, properties)
+			return nil
+		}, nil
+	case textureTargetKind:
+		textureID, property, err := parseTextureTarget(target, 2)
+		modifier, known := textureIntProperties[property]
+		if (err == nil) && !known {
+			err = fmt.Errorf("unknown texture property <%v>", property)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return func(value int) error {
+			var properties dataModel.TextureProperties
+			modifier(&properties, value)
+			resolver.adapter.TextureAdapter().RequestTexturePropertiesChange(textureID, &properties)
+			return nil
+		}, nil
+	case messageTargetKind:
+		messageType, id, property, err := parseMessageTarget(target, 3)
+		modifier, known := messageIntProperties[property]
+		if (err == nil) && !known {
+			err = fmt.Errorf("unknown message property <%v>", property)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return func(value int) error {
+			var properties dataModel.ElectronicMessage
+			modifier(&properties, value)
+			resolver.messageStore(messageType, id).RequestMessageChange(properties)
+			return nil
+		}, nil
+	}
+	return nil, fmt.Errorf("invalid integer target <%v>", target)
+}
+
+func (resolver *setterResolver) BooleanSetter(target string) (func(value bool) error, error) {
+	kind := strings.SplitN(target, "/", 2)[0]
+	switch kind {
+	case levelTargetKind:
+		levelID, property, err := parseLevelPropertyTarget(target)
+		modifier, known := levelBoolProperties[property]
+		if (err == nil) && !known {
+			err = fmt.Errorf("unknown level property <%v>", property)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return func(value bool) error {
+			requestLevelActive(resolver.adapter, levelID).RequestLevelPropertiesChange(func(properties *dataModel.LevelProperties) {
+				modifier(properties, value)
+			})
+			return nil
+		}, nil
+	case textureTargetKind:
+		textureID, property, err := parseTextureTarget(target, 2)
+		modifier, known := textureBoolProperties[property]
+		if (err == nil) && !known {
+			err = fmt.Errorf("unknown texture property <%v>", property)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return func(value bool) error {
+			var properties dataModel.TextureProperties
+			modifier(&properties, value)
+			resolver.adapter.TextureAdapter().RequestTexturePropertiesChange(textureID, &properties)
+			return nil
+		}, nil
+	case messageTargetKind:
+		messageType, id, property, err := parseMessageTarget(target, 3)
+		modifier, known := messageBoolProperties[property]
+		if (err == nil) && !known {
+			err = fmt.Errorf("unknown message property <%v>", property)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return func(value bool) error {
+			var properties dataModel.ElectronicMessage
+			modifier(&properties, value)
+			resolver.messageStore(messageType, id).RequestMessageChange(properties)
+			return nil
+		}, nil
+	}
+	return nil, fmt.Errorf("invalid boolean target <%v>", target)
+}
+
+func (resolver *setterResolver) StringSetter(target string) (func(value string) error, error) {
+	kind := strings.SplitN(target, "/", 2)[0]
+	switch kind {
+	case textureTargetKind:
+		textureID, property, err := parseTextureTarget(target, 3)
+		modifier, known := textureTextProperties[property]
+		language, languageErr := parseTargetLanguage(target)
+		if (err == nil) && !known {
+			err = fmt.Errorf("unknown texture property <%v>", property)
+		}
+		if err == nil {
+			err = languageErr
+		}
+		if err != nil {
+			return nil, err
+		}
+		return func(value string) error {
+			var properties dataModel.TextureProperties
+			modifier(&properties, language, value)
+			resolver.adapter.TextureAdapter().RequestTexturePropertiesChange(textureID, &properties)
+			return nil
+		}, nil
+	case messageTargetKind:
+		messageType, id, property, err := parseMessageTarget(target, 4)
+		modifier, known := messageTextProperties[property]
+		language, languageErr := parseTargetLanguage(target)
+		if (err == nil) && !known {
+			err = fmt.Errorf("unknown message property <%v>", property)
+		}
+		if err == nil {
+			err = languageErr
+		}
+		if err != nil {
+			return nil, err
+		}
+		return func(value string) error {
+			var properties dataModel.ElectronicMessage
+			modifier(&properties, language, value)
+			resolver.messageStore(messageType, id).RequestMessageChange(properties)
+			return nil
+		}, nil
+	case textTargetKind:
+		values, err := parseTargetIntegers(target, kind, 1)
+		if err != nil {
+			return nil, err
+		}
+		key := dataModel.ResourceKeyFromInt(values[0])
+		return func(value string) error {
+			textAdapter := resolver.adapter.TextAdapter()
+			textAdapter.RequestText(key)
+			textAdapter.RequestTextChange(value)
+			return nil
+		}, nil
+	}
+	return nil, fmt.Errorf("invalid text target <%v>", target)
+}
+
+func (resolver *setterResolver) BitmapSetter(target string) (func(bmp *dataModel.RawBitmap) error, error) {
+	kind := strings.SplitN(target, "/", 2)[0]
+	switch kind {
+	case bitmapTargetKind:
+		values, err := parseTargetIntegers(target, kind, 1)
+		if err != nil {
+			return nil, err
+		}
+		key := dataModel.ResourceKeyFromInt(values[0])
+		return func(bmp *dataModel.RawBitmap) error {
+			resolver.adapter.BitmapsAdapter().RequestBitmapChange(key, bmp)
+			return nil
+		}, nil
+	case objectBitmapTargetKind:
+		values, err := parseTargetIntegers(target, kind, 1)
+		if err != nil {
+			return nil, err
+		}
+		id := model.ObjectBitmapIDFromInt(values[0])
+		return func(bmp *dataModel.RawBitmap) error {
+			resolver.adapter.ObjectsAdapter().RequestBitmapChange(id, bmp)
+			return nil
+		}, nil
+	case textureBitmapTargetKind:
+		parameters, err := parseTarget(target, kind, 2)
+		if err != nil {
+			return nil, err
+		}
+		textureID, err := strconv.Atoi(parameters[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid target <%v>: %v", target, err)
+		}
+		size := dataModel.TextureSize(parameters[1])
+		return func(bmp *dataModel.RawBitmap) error {
+			resolver.adapter.TextureAdapter().RequestTextureBitmapChange(textureID, size, bmp)
+			return nil
+		}, nil
+	}
+	return nil, fmt.Errorf("invalid bitmap target <%v>", target)
+}
+
+func (resolver *setterResolver) AudioSetter(target string) (func(data audio.SoundData) error, error) {
+	kind := strings.SplitN(target, "/", 2)[0]
+	switch kind {
+	case audioTargetKind:
+		values, err := parseTargetIntegers(target, kind, 1)
+		if err != nil {
+			return nil, err
+		}
+		key := dataModel.ResourceKeyFromInt(values[0])
+		return func(data audio.SoundData) error {
+			soundAdapter := resolver.adapter.SoundAdapter()
+			if soundAdapter.ResourceKey() != key {
+				soundAdapter.RequestAudio(key)
+			}
+			soundAdapter.RequestAudioChange(data)
+			return nil
+		}, nil
+	case messageAudioTargetKind:
+		parameters, err := parseTarget(target, kind, 3)
+		if err != nil {
+			return nil, err
+		}
+		messageType := dataModel.ElectronicMessageType(parameters[0])
+		id, idErr := strconv.Atoi(parameters[1])
+		languageIndex, languageErr := strconv.Atoi(parameters[2])
+		languages := dataModel.LocalLanguages()
+		if (idErr != nil) || (languageErr != nil) || (languageIndex < 0) || (languageIndex >= len(languages)) {
+			return nil, fmt.Errorf("invalid target <%v>", target)
+		}
+		language := languages[languageIndex]
+		return func(data audio.SoundData) error {
+			resolver.messageStore(messageType, id).RequestAudioChange(language, data)
+			return nil
+		}, nil
+	}
+	return nil, fmt.Errorf("invalid audio target <%v>", target)
+}
+
+// parseLevelTarget returns the level of a target for given level property.
+func parseLevelTarget(target string, property string) (levelID int, err error) {
+	parameters, err := parseTarget(target, levelTargetKind, 2)
+	if (err == nil) && (parameters[1] != property) {
+		err = fmt.Errorf("invalid target <%v>", target)
+	}
+	if err == nil {
+		levelID, err = strconv.Atoi(parameters[0])
+	}
+	return
+}
+
+// parseLevelPropertyTarget returns the level and the property of a level property target.
+func parseLevelPropertyTarget(target string) (levelID int, property string, err error) {
+	parameters, err := parseTarget(target, levelTargetKind, 2)
+	if err == nil {
+		property = parameters[1]
+		levelID, err = strconv.Atoi(parameters[0])
+	}
+	return
+}
+
+// parseIndexedLevelTarget returns the level, the index and the property of targets of given kind,
+// which refer to an entry of a list within a level.
+func parseIndexedLevelTarget(target string, kind string) (levelID int, index int, property string, err error) {
+	parameters, err := parseTarget(target, kind, 3)
+	if err == nil {
+		property = parameters[2]
+		levelID, err = strconv.Atoi(parameters[0])
+	}
+	if err == nil {
+		index, err = strconv.Atoi(parameters[1])
+	}
+	return
+}
+
+// parseTextureTarget returns the texture and the property of a texture target having given amount of parameters.
+func parseTextureTarget(target string, parameterCount int) (textureID int, property string, err error) {
+	parameters, err := parseTarget(target, textureTargetKind, parameterCount)
+	if err == nil {
+		property = parameters[1]
+		textureID, err = strconv.Atoi(parameters[0])
+	}
+	return
+}
+
+// parseMessageTarget returns the message and the property of a message target having given amount of parameters.
+// Targets with two parameters refer to the message as a whole and have no property.
+func parseMessageTarget(target string, parameterCount int) (messageType dataModel.ElectronicMessageType,
+	id int, property string, err error) {
+	parameters, err := parseTarget(target, messageTargetKind, parameterCount)
+	if err == nil {
+		messageType = dataModel.ElectronicMessageType(parameters[0])
+		id, err = strconv.Atoi(parameters[1])
+	}
+	if (err == nil) && (parameterCount > 2) {
+		property = parameters[2]
+	}
+	return
+}
+
+// parseTargetLanguage returns the index of the language, which is the last parameter of texts.
+func parseTargetLanguage(target string) (int, error) {
+	parts := strings.Split(target, "/")
+	language, err := strconv.Atoi(parts[len(parts)-1])
+	if (err != nil) || (language < 0) || (language >= dataModel.LanguageCount) {
+		return 0, fmt.Errorf("invalid language in target <%v>", target)
+	}
+	return language, nil
+}
+
+func (resolver *setterResolver) LevelTexturesSetter(target string) (func(textureIDs []int) error, error) {
+	levelID, err := parseLevelTarget(target, levelTexturesProperty)
+	if err != nil {
+		return nil, err
+	}
+	return func(textureIDs []int) error {
+		requestLevelActive(resolver.adapter, levelID).RequestLevelTexturesChange(textureIDs)
+		return nil
+	}, nil
+}
+
+func (resolver *setterResolver) TilesSetter(target string) (func(x, y int, properties dataModel.TileProperties) error, error) {
+	levelID, err := parseLevelTarget(target, levelTilesProperty)
+	if err != nil {
+		return nil, err
+	}
+	return func(x, y int, properties dataModel.TileProperties) error {
+		coordinates := []model.TileCoordinate{model.TileCoordinateOf(x, y)}
+		requestLevelActive(resolver.adapter, levelID).RequestTilePropertyChange(coordinates, &properties)
+		return nil
+	}, nil
+}
+
+func (resolver *setterResolver) LevelObjectsSetter(target string) (func(objectIndex int, properties dataModel.LevelObjectProperties) error, error) {
+	levelID, err := parseLevelTarget(target, levelObjectsProperty)
+	if err != nil {
+		return nil, err
+	}
+	return func(objectIndex int, properties dataModel.LevelObjectProperties) error {
+		requestLevelActive(resolver.adapter, levelID).RequestObjectPropertiesChange([]int{objectIndex}, &properties)
+		return nil
+	}, nil
+}
+
+func (resolver *setterResolver) LevelObjectAdder(target string) (func(class int, properties dataModel.LevelObjectProperties, onAdded func(objectIndex int), onFailed func()) error, error) {
+	levelID, err := parseLevelTarget(target, levelObjectsProperty)
+	if err != nil {
+		return nil, err
+	}
+	return func(class int, properties dataModel.LevelObjectProperties, onAdded func(objectIndex int), onFailed func()) error {
+		requestLevelActive(resolver.adapter, levelID).RequestNewObjectWithProperties(class, properties,
+			func(object *model.LevelObject) { onAdded(object.Index()) }, onFailed)
+		return nil
+	}, nil
+}
+
+func (resolver *setterResolver) LevelObjectRemover(target string) (func(objectIndex int) error, error) {
+	levelID, err := parseLevelTarget(target, levelObjectsProperty)
+	if err != nil {
+		return nil, err
+	}
+	return func(objectIndex int) error {
+		requestLevelActive(resolver.adapter, levelID).RequestRemoveObjects([]int{objectIndex})
+		return nil
+	}, nil
+}
+
+func (resolver *setterResolver) ElectronicMessageStore(target string) (cmd.ElectronicMessageStore, error) {
+	messageType, id, _, err := parseMessageTarget(target, 2)
+	if err != nil {
+		return nil, err
+	}
+	return resolver.messageStore(messageType, id), nil
+}
+
+// messageStore returns a store for the identified message, which selects the message before each change.
+func (resolver *setterResolver) messageStore(messageType dataModel.ElectronicMessageType, id int) cmd.ElectronicMessageStore {
+	return &selectingMessageStore{adapter: resolver.adapter.ElectronicMessageAdapter(), messageType: messageType, id: id}
+}
+
+type selectingMessageStore struct {
+	adapter     *model.ElectronicMessageAdapter
+	messageType dataModel.ElectronicMessageType
+	id          int
+}
+
+func (store *selectingMessageStore) selected() *model.ElectronicMessageAdapter {
+	store.adapter.RequestMessage(store.messageType, store.id)
+	return store.adapter
+}
+
+func (store *selectingMessageStore) RequestRemove() {
+	store.selected().RequestRemove()
+}
+
+func (store *selectingMessageStore) RequestMessageChange(properties dataModel.ElectronicMessage) {
+	store.selected().RequestMessageChange(properties)
+}
+
+func (store *selectingMessageStore) RequestAudioChange(language dataModel.ResourceLanguage, data audio.SoundData) {
+	store.selected().RequestAudioChange(language, data)
+}
@@ -0,0 +1,90 @@
+package modes
+
+import (
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/editor/cmd"
+	"github.com/inkyblackness/shocked-client/editor/model"
+	"github.com/inkyblackness/shocked-client/editor/model/memstore"
+
+	dataModel "github.com/inkyblackness/shocked-model"
+)
+
+type JournalTargetsSuite struct {
+	store    *memstore.DataStore
+	level    *memstore.Level
+	resolver cmd.SetterResolver
+}
+
+var _ = check.Suite(&JournalTargetsSuite{})
+
+func (suite *JournalTargetsSuite) SetUpTest(c *check.C) {
+	suite.store = memstore.NewDataStore(nil)
+	suite.level = suite.store.Project("project").AddLevel("archive", 1)
+	adapter := model.NewAdapter(suite.store)
+	adapter.RequestProject("project")
+	suite.store.Flush()
+	suite.resolver = NewSetterResolver(adapter)
+}
+
+func (suite *JournalTargetsSuite) TestTargetsOfModesAreResolved(c *check.C) {
+	messageType := dataModel.ElectronicMessageTypeMail
+	language := dataModel.LocalLanguages()[1]
+	for _, target := range []string{
+		levelPropertyTarget(1, "floorEffect"),
+		surveillanceTarget(1, 2, "deathwatch"),
+		animationTarget(1, 3, "loopType"),
+		textureTarget(4, "animationIndex"),
+		messagePropertyTarget(messageType, 5, "colorIndex")} {
+		_, err := suite.resolver.IntSetter(target)
+		c.Check(err, check.IsNil, check.Commentf("%v", target))
+	}
+	for _, target := range []string{
+		levelPropertyTarget(1, "ceilingRadiation"),
+		textureTarget(4, "climbable"),
+		messagePropertyTarget(messageType, 5, "interrupt")} {
+		_, err := suite.resolver.BooleanSetter(target)
+		c.Check(err, check.IsNil, check.Commentf("%v", target))
+	}
+	for _, target := range []string{
+		textureTextTarget(4, "useText", language),
+		messageTextTarget(messageType, 5, "terseText", language),
+		textTarget(dataModel.MakeLocalizedResourceKey(dataModel.ResourceTypeTrapMessages, language, 6))} {
+		_, err := suite.resolver.StringSetter(target)
+		c.Check(err, check.IsNil, check.Commentf("%v", target))
+	}
+	_, err := suite.resolver.ElectronicMessageStore(messageTarget(messageType, 5))
+	c.Check(err, check.IsNil)
+}
+
+func (suite *JournalTargetsSuite) TestUnknownPropertiesAreRejected(c *check.C) {
+	_, intErr := suite.resolver.IntSetter(surveillanceTarget(1, 2, "unknown"))
+	_, boolErr := suite.resolver.BooleanSetter(textureTarget(4, "unknown"))
+	_, stringErr := suite.resolver.StringSetter(textureTextTarget(4, "name", dataModel.ResourceLanguage(7)))
+	_, storeErr := suite.resolver.ElectronicMessageStore(levelPropertyTarget(1, "message"))
+
+	c.Check(intErr, check.NotNil)
+	c.Check(boolErr, check.NotNil)
+	c.Check(stringErr, check.NotNil)
+	c.Check(storeErr, check.NotNil)
+}
+
+func (suite *JournalTargetsSuite) TestSurveillanceSetterChangesLevel(c *check.C) {
+	setter, err := suite.resolver.IntSetter(surveillanceTarget(1, 2, "source"))
+	c.Assert(err, check.IsNil)
+
+	setter(12)
+	suite.store.Flush()
+
+	c.Check(*suite.level.Surveillance()[2].SourceIndex, check.Equals, 12)
+}
+
+func (suite *JournalTargetsSuite) TestLevelBooleanSetterChangesLevel(c *check.C) {
+	setter, err := suite.resolver.BooleanSetter(levelPropertyTarget(1, "ceilingRadiation"))
+	c.Assert(err, check.IsNil)
+
+	setter(true)
+	suite.store.Flush()
+
+	c.Check(*suite.level.Properties().CeilingHasRadiation, check.Equals, true)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/inkyblackness/res/data"
+	"github.com/inkyblackness/shocked-client/editor/actions"
 	"github.com/inkyblackness/shocked-client/editor/cmd"
 	"github.com/inkyblackness/shocked-client/editor/display"
 	"github.com/inkyblackness/shocked-client/editor/model"
@@ -19,6 +20,10 @@ func lbpValueFormatter(value int64) string {
 	return fmt.Sprintf("%v.%v LBP", value/2, (value%2)*5)
 }
 
+var lbpValueParser = unitValueParser(func(value float64) float64 { return value * 2 }, "LBP")
+
+var percentValueParser = unitValueParser(func(value float64) float64 { return value / 25 }, "%")
+
 type ceilingEffect uint32
 
 const (
@@ -34,6 +39,14 @@ func (effect ceilingEffect) formatter() (f func(int64) string) {
 	return
 }
 
+func (effect ceilingEffect) parser() (p controls.SliderValueParser) {
+	p = controls.DefaultSliderValueParser
+	if effect == ceilingEffectRadiation {
+		p = lbpValueParser
+	}
+	return
+}
+
 type floorEffect uint32
 
 const (
@@ -52,6 +65,16 @@ func (effect floorEffect) formatter() (f func(int64) string) {
 	return
 }
 
+func (effect floorEffect) parser() (p controls.SliderValueParser) {
+	p = controls.DefaultSliderValueParser
+	if effect == floorEffectGravity {
+		p = percentValueParser
+	} else if effect == floorEffectBiohazard {
+		p = lbpValueParser
+	}
+	return
+}
+
 // LevelControlMode is a mode for archive level control.
 type LevelControlMode struct {
 	context      Context
@@ -68,6 +91,9 @@ type LevelControlMode struct {
 	heightShiftBox   *controls.ComboBox
 	heightShiftItems enumItems
 
+	levelFileTitle *controls.Label
+	levelFileInfo  *controls.Label
+
 	realWorldProperties *ui.Area
 
 	levelGenericTexturesLabel    *controls.Label
@@ -141,6 +167,7 @@ func NewLevelControlMode(context Context, parent *ui.Area, mapDisplay *display.M
 		builder.OnEvent(events.MouseButtonDownEventType, ui.SilentConsumer)
 		builder.OnEvent(events.MouseButtonClickedEventType, ui.SilentConsumer)
 		builder.OnEvent(events.MouseScrollEventType, ui.SilentConsumer)
+		builder.OnEvent(events.FileDropEventType, mode.onFileDropped)
 		mode.area = builder.Build()
 	}
 	{
@@ -148,14 +175,7 @@ func NewLevelControlMode(context Context, parent *ui.Area, mapDisplay *display.M
 
 		{
 			mode.activeLevelLabel, mode.activeLevelBox = panelBuilder.addComboProperty("Active Level", func(item controls.ComboBoxItem) {
-				selectedLevelID := item.(int)
-				context.Perform(&cmd.SetActiveLevelCommand{
-					Setter: func(levelID int) error {
-						context.ModelAdapter().RequestActiveLevel(levelID)
-						return nil
-					},
-					OldValue: context.ModelAdapter().ActiveLevel().ID(),
-					NewValue: selectedLevelID})
+				mode.requestActiveLevel(item.(int))
 			})
 
 			adapter := context.ModelAdapter()
@@ -175,6 +195,7 @@ func NewLevelControlMode(context Context, parent *ui.Area, mapDisplay *display.M
 				items := make([]controls.ComboBoxItem, len(ids))
 				for index, id := range ids {
 					items[index] = id
+					mode.registerLevelAction(id)
 				}
 				mode.activeLevelBox.SetItems(items)
 			})
@@ -200,6 +221,10 @@ func NewLevelControlMode(context Context, parent *ui.Area, mapDisplay *display.M
 				}
 			})
 		}
+		{
+			mode.levelFileTitle, mode.levelFileInfo = panelBuilder.addInfo("Level File")
+			mode.levelFileInfo.SetText("Drop a folder to export, a file to import")
+		}
 
 		{
 			var realWorldBuilder *controlPanelBuilder
@@ -254,6 +279,7 @@ func NewLevelControlMode(context Context, parent *ui.Area, mapDisplay *display.M
 					mode.ceilingEffectBox.SetSelectedItem(mode.ceilingEffectItems[effect])
 					mode.ceilingEffectLevelSlider.SetValue(int64(level))
 					mode.ceilingEffectLevelSlider.SetValueFormatter(effect.formatter())
+					mode.ceilingEffectLevelSlider.SetValueParser(effect.parser())
 				})
 			}
 			{
@@ -274,6 +300,7 @@ func NewLevelControlMode(context Context, parent *ui.Area, mapDisplay *display.M
 					mode.floorEffectBox.SetSelectedItem(mode.floorEffectItems[effect])
 					mode.floorEffectLevelSlider.SetValue(int64(level))
 					mode.floorEffectLevelSlider.SetValueFormatter(effect.formatter())
+					mode.floorEffectLevelSlider.SetValueParser(effect.parser())
 				})
 			}
 			{
@@ -320,6 +347,40 @@ func (mode *LevelControlMode) SetActive(active bool) {
 	mode.mapDisplay.SetVisible(active)
 }
 
+func (mode *LevelControlMode) requestActiveLevel(selectedLevelID int) {
+	mode.context.Perform(&cmd.SetActiveLevelCommand{
+		Setter: func(levelID int) error {
+			mode.context.ModelAdapter().RequestActiveLevel(levelID)
+			return nil
+		},
+		OldValue: mode.levelAdapter.ID(),
+		NewValue: selectedLevelID})
+}
+
+// registerLevelAction registers an action to select the given level, unless already known.
+// The action is available as long as the level is part of the current archive.
+func (mode *LevelControlMode) registerLevelAction(levelID int) {
+	name := fmt.Sprintf("level.select.%d", levelID)
+	if mode.context.Actions().Action(name) != nil {
+		return
+	}
+	mode.context.Actions().Register(actions.Action{
+		Name:    name,
+		Title:   fmt.Sprintf("Select level %d", levelID),
+		Handler: func() { mode.requestActiveLevel(levelID) },
+		Available: func() bool {
+			if levelID == mode.levelAdapter.ID() {
+				return false
+			}
+			for _, id := range mode.context.ModelAdapter().AvailableLevelIDs() {
+				if id == levelID {
+					return true
+				}
+			}
+			return false
+		}})
+}
+
 func (mode *LevelControlMode) currentFloorEffect() (floorEffect, int) {
 	biohazard, gravity, level := mode.levelAdapter.FloorEffect()
 	effect := floorEffect(floorEffectNone)
@@ -376,6 +437,8 @@ func (mode *LevelControlMode) onHeightShiftChanged(boxItem controls.ComboBoxItem
 	newValue := int(item.value)
 
 	mode.context.Perform(&cmd.SetIntPropertyCommand{
+		Name:   "tile height",
+		Target: levelPropertyTarget(mode.levelAdapter.ID(), "heightShift"),
 		Setter: func(value int) error {
 			mode.levelAdapter.RequestLevelPropertiesChange(func(properties *dataModel.LevelProperties) {
 				properties.HeightShift = intAsPointer(value)
@@ -432,6 +495,7 @@ func (mode *LevelControlMode) setLevelTextureID(id int) {
 		newIDs[mode.currentLevelTextureIndex] = id
 
 		mode.context.Perform(&cmd.SetLevelTexturesCommand{
+			Target: levelTexturesTarget(levelAdapter.ID()),
 			Setter: func(textureIDs []int) error {
 				mode.worldTexturesSelector.SetSelectedIndex(textureIDs[mode.currentLevelTextureIndex])
 				mode.worldTexturesIDSlider.SetValue(int64(textureIDs[mode.currentLevelTextureIndex]))
@@ -478,28 +542,27 @@ func (mode *LevelControlMode) onSurveillanceIndexChanged(boxItem controls.ComboB
 
 func (mode *LevelControlMode) onSurveillanceSourceChanged(newValue int64) {
 	oldValue, _ := mode.levelAdapter.ObjectSurveillanceInfo(mode.selectedSurveillanceIndex)
-	mode.requestSurveillanceChange(func(value int) error {
-		mode.levelAdapter.RequestObjectSurveillance(mode.selectedSurveillanceIndex, &value, nil)
-		return nil
-	}, int(newValue), oldValue)
+	mode.requestSurveillanceChange("source", int(newValue), oldValue)
 }
 
 func (mode *LevelControlMode) onSurveillanceDeathwatchChanged(newValue int64) {
 	_, oldValue := mode.levelAdapter.ObjectSurveillanceInfo(mode.selectedSurveillanceIndex)
-	mode.requestSurveillanceChange(func(value int) error {
-		mode.levelAdapter.RequestObjectSurveillance(mode.selectedSurveillanceIndex, nil, &value)
-		return nil
-	}, int(newValue), oldValue)
+	mode.requestSurveillanceChange("deathwatch", int(newValue), oldValue)
 }
 
-func (mode *LevelControlMode) requestSurveillanceChange(executor func(value int) error, newValue, oldValue int) {
+func (mode *LevelControlMode) requestSurveillanceChange(property string, newValue, oldValue int) {
 	currentSurveillanceIndex := mode.selectedSurveillanceIndex
+	references := surveillanceProperties[property]
 
 	if currentSurveillanceIndex >= 0 {
 		mode.context.Perform(&cmd.SetIntPropertyCommand{
+			Name:   "surveillance",
+			Target: surveillanceTarget(mode.levelAdapter.ID(), currentSurveillanceIndex, property),
 			Setter: func(value int) error {
 				mode.setSurveillanceState(currentSurveillanceIndex)
-				return executor(value)
+				sourceObject, deathwatchObject := references(value)
+				mode.levelAdapter.RequestObjectSurveillance(currentSurveillanceIndex, sourceObject, deathwatchObject)
+				return nil
 			},
 			NewValue: newValue,
 			OldValue: oldValue})
@@ -525,6 +588,8 @@ func (mode *LevelControlMode) onLevelFloorPropertyBoxChanged(boxItem controls.Co
 	oldValue, _ := mode.currentFloorEffect()
 
 	mode.context.Perform(&cmd.SetIntPropertyCommand{
+		Name:   "floor effect",
+		Target: levelPropertyTarget(mode.levelAdapter.ID(), "floorEffect"),
 		Setter: func(value int) error {
 			mode.levelAdapter.RequestLevelPropertiesChange(func(properties *dataModel.LevelProperties) {
 				biohazard := value == floorEffectBiohazard
@@ -544,6 +609,8 @@ func (mode *LevelControlMode) onLevelCeilingPropertyBoxChanged(boxItem controls.
 	oldValue, _ := mode.levelAdapter.CeilingEffect()
 
 	mode.context.Perform(&cmd.SetBooleanPropertyCommand{
+		Name:   "ceiling radiation",
+		Target: levelPropertyTarget(mode.levelAdapter.ID(), "ceilingRadiation"),
 		Setter: func(value bool) error {
 			mode.levelAdapter.RequestLevelPropertiesChange(func(properties *dataModel.LevelProperties) {
 				properties.CeilingHasRadiation = &value
@@ -557,6 +624,8 @@ func (mode *LevelControlMode) onLevelCeilingPropertyBoxChanged(boxItem controls.
 func (mode *LevelControlMode) onCeilingEffectLevelChanged(newValue int64) {
 	_, oldValue := mode.levelAdapter.CeilingEffect()
 	mode.context.Perform(&cmd.SetIntPropertyCommand{
+		Name:   "ceiling effect level",
+		Target: levelPropertyTarget(mode.levelAdapter.ID(), "ceilingEffectLevel"),
 		Setter: func(value int) error {
 			mode.levelAdapter.RequestLevelPropertiesChange(func(properties *dataModel.LevelProperties) {
 				properties.CeilingEffectLevel = &value
@@ -570,6 +639,8 @@ func (mode *LevelControlMode) onCeilingEffectLevelChanged(newValue int64) {
 func (mode *LevelControlMode) onFloorEffectLevelChanged(newValue int64) {
 	_, _, oldValue := mode.levelAdapter.FloorEffect()
 	mode.context.Perform(&cmd.SetIntPropertyCommand{
+		Name:   "floor effect level",
+		Target: levelPropertyTarget(mode.levelAdapter.ID(), "floorEffectLevel"),
 		Setter: func(value int) error {
 			mode.levelAdapter.RequestLevelPropertiesChange(func(properties *dataModel.LevelProperties) {
 				properties.FloorEffectLevel = &value
@@ -604,33 +675,30 @@ func (mode *LevelControlMode) onAnimationGroupIndexChanged(boxItem controls.Comb
 }
 
 func (mode *LevelControlMode) onAnimationGroupTimeChanged(newValue int64) {
-	mode.requestAnimationGroupChange(func(properties *dataModel.TextureAnimation, value *int) {
-		properties.FrameTime = value
-	}, int(newValue), mode.levelAdapter.TextureAnimationGroup(mode.selectedAnimationGroupIndex).FrameTime())
+	mode.requestAnimationGroupChange("frameTime", int(newValue), mode.levelAdapter.TextureAnimationGroup(mode.selectedAnimationGroupIndex).FrameTime())
 }
 
 func (mode *LevelControlMode) onAnimationGroupFramesChanged(newValue int64) {
-	mode.requestAnimationGroupChange(func(properties *dataModel.TextureAnimation, value *int) {
-		properties.FrameCount = value
-	}, int(newValue), mode.levelAdapter.TextureAnimationGroup(mode.selectedAnimationGroupIndex).FrameCount())
+	mode.requestAnimationGroupChange("frameCount", int(newValue), mode.levelAdapter.TextureAnimationGroup(mode.selectedAnimationGroupIndex).FrameCount())
 }
 
 func (mode *LevelControlMode) onAnimationGroupTypeChanged(boxItem controls.ComboBoxItem) {
 	item := boxItem.(*enumItem)
-	mode.requestAnimationGroupChange(func(properties *dataModel.TextureAnimation, value *int) {
-		properties.LoopType = value
-	}, int(item.value), mode.levelAdapter.TextureAnimationGroup(mode.selectedAnimationGroupIndex).LoopType())
+	mode.requestAnimationGroupChange("loopType", int(item.value), mode.levelAdapter.TextureAnimationGroup(mode.selectedAnimationGroupIndex).LoopType())
 }
 
-func (mode *LevelControlMode) requestAnimationGroupChange(modifier func(*dataModel.TextureAnimation, *int), newValue, oldValue int) {
+func (mode *LevelControlMode) requestAnimationGroupChange(property string, newValue, oldValue int) {
 	currentAnimationGroupIndex := mode.selectedAnimationGroupIndex
+	modifier := animationProperties[property]
 
 	if currentAnimationGroupIndex >= 0 {
 		mode.context.Perform(&cmd.SetIntPropertyCommand{
+			Name:   "animation group",
+			Target: animationTarget(mode.levelAdapter.ID(), currentAnimationGroupIndex, property),
 			Setter: func(value int) error {
 				var properties dataModel.TextureAnimation
 				mode.setAnimationGroupState(currentAnimationGroupIndex)
-				modifier(&properties, &value)
+				modifier(&properties, value)
 				mode.levelAdapter.RequestLevelTextureAnimationGroupChange(mode.selectedAnimationGroupIndex, properties)
 				return nil
 			},
@@ -0,0 +1,319 @@
+package modes
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/inkyblackness/shocked-client/editor/cmd"
+	"github.com/inkyblackness/shocked-client/editor/model"
+	dataModel "github.com/inkyblackness/shocked-model"
+)
+
+// levelFileNamePattern matches the names of level files as they are exported.
+var levelFileNamePattern = regexp.MustCompile(`^level_(\d+)\.json$`)
+
+// levelDiffSource is one version of levels to compare. It is either the active level,
+// a single level file, or a folder with level files of several levels.
+type levelDiffSource struct {
+	name   string
+	levels map[int]*model.LevelFile
+	single *model.LevelFile
+}
+
+func activeLevelDiffSource() *levelDiffSource {
+	return &levelDiffSource{name: "Active level"}
+}
+
+// readLevelDiffSource reads the level files of given path. Folders provide all the level files
+// named like exports, files are read either as level file or as map of the Tiled editor.
+func readLevelDiffSource(filePath string) (*levelDiffSource, error) {
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return nil, err
+	}
+	source := &levelDiffSource{name: filepath.Base(filePath)}
+	if fileInfo.IsDir() {
+		source.levels, err = readLevelFolder(filePath)
+	} else {
+		source.single, err = readLevelFileOf(filePath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+func readLevelFolder(dirPath string) (map[int]*model.LevelFile, error) {
+	entries, err := ioutil.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	levels := make(map[int]*model.LevelFile)
+	for _, entry := range entries {
+		match := levelFileNamePattern.FindStringSubmatch(entry.Name())
+		if (match == nil) || entry.IsDir() {
+			continue
+		}
+		levelID, _ := strconv.Atoi(match[1])
+		levels[levelID], err = readLevelFileOf(filepath.Join(dirPath, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("no level files found")
+	}
+	return levels, nil
+}
+
+func readLevelFileOf(filePath string) (*model.LevelFile, error) {
+	read := model.ReadLevelFile
+	if strings.HasSuffix(strings.ToLower(filePath), ".tmx") {
+		read = model.ReadTMXLevelFile
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return read(file)
+}
+
+// levelFile returns the content of identified level, or nil if the source does not have it.
+func (source *levelDiffSource) levelFile(levelAdapter *model.LevelAdapter, levelID int) *model.LevelFile {
+	if source.single != nil {
+		return source.single
+	}
+	if source.levels != nil {
+		return source.levels[levelID]
+	}
+	if levelAdapter.ID() == levelID {
+		return levelAdapter.Export()
+	}
+	return nil
+}
+
+// diffLevelSources compares the levels of two sources. Folders are compared level by level, for all
+// levels both sources have. Otherwise, the active level is the one compared.
+func diffLevelSources(levelAdapter *model.LevelAdapter, base, other *levelDiffSource) []*levelDiff {
+	var levelIDs []int
+	for _, source := range []*levelDiffSource{base, other} {
+		for levelID := range source.levels {
+			levelIDs = append(levelIDs, levelID)
+		}
+	}
+	if (base.levels == nil) && (other.levels == nil) && (levelAdapter.ID() >= 0) {
+		levelIDs = append(levelIDs, levelAdapter.ID())
+	}
+	sort.Ints(levelIDs)
+
+	var diffs []*levelDiff
+	for index, levelID := range levelIDs {
+		if (index > 0) && (levelIDs[index-1] == levelID) {
+			continue
+		}
+		baseFile, otherFile := base.levelFile(levelAdapter, levelID), other.levelFile(levelAdapter, levelID)
+		if (baseFile == nil) || (otherFile == nil) {
+			continue
+		}
+		diff := &levelDiff{levelID: levelID}
+		for _, hunk := range model.DiffLevels(baseFile, otherFile) {
+			diff.hunks = append(diff.hunks, &levelDiffHunkItem{hunk: hunk, selected: true})
+		}
+		if len(diff.hunks) > 0 {
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs
+}
+
+// levelDiff are the differences of one level.
+type levelDiff struct {
+	levelID int
+	hunks   []*levelDiffHunkItem
+}
+
+// String implements the Stringer interface.
+func (diff *levelDiff) String() string {
+	return fmt.Sprintf("Level %d: %d differences", diff.levelID, len(diff.hunks))
+}
+
+func (diff *levelDiff) selectedHunks() (hunks []*model.LevelDiffHunk) {
+	for _, item := range diff.hunks {
+		if item.selected {
+			hunks = append(hunks, item.hunk)
+		}
+	}
+	return
+}
+
+// levelDiffHunkItem is a difference that can be selected to be applied.
+type levelDiffHunkItem struct {
+	hunk     *model.LevelDiffHunk
+	selected bool
+}
+
+// String implements the Stringer interface.
+func (item *levelDiffHunkItem) String() string {
+	mark := "-"
+	if item.selected {
+		mark = "+"
+	}
+	return fmt.Sprintf("%v %v", mark, item.hunk)
+}
+
+// levelDiffApplication collects the commands that change a level to have the other version of differences.
+// The current content of the level provides the values for undoing.
+type levelDiffApplication struct {
+	modelAdapter *model.Adapter
+	levelID      int
+	current      *model.LevelFile
+
+	commands []cmd.Command
+	skipped  int
+}
+
+func newLevelDiffApplication(modelAdapter *model.Adapter) *levelDiffApplication {
+	levelAdapter := modelAdapter.ActiveLevel()
+	return &levelDiffApplication{
+		modelAdapter: modelAdapter,
+		levelID:      levelAdapter.ID(),
+		current:      levelAdapter.Export()}
+}
+
+// level returns the adapter of the changed level, requesting it to be active if necessary.
+func (application *levelDiffApplication) level() *model.LevelAdapter {
+	return requestLevelActive(application.modelAdapter, application.levelID)
+}
+
+// command returns a single command for all given differences. Hunks that can not be applied,
+// such as modifications of objects that do not exist, are skipped.
+// Objects are removed before others are added, so that added objects may take over the index of removed ones.
+func (application *levelDiffApplication) command(hunks []*model.LevelDiffHunk) cmd.Command {
+	currentObjects := make(map[int]model.LevelFileObject)
+	for _, object := range application.current.Objects {
+		currentObjects[object.Index] = object
+	}
+	tiles := cmd.SetTilesCommand{Name: "tiles", Target: levelTilesTarget(application.levelID),
+		Setter: application.tileSetter()}
+	objects := cmd.SetLevelObjectsCommand{Name: "objects", Target: levelObjectsTarget(application.levelID),
+		Setter: application.objectSetter()}
+	var removals, additions []cmd.Command
+
+	for _, hunk := range hunks {
+		current, existing := currentObjects[hunk.ObjectIndex()]
+		existing = existing && (hunk.BaseObject != nil) && (current.Class == hunk.BaseObject.Class)
+		switch {
+		case hunk.Kind == model.LevelPropertyDiff:
+			application.addPropertyCommand(hunk)
+		case hunk.Kind == model.TileDiff:
+			tiles.Changes = append(tiles.Changes, cmd.TileChange{X: hunk.X, Y: hunk.Y,
+				OldProperties: application.current.Tiles[hunk.Y][hunk.X], NewProperties: hunk.OtherTile})
+		case hunk.Kind == model.ObjectAddedDiff:
+			additions = append(additions, application.presenceCommand(*hunk.OtherObject, true))
+		case (hunk.Kind == model.ObjectRemovedDiff) && existing:
+			removals = append(removals, application.presenceCommand(current, false))
+		case (hunk.Kind == model.ObjectModifiedDiff) && existing:
+			objects.Changes = append(objects.Changes, cmd.LevelObjectChange{ObjectIndex: current.Index,
+				OldProperties: current.Properties, NewProperties: hunk.OtherObject.Properties})
+		default:
+			application.skipped++
+		}
+	}
+	if len(tiles.Changes) > 0 {
+		application.commands = append(application.commands, tiles)
+	}
+	application.commands = append(application.commands, removals...)
+	if len(objects.Changes) > 0 {
+		application.commands = append(application.commands, objects)
+	}
+	application.commands = append(application.commands, additions...)
+
+	return cmd.CompoundCommand{
+		Name:     fmt.Sprintf("Apply %d differences to level %d", len(hunks)-application.skipped, application.levelID),
+		Commands: application.commands}
+}
+
+func (application *levelDiffApplication) addPropertyCommand(hunk *model.LevelDiffHunk) {
+	oldValue := model.LevelPropertyValue(application.current.Properties, hunk.Property)
+	switch newValue := hunk.OtherValue.(type) {
+	case int:
+		modifier, known := levelIntProperties[hunk.Property]
+		oldInt, set := oldValue.(int)
+		if !known || !set {
+			application.skipped++
+			return
+		}
+		application.commands = append(application.commands, cmd.SetIntPropertyCommand{
+			Name:   hunk.Property,
+			Target: levelPropertyTarget(application.levelID, hunk.Property),
+			Setter: func(value int) error {
+				application.level().RequestLevelPropertiesChange(func(properties *dataModel.LevelProperties) {
+					modifier(properties, value)
+				})
+				return nil
+			},
+			OldValue: oldInt,
+			NewValue: newValue})
+	case bool:
+		modifier, known := levelBoolProperties[hunk.Property]
+		oldBool, set := oldValue.(bool)
+		if !known || !set {
+			application.skipped++
+			return
+		}
+		application.commands = append(application.commands, cmd.SetBooleanPropertyCommand{
+			Name:   hunk.Property,
+			Target: levelPropertyTarget(application.levelID, hunk.Property),
+			Setter: func(value bool) error {
+				application.level().RequestLevelPropertiesChange(func(properties *dataModel.LevelProperties) {
+					modifier(properties, value)
+				})
+				return nil
+			},
+			OldValue: oldBool,
+			NewValue: newValue})
+	default:
+		application.skipped++
+	}
+}
+
+func (application *levelDiffApplication) tileSetter() func(x, y int, properties dataModel.TileProperties) error {
+	return func(x, y int, properties dataModel.TileProperties) error {
+		coordinates := []model.TileCoordinate{model.TileCoordinateOf(x, y)}
+		application.level().RequestTilePropertyChange(coordinates, &properties)
+		return nil
+	}
+}
+
+func (application *levelDiffApplication) objectSetter() func(objectIndex int, properties dataModel.LevelObjectProperties) error {
+	return func(objectIndex int, properties dataModel.LevelObjectProperties) error {
+		application.level().RequestObjectPropertiesChange([]int{objectIndex}, &properties)
+		return nil
+	}
+}
+
+func (application *levelDiffApplication) presenceCommand(object model.LevelFileObject, add bool) cmd.Command {
+	return cmd.LevelObjectPresenceCommand{
+		Target: levelObjectsTarget(application.levelID),
+		Adder: func(class int, properties dataModel.LevelObjectProperties, onAdded func(objectIndex int), onFailed func()) error {
+			application.level().RequestNewObjectWithProperties(class, properties, func(object *model.LevelObject) {
+				onAdded(object.Index())
+			}, onFailed)
+			return nil
+		},
+		Remover: func(objectIndex int) error {
+			application.level().RequestRemoveObjects([]int{objectIndex})
+			return nil
+		},
+		Class:      object.Class,
+		Properties: object.Properties,
+		Index:      cmd.NewObjectIndex(object.Index),
+		Add:        add}
+}
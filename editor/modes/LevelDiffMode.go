@@ -0,0 +1,325 @@
+package modes
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/inkyblackness/shocked-client/editor/actions"
+	"github.com/inkyblackness/shocked-client/editor/display"
+	"github.com/inkyblackness/shocked-client/editor/model"
+	"github.com/inkyblackness/shocked-client/graphics"
+	"github.com/inkyblackness/shocked-client/graphics/controls"
+	"github.com/inkyblackness/shocked-client/ui"
+	"github.com/inkyblackness/shocked-client/ui/events"
+)
+
+// LevelDiffMode is a mode comparing two versions of levels, which applies selected differences to the active level.
+//
+// The versions are level files, or folders with level files as exported in the level control mode, and are
+// dropped on the panel. A single dropped file or folder is compared against the active level. Two dropped files,
+// or two folders, are compared with each other, with the first in alphabetical order as base.
+// Folders are compared level by level, for all levels both have.
+type LevelDiffMode struct {
+	context      Context
+	levelAdapter *model.LevelAdapter
+
+	mapDisplay *display.MapDisplay
+
+	area *ui.Area
+
+	sourceTitle   *controls.Label
+	sourceInfo    *controls.Label
+	baseTitle     *controls.Label
+	baseInfo      *controls.Label
+	otherTitle    *controls.Label
+	otherInfo     *controls.Label
+	swapLabel     *controls.Label
+	swapButton    *controls.TextButton
+	refreshLabel  *controls.Label
+	refreshButton *controls.TextButton
+	levelLabel    *controls.Label
+	levelBox      *controls.ComboBox
+	hunkLabel     *controls.Label
+	hunkBox       *controls.ComboBox
+	toggleLabel   *controls.Label
+	toggleButton  *controls.TextButton
+	presetLabel   *controls.Label
+	presetBox     *controls.ComboBox
+	countTitle    *controls.Label
+	countInfo     *controls.Label
+	applyLabel    *controls.Label
+	applyButton   *controls.TextButton
+
+	base  *levelDiffSource
+	other *levelDiffSource
+
+	diffs        []*levelDiff
+	selectedDiff *levelDiff
+	selectedHunk *levelDiffHunkItem
+}
+
+// levelDiffPreset selects the differences of certain kinds.
+type levelDiffPreset struct {
+	title string
+	kinds []model.LevelDiffKind
+}
+
+// String implements the Stringer interface.
+func (preset *levelDiffPreset) String() string {
+	return preset.title
+}
+
+var levelDiffPresets = []*levelDiffPreset{
+	{"All", []model.LevelDiffKind{model.LevelPropertyDiff, model.TileDiff,
+		model.ObjectAddedDiff, model.ObjectRemovedDiff, model.ObjectModifiedDiff}},
+	{"None", nil},
+	{"Level Properties", []model.LevelDiffKind{model.LevelPropertyDiff}},
+	{"Tiles", []model.LevelDiffKind{model.TileDiff}},
+	{"Objects", []model.LevelDiffKind{model.ObjectAddedDiff, model.ObjectRemovedDiff, model.ObjectModifiedDiff}}}
+
+// NewLevelDiffMode returns a new instance.
+func NewLevelDiffMode(context Context, parent *ui.Area, mapDisplay *display.MapDisplay) *LevelDiffMode {
+	mode := &LevelDiffMode{
+		context:      context,
+		levelAdapter: context.ModelAdapter().ActiveLevel(),
+		mapDisplay:   mapDisplay}
+
+	scaled := func(value float32) float32 {
+		return value * context.ControlFactory().Scale()
+	}
+
+	{
+		minRight := ui.NewOffsetAnchor(parent.Left(), scaled(100))
+		maxRight := ui.NewRelativeAnchor(parent.Left(), parent.Right(), 0.5)
+		builder := ui.NewAreaBuilder()
+		builder.SetParent(parent)
+		builder.SetLeft(ui.NewOffsetAnchor(parent.Left(), 0))
+		builder.SetTop(ui.NewOffsetAnchor(parent.Top(), 0))
+		builder.SetRight(ui.NewLimitedAnchor(minRight, maxRight, ui.NewOffsetAnchor(parent.Left(), scaled(400))))
+		builder.SetBottom(ui.NewOffsetAnchor(parent.Bottom(), 0))
+		builder.SetVisible(false)
+		builder.OnRender(func(area *ui.Area) {
+			context.ForGraphics().RectangleRenderer().Fill(
+				area.Left().Value(), area.Top().Value(), area.Right().Value(), area.Bottom().Value(),
+				graphics.RGBA(0.7, 0.0, 0.7, 0.3))
+		})
+		builder.OnEvent(events.MouseMoveEventType, ui.SilentConsumer)
+		builder.OnEvent(events.MouseButtonUpEventType, ui.SilentConsumer)
+		builder.OnEvent(events.MouseButtonDownEventType, ui.SilentConsumer)
+		builder.OnEvent(events.MouseButtonClickedEventType, ui.SilentConsumer)
+		builder.OnEvent(events.MouseScrollEventType, ui.SilentConsumer)
+		builder.OnEvent(events.FileDropEventType, mode.onFileDropped)
+		mode.area = builder.Build()
+	}
+	{
+		panelBuilder := newControlPanelBuilder(mode.area, context.ControlFactory())
+
+		mode.sourceTitle, mode.sourceInfo = panelBuilder.addInfo("Compare")
+		mode.sourceInfo.SetText("Drop level files or folders")
+		mode.baseTitle, mode.baseInfo = panelBuilder.addInfo("Base")
+		mode.otherTitle, mode.otherInfo = panelBuilder.addInfo("Other")
+		mode.swapLabel, mode.swapButton = panelBuilder.addTextButton("Direction", "Swap", mode.swap)
+		mode.refreshLabel, mode.refreshButton = panelBuilder.addTextButton("Compare Again", "Refresh", mode.compare)
+		mode.levelLabel, mode.levelBox = panelBuilder.addComboProperty("Level", mode.onLevelDiffChanged)
+		mode.hunkLabel, mode.hunkBox = panelBuilder.addComboProperty("Difference", mode.onHunkChanged)
+		mode.toggleLabel, mode.toggleButton = panelBuilder.addTextButton("Apply Difference", "Toggle", mode.toggleSelectedHunk)
+		mode.presetLabel, mode.presetBox = panelBuilder.addComboProperty("Select", mode.onPresetChanged)
+		presetItems := make([]controls.ComboBoxItem, len(levelDiffPresets))
+		for index, preset := range levelDiffPresets {
+			presetItems[index] = preset
+		}
+		mode.presetBox.SetItems(presetItems)
+		mode.countTitle, mode.countInfo = panelBuilder.addInfo("Selected")
+		mode.applyLabel, mode.applyButton = panelBuilder.addTextButton("Apply to Level", "Apply", mode.apply)
+	}
+
+	mode.setSources(activeLevelDiffSource(), activeLevelDiffSource())
+	mode.levelAdapter.OnIDChanged(mode.updateMarks)
+
+	mode.context.Actions().Register(actions.Action{
+		Name:      "levelDiff.apply",
+		Title:     "Apply selected level differences",
+		Handler:   mode.apply,
+		Available: mode.area.IsVisible})
+
+	return mode
+}
+
+// SetActive implements the Mode interface.
+func (mode *LevelDiffMode) SetActive(active bool) {
+	mode.area.SetVisible(active)
+	mode.mapDisplay.SetVisible(active)
+	if active {
+		mode.compare()
+	} else {
+		mode.setDiffs(nil)
+	}
+}
+
+func (mode *LevelDiffMode) onFileDropped(area *ui.Area, event events.Event) (consumed bool) {
+	dropEvent := event.(*events.FileDropEvent)
+	filePaths := append([]string{}, dropEvent.FilePaths()...)
+	sort.Strings(filePaths)
+	sources := make([]*levelDiffSource, len(filePaths))
+
+	if (len(filePaths) < 1) || (len(filePaths) > 2) {
+		return
+	}
+	for index, filePath := range filePaths {
+		source, err := readLevelDiffSource(filePath)
+		if err != nil {
+			mode.context.ModelAdapter().SetMessage(fmt.Sprintf("Could not read level file %s: %v", filePath, err))
+			return true
+		}
+		sources[index] = source
+	}
+	if len(sources) == 1 {
+		sources = append([]*levelDiffSource{activeLevelDiffSource()}, sources...)
+	}
+	mode.setSources(sources[0], sources[1])
+	mode.compare()
+
+	return true
+}
+
+func (mode *LevelDiffMode) setSources(base, other *levelDiffSource) {
+	mode.base, mode.other = base, other
+	mode.baseInfo.SetText(base.name)
+	mode.otherInfo.SetText(other.name)
+}
+
+func (mode *LevelDiffMode) swap() {
+	mode.setSources(mode.other, mode.base)
+	mode.compare()
+}
+
+// compare determines the differences between the current sources again.
+func (mode *LevelDiffMode) compare() {
+	mode.setDiffs(diffLevelSources(mode.levelAdapter, mode.base, mode.other))
+}
+
+func (mode *LevelDiffMode) setDiffs(diffs []*levelDiff) {
+	mode.diffs = diffs
+	items := make([]controls.ComboBoxItem, len(diffs))
+	var selected *levelDiff
+	for index, diff := range diffs {
+		items[index] = diff
+		if (selected == nil) || (diff.levelID == mode.levelAdapter.ID()) {
+			selected = diff
+		}
+	}
+	mode.levelBox.SetItems(items)
+	mode.selectDiff(selected)
+}
+
+func (mode *LevelDiffMode) onLevelDiffChanged(item controls.ComboBoxItem) {
+	diff := item.(*levelDiff)
+	mode.selectDiff(diff)
+	if diff.levelID != mode.levelAdapter.ID() {
+		mode.context.ModelAdapter().RequestActiveLevel(diff.levelID)
+	}
+}
+
+func (mode *LevelDiffMode) selectDiff(diff *levelDiff) {
+	mode.selectedDiff = diff
+	mode.levelBox.SetSelectedItem(nil)
+	var items []controls.ComboBoxItem
+	if diff != nil {
+		mode.levelBox.SetSelectedItem(diff)
+		for _, hunk := range diff.hunks {
+			items = append(items, hunk)
+		}
+	}
+	mode.hunkBox.SetItems(items)
+	mode.selectHunk(nil)
+	mode.updateSelection()
+}
+
+func (mode *LevelDiffMode) onHunkChanged(item controls.ComboBoxItem) {
+	mode.selectHunk(item.(*levelDiffHunkItem))
+}
+
+// selectHunk shows the given difference. The map is moved to differences of tiles and objects.
+func (mode *LevelDiffMode) selectHunk(item *levelDiffHunkItem) {
+	mode.selectedHunk = item
+	mode.hunkBox.SetSelectedItem(nil)
+	mode.mapDisplay.ClearHighlightedTile()
+	if item != nil {
+		mode.hunkBox.SetSelectedItem(item)
+		if item.hunk.Kind != model.LevelPropertyDiff {
+			mode.mapDisplay.CenterOn(item.hunk.Center())
+			mode.mapDisplay.SetHighlightedTile(model.TileCoordinateOf(item.hunk.X, item.hunk.Y))
+		}
+	}
+}
+
+func (mode *LevelDiffMode) toggleSelectedHunk() {
+	if mode.selectedHunk != nil {
+		mode.selectedHunk.selected = !mode.selectedHunk.selected
+		mode.selectHunk(mode.selectedHunk)
+		mode.updateSelection()
+	}
+}
+
+func (mode *LevelDiffMode) onPresetChanged(item controls.ComboBoxItem) {
+	preset := item.(*levelDiffPreset)
+	if mode.selectedDiff != nil {
+		for _, hunkItem := range mode.selectedDiff.hunks {
+			hunkItem.selected = false
+			for _, kind := range preset.kinds {
+				hunkItem.selected = hunkItem.selected || (hunkItem.hunk.Kind == kind)
+			}
+		}
+	}
+	mode.presetBox.SetSelectedItem(nil)
+	mode.selectHunk(mode.selectedHunk)
+	mode.updateSelection()
+}
+
+func (mode *LevelDiffMode) updateSelection() {
+	selected, count := 0, 0
+	if mode.selectedDiff != nil {
+		selected, count = len(mode.selectedDiff.selectedHunks()), len(mode.selectedDiff.hunks)
+	}
+	mode.countInfo.SetText(fmt.Sprintf("%d of %d", selected, count))
+	mode.updateMarks()
+}
+
+// updateMarks shows the selected differences on the map, if their level is the active one.
+func (mode *LevelDiffMode) updateMarks() {
+	var marks []display.DiffMark
+	if (mode.selectedDiff != nil) && (mode.selectedDiff.levelID == mode.levelAdapter.ID()) {
+		for _, hunk := range mode.selectedDiff.selectedHunks() {
+			x, y := hunk.Center()
+			switch hunk.Kind {
+			case model.TileDiff:
+				marks = append(marks, display.DiffMark{Kind: display.DiffChanged, X: x, Y: y, Tile: true})
+			case model.ObjectAddedDiff:
+				marks = append(marks, display.DiffMark{Kind: display.DiffAdded, X: x, Y: y})
+			case model.ObjectRemovedDiff:
+				marks = append(marks, display.DiffMark{Kind: display.DiffRemoved, X: x, Y: y})
+			case model.ObjectModifiedDiff:
+				marks = append(marks, display.DiffMark{Kind: display.DiffChanged, X: x, Y: y})
+			}
+		}
+	}
+	mode.mapDisplay.SetDiffMarks(marks)
+}
+
+// apply changes the active level to have the other version of all selected differences, as one command.
+func (mode *LevelDiffMode) apply() {
+	adapter := mode.context.ModelAdapter()
+	if mode.selectedDiff == nil {
+		return
+	}
+	if mode.selectedDiff.levelID != mode.levelAdapter.ID() {
+		adapter.SetMessage(fmt.Sprintf("Level %d must be the active level to apply its differences", mode.selectedDiff.levelID))
+		return
+	}
+	application := newLevelDiffApplication(adapter)
+	command := application.command(mode.selectedDiff.selectedHunks())
+	mode.context.Perform(command)
+	if application.skipped > 0 {
+		adapter.SetMessage(fmt.Sprintf("Skipped %d differences that do not match the active level", application.skipped))
+	}
+}
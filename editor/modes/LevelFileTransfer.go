@@ -0,0 +1,144 @@
+package modes
+
+import (
+	"fmt"
+	"image/png"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/inkyblackness/shocked-client/editor/model"
+	"github.com/inkyblackness/shocked-client/ui"
+	"github.com/inkyblackness/shocked-client/ui/events"
+)
+
+func (mode *LevelControlMode) onFileDropped(area *ui.Area, event events.Event) (consumed bool) {
+	dropEvent := event.(*events.FileDropEvent)
+
+	if len(dropEvent.FilePaths()) == 1 {
+		filePath := dropEvent.FilePaths()[0]
+		fileInfo, err := os.Stat(filePath)
+
+		if (err == nil) && fileInfo.IsDir() {
+			mode.exportLevelFile(filePath)
+		} else if (err == nil) && strings.HasSuffix(strings.ToLower(filePath), ".json") {
+			mode.importLevelFile(filePath, model.ReadLevelFile)
+		} else if (err == nil) && strings.HasSuffix(strings.ToLower(filePath), ".tmx") {
+			mode.importLevelFile(filePath, model.ReadTMXLevelFile)
+		} else {
+			mode.context.ModelAdapter().SetMessage(fmt.Sprintf("File is not found/recognized %s", filePath))
+		}
+		consumed = true
+	}
+
+	return
+}
+
+// exportLevelFile writes the active level as level file into the given directory, together with
+// its layout as map for the Tiled editor and the tileset image of that map.
+func (mode *LevelControlMode) exportLevelFile(dirPath string) {
+	if mode.levelAdapter.ID() < 0 {
+		mode.context.ModelAdapter().SetMessage("No level to export")
+		return
+	}
+	levelFile := mode.levelAdapter.Export()
+	baseName := path.Join(dirPath, fmt.Sprintf("level_%02d", mode.levelAdapter.ID()))
+	levelFileName := baseName + ".json"
+	writers := []struct {
+		fileName string
+		write    func(io.Writer) error
+	}{
+		{levelFileName, levelFile.Write},
+		{baseName + ".tmx", levelFile.WriteTMX},
+		{path.Join(dirPath, model.LevelLayoutTilesetImage), func(writer io.Writer) error {
+			return png.Encode(writer, model.TileTypesImage())
+		}}}
+
+	for _, entry := range writers {
+		file, err := os.Create(entry.fileName)
+		if err != nil {
+			mode.context.ModelAdapter().SetMessage("Could not create file for export.")
+			return
+		}
+		err = entry.write(file)
+		file.Close()
+		if err != nil {
+			mode.context.ModelAdapter().SetMessage("Could not write export.")
+			return
+		}
+	}
+	mode.context.ModelAdapter().SetMessage(fmt.Sprintf("Exported %s", levelFileName))
+}
+
+// importLevelFile replaces the content of the active level with the level file read from given path.
+// The import is performed as one command, which restores the previous content when undone.
+func (mode *LevelControlMode) importLevelFile(filePath string, read func(io.Reader) (*model.LevelFile, error)) {
+	if mode.levelAdapter.ID() < 0 {
+		mode.context.ModelAdapter().SetMessage("No level to import into")
+		return
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		mode.context.ModelAdapter().SetMessage(fmt.Sprintf("File is not found/recognized %s", filePath))
+		return
+	}
+	defer file.Close()
+	levelFile, err := read(file)
+	if err != nil {
+		mode.context.ModelAdapter().SetMessage(fmt.Sprintf("Could not read level file %s: %v", filePath, err))
+		return
+	}
+
+	mode.context.Perform(levelImportCommand{
+		modelAdapter: mode.context.ModelAdapter(),
+		levelID:      mode.levelAdapter.ID(),
+		source:       path.Base(filePath),
+		before:       mode.levelAdapter.Export(),
+		after:        levelFile})
+}
+
+// levelImportCommand replaces the content of a level with a level file, named by its source, such as the
+// name of the imported file. When undone, the content
+// the level had before is imported again. The objects then have their previous indices, as long as
+// the store assigns the lowest free index to added objects. The outcome of either direction,
+// including objects that received another index and partial imports, is reported as message.
+type levelImportCommand struct {
+	modelAdapter *model.Adapter
+	levelID      int
+	source       string
+	before       *model.LevelFile
+	after        *model.LevelFile
+}
+
+// Do imports the level file.
+func (command levelImportCommand) Do() error {
+	command.modelAdapter.RequestLevelImport(command.levelID, command.after,
+		command.reporter(fmt.Sprintf("Imported %s", command.source)))
+	return nil
+}
+
+// Undo imports the previous content of the level.
+func (command levelImportCommand) Undo() error {
+	command.modelAdapter.RequestLevelImport(command.levelID, command.before,
+		command.reporter(fmt.Sprintf("Restored level %d", command.levelID)))
+	return nil
+}
+
+func (command levelImportCommand) reporter(done string) func(movedObjects int, err error) {
+	return func(movedObjects int, err error) {
+		adapter := command.modelAdapter
+		if err != nil {
+			adapter.SetMessage(fmt.Sprintf("%s with failures: %v", done, err))
+		} else if movedObjects > 0 {
+			adapter.SetMessage(fmt.Sprintf("%s, %d objects received a new index", done, movedObjects))
+		} else {
+			adapter.SetMessage(done)
+		}
+	}
+}
+
+// Description returns a text of the change.
+func (command levelImportCommand) Description() string {
+	return fmt.Sprintf("Import %v into level %d", command.source, command.levelID)
+}
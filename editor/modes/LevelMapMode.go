@@ -2,7 +2,11 @@ package modes
 
 import (
 	"fmt"
+	"math"
+	"reflect"
 
+	"github.com/inkyblackness/shocked-client/editor/actions"
+	"github.com/inkyblackness/shocked-client/editor/cmd"
 	"github.com/inkyblackness/shocked-client/editor/display"
 	"github.com/inkyblackness/shocked-client/editor/model"
 	"github.com/inkyblackness/shocked-client/env"
@@ -55,6 +59,55 @@ type LevelMapMode struct {
 	panelRight ui.Anchor
 
 	selectedTiles []model.TileCoordinate
+	pastedTiles   []copiedTile
+	pasteAnchor   model.TileCoordinate
+
+	brushTool          tileBrushTool
+	brush              tileBrush
+	brushStroking      bool
+	brushStrokeStart   model.TileCoordinate
+	brushStrokeTiles   []model.TileCoordinate
+	brushStrokeVisited map[model.TileCoordinate]bool
+
+	brushToolLabel           *controls.Label
+	brushToolBox             *controls.ComboBox
+	brushToolItems           []controls.ComboBoxItem
+	brushTypeLabel           *controls.Label
+	brushTypeBox             *controls.ComboBox
+	brushTypeItems           map[dataModel.TileType]controls.ComboBoxItem
+	brushFloorHeightLabel    *controls.Label
+	brushFloorHeightSlider   *controls.Slider
+	brushCeilingHeightLabel  *controls.Label
+	brushCeilingHeightSlider *controls.Slider
+	brushTexturesTitle       *controls.Label
+	brushTexturesInfo        *controls.Label
+
+	ramp           tileRamp
+	rampPreviewing bool
+
+	rampDirectionLabel *controls.Label
+	rampDirectionBox   *controls.ComboBox
+	rampDirectionItems []controls.ComboBoxItem
+	rampStartLabel     *controls.Label
+	rampStartSlider    *controls.Slider
+	rampEndLabel       *controls.Label
+	rampEndSlider      *controls.Slider
+	rampInfoTitle      *controls.Label
+	rampInfoValue      *controls.Label
+	rampBuildLabel     *controls.Label
+	rampBuildButton    *controls.TextButton
+
+	exportSize    int
+	exportObjects bool
+	exportDirPath string
+
+	exportTitleLabel   *controls.Label
+	exportSizeLabel    *controls.Label
+	exportSizeBox      *controls.ComboBox
+	exportObjectsLabel *controls.Label
+	exportObjectsBox   *controls.ComboBox
+	exportInfoTitle    *controls.Label
+	exportInfoValue    *controls.Label
 
 	coloringLabel *controls.Label
 	coloringBox   *controls.ComboBox
@@ -137,7 +190,12 @@ func NewLevelMapMode(context Context, parent *ui.Area, mapDisplay *display.MapDi
 	mode := &LevelMapMode{
 		context:      context,
 		levelAdapter: context.ModelAdapter().ActiveLevel(),
-		mapDisplay:   mapDisplay}
+		mapDisplay:   mapDisplay,
+		brush:        defaultTileBrush(),
+
+		exportSize:    defaultMapExportSize,
+		exportObjects: true,
+		exportDirPath: "."}
 
 	scaled := func(value float32) float32 {
 		return value * context.ControlFactory().Scale()
@@ -152,7 +210,13 @@ func NewLevelMapMode(context Context, parent *ui.Area, mapDisplay *display.MapDi
 		builder.SetBottom(ui.NewOffsetAnchor(parent.Bottom(), 0))
 		builder.SetVisible(false)
 		builder.OnEvent(events.MouseMoveEventType, mode.onMouseMoved)
+		builder.OnEvent(events.MouseButtonDownEventType, mode.onMouseButtonDown)
+		builder.OnEvent(events.MouseButtonUpEventType, mode.onMouseButtonUp)
 		builder.OnEvent(events.MouseButtonClickedEventType, mode.onMouseButtonClicked)
+		builder.OnEvent(events.FocusLostEventType, mode.onFocusLost)
+		builder.OnEvent(events.ClipboardCopyEventType, mode.onClipboardCopy)
+		builder.OnEvent(events.ClipboardPasteEventType, mode.onClipboardPaste)
+		builder.OnEvent(events.FileDropEventType, mode.onFileDropped)
 		mode.area = builder.Build()
 	}
 	{
@@ -224,6 +288,63 @@ func NewLevelMapMode(context Context, parent *ui.Area, mapDisplay *display.MapDi
 			mode.coloringBox.SetItems(items)
 			mode.coloringBox.SetSelectedItem(items[0])
 		}
+		{
+			mode.brushToolLabel, mode.brushToolBox = panelBuilder.addComboProperty("Brush Tool", func(item controls.ComboBoxItem) {
+				mode.setBrushTool(tileBrushTool(item.(*enumItem).value))
+			})
+			mode.brushToolItems = make([]controls.ComboBoxItem, len(tileBrushToolNames))
+			for index, name := range tileBrushToolNames {
+				mode.brushToolItems[index] = &enumItem{uint32(index), name}
+			}
+			mode.brushToolBox.SetItems(mode.brushToolItems)
+			mode.brushToolBox.SetSelectedItem(mode.brushToolItems[noBrushTool])
+
+			tileTypes := dataModel.TileTypes()
+			mode.brushTypeLabel, mode.brushTypeBox = panelBuilder.addComboProperty("Brush Tile Type", func(item controls.ComboBoxItem) {
+				mode.brush.tileType = tileTypes[item.(*enumItem).value]
+			})
+			brushTypeItems := make([]controls.ComboBoxItem, len(tileTypes))
+			mode.brushTypeItems = make(map[dataModel.TileType]controls.ComboBoxItem)
+			for index, tileType := range tileTypes {
+				item := &enumItem{uint32(index), string(tileType)}
+				brushTypeItems[index] = item
+				mode.brushTypeItems[tileType] = item
+			}
+			mode.brushTypeBox.SetItems(brushTypeItems)
+
+			mode.brushFloorHeightLabel, mode.brushFloorHeightSlider = panelBuilder.addSliderProperty("Brush Floor Height", func(newValue int64) {
+				mode.brush.floorHeight = dataModel.HeightUnit(newValue)
+			})
+			mode.brushFloorHeightSlider.SetRange(0, 31)
+			mode.brushCeilingHeightLabel, mode.brushCeilingHeightSlider = panelBuilder.addSliderProperty("Brush Ceiling Height (abs)", func(newValue int64) {
+				mode.brush.ceilingHeight = dataModel.HeightUnit(32 - newValue)
+			})
+			mode.brushCeilingHeightSlider.SetRange(1, 32)
+			mode.brushTexturesTitle, mode.brushTexturesInfo = panelBuilder.addInfo("Brush Textures")
+			mode.onBrushChanged()
+		}
+		{
+			mode.rampDirectionLabel, mode.rampDirectionBox = panelBuilder.addComboProperty("Ramp Direction", func(item controls.ComboBoxItem) {
+				mode.changeRamp(func(ramp *tileRamp) { ramp.direction = rampDirection(item.(*enumItem).value) })
+			})
+			mode.rampDirectionItems = make([]controls.ComboBoxItem, len(rampDirectionNames))
+			for index, name := range rampDirectionNames {
+				mode.rampDirectionItems[index] = &enumItem{uint32(index), name}
+			}
+			mode.rampDirectionBox.SetItems(mode.rampDirectionItems)
+
+			mode.rampStartLabel, mode.rampStartSlider = panelBuilder.addSliderProperty("Ramp Start Height", func(newValue int64) {
+				mode.changeRamp(func(ramp *tileRamp) { ramp.startHeight = int(newValue) })
+			})
+			mode.rampStartSlider.SetRange(0, 31)
+			mode.rampEndLabel, mode.rampEndSlider = panelBuilder.addSliderProperty("Ramp End Height", func(newValue int64) {
+				mode.changeRamp(func(ramp *tileRamp) { ramp.endHeight = int(newValue) })
+			})
+			mode.rampEndSlider.SetRange(0, 31)
+			mode.rampInfoTitle, mode.rampInfoValue = panelBuilder.addInfo("Ramp Steepness")
+			mode.rampBuildLabel, mode.rampBuildButton = panelBuilder.addTextButton("Ramp", "Build", mode.buildRamp)
+			mode.onRampChanged()
+		}
 
 		mode.tileTypeLabel, mode.tileTypeBox = panelBuilder.addComboProperty("Tile Type", mode.onTilePropertyChangeRequested)
 		{
@@ -504,20 +625,80 @@ func NewLevelMapMode(context Context, parent *ui.Area, mapDisplay *display.MapDi
 				mode.gameOfLifeSetBox.SetItems(boxItems)
 			}
 		}
+		{
+			mode.exportTitleLabel = panelBuilder.addTitle("Export")
+			mode.exportSizeLabel, mode.exportSizeBox = panelBuilder.addComboProperty("Image Size", func(item controls.ComboBoxItem) {
+				mode.exportSize = int(item.(*enumItem).value)
+			})
+			sizeItems := make([]controls.ComboBoxItem, len(mapExportSizes))
+			var selectedSizeItem controls.ComboBoxItem
+			for index, size := range mapExportSizes {
+				sizeItems[index] = &enumItem{uint32(size), fmt.Sprintf("%d x %d", size, size)}
+				if size == mode.exportSize {
+					selectedSizeItem = sizeItems[index]
+				}
+			}
+			mode.exportSizeBox.SetItems(sizeItems)
+			mode.exportSizeBox.SetSelectedItem(selectedSizeItem)
+			mode.exportObjectsLabel, mode.exportObjectsBox = panelBuilder.addComboProperty("Objects", func(item controls.ComboBoxItem) {
+				mode.exportObjects = item.(*enumItem).value != 0
+			})
+			objectsItems := []controls.ComboBoxItem{&enumItem{0, "None"}, &enumItem{1, "All"}}
+			mode.exportObjectsBox.SetItems(objectsItems)
+			mode.exportObjectsBox.SetSelectedItem(objectsItems[1])
+			mode.exportInfoTitle, mode.exportInfoValue = panelBuilder.addInfo("Export To")
+			mode.exportInfoValue.SetText("Drop a folder on the map, or export with the action")
+		}
 		mode.levelAdapter.OnLevelPropertiesChanged(func() {
 			mode.realWorldArea.SetVisible(!mode.levelAdapter.IsCyberspace())
 			mode.cyberspaceArea.SetVisible(mode.levelAdapter.IsCyberspace())
 
 			mode.floorHeightSlider.SetValueFormatter(mode.heightUnitToString)
 			mode.ceilingHeightAbsSlider.SetValueFormatter(mode.heightUnitToString)
+			mode.brushFloorHeightSlider.SetValueFormatter(mode.heightUnitToString)
+			mode.brushCeilingHeightSlider.SetValueFormatter(mode.heightUnitToString)
+			mode.rampStartSlider.SetValueFormatter(mode.heightUnitToString)
+			mode.rampEndSlider.SetValueFormatter(mode.heightUnitToString)
 			mode.slopeHeightSlider.SetValueFormatter(mode.heightUnitToString)
 			mode.wallTextureOffsetSlider.SetValueFormatter(mode.heightUnitToString)
+			mode.floorHeightSlider.SetValueParser(mode.heightUnitFromString)
+			mode.ceilingHeightAbsSlider.SetValueParser(mode.heightUnitFromString)
+			mode.brushFloorHeightSlider.SetValueParser(mode.heightUnitFromString)
+			mode.brushCeilingHeightSlider.SetValueParser(mode.heightUnitFromString)
+			mode.rampStartSlider.SetValueParser(mode.heightUnitFromString)
+			mode.rampEndSlider.SetValueParser(mode.heightUnitFromString)
+			mode.onRampChanged()
+			mode.slopeHeightSlider.SetValueParser(mode.heightUnitFromString)
+			mode.wallTextureOffsetSlider.SetValueParser(mode.heightUnitFromString)
 		})
 	}
 
+	mode.registerAction("levelMap.cancelPaste", "Cancel pasting tiles", mode.isPasting, mode.cancelPaste)
+	always := func() bool { return true }
+	mode.registerAction("levelMap.brushNone", "Stop painting tiles", always, func() { mode.setBrushTool(noBrushTool) })
+	mode.registerAction("levelMap.brushPencil", "Paint tiles with pencil", always, func() { mode.setBrushTool(pencilBrushTool) })
+	mode.registerAction("levelMap.brushRectangle", "Paint tiles in rectangle", always, func() { mode.setBrushTool(rectangleBrushTool) })
+	mode.registerAction("levelMap.brushLine", "Paint tiles along line", always, func() { mode.setBrushTool(lineBrushTool) })
+	mode.registerAction("levelMap.brushFloodFill", "Paint connected tiles", always, func() { mode.setBrushTool(floodFillBrushTool) })
+	hasSelection := func() bool { return len(mode.selectedTiles) > 0 }
+	mode.registerAction("levelMap.previewRamp", "Preview ramp on selected tiles", hasSelection, mode.previewRamp)
+	mode.registerAction("levelMap.buildRamp", "Build ramp on selected tiles", hasSelection, mode.buildRamp)
+	mode.registerAction("levelMap.hideRamp", "Hide ramp preview", func() bool { return mode.rampPreviewing }, mode.hideRamp)
+	mode.registerAction("levelMap.exportMap", "Export map into last export folder", always, func() { mode.exportMap(mode.exportDirPath) })
+
 	return mode
 }
 
+func (mode *LevelMapMode) registerAction(name, title string, available func() bool, handler actions.Handler) {
+	mode.context.Actions().Register(actions.Action{
+		Name:    name,
+		Title:   title,
+		Handler: handler,
+		Available: func() bool {
+			return mode.area.IsVisible() && available()
+		}})
+}
+
 func (mode *LevelMapMode) cyberspaceColorOfSingleTile(properties *dataModel.TileProperties,
 	resolver func(*dataModel.TileProperties) int) graphics.Color {
 	palette := mode.context.ModelAdapter().GamePalette()
@@ -557,12 +738,21 @@ func (mode *LevelMapMode) heightUnitToString(value int64) string {
 	return heightToString(mode.levelAdapter.HeightShift(), value, 32.0)
 }
 
+func (mode *LevelMapMode) heightUnitFromString(text string) (int64, error) {
+	return heightParser(mode.levelAdapter.HeightShift(), 32.0)(text)
+}
+
 // SetActive implements the Mode interface.
 func (mode *LevelMapMode) SetActive(active bool) {
 	if active {
 		mode.mapDisplay.SetSelectedTiles(mode.selectedTiles)
+		mode.mapDisplay.SetSelectionBoxHandler(mode.onSelectionBox)
 		mode.onTileColoringChanged(mode.coloringItem)
 	} else {
+		mode.cancelPaste()
+		mode.cancelBrushStroke()
+		mode.hideRamp()
+		mode.mapDisplay.SetSelectionBoxHandler(nil)
 		mode.mapDisplay.ClearHighlightedTile()
 		mode.mapDisplay.SetSelectedTiles(nil)
 		mode.mapDisplay.SetTileColoring(nil)
@@ -600,13 +790,22 @@ func (mode *LevelMapMode) floorCeilingTextures() []*graphics.BitmapTexture {
 func (mode *LevelMapMode) onMouseMoved(area *ui.Area, event events.Event) (consumed bool) {
 	mouseEvent := event.(*events.MouseMoveEvent)
 
-	if mouseEvent.Buttons() == 0 {
+	if mode.brushStroking {
+		coord, _ := mode.tileAtPixel(mouseEvent.Position())
+		mode.mapDisplay.SetHighlightedTile(coord)
+		mode.extendBrushStroke(coord)
+		consumed = true
+	} else if mouseEvent.Buttons() == 0 {
 		worldX, worldY := mode.mapDisplay.WorldCoordinatesForPixel(mouseEvent.Position())
-		coord := model.TileCoordinateOf(int(worldX)>>8, int(worldY)>>8)
+		coord := model.TileCoordinateOf(int(math.Floor(float64(worldX)))>>8, int(math.Floor(float64(worldY)))>>8)
 		tileX, tileY := coord.XY()
 
 		if tileX >= 0 && tileX < 64 && tileY >= 0 && tileY < 64 {
 			mode.mapDisplay.SetHighlightedTile(coord)
+			if mode.isPasting() {
+				mode.pasteAnchor = coord
+				mode.updatePastePreview()
+			}
 		} else {
 			mode.mapDisplay.ClearHighlightedTile()
 		}
@@ -621,26 +820,337 @@ func (mode *LevelMapMode) onMouseButtonClicked(area *ui.Area, event events.Event
 
 	if mouseEvent.AffectedButtons() == env.MousePrimary {
 		worldX, worldY := mode.mapDisplay.WorldCoordinatesForPixel(mouseEvent.Position())
-		coord := model.TileCoordinateOf(int(worldX)>>8, int(worldY)>>8)
+		coord := model.TileCoordinateOf(int(math.Floor(float64(worldX)))>>8, int(math.Floor(float64(worldY)))>>8)
 		tileX, tileY := coord.XY()
 
 		if tileX >= 0 && tileX < 64 && tileY >= 0 && tileY < 64 {
-			if keys.Modifier(mouseEvent.Modifier()) == keys.ModControl {
+			if mode.isPasting() {
+				mode.pasteTilesAt(coord)
+			} else if keys.Modifier(mouseEvent.Modifier()) == keys.ModAlt {
+				mode.pickBrush(coord)
+			} else if keys.Modifier(mouseEvent.Modifier()) == keys.ModControl {
 				mode.toggleSelectedTile(coord)
 			} else if (keys.Modifier(mouseEvent.Modifier()) == keys.ModShift) && (len(mode.selectedTiles) > 0) {
 				firstTile := mode.selectedTiles[0]
 				massSelectStartX, massSelectStartY := firstTile.XY()
 				mode.massSelectTiles(model.TileCoordinateOf(massSelectStartX, massSelectStartY), coord)
-			} else {
+			} else if mode.brushTool == noBrushTool {
 				mode.setSelectedTiles([]model.TileCoordinate{coord})
 			}
 			consumed = true
 		}
+	} else if (mouseEvent.AffectedButtons() == env.MouseSecondary) && mode.isPasting() {
+		mode.cancelPaste()
+		consumed = true
+	}
+
+	return
+}
+
+// onMouseButtonDown starts a brush stroke if a brush tool is chosen and no modifier is held.
+func (mode *LevelMapMode) onMouseButtonDown(area *ui.Area, event events.Event) (consumed bool) {
+	mouseEvent := event.(*events.MouseButtonEvent)
+
+	if (mode.brushTool != noBrushTool) && !mode.isPasting() &&
+		(mouseEvent.Buttons() == env.MousePrimary) && (keys.Modifier(mouseEvent.Modifier()) == keys.ModNone) {
+		if coord, onMap := mode.tileAtPixel(mouseEvent.Position()); onMap {
+			mode.brushStroking = true
+			mode.brushStrokeStart = coord
+			mode.brushStrokeTiles = nil
+			mode.brushStrokeVisited = make(map[model.TileCoordinate]bool)
+			mode.extendBrushStroke(coord)
+			mode.area.RequestFocus()
+			consumed = true
+		}
+	}
+
+	return
+}
+
+func (mode *LevelMapMode) onMouseButtonUp(area *ui.Area, event events.Event) (consumed bool) {
+	mouseEvent := event.(*events.MouseButtonEvent)
+
+	if mode.brushStroking && (mouseEvent.AffectedButtons() == env.MousePrimary) {
+		mode.finishBrushStroke()
+		consumed = true
 	}
 
 	return
 }
 
+func (mode *LevelMapMode) onFocusLost(area *ui.Area, event events.Event) bool {
+	mode.cancelBrushStroke()
+	return true
+}
+
+// tileAtPixel returns the coordinate of the tile at given pixel, limited to the map.
+// The returned flag is false if the pixel is outside of the map.
+func (mode *LevelMapMode) tileAtPixel(pixelX, pixelY float32) (coord model.TileCoordinate, onMap bool) {
+	limit := func(value int) int {
+		if value < 0 {
+			return 0
+		} else if value > 63 {
+			return 63
+		}
+		return value
+	}
+	worldX, worldY := mode.mapDisplay.WorldCoordinatesForPixel(pixelX, pixelY)
+	tileX, tileY := int(math.Floor(float64(worldX)))>>8, int(math.Floor(float64(worldY)))>>8
+
+	return model.TileCoordinateOf(limit(tileX), limit(tileY)), isTileOnMap(tileX, tileY)
+}
+
+func (mode *LevelMapMode) setBrushTool(tool tileBrushTool) {
+	mode.cancelBrushStroke()
+	mode.brushTool = tool
+	mode.brushToolBox.SetSelectedItem(mode.brushToolItems[tool])
+}
+
+// pickBrush takes the tile type, heights and textures of the given tile for the brush.
+func (mode *LevelMapMode) pickBrush(coord model.TileCoordinate) {
+	if properties := mode.levelAdapter.TileMap().Tile(coord).Properties(); properties != nil {
+		mode.brush = mode.brush.pick(properties)
+		mode.onBrushChanged()
+	}
+}
+
+func (mode *LevelMapMode) onBrushChanged() {
+	mode.brushTypeBox.SetSelectedItem(mode.brushTypeItems[mode.brush.tileType])
+	mode.brushFloorHeightSlider.SetValue(int64(mode.brush.floorHeight))
+	mode.brushCeilingHeightSlider.SetValue(int64(32 - mode.brush.ceilingHeight))
+	if mode.brush.hasTextures {
+		mode.brushTexturesInfo.SetText(fmt.Sprintf("F%v C%v W%v",
+			mode.brush.floorTexture, mode.brush.ceilingTexture, mode.brush.wallTexture))
+	} else {
+		mode.brushTexturesInfo.SetText("Alt-click tile to pick")
+	}
+}
+
+// extendBrushStroke updates the tiles of the current stroke for the given cursor position.
+// The pencil collects all tiles it passes, the other tools depend on the start of the stroke.
+func (mode *LevelMapMode) extendBrushStroke(coord model.TileCoordinate) {
+	switch mode.brushTool {
+	case pencilBrushTool:
+		from := coord
+		if len(mode.brushStrokeTiles) > 0 {
+			from = mode.brushStrokeTiles[len(mode.brushStrokeTiles)-1]
+		}
+		for _, passed := range tileLine(from, coord) {
+			if !mode.brushStrokeVisited[passed] {
+				mode.brushStrokeVisited[passed] = true
+				mode.brushStrokeTiles = append(mode.brushStrokeTiles, passed)
+			}
+		}
+	case rectangleBrushTool:
+		mode.brushStrokeTiles = tileRectangle(mode.brushStrokeStart, coord)
+	case lineBrushTool:
+		mode.brushStrokeTiles = tileLine(mode.brushStrokeStart, coord)
+	case floodFillBrushTool:
+		if len(mode.brushStrokeTiles) == 0 {
+			mode.brushStrokeTiles = floodFillTiles(mode.levelAdapter.TileMap(), mode.brushStrokeStart, mode.levelAdapter.IsCyberspace())
+		}
+	}
+	mode.mapDisplay.SetPreviewTiles(mode.brushStrokeTiles)
+}
+
+func (mode *LevelMapMode) stopBrushStroke() {
+	mode.brushStroking = false
+	mode.brushStrokeTiles = nil
+	mode.brushStrokeVisited = nil
+	mode.mapDisplay.SetPreviewTiles(nil)
+	if mode.area.HasFocus() {
+		mode.area.ReleaseFocus()
+	}
+}
+
+func (mode *LevelMapMode) cancelBrushStroke() {
+	if mode.brushStroking {
+		mode.stopBrushStroke()
+	}
+}
+
+// finishBrushStroke applies the brush to all tiles of the stroke as one command.
+// Tiles the brush does not change are not part of the command.
+func (mode *LevelMapMode) finishBrushStroke() {
+	tileMap := mode.levelAdapter.TileMap()
+	cyberspace := mode.levelAdapter.IsCyberspace()
+	command := cmd.SetTilesCommand{
+		Target: levelTilesTarget(mode.levelAdapter.ID()),
+		Setter: mode.tileSetter(mode.levelAdapter.ID())}
+
+	for _, coord := range mode.brushStrokeTiles {
+		if properties := tileMap.Tile(coord).Properties(); properties != nil {
+			oldProperties := pastableTileProperties(*properties, cyberspace)
+			newProperties := mode.brush.applyTo(oldProperties)
+			if !reflect.DeepEqual(oldProperties, newProperties) {
+				x, y := coord.XY()
+				command.Changes = append(command.Changes, cmd.TileChange{
+					X:             x,
+					Y:             y,
+					OldProperties: oldProperties,
+					NewProperties: newProperties})
+			}
+		}
+	}
+	mode.stopBrushStroke()
+	if len(command.Changes) > 0 {
+		mode.context.Perform(cmd.Described(command, fmt.Sprintf("Paint %d tiles", len(command.Changes))))
+	}
+}
+
+func (mode *LevelMapMode) tilePropertiesAt(x, y int) *dataModel.TileProperties {
+	if !isTileOnMap(x, y) {
+		return nil
+	}
+	return mode.levelAdapter.TileMap().Tile(model.TileCoordinateOf(x, y)).Properties()
+}
+
+// changeRamp modifies the ramp and shows its preview.
+func (mode *LevelMapMode) changeRamp(modifier func(ramp *tileRamp)) {
+	modifier(&mode.ramp)
+	mode.rampPreviewing = true
+	mode.onRampChanged()
+}
+
+// onRampChanged updates the ramp controls, and the preview if it is shown.
+func (mode *LevelMapMode) onRampChanged() {
+	mode.rampDirectionBox.SetSelectedItem(mode.rampDirectionItems[mode.ramp.direction])
+	mode.rampStartSlider.SetValue(int64(mode.ramp.startHeight))
+	mode.rampEndSlider.SetValue(int64(mode.ramp.endHeight))
+
+	if len(mode.selectedTiles) > 0 {
+		_, length := mode.ramp.positions(mode.selectedTiles)
+		heightShift := mode.levelAdapter.HeightShift()
+		if mode.ramp.isPossible(length, heightShift) {
+			mode.rampInfoValue.SetText(fmt.Sprintf("%d tiles, %d per tile", length, mode.ramp.steepness(length)))
+		} else {
+			mode.rampInfoValue.SetText(fmt.Sprintf("Too steep: %d per tile", mode.ramp.steepness(length)))
+		}
+	} else {
+		mode.rampInfoValue.SetText("")
+	}
+	if mode.rampPreviewing {
+		mode.previewRamp()
+	}
+}
+
+// previewRamp shows the slopes the ramp would create on the selected tiles.
+func (mode *LevelMapMode) previewRamp() {
+	preview := make(map[model.TileCoordinate]*dataModel.TileProperties)
+	for _, change := range mode.ramp.changes(mode.selectedTiles, mode.tilePropertiesAt, mode.levelAdapter.IsCyberspace()) {
+		properties := change.NewProperties
+		preview[model.TileCoordinateOf(change.X, change.Y)] = &properties
+	}
+	mode.rampPreviewing = len(preview) > 0
+	mode.mapDisplay.SetSlopePreview(preview)
+}
+
+func (mode *LevelMapMode) hideRamp() {
+	mode.rampPreviewing = false
+	mode.mapDisplay.SetSlopePreview(nil)
+}
+
+// buildRamp changes the selected tiles to form the ramp, unless the ramp is too steep for the level.
+func (mode *LevelMapMode) buildRamp() {
+	if len(mode.selectedTiles) == 0 {
+		return
+	}
+	if _, length := mode.ramp.positions(mode.selectedTiles); !mode.ramp.isPossible(length, mode.levelAdapter.HeightShift()) {
+		return
+	}
+	command := cmd.SetTilesCommand{
+		Target:  levelTilesTarget(mode.levelAdapter.ID()),
+		Setter:  mode.tileSetter(mode.levelAdapter.ID()),
+		Changes: mode.ramp.changes(mode.selectedTiles, mode.tilePropertiesAt, mode.levelAdapter.IsCyberspace())}
+	mode.hideRamp()
+	if len(command.Changes) > 0 {
+		mode.context.Perform(cmd.Described(command, fmt.Sprintf("Build ramp on %d tiles", len(command.Changes))))
+	}
+}
+
+func (mode *LevelMapMode) onClipboardCopy(area *ui.Area, event events.Event) bool {
+	clipboardEvent := event.(*events.ClipboardEvent)
+	tiles := copyTiles(mode.levelAdapter.TileMap(), mode.selectedTiles)
+
+	if len(tiles) > 0 {
+		clipboardEvent.Clipboard().SetText(encodeTileClipboard(tiles))
+	}
+	return len(tiles) > 0
+}
+
+// onClipboardPaste starts pasting the tiles from the clipboard. The tiles are previewed
+// at the position of the mouse until a click places them, or pasting is cancelled.
+func (mode *LevelMapMode) onClipboardPaste(area *ui.Area, event events.Event) bool {
+	clipboardEvent := event.(*events.ClipboardEvent)
+	tiles, err := decodeTileClipboard(clipboardEvent.Clipboard().Text())
+
+	if err != nil {
+		return false
+	}
+	worldX, worldY := mode.mapDisplay.WorldCoordinatesForPixel(clipboardEvent.Position())
+	mode.pastedTiles = tiles
+	mode.pasteAnchor = model.TileCoordinateOf(int(math.Floor(float64(worldX)))>>8, int(math.Floor(float64(worldY)))>>8)
+	mode.updatePastePreview()
+	mode.context.ModelAdapter().SetMessage(fmt.Sprintf("Click to paste %d tiles, right-click to cancel.", len(tiles)))
+	return true
+}
+
+func (mode *LevelMapMode) isPasting() bool {
+	return mode.pastedTiles != nil
+}
+
+func (mode *LevelMapMode) cancelPaste() {
+	if mode.isPasting() {
+		mode.pastedTiles = nil
+		mode.mapDisplay.SetPreviewTiles(nil)
+	}
+}
+
+func (mode *LevelMapMode) updatePastePreview() {
+	_, coordinates := placedTiles(mode.pastedTiles, mode.pasteAnchor)
+	mode.mapDisplay.SetPreviewTiles(coordinates)
+}
+
+// pasteTilesAt places the pasted tiles relative to given anchor as one command.
+// The pasted tiles become the new selection.
+func (mode *LevelMapMode) pasteTilesAt(anchor model.TileCoordinate) {
+	tiles, coordinates := placedTiles(mode.pastedTiles, anchor)
+	tileMap := mode.levelAdapter.TileMap()
+	cyberspace := mode.levelAdapter.IsCyberspace()
+	command := cmd.SetTilesCommand{
+		Target: levelTilesTarget(mode.levelAdapter.ID()),
+		Setter: mode.tileSetter(mode.levelAdapter.ID())}
+	pastedCoordinates := []model.TileCoordinate{}
+
+	for index, coord := range coordinates {
+		oldProperties := tileMap.Tile(coord).Properties()
+		if oldProperties != nil {
+			x, y := coord.XY()
+			command.Changes = append(command.Changes, cmd.TileChange{
+				X:             x,
+				Y:             y,
+				OldProperties: pastableTileProperties(*oldProperties, cyberspace),
+				NewProperties: pastableTileProperties(tiles[index].Properties, cyberspace)})
+			pastedCoordinates = append(pastedCoordinates, coord)
+		}
+	}
+	mode.cancelPaste()
+	if len(command.Changes) > 0 {
+		mode.context.Perform(cmd.Described(command, fmt.Sprintf("Paste %d tiles", len(command.Changes))))
+		mode.setSelectedTiles(pastedCoordinates)
+	}
+}
+
+// tileSetter returns a function to change tiles of the given level.
+// Should another level be active, the level is requested to become active again.
+func (mode *LevelMapMode) tileSetter(levelID int) func(x, y int, properties dataModel.TileProperties) error {
+	return func(x, y int, properties dataModel.TileProperties) error {
+		coordinates := []model.TileCoordinate{model.TileCoordinateOf(x, y)}
+		requestLevelActive(mode.context.ModelAdapter(), levelID).RequestTilePropertyChange(coordinates, &properties)
+		return nil
+	}
+}
+
 func (mode *LevelMapMode) massSelectTiles(fromCoord model.TileCoordinate, toCoord model.TileCoordinate) {
 	sel := func(a, b int, selectA bool) (result int) {
 		result = b
@@ -665,6 +1175,65 @@ func (mode *LevelMapMode) massSelectTiles(fromCoord model.TileCoordinate, toCoor
 	mode.setSelectedTiles(newSelection)
 }
 
+// onSelectionBox selects all tiles touched by the box. With Shift, the tiles are added to the
+// current selection; with Control, they are removed from it.
+func (mode *LevelMapMode) onSelectionBox(fromX, fromY, toX, toY float32, modifier keys.Modifier) {
+	mode.setSelectedTiles(boxSelectedTiles(mode.selectedTiles, fromX, fromY, toX, toY, modifier))
+}
+
+// boxSelectedTiles returns the given selection after a selection box with given world coordinates
+// and modifier was drawn.
+func boxSelectedTiles(selected []model.TileCoordinate, fromX, fromY, toX, toY float32,
+	modifier keys.Modifier) []model.TileCoordinate {
+	tileRange := func(from, to float32) (first, last int) {
+		first, last = int(math.Floor(float64(from)))>>8, int(math.Floor(float64(to)))>>8
+		if first > last {
+			first, last = last, first
+		}
+		if first < 0 {
+			first = 0
+		}
+		if last > 63 {
+			last = 63
+		}
+		return
+	}
+	firstX, lastX := tileRange(fromX, toX)
+	firstY, lastY := tileRange(fromY, toY)
+	inBox := func(coord model.TileCoordinate) bool {
+		x, y := coord.XY()
+		return (x >= firstX) && (x <= lastX) && (y >= firstY) && (y <= lastY)
+	}
+	isSelected := func(coord model.TileCoordinate) bool {
+		for _, other := range selected {
+			if other == coord {
+				return true
+			}
+		}
+		return false
+	}
+	newSelection := []model.TileCoordinate{}
+
+	if (modifier == keys.ModShift) || (modifier == keys.ModControl) {
+		for _, coord := range selected {
+			if (modifier == keys.ModShift) || !inBox(coord) {
+				newSelection = append(newSelection, coord)
+			}
+		}
+	}
+	if modifier != keys.ModControl {
+		for y := firstY; y <= lastY; y++ {
+			for x := firstX; x <= lastX; x++ {
+				coord := model.TileCoordinateOf(x, y)
+				if (modifier != keys.ModShift) || !isSelected(coord) {
+					newSelection = append(newSelection, coord)
+				}
+			}
+		}
+	}
+	return newSelection
+}
+
 func (mode *LevelMapMode) setSelectedTiles(tiles []model.TileCoordinate) {
 	mode.selectedTiles = tiles
 	mode.onSelectedTilesChanged()
@@ -691,6 +1260,8 @@ func (mode *LevelMapMode) toggleSelectedTile(coord model.TileCoordinate) {
 
 func (mode *LevelMapMode) onSelectedTilesChanged() {
 	mode.mapDisplay.SetSelectedTiles(mode.selectedTiles)
+	mode.ramp = autoTileRamp(mode.selectedTiles, mode.tilePropertiesAt)
+	mode.onRampChanged()
 	tileMap := mode.levelAdapter.TileMap()
 	typeUnifier := util.NewValueUnifier(dataModel.TileType(""))
 	floorHeightUnifier := util.NewValueUnifier(-1)
@@ -0,0 +1,43 @@
+package modes
+
+import (
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/editor/model"
+	"github.com/inkyblackness/shocked-client/env/keys"
+)
+
+type LevelMapModeSuite struct{}
+
+var _ = check.Suite(&LevelMapModeSuite{})
+
+func tileCoordinates(xy ...int) (coordinates []model.TileCoordinate) {
+	for index := 0; index < len(xy); index += 2 {
+		coordinates = append(coordinates, model.TileCoordinateOf(xy[index], xy[index+1]))
+	}
+	return
+}
+
+func (suite *LevelMapModeSuite) TestBoxSelectsAllTouchedTiles(c *check.C) {
+	selection := boxSelectedTiles(tileCoordinates(1, 1), 0x3F0, 0x580, 0x210, 0x4FF, keys.ModNone)
+
+	c.Check(selection, check.DeepEquals, tileCoordinates(2, 4, 3, 4, 2, 5, 3, 5))
+}
+
+func (suite *LevelMapModeSuite) TestBoxIsLimitedToMap(c *check.C) {
+	selection := boxSelectedTiles(nil, -0x100, 0x3FF0, 0x80, 0x4100, keys.ModNone)
+
+	c.Check(selection, check.DeepEquals, tileCoordinates(0, 63))
+}
+
+func (suite *LevelMapModeSuite) TestBoxWithShiftAddsTilesToSelection(c *check.C) {
+	selection := boxSelectedTiles(tileCoordinates(1, 1, 3, 4), 0x200, 0x400, 0x3FF, 0x400, keys.ModShift)
+
+	c.Check(selection, check.DeepEquals, tileCoordinates(1, 1, 3, 4, 2, 4))
+}
+
+func (suite *LevelMapModeSuite) TestBoxWithControlRemovesTilesFromSelection(c *check.C) {
+	selection := boxSelectedTiles(tileCoordinates(1, 1, 3, 4, 2, 4), 0x200, 0x400, 0x3FF, 0x400, keys.ModControl)
+
+	c.Check(selection, check.DeepEquals, tileCoordinates(1, 1))
+}
@@ -15,6 +15,8 @@ import (
 
 	dataModel "github.com/inkyblackness/shocked-model"
 
+	"github.com/inkyblackness/shocked-client/editor/actions"
+	"github.com/inkyblackness/shocked-client/editor/cmd"
 	"github.com/inkyblackness/shocked-client/editor/display"
 	"github.com/inkyblackness/shocked-client/editor/model"
 	"github.com/inkyblackness/shocked-client/env"
@@ -37,6 +39,19 @@ type LevelObjectsMode struct {
 	displayFilter    func(*model.LevelObject) bool
 	displayedObjects []*model.LevelObject
 
+	searchQuery       objectQuery
+	searchMatchesOnly bool
+	searchMatches     []*model.LevelObject
+	searchMatchIndex  int
+
+	referenceCache     *objectReferenceCache
+	references         []*objectReference
+	referencesShown    int
+	shownReferences    []*objectReference
+	hoveredReference   *objectReference
+	outgoingReferences []*objectReference
+	incomingReferences []*objectReference
+
 	mapDisplay *display.MapDisplay
 
 	area *ui.Area
@@ -59,6 +74,11 @@ type LevelObjectsMode struct {
 	closestObjectHighlightIndex int
 	selectedObjects             []*model.LevelObject
 
+	movingObjects          []*model.LevelObject
+	moveStartX, moveStartY float32
+	moveDeltaX, moveDeltaY float32
+	moveToGrid             bool
+
 	newObjectID model.ObjectID
 
 	newObjectClassLabel *controls.Label
@@ -69,13 +89,37 @@ type LevelObjectsMode struct {
 	highlightedObjectInfoTitle *controls.Label
 	highlightedObjectInfoValue *controls.Label
 
-	selectedObjectsTitleLabel   *controls.Label
-	selectedObjectsDeleteLabel  *controls.Label
-	selectedObjectsDeleteButton *controls.TextButton
-	selectedObjectsIDTitleLabel *controls.Label
-	selectedObjectsIDInfoLabel  *controls.Label
-	selectedObjectsTypeLabel    *controls.Label
-	selectedObjectsTypeBox      *controls.ComboBox
+	searchTitleLabel   *controls.Label
+	searchQueryTitle   *controls.Label
+	searchQueryValue   *controls.Label
+	searchMatchesTitle *controls.Label
+	searchMatchesInfo  *controls.Label
+	searchDisplayLabel *controls.Label
+	searchDisplayBox   *controls.ComboBox
+	searchDisplayItems enumItems
+	searchNextLabel    *controls.Label
+	searchNextButton   *controls.TextButton
+	searchSelectLabel  *controls.Label
+	searchSelectButton *controls.TextButton
+
+	referencesTitleLabel   *controls.Label
+	referencesDisplayLabel *controls.Label
+	referencesDisplayBox   *controls.ComboBox
+	referencesDisplayItems enumItems
+	hoveredReferenceTitle  *controls.Label
+	hoveredReferenceInfo   *controls.Label
+
+	selectedObjectsTitleLabel    *controls.Label
+	selectedObjectsDeleteLabel   *controls.Label
+	selectedObjectsDeleteButton  *controls.TextButton
+	selectedObjectsIDTitleLabel  *controls.Label
+	selectedObjectsIDInfoLabel   *controls.Label
+	selectedObjectsOutgoingLabel *controls.Label
+	selectedObjectsOutgoingBox   *controls.ComboBox
+	selectedObjectsIncomingLabel *controls.Label
+	selectedObjectsIncomingBox   *controls.ComboBox
+	selectedObjectsTypeLabel     *controls.Label
+	selectedObjectsTypeBox       *controls.ComboBox
 
 	selectedObjectsPropertiesTitle *controls.Label
 	selectedObjectsPropertiesBox   *controls.ComboBox
@@ -122,6 +166,10 @@ func NewLevelObjectsMode(context Context, parent *ui.Area, mapDisplay *display.M
 		objectsAdapter: context.ModelAdapter().ObjectsAdapter(),
 		displayFilter:  func(*model.LevelObject) bool { return true },
 
+		searchMatchIndex: -1,
+
+		referenceCache: newObjectReferenceCache(),
+
 		newObjectID: model.MakeObjectID(0, 0, 0),
 
 		mapDisplay: mapDisplay}
@@ -140,7 +188,12 @@ func NewLevelObjectsMode(context Context, parent *ui.Area, mapDisplay *display.M
 		builder.SetVisible(false)
 		builder.OnEvent(events.MouseMoveEventType, mode.onMouseMoved)
 		builder.OnEvent(events.MouseScrollEventType, mode.onMouseScrolled)
+		builder.OnEvent(events.MouseButtonDownEventType, mode.onMouseButtonDown)
+		builder.OnEvent(events.MouseButtonUpEventType, mode.onMouseButtonUp)
 		builder.OnEvent(events.MouseButtonClickedEventType, mode.onMouseButtonClicked)
+		builder.OnEvent(events.FocusLostEventType, mode.onFocusLost)
+		builder.OnEvent(events.ClipboardCopyEventType, mode.onClipboardCopy)
+		builder.OnEvent(events.ClipboardPasteEventType, mode.onClipboardPaste)
 		mode.area = builder.Build()
 	}
 	{
@@ -201,9 +254,32 @@ func NewLevelObjectsMode(context Context, parent *ui.Area, mapDisplay *display.M
 
 		mode.highlightedObjectInfoTitle, mode.highlightedObjectInfoValue = panelBuilder.addInfo("Highlighted Object")
 
+		mode.searchTitleLabel = panelBuilder.addTitle("Search")
+		mode.searchQueryTitle, mode.searchQueryValue = panelBuilder.addInfo("Query")
+		mode.searchQueryValue.AllowTextChange(mode.onSearchQueryChangeRequested)
+		mode.searchMatchesTitle, mode.searchMatchesInfo = panelBuilder.addInfo("Matches")
+		mode.searchDisplayLabel, mode.searchDisplayBox = panelBuilder.addComboProperty("Show Objects", mode.onSearchDisplayChanged)
+		mode.searchDisplayItems = []*enumItem{{0, "All"}, {1, "Matches Only"}}
+		mode.searchDisplayBox.SetItems(mode.searchDisplayItems.forComboBox())
+		mode.searchDisplayBox.SetSelectedItem(mode.searchDisplayItems[0])
+		mode.searchNextLabel, mode.searchNextButton = panelBuilder.addTextButton("Step Through Matches", "Next", func() {
+			mode.selectSearchMatchBy(1)
+		})
+		mode.searchSelectLabel, mode.searchSelectButton = panelBuilder.addTextButton("Select Matches", "Select All", mode.selectSearchMatches)
+
+		mode.referencesTitleLabel = panelBuilder.addTitle("References")
+		mode.referencesDisplayLabel, mode.referencesDisplayBox = panelBuilder.addComboProperty("Show References", mode.onReferencesDisplayChanged)
+		mode.referencesDisplayItems = []*enumItem{{0, "None"}, {1, "Of Selected Objects"}, {2, "All"}}
+		mode.referencesDisplayBox.SetItems(mode.referencesDisplayItems.forComboBox())
+		mode.referencesDisplayBox.SetSelectedItem(mode.referencesDisplayItems[1])
+		mode.referencesShown = 1
+		mode.hoveredReferenceTitle, mode.hoveredReferenceInfo = panelBuilder.addInfo("Hovered Reference")
+
 		mode.selectedObjectsTitleLabel = panelBuilder.addTitle("Selected Object(s)")
 		mode.selectedObjectsDeleteLabel, mode.selectedObjectsDeleteButton = panelBuilder.addTextButton("Delete Selected", "Delete", mode.deleteSelectedObjects)
 		mode.selectedObjectsIDTitleLabel, mode.selectedObjectsIDInfoLabel = panelBuilder.addInfo("Object ID")
+		mode.selectedObjectsOutgoingLabel, mode.selectedObjectsOutgoingBox = panelBuilder.addComboProperty("Refers To", mode.onReferenceChosen)
+		mode.selectedObjectsIncomingLabel, mode.selectedObjectsIncomingBox = panelBuilder.addComboProperty("Referred By", mode.onReferenceChosen)
 		mode.selectedObjectsTypeLabel, mode.selectedObjectsTypeBox = panelBuilder.addComboProperty("Type", func(item controls.ComboBoxItem) {
 			typeItem := item.(*objectTypeItem)
 			mode.updateSelectedObjectsBaseProperties(func(properties *dataModel.LevelObjectProperties) {
@@ -261,6 +337,7 @@ func NewLevelObjectsMode(context Context, parent *ui.Area, mapDisplay *display.M
 		})
 		mode.selectedObjectsRotationXValue.SetRange(0, 255)
 		mode.selectedObjectsRotationXValue.SetValueFormatter(mode.rotationToString)
+		mode.selectedObjectsRotationXValue.SetValueParser(mode.rotationFromString)
 		mode.selectedObjectsRotationYTitle, mode.selectedObjectsRotationYValue = basePropertiesPanelBuilder.addSliderProperty("RotationY", func(newValue int64) {
 			mode.updateSelectedObjectsBaseProperties(func(properties *dataModel.LevelObjectProperties) {
 				properties.RotationY = intAsPointer(int(newValue))
@@ -268,6 +345,7 @@ func NewLevelObjectsMode(context Context, parent *ui.Area, mapDisplay *display.M
 		})
 		mode.selectedObjectsRotationYValue.SetRange(0, 255)
 		mode.selectedObjectsRotationYValue.SetValueFormatter(mode.rotationToString)
+		mode.selectedObjectsRotationYValue.SetValueParser(mode.rotationFromString)
 		mode.selectedObjectsRotationZTitle, mode.selectedObjectsRotationZValue = basePropertiesPanelBuilder.addSliderProperty("RotationZ", func(newValue int64) {
 			mode.updateSelectedObjectsBaseProperties(func(properties *dataModel.LevelObjectProperties) {
 				properties.RotationZ = intAsPointer(int(newValue))
@@ -275,6 +353,7 @@ func NewLevelObjectsMode(context Context, parent *ui.Area, mapDisplay *display.M
 		})
 		mode.selectedObjectsRotationZValue.SetRange(0, 255)
 		mode.selectedObjectsRotationZValue.SetValueFormatter(mode.rotationToString)
+		mode.selectedObjectsRotationZValue.SetValueParser(mode.rotationFromString)
 
 		mode.selectedObjectsHitpointsTitle, mode.selectedObjectsHitpointsValue = basePropertiesPanelBuilder.addSliderProperty("Hitpoints", func(newValue int64) {
 			mode.updateSelectedObjectsBaseProperties(func(properties *dataModel.LevelObjectProperties) {
@@ -410,17 +489,44 @@ func NewLevelObjectsMode(context Context, parent *ui.Area, mapDisplay *display.M
 	})
 	mode.levelAdapter.OnLevelPropertiesChanged(func() {
 		mode.selectedObjectsZValue.SetValueFormatter(mode.objectZToString)
+		mode.selectedObjectsZValue.SetValueParser(mode.objectZFromString)
+		mode.referenceCache.reset()
+		mode.updateReferences()
 	})
 	mode.levelAdapter.OnLevelObjectsChanged(mode.onLevelObjectsChanged)
+	mode.levelAdapter.OnLevelSurveillanceChanged(mode.updateReferences)
 	mode.context.ModelAdapter().ObjectsAdapter().OnObjectsChanged(mode.onGameObjectsChanged)
 
+	mode.registerAction("levelObjects.deleteSelected", "Delete selected objects", mode.deleteSelectedObjects)
+	mode.registerAction("levelObjects.duplicateSelected", "Duplicate selected objects", mode.duplicateSelectedObjects)
+	mode.registerAction("levelObjects.highlightNext", "Highlight next object near cursor",
+		func() { mode.cycleHighlightedObject(1) })
+	mode.registerAction("levelObjects.highlightPrevious", "Highlight previous object near cursor",
+		func() { mode.cycleHighlightedObject(-1) })
+	mode.registerAction("levelObjects.search", "Search objects", mode.searchQueryValue.Edit)
+	mode.registerAction("levelObjects.nextMatch", "Select next object matching search", func() { mode.selectSearchMatchBy(1) })
+	mode.registerAction("levelObjects.previousMatch", "Select previous object matching search", func() { mode.selectSearchMatchBy(-1) })
+	mode.registerAction("levelObjects.selectMatches", "Select all objects matching search", mode.selectSearchMatches)
+
 	return mode
 }
 
+func (mode *LevelObjectsMode) registerAction(name, title string, handler actions.Handler) {
+	mode.context.Actions().Register(actions.Action{
+		Name:      name,
+		Title:     title,
+		Handler:   handler,
+		Available: mode.area.IsVisible})
+}
+
 func (mode *LevelObjectsMode) objectZToString(value int64) string {
 	return heightToString(mode.levelAdapter.HeightShift(), value, 256.0)
 }
 
+func (mode *LevelObjectsMode) objectZFromString(text string) (int64, error) {
+	return heightParser(mode.levelAdapter.HeightShift(), 256.0)(text)
+}
+
 func (mode *LevelObjectsMode) objectZToShortString(value int64) string {
 	return fmt.Sprintf("%.3f", heightToValue(mode.levelAdapter.HeightShift(), value, 256.0))
 }
@@ -429,25 +535,225 @@ func (mode *LevelObjectsMode) rotationToString(value int64) string {
 	return fmt.Sprintf("%.3f degrees  - raw: %v", (float64(value)*360.0)/256.0, value)
 }
 
+func (mode *LevelObjectsMode) rotationFromString(text string) (int64, error) {
+	return unitValueParser(func(value float64) float64 {
+		return (value * 256.0) / 360.0
+	}, "degrees", "degree", "deg", "\u00B0")(text)
+}
+
 // SetActive implements the Mode interface.
 func (mode *LevelObjectsMode) SetActive(active bool) {
 	if active {
 		mode.updateDisplayedObjects()
 		mode.mapDisplay.SetSelectedObjects(mode.selectedObjects)
+		mode.mapDisplay.SetMarkedObjects(mode.searchMatches)
+		mode.mapDisplay.SetSelectionBoxHandler(mode.onSelectionBox)
 	} else {
+		mode.cancelObjectMove()
+		mode.mapDisplay.SetSelectionBoxHandler(nil)
 		mode.mapDisplay.SetDisplayedObjects(nil)
+		mode.mapDisplay.SetMarkedObjects(nil)
+		mode.mapDisplay.SetReferenceArrows(nil)
 		mode.mapDisplay.SetHighlightedObject(nil)
 		mode.mapDisplay.SetSelectedObjects(nil)
 	}
 	mode.area.SetVisible(active)
 	mode.mapDisplay.SetVisible(active)
+	mode.updateReferences()
 }
 
 func (mode *LevelObjectsMode) onLevelObjectsChanged() {
 	mode.updateNewObjectClassQuota()
+	mode.updateSearchMatches()
 	if mode.area.IsVisible() {
 		mode.updateDisplayedObjects()
 	}
+	mode.updateReferences()
+}
+
+// updateReferences collects the references between the objects of the level and updates their display.
+// Only the references of changed objects are collected again, and only while the mode is active.
+// The cache is reset with the level properties, as they decide which interpreters are used.
+func (mode *LevelObjectsMode) updateReferences() {
+	mode.references = nil
+	if mode.area.IsVisible() {
+		mode.references = mode.referenceCache.update(mode.levelAdapter,
+			mode.classInterpreterFactory(), mode.extraInterpreterFactory())
+	}
+	mode.updateReferenceArrows()
+	mode.updateSelectedObjectsReferences()
+}
+
+func (mode *LevelObjectsMode) onReferencesDisplayChanged(item controls.ComboBoxItem) {
+	mode.referencesShown = int(item.(*enumItem).value)
+	mode.updateReferenceArrows()
+}
+
+// updateReferenceArrows shows the references between objects on the map, either all of them,
+// or only those from and to the selected objects.
+func (mode *LevelObjectsMode) updateReferenceArrows() {
+	selected := make(map[int]bool)
+	for _, object := range mode.selectedObjects {
+		selected[object.Index()] = true
+	}
+
+	mode.shownReferences = nil
+	for _, ref := range mode.references {
+		isShown := (mode.referencesShown == 2) ||
+			((mode.referencesShown == 1) && ref.isBetweenObjects() && (selected[ref.from.Index()] || selected[ref.to.Index()]))
+		if isShown && ref.isBetweenObjects() {
+			mode.shownReferences = append(mode.shownReferences, ref)
+		}
+	}
+	mode.setHoveredReference(nil)
+	if mode.area.IsVisible() {
+		arrows := make([]display.Arrow, len(mode.shownReferences))
+		for index, ref := range mode.shownReferences {
+			arrows[index] = ref
+		}
+		mode.mapDisplay.SetReferenceArrows(arrows)
+	}
+}
+
+// updateHoveredReference finds the shown reference closest to the given position, if near enough.
+func (mode *LevelObjectsMode) updateHoveredReference(worldX, worldY float32) {
+	var closest *objectReference
+	closestDistance := float32(32.0)
+	point := mgl.Vec2{worldX, worldY}
+
+	for _, ref := range mode.shownReferences {
+		fromX, fromY, toX, toY := ref.Ends()
+		from, to := mgl.Vec2{fromX, fromY}, mgl.Vec2{toX, toY}
+		line := to.Sub(from)
+		ratio := float32(0.0)
+		if lengthSquared := line.Dot(line); lengthSquared > 0 {
+			ratio = float32(math.Max(0.0, math.Min(1.0, float64(point.Sub(from).Dot(line)/lengthSquared))))
+		}
+		if distance := point.Sub(from.Add(line.Mul(ratio))).Len(); distance <= closestDistance {
+			closest = ref
+			closestDistance = distance
+		}
+	}
+	mode.setHoveredReference(closest)
+}
+
+func (mode *LevelObjectsMode) setHoveredReference(ref *objectReference) {
+	mode.hoveredReference = ref
+	if ref != nil {
+		mode.hoveredReferenceInfo.SetText(fmt.Sprintf("%v: %v", ref.from.Index(), ref.outgoingString()))
+		mode.mapDisplay.SetHighlightedReferenceArrow(ref)
+	} else {
+		mode.hoveredReferenceInfo.SetText("")
+		mode.mapDisplay.SetHighlightedReferenceArrow(nil)
+	}
+}
+
+// updateSelectedObjectsReferences lists the references from and to the selected objects.
+func (mode *LevelObjectsMode) updateSelectedObjectsReferences() {
+	mode.outgoingReferences, mode.incomingReferences = referencesOfObjects(mode.references, mode.selectedObjects)
+	var outgoingItems, incomingItems []controls.ComboBoxItem
+	for _, ref := range mode.outgoingReferences {
+		outgoingItems = append(outgoingItems, &objectReferenceItem{ref, fmt.Sprintf("%v: %v", ref.from.Index(), ref.outgoingString())})
+	}
+	for _, ref := range mode.incomingReferences {
+		incomingItems = append(incomingItems, &objectReferenceItem{ref, fmt.Sprintf("%v: %v", ref.toIndex, ref.incomingString())})
+	}
+	mode.selectedObjectsOutgoingLabel.SetText(fmt.Sprintf("Refers To (%d)", len(outgoingItems)))
+	mode.selectedObjectsOutgoingBox.SetItems(outgoingItems)
+	mode.selectedObjectsOutgoingBox.SetSelectedItem(nil)
+	mode.selectedObjectsIncomingLabel.SetText(fmt.Sprintf("Referred By (%d)", len(incomingItems)))
+	mode.selectedObjectsIncomingBox.SetItems(incomingItems)
+	mode.selectedObjectsIncomingBox.SetSelectedItem(nil)
+}
+
+// onReferenceChosen moves the map to the other end of the chosen reference, seen from the selection.
+func (mode *LevelObjectsMode) onReferenceChosen(item controls.ComboBoxItem) {
+	ref := item.(*objectReferenceItem).reference
+	selected := make(map[int]bool)
+	for _, object := range mode.selectedObjects {
+		selected[object.Index()] = true
+	}
+	other := ref.to
+	if (ref.to != nil) && selected[ref.to.Index()] {
+		other = ref.from
+	}
+	if other != nil {
+		mode.mapDisplay.CenterOn(other.Center())
+	}
+	if ref.isBetweenObjects() {
+		mode.mapDisplay.SetHighlightedReferenceArrow(ref)
+	}
+}
+
+func (mode *LevelObjectsMode) onSearchQueryChangeRequested(text string) {
+	query, err := parseObjectQuery(text)
+	if err == nil {
+		err = query.checkFields(mode.levelAdapter.LevelObjects(func(*model.LevelObject) bool { return true }),
+			mode.classInterpreterFactory(), mode.extraInterpreterFactory())
+	}
+	if err != nil {
+		mode.context.ModelAdapter().SetMessage(fmt.Sprintf("Invalid search: %v", err))
+		return
+	}
+	mode.searchQueryValue.SetText(text)
+	mode.searchQuery = query
+	mode.onSearchChanged()
+}
+
+func (mode *LevelObjectsMode) onSearchDisplayChanged(item controls.ComboBoxItem) {
+	mode.searchMatchesOnly = item.(*enumItem).value == 1
+	mode.onSearchChanged()
+}
+
+func (mode *LevelObjectsMode) onSearchChanged() {
+	mode.updateSearchMatches()
+	if mode.area.IsVisible() {
+		mode.updateDisplayedObjects()
+	}
+}
+
+// updateSearchMatches finds all objects matching the current search. If only matches shall be shown,
+// the display filter is set to the search. An empty search matches nothing, yet shows all objects.
+func (mode *LevelObjectsMode) updateSearchMatches() {
+	filter := mode.searchQuery.filter(mode.classInterpreterFactory(), mode.extraInterpreterFactory())
+
+	mode.searchMatches = nil
+	mode.searchMatchIndex = -1
+	mode.displayFilter = func(*model.LevelObject) bool { return true }
+	if !mode.searchQuery.isEmpty() {
+		mode.searchMatches = mode.levelAdapter.LevelObjects(filter)
+		mode.searchMatchesInfo.SetText(fmt.Sprintf("%d", len(mode.searchMatches)))
+		if mode.searchMatchesOnly {
+			mode.displayFilter = filter
+		}
+	} else {
+		mode.searchMatchesInfo.SetText("")
+	}
+	if mode.area.IsVisible() {
+		mode.mapDisplay.SetMarkedObjects(mode.searchMatches)
+	}
+}
+
+// selectSearchMatchBy selects the match the given amount of entries away from the current one,
+// wrapping around at either end, and moves the map to it.
+func (mode *LevelObjectsMode) selectSearchMatchBy(offset int) {
+	count := len(mode.searchMatches)
+	if count == 0 {
+		return
+	}
+	if (mode.searchMatchIndex < 0) && (offset < 0) {
+		mode.searchMatchIndex = 0
+	}
+	mode.searchMatchIndex = ((mode.searchMatchIndex+offset)%count + count) % count
+	match := mode.searchMatches[mode.searchMatchIndex]
+	mode.setSelectedObjects([]*model.LevelObject{match})
+	mode.mapDisplay.CenterOn(match.Center())
+}
+
+func (mode *LevelObjectsMode) selectSearchMatches() {
+	if len(mode.searchMatches) > 0 {
+		mode.setSelectedObjects(append([]*model.LevelObject{}, mode.searchMatches...))
+	}
 }
 
 func (mode *LevelObjectsMode) onSelectedPropertiesDisplayChanged(item controls.ComboBoxItem) {
@@ -488,37 +794,159 @@ func (mode *LevelObjectsMode) updateSelectedFromDisplayedObjects() {
 func (mode *LevelObjectsMode) onMouseMoved(area *ui.Area, event events.Event) (consumed bool) {
 	mouseEvent := event.(*events.MouseMoveEvent)
 
-	if mouseEvent.Buttons() == 0 {
+	if mode.isMovingObjects() {
+		worldX, worldY := mode.mapDisplay.WorldCoordinatesForPixel(mouseEvent.Position())
+		mode.moveDeltaX, mode.moveDeltaY = worldX-mode.moveStartX, worldY-mode.moveStartY
+		consumed = true
+	} else if mouseEvent.Buttons() == 0 {
 		worldX, worldY := mode.mapDisplay.WorldCoordinatesForPixel(mouseEvent.Position())
 		mode.updateClosestDisplayedObjects(worldX, worldY)
+		mode.updateHoveredReference(worldX, worldY)
+		consumed = true
+	}
+
+	return
+}
+
+// onMouseButtonDown starts moving the selected objects if the primary button is pressed together with Alt.
+// With Shift in addition, the moved objects are snapped to the grid of new objects.
+func (mode *LevelObjectsMode) onMouseButtonDown(area *ui.Area, event events.Event) (consumed bool) {
+	mouseEvent := event.(*events.MouseButtonEvent)
+	modifier := keys.Modifier(mouseEvent.Modifier())
+
+	if (mouseEvent.Buttons() == env.MousePrimary) && modifier.Has(keys.ModAlt) && (len(mode.selectedObjects) > 0) {
+		mode.movingObjects = mode.selectedObjects
+		mode.moveStartX, mode.moveStartY = mode.mapDisplay.WorldCoordinatesForPixel(mouseEvent.Position())
+		mode.moveDeltaX, mode.moveDeltaY = 0, 0
+		mode.moveToGrid = modifier.Has(keys.ModShift)
+		mode.mapDisplay.SetMovedObjects(mode.movingObjects, mode.movedObjectCenter)
+		mode.area.RequestFocus()
 		consumed = true
 	}
 
 	return
 }
 
+func (mode *LevelObjectsMode) onMouseButtonUp(area *ui.Area, event events.Event) (consumed bool) {
+	mouseEvent := event.(*events.MouseButtonEvent)
+
+	if mode.isMovingObjects() && (mouseEvent.AffectedButtons() == env.MousePrimary) {
+		mode.finishObjectMove()
+		consumed = true
+	}
+
+	return
+}
+
+func (mode *LevelObjectsMode) onFocusLost(area *ui.Area, event events.Event) bool {
+	mode.cancelObjectMove()
+	return true
+}
+
+func (mode *LevelObjectsMode) isMovingObjects() bool {
+	return len(mode.movingObjects) > 0
+}
+
+// movedObjectPosition returns the fine coordinates the given object would have with the current move.
+func (mode *LevelObjectsMode) movedObjectPosition(object *model.LevelObject) (x, y int) {
+	centerX, centerY := object.Center()
+	return movedPosition(int(centerX+mode.moveDeltaX), int(centerY+mode.moveDeltaY), mode.moveToGrid)
+}
+
+// movedPosition returns the fine coordinates an object is moved to, kept within the map.
+// If requested, the position is snapped to the grid of its tile.
+func movedPosition(x, y int, toGrid bool) (int, int) {
+	limit := func(value int) int {
+		if value < 0 {
+			return 0
+		} else if value > 0x3FFF {
+			return 0x3FFF
+		}
+		return value
+	}
+	snapped := func(value int) int {
+		if toGrid {
+			return (value & ^0xFF) | model.SnapFineToGrid(value&0xFF)
+		}
+		return value
+	}
+	return snapped(limit(x)), snapped(limit(y))
+}
+
+func (mode *LevelObjectsMode) movedObjectCenter(object *model.LevelObject) (float32, float32) {
+	x, y := mode.movedObjectPosition(object)
+	return float32(x), float32(y)
+}
+
+func (mode *LevelObjectsMode) stopObjectMove() {
+	mode.movingObjects = nil
+	mode.mapDisplay.SetMovedObjects(nil, nil)
+	if mode.area.HasFocus() {
+		mode.area.ReleaseFocus()
+	}
+}
+
+func (mode *LevelObjectsMode) cancelObjectMove() {
+	if mode.isMovingObjects() {
+		mode.stopObjectMove()
+	}
+}
+
+// finishObjectMove changes the positions of all moved objects as one command.
+func (mode *LevelObjectsMode) finishObjectMove() {
+	command := cmd.SetLevelObjectsCommand{
+		Target: levelObjectsTarget(mode.levelAdapter.ID()),
+		Setter: mode.objectSetter(mode.levelAdapter.ID())}
+	for _, object := range mode.movingObjects {
+		centerX, centerY := object.Center()
+		x, y := mode.movedObjectPosition(object)
+		if (x != int(centerX)) || (y != int(centerY)) {
+			command.Changes = append(command.Changes, cmd.LevelObjectChange{
+				ObjectIndex:   object.Index(),
+				OldProperties: objectPositionProperties(int(centerX), int(centerY)),
+				NewProperties: objectPositionProperties(x, y)})
+		}
+	}
+	mode.stopObjectMove()
+	if len(command.Changes) > 0 {
+		mode.context.Perform(cmd.Described(command, fmt.Sprintf("Move %d objects", len(command.Changes))))
+	}
+}
+
+func (mode *LevelObjectsMode) objectSetter(levelID int) func(objectIndex int, properties dataModel.LevelObjectProperties) error {
+	return func(objectIndex int, properties dataModel.LevelObjectProperties) error {
+		requestLevelActive(mode.context.ModelAdapter(), levelID).RequestObjectPropertiesChange([]int{objectIndex}, &properties)
+		return nil
+	}
+}
+
 func (mode *LevelObjectsMode) onMouseScrolled(area *ui.Area, event events.Event) (consumed bool) {
 	mouseEvent := event.(*events.MouseScrollEvent)
 
 	if (mouseEvent.Buttons() == 0) && (keys.Modifier(mouseEvent.Modifier()) == keys.ModControl) {
-		available := len(mode.closestObjects)
-
-		if available > 1 {
-			_, dy := mouseEvent.Deltas()
-			delta := 1
+		_, dy := mouseEvent.Deltas()
+		delta := 1
 
-			if dy < 0 {
-				delta = -1
-			}
-			mode.closestObjectHighlightIndex = (available + mode.closestObjectHighlightIndex + delta) % available
-			mode.updateClosestObjectHighlight()
+		if dy < 0 {
+			delta = -1
 		}
+		mode.cycleHighlightedObject(delta)
 		consumed = true
 	}
 
 	return
 }
 
+// cycleHighlightedObject moves the highlight among the objects closest to the cursor.
+func (mode *LevelObjectsMode) cycleHighlightedObject(delta int) {
+	available := len(mode.closestObjects)
+
+	if available > 1 {
+		mode.closestObjectHighlightIndex = (available + mode.closestObjectHighlightIndex + delta) % available
+		mode.updateClosestObjectHighlight()
+	}
+}
+
 func (mode *LevelObjectsMode) onMouseButtonClicked(area *ui.Area, event events.Event) (consumed bool) {
 	mouseEvent := event.(*events.MouseButtonEvent)
 
@@ -627,6 +1055,51 @@ func (mode *LevelObjectsMode) setSelectedObjects(objects []*model.LevelObject) {
 	mode.onSelectedObjectsChanged()
 }
 
+// onSelectionBox selects all displayed objects within the box. Objects hidden by the display filter
+// are not affected. With Shift, the objects are added to the current selection; with Control,
+// they are removed from it.
+func (mode *LevelObjectsMode) onSelectionBox(fromX, fromY, toX, toY float32, modifier keys.Modifier) {
+	mode.setSelectedObjects(boxSelectedObjects(mode.selectedObjects, mode.displayedObjects,
+		fromX, fromY, toX, toY, modifier))
+}
+
+// boxSelectedObjects returns the given selection after a selection box with given world coordinates
+// and modifier was drawn over the displayed objects.
+func boxSelectedObjects(selected, displayed []*model.LevelObject, fromX, fromY, toX, toY float32,
+	modifier keys.Modifier) []*model.LevelObject {
+	left, right := float32(math.Min(float64(fromX), float64(toX))), float32(math.Max(float64(fromX), float64(toX)))
+	top, bottom := float32(math.Min(float64(fromY), float64(toY))), float32(math.Max(float64(fromY), float64(toY)))
+	inBox := func(object *model.LevelObject) bool {
+		x, y := object.Center()
+		return (x >= left) && (x <= right) && (y >= top) && (y <= bottom)
+	}
+	isSelected := func(object *model.LevelObject) bool {
+		for _, other := range selected {
+			if other.Index() == object.Index() {
+				return true
+			}
+		}
+		return false
+	}
+	newSelection := []*model.LevelObject{}
+
+	if (modifier == keys.ModShift) || (modifier == keys.ModControl) {
+		for _, object := range selected {
+			if (modifier == keys.ModShift) || !inBox(object) {
+				newSelection = append(newSelection, object)
+			}
+		}
+	}
+	if modifier != keys.ModControl {
+		for _, object := range displayed {
+			if inBox(object) && ((modifier != keys.ModShift) || !isSelected(object)) {
+				newSelection = append(newSelection, object)
+			}
+		}
+	}
+	return newSelection
+}
+
 func (mode *LevelObjectsMode) toggleSelectedObject(object *model.LevelObject) {
 	newList := []*model.LevelObject{}
 	wasSelected := false
@@ -646,6 +1119,8 @@ func (mode *LevelObjectsMode) toggleSelectedObject(object *model.LevelObject) {
 
 func (mode *LevelObjectsMode) onSelectedObjectsChanged() {
 	mode.mapDisplay.SetSelectedObjects(mode.selectedObjects)
+	mode.updateReferenceArrows()
+	mode.updateSelectedObjectsReferences()
 
 	classUnifier := util.NewValueUnifier(-1)
 	subclassUnifier := util.NewValueUnifier(-1)
@@ -1051,6 +1526,7 @@ func (mode *LevelObjectsMode) createPropertyControls(panel *propertyPanel, key s
 		})
 		slider.SetRange(0, 255)
 		slider.SetValueFormatter(mode.objectZToString)
+		slider.SetValueParser(mode.objectZFromString)
 		if unifiedValue != math.MinInt64 {
 			slider.SetValue(int64(unifiedValue))
 		}
@@ -1061,6 +1537,7 @@ func (mode *LevelObjectsMode) createPropertyControls(panel *propertyPanel, key s
 		})
 		slider.SetRange(0, 0x0FFF)
 		slider.SetValueFormatter(mode.moveTileHeightUnitToString)
+		slider.SetValueParser(mode.tileHeightUnitFromString)
 		if unifiedValue != math.MinInt64 {
 			slider.SetValue(int64(unifiedValue))
 		}
@@ -1082,6 +1559,10 @@ func (mode *LevelObjectsMode) tileHeightUnitToString(value int64) string {
 	return heightToString(mode.levelAdapter.HeightShift(), value, 32.0)
 }
 
+func (mode *LevelObjectsMode) tileHeightUnitFromString(text string) (int64, error) {
+	return heightParser(mode.levelAdapter.HeightShift(), 32.0)(text)
+}
+
 func (mode *LevelObjectsMode) tileHeightUnitToShortString(value int64) string {
 	return fmt.Sprintf("%.3f", heightToValue(mode.levelAdapter.HeightShift(), value, 32.0))
 }
@@ -1166,6 +1647,88 @@ func (mode *LevelObjectsMode) deleteSelectedObjects() {
 	mode.levelAdapter.RequestRemoveObjects(mode.selectedObjectIndices())
 }
 
+func (mode *LevelObjectsMode) onClipboardCopy(area *ui.Area, event events.Event) bool {
+	clipboardEvent := event.(*events.ClipboardEvent)
+
+	if len(mode.selectedObjects) > 0 {
+		copied, _, _ := copyObjects(mode.selectedObjects)
+		clipboardEvent.Clipboard().SetText(encodeObjectClipboard(copied))
+	}
+	return len(mode.selectedObjects) > 0
+}
+
+// onClipboardPaste adds the objects from the clipboard, with their top left corner at the mouse position.
+func (mode *LevelObjectsMode) onClipboardPaste(area *ui.Area, event events.Event) bool {
+	clipboardEvent := event.(*events.ClipboardEvent)
+	objects, err := decodeObjectClipboard(clipboardEvent.Clipboard().Text())
+
+	if err == nil {
+		worldX, worldY := mode.mapDisplay.WorldCoordinatesForPixel(clipboardEvent.Position())
+		mode.pasteObjects(objects, int(worldX), int(worldY), "Paste")
+	}
+	return err == nil
+}
+
+// duplicateSelectedObjects adds copies of the selected objects, one tile further east and south.
+func (mode *LevelObjectsMode) duplicateSelectedObjects() {
+	copied, left, top := copyObjects(mode.selectedObjects)
+	mode.pasteObjects(copied, left+0x100, top+0x100, "Duplicate")
+}
+
+// pasteObjects adds new objects with the properties of the copied ones, relative to given position,
+// as one command. The added objects become the new selection, also when the command is redone.
+// Objects that would be placed outside of the map are skipped.
+func (mode *LevelObjectsMode) pasteObjects(objects []copiedObject, left, top int, verb string) {
+	levelID := mode.levelAdapter.ID()
+	command := cmd.CompoundCommand{}
+	onAdded := func(object *model.LevelObject) {
+		if mode.levelAdapter.ID() == levelID {
+			selectedObjects := append([]*model.LevelObject{}, mode.selectedObjects...)
+			mode.setSelectedObjects(append(selectedObjects, object))
+		}
+	}
+	adder := mode.objectAdder(levelID, onAdded)
+	remover := mode.objectRemover(levelID)
+
+	for _, object := range objects {
+		if properties, inMap := placedObjectProperties(object, left, top); inMap {
+			command.Commands = append(command.Commands, cmd.LevelObjectPresenceCommand{
+				Target:     levelObjectsTarget(levelID),
+				Adder:      adder,
+				Remover:    remover,
+				Class:      object.Class,
+				Properties: properties,
+				Index:      new(cmd.ObjectIndex),
+				Add:        true})
+		}
+	}
+	if len(command.Commands) > 0 {
+		mode.setSelectedObjects(nil)
+		mode.context.Perform(cmd.Described(command, fmt.Sprintf("%v %d objects", verb, len(command.Commands))))
+	}
+}
+
+// objectAdder returns a function adding objects to the identified level. The given callback is called
+// for each added object, after the callback of the adder.
+func (mode *LevelObjectsMode) objectAdder(levelID int,
+	onAdded func(object *model.LevelObject)) func(int, dataModel.LevelObjectProperties, func(int), func()) error {
+	return func(class int, properties dataModel.LevelObjectProperties, onIndex func(objectIndex int), onFailed func()) error {
+		requestLevelActive(mode.context.ModelAdapter(), levelID).RequestNewObjectWithProperties(class, properties,
+			func(object *model.LevelObject) {
+				onIndex(object.Index())
+				onAdded(object)
+			}, onFailed)
+		return nil
+	}
+}
+
+func (mode *LevelObjectsMode) objectRemover(levelID int) func(objectIndex int) error {
+	return func(objectIndex int) error {
+		requestLevelActive(mode.context.ModelAdapter(), levelID).RequestRemoveObjects([]int{objectIndex})
+		return nil
+	}
+}
+
 func (mode *LevelObjectsMode) onGameObjectsChanged() {
 	newClassItems := make([]controls.ComboBoxItem, len(classNames))
 
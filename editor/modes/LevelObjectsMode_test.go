@@ -0,0 +1,101 @@
+package modes
+
+import (
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/editor/model"
+	"github.com/inkyblackness/shocked-client/editor/model/memstore"
+	"github.com/inkyblackness/shocked-client/env/keys"
+
+	dataModel "github.com/inkyblackness/shocked-model"
+)
+
+type LevelObjectsModeSuite struct {
+	store   *memstore.DataStore
+	level   *memstore.Level
+	adapter *model.Adapter
+}
+
+var _ = check.Suite(&LevelObjectsModeSuite{})
+
+func (suite *LevelObjectsModeSuite) SetUpTest(c *check.C) {
+	suite.store = memstore.NewDataStore(nil)
+	suite.level = suite.store.Project("project").AddLevel("archive", 1)
+	suite.adapter = model.NewAdapter(suite.store)
+	suite.adapter.RequestProject("project")
+	suite.store.Flush()
+}
+
+// givenObjectsAt adds objects at the given fine coordinates, in pairs of x and y.
+func (suite *LevelObjectsModeSuite) givenObjectsAt(xy ...int) {
+	for index := 0; index < len(xy); index += 2 {
+		tileX, fineX, tileY, fineY := xy[index]>>8, xy[index]&0xFF, xy[index+1]>>8, xy[index+1]&0xFF
+		suite.level.AddObject(7, dataModel.LevelObjectProperties{TileX: &tileX, FineX: &fineX, TileY: &tileY, FineY: &fineY})
+	}
+}
+
+// levelObjects returns the objects of given indices.
+func (suite *LevelObjectsModeSuite) levelObjects(indices ...int) []*model.LevelObject {
+	suite.adapter.RequestActiveLevel(1)
+	suite.store.Flush()
+	objects := []*model.LevelObject{}
+	for _, index := range indices {
+		objects = append(objects, suite.adapter.ActiveLevel().LevelObject(index))
+	}
+	return objects
+}
+
+func indicesOf(objects []*model.LevelObject) []int {
+	indices := []int{}
+	for _, object := range objects {
+		indices = append(indices, object.Index())
+	}
+	return indices
+}
+
+func (suite *LevelObjectsModeSuite) TestMovedPositionIsKeptWithinMap(c *check.C) {
+	x, y := movedPosition(-0x20, 0x4010, false)
+
+	c.Check([]int{x, y}, check.DeepEquals, []int{0x0000, 0x3FFF})
+}
+
+func (suite *LevelObjectsModeSuite) TestMovedPositionIsKeptWithoutGrid(c *check.C) {
+	x, y := movedPosition(0x1234, 0x0567, false)
+
+	c.Check([]int{x, y}, check.DeepEquals, []int{0x1234, 0x0567})
+}
+
+func (suite *LevelObjectsModeSuite) TestMovedPositionCanBeSnappedToGridOfItsTile(c *check.C) {
+	x, y := movedPosition(0x1234, 0x0567, true)
+	borderX, borderY := movedPosition(0x12D0, -0x10, true)
+
+	c.Check([]int{x, y}, check.DeepEquals, []int{0x1200, 0x0580})
+	c.Check([]int{borderX, borderY}, check.DeepEquals, []int{0x12FF, 0x0000})
+}
+
+func (suite *LevelObjectsModeSuite) TestBoxSelectsDisplayedObjectsWithin(c *check.C) {
+	suite.givenObjectsAt(0x1080, 0x1080, 0x1180, 0x1200, 0x1400, 0x1400, 0x1100, 0x1100)
+	displayed := suite.levelObjects(1, 2, 3)
+
+	selection := boxSelectedObjects(suite.levelObjects(3), displayed, 0x1200, 0x1280, 0x1000, 0x1000, keys.ModNone)
+
+	c.Check(indicesOf(selection), check.DeepEquals, []int{1, 2})
+}
+
+func (suite *LevelObjectsModeSuite) TestBoxWithShiftAddsObjectsToSelection(c *check.C) {
+	suite.givenObjectsAt(0x1080, 0x1080, 0x1180, 0x1200, 0x1400, 0x1400)
+	displayed := suite.levelObjects(1, 2, 3)
+
+	selection := boxSelectedObjects(suite.levelObjects(3, 1), displayed, 0x1000, 0x1000, 0x1200, 0x1280, keys.ModShift)
+
+	c.Check(indicesOf(selection), check.DeepEquals, []int{3, 1, 2})
+}
+
+func (suite *LevelObjectsModeSuite) TestBoxWithControlRemovesObjectsFromSelection(c *check.C) {
+	suite.givenObjectsAt(0x1080, 0x1080, 0x1180, 0x1200, 0x1400, 0x1400)
+	displayed := suite.levelObjects(1, 2, 3)
+
+	selection := boxSelectedObjects(suite.levelObjects(1, 2, 3), displayed, 0x1000, 0x1000, 0x1200, 0x1280, keys.ModControl)
+
+	c.Check(indicesOf(selection), check.DeepEquals, []int{3})
+}
@@ -0,0 +1,327 @@
+package modes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/inkyblackness/shocked-client/editor/actions"
+	"github.com/inkyblackness/shocked-client/editor/model"
+	"github.com/inkyblackness/shocked-client/graphics"
+	"github.com/inkyblackness/shocked-client/graphics/controls"
+	"github.com/inkyblackness/shocked-client/ui"
+	"github.com/inkyblackness/shocked-client/ui/events"
+
+	dataModel "github.com/inkyblackness/shocked-model"
+)
+
+const (
+	statisticsReportClasses = iota
+	statisticsReportTypes
+	statisticsReportTiles
+	statisticsReportTextures
+	statisticsReportAllLevels
+)
+
+// LevelStatisticsMode is a mode showing the usage of objects, tiles and textures of the levels.
+type LevelStatisticsMode struct {
+	context      Context
+	levelAdapter *model.LevelAdapter
+
+	area        *ui.Area
+	panel       *ui.Area
+	reportArea  *ui.Area
+	reportValue *controls.Label
+
+	reportLabel        *controls.Label
+	reportBox          *controls.ComboBox
+	reportItems        enumItems
+	objectsTitle       *controls.Label
+	objectsInfo        *controls.Label
+	surveillanceTitle  *controls.Label
+	surveillanceInfo   *controls.Label
+	animationsTitle    *controls.Label
+	animationsInfo     *controls.Label
+	summarizeLabel     *controls.Label
+	summarizeButton    *controls.TextButton
+	selectedReport     uint32
+	statistics         *model.LevelStatistics
+	levelSummaries     map[int]*model.LevelStatistics
+	failedSummaries    map[int]bool
+	requestedSummaries []int
+}
+
+// NewLevelStatisticsMode returns a new instance.
+func NewLevelStatisticsMode(context Context, parent *ui.Area) *LevelStatisticsMode {
+	mode := &LevelStatisticsMode{
+		context:         context,
+		levelAdapter:    context.ModelAdapter().ActiveLevel(),
+		levelSummaries:  make(map[int]*model.LevelStatistics),
+		failedSummaries: make(map[int]bool)}
+
+	scaled := func(value float32) float32 {
+		return value * context.ControlFactory().Scale()
+	}
+
+	{
+		builder := ui.NewAreaBuilder()
+		builder.SetParent(parent)
+		builder.SetLeft(ui.NewOffsetAnchor(parent.Left(), 0))
+		builder.SetTop(ui.NewOffsetAnchor(parent.Top(), 0))
+		builder.SetRight(ui.NewOffsetAnchor(parent.Right(), 0))
+		builder.SetBottom(ui.NewOffsetAnchor(parent.Bottom(), 0))
+		builder.SetVisible(false)
+		mode.area = builder.Build()
+	}
+	panelRight := ui.NewLimitedAnchor(ui.NewOffsetAnchor(parent.Left(), scaled(100)),
+		ui.NewRelativeAnchor(parent.Left(), parent.Right(), 0.5), ui.NewOffsetAnchor(parent.Left(), scaled(400)))
+	{
+		builder := ui.NewAreaBuilder()
+		builder.SetParent(mode.area)
+		builder.SetLeft(ui.NewOffsetAnchor(mode.area.Left(), 0))
+		builder.SetTop(ui.NewOffsetAnchor(mode.area.Top(), 0))
+		builder.SetRight(panelRight)
+		builder.SetBottom(ui.NewOffsetAnchor(mode.area.Bottom(), 0))
+		builder.OnRender(func(area *ui.Area) {
+			context.ForGraphics().RectangleRenderer().Fill(
+				area.Left().Value(), area.Top().Value(), area.Right().Value(), area.Bottom().Value(),
+				graphics.RGBA(0.7, 0.0, 0.7, 0.3))
+		})
+		builder.OnEvent(events.MouseMoveEventType, ui.SilentConsumer)
+		builder.OnEvent(events.MouseButtonUpEventType, ui.SilentConsumer)
+		builder.OnEvent(events.MouseButtonDownEventType, ui.SilentConsumer)
+		builder.OnEvent(events.MouseButtonClickedEventType, ui.SilentConsumer)
+		builder.OnEvent(events.MouseScrollEventType, ui.SilentConsumer)
+		mode.panel = builder.Build()
+	}
+	{
+		panelBuilder := newControlPanelBuilder(mode.panel, context.ControlFactory())
+
+		mode.reportLabel, mode.reportBox = panelBuilder.addComboProperty("Show", mode.onReportChanged)
+		mode.reportItems = []*enumItem{
+			{statisticsReportClasses, "Object Classes"},
+			{statisticsReportTypes, "Object Types"},
+			{statisticsReportTiles, "Tile Types"},
+			{statisticsReportTextures, "Texture Usage"},
+			{statisticsReportAllLevels, "All Levels"}}
+		mode.reportBox.SetItems(mode.reportItems.forComboBox())
+		mode.reportBox.SetSelectedItem(mode.reportItems[0])
+		mode.objectsTitle, mode.objectsInfo = panelBuilder.addInfo("Objects")
+		mode.surveillanceTitle, mode.surveillanceInfo = panelBuilder.addInfo("Surveillance Entries")
+		mode.animationsTitle, mode.animationsInfo = panelBuilder.addInfo("Animation Groups")
+		mode.summarizeLabel, mode.summarizeButton = panelBuilder.addTextButton("Summarize All Levels", "Run", mode.summarizeAllLevels)
+	}
+	{
+		padding := scaled(5)
+		builder := ui.NewAreaBuilder()
+		builder.SetParent(mode.area)
+		builder.SetLeft(ui.NewOffsetAnchor(panelRight, 0))
+		builder.SetTop(ui.NewOffsetAnchor(mode.area.Top(), 0))
+		builder.SetRight(ui.NewOffsetAnchor(mode.area.Right(), 0))
+		builder.SetBottom(ui.NewOffsetAnchor(mode.area.Bottom(), 0))
+		builder.OnRender(func(area *ui.Area) {
+			context.ForGraphics().RectangleRenderer().Fill(
+				area.Left().Value(), area.Top().Value(), area.Right().Value(), area.Bottom().Value(),
+				graphics.RGBA(0.5, 0.0, 0.5, 0.5))
+		})
+		mode.reportArea = builder.Build()
+
+		labelBuilder := mode.context.ControlFactory().ForLabel()
+		labelBuilder.SetParent(mode.reportArea)
+		labelBuilder.SetTop(ui.NewOffsetAnchor(mode.reportArea.Top(), padding))
+		labelBuilder.SetBottom(ui.NewOffsetAnchor(mode.reportArea.Bottom(), -padding))
+		labelBuilder.SetLeft(ui.NewOffsetAnchor(mode.reportArea.Left(), padding))
+		labelBuilder.SetRight(ui.NewOffsetAnchor(mode.reportArea.Right(), -padding))
+		labelBuilder.AlignedHorizontallyBy(controls.LeftAligner)
+		labelBuilder.AlignedVerticallyBy(controls.LeftAligner)
+		mode.reportValue = labelBuilder.Build()
+	}
+
+	mode.levelAdapter.OnIDChanged(mode.onLevelDataChanged)
+	mode.levelAdapter.OnLevelPropertiesChanged(mode.onLevelDataChanged)
+	mode.levelAdapter.OnLevelObjectsChanged(mode.onLevelDataChanged)
+	mode.levelAdapter.OnLevelSurveillanceChanged(mode.onLevelDataChanged)
+	mode.levelAdapter.OnLevelTextureAnimationsChanged(mode.onLevelDataChanged)
+	mode.levelAdapter.OnLevelTexturesChanged(mode.onLevelDataChanged)
+	mode.context.ModelAdapter().OnAvailableLevelsChanged(func() {
+		mode.levelSummaries = make(map[int]*model.LevelStatistics)
+		mode.failedSummaries = make(map[int]bool)
+		mode.requestedSummaries = nil
+	})
+
+	mode.context.Actions().Register(actions.Action{
+		Name:      "levelStatistics.summarize",
+		Title:     "Summarize statistics of all levels",
+		Handler:   mode.summarizeAllLevels,
+		Available: mode.area.IsVisible})
+
+	return mode
+}
+
+// SetActive implements the Mode interface.
+func (mode *LevelStatisticsMode) SetActive(active bool) {
+	mode.area.SetVisible(active)
+	if active {
+		mode.updateStatistics()
+	}
+}
+
+func (mode *LevelStatisticsMode) onLevelDataChanged() {
+	if mode.area.IsVisible() {
+		mode.updateStatistics()
+	}
+}
+
+func (mode *LevelStatisticsMode) onReportChanged(item controls.ComboBoxItem) {
+	mode.selectedReport = item.(*enumItem).value
+	mode.updateReport()
+}
+
+// updateStatistics collects the statistics of the active level and shows them.
+func (mode *LevelStatisticsMode) updateStatistics() {
+	mode.statistics = mode.levelAdapter.Statistics()
+	mode.objectsInfo.SetText(fmt.Sprintf("%d/%d", mode.statistics.ObjectCount(), maxObjectsPerLevel))
+	mode.surveillanceInfo.SetText(fmt.Sprintf("%d", mode.statistics.SurveillanceCount))
+	mode.animationsInfo.SetText(fmt.Sprintf("%d", mode.statistics.AnimationGroupCount))
+	mode.updateReport()
+}
+
+// summarizeAllLevels requests the statistics of every available level. Each level is
+// added to the summary as soon as its statistics have been received.
+func (mode *LevelStatisticsMode) summarizeAllLevels() {
+	mode.levelSummaries = make(map[int]*model.LevelStatistics)
+	mode.failedSummaries = make(map[int]bool)
+	mode.requestedSummaries = mode.context.ModelAdapter().AvailableLevelIDs()
+	for _, levelID := range mode.requestedSummaries {
+		mode.context.ModelAdapter().RequestLevelStatistics(levelID, mode.onLevelSummary)
+	}
+	mode.reportBox.SetSelectedItem(mode.reportItems[statisticsReportAllLevels])
+	mode.selectedReport = statisticsReportAllLevels
+	mode.updateReport()
+}
+
+func (mode *LevelStatisticsMode) onLevelSummary(levelID int, stats *model.LevelStatistics, err error) {
+	if err != nil {
+		mode.failedSummaries[levelID] = true
+	} else {
+		mode.levelSummaries[levelID] = stats
+	}
+	if mode.area.IsVisible() {
+		mode.updateReport()
+	}
+}
+
+func (mode *LevelStatisticsMode) updateReport() {
+	var lines []string
+	if mode.statistics != nil {
+		switch mode.selectedReport {
+		case statisticsReportClasses:
+			lines = mode.classReport()
+		case statisticsReportTypes:
+			lines = mode.typeReport()
+		case statisticsReportTiles:
+			lines = mode.tileReport()
+		case statisticsReportTextures:
+			lines = mode.textureReport()
+		case statisticsReportAllLevels:
+			lines = mode.allLevelsReport()
+		}
+	}
+	mode.reportValue.SetText(strings.Join(lines, "\n"))
+}
+
+// classReport lists the objects per class against the limits of the engine.
+func (mode *LevelStatisticsMode) classReport() (lines []string) {
+	for class, maxObjects := range maxObjectsPerClass {
+		count := mode.statistics.ObjectClassCount(class)
+		line := fmt.Sprintf("%-14v %3d/%3d", classNames[class], count, maxObjects-1)
+		if count > maxObjects-1 {
+			line += "  over limit!"
+		}
+		lines = append(lines, line)
+	}
+	return
+}
+
+// typeReport lists the objects per type, grouped by their subclass.
+func (mode *LevelStatisticsMode) typeReport() (lines []string) {
+	ids := make([]model.ObjectID, 0, len(mode.statistics.ObjectCounts))
+	for id := range mode.statistics.ObjectCounts {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(a, b int) bool { return ids[a].ToInt() < ids[b].ToInt() })
+	subclassCount := func(class, subclass int) (count int) {
+		for id, idCount := range mode.statistics.ObjectCounts {
+			if (id.Class() == class) && (id.Subclass() == subclass) {
+				count += idCount
+			}
+		}
+		return
+	}
+	for index, id := range ids {
+		if (index == 0) || (ids[index-1].Class() != id.Class()) || (ids[index-1].Subclass() != id.Subclass()) {
+			lines = append(lines, fmt.Sprintf("%2d/%d %v: %d", id.Class(), id.Subclass(),
+				classNames[id.Class()], subclassCount(id.Class(), id.Subclass())))
+		}
+		displayName := "unknown"
+		if gameObject := mode.context.ModelAdapter().ObjectsAdapter().Object(id); gameObject != nil {
+			displayName = gameObject.DisplayName()
+		}
+		lines = append(lines, fmt.Sprintf("    %v %-24v %3d", id, displayName, mode.statistics.ObjectCounts[id]))
+	}
+	return
+}
+
+func (mode *LevelStatisticsMode) tileReport() (lines []string) {
+	tileTypes := make([]string, 0, len(mode.statistics.TileTypeCounts))
+	for tileType := range mode.statistics.TileTypeCounts {
+		tileTypes = append(tileTypes, string(tileType))
+	}
+	sort.Strings(tileTypes)
+	for _, tileType := range tileTypes {
+		lines = append(lines, fmt.Sprintf("%-28v %4d", tileType, mode.statistics.TileTypeCounts[dataModel.TileType(tileType)]))
+	}
+	return
+}
+
+// textureReport lists the uses of every level texture slot, including unused ones.
+func (mode *LevelStatisticsMode) textureReport() (lines []string) {
+	for slot := range mode.levelAdapter.LevelTextureIDs() {
+		lines = append(lines, fmt.Sprintf("Slot %2d (texture %3d): %4d", slot,
+			mode.levelAdapter.LevelTextureID(slot), mode.statistics.TextureUsageCounts[slot]))
+	}
+	return
+}
+
+func (mode *LevelStatisticsMode) allLevelsReport() (lines []string) {
+	if len(mode.requestedSummaries) == 0 {
+		return []string{"Run the summary to collect the statistics of all levels."}
+	}
+	for _, levelID := range mode.requestedSummaries {
+		stats := mode.levelSummaries[levelID]
+		if mode.failedSummaries[levelID] {
+			lines = append(lines, fmt.Sprintf("Level %2d: failed to load", levelID))
+			continue
+		}
+		if stats == nil {
+			lines = append(lines, fmt.Sprintf("Level %2d: loading...", levelID))
+			continue
+		}
+		openTiles := 0
+		for tileType, count := range stats.TileTypeCounts {
+			if tileType != dataModel.Solid {
+				openTiles += count
+			}
+		}
+		classesOverLimit := 0
+		for class, maxObjects := range maxObjectsPerClass {
+			if stats.ObjectClassCount(class) > maxObjects-1 {
+				classesOverLimit++
+			}
+		}
+		lines = append(lines, fmt.Sprintf("Level %2d: %3d/%d objects, %d classes over limit, %4d open tiles, %2d texture slots used, %d surveillance, %d animation groups",
+			levelID, stats.ObjectCount(), maxObjectsPerLevel, classesOverLimit,
+			openTiles, len(stats.TextureUsageCounts), stats.SurveillanceCount, stats.AnimationGroupCount))
+	}
+	return
+}
@@ -0,0 +1,164 @@
+package modes
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/inkyblackness/res"
+	"github.com/inkyblackness/res/data/levelobj"
+
+	"github.com/inkyblackness/shocked-client/editor/model"
+
+	dataModel "github.com/inkyblackness/shocked-model"
+)
+
+// levelFinding is a problem found by validating a level.
+// Findings about a specific object refer to it; others, such as broken references, have no object.
+type levelFinding struct {
+	description string
+	object      *model.LevelObject
+}
+
+// String implements the Stringer interface.
+func (finding *levelFinding) String() string {
+	return finding.description
+}
+
+// levelValidationRule checks one aspect of the given level and returns all problems found.
+type levelValidationRule func(level *model.LevelAdapter) []*levelFinding
+
+var levelValidationRules = []levelValidationRule{
+	validateSurveillanceReferences,
+	validateBlockPuzzleStateStores,
+	validateObjectsInSolidTiles,
+	validateObjectsBelowFloor}
+
+// validateLevel runs all rules on the given level and returns their findings in order of the rules.
+func validateLevel(level *model.LevelAdapter) (findings []*levelFinding) {
+	for _, rule := range levelValidationRules {
+		findings = append(findings, rule(level)...)
+	}
+	return
+}
+
+func allLevelObjects(level *model.LevelAdapter) []*model.LevelObject {
+	return level.LevelObjects(func(*model.LevelObject) bool { return true })
+}
+
+// objectFinding returns a finding about the given object, with the description prefixed by the object.
+func objectFinding(object *model.LevelObject, format string, a ...interface{}) *levelFinding {
+	return &levelFinding{
+		description: fmt.Sprintf("Object %d (%v): ", object.Index(), object.ID()) + fmt.Sprintf(format, a...),
+		object:      object}
+}
+
+// validateSurveillanceReferences finds surveillance sources and deathwatch objects that do not exist.
+// Index zero is never used by objects and marks an unused entry.
+func validateSurveillanceReferences(level *model.LevelAdapter) (findings []*levelFinding) {
+	for index := 0; index < level.ObjectSurveillanceCount(); index++ {
+		source, deathwatch := level.ObjectSurveillanceInfo(index)
+		if (source != 0) && (level.LevelObject(source) == nil) {
+			findings = append(findings, &levelFinding{
+				description: fmt.Sprintf("Surveillance %d: source refers to missing object %d", index, source)})
+		}
+		if (deathwatch != 0) && (level.LevelObject(deathwatch) == nil) {
+			findings = append(findings, &levelFinding{
+				description: fmt.Sprintf("Surveillance %d: deathwatch refers to missing object %d", index, deathwatch)})
+		}
+	}
+	return
+}
+
+// validateBlockPuzzleStateStores finds block puzzles which do not keep their state in a 12/0/1 data object.
+func validateBlockPuzzleStateStores(level *model.LevelAdapter) (findings []*levelFinding) {
+	interpreterFactory := levelobj.ForRealWorld
+	if level.IsCyberspace() {
+		interpreterFactory = levelobj.ForCyberspace
+	}
+	stateStoreID := model.MakeObjectID(12, 0, 1)
+
+	for _, object := range allLevelObjects(level) {
+		objID := object.ID()
+		resID := res.MakeObjectID(res.ObjectClass(objID.Class()), res.ObjectSubclass(objID.Subclass()), res.ObjectType(objID.Type()))
+		puzzle := interpreterFactory(resID, object.ClassData()).Refined("Puzzle")
+
+		if puzzle.Get("Type") == 0x10 {
+			stateStoreIndex := int(puzzle.Refined("Block").Get("StateStoreObjectIndex"))
+			stateStore := level.LevelObject(stateStoreIndex)
+			if stateStore == nil {
+				findings = append(findings, objectFinding(object,
+					"block puzzle state refers to missing object %d", stateStoreIndex))
+			} else if stateStore.ID() != stateStoreID {
+				findings = append(findings, objectFinding(object,
+					"block puzzle state refers to object %d, which is not a %v", stateStoreIndex, stateStoreID))
+			}
+		}
+	}
+	return
+}
+
+func tilePropertiesOfObject(level *model.LevelAdapter, object *model.LevelObject) *dataModel.TileProperties {
+	if !isTileOnMap(object.TileX(), object.TileY()) {
+		return nil
+	}
+	return level.TileMap().Tile(model.TileCoordinateOf(object.TileX(), object.TileY())).Properties()
+}
+
+// validateObjectsInSolidTiles finds objects that are placed within solid tiles.
+func validateObjectsInSolidTiles(level *model.LevelAdapter) (findings []*levelFinding) {
+	for _, object := range allLevelObjects(level) {
+		properties := tilePropertiesOfObject(level, object)
+		if (properties != nil) && (*properties.Type == dataModel.Solid) {
+			findings = append(findings, objectFinding(object, "placed in solid tile"))
+		}
+	}
+	return
+}
+
+// floorRiseByType returns how far the sloped floor is raised at a position within a tile,
+// as a fraction of the slope height. x and y are from 0.0 (west, south) to 1.0 (east, north).
+var floorRiseByType = map[dataModel.TileType]func(x, y float64) float64{
+	dataModel.SlopeSouthToNorth: func(x, y float64) float64 { return y },
+	dataModel.SlopeWestToEast:   func(x, y float64) float64 { return x },
+	dataModel.SlopeNorthToSouth: func(x, y float64) float64 { return 1.0 - y },
+	dataModel.SlopeEastToWest:   func(x, y float64) float64 { return 1.0 - x },
+
+	dataModel.ValleySouthEastToNorthWest: func(x, y float64) float64 { return math.Min(1.0, 1.0-x+y) },
+	dataModel.ValleySouthWestToNorthEast: func(x, y float64) float64 { return math.Min(1.0, x+y) },
+	dataModel.ValleyNorthWestToSouthEast: func(x, y float64) float64 { return math.Min(1.0, 1.0+x-y) },
+	dataModel.ValleyNorthEastToSouthWest: func(x, y float64) float64 { return math.Min(1.0, 2.0-x-y) },
+
+	dataModel.RidgeSouthEastToNorthWest: func(x, y float64) float64 { return math.Max(0.0, y-x) },
+	dataModel.RidgeSouthWestToNorthEast: func(x, y float64) float64 { return math.Max(0.0, x+y-1.0) },
+	dataModel.RidgeNorthWestToSouthEast: func(x, y float64) float64 { return math.Max(0.0, x-y) },
+	dataModel.RidgeNorthEastToSouthWest: func(x, y float64) float64 { return math.Max(0.0, 1.0-x-y) }}
+
+// floorHeightAt returns the height of the floor, in tile height units, at the given fine position within the tile.
+func floorHeightAt(properties *dataModel.TileProperties, fineX, fineY int) float64 {
+	height := float64(*properties.FloorHeight)
+	if (properties.SlopeHeight != nil) && (*properties.SlopeHeight > 0) && (*properties.SlopeControl != dataModel.SlopeFloorFlat) {
+		if rise, sloped := floorRiseByType[*properties.Type]; sloped {
+			height += float64(*properties.SlopeHeight) * rise(float64(fineX)/256.0, float64(fineY)/256.0)
+		}
+	}
+	return height
+}
+
+// validateObjectsBelowFloor finds objects that are placed below the floor of their tile.
+// The floor is taken at the position of the object, including the slope of the tile. As object heights
+// and tile heights have different ranges, both are compared as tile heights of the level's height shift.
+func validateObjectsBelowFloor(level *model.LevelAdapter) (findings []*levelFinding) {
+	heightShift := level.HeightShift()
+	for _, object := range allLevelObjects(level) {
+		properties := tilePropertiesOfObject(level, object)
+		if (properties == nil) || (*properties.Type == dataModel.Solid) {
+			continue
+		}
+		objectHeight := heightToValue(heightShift, int64(object.Z()), 256.0)
+		floorHeight := heightToValue(heightShift, 1, 32.0) * floorHeightAt(properties, object.FineX(), object.FineY())
+		if objectHeight < floorHeight {
+			findings = append(findings, objectFinding(object, "placed below floor (%.3f < %.3f)", objectHeight, floorHeight))
+		}
+	}
+	return
+}
@@ -0,0 +1,180 @@
+package modes
+
+import (
+	"fmt"
+
+	"github.com/inkyblackness/shocked-client/editor/actions"
+	"github.com/inkyblackness/shocked-client/editor/display"
+	"github.com/inkyblackness/shocked-client/editor/model"
+	"github.com/inkyblackness/shocked-client/graphics"
+	"github.com/inkyblackness/shocked-client/graphics/controls"
+	"github.com/inkyblackness/shocked-client/ui"
+	"github.com/inkyblackness/shocked-client/ui/events"
+)
+
+// LevelValidationMode is a mode listing the problems found in the active level.
+// Selecting a finding moves the map to it and highlights it.
+type LevelValidationMode struct {
+	context      Context
+	levelAdapter *model.LevelAdapter
+
+	mapDisplay *display.MapDisplay
+
+	area *ui.Area
+
+	validateLabel  *controls.Label
+	validateButton *controls.TextButton
+	countTitle     *controls.Label
+	countInfo      *controls.Label
+	findingLabel   *controls.Label
+	findingBox     *controls.ComboBox
+
+	findings        []*levelFinding
+	selectedFinding *levelFinding
+}
+
+// NewLevelValidationMode returns a new instance.
+func NewLevelValidationMode(context Context, parent *ui.Area, mapDisplay *display.MapDisplay) *LevelValidationMode {
+	mode := &LevelValidationMode{
+		context:      context,
+		levelAdapter: context.ModelAdapter().ActiveLevel(),
+		mapDisplay:   mapDisplay}
+
+	scaled := func(value float32) float32 {
+		return value * context.ControlFactory().Scale()
+	}
+
+	{
+		minRight := ui.NewOffsetAnchor(parent.Left(), scaled(100))
+		maxRight := ui.NewRelativeAnchor(parent.Left(), parent.Right(), 0.5)
+		builder := ui.NewAreaBuilder()
+		builder.SetParent(parent)
+		builder.SetLeft(ui.NewOffsetAnchor(parent.Left(), 0))
+		builder.SetTop(ui.NewOffsetAnchor(parent.Top(), 0))
+		builder.SetRight(ui.NewLimitedAnchor(minRight, maxRight, ui.NewOffsetAnchor(parent.Left(), scaled(400))))
+		builder.SetBottom(ui.NewOffsetAnchor(parent.Bottom(), 0))
+		builder.SetVisible(false)
+		builder.OnRender(func(area *ui.Area) {
+			context.ForGraphics().RectangleRenderer().Fill(
+				area.Left().Value(), area.Top().Value(), area.Right().Value(), area.Bottom().Value(),
+				graphics.RGBA(0.7, 0.0, 0.7, 0.3))
+		})
+		builder.OnEvent(events.MouseMoveEventType, ui.SilentConsumer)
+		builder.OnEvent(events.MouseButtonUpEventType, ui.SilentConsumer)
+		builder.OnEvent(events.MouseButtonDownEventType, ui.SilentConsumer)
+		builder.OnEvent(events.MouseButtonClickedEventType, ui.SilentConsumer)
+		builder.OnEvent(events.MouseScrollEventType, ui.SilentConsumer)
+		mode.area = builder.Build()
+	}
+	{
+		panelBuilder := newControlPanelBuilder(mode.area, context.ControlFactory())
+
+		mode.validateLabel, mode.validateButton = panelBuilder.addTextButton("Validate Level", "Run", mode.validate)
+		mode.countTitle, mode.countInfo = panelBuilder.addInfo("Findings")
+		mode.findingLabel, mode.findingBox = panelBuilder.addComboProperty("Finding", mode.onFindingChanged)
+	}
+
+	mode.levelAdapter.OnIDChanged(mode.onLevelDataChanged)
+	mode.levelAdapter.OnLevelObjectsChanged(mode.onLevelDataChanged)
+	mode.levelAdapter.OnLevelSurveillanceChanged(mode.onLevelDataChanged)
+
+	mode.registerAction("levelValidation.validate", "Validate level", mode.validate)
+	mode.registerAction("levelValidation.nextFinding", "Show next validation finding", func() { mode.selectFindingBy(1) })
+	mode.registerAction("levelValidation.previousFinding", "Show previous validation finding", func() { mode.selectFindingBy(-1) })
+
+	return mode
+}
+
+func (mode *LevelValidationMode) registerAction(name, title string, handler actions.Handler) {
+	mode.context.Actions().Register(actions.Action{
+		Name:      name,
+		Title:     title,
+		Handler:   handler,
+		Available: mode.area.IsVisible})
+}
+
+// SetActive implements the Mode interface.
+func (mode *LevelValidationMode) SetActive(active bool) {
+	mode.area.SetVisible(active)
+	mode.mapDisplay.SetVisible(active)
+	if active {
+		mode.validate()
+	} else {
+		mode.setFindings(nil)
+	}
+}
+
+func (mode *LevelValidationMode) onLevelDataChanged() {
+	if mode.area.IsVisible() {
+		mode.validate()
+	}
+}
+
+// validate runs all rules on the active level and lists the findings.
+func (mode *LevelValidationMode) validate() {
+	mode.setFindings(validateLevel(mode.levelAdapter))
+}
+
+func (mode *LevelValidationMode) setFindings(findings []*levelFinding) {
+	mode.findings = findings
+	mode.countInfo.SetText(fmt.Sprintf("%d", len(findings)))
+
+	items := make([]controls.ComboBoxItem, len(findings))
+	for index, finding := range findings {
+		items[index] = finding
+	}
+	mode.findingBox.SetItems(items)
+
+	var objects []*model.LevelObject
+	for _, finding := range findings {
+		if finding.object != nil {
+			objects = append(objects, finding.object)
+		}
+	}
+	mode.mapDisplay.SetDisplayedObjects(objects)
+	mode.selectFinding(nil)
+}
+
+func (mode *LevelValidationMode) onFindingChanged(item controls.ComboBoxItem) {
+	mode.selectFinding(item.(*levelFinding))
+}
+
+// selectFindingBy selects the finding the given amount of entries away from the current one,
+// wrapping around at either end of the list.
+func (mode *LevelValidationMode) selectFindingBy(offset int) {
+	count := len(mode.findings)
+	if count == 0 {
+		return
+	}
+	index := -1
+	for findingIndex, finding := range mode.findings {
+		if finding == mode.selectedFinding {
+			index = findingIndex
+		}
+	}
+	if (index < 0) && (offset < 0) {
+		index = 0
+	}
+	mode.selectFinding(mode.findings[((index+offset)%count+count)%count])
+}
+
+// selectFinding shows the given finding. The description is set as message and the
+// map is moved to the object of the finding, if it has one.
+func (mode *LevelValidationMode) selectFinding(finding *levelFinding) {
+	mode.selectedFinding = finding
+	if finding != nil {
+		mode.findingBox.SetSelectedItem(finding)
+		mode.context.ModelAdapter().SetMessage(finding.description)
+	} else {
+		mode.findingBox.SetSelectedItem(nil)
+	}
+
+	if (finding != nil) && (finding.object != nil) {
+		mode.mapDisplay.CenterOn(finding.object.Center())
+		mode.mapDisplay.SetHighlightedObject(finding.object)
+		mode.mapDisplay.SetHighlightedTile(model.TileCoordinateOf(finding.object.TileX(), finding.object.TileY()))
+	} else {
+		mode.mapDisplay.SetHighlightedObject(nil)
+		mode.mapDisplay.ClearHighlightedTile()
+	}
+}
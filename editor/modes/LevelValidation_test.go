@@ -0,0 +1,161 @@
+package modes
+
+import (
+	"fmt"
+
+	"github.com/inkyblackness/res"
+	"github.com/inkyblackness/res/data/levelobj"
+
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/editor/model"
+	"github.com/inkyblackness/shocked-client/editor/model/memstore"
+
+	dataModel "github.com/inkyblackness/shocked-model"
+)
+
+type LevelValidationSuite struct {
+	store   *memstore.DataStore
+	level   *memstore.Level
+	adapter *model.Adapter
+}
+
+var _ = check.Suite(&LevelValidationSuite{})
+
+func (suite *LevelValidationSuite) SetUpTest(c *check.C) {
+	suite.store = memstore.NewDataStore(nil)
+	suite.level = suite.store.Project("project").AddLevel("archive", 1)
+	suite.adapter = model.NewAdapter(suite.store)
+	suite.adapter.RequestProject("project")
+	suite.store.Flush()
+}
+
+// blockPuzzleProperties returns the properties of a block puzzle that keeps its state in the object
+// of given index. The puzzle is the first fixture for which the interpreters provide block puzzle properties.
+func blockPuzzleProperties(stateStoreIndex int) dataModel.LevelObjectProperties {
+	for subclass := 0; subclass < 8; subclass++ {
+		for objType := 0; objType < 16; objType++ {
+			classData := make([]byte, 64)
+			resID := res.MakeObjectID(res.ObjectClass(7), res.ObjectSubclass(subclass), res.ObjectType(objType))
+			puzzle := levelobj.ForRealWorld(resID, classData).Refined("Puzzle")
+			puzzle.Set("Type", 0x10)
+			puzzle.Refined("Block").Set("StateStoreObjectIndex", uint32(stateStoreIndex))
+			if (puzzle.Get("Type") == 0x10) && (puzzle.Refined("Block").Get("StateStoreObjectIndex") == uint32(stateStoreIndex)) {
+				return dataModel.LevelObjectProperties{Subclass: &subclass, Type: &objType, ClassData: classData}
+			}
+		}
+	}
+	panic("no block puzzle fixture")
+}
+
+func (suite *LevelValidationSuite) givenOpenTile(x, y int, floorHeight int) {
+	tileType := dataModel.Open
+	height := dataModel.HeightUnit(floorHeight)
+	suite.level.SetTile(x, y, dataModel.TileProperties{Type: &tileType, FloorHeight: &height})
+}
+
+func (suite *LevelValidationSuite) givenObjectInTile(x, y int, z int) int {
+	fine := 0x80
+	return suite.level.AddObject(1, dataModel.LevelObjectProperties{
+		TileX: &x, FineX: &fine, TileY: &y, FineY: &fine, Z: &z})
+}
+
+func (suite *LevelValidationSuite) givenHeightShift(heightShift int) {
+	properties := suite.level.Properties()
+	properties.HeightShift = &heightShift
+	suite.level.SetProperties(properties)
+}
+
+func (suite *LevelValidationSuite) findings() []string {
+	suite.adapter.RequestActiveLevel(1)
+	suite.store.Flush()
+	descriptions := []string{}
+	for _, finding := range validateLevel(suite.adapter.ActiveLevel()) {
+		descriptions = append(descriptions, finding.String())
+	}
+	return descriptions
+}
+
+func (suite *LevelValidationSuite) TestObjectsInSolidTilesAreFound(c *check.C) {
+	suite.givenOpenTile(20, 20, 0)
+	solidIndex := suite.givenObjectInTile(10, 10, 0)
+	suite.givenObjectInTile(20, 20, 0)
+
+	c.Check(suite.findings(), check.DeepEquals,
+		[]string{fmt.Sprintf("Object %d ( 1/0/ 0): placed in solid tile", solidIndex)})
+}
+
+func (suite *LevelValidationSuite) TestObjectsBelowFloorAreFound(c *check.C) {
+	suite.givenOpenTile(20, 20, 4)
+	suite.givenOpenTile(30, 30, 4)
+	belowIndex := suite.givenObjectInTile(20, 20, 16)
+	suite.givenObjectInTile(30, 30, 32)
+
+	c.Check(suite.findings(), check.DeepEquals,
+		[]string{fmt.Sprintf("Object %d ( 1/0/ 0): placed below floor (0.250 < 0.500)", belowIndex)})
+}
+
+func (suite *LevelValidationSuite) TestFloorIsScaledByHeightShift(c *check.C) {
+	suite.givenHeightShift(1)
+	suite.givenOpenTile(20, 20, 4)
+	suite.givenOpenTile(30, 30, 4)
+	belowIndex := suite.givenObjectInTile(20, 20, 31)
+	suite.givenObjectInTile(30, 30, 32)
+
+	c.Check(suite.findings(), check.DeepEquals,
+		[]string{fmt.Sprintf("Object %d ( 1/0/ 0): placed below floor (1.938 < 2.000)", belowIndex)})
+}
+
+func (suite *LevelValidationSuite) TestFloorIsTakenAtSlopeBelowObject(c *check.C) {
+	suite.givenOpenTile(20, 20, 2)
+	tileType := dataModel.SlopeSouthToNorth
+	slopeHeight := dataModel.HeightUnit(4)
+	slopeControl := dataModel.SlopeCeilingFlat
+	suite.level.SetTile(20, 20, dataModel.TileProperties{Type: &tileType, SlopeHeight: &slopeHeight, SlopeControl: &slopeControl})
+	belowIndex := suite.givenObjectInTile(20, 20, 24)
+	suite.givenObjectInTile(20, 20, 32)
+
+	c.Check(suite.findings(), check.DeepEquals,
+		[]string{fmt.Sprintf("Object %d ( 1/0/ 0): placed below floor (0.375 < 0.500)", belowIndex)})
+}
+
+func (suite *LevelValidationSuite) TestFloorOfSlopeWithFlatFloorIsNotRaised(c *check.C) {
+	suite.givenOpenTile(20, 20, 2)
+	tileType := dataModel.SlopeSouthToNorth
+	slopeHeight := dataModel.HeightUnit(4)
+	slopeControl := dataModel.SlopeFloorFlat
+	suite.level.SetTile(20, 20, dataModel.TileProperties{Type: &tileType, SlopeHeight: &slopeHeight, SlopeControl: &slopeControl})
+	suite.givenObjectInTile(20, 20, 16)
+
+	c.Check(suite.findings(), check.HasLen, 0)
+}
+
+func (suite *LevelValidationSuite) TestBlockPuzzlesWithoutStateStoreAreFound(c *check.C) {
+	suite.givenOpenTile(0, 0, 0)
+	subclass, objType := 0, 1
+	stateStoreIndex := suite.level.AddObject(12, dataModel.LevelObjectProperties{Subclass: &subclass, Type: &objType})
+	ammoIndex := suite.givenObjectInTile(0, 0, 0)
+	suite.level.AddObject(7, blockPuzzleProperties(stateStoreIndex))
+	missingIndex := suite.level.AddObject(7, blockPuzzleProperties(200))
+	wrongIndex := suite.level.AddObject(7, blockPuzzleProperties(ammoIndex))
+
+	findings := suite.findings()
+
+	puzzleID := suite.adapter.ActiveLevel().LevelObject(wrongIndex).ID()
+	c.Check(findings, check.DeepEquals, []string{
+		fmt.Sprintf("Object %d (%v): block puzzle state refers to missing object 200", missingIndex, puzzleID),
+		fmt.Sprintf("Object %d (%v): block puzzle state refers to object %d, which is not a %v",
+			wrongIndex, puzzleID, ammoIndex, model.MakeObjectID(12, 0, 1))})
+}
+
+func (suite *LevelValidationSuite) TestMissingSurveillanceObjectsAreFound(c *check.C) {
+	suite.givenOpenTile(20, 20, 0)
+	source, deathwatch := suite.givenObjectInTile(20, 20, 0), 123
+	suite.level.SetSurveillance(2, dataModel.SurveillanceObject{SourceIndex: &source, DeathwatchIndex: &deathwatch})
+	missingSource, unused := 124, 0
+	suite.level.SetSurveillance(5, dataModel.SurveillanceObject{SourceIndex: &missingSource, DeathwatchIndex: &unused})
+
+	c.Check(suite.findings(), check.DeepEquals, []string{
+		"Surveillance 2: deathwatch refers to missing object 123",
+		"Surveillance 5: source refers to missing object 124"})
+}
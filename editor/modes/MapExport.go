@@ -0,0 +1,74 @@
+package modes
+
+import (
+	"fmt"
+	"image/png"
+	"os"
+	"path"
+
+	"github.com/inkyblackness/shocked-client/editor/model"
+	"github.com/inkyblackness/shocked-client/ui"
+	"github.com/inkyblackness/shocked-client/ui/events"
+)
+
+// mapExportSizes are the sizes per side, in pixels, a level map can be exported with.
+var mapExportSizes = []int{512, 1024, 2048, 4096, 8192}
+
+const defaultMapExportSize = 1024
+
+func (mode *LevelMapMode) onFileDropped(area *ui.Area, event events.Event) (consumed bool) {
+	dropEvent := event.(*events.FileDropEvent)
+
+	if len(dropEvent.FilePaths()) == 1 {
+		filePath := dropEvent.FilePaths()[0]
+		fileInfo, err := os.Stat(filePath)
+
+		if (err == nil) && fileInfo.IsDir() {
+			mode.exportMap(filePath)
+		} else {
+			mode.context.ModelAdapter().SetMessage(fmt.Sprintf("File is not found/recognized %s", filePath))
+		}
+		consumed = true
+	}
+
+	return
+}
+
+// exportMap writes the map of the active level as image and as vector graphic into the given directory.
+// Both use the current texture view and tile coloring. The directory is kept for the next export
+// by action, which starts with the working directory.
+func (mode *LevelMapMode) exportMap(dirPath string) {
+	mode.exportDirPath = dirPath
+	mode.exportInfoValue.SetText(dirPath)
+
+	var objects []*model.LevelObject
+	if mode.exportObjects {
+		objects = allLevelObjects(mode.levelAdapter)
+	}
+	baseName := path.Join(dirPath, fmt.Sprintf("level_%02d", mode.levelAdapter.ID()))
+	imageFileName := baseName + ".png"
+	vectorFileName := baseName + ".svg"
+	imageFile, imageErr := os.Create(imageFileName)
+	if imageErr != nil {
+		mode.context.ModelAdapter().SetMessage("Could not create file for export.")
+		return
+	}
+	defer imageFile.Close()
+	vectorFile, vectorErr := os.Create(vectorFileName)
+	if vectorErr != nil {
+		mode.context.ModelAdapter().SetMessage("Could not create file for export.")
+		return
+	}
+	defer vectorFile.Close()
+
+	img, imageErr := mode.mapDisplay.RenderImage(mode.exportSize, objects)
+	if imageErr == nil {
+		imageErr = png.Encode(imageFile, img)
+	}
+	vectorErr = mode.mapDisplay.WriteVectorMap(vectorFile, mode.exportSize, objects)
+	if (imageErr != nil) || (vectorErr != nil) {
+		mode.context.ModelAdapter().SetMessage("Could not write export.")
+	} else {
+		mode.context.ModelAdapter().SetMessage(fmt.Sprintf("Exported %s and %s", imageFileName, vectorFileName))
+	}
+}
@@ -19,6 +19,9 @@ var classNames = []string{
 
 var maxObjectsPerClass = []int{16, 32, 32, 32, 32, 8, 16, 176, 128, 64, 64, 32, 160, 64, 64}
 
+// maxObjectsPerLevel is the amount of objects a level can hold, without the reserved first entry.
+const maxObjectsPerLevel = 871
+
 type objectClassItem struct {
 	class int
 }
@@ -0,0 +1,97 @@
+package modes
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/inkyblackness/shocked-client/editor/model"
+
+	dataModel "github.com/inkyblackness/shocked-model"
+)
+
+// objectClipboardFormat identifies clipboard texts that contain level objects.
+const objectClipboardFormat = "shocked-client/objects"
+
+// copiedObject is a level object in the clipboard. Its position is relative to the
+// top left corner of all copied objects, in fine coordinates.
+type copiedObject struct {
+	Class      int                             `json:"class"`
+	X          int                             `json:"x"`
+	Y          int                             `json:"y"`
+	Properties dataModel.LevelObjectProperties `json:"properties"`
+}
+
+type objectClipboardData struct {
+	Format  string         `json:"format"`
+	Objects []copiedObject `json:"objects"`
+}
+
+// copyObjects returns the given objects relative to their top left corner.
+// The corner is returned in fine coordinates.
+func copyObjects(objects []*model.LevelObject) (copied []copiedObject, left, top int) {
+	for index, object := range objects {
+		x, y := object.Center()
+		if (index == 0) || (int(x) < left) {
+			left = int(x)
+		}
+		if (index == 0) || (int(y) < top) {
+			top = int(y)
+		}
+	}
+	for _, object := range objects {
+		x, y := object.Center()
+		copied = append(copied, copiedObject{
+			Class:      object.ID().Class(),
+			X:          int(x) - left,
+			Y:          int(y) - top,
+			Properties: object.Properties()})
+	}
+	return
+}
+
+// encodeObjectClipboard returns the clipboard text for given objects.
+func encodeObjectClipboard(objects []copiedObject) string {
+	data, _ := json.Marshal(objectClipboardData{Format: objectClipboardFormat, Objects: objects})
+	return string(data)
+}
+
+// decodeObjectClipboard returns the objects of the given clipboard text.
+// An error is returned if the text does not contain objects.
+func decodeObjectClipboard(text string) ([]copiedObject, error) {
+	var data objectClipboardData
+	err := json.Unmarshal([]byte(text), &data)
+	if (err == nil) && (data.Format != objectClipboardFormat) {
+		err = fmt.Errorf("unknown format <%v>", data.Format)
+	}
+	if (err == nil) && (len(data.Objects) == 0) {
+		err = fmt.Errorf("no objects")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return data.Objects, nil
+}
+
+// placedObjectProperties returns the properties of the copied object, moved to the given
+// fine coordinates. false is returned if the position is outside of the map.
+func placedObjectProperties(object copiedObject, left, top int) (dataModel.LevelObjectProperties, bool) {
+	properties := object.Properties
+	x, y := left+object.X, top+object.Y
+	position := objectPositionProperties(x, y)
+
+	properties.TileX, properties.FineX = position.TileX, position.FineX
+	properties.TileY, properties.FineY = position.TileY, position.FineY
+	return properties, (x >= 0) && (*position.TileX < 64) && (y >= 0) && (*position.TileY < 64)
+}
+
+// objectPositionProperties returns properties that only contain the position of given fine coordinates.
+func objectPositionProperties(x, y int) (properties dataModel.LevelObjectProperties) {
+	tileX, fineX := x>>8, x&0xFF
+	tileY, fineY := y>>8, y&0xFF
+
+	properties.TileX = &tileX
+	properties.FineX = &fineX
+	properties.TileY = &tileY
+	properties.FineY = &fineY
+	return
+}
@@ -0,0 +1,92 @@
+package modes
+
+import (
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/editor/model"
+	"github.com/inkyblackness/shocked-client/editor/model/memstore"
+
+	dataModel "github.com/inkyblackness/shocked-model"
+)
+
+type ObjectClipboardSuite struct {
+	store   *memstore.DataStore
+	level   *memstore.Level
+	adapter *model.Adapter
+}
+
+var _ = check.Suite(&ObjectClipboardSuite{})
+
+func (suite *ObjectClipboardSuite) SetUpTest(c *check.C) {
+	suite.store = memstore.NewDataStore(nil)
+	suite.level = suite.store.Project("project").AddLevel("archive", 1)
+	suite.adapter = model.NewAdapter(suite.store)
+	suite.adapter.RequestProject("project")
+	suite.store.Flush()
+}
+
+func (suite *ObjectClipboardSuite) givenObjectAt(x, y int, classData []byte) {
+	tileX, fineX, tileY, fineY := x>>8, x&0xFF, y>>8, y&0xFF
+	suite.level.AddObject(7, dataModel.LevelObjectProperties{
+		TileX: &tileX, FineX: &fineX, TileY: &tileY, FineY: &fineY, ClassData: classData})
+}
+
+func (suite *ObjectClipboardSuite) levelObjects() []*model.LevelObject {
+	suite.adapter.RequestActiveLevel(1)
+	suite.store.Flush()
+	level := suite.adapter.ActiveLevel()
+	return []*model.LevelObject{level.LevelObject(1), level.LevelObject(2)}
+}
+
+func (suite *ObjectClipboardSuite) TestCopiedObjectsAreRelativeToTopLeftCorner(c *check.C) {
+	suite.givenObjectAt(0x1080, 0x2340, []byte{1, 2})
+	suite.givenObjectAt(0x1200, 0x2200, []byte{3, 4})
+
+	copied, left, top := copyObjects(suite.levelObjects())
+
+	c.Check([]int{left, top}, check.DeepEquals, []int{0x1080, 0x2200})
+	c.Assert(len(copied), check.Equals, 2)
+	c.Check([]int{copied[0].Class, copied[0].X, copied[0].Y}, check.DeepEquals, []int{7, 0x0000, 0x0140})
+	c.Check([]int{copied[1].Class, copied[1].X, copied[1].Y}, check.DeepEquals, []int{7, 0x0180, 0x0000})
+	c.Check(copied[0].Properties.ClassData, check.DeepEquals, []byte{1, 2})
+	c.Check(copied[1].Properties.ClassData, check.DeepEquals, []byte{3, 4})
+}
+
+func (suite *ObjectClipboardSuite) TestClipboardTextKeepsObjects(c *check.C) {
+	objects := []copiedObject{{Class: 7, X: 0x10, Y: 0x20, Properties: dataModel.LevelObjectProperties{ClassData: []byte{1, 2}}}}
+
+	text := encodeObjectClipboard(objects)
+	decoded, err := decodeObjectClipboard(text)
+
+	c.Check(text, check.Matches, `\{"format":"shocked-client/objects".*`)
+	c.Assert(err, check.IsNil)
+	c.Check(decoded, check.DeepEquals, objects)
+}
+
+func (suite *ObjectClipboardSuite) TestClipboardTextWithoutObjectsIsRejected(c *check.C) {
+	for _, text := range []string{"", `{"format":"shocked-client/tiles","objects":[{}]}`, `{"format":"shocked-client/objects"}`} {
+		_, err := decodeObjectClipboard(text)
+		c.Check(err, check.NotNil, check.Commentf("text <%v>", text))
+	}
+}
+
+func (suite *ObjectClipboardSuite) TestPlacedObjectsKeepRelativePosition(c *check.C) {
+	object := copiedObject{Class: 7, X: 0x0180, Y: 0x0040, Properties: dataModel.LevelObjectProperties{ClassData: []byte{1, 2}}}
+
+	properties, inMap := placedObjectProperties(object, 0x1000, 0x20F0)
+
+	c.Check(inMap, check.Equals, true)
+	c.Check([]int{*properties.TileX, *properties.FineX}, check.DeepEquals, []int{0x11, 0x80})
+	c.Check([]int{*properties.TileY, *properties.FineY}, check.DeepEquals, []int{0x21, 0x30})
+	c.Check(properties.ClassData, check.DeepEquals, []byte{1, 2})
+}
+
+func (suite *ObjectClipboardSuite) TestObjectsPlacedOutsideMapAreReported(c *check.C) {
+	object := copiedObject{X: 0x0100, Y: 0x0000}
+
+	_, eastInMap := placedObjectProperties(object, 0x3F00, 0x1000)
+	_, northInMap := placedObjectProperties(object, 0x1000, -1)
+
+	c.Check(eastInMap, check.Equals, false)
+	c.Check(northInMap, check.Equals, false)
+}
@@ -0,0 +1,197 @@
+package modes
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/inkyblackness/res"
+	"github.com/inkyblackness/res/data/interpreters"
+
+	"github.com/inkyblackness/shocked-client/editor/model"
+)
+
+// objectQueryTermExpression matches one term of an object query, such as "class=barriers", "z>=16" or "lock > 0".
+var objectQueryTermExpression = regexp.MustCompile(`([A-Za-z][\w.]*)\s*(<=|>=|!=|==|=|<|>)\s*([^\s<>=!]+)`)
+
+// objectBaseProperties are the properties every level object has, by their lower case name in a query.
+var objectBaseProperties = map[string]func(object *model.LevelObject) int64{
+	"index":     func(object *model.LevelObject) int64 { return int64(object.Index()) },
+	"class":     func(object *model.LevelObject) int64 { return int64(object.ID().Class()) },
+	"subclass":  func(object *model.LevelObject) int64 { return int64(object.ID().Subclass()) },
+	"type":      func(object *model.LevelObject) int64 { return int64(object.ID().Type()) },
+	"tilex":     func(object *model.LevelObject) int64 { return int64(object.TileX()) },
+	"tiley":     func(object *model.LevelObject) int64 { return int64(object.TileY()) },
+	"z":         func(object *model.LevelObject) int64 { return int64(object.Z()) },
+	"rotationx": func(object *model.LevelObject) int64 { return int64(object.RotationX()) },
+	"rotationy": func(object *model.LevelObject) int64 { return int64(object.RotationY()) },
+	"rotationz": func(object *model.LevelObject) int64 { return int64(object.RotationZ()) },
+	"hitpoints": func(object *model.LevelObject) int64 { return int64(object.Hitpoints()) }}
+
+// objectQueryTerm is a single condition of an object query.
+type objectQueryTerm struct {
+	name    string
+	matches func(value int64) bool
+}
+
+// objectQuery describes which level objects to find. An object matches if it fulfills all terms.
+// Terms refer either to a base property or to a field of the class or extra data.
+// Fields are named by their full path, such as "Puzzle.Block.Layout", or only by their last part.
+type objectQuery struct {
+	terms []objectQueryTerm
+}
+
+// parseObjectQuery parses the given text. Terms are of the form <name><operator><value>, with
+// the operators =, !=, <, <=, > and >=. With =, the value can also be a range, such as "3..5".
+// Classes can be given by the first word of their name.
+func parseObjectQuery(text string) (query objectQuery, err error) {
+	matches := objectQueryTermExpression.FindAllStringSubmatchIndex(text, -1)
+	lastEnd := 0
+
+	for _, match := range matches {
+		if strings.TrimSpace(text[lastEnd:match[0]]) != "" {
+			return objectQuery{}, fmt.Errorf("invalid term <%v>", strings.TrimSpace(text[lastEnd:match[0]]))
+		}
+		lastEnd = match[1]
+		name := strings.ToLower(text[match[2]:match[3]])
+		operator := text[match[4]:match[5]]
+		value := text[match[6]:match[7]]
+		term := objectQueryTerm{name: name}
+
+		term.matches, err = objectQueryComparison(name, operator, value)
+		if err != nil {
+			return objectQuery{}, err
+		}
+		query.terms = append(query.terms, term)
+	}
+	if strings.TrimSpace(text[lastEnd:]) != "" {
+		return objectQuery{}, fmt.Errorf("invalid term <%v>", strings.TrimSpace(text[lastEnd:]))
+	}
+	return
+}
+
+func objectQueryComparison(name, operator, value string) (func(int64) bool, error) {
+	if rangeParts := strings.SplitN(value, "..", 2); len(rangeParts) == 2 {
+		from, fromErr := objectQueryValue(name, rangeParts[0])
+		to, toErr := objectQueryValue(name, rangeParts[1])
+		if (fromErr != nil) || (toErr != nil) || ((operator != "=") && (operator != "==")) {
+			return nil, fmt.Errorf("invalid range <%v%v%v>", name, operator, value)
+		}
+		return func(actual int64) bool { return (actual >= from) && (actual <= to) }, nil
+	}
+	expected, err := objectQueryValue(name, value)
+	if err != nil {
+		return nil, err
+	}
+	comparisons := map[string]func(int64) bool{
+		"=":  func(actual int64) bool { return actual == expected },
+		"==": func(actual int64) bool { return actual == expected },
+		"!=": func(actual int64) bool { return actual != expected },
+		"<":  func(actual int64) bool { return actual < expected },
+		"<=": func(actual int64) bool { return actual <= expected },
+		">":  func(actual int64) bool { return actual > expected },
+		">=": func(actual int64) bool { return actual >= expected }}
+	return comparisons[operator], nil
+}
+
+func objectQueryValue(name, text string) (int64, error) {
+	if name == "class" {
+		for class, className := range classNames {
+			if strings.EqualFold(strings.Fields(className)[0], text) {
+				return int64(class), nil
+			}
+		}
+	}
+	value, err := strconv.ParseInt(text, 0, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value <%v> for <%v>", text, name)
+	}
+	return value, nil
+}
+
+// isEmpty returns true if the query has no terms. Empty queries match all objects.
+func (query objectQuery) isEmpty() bool {
+	return len(query.terms) == 0
+}
+
+// checkFields returns an error for the first term that refers neither to a base property nor to
+// a field of any of the given objects. Field names depend on the objects, so they can not be checked
+// while parsing.
+func (query objectQuery) checkFields(objects []*model.LevelObject, classFactory, extraFactory interpreterFactoryFunc) error {
+	unknown := make(map[string]bool)
+	for _, term := range query.terms {
+		if _, isBase := objectBaseProperties[term.name]; !isBase {
+			unknown[term.name] = true
+		}
+	}
+	for _, object := range objects {
+		if len(unknown) == 0 {
+			break
+		}
+		for name := range objectQueryFields(object, classFactory, extraFactory) {
+			delete(unknown, name)
+		}
+	}
+	for _, term := range query.terms {
+		if unknown[term.name] {
+			return fmt.Errorf("unknown field <%v>", term.name)
+		}
+	}
+	return nil
+}
+
+// filter returns a function that reports whether an object matches the query.
+// The interpreter factories provide the fields of the class and extra data.
+func (query objectQuery) filter(classFactory, extraFactory interpreterFactoryFunc) func(*model.LevelObject) bool {
+	return func(object *model.LevelObject) bool {
+		var fields map[string][]int64
+		for _, term := range query.terms {
+			if baseProperty, isBase := objectBaseProperties[term.name]; isBase {
+				if !term.matches(baseProperty(object)) {
+					return false
+				}
+				continue
+			}
+			if fields == nil {
+				fields = objectQueryFields(object, classFactory, extraFactory)
+			}
+			matched := false
+			for _, value := range fields[term.name] {
+				matched = matched || term.matches(value)
+			}
+			if !matched {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// objectQueryFields returns the values of all fields of the class and extra data of given object.
+// The values are registered under the lower case full path and the lower case last part of each field.
+func objectQueryFields(object *model.LevelObject, classFactory, extraFactory interpreterFactoryFunc) map[string][]int64 {
+	fields := make(map[string][]int64)
+	objID := object.ID()
+	resID := res.MakeObjectID(res.ObjectClass(objID.Class()), res.ObjectSubclass(objID.Subclass()), res.ObjectType(objID.Type()))
+
+	var addFields func(path string, interpreter *interpreters.Instance)
+	addFields = func(path string, interpreter *interpreters.Instance) {
+		for _, key := range interpreter.Keys() {
+			value := int64(interpreter.Get(key))
+			fullPath := strings.ToLower(path + key)
+			fields[fullPath] = append(fields[fullPath], value)
+			if path != "" {
+				shortName := strings.ToLower(key)
+				fields[shortName] = append(fields[shortName], value)
+			}
+		}
+		for _, key := range interpreter.ActiveRefinements() {
+			addFields(path+key+".", interpreter.Refined(key))
+		}
+	}
+	addFields("", classFactory(resID, object.ClassData()))
+	addFields("", extraFactory(resID, object.ExtraData()))
+
+	return fields
+}
@@ -0,0 +1,132 @@
+package modes
+
+import (
+	"github.com/inkyblackness/res/data/levelobj"
+
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/editor/model"
+	"github.com/inkyblackness/shocked-client/editor/model/memstore"
+
+	dataModel "github.com/inkyblackness/shocked-model"
+)
+
+type ObjectQuerySuite struct {
+	store   *memstore.DataStore
+	level   *memstore.Level
+	adapter *model.Adapter
+}
+
+var _ = check.Suite(&ObjectQuerySuite{})
+
+func (suite *ObjectQuerySuite) SetUpTest(c *check.C) {
+	suite.store = memstore.NewDataStore(nil)
+	suite.level = suite.store.Project("project").AddLevel("archive", 1)
+	suite.adapter = model.NewAdapter(suite.store)
+	suite.adapter.RequestProject("project")
+	suite.store.Flush()
+}
+
+func (suite *ObjectQuerySuite) givenAmmoClipInTile(x, y int, z int) int {
+	return suite.level.AddObject(1, dataModel.LevelObjectProperties{TileX: &x, TileY: &y, Z: &z})
+}
+
+func (suite *ObjectQuerySuite) levelObjects() []*model.LevelObject {
+	suite.adapter.RequestActiveLevel(1)
+	suite.store.Flush()
+	return suite.adapter.ActiveLevel().LevelObjects(func(*model.LevelObject) bool { return true })
+}
+
+// matches returns the indices of all objects matching the given query.
+func (suite *ObjectQuerySuite) matches(c *check.C, text string) []int {
+	query, err := parseObjectQuery(text)
+	c.Assert(err, check.IsNil)
+	objects := suite.levelObjects()
+	c.Assert(query.checkFields(objects, levelobj.ForRealWorld, levelobj.RealWorldExtra), check.IsNil)
+	filter := query.filter(levelobj.ForRealWorld, levelobj.RealWorldExtra)
+	indices := []int{}
+	for _, object := range objects {
+		if filter(object) {
+			indices = append(indices, object.Index())
+		}
+	}
+	return indices
+}
+
+func (suite *ObjectQuerySuite) TestEmptyQueryMatchesAllObjects(c *check.C) {
+	suite.givenAmmoClipInTile(10, 10, 2)
+	suite.givenAmmoClipInTile(12, 10, 4)
+
+	query, err := parseObjectQuery("  ")
+
+	c.Assert(err, check.IsNil)
+	c.Check(query.isEmpty(), check.Equals, true)
+	c.Check(suite.matches(c, ""), check.HasLen, 2)
+}
+
+func (suite *ObjectQuerySuite) TestAllTermsMustMatch(c *check.C) {
+	suite.givenAmmoClipInTile(10, 10, 2)
+	second := suite.givenAmmoClipInTile(12, 10, 4)
+	third := suite.givenAmmoClipInTile(14, 10, 8)
+	projectileZ := 8
+	suite.level.AddObject(2, dataModel.LevelObjectProperties{Z: &projectileZ})
+
+	c.Check(suite.matches(c, "class=ammoclips z>=4"), check.DeepEquals, []int{second, third})
+}
+
+func (suite *ObjectQuerySuite) TestTermsCompareByOperator(c *check.C) {
+	first := suite.givenAmmoClipInTile(10, 10, 2)
+	second := suite.givenAmmoClipInTile(12, 10, 4)
+	third := suite.givenAmmoClipInTile(14, 10, 8)
+
+	c.Check(suite.matches(c, "z==4"), check.DeepEquals, []int{second})
+	c.Check(suite.matches(c, "z != 4"), check.DeepEquals, []int{first, third})
+	c.Check(suite.matches(c, "z<4"), check.DeepEquals, []int{first})
+	c.Check(suite.matches(c, "z<=4"), check.DeepEquals, []int{first, second})
+	c.Check(suite.matches(c, "TileX>12"), check.DeepEquals, []int{third})
+	c.Check(suite.matches(c, "z=0x08"), check.DeepEquals, []int{third})
+}
+
+func (suite *ObjectQuerySuite) TestRangesIncludeTheirLimits(c *check.C) {
+	suite.givenAmmoClipInTile(10, 10, 2)
+	second := suite.givenAmmoClipInTile(12, 10, 4)
+	suite.givenAmmoClipInTile(14, 10, 8)
+
+	c.Check(suite.matches(c, "tilex=11..20 z=0..4"), check.DeepEquals, []int{second})
+}
+
+func (suite *ObjectQuerySuite) TestTermsMatchFieldsOfObjectData(c *check.C) {
+	first := suite.level.AddObject(7, blockPuzzleProperties(5))
+	second := suite.level.AddObject(7, blockPuzzleProperties(6))
+	suite.givenAmmoClipInTile(14, 10, 0)
+
+	c.Check(suite.matches(c, "StateStoreObjectIndex=5"), check.DeepEquals, []int{first})
+	c.Check(suite.matches(c, "Puzzle.Block.StateStoreObjectIndex=5..6"), check.DeepEquals, []int{first, second})
+}
+
+func (suite *ObjectQuerySuite) TestUnknownFieldsAreReported(c *check.C) {
+	suite.level.AddObject(7, blockPuzzleProperties(5))
+	query, _ := parseObjectQuery("z>=0 StateStoreObjectIdx=5")
+
+	err := query.checkFields(suite.levelObjects(), levelobj.ForRealWorld, levelobj.RealWorldExtra)
+
+	c.Assert(err, check.NotNil)
+	c.Check(err.Error(), check.Equals, "unknown field <statestoreobjectidx>")
+}
+
+func (suite *ObjectQuerySuite) TestInvalidQueriesAreReported(c *check.C) {
+	expectations := map[string]string{
+		"z>>3":           "invalid term <z>>3>",
+		"z=high":         "invalid value <high> for <z>",
+		"class=weapons2": "invalid value <weapons2> for <class>",
+		"z>2..4":         "invalid range <z>2..4>",
+		"z=2..x":         "invalid range <z=2..x>",
+		"z=2 3":          "invalid term <3>",
+		"=4":             "invalid term <=4>"}
+
+	for text, expected := range expectations {
+		_, err := parseObjectQuery(text)
+		c.Assert(err, check.NotNil, check.Commentf("query <%v>", text))
+		c.Check(err.Error(), check.Equals, expected, check.Commentf("query <%v>", text))
+	}
+}
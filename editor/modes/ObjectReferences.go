@@ -0,0 +1,192 @@
+package modes
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/inkyblackness/res"
+	"github.com/inkyblackness/res/data/interpreters"
+
+	"github.com/inkyblackness/shocked-client/editor/model"
+)
+
+// objectReference is a reference from one level object to another, by index.
+// References from surveillance entries have no referring object. The referenced
+// object is nil if the index does not refer to an existing object.
+type objectReference struct {
+	kind    string
+	from    *model.LevelObject
+	toIndex int
+	to      *model.LevelObject
+}
+
+// outgoingString describes the reference as seen from the referring object.
+func (ref *objectReference) outgoingString() string {
+	return fmt.Sprintf("%v -> %v", ref.kind, objectReferenceTarget(ref.toIndex, ref.to))
+}
+
+// incomingString describes the reference as seen from the referenced object.
+func (ref *objectReference) incomingString() string {
+	if ref.from == nil {
+		return ref.kind
+	}
+	return fmt.Sprintf("%v <- %v", ref.kind, objectReferenceTarget(ref.from.Index(), ref.from))
+}
+
+func objectReferenceTarget(index int, object *model.LevelObject) string {
+	if object == nil {
+		return fmt.Sprintf("%d (missing)", index)
+	}
+	return fmt.Sprintf("%d (%v)", index, object.ID())
+}
+
+// objectReferenceItem is a combo box item for a reference, titled from one side of it.
+type objectReferenceItem struct {
+	reference *objectReference
+	title     string
+}
+
+func (item *objectReferenceItem) String() string {
+	return item.title
+}
+
+// objectReferenceCache keeps the references between the objects of a level. As interpreting the data
+// of all objects is costly, an update only collects the references of the objects that were added or
+// changed since the previous update. The cache must be reset if the interpreters change.
+type objectReferenceCache struct {
+	entries map[int]*objectReferenceEntry
+}
+
+// objectReferenceEntry keeps the references of one object, together with the data they were taken from.
+type objectReferenceEntry struct {
+	object     *model.LevelObject
+	id         model.ObjectID
+	classData  []byte
+	extraData  []byte
+	references []*objectReference
+}
+
+func newObjectReferenceCache() *objectReferenceCache {
+	return &objectReferenceCache{entries: make(map[int]*objectReferenceEntry)}
+}
+
+func (cache *objectReferenceCache) reset() {
+	cache.entries = make(map[int]*objectReferenceEntry)
+}
+
+// update returns all references between the objects of given level.
+// Object references are found in all fields of class and extra data that are described as object
+// index, or that are named like one. Surveillance entries refer to their source and deathwatch objects.
+func (cache *objectReferenceCache) update(level *model.LevelAdapter,
+	classFactory, extraFactory interpreterFactoryFunc) (references []*objectReference) {
+	for index := 0; index < level.ObjectSurveillanceCount(); index++ {
+		source, deathwatch := level.ObjectSurveillanceInfo(index)
+		if source != 0 {
+			references = append(references, &objectReference{
+				kind:    fmt.Sprintf("Surveillance %d source", index),
+				toIndex: source,
+				to:      level.LevelObject(source)})
+		}
+		if deathwatch != 0 {
+			references = append(references, &objectReference{
+				kind:    fmt.Sprintf("Surveillance %d deathwatch", index),
+				toIndex: deathwatch,
+				to:      level.LevelObject(deathwatch)})
+		}
+	}
+
+	objects := allLevelObjects(level)
+	entries := make(map[int]*objectReferenceEntry, len(objects))
+	for _, object := range objects {
+		entry := cache.entries[object.Index()]
+		if (entry == nil) || !entry.isFor(object) {
+			entry = &objectReferenceEntry{
+				object:     object,
+				id:         object.ID(),
+				classData:  object.ClassData(),
+				extraData:  object.ExtraData(),
+				references: objectReferencesOf(object, classFactory, extraFactory)}
+		}
+		entries[object.Index()] = entry
+		for _, ref := range entry.references {
+			ref.to = level.LevelObject(ref.toIndex)
+		}
+		references = append(references, entry.references...)
+	}
+	cache.entries = entries
+
+	return
+}
+
+// isFor returns true if the entry was collected from the current data of given object.
+func (entry *objectReferenceEntry) isFor(object *model.LevelObject) bool {
+	return (entry.object == object) && (entry.id == object.ID()) &&
+		bytes.Equal(entry.classData, object.ClassData()) && bytes.Equal(entry.extraData, object.ExtraData())
+}
+
+// objectReferencesOf returns the references from given object. The referenced objects are not yet resolved.
+func objectReferencesOf(object *model.LevelObject, classFactory, extraFactory interpreterFactoryFunc) (references []*objectReference) {
+	objID := object.ID()
+	resID := res.MakeObjectID(res.ObjectClass(objID.Class()), res.ObjectSubclass(objID.Subclass()), res.ObjectType(objID.Type()))
+	addReferences := func(path string, index int) {
+		if index != 0 {
+			references = append(references, &objectReference{
+				kind:    path,
+				from:    object,
+				toIndex: index})
+		}
+	}
+	var addFields func(path string, interpreter *interpreters.Instance)
+	addFields = func(path string, interpreter *interpreters.Instance) {
+		for _, key := range interpreter.Keys() {
+			if isObjectIndexField(interpreter, key) {
+				addReferences(path+key, int(interpreter.Get(key)))
+			}
+		}
+		for _, key := range interpreter.ActiveRefinements() {
+			addFields(path+key+".", interpreter.Refined(key))
+		}
+	}
+	addFields("", classFactory(resID, object.ClassData()))
+	addFields("Extra.", extraFactory(resID, object.ExtraData()))
+	return
+}
+
+// referencesOfObjects returns the references from and to the given objects. Only references to
+// existing objects are incoming.
+func referencesOfObjects(references []*objectReference, objects []*model.LevelObject) (outgoing, incoming []*objectReference) {
+	selected := make(map[int]bool)
+	for _, object := range objects {
+		selected[object.Index()] = true
+	}
+	for _, ref := range references {
+		if (ref.from != nil) && selected[ref.from.Index()] {
+			outgoing = append(outgoing, ref)
+		}
+		if selected[ref.toIndex] && (ref.to != nil) {
+			incoming = append(incoming, ref)
+		}
+	}
+	return
+}
+
+func isObjectIndexField(interpreter *interpreters.Instance, key string) bool {
+	isIndex := strings.Contains(key, "ObjectIndex")
+	simplifier := interpreters.NewSimplifier(func(minValue, maxValue int64, formatter interpreters.RawValueFormatter) {})
+	simplifier.SetObjectIndexHandler(func() { isIndex = true })
+	interpreter.Describe(key, simplifier)
+	return isIndex
+}
+
+// Ends implements the display.Arrow interface. It must only be called for references between existing objects.
+func (ref *objectReference) Ends() (fromX, fromY, toX, toY float32) {
+	fromX, fromY = ref.from.Center()
+	toX, toY = ref.to.Center()
+	return
+}
+
+// isBetweenObjects returns true if both the referring and the referenced objects exist.
+func (ref *objectReference) isBetweenObjects() bool {
+	return (ref.from != nil) && (ref.to != nil)
+}
@@ -0,0 +1,170 @@
+package modes
+
+import (
+	"fmt"
+
+	"github.com/inkyblackness/res"
+	"github.com/inkyblackness/res/data/interpreters"
+	"github.com/inkyblackness/res/data/levelobj"
+
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/editor/model"
+	"github.com/inkyblackness/shocked-client/editor/model/memstore"
+
+	dataModel "github.com/inkyblackness/shocked-model"
+)
+
+type ObjectReferencesSuite struct {
+	store   *memstore.DataStore
+	level   *memstore.Level
+	adapter *model.Adapter
+}
+
+var _ = check.Suite(&ObjectReferencesSuite{})
+
+func (suite *ObjectReferencesSuite) SetUpTest(c *check.C) {
+	suite.store = memstore.NewDataStore(nil)
+	suite.level = suite.store.Project("project").AddLevel("archive", 1)
+	suite.adapter = model.NewAdapter(suite.store)
+	suite.adapter.RequestProject("project")
+	suite.store.Flush()
+}
+
+func (suite *ObjectReferencesSuite) givenStateStore() int {
+	subclass, objType := 0, 1
+	return suite.level.AddObject(12, dataModel.LevelObjectProperties{Subclass: &subclass, Type: &objType})
+}
+
+func (suite *ObjectReferencesSuite) givenBlockPuzzle(stateStoreIndex int) int {
+	return suite.level.AddObject(7, blockPuzzleProperties(stateStoreIndex))
+}
+
+func (suite *ObjectReferencesSuite) references() []*objectReference {
+	suite.adapter.RequestActiveLevel(1)
+	suite.store.Flush()
+	return newObjectReferenceCache().update(suite.adapter.ActiveLevel(), levelobj.ForRealWorld, levelobj.RealWorldExtra)
+}
+
+// stateStoreReferences returns the references of the block puzzle state fields.
+func stateStoreReferences(references []*objectReference) (result []*objectReference) {
+	for _, ref := range references {
+		if ref.kind == "Puzzle.Block.StateStoreObjectIndex" {
+			result = append(result, ref)
+		}
+	}
+	return
+}
+
+func (suite *ObjectReferencesSuite) TestReferencesAreTakenFromObjectIndexFields(c *check.C) {
+	stateStoreIndex := suite.givenStateStore()
+	puzzleIndex := suite.givenBlockPuzzle(stateStoreIndex)
+
+	references := stateStoreReferences(suite.references())
+
+	c.Assert(len(references), check.Equals, 1)
+	ref := references[0]
+	c.Check(ref.from.Index(), check.Equals, puzzleIndex)
+	c.Check(ref.toIndex, check.Equals, stateStoreIndex)
+	c.Assert(ref.to, check.NotNil)
+	c.Check(ref.to.Index(), check.Equals, stateStoreIndex)
+	c.Check(ref.isBetweenObjects(), check.Equals, true)
+	c.Check(ref.outgoingString(), check.Equals,
+		fmt.Sprintf("Puzzle.Block.StateStoreObjectIndex -> %d (12/0/ 1)", stateStoreIndex))
+	c.Check(ref.incomingString(), check.Equals,
+		fmt.Sprintf("Puzzle.Block.StateStoreObjectIndex <- %d (%v)", puzzleIndex, ref.from.ID()))
+}
+
+func (suite *ObjectReferencesSuite) TestReferencesToMissingObjectsAreKept(c *check.C) {
+	suite.givenBlockPuzzle(200)
+
+	references := stateStoreReferences(suite.references())
+
+	c.Assert(len(references), check.Equals, 1)
+	c.Check(references[0].toIndex, check.Equals, 200)
+	c.Check(references[0].to, check.IsNil)
+	c.Check(references[0].isBetweenObjects(), check.Equals, false)
+	c.Check(references[0].outgoingString(), check.Equals, "Puzzle.Block.StateStoreObjectIndex -> 200 (missing)")
+}
+
+func (suite *ObjectReferencesSuite) TestIndexZeroIsNoReference(c *check.C) {
+	suite.givenBlockPuzzle(0)
+
+	c.Check(len(stateStoreReferences(suite.references())), check.Equals, 0)
+}
+
+func (suite *ObjectReferencesSuite) TestSurveillanceEntriesReferToSourceAndDeathwatch(c *check.C) {
+	stateStoreIndex := suite.givenStateStore()
+	source, deathwatch := stateStoreIndex, 123
+	unused := 0
+	suite.level.SetSurveillance(2, dataModel.SurveillanceObject{SourceIndex: &source, DeathwatchIndex: &deathwatch})
+	suite.level.SetSurveillance(3, dataModel.SurveillanceObject{SourceIndex: &unused, DeathwatchIndex: &unused})
+
+	references := suite.references()
+
+	c.Assert(len(references), check.Equals, 2)
+	c.Check(references[0].from, check.IsNil)
+	c.Check(references[0].to.Index(), check.Equals, stateStoreIndex)
+	c.Check(references[0].incomingString(), check.Equals, "Surveillance 2 source")
+	c.Check(references[1].to, check.IsNil)
+	c.Check(references[1].outgoingString(), check.Equals, "Surveillance 2 deathwatch -> 123 (missing)")
+}
+
+func (suite *ObjectReferencesSuite) TestReferencesOfObjectsAreSplitByDirection(c *check.C) {
+	stateStoreIndex := suite.givenStateStore()
+	puzzleIndex := suite.givenBlockPuzzle(stateStoreIndex)
+	suite.givenBlockPuzzle(200)
+	references := stateStoreReferences(suite.references())
+	level := suite.adapter.ActiveLevel()
+
+	outgoing, incoming := referencesOfObjects(references, []*model.LevelObject{level.LevelObject(puzzleIndex)})
+	c.Check(outgoing, check.DeepEquals, references[0:1])
+	c.Check(len(incoming), check.Equals, 0)
+
+	outgoing, incoming = referencesOfObjects(references, []*model.LevelObject{level.LevelObject(stateStoreIndex)})
+	c.Check(len(outgoing), check.Equals, 0)
+	c.Check(incoming, check.DeepEquals, references[0:1])
+}
+
+func (suite *ObjectReferencesSuite) TestUpdateOnlyInterpretsChangedObjects(c *check.C) {
+	stateStoreIndex := suite.givenStateStore()
+	puzzleIndex := suite.givenBlockPuzzle(stateStoreIndex)
+	suite.adapter.RequestActiveLevel(1)
+	suite.store.Flush()
+	level := suite.adapter.ActiveLevel()
+	var interpreted []int
+	classFactory := func(id res.ObjectID, data []byte) *interpreters.Instance {
+		interpreted = append(interpreted, int(id.Class))
+		return levelobj.ForRealWorld(id, data)
+	}
+	cache := newObjectReferenceCache()
+	cache.update(level, classFactory, levelobj.RealWorldExtra)
+	interpreted = nil
+	classData := append([]byte{}, level.LevelObject(puzzleIndex).ClassData()...)
+	classData[len(classData)-1]++
+	level.RequestObjectPropertiesChange([]int{puzzleIndex}, &dataModel.LevelObjectProperties{ClassData: classData})
+	suite.store.Flush()
+
+	references := stateStoreReferences(cache.update(level, classFactory, levelobj.RealWorldExtra))
+
+	c.Check(interpreted, check.DeepEquals, []int{7})
+	c.Assert(len(references), check.Equals, 1)
+	c.Check(references[0].to.Index(), check.Equals, stateStoreIndex)
+}
+
+func (suite *ObjectReferencesSuite) TestUpdateResolvesReferencesToAddedObjects(c *check.C) {
+	suite.givenBlockPuzzle(2)
+	suite.adapter.RequestActiveLevel(1)
+	suite.store.Flush()
+	level := suite.adapter.ActiveLevel()
+	cache := newObjectReferenceCache()
+	c.Assert(stateStoreReferences(cache.update(level, levelobj.ForRealWorld, levelobj.RealWorldExtra))[0].to, check.IsNil)
+	level.RequestNewObjectWithProperties(12, dataModel.LevelObjectProperties{}, nil, nil)
+	suite.store.Flush()
+	c.Assert(level.LevelObject(2), check.NotNil)
+
+	references := stateStoreReferences(cache.update(level, levelobj.ForRealWorld, levelobj.RealWorldExtra))
+
+	c.Assert(len(references), check.Equals, 1)
+	c.Check(references[0].to, check.NotNil)
+}
@@ -0,0 +1,325 @@
+package modes
+
+import (
+	"fmt"
+
+	"github.com/inkyblackness/shocked-client/editor/model"
+	"github.com/inkyblackness/shocked-client/graphics"
+	"github.com/inkyblackness/shocked-client/graphics/controls"
+	"github.com/inkyblackness/shocked-client/ui"
+	"github.com/inkyblackness/shocked-client/ui/events"
+
+	dataModel "github.com/inkyblackness/shocked-model"
+)
+
+// ProjectsMode is a mode to switch between the open projects, and to copy resources
+// from another project into the active one. Copies are not part of the undo history.
+type ProjectsMode struct {
+	context      Context
+	modelAdapter *model.Adapter
+
+	area *ui.Area
+
+	projectLabel      *controls.Label
+	projectBox        *controls.ComboBox
+	accessTitle       *controls.Label
+	accessInfo        *controls.Label
+	sourceLabel       *controls.Label
+	sourceBox         *controls.ComboBox
+	copyTitle         *controls.Label
+	levelTitle        *controls.Label
+	levelInfo         *controls.Label
+	levelCopyLabel    *controls.Label
+	levelCopyButton   *controls.TextButton
+	textureLabel      *controls.Label
+	textureSlider     *controls.Slider
+	textureCopyLabel  *controls.Label
+	textureCopyButton *controls.TextButton
+	objectLabel       *controls.Label
+	objectBox         *controls.ComboBox
+	objectCopyLabel   *controls.Label
+	objectCopyButton  *controls.TextButton
+	textTypeLabel     *controls.Label
+	textTypeBox       *controls.ComboBox
+	textTypeItems     enumItems
+	textIDLabel       *controls.Label
+	textIDSlider      *controls.Slider
+	textCopyLabel     *controls.Label
+	textCopyButton    *controls.TextButton
+
+	sourceProjectID    string
+	selectedTextureID  int
+	selectedObjectID   model.ObjectID
+	selectedObjectItem controls.ComboBoxItem
+	selectedTextType   dataModel.ResourceType
+	selectedTextID     int
+}
+
+// NewProjectsMode returns a new instance.
+func NewProjectsMode(context Context, parent *ui.Area) *ProjectsMode {
+	mode := &ProjectsMode{
+		context:      context,
+		modelAdapter: context.ModelAdapter()}
+
+	scaled := func(value float32) float32 {
+		return value * context.ControlFactory().Scale()
+	}
+
+	{
+		minRight := ui.NewOffsetAnchor(parent.Left(), scaled(100))
+		maxRight := ui.NewRelativeAnchor(parent.Left(), parent.Right(), 0.5)
+		builder := ui.NewAreaBuilder()
+		builder.SetParent(parent)
+		builder.SetLeft(ui.NewOffsetAnchor(parent.Left(), 0))
+		builder.SetTop(ui.NewOffsetAnchor(parent.Top(), 0))
+		builder.SetRight(ui.NewLimitedAnchor(minRight, maxRight, ui.NewOffsetAnchor(parent.Left(), scaled(400))))
+		builder.SetBottom(ui.NewOffsetAnchor(parent.Bottom(), 0))
+		builder.SetVisible(false)
+		builder.OnRender(func(area *ui.Area) {
+			context.ForGraphics().RectangleRenderer().Fill(
+				area.Left().Value(), area.Top().Value(), area.Right().Value(), area.Bottom().Value(),
+				graphics.RGBA(0.7, 0.0, 0.7, 0.3))
+		})
+		builder.OnEvent(events.MouseMoveEventType, ui.SilentConsumer)
+		builder.OnEvent(events.MouseButtonUpEventType, ui.SilentConsumer)
+		builder.OnEvent(events.MouseButtonDownEventType, ui.SilentConsumer)
+		builder.OnEvent(events.MouseButtonClickedEventType, ui.SilentConsumer)
+		builder.OnEvent(events.MouseScrollEventType, ui.SilentConsumer)
+		mode.area = builder.Build()
+	}
+	{
+		panelBuilder := newControlPanelBuilder(mode.area, context.ControlFactory())
+
+		mode.projectLabel, mode.projectBox = panelBuilder.addComboProperty("Project", mode.onProjectSelected)
+		mode.accessTitle, mode.accessInfo = panelBuilder.addInfo("Access")
+		mode.sourceLabel, mode.sourceBox = panelBuilder.addComboProperty("Copy From", mode.onSourceSelected)
+
+		mode.copyTitle = panelBuilder.addTitle("Copy to active project")
+		mode.levelTitle, mode.levelInfo = panelBuilder.addInfo("Level")
+		mode.levelCopyLabel, mode.levelCopyButton = panelBuilder.addTextButton("Copy Level", "Copy", mode.copyLevel)
+		mode.textureLabel, mode.textureSlider = panelBuilder.addSliderProperty("Texture", func(newValue int64) {
+			mode.selectedTextureID = int(newValue)
+		})
+		mode.textureCopyLabel, mode.textureCopyButton = panelBuilder.addTextButton("Copy Texture", "Copy", mode.copyTexture)
+		mode.objectLabel, mode.objectBox = panelBuilder.addComboProperty("Object", mode.onObjectSelected)
+		mode.objectCopyLabel, mode.objectCopyButton = panelBuilder.addTextButton("Copy Object", "Copy", mode.copyObject)
+		mode.textTypeLabel, mode.textTypeBox = panelBuilder.addComboProperty("Text Type", mode.onTextTypeSelected)
+		mode.textTypeItems = gameTextTypeItems()
+		mode.textTypeBox.SetItems(mode.textTypeItems.forComboBox())
+		mode.textIDLabel, mode.textIDSlider = panelBuilder.addSliderProperty("Text ID", func(newValue int64) {
+			mode.selectedTextID = int(newValue)
+		})
+		mode.textCopyLabel, mode.textCopyButton = panelBuilder.addTextButton("Copy Text", "Copy", mode.copyText)
+	}
+
+	mode.setTextType(mode.textTypeItems[0])
+	mode.modelAdapter.OnProjectChanged(mode.onProjectChanged)
+	mode.modelAdapter.ActiveLevel().OnIDChanged(mode.onLevelChanged)
+	mode.modelAdapter.TextureAdapter().OnGameTexturesChanged(mode.onTexturesChanged)
+	mode.modelAdapter.ObjectsAdapter().OnObjectsChanged(mode.onObjectsChanged)
+	mode.onLevelChanged()
+
+	return mode
+}
+
+// SetActive implements the Mode interface.
+func (mode *ProjectsMode) SetActive(active bool) {
+	mode.area.SetVisible(active)
+}
+
+func (mode *ProjectsMode) onProjectSelected(item controls.ComboBoxItem) {
+	projectID := item.(string)
+	if projectID != mode.modelAdapter.ActiveProjectID() {
+		mode.modelAdapter.RequestProject(projectID)
+		mode.modelAdapter.SetMessage(fmt.Sprintf("Switched to project %s", projectID))
+	}
+}
+
+func (mode *ProjectsMode) onProjectChanged() {
+	activeProjectID := mode.modelAdapter.ActiveProjectID()
+	projectItems := []controls.ComboBoxItem{}
+	sourceItems := []controls.ComboBoxItem{}
+	var selectedSource controls.ComboBoxItem
+
+	for _, projectID := range mode.modelAdapter.AvailableProjectIDs() {
+		projectItems = append(projectItems, projectID)
+		if projectID != activeProjectID {
+			sourceItems = append(sourceItems, projectID)
+			if (selectedSource == nil) || (projectID == mode.sourceProjectID) {
+				selectedSource = projectID
+			}
+		}
+	}
+	mode.projectBox.SetItems(projectItems)
+	mode.projectBox.SetSelectedItem(activeProjectID)
+	if mode.modelAdapter.IsProjectReadOnly(activeProjectID) {
+		mode.accessInfo.SetText("Read-only")
+	} else {
+		mode.accessInfo.SetText("Modifiable")
+	}
+	mode.sourceBox.SetItems(sourceItems)
+	mode.sourceBox.SetSelectedItem(selectedSource)
+	mode.sourceProjectID, _ = selectedSource.(string)
+}
+
+func (mode *ProjectsMode) onSourceSelected(item controls.ComboBoxItem) {
+	mode.sourceProjectID = item.(string)
+}
+
+func (mode *ProjectsMode) onLevelChanged() {
+	levelID := mode.modelAdapter.ActiveLevel().ID()
+	if levelID >= 0 {
+		mode.levelInfo.SetText(fmt.Sprintf("%d", levelID))
+	} else {
+		mode.levelInfo.SetText("(none)")
+	}
+}
+
+func (mode *ProjectsMode) onTexturesChanged() {
+	count := mode.modelAdapter.TextureAdapter().WorldTextureCount()
+	if count > 0 {
+		mode.textureSlider.SetRange(0, int64(count)-1)
+		if mode.selectedTextureID >= count {
+			mode.selectedTextureID = 0
+		}
+		mode.textureSlider.SetValue(int64(mode.selectedTextureID))
+	} else {
+		mode.textureSlider.SetValueUndefined()
+	}
+}
+
+func (mode *ProjectsMode) onObjectsChanged() {
+	objects := mode.modelAdapter.ObjectsAdapter().Objects()
+	items := make([]controls.ComboBoxItem, len(objects))
+	var selectedItem controls.ComboBoxItem
+
+	for index, object := range objects {
+		item := &objectTypeItem{object.ID(), object.DisplayName()}
+		items[index] = item
+		if object.ID() == mode.selectedObjectID {
+			selectedItem = item
+		}
+	}
+	mode.objectBox.SetItems(items)
+	mode.objectBox.SetSelectedItem(selectedItem)
+	mode.selectedObjectItem = selectedItem
+}
+
+func (mode *ProjectsMode) onObjectSelected(item controls.ComboBoxItem) {
+	mode.selectedObjectItem = item
+	mode.selectedObjectID = item.(*objectTypeItem).id
+}
+
+func (mode *ProjectsMode) onTextTypeSelected(item controls.ComboBoxItem) {
+	mode.setTextType(item.(*enumItem))
+}
+
+func (mode *ProjectsMode) setTextType(item *enumItem) {
+	mode.selectedTextType = dataModel.ResourceType(item.value)
+	mode.selectedTextID = 0
+	mode.textTypeBox.SetSelectedItem(item)
+	mode.textIDSlider.SetRange(0, int64(dataModel.MaxEntriesFor(mode.selectedTextType))-1)
+	mode.textIDSlider.SetValue(0)
+}
+
+// canCopy returns true if there is a project to copy from, and the active project accepts copies.
+func (mode *ProjectsMode) canCopy() bool {
+	activeProjectID := mode.modelAdapter.ActiveProjectID()
+	if mode.modelAdapter.IsProjectReadOnly(activeProjectID) {
+		mode.modelAdapter.SetMessage(fmt.Sprintf("Project %s is read-only", activeProjectID))
+		return false
+	}
+	if mode.sourceProjectID == "" {
+		mode.modelAdapter.SetMessage("No other project to copy from")
+		return false
+	}
+	return true
+}
+
+func (mode *ProjectsMode) copyLevel() {
+	levelID := mode.modelAdapter.ActiveLevel().ID()
+	if levelID < 0 {
+		mode.modelAdapter.SetMessage("No level to copy")
+		return
+	}
+	if mode.canCopy() {
+		sourceProjectID := mode.sourceProjectID
+		mode.modelAdapter.RequestLevelCopy(sourceProjectID, levelID, func(before, after *model.LevelFile) {
+			mode.context.Perform(levelImportCommand{
+				modelAdapter: mode.modelAdapter,
+				levelID:      levelID,
+				source:       fmt.Sprintf("level %d of %s", levelID, sourceProjectID),
+				before:       before,
+				after:        after})
+		})
+	}
+}
+
+func (mode *ProjectsMode) copyTexture() {
+	if mode.canCopy() {
+		sourceProjectID, textureID := mode.sourceProjectID, mode.selectedTextureID
+		mode.modelAdapter.RequestTextureCopy(sourceProjectID, textureID, mode.resourceCopyPerformer(
+			fmt.Sprintf("texture %d", textureID), sourceProjectID))
+	}
+}
+
+func (mode *ProjectsMode) copyObject() {
+	if mode.selectedObjectItem == nil {
+		mode.modelAdapter.SetMessage("No object to copy")
+		return
+	}
+	if mode.canCopy() {
+		sourceProjectID, objectID := mode.sourceProjectID, mode.selectedObjectID
+		mode.modelAdapter.RequestGameObjectCopy(sourceProjectID, objectID, mode.resourceCopyPerformer(
+			fmt.Sprintf("object %v", objectID), sourceProjectID))
+	}
+}
+
+func (mode *ProjectsMode) copyText() {
+	if mode.canCopy() {
+		sourceProjectID, textID := mode.sourceProjectID, mode.selectedTextID
+		mode.modelAdapter.RequestTextCopy(sourceProjectID, mode.selectedTextType, textID, mode.resourceCopyPerformer(
+			fmt.Sprintf("text %d", textID), sourceProjectID))
+	}
+}
+
+// resourceCopyPerformer returns a function performing a read copy as command.
+func (mode *ProjectsMode) resourceCopyPerformer(resource string, sourceProjectID string) func(model.ResourceCopy) {
+	return func(resourceCopy model.ResourceCopy) {
+		mode.context.Perform(resourceCopyCommand{
+			modelAdapter:    mode.modelAdapter,
+			resource:        resource,
+			sourceProjectID: sourceProjectID,
+			resourceCopy:    resourceCopy})
+	}
+}
+
+// resourceCopyCommand writes a resource copied from another project. When undone, the resource
+// the active project had before is written again.
+type resourceCopyCommand struct {
+	modelAdapter    *model.Adapter
+	resource        string
+	sourceProjectID string
+	resourceCopy    model.ResourceCopy
+}
+
+// Do writes the copied resource.
+func (command resourceCopyCommand) Do() error {
+	command.resourceCopy.Apply(func() {
+		command.modelAdapter.SetMessage(fmt.Sprintf("Copied %s from %s", command.resource, command.sourceProjectID))
+	})
+	return nil
+}
+
+// Undo writes the previous resource.
+func (command resourceCopyCommand) Undo() error {
+	command.resourceCopy.Revert(func() {
+		command.modelAdapter.SetMessage(fmt.Sprintf("Restored %s", command.resource))
+	})
+	return nil
+}
+
+// Description returns a text of the change.
+func (command resourceCopyCommand) Description() string {
+	return fmt.Sprintf("Copy %s from %s", command.resource, command.sourceProjectID)
+}
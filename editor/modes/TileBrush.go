@@ -0,0 +1,172 @@
+package modes
+
+import (
+	"reflect"
+
+	"github.com/inkyblackness/shocked-client/editor/model"
+
+	dataModel "github.com/inkyblackness/shocked-model"
+)
+
+// tileBrushTool determines which tiles a brush stroke affects.
+type tileBrushTool int
+
+const (
+	noBrushTool tileBrushTool = iota
+	pencilBrushTool
+	rectangleBrushTool
+	lineBrushTool
+	floodFillBrushTool
+)
+
+var tileBrushToolNames = []string{"None", "Pencil", "Rectangle", "Line", "Flood Fill"}
+
+// tileBrush describes the properties a brush stroke applies to the tiles.
+// The textures are only applied if they have been picked from a tile.
+type tileBrush struct {
+	tileType      dataModel.TileType
+	floorHeight   dataModel.HeightUnit
+	ceilingHeight dataModel.HeightUnit
+
+	hasTextures    bool
+	floorTexture   int
+	ceilingTexture int
+	wallTexture    int
+}
+
+// defaultTileBrush returns the brush a level map starts with. It opens tiles.
+func defaultTileBrush() tileBrush {
+	return tileBrush{tileType: dataModel.Open, floorHeight: 0, ceilingHeight: 0}
+}
+
+// pick returns a brush with the properties of given tile.
+func (brush tileBrush) pick(properties *dataModel.TileProperties) tileBrush {
+	brush.tileType = *properties.Type
+	brush.floorHeight = *properties.FloorHeight
+	brush.ceilingHeight = *properties.CeilingHeight
+	brush.hasTextures = properties.RealWorld != nil
+	if brush.hasTextures {
+		brush.floorTexture = *properties.RealWorld.FloorTexture
+		brush.ceilingTexture = *properties.RealWorld.CeilingTexture
+		brush.wallTexture = *properties.RealWorld.WallTexture
+	}
+	return brush
+}
+
+// applyTo returns the given properties, modified by the brush.
+func (brush tileBrush) applyTo(properties dataModel.TileProperties) dataModel.TileProperties {
+	tileType := brush.tileType
+	floorHeight := brush.floorHeight
+	ceilingHeight := brush.ceilingHeight
+
+	properties.Type = &tileType
+	properties.FloorHeight = &floorHeight
+	properties.CeilingHeight = &ceilingHeight
+	if brush.hasTextures && (properties.RealWorld != nil) {
+		realWorld := *properties.RealWorld
+		floorTexture, ceilingTexture, wallTexture := brush.floorTexture, brush.ceilingTexture, brush.wallTexture
+		realWorld.FloorTexture = &floorTexture
+		realWorld.CeilingTexture = &ceilingTexture
+		realWorld.WallTexture = &wallTexture
+		properties.RealWorld = &realWorld
+	}
+	return properties
+}
+
+func isTileOnMap(x, y int) bool {
+	return (x >= 0) && (x < 64) && (y >= 0) && (y < 64)
+}
+
+// tileRectangle returns all tiles within the rectangle spanned by the two given corners.
+func tileRectangle(from, to model.TileCoordinate) (tiles []model.TileCoordinate) {
+	fromX, fromY := from.XY()
+	toX, toY := to.XY()
+	if toX < fromX {
+		fromX, toX = toX, fromX
+	}
+	if toY < fromY {
+		fromY, toY = toY, fromY
+	}
+	for y := fromY; y <= toY; y++ {
+		for x := fromX; x <= toX; x++ {
+			tiles = append(tiles, model.TileCoordinateOf(x, y))
+		}
+	}
+	return
+}
+
+// tileLine returns the tiles on the straight line between the two given tiles, using Bresenham's algorithm.
+func tileLine(from, to model.TileCoordinate) (tiles []model.TileCoordinate) {
+	abs := func(value int) int {
+		if value < 0 {
+			return -value
+		}
+		return value
+	}
+	sign := func(value int) int {
+		if value < 0 {
+			return -1
+		}
+		return 1
+	}
+	x, y := from.XY()
+	toX, toY := to.XY()
+	dx, dy := abs(toX-x), -abs(toY-y)
+	stepX, stepY := sign(toX-x), sign(toY-y)
+	err := dx + dy
+
+	for {
+		tiles = append(tiles, model.TileCoordinateOf(x, y))
+		if (x == toX) && (y == toY) {
+			return
+		}
+		doubledErr := 2 * err
+		if doubledErr >= dy {
+			err += dy
+			x += stepX
+		}
+		if doubledErr <= dx {
+			err += dx
+			y += stepY
+		}
+	}
+}
+
+// floodFillTiles returns the start tile and all tiles connected to it that have the same properties.
+// Tiles are connected if they share an edge. The calculated wall heights are not compared.
+func floodFillTiles(tileMap *model.TileMap, start model.TileCoordinate, cyberspace bool) (tiles []model.TileCoordinate) {
+	propertiesOf := func(coord model.TileCoordinate) *dataModel.TileProperties {
+		if x, y := coord.XY(); isTileOnMap(x, y) {
+			return tileMap.Tile(coord).Properties()
+		}
+		return nil
+	}
+	startProperties := propertiesOf(start)
+	if startProperties == nil {
+		return
+	}
+	reference := pastableTileProperties(*startProperties, cyberspace)
+	visited := map[model.TileCoordinate]bool{start: true}
+	pending := []model.TileCoordinate{start}
+
+	for len(pending) > 0 {
+		coord := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		tiles = append(tiles, coord)
+
+		x, y := coord.XY()
+		for _, neighbour := range []model.TileCoordinate{
+			model.TileCoordinateOf(x-1, y), model.TileCoordinateOf(x+1, y),
+			model.TileCoordinateOf(x, y-1), model.TileCoordinateOf(x, y+1)} {
+			if visited[neighbour] {
+				continue
+			}
+			visited[neighbour] = true
+			if properties := propertiesOf(neighbour); (properties != nil) &&
+				reflect.DeepEqual(pastableTileProperties(*properties, cyberspace), reference) {
+				pending = append(pending, neighbour)
+			}
+		}
+	}
+	return
+}
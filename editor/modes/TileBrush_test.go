@@ -0,0 +1,113 @@
+package modes
+
+import (
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/editor/model"
+	"github.com/inkyblackness/shocked-client/editor/model/memstore"
+
+	dataModel "github.com/inkyblackness/shocked-model"
+)
+
+type TileBrushSuite struct {
+	store   *memstore.DataStore
+	level   *memstore.Level
+	adapter *model.Adapter
+}
+
+var _ = check.Suite(&TileBrushSuite{})
+
+func (suite *TileBrushSuite) SetUpTest(c *check.C) {
+	suite.store = memstore.NewDataStore(nil)
+	suite.level = suite.store.Project("project").AddLevel("archive", 1)
+	suite.adapter = model.NewAdapter(suite.store)
+	suite.adapter.RequestProject("project")
+	suite.store.Flush()
+}
+
+func (suite *TileBrushSuite) givenOpenTile(x, y int, floorHeight int) {
+	tileType := dataModel.Open
+	height := dataModel.HeightUnit(floorHeight)
+	suite.level.SetTile(x, y, dataModel.TileProperties{Type: &tileType, FloorHeight: &height})
+}
+
+func (suite *TileBrushSuite) tileMap() *model.TileMap {
+	suite.adapter.RequestActiveLevel(1)
+	suite.store.Flush()
+	return suite.adapter.ActiveLevel().TileMap()
+}
+
+func texturedTile(tileType dataModel.TileType, floorHeight int, floorTexture, ceilingTexture, wallTexture int) dataModel.TileProperties {
+	floor, ceiling := dataModel.HeightUnit(floorHeight), dataModel.HeightUnit(0)
+	return dataModel.TileProperties{Type: &tileType, FloorHeight: &floor, CeilingHeight: &ceiling,
+		RealWorld: &dataModel.RealWorldTileProperties{
+			FloorTexture: &floorTexture, CeilingTexture: &ceilingTexture, WallTexture: &wallTexture}}
+}
+
+func (suite *TileBrushSuite) TestDefaultBrushOpensTiles(c *check.C) {
+	properties := defaultTileBrush().applyTo(texturedTile(dataModel.Solid, 5, 1, 2, 3))
+
+	c.Check(*properties.Type, check.Equals, dataModel.Open)
+	c.Check(*properties.FloorHeight, check.Equals, dataModel.HeightUnit(0))
+	c.Check(*properties.RealWorld.FloorTexture, check.Equals, 1)
+}
+
+func (suite *TileBrushSuite) TestPickedBrushAppliesPropertiesAndTextures(c *check.C) {
+	picked := texturedTile(dataModel.DiagonalOpenNorthEast, 7, 10, 11, 12)
+	brush := defaultTileBrush().pick(&picked)
+
+	properties := brush.applyTo(texturedTile(dataModel.Solid, 0, 1, 2, 3))
+
+	c.Check(*properties.Type, check.Equals, dataModel.DiagonalOpenNorthEast)
+	c.Check(*properties.FloorHeight, check.Equals, dataModel.HeightUnit(7))
+	c.Check([]int{*properties.RealWorld.FloorTexture, *properties.RealWorld.CeilingTexture, *properties.RealWorld.WallTexture},
+		check.DeepEquals, []int{10, 11, 12})
+}
+
+func (suite *TileBrushSuite) TestApplyingBrushKeepsGivenProperties(c *check.C) {
+	picked := texturedTile(dataModel.Open, 7, 10, 11, 12)
+	brush := defaultTileBrush().pick(&picked)
+	original := texturedTile(dataModel.Solid, 0, 1, 2, 3)
+
+	brush.applyTo(original)
+
+	c.Check(*original.Type, check.Equals, dataModel.Solid)
+	c.Check(*original.RealWorld.FloorTexture, check.Equals, 1)
+}
+
+func (suite *TileBrushSuite) TestRectangleContainsAllTilesBetweenCorners(c *check.C) {
+	tiles := tileRectangle(model.TileCoordinateOf(3, 5), model.TileCoordinateOf(2, 4))
+
+	c.Check(tiles, check.DeepEquals, tileCoordinates(2, 4, 3, 4, 2, 5, 3, 5))
+}
+
+func (suite *TileBrushSuite) TestLineContainsDiagonalTiles(c *check.C) {
+	tiles := tileLine(model.TileCoordinateOf(10, 20), model.TileCoordinateOf(13, 17))
+
+	c.Check(tiles, check.DeepEquals, tileCoordinates(10, 20, 11, 19, 12, 18, 13, 17))
+}
+
+func (suite *TileBrushSuite) TestLineContainsEachTileAlongItsLongerAxis(c *check.C) {
+	tiles := tileLine(model.TileCoordinateOf(0, 0), model.TileCoordinateOf(4, 1))
+
+	c.Check(tiles, check.DeepEquals, tileCoordinates(0, 0, 1, 0, 2, 1, 3, 1, 4, 1))
+}
+
+func (suite *TileBrushSuite) TestFloodFillContainsConnectedEqualTiles(c *check.C) {
+	suite.givenOpenTile(10, 10, 5)
+	suite.givenOpenTile(11, 10, 5)
+	suite.givenOpenTile(11, 11, 5)
+	suite.givenOpenTile(13, 10, 5)
+	suite.givenOpenTile(10, 11, 6)
+
+	tiles := floodFillTiles(suite.tileMap(), model.TileCoordinateOf(10, 10), false)
+
+	c.Check(tiles, check.HasLen, 3)
+	c.Check(tiles, check.DeepEquals, tileCoordinates(10, 10, 11, 10, 11, 11))
+}
+
+func (suite *TileBrushSuite) TestFloodFillOutsideMapContainsNoTiles(c *check.C) {
+	tiles := floodFillTiles(suite.tileMap(), model.TileCoordinateOf(64, 10), false)
+
+	c.Check(tiles, check.HasLen, 0)
+}
@@ -0,0 +1,102 @@
+package modes
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/inkyblackness/shocked-client/editor/model"
+
+	dataModel "github.com/inkyblackness/shocked-model"
+)
+
+// tileClipboardFormat identifies clipboard texts that contain tiles.
+const tileClipboardFormat = "shocked-client/tiles"
+
+// copiedTile is a tile in the clipboard. Its position is relative to the
+// top left corner of the copied region.
+type copiedTile struct {
+	X          int                      `json:"x"`
+	Y          int                      `json:"y"`
+	Properties dataModel.TileProperties `json:"properties"`
+}
+
+type tileClipboardData struct {
+	Format string       `json:"format"`
+	Tiles  []copiedTile `json:"tiles"`
+}
+
+// copyTiles returns the properties of the given tiles, relative to their top left corner.
+// Tiles without known properties are skipped.
+func copyTiles(tileMap *model.TileMap, coordinates []model.TileCoordinate) []copiedTile {
+	if len(coordinates) == 0 {
+		return nil
+	}
+	left, top := coordinates[0].XY()
+	for _, coord := range coordinates {
+		x, y := coord.XY()
+		if x < left {
+			left = x
+		}
+		if y < top {
+			top = y
+		}
+	}
+	tiles := []copiedTile{}
+	for _, coord := range coordinates {
+		if properties := tileMap.Tile(coord).Properties(); properties != nil {
+			x, y := coord.XY()
+			tiles = append(tiles, copiedTile{X: x - left, Y: y - top, Properties: *properties})
+		}
+	}
+	return tiles
+}
+
+// encodeTileClipboard returns the clipboard text for given tiles.
+func encodeTileClipboard(tiles []copiedTile) string {
+	data, _ := json.Marshal(tileClipboardData{Format: tileClipboardFormat, Tiles: tiles})
+	return string(data)
+}
+
+// decodeTileClipboard returns the tiles of the given clipboard text.
+// An error is returned if the text does not contain tiles.
+func decodeTileClipboard(text string) ([]copiedTile, error) {
+	var data tileClipboardData
+	err := json.Unmarshal([]byte(text), &data)
+	if (err == nil) && (data.Format != tileClipboardFormat) {
+		err = fmt.Errorf("unknown format <%v>", data.Format)
+	}
+	if (err == nil) && (len(data.Tiles) == 0) {
+		err = fmt.Errorf("no tiles")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return data.Tiles, nil
+}
+
+// placedTiles returns the coordinates the given tiles are placed at for the given anchor.
+// Tiles outside the map are skipped, for these the returned coordinate list has no entry.
+func placedTiles(pastedTiles []copiedTile, anchor model.TileCoordinate) (tiles []copiedTile, coordinates []model.TileCoordinate) {
+	anchorX, anchorY := anchor.XY()
+	for _, tile := range pastedTiles {
+		x, y := anchorX+tile.X, anchorY+tile.Y
+		if (x >= 0) && (x < 64) && (y >= 0) && (y < 64) {
+			tiles = append(tiles, tile)
+			coordinates = append(coordinates, model.TileCoordinateOf(x, y))
+		}
+	}
+	return
+}
+
+// pastableTileProperties returns the properties to paste into a level of given kind.
+// The properties of the other kind of level are removed, as are the calculated wall heights,
+// which are determined by the neighbouring tiles.
+func pastableTileProperties(properties dataModel.TileProperties, cyberspace bool) dataModel.TileProperties {
+	properties.CalculatedWallHeights = nil
+	if cyberspace {
+		properties.RealWorld = nil
+	} else {
+		properties.Cyberspace = nil
+	}
+	return properties
+}
@@ -0,0 +1,102 @@
+package modes
+
+import (
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/editor/model"
+	"github.com/inkyblackness/shocked-client/editor/model/memstore"
+
+	dataModel "github.com/inkyblackness/shocked-model"
+)
+
+type TileClipboardSuite struct {
+	store   *memstore.DataStore
+	level   *memstore.Level
+	adapter *model.Adapter
+}
+
+var _ = check.Suite(&TileClipboardSuite{})
+
+func (suite *TileClipboardSuite) SetUpTest(c *check.C) {
+	suite.store = memstore.NewDataStore(nil)
+	suite.level = suite.store.Project("project").AddLevel("archive", 1)
+	suite.adapter = model.NewAdapter(suite.store)
+	suite.adapter.RequestProject("project")
+	suite.store.Flush()
+}
+
+func (suite *TileClipboardSuite) givenOpenTile(x, y int, floorHeight int) {
+	tileType := dataModel.Open
+	height := dataModel.HeightUnit(floorHeight)
+	suite.level.SetTile(x, y, dataModel.TileProperties{Type: &tileType, FloorHeight: &height})
+}
+
+func (suite *TileClipboardSuite) tileMap() *model.TileMap {
+	suite.adapter.RequestActiveLevel(1)
+	suite.store.Flush()
+	return suite.adapter.ActiveLevel().TileMap()
+}
+
+func (suite *TileClipboardSuite) TestCopiedTilesAreRelativeToTopLeftCorner(c *check.C) {
+	suite.givenOpenTile(10, 21, 5)
+	suite.givenOpenTile(12, 20, 7)
+
+	tiles := copyTiles(suite.tileMap(), []model.TileCoordinate{model.TileCoordinateOf(10, 21), model.TileCoordinateOf(12, 20)})
+
+	c.Assert(len(tiles), check.Equals, 2)
+	c.Check([]int{tiles[0].X, tiles[0].Y, int(*tiles[0].Properties.FloorHeight)}, check.DeepEquals, []int{0, 1, 5})
+	c.Check([]int{tiles[1].X, tiles[1].Y, int(*tiles[1].Properties.FloorHeight)}, check.DeepEquals, []int{2, 0, 7})
+}
+
+func (suite *TileClipboardSuite) TestClipboardTextKeepsTiles(c *check.C) {
+	height := dataModel.HeightUnit(3)
+	tiles := []copiedTile{{X: 1, Y: 2, Properties: dataModel.TileProperties{FloorHeight: &height}}}
+
+	text := encodeTileClipboard(tiles)
+	decoded, err := decodeTileClipboard(text)
+
+	c.Check(text, check.Matches, `\{"format":"shocked-client/tiles".*`)
+	c.Assert(err, check.IsNil)
+	c.Check(decoded, check.DeepEquals, tiles)
+}
+
+func (suite *TileClipboardSuite) TestClipboardTextWithoutTilesIsRejected(c *check.C) {
+	for _, text := range []string{"", `{"format":"shocked-client/objects","tiles":[{}]}`, `{"format":"shocked-client/tiles"}`} {
+		_, err := decodeTileClipboard(text)
+		c.Check(err, check.NotNil, check.Commentf("text <%v>", text))
+	}
+}
+
+func (suite *TileClipboardSuite) TestPlacedTilesKeepRelativeOffsets(c *check.C) {
+	pasted := []copiedTile{{X: 0, Y: 1}, {X: 2, Y: 0}}
+
+	tiles, coordinates := placedTiles(pasted, model.TileCoordinateOf(30, 40))
+
+	c.Check(tiles, check.DeepEquals, pasted)
+	c.Check(coordinates, check.DeepEquals, []model.TileCoordinate{model.TileCoordinateOf(30, 41), model.TileCoordinateOf(32, 40)})
+}
+
+func (suite *TileClipboardSuite) TestPlacedTilesSkipTilesOutsideMap(c *check.C) {
+	pasted := []copiedTile{{X: 0, Y: 0}, {X: 1, Y: 0}}
+
+	tiles, coordinates := placedTiles(pasted, model.TileCoordinateOf(63, 10))
+
+	c.Check(tiles, check.DeepEquals, pasted[0:1])
+	c.Check(coordinates, check.DeepEquals, []model.TileCoordinate{model.TileCoordinateOf(63, 10)})
+}
+
+func (suite *TileClipboardSuite) TestPastedPropertiesKeepOnlyPropertiesOfLevelKind(c *check.C) {
+	properties := dataModel.TileProperties{
+		CalculatedWallHeights: &dataModel.CalculatedWallHeights{},
+		RealWorld:             &dataModel.RealWorldTileProperties{},
+		Cyberspace:            &dataModel.CyberspaceTileProperties{}}
+
+	realWorld := pastableTileProperties(properties, false)
+	cyberspace := pastableTileProperties(properties, true)
+
+	c.Check(realWorld.CalculatedWallHeights, check.IsNil)
+	c.Check(realWorld.RealWorld, check.NotNil)
+	c.Check(realWorld.Cyberspace, check.IsNil)
+	c.Check(cyberspace.RealWorld, check.IsNil)
+	c.Check(cyberspace.Cyberspace, check.NotNil)
+}
@@ -0,0 +1,169 @@
+package modes
+
+import (
+	"github.com/inkyblackness/shocked-client/editor/cmd"
+	"github.com/inkyblackness/shocked-client/editor/model"
+
+	dataModel "github.com/inkyblackness/shocked-model"
+)
+
+// rampDirection is the direction in which a ramp goes from its start height to its end height.
+type rampDirection int
+
+const (
+	rampTowardsNorth rampDirection = iota
+	rampTowardsEast
+	rampTowardsSouth
+	rampTowardsWest
+)
+
+var rampDirectionNames = []string{"Towards North", "Towards East", "Towards South", "Towards West"}
+
+// rampSlopeTypes are the tile types of slopes rising into the corresponding direction.
+var rampSlopeTypes = []dataModel.TileType{
+	dataModel.SlopeSouthToNorth, dataModel.SlopeWestToEast,
+	dataModel.SlopeNorthToSouth, dataModel.SlopeEastToWest}
+
+func (direction rampDirection) opposite() rampDirection {
+	return (direction + 2) % 4
+}
+
+// tileRamp describes a ramp from a start height to an end height, in height units.
+type tileRamp struct {
+	direction   rampDirection
+	startHeight int
+	endHeight   int
+}
+
+// autoTileRamp returns a ramp that connects the neighbours on either end of the given tiles.
+// The ramp runs along the longer side of the tiles. The heights are taken from the first tile
+// beyond each end that is not solid, or from the tiles at the end if there is none.
+func autoTileRamp(tiles []model.TileCoordinate, propertiesOf func(x, y int) *dataModel.TileProperties) (ramp tileRamp) {
+	if len(tiles) == 0 {
+		return
+	}
+	minX, minY, maxX, maxY := tileBounds(tiles)
+	if (maxX - minX) > (maxY - minY) {
+		ramp.direction = rampTowardsEast
+	}
+	heightAt := func(x, y int, fallbackX, fallbackY int) int {
+		properties := propertiesOf(x, y)
+		if (properties == nil) || (*properties.Type == dataModel.Solid) {
+			properties = propertiesOf(fallbackX, fallbackY)
+		}
+		if properties == nil {
+			return 0
+		}
+		return int(*properties.FloorHeight)
+	}
+	firstX, firstY := tiles[0].XY()
+	if ramp.direction == rampTowardsNorth {
+		ramp.startHeight = heightAt(firstX, minY-1, firstX, minY)
+		ramp.endHeight = heightAt(firstX, maxY+1, firstX, maxY)
+	} else {
+		ramp.startHeight = heightAt(minX-1, firstY, minX, firstY)
+		ramp.endHeight = heightAt(maxX+1, firstY, maxX, firstY)
+	}
+
+	return
+}
+
+// tileBounds returns the smallest and largest coordinates of the given tiles.
+func tileBounds(tiles []model.TileCoordinate) (minX, minY, maxX, maxY int) {
+	for index, coord := range tiles {
+		x, y := coord.XY()
+		if (index == 0) || (x < minX) {
+			minX = x
+		}
+		if (index == 0) || (x > maxX) {
+			maxX = x
+		}
+		if (index == 0) || (y < minY) {
+			minY = y
+		}
+		if (index == 0) || (y > maxY) {
+			maxY = y
+		}
+	}
+	return
+}
+
+// positions returns the index of each tile along the ramp, starting at zero, and the length of the ramp.
+func (ramp tileRamp) positions(tiles []model.TileCoordinate) (positions []int, length int) {
+	minX, minY, maxX, maxY := tileBounds(tiles)
+	positionOf := map[rampDirection]func(x, y int) int{
+		rampTowardsNorth: func(x, y int) int { return y - minY },
+		rampTowardsEast:  func(x, y int) int { return x - minX },
+		rampTowardsSouth: func(x, y int) int { return maxY - y },
+		rampTowardsWest:  func(x, y int) int { return maxX - x }}[ramp.direction]
+
+	length = maxY - minY + 1
+	if (ramp.direction == rampTowardsEast) || (ramp.direction == rampTowardsWest) {
+		length = maxX - minX + 1
+	}
+	positions = make([]int, len(tiles))
+	for index, coord := range tiles {
+		positions[index] = positionOf(coord.XY())
+	}
+	return
+}
+
+// steepness returns the largest difference in height units a single tile has to cover.
+func (ramp tileRamp) steepness(length int) int {
+	difference := ramp.endHeight - ramp.startHeight
+	if difference < 0 {
+		difference = -difference
+	}
+	return (difference + length - 1) / length
+}
+
+// isPossible returns true if no tile of the ramp is steeper than 45 degrees.
+// The height shift of the level determines how many height units make up the length of a tile.
+func (ramp tileRamp) isPossible(length int, heightShift int) bool {
+	return (heightShift >= 0) && (ramp.steepness(length) <= (1 << uint(heightShift)))
+}
+
+// edgeHeight returns the height at the end of the tile at given position.
+func (ramp tileRamp) edgeHeight(position, length int) int {
+	return ramp.startHeight + ((ramp.endHeight-ramp.startHeight)*position)/length
+}
+
+// changes returns the changes for the given tiles to form the ramp. Each tile starts at the height
+// the previous one ends, so that all tiles connect. Flat steps become open tiles.
+// If a slope has a flat floor, it is changed to have a flat ceiling instead.
+func (ramp tileRamp) changes(tiles []model.TileCoordinate,
+	propertiesOf func(x, y int) *dataModel.TileProperties, cyberspace bool) (changes []cmd.TileChange) {
+	positions, length := ramp.positions(tiles)
+
+	for index, coord := range tiles {
+		x, y := coord.XY()
+		properties := propertiesOf(x, y)
+		if properties == nil {
+			continue
+		}
+		oldProperties := pastableTileProperties(*properties, cyberspace)
+		newProperties := oldProperties
+		lowEdge := ramp.edgeHeight(positions[index], length)
+		highEdge := ramp.edgeHeight(positions[index]+1, length)
+		tileType := rampSlopeTypes[ramp.direction]
+		if highEdge < lowEdge {
+			lowEdge, highEdge = highEdge, lowEdge
+			tileType = rampSlopeTypes[ramp.direction.opposite()]
+		}
+		if highEdge == lowEdge {
+			tileType = dataModel.Open
+		}
+		floorHeight := dataModel.HeightUnit(lowEdge)
+		slopeHeight := dataModel.HeightUnit(highEdge - lowEdge)
+		newProperties.Type = &tileType
+		newProperties.FloorHeight = &floorHeight
+		newProperties.SlopeHeight = &slopeHeight
+		if (slopeHeight > 0) && (*oldProperties.SlopeControl == dataModel.SlopeFloorFlat) {
+			slopeControl := dataModel.SlopeCeilingFlat
+			newProperties.SlopeControl = &slopeControl
+		}
+		changes = append(changes, cmd.TileChange{X: x, Y: y, OldProperties: oldProperties, NewProperties: newProperties})
+	}
+
+	return
+}
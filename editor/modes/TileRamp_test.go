@@ -0,0 +1,120 @@
+package modes
+
+import (
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/editor/model"
+
+	dataModel "github.com/inkyblackness/shocked-model"
+)
+
+type TileRampSuite struct {
+	tiles map[model.TileCoordinate]*dataModel.TileProperties
+}
+
+var _ = check.Suite(&TileRampSuite{})
+
+func (suite *TileRampSuite) SetUpTest(c *check.C) {
+	suite.tiles = make(map[model.TileCoordinate]*dataModel.TileProperties)
+}
+
+func (suite *TileRampSuite) givenTile(x, y int, tileType dataModel.TileType, floorHeight int) {
+	height := dataModel.HeightUnit(floorHeight)
+	slopeControl := dataModel.SlopeFloorFlat
+	suite.tiles[model.TileCoordinateOf(x, y)] = &dataModel.TileProperties{
+		Type: &tileType, FloorHeight: &height, SlopeControl: &slopeControl}
+}
+
+func (suite *TileRampSuite) propertiesOf(x, y int) *dataModel.TileProperties {
+	return suite.tiles[model.TileCoordinateOf(x, y)]
+}
+
+// givenStrip prepares open tiles from (10, 10) northwards, with given heights beyond either end.
+func (suite *TileRampSuite) givenStrip(length int, southHeight, northHeight int) []model.TileCoordinate {
+	var strip []model.TileCoordinate
+	suite.givenTile(10, 9, dataModel.Open, southHeight)
+	for offset := 0; offset < length; offset++ {
+		suite.givenTile(10, 10+offset, dataModel.Open, 0)
+		strip = append(strip, model.TileCoordinateOf(10, 10+offset))
+	}
+	suite.givenTile(10, 10+length, dataModel.Open, northHeight)
+	return strip
+}
+
+func (suite *TileRampSuite) TestAutoRampConnectsNeighbouringTiles(c *check.C) {
+	strip := suite.givenStrip(3, 2, 8)
+
+	ramp := autoTileRamp(strip, suite.propertiesOf)
+
+	c.Check(ramp, check.Equals, tileRamp{direction: rampTowardsNorth, startHeight: 2, endHeight: 8})
+}
+
+func (suite *TileRampSuite) TestAutoRampRunsAlongLongerSide(c *check.C) {
+	suite.givenTile(9, 10, dataModel.Open, 4)
+	suite.givenTile(10, 10, dataModel.Open, 0)
+	suite.givenTile(11, 10, dataModel.Open, 0)
+	suite.givenTile(12, 10, dataModel.Open, 6)
+
+	ramp := autoTileRamp(tileCoordinates(10, 10, 11, 10), suite.propertiesOf)
+
+	c.Check(ramp, check.Equals, tileRamp{direction: rampTowardsEast, startHeight: 4, endHeight: 6})
+}
+
+func (suite *TileRampSuite) TestAutoRampTakesHeightOfEndTileBesideSolidTiles(c *check.C) {
+	strip := suite.givenStrip(3, 2, 8)
+	suite.givenTile(10, 9, dataModel.Solid, 20)
+	suite.givenTile(10, 10, dataModel.Open, 3)
+
+	ramp := autoTileRamp(strip, suite.propertiesOf)
+
+	c.Check(ramp.startHeight, check.Equals, 3)
+}
+
+func (suite *TileRampSuite) TestRampIsPossibleUpToOneTileLengthPerTile(c *check.C) {
+	ramp := tileRamp{startHeight: 0, endHeight: 24}
+
+	c.Check(ramp.isPossible(3, 3), check.Equals, true)
+	c.Check(ramp.isPossible(2, 3), check.Equals, false)
+	c.Check(ramp.isPossible(3, -1), check.Equals, false)
+}
+
+func (suite *TileRampSuite) TestChangesFormConnectedSlopes(c *check.C) {
+	strip := suite.givenStrip(3, 2, 8)
+	ramp := tileRamp{direction: rampTowardsNorth, startHeight: 2, endHeight: 8}
+
+	changes := ramp.changes(strip, suite.propertiesOf, false)
+
+	c.Assert(changes, check.HasLen, 3)
+	for offset, change := range changes {
+		properties := change.NewProperties
+		c.Check([]int{change.X, change.Y}, check.DeepEquals, []int{10, 10 + offset})
+		c.Check(*properties.Type, check.Equals, dataModel.SlopeSouthToNorth)
+		c.Check(*properties.FloorHeight, check.Equals, dataModel.HeightUnit(2+offset*2))
+		c.Check(*properties.SlopeHeight, check.Equals, dataModel.HeightUnit(2))
+		c.Check(*properties.SlopeControl, check.Equals, dataModel.SlopeCeilingFlat)
+		c.Check(*change.OldProperties.Type, check.Equals, dataModel.Open)
+	}
+}
+
+func (suite *TileRampSuite) TestChangesOfDescendingRampUseOppositeSlope(c *check.C) {
+	strip := suite.givenStrip(2, 8, 2)
+	ramp := tileRamp{direction: rampTowardsNorth, startHeight: 8, endHeight: 2}
+
+	changes := ramp.changes(strip, suite.propertiesOf, false)
+
+	c.Assert(changes, check.HasLen, 2)
+	c.Check(*changes[0].NewProperties.Type, check.Equals, dataModel.SlopeNorthToSouth)
+	c.Check(*changes[0].NewProperties.FloorHeight, check.Equals, dataModel.HeightUnit(5))
+	c.Check(*changes[1].NewProperties.FloorHeight, check.Equals, dataModel.HeightUnit(2))
+}
+
+func (suite *TileRampSuite) TestChangesOfFlatStepsAreOpenTiles(c *check.C) {
+	strip := suite.givenStrip(3, 4, 4)
+	ramp := tileRamp{direction: rampTowardsNorth, startHeight: 4, endHeight: 4}
+
+	changes := ramp.changes(strip, suite.propertiesOf, false)
+
+	c.Assert(changes, check.HasLen, 3)
+	c.Check(*changes[1].NewProperties.Type, check.Equals, dataModel.Open)
+	c.Check(*changes[1].NewProperties.SlopeControl, check.Equals, dataModel.SlopeFloorFlat)
+}
@@ -3,6 +3,12 @@ package modes
 import (
 	"fmt"
 	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/inkyblackness/shocked-client/editor/model"
+	"github.com/inkyblackness/shocked-client/graphics/controls"
 )
 
 func cloneBytes(original []byte) []byte {
@@ -30,6 +36,14 @@ func stringAsPointer(value string) (ptr *string) {
 	return
 }
 
+// requestLevelActive returns the adapter of the given level, requesting it to be the active one if necessary.
+func requestLevelActive(modelAdapter *model.Adapter, levelID int) *model.LevelAdapter {
+	if modelAdapter.ActiveLevel().ID() != levelID {
+		modelAdapter.RequestActiveLevel(levelID)
+	}
+	return modelAdapter.ActiveLevel()
+}
+
 func heightToValue(heightShift int, value int64, scale float64) float64 {
 	tileHeights := []float64{32.0, 16.0, 8.0, 4.0, 2.0, 1.0, 0.5, 0.25}
 	if (heightShift >= 0) && (heightShift < len(tileHeights)) {
@@ -39,6 +53,14 @@ func heightToValue(heightShift int, value int64, scale float64) float64 {
 	}
 }
 
+func heightFromValue(heightShift int, value float64, scale float64) float64 {
+	tileHeights := []float64{32.0, 16.0, 8.0, 4.0, 2.0, 1.0, 0.5, 0.25}
+	if (heightShift >= 0) && (heightShift < len(tileHeights)) {
+		return (value * scale) / tileHeights[heightShift]
+	}
+	return math.NaN()
+}
+
 func heightToString(heightShift int, value int64, scale float64) (result string) {
 	tileHeights := []float64{32.0, 16.0, 8.0, 4.0, 2.0, 1.0, 0.5, 0.25}
 	if (heightShift >= 0) && (heightShift < len(tileHeights)) {
@@ -48,3 +70,35 @@ func heightToString(heightShift int, value int64, scale float64) (result string)
 	}
 	return
 }
+
+var quantityExpression = regexp.MustCompile(`^\s*([-+]?[0-9]*\.?[0-9]+)\s*([^\s0-9]*)`)
+
+// unitValueParser returns a slider value parser for numbers with a unit, as they are
+// printed by the formatters. A number is taken as in unit if it is followed by one of
+// the given units, or if it has a decimal point. Otherwise, the text is parsed as raw value.
+func unitValueParser(toRaw func(float64) float64, units ...string) controls.SliderValueParser {
+	return func(text string) (int64, error) {
+		match := quantityExpression.FindStringSubmatch(text)
+		if match != nil {
+			inUnit := strings.Contains(match[1], ".")
+			for _, unit := range units {
+				inUnit = inUnit || (match[2] == unit)
+			}
+			if inUnit {
+				value, err := strconv.ParseFloat(match[1], 64)
+				raw := toRaw(value)
+				if (err != nil) || math.IsNaN(raw) {
+					return 0, fmt.Errorf("invalid value: <%v>", text)
+				}
+				return int64(math.Floor(raw + 0.5)), nil
+			}
+		}
+		return controls.DefaultSliderValueParser(text)
+	}
+}
+
+func heightParser(heightShift int, scale float64) controls.SliderValueParser {
+	return unitValueParser(func(value float64) float64 {
+		return heightFromValue(heightShift, value, scale)
+	}, "tile(s)", "tiles", "tile", "t")
+}
@@ -0,0 +1,130 @@
+package headless
+
+import (
+	"github.com/inkyblackness/shocked-client/env"
+	"github.com/inkyblackness/shocked-client/env/keys"
+	"github.com/inkyblackness/shocked-client/opengl"
+	"github.com/inkyblackness/shocked-client/opengl/software"
+)
+
+// OpenGlWindow is an OpenGL surface without a display. It renders into
+// an offscreen framebuffer and receives its events from code.
+type OpenGlWindow struct {
+	env.AbstractOpenGlWindow
+
+	keyBuffer *keys.StickyKeyBuffer
+
+	glWrapper     *software.OpenGl
+	clipboardText string
+}
+
+// NewOpenGlWindow returns a new window instance of given size.
+func NewOpenGlWindow(width, height int) *OpenGlWindow {
+	window := &OpenGlWindow{
+		AbstractOpenGlWindow: env.InitAbstractOpenGlWindow(),
+		glWrapper:            software.NewOpenGl(width, height)}
+
+	window.CallOnMouseScroll = func(float32, float32) {}
+	window.keyBuffer = keys.NewStickyKeyBuffer(window.StickyKeyListener())
+
+	return window
+}
+
+// OpenGl implements the env.OpenGlWindow interface.
+func (window *OpenGlWindow) OpenGl() opengl.OpenGl {
+	return window.glWrapper
+}
+
+// Framebuffer returns the software implementation the window renders with.
+func (window *OpenGlWindow) Framebuffer() *software.OpenGl {
+	return window.glWrapper
+}
+
+// Size implements the env.OpenGlWindow interface.
+func (window *OpenGlWindow) Size() (width int, height int) {
+	return window.glWrapper.Size()
+}
+
+// Resize changes the size of the window and notifies the resize callback.
+func (window *OpenGlWindow) Resize(width, height int) {
+	window.glWrapper.Resize(width, height)
+	window.CallResize(width, height)
+}
+
+// Render calls the render callback to update the framebuffer.
+func (window *OpenGlWindow) Render() {
+	window.CallRender()
+}
+
+// Clipboard implements the env.OpenGlWindow interface.
+func (window *OpenGlWindow) Clipboard() env.Clipboard {
+	return window
+}
+
+// Text implements the env.Clipboard interface.
+func (window *OpenGlWindow) Text() string {
+	return window.clipboardText
+}
+
+// SetText implements the env.Clipboard interface.
+func (window *OpenGlWindow) SetText(value string) {
+	window.clipboardText = value
+}
+
+// ActiveModifier returns the modifier set of currently pressed keys.
+func (window *OpenGlWindow) ActiveModifier() keys.Modifier {
+	return window.keyBuffer.ActiveModifier()
+}
+
+// MouseMove reports a moved mouse cursor.
+func (window *OpenGlWindow) MouseMove(x, y float32) {
+	window.CallOnMouseMove(x, y)
+}
+
+// MouseButtonDown reports a pressed mouse button.
+func (window *OpenGlWindow) MouseButtonDown(button uint32) {
+	window.CallOnMouseButtonDown(button, window.ActiveModifier())
+}
+
+// MouseButtonUp reports a released mouse button.
+func (window *OpenGlWindow) MouseButtonUp(button uint32) {
+	window.CallOnMouseButtonUp(button, window.ActiveModifier())
+}
+
+// MouseScroll reports a scroll event. Delta values are right-hand oriented:
+// positive values go right/down/far.
+func (window *OpenGlWindow) MouseScroll(dx, dy float32) {
+	window.CallOnMouseScroll(dx, dy)
+}
+
+// KeyDown reports a pressed key. Modifier keys update the active modifier.
+// Like in the native window, character keys are only reported while a modifier
+// other than Shift is active - otherwise they are typed characters (see Char()).
+func (window *OpenGlWindow) KeyDown(key keys.Key) {
+	modifier := window.ActiveModifier()
+	if _, isChar := key.Char(); isChar {
+		if modifier.Without(keys.ModShift) != keys.ModNone {
+			window.CallKey(key, modifier)
+		}
+	} else {
+		window.keyBuffer.KeyDown(key, modifier)
+	}
+}
+
+// KeyUp reports a released key. Modifier keys update the active modifier.
+// Character keys have no release event.
+func (window *OpenGlWindow) KeyUp(key keys.Key) {
+	if _, isChar := key.Char(); !isChar {
+		window.keyBuffer.KeyUp(key, window.ActiveModifier())
+	}
+}
+
+// Char reports a typed character.
+func (window *OpenGlWindow) Char(char rune) {
+	window.CallCharCallback(char)
+}
+
+// FileDrop reports files dropped into the window.
+func (window *OpenGlWindow) FileDrop(filePaths []string) {
+	window.CallFileDropCallback(filePaths)
+}
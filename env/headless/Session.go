@@ -0,0 +1,160 @@
+package headless
+
+import (
+	"image"
+
+	"github.com/inkyblackness/shocked-client/env"
+	"github.com/inkyblackness/shocked-client/env/keys"
+)
+
+var modifierKeys = []keys.Key{keys.KeyShift, keys.KeyControl, keys.KeyAlt, keys.KeySuper}
+
+// Session drives an application within a headless window. It is meant to
+// script user interaction, for example in end-to-end tests.
+//
+// All actions are forwarded synchronously; Afterwards, any task pending in the
+// deferrer channel is executed, so that data store responses are processed
+// before the next action.
+type Session struct {
+	window   *OpenGlWindow
+	deferrer <-chan func()
+
+	mouseX, mouseY float32
+}
+
+// NewSession creates a headless window of given size and initializes the
+// application with it. The deferrer is the same channel the data store
+// uses to return results in the main thread; It may be nil.
+func NewSession(app env.Application, width, height int, deferrer <-chan func()) *Session {
+	session := &Session{
+		window:   NewOpenGlWindow(width, height),
+		deferrer: deferrer}
+
+	app.Init(session.window)
+	session.Settle()
+
+	return session
+}
+
+// Window returns the window the session works with.
+func (session *Session) Window() *OpenGlWindow {
+	return session.window
+}
+
+// Settle runs all tasks pending in the deferrer channel, including those
+// that are queued while processing.
+func (session *Session) Settle() *Session {
+	for session.deferrer != nil {
+		select {
+		case task, open := <-session.deferrer:
+			if !open {
+				return session
+			}
+			task()
+		default:
+			return session
+		}
+	}
+	return session
+}
+
+// Resize changes the window size.
+func (session *Session) Resize(width, height int) *Session {
+	session.window.Resize(width, height)
+	return session.Settle()
+}
+
+// Move moves the mouse cursor to the given window coordinate.
+func (session *Session) Move(x, y float32) *Session {
+	session.mouseX, session.mouseY = x, y
+	session.window.MouseMove(x, y)
+	return session.Settle()
+}
+
+// Press presses the given mouse button at the current cursor position.
+func (session *Session) Press(button uint32) *Session {
+	session.window.MouseButtonDown(button)
+	return session.Settle()
+}
+
+// Release releases the given mouse button at the current cursor position.
+func (session *Session) Release(button uint32) *Session {
+	session.window.MouseButtonUp(button)
+	return session.Settle()
+}
+
+// Click presses and releases the given mouse button at the current cursor position,
+// while holding the keys of the given modifier.
+func (session *Session) Click(button uint32, modifier keys.Modifier) *Session {
+	return session.withModifier(modifier, func() {
+		session.window.MouseButtonDown(button)
+		session.window.MouseButtonUp(button)
+	})
+}
+
+// Drag presses the given mouse button at the current position, moves the cursor
+// to the given coordinate and releases the button there.
+func (session *Session) Drag(button uint32, modifier keys.Modifier, toX, toY float32) *Session {
+	return session.withModifier(modifier, func() {
+		session.window.MouseButtonDown(button)
+		session.mouseX, session.mouseY = toX, toY
+		session.window.MouseMove(toX, toY)
+		session.window.MouseButtonUp(button)
+	})
+}
+
+// Scroll scrolls the mouse wheel at the current cursor position.
+// Positive values go right/down.
+func (session *Session) Scroll(dx, dy float32) *Session {
+	session.window.MouseScroll(dx, dy)
+	return session.Settle()
+}
+
+// Key presses and releases the given key, while holding the keys of the given modifier.
+func (session *Session) Key(key keys.Key, modifier keys.Modifier) *Session {
+	return session.withModifier(modifier, func() {
+		session.window.KeyDown(key)
+		session.window.KeyUp(key)
+	})
+}
+
+// Type reports each character of the given text as typed.
+func (session *Session) Type(text string) *Session {
+	for _, char := range text {
+		session.window.Char(char)
+	}
+	return session.Settle()
+}
+
+// Drop drops the given files at the current cursor position.
+func (session *Session) Drop(filePaths ...string) *Session {
+	session.window.FileDrop(filePaths)
+	return session.Settle()
+}
+
+// Frame renders the current state and returns the resulting image.
+func (session *Session) Frame() *image.NRGBA {
+	session.Settle()
+	session.window.Render()
+	return session.window.Framebuffer().Image()
+}
+
+// Cursor returns the current position of the mouse cursor.
+func (session *Session) Cursor() (x, y float32) {
+	return session.mouseX, session.mouseY
+}
+
+func (session *Session) withModifier(modifier keys.Modifier, action func()) *Session {
+	var pressed []keys.Key
+	for _, key := range modifierKeys {
+		if modifier.Has(key.AsModifier()) && !session.window.ActiveModifier().Has(key.AsModifier()) {
+			session.window.KeyDown(key)
+			pressed = append(pressed, key)
+		}
+	}
+	action()
+	for index := len(pressed) - 1; index >= 0; index-- {
+		session.window.KeyUp(pressed[index])
+	}
+	return session.Settle()
+}
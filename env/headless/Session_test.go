@@ -0,0 +1,131 @@
+package headless
+
+import (
+	"fmt"
+
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/env"
+	"github.com/inkyblackness/shocked-client/env/keys"
+	"github.com/inkyblackness/shocked-client/opengl"
+)
+
+type recordingApplication struct {
+	events   []string
+	deferrer chan func()
+}
+
+func (app *recordingApplication) record(format string, args ...interface{}) {
+	app.events = append(app.events, fmt.Sprintf(format, args...))
+}
+
+func (app *recordingApplication) Init(window env.OpenGlWindow) {
+	width, height := window.Size()
+	app.record("init %dx%d", width, height)
+	gl := window.OpenGl()
+	window.OnRender(func() {
+		gl.ClearColor(0.0, 1.0, 0.0, 1.0)
+		gl.Clear(opengl.COLOR_BUFFER_BIT)
+	})
+	window.OnResize(func(width, height int) { app.record("resize %dx%d", width, height) })
+	window.OnMouseMove(func(x, y float32) { app.record("move %v/%v", x, y) })
+	window.OnMouseButtonDown(func(button uint32, modifier keys.Modifier) { app.record("down %v %v", button, modifier) })
+	window.OnMouseButtonUp(func(button uint32, modifier keys.Modifier) { app.record("up %v %v", button, modifier) })
+	window.OnMouseScroll(func(dx, dy float32) { app.record("scroll %v/%v", dx, dy) })
+	window.OnKey(func(key keys.Key, modifier keys.Modifier) {
+		app.record("key %v %v", key, modifier)
+		app.deferrer <- func() { app.record("deferred %v", key) }
+	})
+	window.OnModifier(func(modifier keys.Modifier) { app.record("modifier %v", modifier) })
+	window.OnCharCallback(func(char rune) { app.record("char %c", char) })
+	window.OnFileDropCallback(func(filePaths []string) { app.record("drop %v", filePaths) })
+}
+
+type SessionSuite struct {
+	app     *recordingApplication
+	session *Session
+}
+
+var _ = check.Suite(&SessionSuite{})
+
+func (suite *SessionSuite) SetUpTest(c *check.C) {
+	suite.app = &recordingApplication{deferrer: make(chan func(), 10)}
+	suite.session = NewSession(suite.app, 40, 30, suite.app.deferrer)
+	suite.app.events = nil
+}
+
+func (suite *SessionSuite) TestNewSessionInitializesApplicationWithWindowSize(c *check.C) {
+	app := &recordingApplication{}
+	NewSession(app, 320, 200, nil)
+
+	c.Check(app.events, check.DeepEquals, []string{"init 320x200"})
+}
+
+func (suite *SessionSuite) TestClickReportsButtonWithModifierKeysHeld(c *check.C) {
+	suite.session.Move(10, 20).Click(env.MousePrimary, keys.ModControl)
+
+	c.Check(suite.app.events, check.DeepEquals, []string{
+		"move 10/20", "modifier 2", "down 1 2", "up 1 2", "modifier 0"})
+}
+
+func (suite *SessionSuite) TestDragMovesWhileButtonIsPressed(c *check.C) {
+	suite.session.Move(1, 2).Drag(env.MouseSecondary, keys.ModNone, 5, 6)
+
+	c.Check(suite.app.events, check.DeepEquals, []string{"move 1/2", "down 2 0", "move 5/6", "up 2 0"})
+	x, y := suite.session.Cursor()
+	c.Check([]float32{x, y}, check.DeepEquals, []float32{5, 6})
+}
+
+func (suite *SessionSuite) TestKeyProcessesDeferredTasks(c *check.C) {
+	suite.session.Key(keys.KeyEnter, keys.ModNone)
+
+	c.Check(suite.app.events, check.DeepEquals, []string{
+		fmt.Sprintf("key %v 0", keys.KeyEnter), fmt.Sprintf("deferred %v", keys.KeyEnter)})
+}
+
+func (suite *SessionSuite) TestCharKeysAreReportedWithModifier(c *check.C) {
+	suite.session.Key(keys.CharKey('s'), keys.ModControl)
+
+	c.Check(suite.app.events, check.DeepEquals, []string{
+		"modifier 2", fmt.Sprintf("key %v 2", keys.CharKey('s')), "modifier 0", fmt.Sprintf("deferred %v", keys.CharKey('s'))})
+}
+
+func (suite *SessionSuite) TestCharKeysAreIgnoredWithoutModifierOtherThanShift(c *check.C) {
+	suite.session.Key(keys.CharKey('s'), keys.ModNone)
+	suite.session.Key(keys.CharKey('s'), keys.ModShift)
+
+	c.Check(suite.app.events, check.DeepEquals, []string{"modifier 1", "modifier 0"})
+}
+
+func (suite *SessionSuite) TestTypeReportsCharacters(c *check.C) {
+	suite.session.Type("ab")
+
+	c.Check(suite.app.events, check.DeepEquals, []string{"char a", "char b"})
+}
+
+func (suite *SessionSuite) TestDropReportsFiles(c *check.C) {
+	suite.session.Drop("a.png", "b.wav")
+
+	c.Check(suite.app.events, check.DeepEquals, []string{"drop [a.png b.wav]"})
+}
+
+func (suite *SessionSuite) TestResizeChangesFrameSize(c *check.C) {
+	frame := suite.session.Resize(16, 8).Frame()
+
+	c.Check(suite.app.events, check.DeepEquals, []string{"resize 16x8"})
+	c.Check(frame.Bounds().Dx(), check.Equals, 16)
+	c.Check(frame.Bounds().Dy(), check.Equals, 8)
+}
+
+func (suite *SessionSuite) TestFrameReturnsRenderedImage(c *check.C) {
+	frame := suite.session.Frame()
+
+	c.Check(frame.NRGBAAt(39, 29).G, check.Equals, byte(255))
+}
+
+func (suite *SessionSuite) TestClipboardKeepsText(c *check.C) {
+	clipboard := suite.session.Window().Clipboard()
+	clipboard.SetText("copied")
+
+	c.Check(clipboard.Text(), check.Equals, "copied")
+}
@@ -1,8 +1,13 @@
 package keys
 
+import "unicode"
+
 // Key describes a named key on the keyboard. These are keys which are
 // unspecific to layout or language, or are universal.
 // Or, described in another way: keys that don't end up as printable characters.
+//
+// Keys of printable characters are only reported while a modifier other than
+// Shift is active (see CharKey) - otherwise they are typed characters.
 type Key int
 
 // Constants for commonly known named keys.
@@ -59,16 +64,23 @@ const (
 	KeyF7  = Key(357)
 	KeyF8  = Key(358)
 	KeyF9  = Key(359)
+)
 
-	KeyCopy  = Key(380)
-	KeyCut   = Key(381)
-	KeyPaste = Key(382)
+const charKeyBase = Key(0x01000000)
 
-	KeyUndo = Key(390)
-	KeyRedo = Key(391)
+// CharKey returns the key of a printable character, such as 'a' for the A key.
+// Letters are always mapped to their lowercase variant.
+func CharKey(char rune) Key {
+	return charKeyBase + Key(unicode.ToLower(char))
+}
 
-	KeySave = Key(400)
-)
+// Char returns the printable character of a key created with CharKey.
+func (key Key) Char() (char rune, isChar bool) {
+	if key > charKeyBase {
+		char, isChar = rune(key-charKeyBase), true
+	}
+	return
+}
 
 var keyToModifier = map[Key]Modifier{
 	KeyShift:   ModShift,
@@ -1,33 +1,130 @@
 package keys
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
 
-type shortcut struct {
-	keyName  string
+// Shortcut is the combination of a key and the modifier that has to be active.
+type Shortcut struct {
+	Key      Key
+	Modifier Modifier
+}
+
+var modifierNames = []struct {
+	name     string
 	modifier Modifier
-	key      Key
+}{
+	{"Ctrl", ModControl},
+	{"Shift", ModShift},
+	{"Alt", ModAlt},
+	{"Super", ModSuper}}
+
+var keyNames = map[Key]string{
+	KeyEnter:     "Enter",
+	KeyEscape:    "Escape",
+	KeyBackspace: "Backspace",
+	KeyTab:       "Tab",
+
+	KeyDown:  "Down",
+	KeyLeft:  "Left",
+	KeyRight: "Right",
+	KeyUp:    "Up",
+
+	KeyDelete:   "Delete",
+	KeyEnd:      "End",
+	KeyHome:     "Home",
+	KeyInsert:   "Insert",
+	KeyPageDown: "PageDown",
+	KeyPageUp:   "PageUp",
+
+	KeyPause:       "Pause",
+	KeyPrintScreen: "PrintScreen",
+
+	KeyF1:  "F1",
+	KeyF2:  "F2",
+	KeyF3:  "F3",
+	KeyF4:  "F4",
+	KeyF5:  "F5",
+	KeyF6:  "F6",
+	KeyF7:  "F7",
+	KeyF8:  "F8",
+	KeyF9:  "F9",
+	KeyF10: "F10",
+	KeyF11: "F11",
+	KeyF12: "F12"}
+
+// ParseShortcut returns the shortcut described by given text. The text lists
+// the modifier keys and the key, separated by "+", such as "Ctrl+Shift+Z" or "F1".
+// Names are not case sensitive.
+func ParseShortcut(text string) (shortcut Shortcut, err error) {
+	parts := strings.Split(text, "+")
+	if strings.HasSuffix(text, "++") || (text == "+") {
+		parts = append(parts[:len(parts)-2], "+")
+	}
+	for _, part := range parts[:len(parts)-1] {
+		modifier, known := modifierByName(part)
+		if !known {
+			return Shortcut{}, fmt.Errorf("unknown modifier <%v> in shortcut <%v>", part, text)
+		}
+		shortcut.Modifier = shortcut.Modifier.With(modifier)
+	}
+	key, known := keyByName(parts[len(parts)-1])
+	if !known {
+		return Shortcut{}, fmt.Errorf("unknown key in shortcut <%v>", text)
+	}
+	shortcut.Key = key
+	return
 }
 
-var shortcuts = []shortcut{
-	{"c", ModControl, KeyCopy},
-	{"x", ModControl, KeyCut},
-	{"v", ModControl, KeyPaste},
-	{"z", ModControl, KeyUndo},
-	{"Z", ModControl.With(ModShift), KeyRedo},
-	{"z", ModControl.With(ModShift), KeyRedo},
-	{"y", ModControl, KeyRedo},
-	{"s", ModControl, KeySave}}
-
-// ResolveShortcut tries to map the given name and modifier combination to a
-// known (common) shortcut key. For instance, Ctrl+C is KeyCopy.
-func ResolveShortcut(keyName string, modifier Modifier) (key Key, knownKey bool) {
-	lowercaseName := strings.ToLower(keyName)
-	for _, entry := range shortcuts {
-		if (entry.keyName == lowercaseName) && (entry.modifier == modifier) {
-			knownKey = true
-			key = entry.key
+func modifierByName(name string) (Modifier, bool) {
+	trimmed := strings.TrimSpace(name)
+	for _, entry := range modifierNames {
+		if strings.EqualFold(entry.name, trimmed) {
+			return entry.modifier, true
 		}
 	}
+	if strings.EqualFold("Control", trimmed) {
+		return ModControl, true
+	}
+	return ModNone, false
+}
 
-	return
+func keyByName(name string) (Key, bool) {
+	trimmed := strings.TrimSpace(name)
+	if trimmed == "" {
+		trimmed = name
+	}
+	for key, keyName := range keyNames {
+		if strings.EqualFold(keyName, trimmed) {
+			return key, true
+		}
+	}
+	if utf8.RuneCountInString(trimmed) == 1 {
+		char, _ := utf8.DecodeRuneInString(trimmed)
+		if unicode.IsPrint(char) {
+			return CharKey(char), true
+		}
+	}
+	return Key(0), false
+}
+
+// String returns the textual representation of the shortcut, as accepted by ParseShortcut.
+func (shortcut Shortcut) String() string {
+	var parts []string
+	for _, entry := range modifierNames {
+		if shortcut.Modifier.Has(entry.modifier) {
+			parts = append(parts, entry.name)
+		}
+	}
+	if char, isChar := shortcut.Key.Char(); isChar {
+		parts = append(parts, string(unicode.ToUpper(char)))
+	} else if name, known := keyNames[shortcut.Key]; known {
+		parts = append(parts, name)
+	} else {
+		parts = append(parts, fmt.Sprintf("Key%v", int(shortcut.Key)))
+	}
+	return strings.Join(parts, "+")
 }
@@ -8,22 +8,57 @@ type ShortcutSuite struct{}
 
 var _ = check.Suite(&ShortcutSuite{})
 
-func (suite *ShortcutSuite) TestResolveShorcutReturnsValuesOfKnownCombo(c *check.C) {
-	key, knownKey := ResolveShortcut("c", ModControl)
+func (suite *ShortcutSuite) TestParseShortcutReturnsNamedKeys(c *check.C) {
+	shortcut, err := ParseShortcut("F1")
 
-	c.Check(knownKey, check.Equals, true)
-	c.Check(key, check.Equals, KeyCopy)
+	c.Assert(err, check.IsNil)
+	c.Check(shortcut, check.Equals, Shortcut{Key: KeyF1, Modifier: ModNone})
 }
 
-func (suite *ShortcutSuite) TestResolveShorcutReturnsValuesOfKnownComboIfUppercase(c *check.C) {
-	key, knownKey := ResolveShortcut("C", ModControl)
+func (suite *ShortcutSuite) TestParseShortcutReturnsCharKeysWithModifier(c *check.C) {
+	shortcut, err := ParseShortcut("Ctrl+Shift+Z")
 
-	c.Check(knownKey, check.Equals, true)
-	c.Check(key, check.Equals, KeyCopy)
+	c.Assert(err, check.IsNil)
+	c.Check(shortcut, check.Equals, Shortcut{Key: CharKey('z'), Modifier: ModControl.With(ModShift)})
 }
 
-func (suite *ShortcutSuite) TestResolveShorcutReturnsFalseForUnknownCombo(c *check.C) {
-	_, knownKey := ResolveShortcut("l", ModControl.With(ModShift))
+func (suite *ShortcutSuite) TestParseShortcutIgnoresCase(c *check.C) {
+	shortcut, err := ParseShortcut("control+pagedown")
 
-	c.Check(knownKey, check.Equals, false)
+	c.Assert(err, check.IsNil)
+	c.Check(shortcut, check.Equals, Shortcut{Key: KeyPageDown, Modifier: ModControl})
+}
+
+func (suite *ShortcutSuite) TestParseShortcutAcceptsPlusKey(c *check.C) {
+	shortcut, err := ParseShortcut("Ctrl++")
+
+	c.Assert(err, check.IsNil)
+	c.Check(shortcut, check.Equals, Shortcut{Key: CharKey('+'), Modifier: ModControl})
+}
+
+func (suite *ShortcutSuite) TestParseShortcutReturnsErrorForUnknownNames(c *check.C) {
+	_, modifierErr := ParseShortcut("Hyper+A")
+	_, keyErr := ParseShortcut("Ctrl+Banana")
+	_, emptyErr := ParseShortcut("")
+
+	c.Check(modifierErr, check.NotNil)
+	c.Check(keyErr, check.NotNil)
+	c.Check(emptyErr, check.NotNil)
+}
+
+func (suite *ShortcutSuite) TestStringReturnsParsableText(c *check.C) {
+	for _, text := range []string{"Ctrl+S", "Ctrl+Shift+Z", "Delete", "Shift+Tab", "Alt+F4"} {
+		shortcut, err := ParseShortcut(text)
+		c.Assert(err, check.IsNil)
+		c.Check(shortcut.String(), check.Equals, text)
+	}
+}
+
+func (suite *ShortcutSuite) TestCharKeysAreLowercase(c *check.C) {
+	char, isChar := CharKey('S').Char()
+
+	c.Check(isChar, check.Equals, true)
+	c.Check(char, check.Equals, 's')
+	_, isChar = KeyEnter.Char()
+	c.Check(isChar, check.Equals, false)
 }
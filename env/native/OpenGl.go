@@ -41,6 +41,11 @@ func (native *OpenGl) BindBuffer(target uint32, buffer uint32) {
 	gl.BindBuffer(target, buffer)
 }
 
+// BindFramebuffer implements the opengl.OpenGl interface.
+func (native *OpenGl) BindFramebuffer(target uint32, framebuffer uint32) {
+	gl.BindFramebuffer(target, framebuffer)
+}
+
 // BindTexture implements the opengl.OpenGl interface.
 func (native *OpenGl) BindTexture(target uint32, texture uint32) {
 	gl.BindTexture(target, texture)
@@ -61,6 +66,11 @@ func (native *OpenGl) BufferData(target uint32, size int, data interface{}, usag
 	gl.BufferData(target, size, gl.Ptr(data), usage)
 }
 
+// CheckFramebufferStatus implements the opengl.OpenGl interface.
+func (native *OpenGl) CheckFramebufferStatus(target uint32) uint32 {
+	return gl.CheckFramebufferStatus(target)
+}
+
 // Clear implements the opengl.OpenGl interface.
 func (native *OpenGl) Clear(mask uint32) {
 	gl.Clear(mask)
@@ -91,6 +101,11 @@ func (native *OpenGl) DeleteBuffers(buffers []uint32) {
 	gl.DeleteBuffers(int32(len(buffers)), (*uint32)(&buffers[0]))
 }
 
+// DeleteFramebuffers implements the opengl.OpenGl interface.
+func (native *OpenGl) DeleteFramebuffers(framebuffers []uint32) {
+	gl.DeleteFramebuffers(int32(len(framebuffers)), (*uint32)(&framebuffers[0]))
+}
+
 // DeleteProgram implements the opengl.OpenGl interface.
 func (native *OpenGl) DeleteProgram(program uint32) {
 	gl.DeleteProgram(program)
@@ -131,6 +146,11 @@ func (native *OpenGl) EnableVertexAttribArray(index uint32) {
 	gl.EnableVertexAttribArray(index)
 }
 
+// FramebufferTexture2D implements the opengl.OpenGl interface.
+func (native *OpenGl) FramebufferTexture2D(target uint32, attachment uint32, texTarget uint32, texture uint32, level int32) {
+	gl.FramebufferTexture2D(target, attachment, texTarget, texture, level)
+}
+
 // GenerateMipmap implements the opengl.OpenGl interface.
 func (native *OpenGl) GenerateMipmap(target uint32) {
 	gl.GenerateMipmap(target)
@@ -143,6 +163,13 @@ func (native *OpenGl) GenBuffers(n int32) []uint32 {
 	return buffers
 }
 
+// GenFramebuffers implements the opengl.OpenGl interface.
+func (native *OpenGl) GenFramebuffers(n int32) []uint32 {
+	ids := make([]uint32, n, n)
+	gl.GenFramebuffers(n, &ids[0])
+	return ids
+}
+
 // GenTextures implements the opengl.OpenGl interface.
 func (native *OpenGl) GenTextures(n int32) []uint32 {
 	ids := make([]uint32, n, n)
@@ -162,10 +162,10 @@ func (window *OpenGlWindow) onKey(rawWindow *glfw.Window, glfwKey glfw.Key, scan
 		} else if action == glfw.Release {
 			window.keyBuffer.KeyUp(key, modifier)
 		}
-	} else if action != glfw.Release {
-		keyName := glfw.GetKeyName(glfwKey, scancode)
-		if key, knownKey = keys.ResolveShortcut(keyName, modifier); knownKey {
-			window.CallKey(key, modifier)
+	} else if (action != glfw.Release) && (modifier.Without(keys.ModShift) != keys.ModNone) {
+		keyName := []rune(glfw.GetKeyName(glfwKey, scancode))
+		if len(keyName) == 1 {
+			window.CallKey(keys.CharKey(keyName[0]), modifier)
 		}
 	}
 }
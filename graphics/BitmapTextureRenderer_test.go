@@ -0,0 +1,74 @@
+package graphics
+
+import (
+	mgl "github.com/go-gl/mathgl/mgl32"
+
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/opengl"
+	"github.com/inkyblackness/shocked-client/opengl/software"
+)
+
+type BitmapTextureRendererSuite struct {
+	gl         *software.OpenGl
+	projection mgl.Mat4
+	view       mgl.Mat4
+	palette    *PaletteTexture
+	renderer   *BitmapTextureRenderer
+}
+
+var _ = check.Suite(&BitmapTextureRendererSuite{})
+
+func (suite *BitmapTextureRendererSuite) SetUpTest(c *check.C) {
+	suite.gl = software.NewOpenGl(64, 64)
+	suite.gl.ClearColor(0.0, 0.0, 0.0, 1.0)
+	suite.gl.Clear(opengl.COLOR_BUFFER_BIT)
+	suite.gl.Enable(opengl.BLEND)
+	suite.gl.BlendFunc(opengl.SRC_ALPHA, opengl.ONE_MINUS_SRC_ALPHA)
+	suite.projection = mgl.Ortho2D(0, 64, 64, 0)
+	suite.view = mgl.Ident4()
+	suite.palette = NewPaletteTexture(suite.gl, func(index int) (byte, byte, byte, byte) {
+		return byte(index), byte(255 - index), byte(index / 2), 255
+	})
+	renderContext := NewBasicRenderContext(suite.gl, &suite.projection, &suite.view)
+	suite.renderer = NewBitmapTextureRenderer(renderContext, suite.palette)
+}
+
+func (suite *BitmapTextureRendererSuite) TearDownTest(c *check.C) {
+	suite.renderer.Dispose()
+	suite.palette.Dispose()
+}
+
+func (suite *BitmapTextureRendererSuite) aBitmap(width, height int) *BitmapTexture {
+	pixels := make([]byte, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if (x+y)%5 != 0 {
+				pixels[y*width+x] = byte(x*16 + y)
+			}
+		}
+	}
+	return NewBitmapTexture(suite.gl, width, height, pixels)
+}
+
+func (suite *BitmapTextureRendererSuite) TestRenderDrawsBitmapWithPaletteAndTransparency(c *check.C) {
+	bitmap := suite.aBitmap(12, 6)
+	defer bitmap.Dispose()
+	u, v := bitmap.UV()
+	modelMatrix := mgl.Translate3D(4, 8, 0).Mul4(mgl.Scale3D(48, 24, 1))
+
+	suite.renderer.Render(&modelMatrix, bitmap, RectByCoord(0, 0, u, v))
+
+	c.Check(software.CheckGoldenImage(suite.gl.Image(), "testdata/BitmapTextureRenderer_Render.png", *updateGolden), check.IsNil)
+}
+
+func (suite *BitmapTextureRendererSuite) TestRenderDrawsPartOfBitmap(c *check.C) {
+	bitmap := suite.aBitmap(12, 6)
+	defer bitmap.Dispose()
+	u, v := bitmap.UV()
+	modelMatrix := mgl.Translate3D(16, 16, 0).Mul4(mgl.Scale3D(32, 32, 1))
+
+	suite.renderer.Render(&modelMatrix, bitmap, RectByCoord(u/2, 0, u, v/2))
+
+	c.Check(software.CheckGoldenImage(suite.gl.Image(), "testdata/BitmapTextureRenderer_RenderPart.png", *updateGolden), check.IsNil)
+}
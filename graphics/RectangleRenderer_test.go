@@ -0,0 +1,45 @@
+package graphics
+
+import (
+	mgl "github.com/go-gl/mathgl/mgl32"
+
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/opengl"
+	"github.com/inkyblackness/shocked-client/opengl/software"
+)
+
+type RectangleRendererSuite struct {
+	gl         *software.OpenGl
+	projection mgl.Mat4
+	renderer   *RectangleRenderer
+}
+
+var _ = check.Suite(&RectangleRendererSuite{})
+
+func (suite *RectangleRendererSuite) SetUpTest(c *check.C) {
+	suite.gl = software.NewOpenGl(64, 64)
+	suite.gl.ClearColor(0.0, 0.0, 0.0, 1.0)
+	suite.gl.Clear(opengl.COLOR_BUFFER_BIT)
+	suite.gl.Enable(opengl.BLEND)
+	suite.gl.BlendFunc(opengl.SRC_ALPHA, opengl.ONE_MINUS_SRC_ALPHA)
+	suite.projection = mgl.Ortho2D(0, 64, 64, 0)
+	suite.renderer = NewRectangleRenderer(suite.gl, &suite.projection)
+}
+
+func (suite *RectangleRendererSuite) TearDownTest(c *check.C) {
+	suite.renderer.Dispose()
+}
+
+func (suite *RectangleRendererSuite) TestFillRendersSolidRectangle(c *check.C) {
+	suite.renderer.Fill(8, 8, 40, 24, RGBA(1.0, 0.0, 0.0, 1.0))
+
+	c.Check(software.CheckGoldenImage(suite.gl.Image(), "testdata/RectangleRenderer_Fill.png", *updateGolden), check.IsNil)
+}
+
+func (suite *RectangleRendererSuite) TestFillBlendsTranslucentRectangles(c *check.C) {
+	suite.renderer.Fill(8, 8, 40, 24, RGBA(1.0, 0.0, 0.0, 1.0))
+	suite.renderer.Fill(24, 16, 56, 56, RGBA(0.0, 0.0, 1.0, 0.5))
+
+	c.Check(software.CheckGoldenImage(suite.gl.Image(), "testdata/RectangleRenderer_FillBlended.png", *updateGolden), check.IsNil)
+}
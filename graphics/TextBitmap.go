@@ -44,3 +44,12 @@ func (bmp TextBitmap) CharOffset(line, char int) int {
 
 	return offset
 }
+
+// CharCount returns the number of characters in given line.
+// An unknown line results in a count of zero.
+func (bmp TextBitmap) CharCount(line int) (count int) {
+	if (line >= 0) && (line < bmp.LineCount()) {
+		count = len(bmp.offsets[line]) - 1
+	}
+	return
+}
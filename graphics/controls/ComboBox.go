@@ -2,8 +2,10 @@ package controls
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/inkyblackness/shocked-client/env"
+	"github.com/inkyblackness/shocked-client/env/keys"
 	"github.com/inkyblackness/shocked-client/graphics"
 	"github.com/inkyblackness/shocked-client/ui"
 	"github.com/inkyblackness/shocked-client/ui/events"
@@ -34,6 +36,10 @@ type ComboBox struct {
 	listItemCount  int
 	listItemLabels []*Label
 	listStartIndex int
+
+	filter           string
+	filteredItems    []ComboBoxItem
+	highlightedIndex int
 }
 
 // Dispose releases the resources.
@@ -48,6 +54,7 @@ func (box *ComboBox) Dispose() {
 func (box *ComboBox) SetItems(items []ComboBoxItem) {
 	box.hideList()
 	box.items = items
+	box.filteredItems = items
 	box.listStartIndex = 0
 }
 
@@ -55,11 +62,7 @@ func (box *ComboBox) SetItems(items []ComboBoxItem) {
 func (box *ComboBox) SetSelectedItem(item ComboBoxItem) {
 	if box.selectedItem != item {
 		box.selectedItem = item
-		if box.selectedItem != nil {
-			box.selectedLabel.SetText(fmt.Sprintf("%v", item))
-		} else {
-			box.selectedLabel.SetText("")
-		}
+		box.updateSelectedLabel()
 	}
 }
 
@@ -68,6 +71,10 @@ func (box *ComboBox) onRender(area *ui.Area) {
 		graphics.RGBA(0.31, 0.56, 0.34, 0.8))
 }
 
+func (box *ComboBox) itemText(item ComboBoxItem) string {
+	return fmt.Sprintf("%v", item)
+}
+
 func (box *ComboBox) onMouseDown(area *ui.Area, event events.Event) (consumed bool) {
 	mouseEvent := event.(*events.MouseButtonEvent)
 
@@ -97,6 +104,9 @@ func (box *ComboBox) showList() {
 		boxTop := box.area.Top().Value()
 		boxBottom := box.area.Bottom().Value()
 		boxHeight := boxBottom - boxTop
+		box.filter = ""
+		box.filteredItems = box.items
+		box.highlightedIndex = box.indexOfItem(box.selectedItem)
 		box.listItemCount = len(box.items)
 		if box.listItemCount > 6 {
 			box.listItemCount = 6
@@ -119,7 +129,10 @@ func (box *ComboBox) showList() {
 		listAreaBuilder.OnEvent(events.MouseButtonDownEventType, box.onListMouseDown)
 		listAreaBuilder.OnEvent(events.MouseButtonUpEventType, box.onListMouseUp)
 		listAreaBuilder.OnEvent(events.MouseScrollEventType, box.onListScroll)
-		listAreaBuilder.OnEvent(events.MouseButtonClickedEventType, ui.SilentConsumer)
+		listAreaBuilder.OnEvent(events.MouseButtonClickedEventType, box.onListMouseClicked)
+		listAreaBuilder.OnEvent(events.KeyEventType, box.onListKey)
+		listAreaBuilder.OnEvent(events.CharEventType, box.onListChar)
+		listAreaBuilder.OnEvent(events.FocusLostEventType, box.onListFocusLost)
 
 		box.listArea = listAreaBuilder.Build()
 		box.listArea.RequestFocus()
@@ -138,37 +151,138 @@ func (box *ComboBox) showList() {
 			box.listItemLabels[listIndex] = box.labelBuilder.Build()
 			lastBottom = nextBottom
 		}
+		box.scrollToHighlighted()
 		box.updateListItemLabels()
 	}
 }
 
 func (box *ComboBox) hideList() {
 	if box.listArea != nil {
-		box.listArea.Remove()
+		listArea := box.listArea
 		box.listArea = nil
+		if listArea.HasFocus() {
+			listArea.ReleaseFocus()
+		}
+		listArea.Remove()
 		for _, label := range box.listItemLabels {
 			label.Dispose()
 		}
 		box.listItemLabels = nil
+		if box.filter != "" {
+			box.filter = ""
+			box.filteredItems = box.items
+			box.listStartIndex = 0
+			box.updateSelectedLabel()
+		}
+	}
+}
+
+func (box *ComboBox) updateSelectedLabel() {
+	if box.filter != "" {
+		box.selectedLabel.SetText("Search: " + box.filter)
+	} else if box.selectedItem != nil {
+		box.selectedLabel.SetText(box.itemText(box.selectedItem))
+	} else {
+		box.selectedLabel.SetText("")
 	}
 }
 
 func (box *ComboBox) updateListItemLabels() {
 	for listIndex, label := range box.listItemLabels {
-		label.SetText(fmt.Sprintf("%v", box.items[box.listStartIndex+listIndex]))
+		itemIndex := box.listStartIndex + listIndex
+		if itemIndex < len(box.filteredItems) {
+			label.SetText(box.itemText(box.filteredItems[itemIndex]))
+		} else {
+			label.SetText("")
+		}
+	}
+}
+
+func (box *ComboBox) indexOfItem(item ComboBoxItem) int {
+	for index, filteredItem := range box.filteredItems {
+		if filteredItem == item {
+			return index
+		}
+	}
+	return -1
+}
+
+// setFilter narrows the listed items to those containing the given text, ignoring case.
+func (box *ComboBox) setFilter(filter string) {
+	box.filter = filter
+	if filter == "" {
+		box.filteredItems = box.items
+	} else {
+		lowerFilter := strings.ToLower(filter)
+		box.filteredItems = nil
+		for _, item := range box.items {
+			if strings.Contains(strings.ToLower(box.itemText(item)), lowerFilter) {
+				box.filteredItems = append(box.filteredItems, item)
+			}
+		}
+	}
+	box.highlightedIndex = box.indexOfItem(box.selectedItem)
+	if (box.highlightedIndex < 0) && (len(box.filteredItems) > 0) {
+		box.highlightedIndex = 0
+	}
+	box.listStartIndex = 0
+	box.scrollToHighlighted()
+	box.updateListItemLabels()
+	box.updateSelectedLabel()
+}
+
+// scrollToHighlighted ensures the highlighted item is visible in the list.
+func (box *ComboBox) scrollToHighlighted() {
+	if box.highlightedIndex >= 0 {
+		if box.highlightedIndex < box.listStartIndex {
+			box.listStartIndex = box.highlightedIndex
+		} else if box.highlightedIndex >= (box.listStartIndex + box.listItemCount) {
+			box.listStartIndex = box.highlightedIndex - box.listItemCount + 1
+		}
+	}
+	maxStartIndex := len(box.filteredItems) - box.listItemCount
+	if box.listStartIndex > maxStartIndex {
+		box.listStartIndex = maxStartIndex
+	}
+	if box.listStartIndex < 0 {
+		box.listStartIndex = 0
+	}
+}
+
+func (box *ComboBox) moveHighlight(delta int) {
+	count := len(box.filteredItems)
+	if count > 0 {
+		box.highlightedIndex += delta
+		if box.highlightedIndex < 0 {
+			box.highlightedIndex = 0
+		} else if box.highlightedIndex >= count {
+			box.highlightedIndex = count - 1
+		}
+		box.scrollToHighlighted()
+		box.updateListItemLabels()
 	}
 }
 
 func (box *ComboBox) onListRender(area *ui.Area) {
-	box.rectRenderer.Fill(area.Left().Value(), area.Top().Value(), area.Right().Value(), area.Bottom().Value(),
-		graphics.RGBA(0.31, 0.56, 0.34, 0.7))
+	areaLeft, areaTop := area.Left().Value(), area.Top().Value()
+	areaRight, areaBottom := area.Right().Value(), area.Bottom().Value()
+	box.rectRenderer.Fill(areaLeft, areaTop, areaRight, areaBottom, graphics.RGBA(0.31, 0.56, 0.34, 0.7))
+
+	listIndex := box.highlightedIndex - box.listStartIndex
+	if (box.highlightedIndex >= 0) && (listIndex >= 0) && (listIndex < box.listItemCount) {
+		itemHeight := (areaBottom - areaTop) / float32(box.listItemCount)
+		itemTop := areaTop + itemHeight*float32(listIndex)
+		box.rectRenderer.Fill(areaLeft, itemTop, areaRight, itemTop+itemHeight, graphics.RGBA(0.56, 0.69, 0.36, 0.5))
+	}
 }
 
 func (box *ComboBox) onListMouseDown(area *ui.Area, event events.Event) (consumed bool) {
 	mouseEvent := event.(*events.MouseButtonEvent)
 
-	if mouseEvent.Buttons() == env.MousePrimary {
-		box.listArea.RequestFocus()
+	if !box.contains(area, mouseEvent) {
+		consumed = box.contains(box.area, mouseEvent)
+		box.hideList()
+	} else if mouseEvent.Buttons() == env.MousePrimary {
 		consumed = true
 	}
 
@@ -179,16 +293,15 @@ func (box *ComboBox) onListMouseUp(area *ui.Area, event events.Event) (consumed
 	mouseEvent := event.(*events.MouseButtonEvent)
 
 	if mouseEvent.AffectedButtons() == env.MousePrimary {
-		if box.listArea != nil {
-			if box.listArea.HasFocus() {
-				box.listArea.ReleaseFocus()
-			}
-			if box.contains(box.listArea, mouseEvent) {
-				_, mouseY := mouseEvent.Position()
-				chosenItem := ((mouseY - box.listArea.Top().Value()) * float32(box.listItemCount)) /
-					(box.listArea.Bottom().Value() - box.listArea.Top().Value())
+		if (box.listArea != nil) && box.contains(box.listArea, mouseEvent) {
+			_, mouseY := mouseEvent.Position()
+			chosenItem := ((mouseY - box.listArea.Top().Value()) * float32(box.listItemCount)) /
+				(box.listArea.Bottom().Value() - box.listArea.Top().Value())
+			itemIndex := box.listStartIndex + int(chosenItem)
+			if itemIndex < len(box.filteredItems) {
+				item := box.filteredItems[itemIndex]
 				box.hideList()
-				box.onItemChosen(box.items[box.listStartIndex+int(chosenItem)])
+				box.onItemChosen(item)
 			}
 		}
 		consumed = true
@@ -197,6 +310,54 @@ func (box *ComboBox) onListMouseUp(area *ui.Area, event events.Event) (consumed
 	return
 }
 
+func (box *ComboBox) onListMouseClicked(area *ui.Area, event events.Event) bool {
+	return box.contains(area, event.(*events.MouseButtonEvent))
+}
+
+func (box *ComboBox) onListKey(area *ui.Area, event events.Event) (consumed bool) {
+	keyEvent := event.(*events.KeyEvent)
+
+	consumed = true
+	switch keys.Key(keyEvent.Key()) {
+	case keys.KeyUp:
+		box.moveHighlight(-1)
+	case keys.KeyDown:
+		box.moveHighlight(1)
+	case keys.KeyPageUp:
+		box.moveHighlight(-box.listItemCount)
+	case keys.KeyPageDown:
+		box.moveHighlight(box.listItemCount)
+	case keys.KeyEnter:
+		if (box.highlightedIndex >= 0) && (box.highlightedIndex < len(box.filteredItems)) {
+			item := box.filteredItems[box.highlightedIndex]
+			box.hideList()
+			box.onItemChosen(item)
+		}
+	case keys.KeyEscape:
+		box.hideList()
+	case keys.KeyBackspace:
+		if box.filter != "" {
+			filterRunes := []rune(box.filter)
+			box.setFilter(string(filterRunes[:len(filterRunes)-1]))
+		}
+	default:
+		consumed = false
+	}
+
+	return
+}
+
+func (box *ComboBox) onListChar(area *ui.Area, event events.Event) bool {
+	charEvent := event.(*events.CharEvent)
+	box.setFilter(box.filter + string(charEvent.Char()))
+	return true
+}
+
+func (box *ComboBox) onListFocusLost(area *ui.Area, event events.Event) bool {
+	box.hideList()
+	return true
+}
+
 func (box *ComboBox) onItemChosen(item ComboBoxItem) {
 	if item != box.selectedItem {
 		box.SetSelectedItem(item)
@@ -206,9 +367,15 @@ func (box *ComboBox) onItemChosen(item ComboBoxItem) {
 
 func (box *ComboBox) onListScroll(area *ui.Area, event events.Event) (consumed bool) {
 	mouseEvent := event.(*events.MouseScrollEvent)
+	if !box.contains(area, mouseEvent) {
+		return
+	}
 	_, dy := mouseEvent.Deltas()
 	toScroll := func(available int) int {
 		result := 1
+		if available < 0 {
+			available = 0
+		}
 		if result > available {
 			result = available
 		}
@@ -219,7 +386,7 @@ func (box *ComboBox) onListScroll(area *ui.Area, event events.Event) (consumed b
 		available := box.listStartIndex
 		box.listStartIndex -= toScroll(available)
 	} else if dy > 0 {
-		available := len(box.items) - (box.listStartIndex + box.listItemCount)
+		available := len(box.filteredItems) - (box.listStartIndex + box.listItemCount)
 		box.listStartIndex += toScroll(available)
 	}
 	box.updateListItemLabels()
@@ -0,0 +1,143 @@
+package controls
+
+import (
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/env"
+	"github.com/inkyblackness/shocked-client/env/keys"
+	"github.com/inkyblackness/shocked-client/ui"
+	"github.com/inkyblackness/shocked-client/ui/events"
+)
+
+type testingComboBoxItem string
+
+func (item testingComboBoxItem) String() string {
+	return string(item)
+}
+
+type ComboBoxSuite struct {
+	root   *ui.Area
+	box    *ComboBox
+	chosen []ComboBoxItem
+	items  []ComboBoxItem
+}
+
+var _ = check.Suite(&ComboBoxSuite{})
+
+func (suite *ComboBoxSuite) SetUpTest(c *check.C) {
+	rootBuilder := ui.NewAreaBuilder()
+	rootBuilder.SetRight(ui.NewAbsoluteAnchor(200.0))
+	rootBuilder.SetBottom(ui.NewAbsoluteAnchor(200.0))
+	suite.root = rootBuilder.Build()
+	suite.chosen = nil
+
+	builder := NewComboBoxBuilder(aTestingLabelBuilder(), nil)
+	builder.SetParent(suite.root)
+	builder.SetRight(ui.NewAbsoluteAnchor(100.0))
+	builder.SetBottom(ui.NewAbsoluteAnchor(10.0))
+	builder.WithSelectionChangeHandler(func(item ComboBoxItem) { suite.chosen = append(suite.chosen, item) })
+	suite.box = builder.Build()
+	suite.items = []ComboBoxItem{}
+	for _, text := range []string{"Alpha", "Beta", "Gamma", "Delta", "Epsilon", "Zeta", "Eta", "Theta", "Iota", "Kappa"} {
+		suite.items = append(suite.items, testingComboBoxItem(text))
+	}
+	suite.box.SetItems(suite.items)
+}
+
+func (suite *ComboBoxSuite) mouseButton(eventType events.EventType, x, y float32) {
+	suite.root.DispatchPositionalEvent(events.NewMouseButtonEvent(eventType,
+		x, y, 0, env.MousePrimary, env.MousePrimary))
+}
+
+func (suite *ComboBoxSuite) openList() {
+	suite.mouseButton(events.MouseButtonDownEventType, 50, 5)
+	suite.mouseButton(events.MouseButtonUpEventType, 50, 5)
+}
+
+func (suite *ComboBoxSuite) key(key keys.Key) {
+	suite.root.HandleEvent(events.NewKeyEvent(int(key), 0))
+}
+
+func (suite *ComboBoxSuite) typeText(text string) {
+	for _, char := range text {
+		suite.root.HandleEvent(events.NewCharEvent(char))
+	}
+}
+
+func (suite *ComboBoxSuite) TestTypingNarrowsItems(c *check.C) {
+	suite.openList()
+	suite.typeText("ETA")
+
+	c.Check(suite.box.filteredItems, check.DeepEquals,
+		[]ComboBoxItem{testingComboBoxItem("Beta"), testingComboBoxItem("Zeta"), testingComboBoxItem("Eta"), testingComboBoxItem("Theta")})
+	c.Check(suite.box.listItemLabels[0].text, check.Equals, "Beta")
+	c.Check(suite.box.listItemLabels[3].text, check.Equals, "Theta")
+	c.Check(suite.box.listItemLabels[4].text, check.Equals, "")
+}
+
+func (suite *ComboBoxSuite) TestBackspaceWidensFilter(c *check.C) {
+	suite.openList()
+	suite.typeText("alp")
+	suite.key(keys.KeyBackspace)
+	suite.key(keys.KeyBackspace)
+
+	c.Check(suite.box.filter, check.Equals, "a")
+	c.Check(len(suite.box.filteredItems), check.Equals, 9)
+}
+
+func (suite *ComboBoxSuite) TestEnterSelectsHighlightedItem(c *check.C) {
+	suite.openList()
+	suite.typeText("eta")
+	suite.key(keys.KeyDown)
+	suite.key(keys.KeyEnter)
+
+	c.Check(suite.chosen, check.DeepEquals, []ComboBoxItem{testingComboBoxItem("Zeta")})
+	c.Check(suite.box.listArea, check.IsNil)
+	c.Check(suite.box.selectedLabel.text, check.Equals, "Zeta")
+}
+
+func (suite *ComboBoxSuite) TestListScrollsToHighlightedItem(c *check.C) {
+	suite.openList()
+	for i := 0; i < 8; i++ {
+		suite.key(keys.KeyDown)
+	}
+
+	c.Check(suite.box.highlightedIndex, check.Equals, 7)
+	c.Check(suite.box.listStartIndex, check.Equals, 2)
+	c.Check(suite.box.listItemLabels[5].text, check.Equals, "Theta")
+}
+
+func (suite *ComboBoxSuite) TestListStartsAtSelectedItem(c *check.C) {
+	suite.box.SetSelectedItem(suite.items[9])
+	suite.openList()
+
+	c.Check(suite.box.highlightedIndex, check.Equals, 9)
+	c.Check(suite.box.listStartIndex, check.Equals, 4)
+}
+
+func (suite *ComboBoxSuite) TestClickingFilteredItemSelectsIt(c *check.C) {
+	suite.openList()
+	suite.typeText("eta")
+	suite.mouseButton(events.MouseButtonDownEventType, 50, 25)
+	suite.mouseButton(events.MouseButtonUpEventType, 50, 25)
+
+	c.Check(suite.chosen, check.DeepEquals, []ComboBoxItem{testingComboBoxItem("Zeta")})
+}
+
+func (suite *ComboBoxSuite) TestEscapeClosesListAndRestoresSelection(c *check.C) {
+	suite.box.SetSelectedItem(suite.items[1])
+	suite.openList()
+	suite.typeText("x")
+	suite.key(keys.KeyEscape)
+
+	c.Check(suite.box.listArea, check.IsNil)
+	c.Check(suite.box.selectedLabel.text, check.Equals, "Beta")
+	c.Check(suite.chosen, check.IsNil)
+}
+
+func (suite *ComboBoxSuite) TestClickingOutsideClosesList(c *check.C) {
+	suite.openList()
+	suite.mouseButton(events.MouseButtonDownEventType, 150, 150)
+
+	c.Check(suite.box.listArea, check.IsNil)
+}
@@ -6,6 +6,8 @@ import (
 
 	mgl "github.com/go-gl/mathgl/mgl32"
 
+	"github.com/inkyblackness/shocked-client/env"
+	"github.com/inkyblackness/shocked-client/env/keys"
 	"github.com/inkyblackness/shocked-client/graphics"
 	"github.com/inkyblackness/shocked-client/ui"
 	"github.com/inkyblackness/shocked-client/ui/events"
@@ -27,8 +29,10 @@ type Label struct {
 	textPainter     graphics.TextPainter
 	texturizer      BitmapTexturizer
 	textureRenderer graphics.TextureRenderer
+	rectRenderer    *graphics.RectangleRenderer
 
 	textChangeRequestHandler TextChangeRequestHandler
+	editOnClick              bool
 
 	scale             float32
 	horizontalAligner Aligner
@@ -36,6 +40,18 @@ type Label struct {
 
 	bitmap  graphics.TextBitmap
 	texture *graphics.BitmapTexture
+
+	multiLine bool
+	editor    *textEditor
+	layout    []textPosition
+	textLeft  float32
+	textTop   float32
+}
+
+// textPosition is the location of a caret position within the text bitmap.
+type textPosition struct {
+	line   int
+	column int
 }
 
 // Dispose releases all resources and removes the area from the tree.
@@ -48,14 +64,18 @@ func (label *Label) Dispose() {
 }
 
 // SetText updates the current label text.
+// While the user is editing the text, the new text is only shown after editing ended.
 func (label *Label) SetText(text string) {
 	label.text = text
-	label.updateTextBitmap()
+	if label.editor == nil {
+		label.updateTextBitmap()
+	}
 }
 
 // AllowTextChange enables the label to receive text updates from the user.
 func (label *Label) AllowTextChange(handler TextChangeRequestHandler) {
 	label.textChangeRequestHandler = handler
+	label.editOnClick = handler != nil
 }
 
 func (label *Label) updateTextBitmap() {
@@ -67,10 +87,43 @@ func (label *Label) updateTextBitmap() {
 	if label.fitToWidth {
 		widthLimit = int(label.lastWidth / label.scale)
 	}
-	label.bitmap = label.textPainter.Paint(label.text, widthLimit)
+	if label.editor != nil {
+		label.bitmap = label.textPainter.Paint(label.editor.String(), widthLimit)
+		label.updateLayout()
+	} else {
+		label.bitmap = label.textPainter.Paint(label.text, widthLimit)
+		label.layout = nil
+	}
 	label.texture = label.texturizer(&label.bitmap.Bitmap)
 }
 
+// updateLayout determines the location of each caret position within the bitmap.
+// A position is placed where the character following it is painted; The painter
+// moves characters to the next line if they don't fit.
+func (label *Label) updateLayout() {
+	text := label.editor.text
+	label.layout = make([]textPosition, len(text)+1)
+	line, column := 0, 0
+	lineCount := label.bitmap.LineCount()
+
+	for index, char := range text {
+		if char != '\n' {
+			for (column >= label.bitmap.CharCount(line)) && ((line + 1) < lineCount) {
+				line++
+				column = 0
+			}
+		}
+		label.layout[index] = textPosition{line: line, column: column}
+		if char == '\n' {
+			line++
+			column = 0
+		} else {
+			column++
+		}
+	}
+	label.layout[len(text)] = textPosition{line: line, column: column}
+}
+
 func (label *Label) onRender(area *ui.Area) {
 	u, v := label.texture.UV()
 	fromLeft := float32(0.0)
@@ -96,6 +149,7 @@ func (label *Label) onRender(area *ui.Area) {
 
 	toLeft := areaLeft + label.horizontalAligner(areaWidth, scaledWidth)
 	toTop := areaTop + label.verticalAligner(areaHeight, scaledHeight)
+	label.textLeft, label.textTop = toLeft, toTop
 	toRight := toLeft + scaledWidth
 	toBottom := toTop + scaledHeight
 
@@ -118,17 +172,319 @@ func (label *Label) onRender(area *ui.Area) {
 
 	modelMatrix := mgl.Ident4().Mul4(mgl.Translate3D(toLeft, toTop, 0.0)).Mul4(mgl.Scale3D(toRight-toLeft, toBottom-toTop, 1.0))
 
+	if label.editor != nil {
+		label.renderEditState(area)
+	}
 	label.textureRenderer.Render(&modelMatrix, label.texture, graphics.RectByCoord(fromLeft, fromTop, fromRight, fromBottom))
 }
 
+func (label *Label) renderEditState(area *ui.Area) {
+	areaLeft, areaTop := area.Left().Value(), area.Top().Value()
+	areaRight, areaBottom := area.Right().Value(), area.Bottom().Value()
+	fill := func(left, top, right, bottom float32, color graphics.Color) {
+		if left < areaLeft {
+			left = areaLeft
+		}
+		if right > areaRight {
+			right = areaRight
+		}
+		if top < areaTop {
+			top = areaTop
+		}
+		if bottom > areaBottom {
+			bottom = areaBottom
+		}
+		if (left < right) && (top < bottom) {
+			label.rectRenderer.Fill(left, top, right, bottom, color)
+		}
+	}
+	from, to := label.editor.selection()
+
+	for index := from; index < to; index++ {
+		if label.editor.text[index] != '\n' {
+			left, top, bottom := label.positionRect(index)
+			pos := label.layout[index]
+			right := label.textLeft + float32(1+label.bitmap.CharOffset(pos.line, pos.column+1))*label.scale
+			fill(left, top, right, bottom, graphics.RGBA(0.31, 0.56, 0.34, 0.5))
+		}
+	}
+	left, top, bottom := label.positionRect(label.editor.caret)
+	fill(left, top, left+label.scale, bottom, graphics.RGBA(0.56, 0.69, 0.36, 1.0))
+}
+
+// positionRect returns the screen coordinates of given caret position.
+func (label *Label) positionRect(index int) (left, top, bottom float32) {
+	pos := label.layout[index]
+	lineHeight := label.bitmap.LineHeight()
+	left = label.textLeft + float32(1+label.bitmap.CharOffset(pos.line, pos.column))*label.scale
+	top = label.textTop + float32(1+pos.line*lineHeight)*label.scale
+	bottom = top + float32(lineHeight-1)*label.scale
+	return
+}
+
+// positionAt returns the caret position closest to given screen coordinates.
+func (label *Label) positionAt(x, y float32) int {
+	lineHeight := label.bitmap.LineHeight()
+	line := 0
+	if lineHeight > 0 {
+		line = int((y - label.textTop - label.scale) / (float32(lineHeight) * label.scale))
+	}
+	return label.positionInLine(line, x)
+}
+
+// positionInLine returns the caret position of given line that is closest
+// to given horizontal screen coordinate. Lines out of range are limited to the
+// first and last line of the text.
+func (label *Label) positionInLine(line int, x float32) int {
+	lastLine := label.layout[len(label.layout)-1].line
+	if line < label.layout[0].line {
+		line = label.layout[0].line
+	} else if line > lastLine {
+		line = lastLine
+	}
+	result := -1
+	closest := float32(0.0)
+	for index, pos := range label.layout {
+		if pos.line == line {
+			left, _, _ := label.positionRect(index)
+			distance := left - x
+			if distance < 0 {
+				distance = -distance
+			}
+			if (result < 0) || (distance < closest) {
+				result, closest = index, distance
+			}
+		}
+	}
+	if result < 0 {
+		result = label.nearestPositionOfLine(line)
+	}
+	return result
+}
+
+func (label *Label) nearestPositionOfLine(line int) int {
+	for index, pos := range label.layout {
+		if pos.line >= line {
+			return index
+		}
+	}
+	return len(label.layout) - 1
+}
+
+func (label *Label) lineStart(index int) int {
+	line := label.layout[index].line
+	for (index > 0) && (label.layout[index-1].line == line) {
+		index--
+	}
+	return index
+}
+
+func (label *Label) lineEnd(index int) int {
+	line := label.layout[index].line
+	for ((index + 1) < len(label.layout)) && (label.layout[index+1].line == line) {
+		index++
+	}
+	return index
+}
+
+// IsEditing returns true while the user is editing the text.
+func (label *Label) IsEditing() bool {
+	return label.editor != nil
+}
+
+// Edit starts editing the text, with all of it selected, if text changes are allowed.
+func (label *Label) Edit() {
+	if label.editOnClick {
+		if label.editor == nil {
+			label.startEditing()
+		}
+		label.editor.selectAll()
+	}
+}
+
+func (label *Label) startEditing() {
+	label.editor = newTextEditor(label.text)
+	label.updateTextBitmap()
+	if !label.area.HasFocus() {
+		label.area.RequestFocus()
+	}
+}
+
+func (label *Label) commitEditing() {
+	if label.editor != nil {
+		newText := label.editor.String()
+		label.stopEditing()
+		if newText != label.text {
+			label.textChangeRequestHandler(newText)
+		}
+	}
+}
+
+func (label *Label) stopEditing() {
+	if label.editor != nil {
+		label.editor = nil
+		label.updateTextBitmap()
+		if label.area.HasFocus() {
+			label.area.ReleaseFocus()
+		}
+	}
+}
+
+// textContains returns true if given screen coordinates are within the painted text.
+func (label *Label) textContains(x, y float32) bool {
+	right := label.textLeft + float32(label.bitmap.Width)*label.scale
+	bottom := label.textTop + float32(label.bitmap.Height)*label.scale
+
+	return (x >= label.textLeft) && (x < right) && (y >= label.textTop) && (y < bottom)
+}
+
+func (label *Label) contains(event events.PositionalEvent) bool {
+	x, y := event.Position()
+
+	return (x >= label.area.Left().Value()) && (x < label.area.Right().Value()) &&
+		(y >= label.area.Top().Value()) && (y < label.area.Bottom().Value())
+}
+
+func (label *Label) onMouseButtonDown(area *ui.Area, event events.Event) (consumed bool) {
+	mouseEvent := event.(*events.MouseButtonEvent)
+
+	if !label.editOnClick && (label.editor == nil) {
+		return
+	}
+	if !label.contains(mouseEvent) {
+		label.commitEditing()
+	} else if mouseEvent.AffectedButtons() == env.MousePrimary {
+		extend := keys.Modifier(mouseEvent.Modifier()).Has(keys.ModShift)
+		if label.editor == nil {
+			label.startEditing()
+			extend = false
+		}
+		x, y := mouseEvent.Position()
+		label.editor.moveTo(label.positionAt(x, y), extend)
+		consumed = true
+	} else {
+		consumed = label.editor != nil
+	}
+
+	return
+}
+
+func (label *Label) onMouseMove(area *ui.Area, event events.Event) (consumed bool) {
+	mouseEvent := event.(*events.MouseMoveEvent)
+
+	if (label.editor != nil) && (mouseEvent.Buttons() == env.MousePrimary) {
+		x, y := mouseEvent.Position()
+		label.editor.moveTo(label.positionAt(x, y), true)
+		consumed = true
+	}
+
+	return
+}
+
+func (label *Label) onMouseButtonEnd(area *ui.Area, event events.Event) bool {
+	mouseEvent := event.(*events.MouseButtonEvent)
+
+	return (label.editor != nil) && label.contains(mouseEvent)
+}
+
+func (label *Label) onKey(area *ui.Area, event events.Event) (consumed bool) {
+	keyEvent := event.(*events.KeyEvent)
+	editor := label.editor
+
+	if editor == nil {
+		return
+	}
+	key := keys.Key(keyEvent.Key())
+	modifier := keys.Modifier(keyEvent.Modifier())
+	extend := modifier.Has(keys.ModShift)
+	consumed = true
+	switch key {
+	case keys.KeyLeft:
+		editor.moveLeft(extend)
+	case keys.KeyRight:
+		editor.moveRight(extend)
+	case keys.KeyUp, keys.KeyDown:
+		pos := label.layout[editor.caret]
+		left, _, _ := label.positionRect(editor.caret)
+		if key == keys.KeyUp {
+			pos.line--
+		} else {
+			pos.line++
+		}
+		editor.moveTo(label.positionInLine(pos.line, left), extend)
+	case keys.KeyHome:
+		if modifier.Has(keys.ModControl) {
+			editor.moveTo(0, extend)
+		} else {
+			editor.moveTo(label.lineStart(editor.caret), extend)
+		}
+	case keys.KeyEnd:
+		if modifier.Has(keys.ModControl) {
+			editor.moveTo(editor.length(), extend)
+		} else {
+			editor.moveTo(label.lineEnd(editor.caret), extend)
+		}
+	case keys.KeyBackspace:
+		editor.deleteBackward()
+		label.updateTextBitmap()
+	case keys.KeyDelete:
+		editor.deleteForward()
+		label.updateTextBitmap()
+	case keys.KeyEnter:
+		if label.multiLine && !modifier.Has(keys.ModControl) {
+			editor.insert("\n")
+			label.updateTextBitmap()
+		} else {
+			label.commitEditing()
+		}
+	case keys.KeyEscape:
+		label.stopEditing()
+	default:
+		consumed = false
+	}
+
+	return
+}
+
+func (label *Label) onChar(area *ui.Area, event events.Event) (consumed bool) {
+	charEvent := event.(*events.CharEvent)
+
+	if label.editor != nil {
+		char := string(charEvent.Char())
+		if label.textPainter.Paint(char, 0).LineLength(0) > 0 {
+			label.editor.insert(char)
+			label.updateTextBitmap()
+		}
+		consumed = true
+	}
+
+	return
+}
+
+func (label *Label) onFocusLost(area *ui.Area, event events.Event) bool {
+	label.commitEditing()
+	return true
+}
+
 func (label *Label) onClipboardCopy(area *ui.Area, event events.Event) bool {
 	clipboardEvent := event.(*events.ClipboardEvent)
-	clipboardEvent.Clipboard().SetText(label.text)
+	if (label.editor != nil) && label.editor.hasSelection() {
+		clipboardEvent.Clipboard().SetText(label.editor.selectedText())
+	} else if label.editor != nil {
+		clipboardEvent.Clipboard().SetText(label.editor.String())
+	} else {
+		clipboardEvent.Clipboard().SetText(label.text)
+	}
 	return true
 }
 
 func (label *Label) onClipboardPaste(area *ui.Area, event events.Event) (result bool) {
-	if label.textChangeRequestHandler != nil {
+	if label.editor != nil {
+		clipboardEvent := event.(*events.ClipboardEvent)
+		label.editor.insert(clipboardEvent.Clipboard().Text())
+		label.updateTextBitmap()
+		result = true
+	} else if label.textChangeRequestHandler != nil {
 		clipboardEvent := event.(*events.ClipboardEvent)
 		newText := clipboardEvent.Clipboard().Text()
 		label.textChangeRequestHandler(newText)
@@ -13,8 +13,10 @@ type LabelBuilder struct {
 	textPainter     graphics.TextPainter
 	texturizer      BitmapTexturizer
 	textureRenderer graphics.TextureRenderer
+	rectRenderer    *graphics.RectangleRenderer
 
 	fitToWidth        bool
+	multiLine         bool
 	scale             float32
 	horizontalAligner Aligner
 	verticalAligner   Aligner
@@ -22,12 +24,13 @@ type LabelBuilder struct {
 
 // NewLabelBuilder returns a new instance of a LabelBuilder.
 func NewLabelBuilder(textPainter graphics.TextPainter, texturizer BitmapTexturizer,
-	textureRenderer graphics.TextureRenderer) *LabelBuilder {
+	textureRenderer graphics.TextureRenderer, rectRenderer *graphics.RectangleRenderer) *LabelBuilder {
 	builder := &LabelBuilder{
 		areaBuilder:       ui.NewAreaBuilder(),
 		textPainter:       textPainter,
 		texturizer:        texturizer,
 		textureRenderer:   textureRenderer,
+		rectRenderer:      rectRenderer,
 		scale:             1.0,
 		horizontalAligner: CenterAligner,
 		verticalAligner:   CenterAligner}
@@ -41,7 +44,9 @@ func (builder *LabelBuilder) Build() *Label {
 		textPainter:       builder.textPainter,
 		texturizer:        builder.texturizer,
 		textureRenderer:   builder.textureRenderer,
+		rectRenderer:      builder.rectRenderer,
 		fitToWidth:        builder.fitToWidth,
+		multiLine:         builder.multiLine,
 		scale:             builder.scale,
 		horizontalAligner: builder.horizontalAligner,
 		verticalAligner:   builder.verticalAligner}
@@ -50,6 +55,13 @@ func (builder *LabelBuilder) Build() *Label {
 	builder.areaBuilder.OnEvent(events.ClipboardCopyEventType, label.onClipboardCopy)
 	builder.areaBuilder.OnEvent(events.ClipboardPasteEventType, label.onClipboardPaste)
 	builder.areaBuilder.OnEvent(events.FileDropEventType, label.onFileDrop)
+	builder.areaBuilder.OnEvent(events.MouseButtonDownEventType, label.onMouseButtonDown)
+	builder.areaBuilder.OnEvent(events.MouseButtonUpEventType, label.onMouseButtonEnd)
+	builder.areaBuilder.OnEvent(events.MouseButtonClickedEventType, label.onMouseButtonEnd)
+	builder.areaBuilder.OnEvent(events.MouseMoveEventType, label.onMouseMove)
+	builder.areaBuilder.OnEvent(events.KeyEventType, label.onKey)
+	builder.areaBuilder.OnEvent(events.CharEventType, label.onChar)
+	builder.areaBuilder.OnEvent(events.FocusLostEventType, label.onFocusLost)
 	label.area = builder.areaBuilder.Build()
 	label.SetText("")
 
@@ -109,3 +121,10 @@ func (builder *LabelBuilder) SetFitToWidth() *LabelBuilder {
 	builder.fitToWidth = true
 	return builder
 }
+
+// SetMultiLine allows the text to span several lines while editing.
+// Enter then starts a new line, while Ctrl+Enter finishes editing.
+func (builder *LabelBuilder) SetMultiLine() *LabelBuilder {
+	builder.multiLine = true
+	return builder
+}
@@ -0,0 +1,225 @@
+package controls
+
+import (
+	"encoding/base64"
+
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/env"
+	"github.com/inkyblackness/shocked-client/env/keys"
+	"github.com/inkyblackness/shocked-client/graphics"
+	"github.com/inkyblackness/shocked-client/ui"
+	"github.com/inkyblackness/shocked-client/ui/events"
+	"github.com/inkyblackness/shocked-model"
+)
+
+type LabelSuite struct {
+	root      *ui.Area
+	builder   *LabelBuilder
+	requested []string
+}
+
+var _ = check.Suite(&LabelSuite{})
+
+// aTestingLabelBuilder returns a label builder for a font with glyphs of two pixels width.
+// Created labels can not be rendered.
+func aTestingLabelBuilder() *LabelBuilder {
+	glyphCount := 96
+	offsets := make([]int, glyphCount+1)
+	for index := range offsets {
+		offsets[index] = index * 2
+	}
+	font := model.Font{
+		Bitmap: model.RawBitmap{
+			Width:  glyphCount * 2,
+			Height: 2,
+			Pixels: base64.StdEncoding.EncodeToString(make([]byte, glyphCount*4))},
+		FirstCharacter: 32,
+		GlyphXOffsets:  offsets}
+	texturizer := func(*graphics.Bitmap) *graphics.BitmapTexture { return nil }
+
+	return NewLabelBuilder(graphics.NewBitmapTextPainter(font), texturizer, nil, nil)
+}
+
+func (suite *LabelSuite) SetUpTest(c *check.C) {
+	rootBuilder := ui.NewAreaBuilder()
+	rootBuilder.SetRight(ui.NewAbsoluteAnchor(100.0))
+	rootBuilder.SetBottom(ui.NewAbsoluteAnchor(100.0))
+	suite.root = rootBuilder.Build()
+
+	suite.builder = aTestingLabelBuilder()
+	suite.builder.SetParent(suite.root)
+	suite.builder.SetRight(ui.NewAbsoluteAnchor(50.0))
+	suite.builder.SetBottom(ui.NewAbsoluteAnchor(50.0))
+	suite.requested = nil
+}
+
+func (suite *LabelSuite) aLabel(text string) *Label {
+	label := suite.builder.Build()
+	label.SetText(text)
+	label.AllowTextChange(func(newText string) { suite.requested = append(suite.requested, newText) })
+	return label
+}
+
+func (suite *LabelSuite) click(x, y float32, modifier keys.Modifier) {
+	suite.root.DispatchPositionalEvent(events.NewMouseButtonEvent(events.MouseButtonDownEventType,
+		x, y, uint32(modifier), env.MousePrimary, env.MousePrimary))
+}
+
+func (suite *LabelSuite) key(key keys.Key, modifier keys.Modifier) {
+	suite.root.HandleEvent(events.NewKeyEvent(int(key), uint32(modifier)))
+}
+
+func (suite *LabelSuite) typeText(text string) {
+	for _, char := range text {
+		suite.root.HandleEvent(events.NewCharEvent(char))
+	}
+}
+
+func (suite *LabelSuite) TestClickStartsEditingOnlyIfTextChangeIsAllowed(c *check.C) {
+	label := suite.builder.Build()
+
+	suite.click(10, 10, keys.ModNone)
+
+	c.Check(label.IsEditing(), check.Equals, false)
+}
+
+func (suite *LabelSuite) TestTypingAndEnterRequestsChange(c *check.C) {
+	label := suite.aLabel("abc")
+
+	suite.click(10, 10, keys.ModNone)
+	suite.key(keys.KeyEnd, keys.ModControl)
+	suite.typeText("de")
+	suite.key(keys.KeyEnter, keys.ModNone)
+
+	c.Check(suite.requested, check.DeepEquals, []string{"abcde"})
+	c.Check(label.IsEditing(), check.Equals, false)
+	c.Check(label.area.HasFocus(), check.Equals, false)
+}
+
+func (suite *LabelSuite) TestEditReplacesWholeText(c *check.C) {
+	label := suite.aLabel("abc")
+
+	label.Edit()
+	suite.typeText("de")
+	suite.key(keys.KeyEnter, keys.ModNone)
+
+	c.Check(suite.requested, check.DeepEquals, []string{"de"})
+}
+
+func (suite *LabelSuite) TestEditIsIgnoredIfTextChangeIsNotAllowed(c *check.C) {
+	label := suite.builder.Build()
+
+	label.Edit()
+
+	c.Check(label.IsEditing(), check.Equals, false)
+}
+
+func (suite *LabelSuite) TestCharactersAreEditedAtCaret(c *check.C) {
+	suite.aLabel("abc")
+
+	suite.click(10, 10, keys.ModNone)
+	suite.key(keys.KeyHome, keys.ModNone)
+	suite.key(keys.KeyRight, keys.ModNone)
+	suite.key(keys.KeyRight, keys.ModShift)
+	suite.typeText("X")
+	suite.key(keys.KeyBackspace, keys.ModNone)
+	suite.key(keys.KeyDelete, keys.ModNone)
+	suite.key(keys.KeyEnter, keys.ModNone)
+
+	c.Check(suite.requested, check.DeepEquals, []string{"a"})
+}
+
+func (suite *LabelSuite) TestEscapeCancelsEditing(c *check.C) {
+	label := suite.aLabel("abc")
+
+	suite.click(10, 10, keys.ModNone)
+	suite.typeText("de")
+	suite.key(keys.KeyEscape, keys.ModNone)
+
+	c.Check(suite.requested, check.IsNil)
+	c.Check(label.IsEditing(), check.Equals, false)
+}
+
+func (suite *LabelSuite) TestUnchangedTextIsNotRequested(c *check.C) {
+	suite.aLabel("abc")
+
+	suite.click(10, 10, keys.ModNone)
+	suite.key(keys.KeyEnter, keys.ModNone)
+
+	c.Check(suite.requested, check.IsNil)
+}
+
+func (suite *LabelSuite) TestClickOutsideCommitsEditing(c *check.C) {
+	label := suite.aLabel("abc")
+
+	suite.click(10, 10, keys.ModNone)
+	suite.typeText("d")
+	suite.click(80, 80, keys.ModNone)
+
+	c.Check(suite.requested, check.DeepEquals, []string{"abcd"})
+	c.Check(label.IsEditing(), check.Equals, false)
+}
+
+func (suite *LabelSuite) TestFocusLossCommitsEditing(c *check.C) {
+	suite.aLabel("abc")
+	other := ui.NewAreaBuilder().SetParent(suite.root).Build()
+
+	suite.click(10, 10, keys.ModNone)
+	suite.typeText("d")
+	other.RequestFocus()
+
+	c.Check(suite.requested, check.DeepEquals, []string{"abcd"})
+}
+
+func (suite *LabelSuite) TestEnterInMultiLineLabelStartsNewLine(c *check.C) {
+	suite.builder.SetMultiLine()
+	label := suite.aLabel("ab")
+
+	suite.click(10, 10, keys.ModNone)
+	suite.key(keys.KeyEnd, keys.ModControl)
+	suite.key(keys.KeyEnter, keys.ModNone)
+	suite.typeText("cd")
+	suite.key(keys.KeyUp, keys.ModNone)
+	suite.typeText("X")
+
+	c.Check(label.editor.String(), check.Equals, "abX\ncd")
+
+	suite.key(keys.KeyEnter, keys.ModControl)
+	c.Check(suite.requested, check.DeepEquals, []string{"abX\ncd"})
+}
+
+func (suite *LabelSuite) TestHomeAndEndWorkOnLine(c *check.C) {
+	suite.builder.SetMultiLine()
+	label := suite.aLabel("ab\ncde\nf")
+
+	suite.click(10, 10, keys.ModNone)
+	label.editor.moveTo(4, false)
+	suite.key(keys.KeyHome, keys.ModNone)
+	c.Check(label.editor.caret, check.Equals, 3)
+	suite.key(keys.KeyEnd, keys.ModShift)
+	c.Check(label.editor.selectedText(), check.Equals, "cde")
+}
+
+func (suite *LabelSuite) TestPasteInsertsAtCaretWhileEditing(c *check.C) {
+	suite.aLabel("abc")
+	clipboard := &testingClipboard{text: "XY"}
+
+	suite.click(10, 10, keys.ModNone)
+	suite.root.DispatchPositionalEvent(events.NewClipboardEvent(events.ClipboardPasteEventType, 80, 80, clipboard))
+	suite.key(keys.KeyEnter, keys.ModNone)
+
+	c.Check(suite.requested, check.DeepEquals, []string{"abcXY"})
+}
+
+type testingClipboard struct {
+	text string
+}
+
+func (clipboard *testingClipboard) SetText(value string) {
+	clipboard.text = value
+}
+
+func (clipboard *testingClipboard) Text() string {
+	return clipboard.text
+}
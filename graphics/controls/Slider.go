@@ -2,6 +2,9 @@ package controls
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/inkyblackness/shocked-client/env"
 	"github.com/inkyblackness/shocked-client/graphics"
@@ -20,6 +23,35 @@ func DefaultSliderValueFormatter(value int64) string {
 	return fmt.Sprintf("%v", value)
 }
 
+// SliderValueParser converts a text entered by the user into a value.
+type SliderValueParser func(text string) (int64, error)
+
+var sliderIntegerExpression = regexp.MustCompile(`^[-+]?(0[xX][0-9a-fA-F]+|[0-9]+)$`)
+
+// DefaultSliderValueParser accepts an integer value, given either at the start of the text,
+// or following a "raw:" marker. Hexadecimal values are accepted with a "0x" prefix.
+func DefaultSliderValueParser(text string) (value int64, err error) {
+	fields := strings.Fields(text)
+	if rawIndex := strings.Index(text, "raw:"); rawIndex >= 0 {
+		fields = strings.Fields(text[rawIndex+len("raw:"):])
+	}
+	if (len(fields) == 0) || !sliderIntegerExpression.MatchString(fields[0]) {
+		return 0, fmt.Errorf("not a number: <%v>", text)
+	}
+	number := strings.TrimPrefix(fields[0], "+")
+	negative := strings.HasPrefix(number, "-")
+	number = strings.TrimPrefix(number, "-")
+	if strings.HasPrefix(number, "0x") || strings.HasPrefix(number, "0X") {
+		value, err = strconv.ParseInt(number[2:], 16, 64)
+	} else {
+		value, err = strconv.ParseInt(number, 10, 64)
+	}
+	if negative {
+		value = -value
+	}
+	return
+}
+
 // Slider is a control for selecting a numerical value with a slider.
 type Slider struct {
 	area         *ui.Area
@@ -29,6 +61,7 @@ type Slider struct {
 
 	sliderChangeHandler SliderChangeHandler
 	formatter           SliderValueFormatter
+	parser              SliderValueParser
 
 	valueMin       int64
 	valueMax       int64
@@ -36,6 +69,7 @@ type Slider struct {
 
 	valueUndefined bool
 	value          int64
+	editPending    bool
 }
 
 // Dispose releases all resources and removes the area from the tree.
@@ -72,6 +106,15 @@ func (slider *Slider) SetValueFormatter(formatter SliderValueFormatter) {
 	slider.updateValueLabel()
 }
 
+// SetValueParser sets the parser for values entered as text. nil resets to default.
+// The parser should accept the texts created by the current formatter.
+func (slider *Slider) SetValueParser(parser SliderValueParser) {
+	slider.parser = parser
+	if slider.parser == nil {
+		slider.parser = DefaultSliderValueParser
+	}
+}
+
 func (slider *Slider) updateValueLabel() {
 	text := ""
 	if !slider.valueUndefined {
@@ -109,7 +152,12 @@ func (slider *Slider) onMouseButtonDown(area *ui.Area, event events.Event) bool
 	mouseEvent := event.(*events.MouseButtonEvent)
 	if mouseEvent.AffectedButtons() == env.MousePrimary {
 		area.RequestFocus()
-		slider.updateValueOnMouseEvent(mouseEvent)
+		x, y := mouseEvent.Position()
+		if !slider.valueUndefined && slider.valueLabel.textContains(x, y) {
+			slider.editPending = true
+		} else {
+			slider.updateValueOnMouseEvent(mouseEvent)
+		}
 	}
 	return true
 }
@@ -118,8 +166,14 @@ func (slider *Slider) onMouseButtonUp(area *ui.Area, event events.Event) bool {
 	mouseEvent := event.(*events.MouseButtonEvent)
 	if slider.area.HasFocus() && (mouseEvent.AffectedButtons() == env.MousePrimary) {
 		area.ReleaseFocus()
-		slider.updateValueOnMouseEvent(mouseEvent)
-		slider.onValueChange(slider.value)
+		if slider.editPending {
+			slider.editPending = false
+			slider.valueLabel.startEditing()
+			slider.valueLabel.editor.selectAll()
+		} else {
+			slider.updateValueOnMouseEvent(mouseEvent)
+			slider.onValueChange(slider.value)
+		}
 	}
 	return true
 }
@@ -127,6 +181,7 @@ func (slider *Slider) onMouseButtonUp(area *ui.Area, event events.Event) bool {
 func (slider *Slider) onMouseMove(area *ui.Area, event events.Event) bool {
 	mouseEvent := event.(*events.MouseMoveEvent)
 	if area.HasFocus() && (mouseEvent.Buttons() == env.MousePrimary) {
+		slider.editPending = false
 		slider.updateValueOnMouseEvent(mouseEvent)
 	}
 	return true
@@ -173,3 +228,12 @@ func (slider *Slider) onValueChange(newValue int64) {
 	slider.SetValue(newValue)
 	slider.sliderChangeHandler(newValue)
 }
+
+func (slider *Slider) onValueTextEntered(text string) {
+	value, err := slider.parser(text)
+	if (err == nil) && (value >= slider.valueMin) && (value <= slider.valueMax) {
+		slider.onValueChange(value)
+	} else {
+		slider.updateValueLabel()
+	}
+}
@@ -36,6 +36,7 @@ func (builder *SliderBuilder) Build() *Slider {
 		rectRenderer:        builder.rectRenderer,
 		sliderChangeHandler: builder.sliderChangeHandler,
 		formatter:           DefaultSliderValueFormatter,
+		parser:              DefaultSliderValueParser,
 		valueMin:            builder.valueMin,
 		valueMax:            builder.valueMax,
 		invertedScroll:      builder.invertedScroll,
@@ -58,6 +59,7 @@ func (builder *SliderBuilder) Build() *Slider {
 	builder.labelBuilder.AlignedHorizontallyBy(LeftAligner)
 
 	slider.valueLabel = builder.labelBuilder.Build()
+	slider.valueLabel.textChangeRequestHandler = slider.onValueTextEntered
 
 	return slider
 }
@@ -0,0 +1,110 @@
+package controls
+
+import (
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/env"
+	"github.com/inkyblackness/shocked-client/env/keys"
+	"github.com/inkyblackness/shocked-client/ui"
+	"github.com/inkyblackness/shocked-client/ui/events"
+)
+
+type SliderSuite struct {
+	root    *ui.Area
+	slider  *Slider
+	changes []int64
+}
+
+var _ = check.Suite(&SliderSuite{})
+
+func (suite *SliderSuite) SetUpTest(c *check.C) {
+	rootBuilder := ui.NewAreaBuilder()
+	rootBuilder.SetRight(ui.NewAbsoluteAnchor(100.0))
+	rootBuilder.SetBottom(ui.NewAbsoluteAnchor(100.0))
+	suite.root = rootBuilder.Build()
+	suite.changes = nil
+
+	builder := NewSliderBuilder(aTestingLabelBuilder(), nil)
+	builder.SetParent(suite.root)
+	builder.SetRight(ui.NewAbsoluteAnchor(100.0))
+	builder.SetBottom(ui.NewAbsoluteAnchor(20.0))
+	builder.WithSliderChangeHandler(func(value int64) { suite.changes = append(suite.changes, value) })
+	suite.slider = builder.Build()
+	suite.slider.SetRange(0, 100)
+	suite.slider.SetValue(50)
+	suite.slider.valueLabel.textLeft = 4
+}
+
+func (suite *SliderSuite) mouseButton(eventType events.EventType, x, y float32) {
+	suite.root.DispatchPositionalEvent(events.NewMouseButtonEvent(eventType,
+		x, y, 0, env.MousePrimary, env.MousePrimary))
+}
+
+func (suite *SliderSuite) enter(text string) {
+	suite.mouseButton(events.MouseButtonDownEventType, 5, 2)
+	suite.mouseButton(events.MouseButtonUpEventType, 5, 2)
+	for _, char := range text {
+		suite.root.HandleEvent(events.NewCharEvent(char))
+	}
+	suite.root.HandleEvent(events.NewKeyEvent(int(keys.KeyEnter), 0))
+}
+
+func (suite *SliderSuite) TestDefaultParserAcceptsIntegers(c *check.C) {
+	for text, expected := range map[string]int64{"12": 12, " -3 ": -3, "0x1F": 31, "010": 10, "500 msec": 500} {
+		value, err := DefaultSliderValueParser(text)
+		c.Check(err, check.IsNil, check.Commentf("text <%v>", text))
+		c.Check(value, check.Equals, expected, check.Commentf("text <%v>", text))
+	}
+}
+
+func (suite *SliderSuite) TestDefaultParserPrefersRawMarker(c *check.C) {
+	value, err := DefaultSliderValueParser("1.000 tile(s)  - raw: 32")
+
+	c.Check(err, check.IsNil)
+	c.Check(value, check.Equals, int64(32))
+}
+
+func (suite *SliderSuite) TestDefaultParserRejectsOtherTexts(c *check.C) {
+	for _, text := range []string{"", "abc", "1.5", "raw:"} {
+		_, err := DefaultSliderValueParser(text)
+		c.Check(err, check.NotNil, check.Commentf("text <%v>", text))
+	}
+}
+
+func (suite *SliderSuite) TestClickingValueTextStartsEditing(c *check.C) {
+	suite.mouseButton(events.MouseButtonDownEventType, 5, 2)
+	suite.mouseButton(events.MouseButtonUpEventType, 5, 2)
+
+	c.Check(suite.slider.valueLabel.IsEditing(), check.Equals, true)
+	c.Check(suite.changes, check.IsNil)
+}
+
+func (suite *SliderSuite) TestClickingOutsideValueTextChangesValue(c *check.C) {
+	suite.mouseButton(events.MouseButtonDownEventType, 75, 2)
+	suite.mouseButton(events.MouseButtonUpEventType, 75, 2)
+
+	c.Check(suite.slider.valueLabel.IsEditing(), check.Equals, false)
+	c.Check(suite.changes, check.DeepEquals, []int64{75})
+}
+
+func (suite *SliderSuite) TestEnteredValueIsReportedToChangeHandler(c *check.C) {
+	suite.enter("42")
+
+	c.Check(suite.changes, check.DeepEquals, []int64{42})
+	c.Check(suite.slider.value, check.Equals, int64(42))
+}
+
+func (suite *SliderSuite) TestEnteredValueOutOfRangeIsIgnored(c *check.C) {
+	suite.enter("101")
+
+	c.Check(suite.changes, check.IsNil)
+	c.Check(suite.slider.value, check.Equals, int64(50))
+}
+
+func (suite *SliderSuite) TestEnteredValueIsParsedWithParser(c *check.C) {
+	suite.slider.SetValueParser(func(text string) (int64, error) { return 7, nil })
+
+	suite.enter("seven")
+
+	c.Check(suite.changes, check.DeepEquals, []int64{7})
+}
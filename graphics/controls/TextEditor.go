@@ -0,0 +1,112 @@
+package controls
+
+// textEditor keeps the state of a text while it is being edited.
+// Positions are indices between the characters of the text, ranging from
+// zero (before the first character) to the length of the text.
+type textEditor struct {
+	text   []rune
+	caret  int
+	anchor int
+}
+
+func newTextEditor(text string) *textEditor {
+	editor := &textEditor{text: []rune(text)}
+	editor.caret = len(editor.text)
+	editor.anchor = editor.caret
+
+	return editor
+}
+
+func (editor *textEditor) String() string {
+	return string(editor.text)
+}
+
+func (editor *textEditor) length() int {
+	return len(editor.text)
+}
+
+func (editor *textEditor) hasSelection() bool {
+	return editor.caret != editor.anchor
+}
+
+func (editor *textEditor) selection() (from, to int) {
+	from, to = editor.anchor, editor.caret
+	if from > to {
+		from, to = to, from
+	}
+	return
+}
+
+func (editor *textEditor) selectedText() string {
+	from, to := editor.selection()
+	return string(editor.text[from:to])
+}
+
+func (editor *textEditor) selectAll() {
+	editor.anchor = 0
+	editor.caret = len(editor.text)
+}
+
+// moveTo places the caret at given position. If extend is set, the
+// selection is extended up to the new position, otherwise it is cleared.
+func (editor *textEditor) moveTo(position int, extend bool) {
+	if position < 0 {
+		position = 0
+	} else if position > len(editor.text) {
+		position = len(editor.text)
+	}
+	editor.caret = position
+	if !extend {
+		editor.anchor = position
+	}
+}
+
+// moveLeft moves the caret one character to the left. Without extension,
+// an existing selection is collapsed to its start.
+func (editor *textEditor) moveLeft(extend bool) {
+	if !extend && editor.hasSelection() {
+		from, _ := editor.selection()
+		editor.moveTo(from, false)
+	} else {
+		editor.moveTo(editor.caret-1, extend)
+	}
+}
+
+// moveRight moves the caret one character to the right. Without extension,
+// an existing selection is collapsed to its end.
+func (editor *textEditor) moveRight(extend bool) {
+	if !extend && editor.hasSelection() {
+		_, to := editor.selection()
+		editor.moveTo(to, false)
+	} else {
+		editor.moveTo(editor.caret+1, extend)
+	}
+}
+
+// insert replaces the current selection with given text.
+func (editor *textEditor) insert(text string) {
+	from, to := editor.selection()
+	inserted := []rune(text)
+	newText := make([]rune, 0, len(editor.text)-(to-from)+len(inserted))
+	newText = append(newText, editor.text[:from]...)
+	newText = append(newText, inserted...)
+	newText = append(newText, editor.text[to:]...)
+	editor.text = newText
+	editor.moveTo(from+len(inserted), false)
+}
+
+// deleteBackward removes the current selection, or the character before the caret.
+func (editor *textEditor) deleteBackward() {
+	if !editor.hasSelection() {
+		editor.moveTo(editor.caret-1, true)
+	}
+	editor.insert("")
+}
+
+// deleteForward removes the current selection, or the character after the caret.
+func (editor *textEditor) deleteForward() {
+	if !editor.hasSelection() {
+		editor.moveTo(editor.caret+1, true)
+	}
+	editor.insert("")
+}
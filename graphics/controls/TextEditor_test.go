@@ -0,0 +1,102 @@
+package controls
+
+import (
+	check "gopkg.in/check.v1"
+)
+
+type TextEditorSuite struct {
+	editor *textEditor
+}
+
+var _ = check.Suite(&TextEditorSuite{})
+
+func (suite *TextEditorSuite) SetUpTest(c *check.C) {
+	suite.editor = newTextEditor("abcdef")
+}
+
+func (suite *TextEditorSuite) TestCaretStartsAtEnd(c *check.C) {
+	c.Check(suite.editor.caret, check.Equals, 6)
+	c.Check(suite.editor.hasSelection(), check.Equals, false)
+}
+
+func (suite *TextEditorSuite) TestInsertAddsTextAtCaret(c *check.C) {
+	suite.editor.moveTo(2, false)
+	suite.editor.insert("XY")
+
+	c.Check(suite.editor.String(), check.Equals, "abXYcdef")
+	c.Check(suite.editor.caret, check.Equals, 4)
+}
+
+func (suite *TextEditorSuite) TestInsertReplacesSelection(c *check.C) {
+	suite.editor.moveTo(1, false)
+	suite.editor.moveTo(4, true)
+	suite.editor.insert("Z")
+
+	c.Check(suite.editor.String(), check.Equals, "aZef")
+	c.Check(suite.editor.hasSelection(), check.Equals, false)
+}
+
+func (suite *TextEditorSuite) TestSelectionIsOrdered(c *check.C) {
+	suite.editor.moveTo(4, false)
+	suite.editor.moveTo(1, true)
+
+	from, to := suite.editor.selection()
+	c.Check(from, check.Equals, 1)
+	c.Check(to, check.Equals, 4)
+	c.Check(suite.editor.selectedText(), check.Equals, "bcd")
+}
+
+func (suite *TextEditorSuite) TestMoveToIsLimited(c *check.C) {
+	suite.editor.moveTo(-3, false)
+	c.Check(suite.editor.caret, check.Equals, 0)
+	suite.editor.moveTo(30, false)
+	c.Check(suite.editor.caret, check.Equals, 6)
+}
+
+func (suite *TextEditorSuite) TestMoveLeftCollapsesSelectionToStart(c *check.C) {
+	suite.editor.moveTo(2, false)
+	suite.editor.moveTo(5, true)
+	suite.editor.moveLeft(false)
+
+	c.Check(suite.editor.caret, check.Equals, 2)
+	c.Check(suite.editor.hasSelection(), check.Equals, false)
+}
+
+func (suite *TextEditorSuite) TestMoveRightExtendsSelection(c *check.C) {
+	suite.editor.moveTo(2, false)
+	suite.editor.moveRight(true)
+	suite.editor.moveRight(true)
+
+	c.Check(suite.editor.selectedText(), check.Equals, "cd")
+}
+
+func (suite *TextEditorSuite) TestDeleteBackwardRemovesCharBeforeCaret(c *check.C) {
+	suite.editor.moveTo(3, false)
+	suite.editor.deleteBackward()
+
+	c.Check(suite.editor.String(), check.Equals, "abdef")
+	c.Check(suite.editor.caret, check.Equals, 2)
+}
+
+func (suite *TextEditorSuite) TestDeleteForwardRemovesCharAfterCaret(c *check.C) {
+	suite.editor.moveTo(3, false)
+	suite.editor.deleteForward()
+
+	c.Check(suite.editor.String(), check.Equals, "abcef")
+	c.Check(suite.editor.caret, check.Equals, 3)
+}
+
+func (suite *TextEditorSuite) TestDeleteAtLimitsKeepsText(c *check.C) {
+	suite.editor.deleteForward()
+	suite.editor.moveTo(0, false)
+	suite.editor.deleteBackward()
+
+	c.Check(suite.editor.String(), check.Equals, "abcdef")
+}
+
+func (suite *TextEditorSuite) TestDeleteRemovesSelection(c *check.C) {
+	suite.editor.selectAll()
+	suite.editor.deleteForward()
+
+	c.Check(suite.editor.String(), check.Equals, "")
+}
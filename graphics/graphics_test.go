@@ -1,9 +1,12 @@
 package graphics
 
 import (
+	"flag"
 	"testing"
 
 	check "gopkg.in/check.v1"
 )
 
+var updateGolden = flag.Bool("update", false, "update the golden images in testdata")
+
 func Test(t *testing.T) { check.TestingT(t) }
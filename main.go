@@ -8,10 +8,15 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/docopt/docopt-go"
 
 	"github.com/inkyblackness/shocked-client/editor"
+	"github.com/inkyblackness/shocked-client/editor/actions"
+	"github.com/inkyblackness/shocked-client/editor/cmd"
+	"github.com/inkyblackness/shocked-client/editor/model"
+	"github.com/inkyblackness/shocked-client/editor/model/multistore"
 	"github.com/inkyblackness/shocked-client/env/native"
 	"github.com/inkyblackness/shocked-core"
 	"github.com/inkyblackness/shocked-core/release"
@@ -21,7 +26,7 @@ func usage() string {
 	return Title + `
 
 Usage:
-   shocked-client --path=<datadir>... [--autosave=<sec>] [--scale=<scale>] [--invertedSliderScroll]
+   shocked-client --path=<datadir>... [--project=<datadir>...] [--readonly=<datadir>...] [--autosave=<sec>] [--scale=<scale>] [--invertedSliderScroll] [--keymap=<file>] [--undoJournal=<MB>]
    shocked-client -h | --help
    shocked-client --version
 
@@ -29,9 +34,13 @@ Options:
    -h --help               Show this screen.
    --version               Show version.
    --path=<datadir>        A path to data directory for inplace modifications. Repeat option for multiple directories.
+   --project=<datadir>     A path to a data directory opened as further project. Repeat option for multiple projects.
+   --readonly=<datadir>    A path to a data directory opened as further project that can not be modified, such as the original data. Repeat option for multiple projects.
    --autosave=<sec>        A duration, in seconds (1..1800), after which changed files are automatically saved. Default: 5.
    --scale=<scale>         A factor for scaling the UI (0.5 .. 1.0). 1080p displays should use default. 4K most likely 2.0. Default: 1.0.
    --invertedSliderScroll  Specify to have sliders go "down" if scrolling "up" (= old behaviour)
+   --keymap=<file>         A JSON file with keyboard shortcuts. Default: keymap.json in the user configuration directory.
+   --undoJournal=<MB>      Maximum size, in megabytes (0..1024), of the undo history of each writable project, kept next to its data directory. 0 disables it. Default: 32.
 `
 }
 
@@ -40,6 +49,7 @@ func main() {
 	autoSaveTimeoutMSec := 5000
 	scale := 1.0
 	invertedSliderScroll := false
+	undoJournalSizeMB := 32
 
 	autoSaveValue, err := opts.Int("--autosave")
 	if err == nil {
@@ -61,7 +71,25 @@ func main() {
 	if err == nil {
 		invertedSliderScroll = invertedSliderScrollArg
 	}
+	undoJournalValue, err := opts.Int("--undoJournal")
+	if err == nil {
+		if (undoJournalValue >= 0) && (undoJournalValue <= 1024) {
+			undoJournalSizeMB = undoJournalValue
+		} else {
+			fmt.Fprintf(os.Stderr, "--undoJournal is supported only between 0 and 1024 -- value ignored: <%v>\n", undoJournalValue)
+		}
+	}
+	keymap := loadKeymap(opts)
 	pathArg := opts["--path"]
+	journals := make(map[string]*cmd.JournalFile)
+	addJournal := func(projectID string, path string) {
+		if undoJournalSizeMB > 0 {
+			journals[projectID] = cmd.NewJournalFile(filepath.Clean(path)+".undo.json", undoJournalSizeMB*1024*1024)
+		}
+	}
+	if paths := pathArg.([]string); len(paths) > 0 {
+		addJournal(model.InplaceProjectID, paths[0])
+	}
 
 	source, srcErr := release.FromAbsolutePaths(pathArg.([]string))
 	if srcErr != nil {
@@ -72,8 +100,71 @@ func main() {
 	deferrer := make(chan func(), 100)
 	defer close(deferrer)
 
-	store := core.NewInplaceDataStore(source, deferrer, autoSaveTimeoutMSec)
-	app := editor.NewMainApplication(store, float32(scale), invertedSliderScroll)
+	store := multistore.NewDataStore()
+	store.Add(model.InplaceProjectID, core.NewInplaceDataStore(source, deferrer, autoSaveTimeoutMSec), model.InplaceProjectID, false)
+	projectPaths, _ := opts["--project"].([]string)
+	addProjects(store, projectPaths, false, deferrer, autoSaveTimeoutMSec, addJournal)
+	readOnlyPaths, _ := opts["--readonly"].([]string)
+	addProjects(store, readOnlyPaths, true, deferrer, autoSaveTimeoutMSec, nil)
+	app := editor.NewMainApplication(store, float32(scale), invertedSliderScroll, keymap, journals)
 
 	native.Run(app, deferrer)
+	app.Close()
+}
+
+// addProjects adds a project for each of the given data directories. The projects are named after
+// the directories. The optional onAdded callback is called for each added project.
+func addProjects(store *multistore.DataStore, paths []string, readOnly bool, deferrer chan func(), autoSaveTimeoutMSec int,
+	onAdded func(projectID string, path string)) {
+	for _, path := range paths {
+		source, srcErr := release.FromAbsolutePaths([]string{path})
+		if srcErr != nil {
+			log.Fatalf("Source <%v> is not available: %v", path, srcErr)
+		}
+		baseName := filepath.Base(filepath.Clean(path))
+		projectID := baseName
+		for suffix := 2; containsString(store.ProjectIDs(), projectID); suffix++ {
+			projectID = fmt.Sprintf("%v (%d)", baseName, suffix)
+		}
+		store.Add(projectID, core.NewInplaceDataStore(source, deferrer, autoSaveTimeoutMSec), model.InplaceProjectID, readOnly)
+		if onAdded != nil {
+			onAdded(projectID, path)
+		}
+	}
+}
+
+func containsString(list []string, value string) bool {
+	for _, entry := range list {
+		if entry == value {
+			return true
+		}
+	}
+	return false
+}
+
+func loadKeymap(opts docopt.Opts) *actions.Keymap {
+	keymap := actions.DefaultKeymap()
+	fileName, err := opts.String("--keymap")
+	required := err == nil
+	if !required {
+		configDir, dirErr := os.UserConfigDir()
+		if dirErr != nil {
+			return keymap
+		}
+		fileName = filepath.Join(configDir, "shocked-client", "keymap.json")
+	}
+	file, err := os.Open(fileName)
+	if err != nil {
+		if required || !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "Keymap could not be opened, using defaults: %v\n", err)
+		}
+		return keymap
+	}
+	defer file.Close()
+	userKeymap, err := actions.LoadKeymap(file, keymap)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Keymap <%v> is not valid, using defaults: %v\n", fileName, err)
+		return keymap
+	}
+	return userKeymap
 }
@@ -52,6 +52,13 @@ func (debugging *debuggingOpenGl) BindBuffer(target uint32, buffer uint32) {
 	debugging.recordExit("BindBuffer")
 }
 
+// BindFramebuffer implements the OpenGl interface.
+func (debugging *debuggingOpenGl) BindFramebuffer(target uint32, framebuffer uint32) {
+	debugging.recordEntry("BindFramebuffer", target, framebuffer)
+	debugging.gl.BindFramebuffer(target, framebuffer)
+	debugging.recordExit("BindFramebuffer")
+}
+
 // BindTexture implements the opengl.OpenGl interface.
 func (debugging *debuggingOpenGl) BindTexture(target uint32, texture uint32) {
 	debugging.recordEntry("BindTexture", target, texture)
@@ -80,6 +87,14 @@ func (debugging *debuggingOpenGl) BufferData(target uint32, size int, data inter
 	debugging.recordExit("BufferData")
 }
 
+// CheckFramebufferStatus implements the OpenGl interface.
+func (debugging *debuggingOpenGl) CheckFramebufferStatus(target uint32) uint32 {
+	debugging.recordEntry("CheckFramebufferStatus", target)
+	result := debugging.gl.CheckFramebufferStatus(target)
+	debugging.recordExit("CheckFramebufferStatus", result)
+	return result
+}
+
 // Clear implements the OpenGl interface.
 func (debugging *debuggingOpenGl) Clear(mask uint32) {
 	debugging.recordEntry("Clear", mask)
@@ -124,6 +139,13 @@ func (debugging *debuggingOpenGl) DeleteBuffers(buffers []uint32) {
 	debugging.recordExit("DeleteBuffers")
 }
 
+// DeleteFramebuffers implements the OpenGl interface.
+func (debugging *debuggingOpenGl) DeleteFramebuffers(framebuffers []uint32) {
+	debugging.recordEntry("DeleteFramebuffers", framebuffers)
+	debugging.gl.DeleteFramebuffers(framebuffers)
+	debugging.recordExit("DeleteFramebuffers")
+}
+
 // DeleteProgram implements the OpenGl interface.
 func (debugging *debuggingOpenGl) DeleteProgram(program uint32) {
 	debugging.recordEntry("DeleteProgram", program)
@@ -180,6 +202,13 @@ func (debugging *debuggingOpenGl) EnableVertexAttribArray(index uint32) {
 	debugging.recordExit("EnableVertexAttribArray")
 }
 
+// FramebufferTexture2D implements the OpenGl interface.
+func (debugging *debuggingOpenGl) FramebufferTexture2D(target uint32, attachment uint32, texTarget uint32, texture uint32, level int32) {
+	debugging.recordEntry("FramebufferTexture2D", target, attachment, texTarget, texture, level)
+	debugging.gl.FramebufferTexture2D(target, attachment, texTarget, texture, level)
+	debugging.recordExit("FramebufferTexture2D")
+}
+
 // GenerateMipmap implements the opengl.OpenGl interface.
 func (debugging *debuggingOpenGl) GenerateMipmap(target uint32) {
 	debugging.recordEntry("GenerateMipmap", target)
@@ -195,6 +224,14 @@ func (debugging *debuggingOpenGl) GenBuffers(n int32) []uint32 {
 	return result
 }
 
+// GenFramebuffers implements the OpenGl interface.
+func (debugging *debuggingOpenGl) GenFramebuffers(n int32) []uint32 {
+	debugging.recordEntry("GenFramebuffers", n)
+	result := debugging.gl.GenFramebuffers(n)
+	debugging.recordExit("GenFramebuffers", result)
+	return result
+}
+
 // GenTextures implements the opengl.OpenGl interface.
 func (debugging *debuggingOpenGl) GenTextures(n int32) []uint32 {
 	debugging.recordEntry("GenTextures", n)
@@ -9,11 +9,13 @@ type OpenGl interface {
 
 	BindAttribLocation(program uint32, index uint32, name string)
 	BindBuffer(target uint32, buffer uint32)
+	BindFramebuffer(target uint32, framebuffer uint32)
 	BindTexture(target uint32, texture uint32)
 	BindVertexArray(array uint32)
 	BlendFunc(sfactor uint32, dfactor uint32)
 	BufferData(target uint32, size int, data interface{}, usage uint32)
 
+	CheckFramebufferStatus(target uint32) uint32
 	Clear(mask uint32)
 	ClearColor(red float32, green float32, blue float32, alpha float32)
 
@@ -23,6 +25,7 @@ type OpenGl interface {
 	CreateShader(shaderType uint32) uint32
 
 	DeleteBuffers(buffers []uint32)
+	DeleteFramebuffers(framebuffers []uint32)
 	DeleteProgram(program uint32)
 	DeleteShader(shader uint32)
 	DeleteTextures(textures []uint32)
@@ -34,8 +37,11 @@ type OpenGl interface {
 	Enable(cap uint32)
 	EnableVertexAttribArray(index uint32)
 
+	FramebufferTexture2D(target uint32, attachment uint32, texTarget uint32, texture uint32, level int32)
+
 	GenerateMipmap(target uint32)
 	GenBuffers(n int32) []uint32
+	GenFramebuffers(n int32) []uint32
 	GenTextures(n int32) []uint32
 	GenVertexArrays(n int32) []uint32
 
@@ -26,8 +26,9 @@ const (
 
 // Status Values
 const (
-	COMPILE_STATUS uint32 = 0x8B81
-	LINK_STATUS           = 0x8B82
+	COMPILE_STATUS  uint32 = 0x8B81
+	LINK_STATUS            = 0x8B82
+	INFO_LOG_LENGTH        = 0x8B84
 )
 
 // Buffer Types
@@ -50,9 +51,16 @@ const (
 
 // Alpha constants
 const (
-	SRC_ALPHA           uint32 = 0x0302
-	ONE_MINUS_SRC_ALPHA        = 0x0303
+	ZERO                uint32 = 0
+	ONE                        = 1
+	SRC_COLOR                  = 0x0300
 	ONE_MINUS_SRC_COLOR        = 0x0301
+	SRC_ALPHA                  = 0x0302
+	ONE_MINUS_SRC_ALPHA        = 0x0303
+	DST_ALPHA                  = 0x0304
+	ONE_MINUS_DST_ALPHA        = 0x0305
+	DST_COLOR                  = 0x0306
+	ONE_MINUS_DST_COLOR        = 0x0307
 )
 
 // Data Types
@@ -73,8 +81,25 @@ const (
 	TEXTURE0 = 0x84C0
 
 	NEAREST            = 0x2600
+	LINEAR             = 0x2601
 	TEXTURE_MAG_FILTER = 0x2800
 	TEXTURE_MIN_FILTER = 0x2801
+	TEXTURE_WRAP_S     = 0x2802
+	TEXTURE_WRAP_T     = 0x2803
+	REPEAT             = 0x2901
+	CLAMP_TO_EDGE      = 0x812F
+	MIRRORED_REPEAT    = 0x8370
+)
+
+// Framebuffer Constants
+const (
+	FRAMEBUFFER uint32 = 0x8D40
+
+	COLOR_ATTACHMENT0 = 0x8CE0
+
+	FRAMEBUFFER_COMPLETE                      = 0x8CD5
+	FRAMEBUFFER_INCOMPLETE_ATTACHMENT         = 0x8CD6
+	FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT = 0x8CD7
 )
 
 // Errors
@@ -0,0 +1,110 @@
+package software
+
+import (
+	"github.com/inkyblackness/shocked-client/opengl"
+)
+
+// framebuffer is a framebuffer object. Only a color attachment is supported,
+// which is a texture that rendering writes into.
+type framebuffer struct {
+	color *texture
+}
+
+// drawTarget returns the pixels that rendering currently writes into, and their size.
+// These are the ones of the color attachment of the bound framebuffer object, or those
+// of the default framebuffer. An incomplete framebuffer object has no pixels.
+func (gl *OpenGl) drawTarget() (pixels []byte, width, height int) {
+	if gl.boundFramebuffer == nil {
+		return gl.framebuffer, gl.width, gl.height
+	}
+	if color := gl.boundFramebuffer.color; color != nil {
+		return color.pixels, color.width, color.height
+	}
+	return nil, 0, 0
+}
+
+// BindFramebuffer implements the opengl.OpenGl interface.
+func (gl *OpenGl) BindFramebuffer(target uint32, framebuffer uint32) {
+	if target != opengl.FRAMEBUFFER {
+		gl.setError(opengl.INVALID_ENUM)
+		return
+	}
+	if framebuffer == 0 {
+		gl.boundFramebuffer = nil
+		return
+	}
+	framebufferObj, existing := gl.framebuffers[framebuffer]
+	if !existing {
+		gl.setError(opengl.INVALID_OPERATION)
+		return
+	}
+	gl.boundFramebuffer = framebufferObj
+}
+
+// CheckFramebufferStatus implements the opengl.OpenGl interface.
+func (gl *OpenGl) CheckFramebufferStatus(target uint32) uint32 {
+	if target != opengl.FRAMEBUFFER {
+		gl.setError(opengl.INVALID_ENUM)
+		return 0
+	}
+	if gl.boundFramebuffer == nil {
+		return opengl.FRAMEBUFFER_COMPLETE
+	}
+	color := gl.boundFramebuffer.color
+	if color == nil {
+		return opengl.FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT
+	}
+	if (color.width == 0) || (color.height == 0) {
+		return opengl.FRAMEBUFFER_INCOMPLETE_ATTACHMENT
+	}
+	return opengl.FRAMEBUFFER_COMPLETE
+}
+
+// DeleteFramebuffers implements the opengl.OpenGl interface.
+func (gl *OpenGl) DeleteFramebuffers(framebuffers []uint32) {
+	for _, name := range framebuffers {
+		if framebufferObj, existing := gl.framebuffers[name]; existing {
+			if gl.boundFramebuffer == framebufferObj {
+				gl.boundFramebuffer = nil
+			}
+			delete(gl.framebuffers, name)
+		}
+	}
+}
+
+// FramebufferTexture2D implements the opengl.OpenGl interface.
+// Only the base level of a texture can be attached, as the color attachment.
+func (gl *OpenGl) FramebufferTexture2D(target uint32, attachment uint32, texTarget uint32, texture uint32, level int32) {
+	if (target != opengl.FRAMEBUFFER) || (attachment != opengl.COLOR_ATTACHMENT0) || (texTarget != opengl.TEXTURE_2D) {
+		gl.setError(opengl.INVALID_ENUM)
+		return
+	}
+	if level != 0 {
+		gl.setError(opengl.INVALID_VALUE)
+		return
+	}
+	if gl.boundFramebuffer == nil {
+		gl.setError(opengl.INVALID_OPERATION)
+		return
+	}
+	if texture == 0 {
+		gl.boundFramebuffer.color = nil
+		return
+	}
+	textureObj, existing := gl.textures[texture]
+	if !existing {
+		gl.setError(opengl.INVALID_OPERATION)
+		return
+	}
+	gl.boundFramebuffer.color = textureObj
+}
+
+// GenFramebuffers implements the opengl.OpenGl interface.
+func (gl *OpenGl) GenFramebuffers(n int32) []uint32 {
+	names := make([]uint32, n)
+	for index := range names {
+		names[index] = gl.newName()
+		gl.framebuffers[names[index]] = &framebuffer{}
+	}
+	return names
+}
@@ -0,0 +1,66 @@
+package software
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+// CheckGoldenImage compares the given image against the PNG file at the given path.
+// If update is set, the file is (re-)written from the image instead. A missing file
+// is an error; create it by running the test with update set.
+// The returned error describes the first difference.
+func CheckGoldenImage(actual image.Image, fileName string, update bool) error {
+	if update {
+		return writePng(actual, fileName)
+	}
+	file, openErr := os.Open(fileName)
+	if os.IsNotExist(openErr) {
+		return fmt.Errorf("%s: golden image missing, run with update to create it", fileName)
+	}
+	if openErr != nil {
+		return openErr
+	}
+	defer file.Close()
+	expected, decodeErr := png.Decode(file)
+	if decodeErr != nil {
+		return fmt.Errorf("%s: %v", fileName, decodeErr)
+	}
+	return compareImages(expected, actual, fileName)
+}
+
+func writePng(img image.Image, fileName string) error {
+	if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+		return err
+	}
+	file, createErr := os.Create(fileName)
+	if createErr != nil {
+		return createErr
+	}
+	encodeErr := png.Encode(file, img)
+	closeErr := file.Close()
+	if encodeErr != nil {
+		return encodeErr
+	}
+	return closeErr
+}
+
+func compareImages(expected, actual image.Image, name string) error {
+	expectedBounds, actualBounds := expected.Bounds(), actual.Bounds()
+	if expectedBounds.Size() != actualBounds.Size() {
+		return fmt.Errorf("%s: size mismatch: expected %v, got %v", name, expectedBounds.Size(), actualBounds.Size())
+	}
+	for y := 0; y < expectedBounds.Dy(); y++ {
+		for x := 0; x < expectedBounds.Dx(); x++ {
+			er, eg, eb, ea := expected.At(expectedBounds.Min.X+x, expectedBounds.Min.Y+y).RGBA()
+			ar, ag, ab, aa := actual.At(actualBounds.Min.X+x, actualBounds.Min.Y+y).RGBA()
+			if (er != ar) || (eg != ag) || (eb != ab) || (ea != aa) {
+				return fmt.Errorf("%s: pixel mismatch at %d/%d: expected %04X%04X%04X%04X, got %04X%04X%04X%04X",
+					name, x, y, er, eg, eb, ea, ar, ag, ab, aa)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,709 @@
+package software
+
+import (
+	"image"
+
+	"github.com/inkyblackness/shocked-client/opengl"
+)
+
+const maxTextureUnits = 16
+
+// OpenGl is a software implementation of the opengl.OpenGl interface.
+// It renders into an in-memory RGBA framebuffer and requires neither a window
+// nor graphics hardware, which makes it usable for automated tests.
+//
+// Shaders are interpreted from their GLSL source. The supported subset covers
+// float based types (float, vec2-4, mat4), bool, sampler2D, user functions and the
+// common built-in functions. Textures are sampled with nearest filtering only.
+// There is no depth buffer and primitives are not clipped against the near plane.
+// Framebuffer objects render into the texture that is attached as their color attachment.
+type OpenGl struct {
+	width       int
+	height      int
+	framebuffer []byte
+
+	lastError uint32
+	lastName  uint32
+
+	buffers      map[uint32]*buffer
+	textures     map[uint32]*texture
+	vertexArrays map[uint32]*vertexArray
+	shaders      map[uint32]*shader
+	programs     map[uint32]*program
+	framebuffers map[uint32]*framebuffer
+
+	boundBuffer       *buffer
+	boundFramebuffer  *framebuffer
+	defaultArray      *vertexArray
+	boundArray        *vertexArray
+	activeTextureUnit int
+	textureUnits      [maxTextureUnits]*texture
+	currentProgram    *program
+
+	clearColor        [4]float32
+	viewport          [4]int
+	blendEnabled      bool
+	sourceFactor      uint32
+	destinationFactor uint32
+}
+
+// NewOpenGl returns a new instance with a framebuffer of given size.
+func NewOpenGl(width, height int) *OpenGl {
+	gl := &OpenGl{
+		buffers:           make(map[uint32]*buffer),
+		textures:          make(map[uint32]*texture),
+		vertexArrays:      make(map[uint32]*vertexArray),
+		shaders:           make(map[uint32]*shader),
+		programs:          make(map[uint32]*program),
+		framebuffers:      make(map[uint32]*framebuffer),
+		defaultArray:      &vertexArray{},
+		sourceFactor:      opengl.ONE,
+		destinationFactor: opengl.ZERO}
+	gl.boundArray = gl.defaultArray
+	gl.Resize(width, height)
+	gl.viewport = [4]int{0, 0, width, height}
+
+	return gl
+}
+
+// Resize changes the size of the framebuffer. The content of the framebuffer is lost.
+// The viewport is not modified.
+func (gl *OpenGl) Resize(width, height int) {
+	if width < 0 {
+		width = 0
+	}
+	if height < 0 {
+		height = 0
+	}
+	gl.width = width
+	gl.height = height
+	gl.framebuffer = make([]byte, width*height*4)
+}
+
+// Size returns the current size of the framebuffer.
+func (gl *OpenGl) Size() (width, height int) {
+	return gl.width, gl.height
+}
+
+// Image returns a copy of the current framebuffer content.
+// In contrast to ReadPixels, the first row of the image is the top row.
+// The color values are not premultiplied by alpha, as they are kept in the framebuffer.
+func (gl *OpenGl) Image() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, gl.width, gl.height))
+	rowSize := gl.width * 4
+	for y := 0; y < gl.height; y++ {
+		source := (gl.height - 1 - y) * rowSize
+		copy(img.Pix[y*img.Stride:y*img.Stride+rowSize], gl.framebuffer[source:source+rowSize])
+	}
+	return img
+}
+
+func (gl *OpenGl) setError(code uint32) {
+	if gl.lastError == opengl.NO_ERROR {
+		gl.lastError = code
+	}
+}
+
+func (gl *OpenGl) newName() uint32 {
+	gl.lastName++
+	return gl.lastName
+}
+
+// ActiveTexture implements the opengl.OpenGl interface.
+func (gl *OpenGl) ActiveTexture(texture uint32) {
+	unit := int(texture) - opengl.TEXTURE0
+	if (unit < 0) || (unit >= maxTextureUnits) {
+		gl.setError(opengl.INVALID_ENUM)
+		return
+	}
+	gl.activeTextureUnit = unit
+}
+
+// AttachShader implements the opengl.OpenGl interface.
+func (gl *OpenGl) AttachShader(program uint32, shader uint32) {
+	prog, shaderObj := gl.programs[program], gl.shaders[shader]
+	if (prog == nil) || (shaderObj == nil) {
+		gl.setError(opengl.INVALID_VALUE)
+	} else if !prog.attach(shaderObj) {
+		gl.setError(opengl.INVALID_OPERATION)
+	}
+}
+
+// BindAttribLocation implements the opengl.OpenGl interface.
+func (gl *OpenGl) BindAttribLocation(program uint32, index uint32, name string) {
+	prog := gl.programs[program]
+	if (prog == nil) || (index >= maxVertexAttribs) {
+		gl.setError(opengl.INVALID_VALUE)
+		return
+	}
+	prog.boundLocations[name] = index
+}
+
+// BindBuffer implements the opengl.OpenGl interface.
+func (gl *OpenGl) BindBuffer(target uint32, buffer uint32) {
+	if target != opengl.ARRAY_BUFFER {
+		gl.setError(opengl.INVALID_ENUM)
+		return
+	}
+	if buffer == 0 {
+		gl.boundBuffer = nil
+		return
+	}
+	bufferObj, existing := gl.buffers[buffer]
+	if !existing {
+		gl.setError(opengl.INVALID_OPERATION)
+		return
+	}
+	gl.boundBuffer = bufferObj
+}
+
+// BindTexture implements the opengl.OpenGl interface.
+func (gl *OpenGl) BindTexture(target uint32, texture uint32) {
+	if target != opengl.TEXTURE_2D {
+		gl.setError(opengl.INVALID_ENUM)
+		return
+	}
+	if texture == 0 {
+		gl.textureUnits[gl.activeTextureUnit] = nil
+		return
+	}
+	textureObj, existing := gl.textures[texture]
+	if !existing {
+		gl.setError(opengl.INVALID_OPERATION)
+		return
+	}
+	gl.textureUnits[gl.activeTextureUnit] = textureObj
+}
+
+// BindVertexArray implements the opengl.OpenGl interface.
+func (gl *OpenGl) BindVertexArray(array uint32) {
+	if array == 0 {
+		gl.boundArray = gl.defaultArray
+		return
+	}
+	arrayObj, existing := gl.vertexArrays[array]
+	if !existing {
+		gl.setError(opengl.INVALID_OPERATION)
+		return
+	}
+	gl.boundArray = arrayObj
+}
+
+// BlendFunc implements the opengl.OpenGl interface.
+func (gl *OpenGl) BlendFunc(sfactor uint32, dfactor uint32) {
+	if !isBlendFactor(sfactor) || !isBlendFactor(dfactor) {
+		gl.setError(opengl.INVALID_ENUM)
+		return
+	}
+	gl.sourceFactor = sfactor
+	gl.destinationFactor = dfactor
+}
+
+// BufferData implements the opengl.OpenGl interface.
+func (gl *OpenGl) BufferData(target uint32, size int, data interface{}, usage uint32) {
+	if target != opengl.ARRAY_BUFFER {
+		gl.setError(opengl.INVALID_ENUM)
+		return
+	}
+	if gl.boundBuffer == nil {
+		gl.setError(opengl.INVALID_OPERATION)
+		return
+	}
+	raw, supported := bufferBytes(data)
+	if !supported || (size < 0) {
+		gl.setError(opengl.INVALID_VALUE)
+		return
+	}
+	storage := make([]byte, size)
+	copy(storage, raw)
+	gl.boundBuffer.data = storage
+}
+
+// Clear implements the opengl.OpenGl interface.
+func (gl *OpenGl) Clear(mask uint32) {
+	if (mask & opengl.COLOR_BUFFER_BIT) == 0 {
+		return
+	}
+	var color [4]byte
+	for index, component := range gl.clearColor {
+		color[index] = toByte(component)
+	}
+	pixels, _, _ := gl.drawTarget()
+	for offset := 0; offset < len(pixels); offset += 4 {
+		copy(pixels[offset:offset+4], color[:])
+	}
+}
+
+// ClearColor implements the opengl.OpenGl interface.
+func (gl *OpenGl) ClearColor(red float32, green float32, blue float32, alpha float32) {
+	gl.clearColor = [4]float32{red, green, blue, alpha}
+}
+
+// CompileShader implements the opengl.OpenGl interface.
+func (gl *OpenGl) CompileShader(shader uint32) {
+	shaderObj := gl.shaders[shader]
+	if shaderObj == nil {
+		gl.setError(opengl.INVALID_VALUE)
+		return
+	}
+	shaderObj.compile()
+}
+
+// CreateProgram implements the opengl.OpenGl interface.
+func (gl *OpenGl) CreateProgram() uint32 {
+	name := gl.newName()
+	gl.programs[name] = newProgram()
+	return name
+}
+
+// CreateShader implements the opengl.OpenGl interface.
+func (gl *OpenGl) CreateShader(shaderType uint32) uint32 {
+	if (shaderType != opengl.VERTEX_SHADER) && (shaderType != opengl.FRAGMENT_SHADER) {
+		gl.setError(opengl.INVALID_ENUM)
+		return 0
+	}
+	name := gl.newName()
+	gl.shaders[name] = &shader{shaderType: shaderType}
+	return name
+}
+
+// DeleteBuffers implements the opengl.OpenGl interface.
+func (gl *OpenGl) DeleteBuffers(buffers []uint32) {
+	for _, name := range buffers {
+		if bufferObj, existing := gl.buffers[name]; existing {
+			if gl.boundBuffer == bufferObj {
+				gl.boundBuffer = nil
+			}
+			delete(gl.buffers, name)
+		}
+	}
+}
+
+// DeleteProgram implements the opengl.OpenGl interface.
+// A program in use stays valid until it is replaced.
+func (gl *OpenGl) DeleteProgram(program uint32) {
+	delete(gl.programs, program)
+}
+
+// DeleteShader implements the opengl.OpenGl interface.
+// Programs already linked keep using the compiled shader.
+func (gl *OpenGl) DeleteShader(shader uint32) {
+	if shaderObj, existing := gl.shaders[shader]; existing {
+		for _, prog := range gl.programs {
+			prog.detach(shaderObj)
+		}
+		delete(gl.shaders, shader)
+	}
+}
+
+// DeleteTextures implements the opengl.OpenGl interface.
+func (gl *OpenGl) DeleteTextures(textures []uint32) {
+	for _, name := range textures {
+		if textureObj, existing := gl.textures[name]; existing {
+			for unit, bound := range gl.textureUnits {
+				if bound == textureObj {
+					gl.textureUnits[unit] = nil
+				}
+			}
+			if (gl.boundFramebuffer != nil) && (gl.boundFramebuffer.color == textureObj) {
+				gl.boundFramebuffer.color = nil
+			}
+			delete(gl.textures, name)
+		}
+	}
+}
+
+// DeleteVertexArrays implements the opengl.OpenGl interface.
+func (gl *OpenGl) DeleteVertexArrays(arrays []uint32) {
+	for _, name := range arrays {
+		if arrayObj, existing := gl.vertexArrays[name]; existing {
+			if gl.boundArray == arrayObj {
+				gl.boundArray = gl.defaultArray
+			}
+			delete(gl.vertexArrays, name)
+		}
+	}
+}
+
+// Disable implements the opengl.OpenGl interface.
+func (gl *OpenGl) Disable(cap uint32) {
+	gl.setCapability(cap, false)
+}
+
+// DrawArrays implements the opengl.OpenGl interface.
+func (gl *OpenGl) DrawArrays(mode uint32, first int32, count int32) {
+	if (first < 0) || (count < 0) {
+		gl.setError(opengl.INVALID_VALUE)
+		return
+	}
+	if (gl.currentProgram == nil) || !gl.currentProgram.linked {
+		gl.setError(opengl.INVALID_OPERATION)
+		return
+	}
+	if !gl.draw(mode, int(first), int(count)) {
+		gl.setError(opengl.INVALID_ENUM)
+	}
+}
+
+// Enable implements the opengl.OpenGl interface.
+func (gl *OpenGl) Enable(cap uint32) {
+	gl.setCapability(cap, true)
+}
+
+func (gl *OpenGl) setCapability(cap uint32, enabled bool) {
+	switch cap {
+	case opengl.BLEND:
+		gl.blendEnabled = enabled
+	case opengl.DEPTH_TEST:
+	default:
+		gl.setError(opengl.INVALID_ENUM)
+	}
+}
+
+// EnableVertexAttribArray implements the opengl.OpenGl interface.
+func (gl *OpenGl) EnableVertexAttribArray(index uint32) {
+	if index >= maxVertexAttribs {
+		gl.setError(opengl.INVALID_VALUE)
+		return
+	}
+	gl.boundArray.attribs[index].enabled = true
+}
+
+// GenerateMipmap implements the opengl.OpenGl interface.
+// Mipmaps are not used, so this function only validates the call.
+func (gl *OpenGl) GenerateMipmap(target uint32) {
+	if target != opengl.TEXTURE_2D {
+		gl.setError(opengl.INVALID_ENUM)
+	} else if gl.textureUnits[gl.activeTextureUnit] == nil {
+		gl.setError(opengl.INVALID_OPERATION)
+	}
+}
+
+// GenBuffers implements the opengl.OpenGl interface.
+func (gl *OpenGl) GenBuffers(n int32) []uint32 {
+	names := make([]uint32, n)
+	for index := range names {
+		names[index] = gl.newName()
+		gl.buffers[names[index]] = &buffer{}
+	}
+	return names
+}
+
+// GenTextures implements the opengl.OpenGl interface.
+func (gl *OpenGl) GenTextures(n int32) []uint32 {
+	names := make([]uint32, n)
+	for index := range names {
+		names[index] = gl.newName()
+		gl.textures[names[index]] = newTexture()
+	}
+	return names
+}
+
+// GenVertexArrays implements the opengl.OpenGl interface.
+func (gl *OpenGl) GenVertexArrays(n int32) []uint32 {
+	names := make([]uint32, n)
+	for index := range names {
+		names[index] = gl.newName()
+		gl.vertexArrays[names[index]] = &vertexArray{}
+	}
+	return names
+}
+
+// GetAttribLocation implements the opengl.OpenGl interface.
+func (gl *OpenGl) GetAttribLocation(program uint32, name string) int32 {
+	prog := gl.programs[program]
+	if (prog == nil) || !prog.linked {
+		gl.setError(opengl.INVALID_OPERATION)
+		return -1
+	}
+	return prog.attribLocation(name)
+}
+
+// GetError implements the opengl.OpenGl interface.
+func (gl *OpenGl) GetError() uint32 {
+	code := gl.lastError
+	gl.lastError = opengl.NO_ERROR
+	return code
+}
+
+// GetShaderInfoLog implements the opengl.OpenGl interface.
+func (gl *OpenGl) GetShaderInfoLog(shader uint32) string {
+	shaderObj := gl.shaders[shader]
+	if shaderObj == nil {
+		gl.setError(opengl.INVALID_VALUE)
+		return ""
+	}
+	return shaderObj.infoLog
+}
+
+// GetShaderParameter implements the opengl.OpenGl interface.
+func (gl *OpenGl) GetShaderParameter(shader uint32, param uint32) int32 {
+	shaderObj := gl.shaders[shader]
+	if shaderObj == nil {
+		gl.setError(opengl.INVALID_VALUE)
+		return 0
+	}
+	switch param {
+	case opengl.COMPILE_STATUS:
+		return boolParameter(shaderObj.compiled != nil)
+	case opengl.INFO_LOG_LENGTH:
+		return logLength(shaderObj.infoLog)
+	}
+	gl.setError(opengl.INVALID_ENUM)
+	return 0
+}
+
+// GetProgramInfoLog implements the opengl.OpenGl interface.
+func (gl *OpenGl) GetProgramInfoLog(program uint32) string {
+	prog := gl.programs[program]
+	if prog == nil {
+		gl.setError(opengl.INVALID_VALUE)
+		return ""
+	}
+	return prog.infoLog
+}
+
+// GetProgramParameter implements the opengl.OpenGl interface.
+func (gl *OpenGl) GetProgramParameter(program uint32, param uint32) int32 {
+	prog := gl.programs[program]
+	if prog == nil {
+		gl.setError(opengl.INVALID_VALUE)
+		return 0
+	}
+	switch param {
+	case opengl.LINK_STATUS:
+		return boolParameter(prog.linked)
+	case opengl.INFO_LOG_LENGTH:
+		return logLength(prog.infoLog)
+	}
+	gl.setError(opengl.INVALID_ENUM)
+	return 0
+}
+
+func boolParameter(flag bool) int32 {
+	if flag {
+		return 1
+	}
+	return 0
+}
+
+func logLength(log string) int32 {
+	if len(log) == 0 {
+		return 0
+	}
+	return int32(len(log) + 1)
+}
+
+// GetUniformLocation implements the opengl.OpenGl interface.
+func (gl *OpenGl) GetUniformLocation(program uint32, name string) int32 {
+	prog := gl.programs[program]
+	if (prog == nil) || !prog.linked {
+		gl.setError(opengl.INVALID_OPERATION)
+		return -1
+	}
+	return prog.uniformLocation(name)
+}
+
+// LinkProgram implements the opengl.OpenGl interface.
+func (gl *OpenGl) LinkProgram(program uint32) {
+	prog := gl.programs[program]
+	if prog == nil {
+		gl.setError(opengl.INVALID_VALUE)
+		return
+	}
+	prog.link()
+}
+
+// ReadPixels implements the opengl.OpenGl interface.
+// Only the format RGBA with type UNSIGNED_BYTE into a byte slice is supported.
+// As with OpenGL, the first row returned is the bottom row of the requested area.
+func (gl *OpenGl) ReadPixels(x int32, y int32, width int32, height int32, format uint32, pixelType uint32, pixels interface{}) {
+	if (format != opengl.RGBA) || (pixelType != opengl.UNSIGNED_BYTE) {
+		gl.setError(opengl.INVALID_ENUM)
+		return
+	}
+	target, isBytes := pixels.([]byte)
+	if !isBytes || (width < 0) || (height < 0) || (len(target) < int(width*height*4)) {
+		gl.setError(opengl.INVALID_VALUE)
+		return
+	}
+	source, sourceWidth, sourceHeight := gl.drawTarget()
+	for row := 0; row < int(height); row++ {
+		for column := 0; column < int(width); column++ {
+			sourceX, sourceY := int(x)+column, int(y)+row
+			targetOffset := (row*int(width) + column) * 4
+			if (sourceX >= 0) && (sourceX < sourceWidth) && (sourceY >= 0) && (sourceY < sourceHeight) {
+				sourceOffset := (sourceY*sourceWidth + sourceX) * 4
+				copy(target[targetOffset:targetOffset+4], source[sourceOffset:sourceOffset+4])
+			}
+		}
+	}
+}
+
+// ShaderSource implements the opengl.OpenGl interface.
+func (gl *OpenGl) ShaderSource(shader uint32, source string) {
+	shaderObj := gl.shaders[shader]
+	if shaderObj == nil {
+		gl.setError(opengl.INVALID_VALUE)
+		return
+	}
+	shaderObj.source = source
+}
+
+// TexImage2D implements the opengl.OpenGl interface.
+// Only the format RGBA with type UNSIGNED_BYTE is supported. Levels other than
+// the base level are accepted, yet ignored.
+func (gl *OpenGl) TexImage2D(target uint32, level int32, internalFormat uint32, width int32, height int32,
+	border int32, format uint32, xtype uint32, pixels interface{}) {
+	textureObj := gl.textureUnits[gl.activeTextureUnit]
+	if target != opengl.TEXTURE_2D {
+		gl.setError(opengl.INVALID_ENUM)
+		return
+	}
+	if (format != opengl.RGBA) || (xtype != opengl.UNSIGNED_BYTE) || (internalFormat != opengl.RGBA) {
+		gl.setError(opengl.INVALID_ENUM)
+		return
+	}
+	if (level < 0) || (width < 0) || (height < 0) || (border != 0) {
+		gl.setError(opengl.INVALID_VALUE)
+		return
+	}
+	if textureObj == nil {
+		gl.setError(opengl.INVALID_OPERATION)
+		return
+	}
+	if level != 0 {
+		return
+	}
+	size := int(width * height * 4)
+	data := make([]byte, size)
+	if pixels != nil {
+		source, isBytes := pixels.([]byte)
+		if !isBytes || (len(source) < size) {
+			gl.setError(opengl.INVALID_VALUE)
+			return
+		}
+		copy(data, source)
+	}
+	textureObj.width = int(width)
+	textureObj.height = int(height)
+	textureObj.pixels = data
+}
+
+// TexParameteri implements the opengl.OpenGl interface.
+// Filters are accepted, yet sampling is always done with nearest filtering.
+func (gl *OpenGl) TexParameteri(target uint32, pname uint32, param int32) {
+	textureObj := gl.textureUnits[gl.activeTextureUnit]
+	if target != opengl.TEXTURE_2D {
+		gl.setError(opengl.INVALID_ENUM)
+		return
+	}
+	if textureObj == nil {
+		gl.setError(opengl.INVALID_OPERATION)
+		return
+	}
+	switch pname {
+	case opengl.TEXTURE_MAG_FILTER, opengl.TEXTURE_MIN_FILTER:
+	case opengl.TEXTURE_WRAP_S:
+		textureObj.wrapS = param
+	case opengl.TEXTURE_WRAP_T:
+		textureObj.wrapT = param
+	default:
+		gl.setError(opengl.INVALID_ENUM)
+	}
+}
+
+func (gl *OpenGl) currentUniform(location int32, accepted ...glslType) *programUniform {
+	if gl.currentProgram == nil {
+		gl.setError(opengl.INVALID_OPERATION)
+		return nil
+	}
+	if location == -1 {
+		return nil
+	}
+	uniform := gl.currentProgram.uniform(location)
+	if uniform != nil {
+		for _, t := range accepted {
+			if uniform.t == t {
+				return uniform
+			}
+		}
+	}
+	gl.setError(opengl.INVALID_OPERATION)
+	return nil
+}
+
+// Uniform1i implements the opengl.OpenGl interface.
+func (gl *OpenGl) Uniform1i(location int32, value int32) {
+	if uniform := gl.currentUniform(location, typeSampler2D, typeFloat, typeBool); uniform != nil {
+		uniform.value[0] = float32(value)
+	}
+}
+
+// Uniform4fv implements the opengl.OpenGl interface.
+func (gl *OpenGl) Uniform4fv(location int32, value *[4]float32) {
+	if uniform := gl.currentUniform(location, typeVec4); uniform != nil {
+		copy(uniform.value[:4], value[:])
+	}
+}
+
+// UniformMatrix4fv implements the opengl.OpenGl interface.
+func (gl *OpenGl) UniformMatrix4fv(location int32, transpose bool, value *[16]float32) {
+	if uniform := gl.currentUniform(location, typeMat4); uniform != nil {
+		for column := 0; column < 4; column++ {
+			for row := 0; row < 4; row++ {
+				if transpose {
+					uniform.value[column*4+row] = value[row*4+column]
+				} else {
+					uniform.value[column*4+row] = value[column*4+row]
+				}
+			}
+		}
+	}
+}
+
+// UseProgram implements the opengl.OpenGl interface.
+func (gl *OpenGl) UseProgram(program uint32) {
+	if program == 0 {
+		gl.currentProgram = nil
+		return
+	}
+	prog := gl.programs[program]
+	if (prog == nil) || !prog.linked {
+		gl.setError(opengl.INVALID_OPERATION)
+		return
+	}
+	gl.currentProgram = prog
+}
+
+// VertexAttribOffset implements the opengl.OpenGl interface.
+func (gl *OpenGl) VertexAttribOffset(index uint32, size int32, attribType uint32, normalized bool, stride int32, offset int) {
+	if (index >= maxVertexAttribs) || (size < 1) || (size > 4) || (stride < 0) || (offset < 0) {
+		gl.setError(opengl.INVALID_VALUE)
+		return
+	}
+	if attribTypeSize(attribType) == 0 {
+		gl.setError(opengl.INVALID_ENUM)
+		return
+	}
+	if gl.boundBuffer == nil {
+		gl.setError(opengl.INVALID_OPERATION)
+		return
+	}
+	attrib := &gl.boundArray.attribs[index]
+	attrib.buffer = gl.boundBuffer
+	attrib.size = int(size)
+	attrib.attribType = attribType
+	attrib.normalized = normalized
+	attrib.stride = int(stride)
+	attrib.offset = offset
+}
+
+// Viewport implements the opengl.OpenGl interface.
+func (gl *OpenGl) Viewport(x int32, y int32, width int32, height int32) {
+	if (width < 0) || (height < 0) {
+		gl.setError(opengl.INVALID_VALUE)
+		return
+	}
+	gl.viewport = [4]int{int(x), int(y), int(width), int(height)}
+}
@@ -0,0 +1,269 @@
+package software
+
+import (
+	mgl "github.com/go-gl/mathgl/mgl32"
+
+	check "gopkg.in/check.v1"
+
+	"github.com/inkyblackness/shocked-client/opengl"
+)
+
+var colorVertexShaderSource = `
+#version 150
+precision mediump float;
+
+in vec2 vertexPosition;
+
+uniform mat4 projectionMatrix;
+
+void main(void) {
+	gl_Position = projectionMatrix * vec4(vertexPosition, 0.0, 1.0);
+}
+`
+
+var colorFragmentShaderSource = `
+#version 150
+precision mediump float;
+
+uniform vec4 color;
+out vec4 fragColor;
+
+void main(void) {
+	fragColor = color;
+}
+`
+
+var textureVertexShaderSource = `
+#version 150
+precision mediump float;
+
+in vec2 vertexPosition;
+in vec2 uvPosition;
+
+uniform mat4 projectionMatrix;
+
+out vec2 uv;
+
+void main(void) {
+	gl_Position = projectionMatrix * vec4(vertexPosition, 0.0, 1.0);
+	uv = uvPosition;
+}
+`
+
+var textureFragmentShaderSource = `
+#version 150
+precision mediump float;
+
+uniform sampler2D bitmap;
+
+in vec2 uv;
+out vec4 fragColor;
+
+void main(void) {
+	vec4 pixel = texture(bitmap, uv);
+
+	if (pixel.a > 0.0) {
+		fragColor = pixel;
+	} else {
+		discard;
+	}
+}
+`
+
+type OpenGlSuite struct {
+	gl         *OpenGl
+	projection mgl.Mat4
+}
+
+var _ = check.Suite(&OpenGlSuite{})
+
+func (suite *OpenGlSuite) SetUpTest(c *check.C) {
+	suite.gl = NewOpenGl(4, 4)
+	suite.projection = mgl.Ortho2D(0, 4, 4, 0)
+}
+
+func (suite *OpenGlSuite) program(c *check.C, vertexSource, fragmentSource string) uint32 {
+	program, err := opengl.LinkNewStandardProgram(suite.gl, vertexSource, fragmentSource)
+	c.Assert(err, check.IsNil)
+	return program
+}
+
+func (suite *OpenGlSuite) buffer(vertices []float32) uint32 {
+	gl := suite.gl
+	buffer := gl.GenBuffers(1)[0]
+	gl.BindBuffer(opengl.ARRAY_BUFFER, buffer)
+	gl.BufferData(opengl.ARRAY_BUFFER, len(vertices)*4, vertices, opengl.STATIC_DRAW)
+	gl.BindBuffer(opengl.ARRAY_BUFFER, 0)
+	return buffer
+}
+
+func (suite *OpenGlSuite) fillRect(c *check.C, left, top, right, bottom float32, color [4]float32) {
+	gl := suite.gl
+	program := suite.program(c, colorVertexShaderSource, colorFragmentShaderSource)
+	buffer := suite.buffer([]float32{
+		left, top, right, top, left, bottom,
+		left, bottom, right, top, right, bottom})
+	attrib := uint32(gl.GetAttribLocation(program, "vertexPosition"))
+
+	gl.UseProgram(program)
+	gl.EnableVertexAttribArray(attrib)
+	gl.BindBuffer(opengl.ARRAY_BUFFER, buffer)
+	gl.VertexAttribOffset(attrib, 2, opengl.FLOAT, false, 0, 0)
+	gl.UniformMatrix4fv(gl.GetUniformLocation(program, "projectionMatrix"), false, (*[16]float32)(&suite.projection))
+	gl.Uniform4fv(gl.GetUniformLocation(program, "color"), &color)
+	gl.DrawArrays(opengl.TRIANGLES, 0, 6)
+}
+
+func (suite *OpenGlSuite) pixel(x, y int) [4]byte {
+	var result [4]byte
+	suite.gl.ReadPixels(int32(x), int32(y), 1, 1, opengl.RGBA, opengl.UNSIGNED_BYTE, result[:])
+	return result
+}
+
+func (suite *OpenGlSuite) TestClearFillsFramebufferWithClearColor(c *check.C) {
+	suite.gl.ClearColor(1.0, 0.0, 0.5, 1.0)
+	suite.gl.Clear(opengl.COLOR_BUFFER_BIT)
+
+	c.Check(suite.pixel(2, 3), check.Equals, [4]byte{255, 0, 128, 255})
+}
+
+func (suite *OpenGlSuite) TestCompileErrorIsReportedInInfoLog(c *check.C) {
+	_, err := opengl.CompileNewShader(suite.gl, opengl.VERTEX_SHADER, "void main(void) { unknown = 1.0; }")
+
+	c.Check(err, check.ErrorMatches, ".*undeclared identifier 'unknown'.*")
+}
+
+func (suite *OpenGlSuite) TestLinkErrorIsReportedForUnmatchedInput(c *check.C) {
+	_, err := opengl.LinkNewStandardProgram(suite.gl, colorVertexShaderSource, textureFragmentShaderSource)
+
+	c.Check(err, check.ErrorMatches, ".*fragment input 'uv'.*")
+}
+
+func (suite *OpenGlSuite) TestDrawArraysFillsCoveredPixels(c *check.C) {
+	suite.fillRect(c, 1, 1, 3, 2, [4]float32{0.0, 1.0, 0.0, 1.0})
+
+	img := suite.gl.Image()
+	c.Check(img.NRGBAAt(1, 1).G, check.Equals, byte(255))
+	c.Check(img.NRGBAAt(2, 1).G, check.Equals, byte(255))
+	c.Check(img.NRGBAAt(0, 1).G, check.Equals, byte(0))
+	c.Check(img.NRGBAAt(1, 2).G, check.Equals, byte(0))
+	c.Check(suite.gl.GetError(), check.Equals, opengl.NO_ERROR)
+}
+
+func (suite *OpenGlSuite) TestBlendingDoesNotDrawSharedEdgesTwice(c *check.C) {
+	suite.gl.Enable(opengl.BLEND)
+	suite.gl.BlendFunc(opengl.SRC_ALPHA, opengl.ONE_MINUS_SRC_ALPHA)
+	suite.fillRect(c, 0, 0, 4, 4, [4]float32{1.0, 1.0, 1.0, 0.5})
+
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			c.Check(suite.pixel(x, y)[0], check.Equals, byte(128), check.Commentf("at %d/%d", x, y))
+		}
+	}
+}
+
+func (suite *OpenGlSuite) TestViewportLimitsRendering(c *check.C) {
+	suite.gl.Viewport(0, 0, 2, 2)
+	suite.projection = mgl.Ortho2D(0, 2, 2, 0)
+	suite.fillRect(c, 0, 0, 2, 2, [4]float32{1.0, 0.0, 0.0, 1.0})
+
+	c.Check(suite.pixel(1, 1), check.Equals, [4]byte{255, 0, 0, 255})
+	c.Check(suite.pixel(2, 2), check.Equals, [4]byte{0, 0, 0, 0})
+}
+
+func (suite *OpenGlSuite) TestTexturesAreSampledAndFragmentsDiscarded(c *check.C) {
+	gl := suite.gl
+	program := suite.program(c, textureVertexShaderSource, textureFragmentShaderSource)
+	buffer := suite.buffer([]float32{
+		0, 0, 0, 0, 4, 0, 1, 0, 0, 4, 0, 1,
+		0, 4, 0, 1, 4, 0, 1, 0, 4, 4, 1, 1})
+	texture := gl.GenTextures(1)[0]
+	gl.BindTexture(opengl.TEXTURE_2D, texture)
+	gl.TexImage2D(opengl.TEXTURE_2D, 0, opengl.RGBA, 2, 1, 0, opengl.RGBA, opengl.UNSIGNED_BYTE,
+		[]byte{10, 20, 30, 255, 0, 0, 0, 0})
+	positionAttrib := uint32(gl.GetAttribLocation(program, "vertexPosition"))
+	uvAttrib := uint32(gl.GetAttribLocation(program, "uvPosition"))
+
+	gl.ClearColor(0.0, 0.0, 1.0, 1.0)
+	gl.Clear(opengl.COLOR_BUFFER_BIT)
+	gl.UseProgram(program)
+	gl.EnableVertexAttribArray(positionAttrib)
+	gl.EnableVertexAttribArray(uvAttrib)
+	gl.BindBuffer(opengl.ARRAY_BUFFER, buffer)
+	gl.VertexAttribOffset(positionAttrib, 2, opengl.FLOAT, false, 16, 0)
+	gl.VertexAttribOffset(uvAttrib, 2, opengl.FLOAT, false, 16, 8)
+	gl.UniformMatrix4fv(gl.GetUniformLocation(program, "projectionMatrix"), false, (*[16]float32)(&suite.projection))
+	gl.Uniform1i(gl.GetUniformLocation(program, "bitmap"), 0)
+	gl.DrawArrays(opengl.TRIANGLES, 0, 6)
+
+	img := gl.Image()
+	c.Check(img.NRGBAAt(1, 0).R, check.Equals, byte(10))
+	c.Check(img.NRGBAAt(1, 3).B, check.Equals, byte(30))
+	c.Check(img.NRGBAAt(2, 0).B, check.Equals, byte(255))
+	c.Check(gl.GetError(), check.Equals, opengl.NO_ERROR)
+}
+
+func (suite *OpenGlSuite) TestUniformOfWrongTypeSetsError(c *check.C) {
+	gl := suite.gl
+	program := suite.program(c, colorVertexShaderSource, colorFragmentShaderSource)
+	gl.UseProgram(program)
+	gl.Uniform1i(gl.GetUniformLocation(program, "color"), 1)
+
+	c.Check(gl.GetError(), check.Equals, uint32(opengl.INVALID_OPERATION))
+	c.Check(gl.GetError(), check.Equals, opengl.NO_ERROR)
+}
+
+func (suite *OpenGlSuite) givenFramebufferWithTexture(width, height int32) (framebuffer, texture uint32) {
+	gl := suite.gl
+	texture = gl.GenTextures(1)[0]
+	gl.BindTexture(opengl.TEXTURE_2D, texture)
+	gl.TexImage2D(opengl.TEXTURE_2D, 0, opengl.RGBA, width, height, 0, opengl.RGBA, opengl.UNSIGNED_BYTE, nil)
+	gl.BindTexture(opengl.TEXTURE_2D, 0)
+	framebuffer = gl.GenFramebuffers(1)[0]
+	gl.BindFramebuffer(opengl.FRAMEBUFFER, framebuffer)
+	gl.FramebufferTexture2D(opengl.FRAMEBUFFER, opengl.COLOR_ATTACHMENT0, opengl.TEXTURE_2D, texture, 0)
+	return
+}
+
+func (suite *OpenGlSuite) TestFramebufferObjectRendersIntoTexture(c *check.C) {
+	suite.givenFramebufferWithTexture(4, 4)
+	c.Assert(suite.gl.CheckFramebufferStatus(opengl.FRAMEBUFFER), check.Equals, uint32(opengl.FRAMEBUFFER_COMPLETE))
+
+	suite.fillRect(c, 0, 0, 2, 2, [4]float32{1.0, 0.0, 0.0, 1.0})
+
+	c.Check(suite.pixel(1, 3), check.Equals, [4]byte{255, 0, 0, 255})
+	c.Check(suite.pixel(3, 0), check.Equals, [4]byte{0, 0, 0, 0})
+	c.Check(suite.gl.GetError(), check.Equals, opengl.NO_ERROR)
+}
+
+func (suite *OpenGlSuite) TestFramebufferObjectKeepsDefaultFramebuffer(c *check.C) {
+	suite.gl.ClearColor(0.0, 0.0, 1.0, 1.0)
+	suite.gl.Clear(opengl.COLOR_BUFFER_BIT)
+	suite.givenFramebufferWithTexture(4, 4)
+
+	suite.gl.ClearColor(0.0, 1.0, 0.0, 1.0)
+	suite.gl.Clear(opengl.COLOR_BUFFER_BIT)
+	suite.fillRect(c, 0, 0, 4, 4, [4]float32{1.0, 0.0, 0.0, 1.0})
+	suite.gl.BindFramebuffer(opengl.FRAMEBUFFER, 0)
+
+	c.Check(suite.pixel(2, 2), check.Equals, [4]byte{0, 0, 255, 255})
+}
+
+func (suite *OpenGlSuite) TestFramebufferObjectWithoutAttachmentIsIncomplete(c *check.C) {
+	framebuffer := suite.gl.GenFramebuffers(1)[0]
+	suite.gl.BindFramebuffer(opengl.FRAMEBUFFER, framebuffer)
+
+	c.Check(suite.gl.CheckFramebufferStatus(opengl.FRAMEBUFFER), check.Equals, uint32(opengl.FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT))
+	suite.gl.Clear(opengl.COLOR_BUFFER_BIT)
+	c.Check(suite.gl.GetError(), check.Equals, opengl.NO_ERROR)
+}
+
+func (suite *OpenGlSuite) TestDeletedFramebufferObjectIsUnbound(c *check.C) {
+	framebuffer, _ := suite.givenFramebufferWithTexture(2, 2)
+
+	suite.gl.DeleteFramebuffers([]uint32{framebuffer})
+	suite.gl.ClearColor(1.0, 1.0, 1.0, 1.0)
+	suite.gl.Clear(opengl.COLOR_BUFFER_BIT)
+
+	c.Check(suite.pixel(3, 3), check.Equals, [4]byte{255, 255, 255, 255})
+}
@@ -0,0 +1,286 @@
+package software
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/inkyblackness/shocked-client/opengl"
+)
+
+// shader is a shader object, holding the source and the result of its compilation.
+type shader struct {
+	shaderType uint32
+	source     string
+	compiled   *compiledShader
+	infoLog    string
+}
+
+func builtInsFor(shaderType uint32) []*globalVariable {
+	if shaderType == opengl.VERTEX_SHADER {
+		return []*globalVariable{
+			{name: "gl_Position", t: typeVec4, qualifier: "out"},
+			{name: "gl_PointSize", t: typeFloat, qualifier: "out"}}
+	}
+	return []*globalVariable{
+		{name: "gl_FragCoord", t: typeVec4, qualifier: "in"},
+		{name: "gl_FrontFacing", t: typeBool, qualifier: "in"},
+		{name: "gl_FragColor", t: typeVec4, qualifier: "out"}}
+}
+
+func (shader *shader) compile() {
+	compiled, err := compileShader(shader.source, builtInsFor(shader.shaderType))
+	shader.compiled = compiled
+	shader.infoLog = ""
+	if err != nil {
+		shader.infoLog = err.Error()
+	}
+}
+
+type programAttribute struct {
+	name     string
+	t        glslType
+	location uint32
+	slot     int
+}
+
+type programUniform struct {
+	name         string
+	t            glslType
+	vertexSlot   int
+	fragmentSlot int
+	value        value
+}
+
+type programVarying struct {
+	components   int
+	vertexSlot   int
+	fragmentSlot int
+	offset       int
+}
+
+// program is a program object. Linking takes a snapshot of the attached shaders,
+// which may be deleted afterwards.
+type program struct {
+	attached       []*shader
+	boundLocations map[string]uint32
+	linked         bool
+	infoLog        string
+
+	vertex   *compiledShader
+	fragment *compiledShader
+
+	attributes  []programAttribute
+	uniforms    []*programUniform
+	varyings    []programVarying
+	varyingSize int
+
+	positionSlot    int
+	fragCoordSlot   int
+	frontFacingSlot int
+	outputSlot      int
+}
+
+func newProgram() *program {
+	return &program{boundLocations: make(map[string]uint32)}
+}
+
+func (prog *program) attach(shader *shader) bool {
+	for _, existing := range prog.attached {
+		if (existing == shader) || (existing.shaderType == shader.shaderType) {
+			return false
+		}
+	}
+	prog.attached = append(prog.attached, shader)
+	return true
+}
+
+func (prog *program) detach(shader *shader) {
+	for index, existing := range prog.attached {
+		if existing == shader {
+			prog.attached = append(prog.attached[:index], prog.attached[index+1:]...)
+			return
+		}
+	}
+}
+
+func (prog *program) link() {
+	err := prog.tryLink()
+	prog.linked = err == nil
+	prog.infoLog = ""
+	if err != nil {
+		prog.infoLog = err.Error()
+	}
+}
+
+func (prog *program) tryLink() error {
+	var vertex, fragment *compiledShader
+	for _, attached := range prog.attached {
+		if attached.shaderType == opengl.VERTEX_SHADER {
+			vertex = attached.compiled
+		} else {
+			fragment = attached.compiled
+		}
+	}
+	if (vertex == nil) || (fragment == nil) {
+		return fmt.Errorf("program requires a compiled vertex and fragment shader")
+	}
+
+	attributes, err := prog.linkAttributes(vertex)
+	if err != nil {
+		return err
+	}
+	uniforms, err := linkUniforms(vertex, fragment)
+	if err != nil {
+		return err
+	}
+	varyings, varyingSize, err := linkVaryings(vertex, fragment)
+	if err != nil {
+		return err
+	}
+	output := fragment.global("gl_FragColor")
+	for _, entry := range fragment.globals {
+		if (entry.qualifier == "out") && !strings.HasPrefix(entry.name, "gl_") {
+			if entry.t != typeVec4 {
+				return fmt.Errorf("fragment output '%s' must be vec4", entry.name)
+			}
+			output = entry
+			break
+		}
+	}
+
+	prog.vertex = vertex
+	prog.fragment = fragment
+	prog.attributes = attributes
+	prog.uniforms = uniforms
+	prog.varyings = varyings
+	prog.varyingSize = varyingSize
+	prog.positionSlot = vertex.global("gl_Position").slot
+	prog.fragCoordSlot = fragment.global("gl_FragCoord").slot
+	prog.frontFacingSlot = fragment.global("gl_FrontFacing").slot
+	prog.outputSlot = output.slot
+
+	return nil
+}
+
+func (prog *program) linkAttributes(vertex *compiledShader) ([]programAttribute, error) {
+	var attributes []programAttribute
+	used := make(map[uint32]bool)
+	for _, entry := range vertex.globals {
+		if (entry.qualifier == "in") && !strings.HasPrefix(entry.name, "gl_") {
+			if !entry.t.isNumeric() || (entry.t == typeMat4) {
+				return nil, fmt.Errorf("vertex input '%s' has unsupported type %v", entry.name, entry.t)
+			}
+			attribute := programAttribute{name: entry.name, t: entry.t, slot: entry.slot}
+			if location, bound := prog.boundLocations[entry.name]; bound {
+				attribute.location = location
+				used[location] = true
+			} else {
+				attribute.location = maxVertexAttribs
+			}
+			attributes = append(attributes, attribute)
+		}
+	}
+	next := uint32(0)
+	for index := range attributes {
+		if attributes[index].location == maxVertexAttribs {
+			for used[next] {
+				next++
+			}
+			if next >= maxVertexAttribs {
+				return nil, fmt.Errorf("too many vertex inputs")
+			}
+			attributes[index].location = next
+			used[next] = true
+		}
+	}
+	return attributes, nil
+}
+
+func linkUniforms(vertex, fragment *compiledShader) ([]*programUniform, error) {
+	var uniforms []*programUniform
+	byName := make(map[string]*programUniform)
+	add := func(entry *globalVariable, isVertex bool) error {
+		uniform, existing := byName[entry.name]
+		if !existing {
+			uniform = &programUniform{name: entry.name, t: entry.t, vertexSlot: -1, fragmentSlot: -1}
+			byName[entry.name] = uniform
+			uniforms = append(uniforms, uniform)
+		} else if uniform.t != entry.t {
+			return fmt.Errorf("uniform '%s' declared with different types %v and %v", entry.name, uniform.t, entry.t)
+		}
+		if isVertex {
+			uniform.vertexSlot = entry.slot
+		} else {
+			uniform.fragmentSlot = entry.slot
+		}
+		return nil
+	}
+	for _, entry := range vertex.globals {
+		if entry.qualifier == "uniform" {
+			if err := add(entry, true); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, entry := range fragment.globals {
+		if entry.qualifier == "uniform" {
+			if err := add(entry, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return uniforms, nil
+}
+
+func linkVaryings(vertex, fragment *compiledShader) ([]programVarying, int, error) {
+	var varyings []programVarying
+	size := 0
+	for _, entry := range fragment.globals {
+		if (entry.qualifier == "in") && !strings.HasPrefix(entry.name, "gl_") {
+			source := vertex.global(entry.name)
+			if (source == nil) || (source.qualifier != "out") {
+				return nil, 0, fmt.Errorf("fragment input '%s' is not written by the vertex shader", entry.name)
+			}
+			if source.t != entry.t {
+				return nil, 0, fmt.Errorf("type mismatch for '%s': %v and %v", entry.name, source.t, entry.t)
+			}
+			if !entry.t.isNumeric() {
+				return nil, 0, fmt.Errorf("fragment input '%s' has unsupported type %v", entry.name, entry.t)
+			}
+			components := entry.t.components()
+			varyings = append(varyings, programVarying{
+				components:   components,
+				vertexSlot:   source.slot,
+				fragmentSlot: entry.slot,
+				offset:       size})
+			size += components
+		}
+	}
+	return varyings, size, nil
+}
+
+func (prog *program) attribLocation(name string) int32 {
+	for _, attribute := range prog.attributes {
+		if attribute.name == name {
+			return int32(attribute.location)
+		}
+	}
+	return -1
+}
+
+func (prog *program) uniformLocation(name string) int32 {
+	for location, uniform := range prog.uniforms {
+		if uniform.name == name {
+			return int32(location)
+		}
+	}
+	return -1
+}
+
+// uniform returns the uniform at given location, or nil if the location is invalid.
+func (prog *program) uniform(location int32) *programUniform {
+	if (location < 0) || (int(location) >= len(prog.uniforms)) {
+		return nil
+	}
+	return prog.uniforms[location]
+}
@@ -0,0 +1,371 @@
+package software
+
+import (
+	"math"
+
+	"github.com/inkyblackness/shocked-client/opengl"
+)
+
+// shadedVertex is the result of the vertex stage, already transformed to window coordinates.
+type shadedVertex struct {
+	x, y, z  float32
+	inverseW float32
+	clipped  bool
+	varyings []float32
+}
+
+// fragmentStage holds the state to process the fragments of one draw call.
+type fragmentStage struct {
+	gl           *OpenGl
+	prog         *program
+	globals      []value
+	inv          invocation
+	interpolated []float32
+}
+
+func (gl *OpenGl) draw(mode uint32, first, count int) bool {
+	var assemble func(vertices []shadedVertex, stage *fragmentStage)
+	switch mode {
+	case opengl.POINTS:
+		assemble = assemblePoints
+	case opengl.LINES:
+		assemble = assembleLines
+	case opengl.LINE_STRIP:
+		assemble = assembleLineStrip
+	case opengl.LINE_LOOP:
+		assemble = assembleLineLoop
+	case opengl.TRIANGLES:
+		assemble = assembleTriangles
+	case opengl.TRIANGLE_STRIP:
+		assemble = assembleTriangleStrip
+	case opengl.TRIANGLE_FAN:
+		assemble = assembleTriangleFan
+	default:
+		return false
+	}
+	prog := gl.currentProgram
+	vertices := gl.shadeVertices(prog, first, count)
+	stage := &fragmentStage{
+		gl:           gl,
+		prog:         prog,
+		globals:      make([]value, prog.fragment.globalCount),
+		interpolated: make([]float32, prog.varyingSize)}
+	stage.inv.sampler = gl.sample
+	for _, uniform := range prog.uniforms {
+		if uniform.fragmentSlot >= 0 {
+			stage.globals[uniform.fragmentSlot] = uniform.value
+		}
+	}
+	assemble(vertices, stage)
+	return true
+}
+
+func (gl *OpenGl) sample(unit int, s, t float32) [4]float32 {
+	if (unit < 0) || (unit >= maxTextureUnits) || (gl.textureUnits[unit] == nil) {
+		return [4]float32{0.0, 0.0, 0.0, 1.0}
+	}
+	return gl.textureUnits[unit].sample(s, t)
+}
+
+func (gl *OpenGl) shadeVertices(prog *program, first, count int) []shadedVertex {
+	globals := make([]value, prog.vertex.globalCount)
+	for _, uniform := range prog.uniforms {
+		if uniform.vertexSlot >= 0 {
+			globals[uniform.vertexSlot] = uniform.value
+		}
+	}
+	inv := invocation{sampler: gl.sample}
+	vertices := make([]shadedVertex, count)
+	viewX, viewY := float32(gl.viewport[0]), float32(gl.viewport[1])
+	viewWidth, viewHeight := float32(gl.viewport[2]), float32(gl.viewport[3])
+
+	for index := range vertices {
+		for _, attribute := range prog.attributes {
+			globals[attribute.slot] = gl.boundArray.attribs[attribute.location].fetch(first + index)
+		}
+		prog.vertex.run(globals, &inv)
+
+		vertex := &vertices[index]
+		position := globals[prog.positionSlot]
+		vertex.varyings = make([]float32, prog.varyingSize)
+		for _, varying := range prog.varyings {
+			copy(vertex.varyings[varying.offset:varying.offset+varying.components], globals[varying.vertexSlot][:varying.components])
+		}
+		if position[3] <= 0.0 {
+			vertex.clipped = true
+			continue
+		}
+		vertex.inverseW = 1.0 / position[3]
+		vertex.x = viewX + (position[0]*vertex.inverseW+1.0)*viewWidth/2.0
+		vertex.y = viewY + (position[1]*vertex.inverseW+1.0)*viewHeight/2.0
+		vertex.z = (position[2]*vertex.inverseW + 1.0) / 2.0
+	}
+	return vertices
+}
+
+func assemblePoints(vertices []shadedVertex, stage *fragmentStage) {
+	for index := range vertices {
+		stage.point(&vertices[index])
+	}
+}
+
+func assembleLines(vertices []shadedVertex, stage *fragmentStage) {
+	for index := 0; index+1 < len(vertices); index += 2 {
+		stage.line(&vertices[index], &vertices[index+1])
+	}
+}
+
+func assembleLineStrip(vertices []shadedVertex, stage *fragmentStage) {
+	for index := 0; index+1 < len(vertices); index++ {
+		stage.line(&vertices[index], &vertices[index+1])
+	}
+}
+
+func assembleLineLoop(vertices []shadedVertex, stage *fragmentStage) {
+	assembleLineStrip(vertices, stage)
+	if len(vertices) > 2 {
+		stage.line(&vertices[len(vertices)-1], &vertices[0])
+	}
+}
+
+func assembleTriangles(vertices []shadedVertex, stage *fragmentStage) {
+	for index := 0; index+2 < len(vertices); index += 3 {
+		stage.triangle(&vertices[index], &vertices[index+1], &vertices[index+2])
+	}
+}
+
+func assembleTriangleStrip(vertices []shadedVertex, stage *fragmentStage) {
+	for index := 0; index+2 < len(vertices); index++ {
+		if (index % 2) == 0 {
+			stage.triangle(&vertices[index], &vertices[index+1], &vertices[index+2])
+		} else {
+			stage.triangle(&vertices[index+1], &vertices[index], &vertices[index+2])
+		}
+	}
+}
+
+func assembleTriangleFan(vertices []shadedVertex, stage *fragmentStage) {
+	for index := 1; index+1 < len(vertices); index++ {
+		stage.triangle(&vertices[0], &vertices[index], &vertices[index+1])
+	}
+}
+
+// bounds returns the pixel rectangle fragments may be produced in: the intersection
+// of viewport and framebuffer.
+func (stage *fragmentStage) bounds() (minX, minY, maxX, maxY int) {
+	viewport := stage.gl.viewport
+	minX, minY = viewport[0], viewport[1]
+	maxX, maxY = viewport[0]+viewport[2], viewport[1]+viewport[3]
+	if minX < 0 {
+		minX = 0
+	}
+	if minY < 0 {
+		minY = 0
+	}
+	_, width, height := stage.gl.drawTarget()
+	if maxX > width {
+		maxX = width
+	}
+	if maxY > height {
+		maxY = height
+	}
+	return
+}
+
+func (stage *fragmentStage) point(vertex *shadedVertex) {
+	if vertex.clipped {
+		return
+	}
+	x := int(math.Floor(float64(vertex.x)))
+	y := int(math.Floor(float64(vertex.y)))
+	copy(stage.interpolated, vertex.varyings)
+	stage.fragment(x, y, vertex.z, vertex.inverseW, true)
+}
+
+// line rasterizes a line by sampling it at the center of each step along its major axis.
+func (stage *fragmentStage) line(a, b *shadedVertex) {
+	if a.clipped || b.clipped {
+		return
+	}
+	deltaX, deltaY := b.x-a.x, b.y-a.y
+	steps := int(math.Floor(math.Max(math.Abs(float64(deltaX)), math.Abs(float64(deltaY))) + 0.5))
+	for step := 0; step < steps; step++ {
+		t := (float32(step) + 0.5) / float32(steps)
+		x := int(math.Floor(float64(a.x + deltaX*t)))
+		y := int(math.Floor(float64(a.y + deltaY*t)))
+		weightA := (1.0 - t) * a.inverseW
+		weightB := t * b.inverseW
+		inverseW := weightA + weightB
+		for index := range stage.interpolated {
+			stage.interpolated[index] = (a.varyings[index]*weightA + b.varyings[index]*weightB) / inverseW
+		}
+		stage.fragment(x, y, a.z+(b.z-a.z)*t, inverseW, true)
+	}
+}
+
+func edge(ax, ay, bx, by, px, py float32) float32 {
+	return (bx-ax)*(py-ay) - (by-ay)*(px-ax)
+}
+
+// isTopLeft returns true if a pixel center exactly on the given edge of a
+// counter-clockwise triangle belongs to the triangle. This avoids drawing pixels
+// twice along edges shared by adjacent triangles.
+func isTopLeft(ax, ay, bx, by float32) bool {
+	return (by < ay) || ((by == ay) && (bx < ax))
+}
+
+func (stage *fragmentStage) triangle(v0, v1, v2 *shadedVertex) {
+	if v0.clipped || v1.clipped || v2.clipped {
+		return
+	}
+	area := edge(v0.x, v0.y, v1.x, v1.y, v2.x, v2.y)
+	if area == 0.0 {
+		return
+	}
+	frontFacing := area > 0.0
+	if !frontFacing {
+		v1, v2 = v2, v1
+		area = -area
+	}
+	minX, minY, maxX, maxY := stage.bounds()
+	left := int(math.Floor(float64(min3(v0.x, v1.x, v2.x))))
+	right := int(math.Ceil(float64(max3(v0.x, v1.x, v2.x))))
+	bottom := int(math.Floor(float64(min3(v0.y, v1.y, v2.y))))
+	top := int(math.Ceil(float64(max3(v0.y, v1.y, v2.y))))
+	if left > minX {
+		minX = left
+	}
+	if right < maxX {
+		maxX = right
+	}
+	if bottom > minY {
+		minY = bottom
+	}
+	if top < maxY {
+		maxY = top
+	}
+	topLeft0 := isTopLeft(v1.x, v1.y, v2.x, v2.y)
+	topLeft1 := isTopLeft(v2.x, v2.y, v0.x, v0.y)
+	topLeft2 := isTopLeft(v0.x, v0.y, v1.x, v1.y)
+
+	for y := minY; y < maxY; y++ {
+		centerY := float32(y) + 0.5
+		for x := minX; x < maxX; x++ {
+			centerX := float32(x) + 0.5
+			w0 := edge(v1.x, v1.y, v2.x, v2.y, centerX, centerY)
+			w1 := edge(v2.x, v2.y, v0.x, v0.y, centerX, centerY)
+			w2 := edge(v0.x, v0.y, v1.x, v1.y, centerX, centerY)
+			if !covers(w0, topLeft0) || !covers(w1, topLeft1) || !covers(w2, topLeft2) {
+				continue
+			}
+			l0, l1, l2 := w0/area, w1/area, w2/area
+			p0, p1, p2 := l0*v0.inverseW, l1*v1.inverseW, l2*v2.inverseW
+			inverseW := p0 + p1 + p2
+			for index := range stage.interpolated {
+				stage.interpolated[index] = (v0.varyings[index]*p0 + v1.varyings[index]*p1 + v2.varyings[index]*p2) / inverseW
+			}
+			stage.fragment(x, y, l0*v0.z+l1*v1.z+l2*v2.z, inverseW, frontFacing)
+		}
+	}
+}
+
+func covers(weight float32, topLeft bool) bool {
+	return (weight > 0.0) || ((weight == 0.0) && topLeft)
+}
+
+func min3(a, b, c float32) float32 {
+	return float32(math.Min(float64(a), math.Min(float64(b), float64(c))))
+}
+
+func max3(a, b, c float32) float32 {
+	return float32(math.Max(float64(a), math.Max(float64(b), float64(c))))
+}
+
+// fragment runs the fragment shader for the given pixel and writes the result.
+func (stage *fragmentStage) fragment(x, y int, z, inverseW float32, frontFacing bool) {
+	minX, minY, maxX, maxY := stage.bounds()
+	if (x < minX) || (x >= maxX) || (y < minY) || (y >= maxY) {
+		return
+	}
+	prog := stage.prog
+	for _, varying := range prog.varyings {
+		target := &stage.globals[varying.fragmentSlot]
+		copy(target[:varying.components], stage.interpolated[varying.offset:varying.offset+varying.components])
+	}
+	stage.globals[prog.fragCoordSlot] = value{float32(x) + 0.5, float32(y) + 0.5, z, inverseW}
+	stage.globals[prog.frontFacingSlot] = boolValue(frontFacing)
+	if !prog.fragment.run(stage.globals, &stage.inv) {
+		return
+	}
+	stage.gl.writePixel(x, y, stage.globals[prog.outputSlot])
+}
+
+func (gl *OpenGl) writePixel(x, y int, color value) {
+	pixels, width, _ := gl.drawTarget()
+	offset := (y*width + x) * 4
+	target := pixels[offset : offset+4]
+	var source [4]float32
+	for index := 0; index < 4; index++ {
+		source[index] = clampUnit(color[index])
+	}
+	if gl.blendEnabled {
+		var destination [4]float32
+		for index := 0; index < 4; index++ {
+			destination[index] = float32(target[index]) / 255.0
+		}
+		for index := 0; index < 4; index++ {
+			source[index] = source[index]*blendFactor(gl.sourceFactor, &source, &destination, index) +
+				destination[index]*blendFactor(gl.destinationFactor, &source, &destination, index)
+		}
+	}
+	for index := 0; index < 4; index++ {
+		target[index] = toByte(source[index])
+	}
+}
+
+func isBlendFactor(factor uint32) bool {
+	switch factor {
+	case opengl.ZERO, opengl.ONE,
+		opengl.SRC_COLOR, opengl.ONE_MINUS_SRC_COLOR, opengl.SRC_ALPHA, opengl.ONE_MINUS_SRC_ALPHA,
+		opengl.DST_COLOR, opengl.ONE_MINUS_DST_COLOR, opengl.DST_ALPHA, opengl.ONE_MINUS_DST_ALPHA:
+		return true
+	}
+	return false
+}
+
+func blendFactor(factor uint32, source, destination *[4]float32, index int) float32 {
+	switch factor {
+	case opengl.ZERO:
+		return 0.0
+	case opengl.SRC_COLOR:
+		return source[index]
+	case opengl.ONE_MINUS_SRC_COLOR:
+		return 1.0 - source[index]
+	case opengl.SRC_ALPHA:
+		return source[3]
+	case opengl.ONE_MINUS_SRC_ALPHA:
+		return 1.0 - source[3]
+	case opengl.DST_COLOR:
+		return destination[index]
+	case opengl.ONE_MINUS_DST_COLOR:
+		return 1.0 - destination[index]
+	case opengl.DST_ALPHA:
+		return destination[3]
+	case opengl.ONE_MINUS_DST_ALPHA:
+		return 1.0 - destination[3]
+	}
+	return 1.0
+}
+
+func clampUnit(component float32) float32 {
+	if component < 0.0 || component != component {
+		return 0.0
+	} else if component > 1.0 {
+		return 1.0
+	}
+	return component
+}
+
+func toByte(component float32) byte {
+	return byte(math.Floor(float64(clampUnit(component))*255.0 + 0.5))
+}
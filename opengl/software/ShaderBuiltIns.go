@@ -0,0 +1,232 @@
+package software
+
+import (
+	"fmt"
+	"math"
+)
+
+type builtInFunction func(args []expression) (expression, error)
+
+var builtInFunctions map[string]builtInFunction
+
+func init() {
+	builtInFunctions = map[string]builtInFunction{
+		"abs":         componentwise(1, func(a []float32) float32 { return float32(math.Abs(float64(a[0]))) }),
+		"sign":        componentwise(1, sign),
+		"floor":       componentwise(1, func(a []float32) float32 { return float32(math.Floor(float64(a[0]))) }),
+		"ceil":        componentwise(1, func(a []float32) float32 { return float32(math.Ceil(float64(a[0]))) }),
+		"trunc":       componentwise(1, func(a []float32) float32 { return float32(math.Trunc(float64(a[0]))) }),
+		"round":       componentwise(1, func(a []float32) float32 { return float32(math.Floor(float64(a[0]) + 0.5)) }),
+		"fract":       componentwise(1, func(a []float32) float32 { return a[0] - float32(math.Floor(float64(a[0]))) }),
+		"sqrt":        componentwise(1, func(a []float32) float32 { return float32(math.Sqrt(float64(a[0]))) }),
+		"inversesqrt": componentwise(1, func(a []float32) float32 { return float32(1.0 / math.Sqrt(float64(a[0]))) }),
+		"exp":         componentwise(1, func(a []float32) float32 { return float32(math.Exp(float64(a[0]))) }),
+		"exp2":        componentwise(1, func(a []float32) float32 { return float32(math.Exp2(float64(a[0]))) }),
+		"log":         componentwise(1, func(a []float32) float32 { return float32(math.Log(float64(a[0]))) }),
+		"log2":        componentwise(1, func(a []float32) float32 { return float32(math.Log2(float64(a[0]))) }),
+		"sin":         componentwise(1, func(a []float32) float32 { return float32(math.Sin(float64(a[0]))) }),
+		"cos":         componentwise(1, func(a []float32) float32 { return float32(math.Cos(float64(a[0]))) }),
+		"tan":         componentwise(1, func(a []float32) float32 { return float32(math.Tan(float64(a[0]))) }),
+		"radians":     componentwise(1, func(a []float32) float32 { return a[0] * math.Pi / 180.0 }),
+		"degrees":     componentwise(1, func(a []float32) float32 { return a[0] * 180.0 / math.Pi }),
+		"min":         componentwise(2, func(a []float32) float32 { return float32(math.Min(float64(a[0]), float64(a[1]))) }),
+		"max":         componentwise(2, func(a []float32) float32 { return float32(math.Max(float64(a[0]), float64(a[1]))) }),
+		"mod":         componentwise(2, func(a []float32) float32 { return a[0] - a[1]*float32(math.Floor(float64(a[0]/a[1]))) }),
+		"pow":         componentwise(2, func(a []float32) float32 { return float32(math.Pow(float64(a[0]), float64(a[1]))) }),
+		"step":        componentwise(2, step),
+		"clamp":       componentwise(3, clamp),
+		"mix":         componentwise(3, func(a []float32) float32 { return a[0]*(1.0-a[2]) + a[1]*a[2] }),
+		"smoothstep":  componentwise(3, smoothstep),
+		"dot":         dot,
+		"length":      length,
+		"distance":    distance,
+		"normalize":   normalize,
+		"cross":       cross,
+		"texture":     textureLookup,
+		"texture2D":   textureLookup}
+}
+
+func sign(a []float32) float32 {
+	switch {
+	case a[0] > 0.0:
+		return 1.0
+	case a[0] < 0.0:
+		return -1.0
+	}
+	return 0.0
+}
+
+func step(a []float32) float32 {
+	if a[1] < a[0] {
+		return 0.0
+	}
+	return 1.0
+}
+
+func clamp(a []float32) float32 {
+	return float32(math.Min(math.Max(float64(a[0]), float64(a[1])), float64(a[2])))
+}
+
+func smoothstep(a []float32) float32 {
+	t := clamp([]float32{(a[2] - a[0]) / (a[1] - a[0]), 0.0, 1.0})
+	return t * t * (3.0 - 2.0*t)
+}
+
+// componentwise creates a built-in function that applies the given operation to each component.
+// The result type is the one of the first vector argument; all other arguments must either
+// be of the same type, or be a scalar - which is then applied to all components.
+func componentwise(arity int, operation func([]float32) float32) builtInFunction {
+	return func(args []expression) (expr expression, err error) {
+		if len(args) != arity {
+			return expr, fmt.Errorf("expected %d arguments, got %d", arity, len(args))
+		}
+		resultType := typeFloat
+		for _, arg := range args {
+			if !arg.t.isGeneric() {
+				return expr, fmt.Errorf("invalid argument type %v", arg.t)
+			}
+			if arg.t != typeFloat {
+				if (resultType != typeFloat) && (resultType != arg.t) {
+					return expr, fmt.Errorf("mismatching argument types %v and %v", resultType, arg.t)
+				}
+				resultType = arg.t
+			}
+		}
+		count := resultType.components()
+		scalar := make([]bool, arity)
+		for index, arg := range args {
+			scalar[index] = (arg.t == typeFloat)
+		}
+		expr = expression{t: resultType, eval: func(f *frame) (result value) {
+			var evaluated [3]value
+			var operands [3]float32
+			for index, arg := range args {
+				evaluated[index] = arg.eval(f)
+			}
+			for component := 0; component < count; component++ {
+				for index := 0; index < arity; index++ {
+					if scalar[index] {
+						operands[index] = evaluated[index][0]
+					} else {
+						operands[index] = evaluated[index][component]
+					}
+				}
+				result[component] = operation(operands[:arity])
+			}
+			return
+		}}
+		return
+	}
+}
+
+func sameVectors(args []expression, count int) error {
+	if len(args) != count {
+		return fmt.Errorf("expected %d arguments, got %d", count, len(args))
+	}
+	for _, arg := range args {
+		if !arg.t.isGeneric() || (arg.t != args[0].t) {
+			return fmt.Errorf("invalid argument type %v", arg.t)
+		}
+	}
+	return nil
+}
+
+func dotProduct(a, b *value, count int) float32 {
+	sum := float32(0.0)
+	for index := 0; index < count; index++ {
+		sum += a[index] * b[index]
+	}
+	return sum
+}
+
+func dot(args []expression) (expr expression, err error) {
+	if err = sameVectors(args, 2); err != nil {
+		return
+	}
+	count := args[0].t.components()
+	expr = expression{t: typeFloat, eval: func(f *frame) (result value) {
+		a := args[0].eval(f)
+		b := args[1].eval(f)
+		result[0] = dotProduct(&a, &b, count)
+		return
+	}}
+	return
+}
+
+func length(args []expression) (expr expression, err error) {
+	if err = sameVectors(args, 1); err != nil {
+		return
+	}
+	count := args[0].t.components()
+	expr = expression{t: typeFloat, eval: func(f *frame) (result value) {
+		a := args[0].eval(f)
+		result[0] = float32(math.Sqrt(float64(dotProduct(&a, &a, count))))
+		return
+	}}
+	return
+}
+
+func distance(args []expression) (expr expression, err error) {
+	if err = sameVectors(args, 2); err != nil {
+		return
+	}
+	count := args[0].t.components()
+	expr = expression{t: typeFloat, eval: func(f *frame) (result value) {
+		a := args[0].eval(f)
+		b := args[1].eval(f)
+		for index := 0; index < count; index++ {
+			a[index] -= b[index]
+		}
+		result[0] = float32(math.Sqrt(float64(dotProduct(&a, &a, count))))
+		return
+	}}
+	return
+}
+
+func normalize(args []expression) (expr expression, err error) {
+	if err = sameVectors(args, 1); err != nil {
+		return
+	}
+	count := args[0].t.components()
+	expr = expression{t: args[0].t, eval: func(f *frame) (result value) {
+		a := args[0].eval(f)
+		size := float32(math.Sqrt(float64(dotProduct(&a, &a, count))))
+		for index := 0; index < count; index++ {
+			result[index] = a[index] / size
+		}
+		return
+	}}
+	return
+}
+
+func cross(args []expression) (expr expression, err error) {
+	if err = sameVectors(args, 2); err != nil {
+		return
+	}
+	if args[0].t != typeVec3 {
+		return expr, fmt.Errorf("invalid argument type %v", args[0].t)
+	}
+	expr = expression{t: typeVec3, eval: func(f *frame) (result value) {
+		a := args[0].eval(f)
+		b := args[1].eval(f)
+		result[0] = a[1]*b[2] - a[2]*b[1]
+		result[1] = a[2]*b[0] - a[0]*b[2]
+		result[2] = a[0]*b[1] - a[1]*b[0]
+		return
+	}}
+	return
+}
+
+func textureLookup(args []expression) (expr expression, err error) {
+	if (len(args) != 2) || (args[0].t != typeSampler2D) || (args[1].t != typeVec2) {
+		return expr, fmt.Errorf("expected arguments (sampler2D, vec2)")
+	}
+	expr = expression{t: typeVec4, eval: func(f *frame) (result value) {
+		unit := args[0].eval(f)
+		coord := args[1].eval(f)
+		color := f.inv.sampler(int(unit[0]), coord[0], coord[1])
+		copy(result[:4], color[:])
+		return
+	}}
+	return
+}
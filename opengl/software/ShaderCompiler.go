@@ -0,0 +1,1291 @@
+package software
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// flow describes how execution continues after a statement.
+type flow int
+
+const (
+	flowNext flow = iota
+	flowReturn
+	flowBreak
+	flowContinue
+	flowDiscard
+)
+
+// textureSampler returns the color of the texture bound to given unit at the given coordinates.
+type textureSampler func(unit int, s, t float32) [4]float32
+
+// invocation is the state shared among all function calls of one shader run.
+type invocation struct {
+	sampler   textureSampler
+	discarded bool
+}
+
+// frame holds the variables of one function call.
+type frame struct {
+	globals []value
+	locals  []value
+	result  value
+	inv     *invocation
+}
+
+type statement func(*frame) flow
+
+type expression struct {
+	t     glslType
+	eval  func(*frame) value
+	store func(*frame, value)
+}
+
+type variable struct {
+	name     string
+	t        glslType
+	global   bool
+	slot     int
+	readOnly bool
+}
+
+type function struct {
+	name       string
+	returnType glslType
+	paramTypes []glslType
+	localCount int
+	body       statement
+}
+
+// globalVariable describes a variable on shader level, such as inputs, outputs and uniforms.
+type globalVariable struct {
+	name      string
+	t         glslType
+	qualifier string
+	slot      int
+}
+
+// compiledShader is the executable form of one shader source.
+type compiledShader struct {
+	globals     []*globalVariable
+	globalCount int
+	initializer []statement
+	main        *function
+}
+
+// run executes the main function of the shader with the given global variables.
+// It returns false if the invocation was discarded.
+func (shader *compiledShader) run(globals []value, inv *invocation) bool {
+	inv.discarded = false
+	global := &frame{globals: globals, inv: inv}
+	for _, init := range shader.initializer {
+		init(global)
+	}
+	mainFrame := &frame{globals: globals, locals: make([]value, shader.main.localCount), inv: inv}
+	shader.main.body(mainFrame)
+	return !inv.discarded
+}
+
+// global returns the global variable with given name and qualifier, or nil if not existing.
+func (shader *compiledShader) global(name string) *globalVariable {
+	for _, entry := range shader.globals {
+		if entry.name == name {
+			return entry
+		}
+	}
+	return nil
+}
+
+type compileError struct {
+	message string
+}
+
+type shaderCompiler struct {
+	tokens []token
+	pos    int
+
+	shader    *compiledShader
+	globals   map[string]*variable
+	functions map[string][]*function
+	scopes    []map[string]*variable
+	current   *function
+}
+
+// compileShader parses the given source and returns an executable shader.
+// The builtIns list specifies the predefined global variables of the stage.
+func compileShader(source string, builtIns []*globalVariable) (shader *compiledShader, err error) {
+	tokens, tokenErr := tokenize(source)
+	if tokenErr != nil {
+		return nil, tokenErr
+	}
+	compiler := &shaderCompiler{
+		tokens:    tokens,
+		shader:    &compiledShader{},
+		globals:   make(map[string]*variable),
+		functions: make(map[string][]*function)}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			compileErr, isCompileError := recovered.(compileError)
+			if !isCompileError {
+				panic(recovered)
+			}
+			shader = nil
+			err = fmt.Errorf("%s", compileErr.message)
+		}
+	}()
+	for _, builtIn := range builtIns {
+		compiler.declareGlobal(builtIn.name, builtIn.t, builtIn.qualifier)
+	}
+	compiler.translationUnit()
+	shader = compiler.shader
+
+	return
+}
+
+func (compiler *shaderCompiler) fail(format string, args ...interface{}) {
+	line := compiler.peek().line
+	panic(compileError{fmt.Sprintf("line %d: %s", line, fmt.Sprintf(format, args...))})
+}
+
+func (compiler *shaderCompiler) peek() token {
+	return compiler.tokens[compiler.pos]
+}
+
+func (compiler *shaderCompiler) peekAt(offset int) token {
+	index := compiler.pos + offset
+	if index >= len(compiler.tokens) {
+		index = len(compiler.tokens) - 1
+	}
+	return compiler.tokens[index]
+}
+
+func (compiler *shaderCompiler) next() token {
+	tok := compiler.tokens[compiler.pos]
+	if tok.kind != tokenEnd {
+		compiler.pos++
+	}
+	return tok
+}
+
+func (compiler *shaderCompiler) isSymbol(text string) bool {
+	tok := compiler.peek()
+	return (tok.kind == tokenSymbol) && (tok.text == text)
+}
+
+func (compiler *shaderCompiler) isKeyword(text string) bool {
+	tok := compiler.peek()
+	return (tok.kind == tokenIdentifier) && (tok.text == text)
+}
+
+func (compiler *shaderCompiler) acceptSymbol(text string) bool {
+	accepted := compiler.isSymbol(text)
+	if accepted {
+		compiler.next()
+	}
+	return accepted
+}
+
+func (compiler *shaderCompiler) expectSymbol(text string) {
+	if !compiler.acceptSymbol(text) {
+		compiler.fail("expected '%s' but found %v", text, compiler.peek())
+	}
+}
+
+func (compiler *shaderCompiler) expectIdentifier() string {
+	tok := compiler.next()
+	if tok.kind != tokenIdentifier {
+		compiler.fail("expected identifier but found %v", tok)
+	}
+	return tok.text
+}
+
+func (compiler *shaderCompiler) isTypeName(tok token) bool {
+	_, known := typesByName[tok.text]
+	return (tok.kind == tokenIdentifier) && known
+}
+
+func (compiler *shaderCompiler) expectType() glslType {
+	tok := compiler.next()
+	t, known := typesByName[tok.text]
+	if (tok.kind != tokenIdentifier) || !known {
+		compiler.fail("expected type but found %v", tok)
+	}
+	return t
+}
+
+func isPrecisionQualifier(text string) bool {
+	return (text == "lowp") || (text == "mediump") || (text == "highp")
+}
+
+func isInterpolationQualifier(text string) bool {
+	return (text == "smooth") || (text == "flat") || (text == "noperspective")
+}
+
+func (compiler *shaderCompiler) translationUnit() {
+	for compiler.peek().kind != tokenEnd {
+		if compiler.acceptSymbol(";") {
+			continue
+		}
+		if compiler.isKeyword("precision") {
+			for !compiler.acceptSymbol(";") {
+				if compiler.next().kind == tokenEnd {
+					compiler.fail("unterminated precision statement")
+				}
+			}
+			continue
+		}
+		qualifier := ""
+		for {
+			text := compiler.peek().text
+			if (text == "in") || (text == "out") || (text == "uniform") || (text == "const") || (text == "attribute") || (text == "varying") {
+				qualifier = text
+				compiler.next()
+			} else if isPrecisionQualifier(text) || isInterpolationQualifier(text) {
+				compiler.next()
+			} else {
+				break
+			}
+		}
+		t := compiler.expectType()
+		name := compiler.expectIdentifier()
+		if (qualifier == "") && compiler.isSymbol("(") {
+			compiler.functionDefinition(t, name)
+		} else {
+			compiler.globalDeclaration(qualifier, t, name)
+		}
+	}
+	if compiler.shader.main == nil || compiler.shader.main.body == nil {
+		compiler.fail("missing main function")
+	}
+}
+
+func (compiler *shaderCompiler) declareGlobal(name string, t glslType, qualifier string) *variable {
+	if _, existing := compiler.globals[name]; existing {
+		compiler.fail("redefinition of '%s'", name)
+	}
+	slot := compiler.shader.globalCount
+	compiler.shader.globalCount++
+	compiler.shader.globals = append(compiler.shader.globals, &globalVariable{name: name, t: t, qualifier: qualifier, slot: slot})
+	entry := &variable{
+		name:     name,
+		t:        t,
+		global:   true,
+		slot:     slot,
+		readOnly: (qualifier == "in") || (qualifier == "uniform") || (qualifier == "const") || (qualifier == "attribute")}
+	compiler.globals[name] = entry
+	return entry
+}
+
+func (compiler *shaderCompiler) globalDeclaration(qualifier string, t glslType, name string) {
+	if qualifier == "attribute" {
+		qualifier = "in"
+	}
+	for {
+		if t == typeVoid {
+			compiler.fail("variable '%s' declared void", name)
+		}
+		entry := compiler.declareGlobal(name, t, qualifier)
+		if compiler.acceptSymbol("=") {
+			if (qualifier == "in") || (qualifier == "uniform") {
+				compiler.fail("'%s' variables can not be initialized", qualifier)
+			}
+			init := compiler.convertTo(compiler.assignment(), t)
+			slot := entry.slot
+			compiler.shader.initializer = append(compiler.shader.initializer, func(f *frame) flow {
+				f.globals[slot] = init.eval(f)
+				return flowNext
+			})
+		} else if qualifier == "const" {
+			compiler.fail("const variable '%s' requires an initializer", name)
+		}
+		if !compiler.acceptSymbol(",") {
+			break
+		}
+		name = compiler.expectIdentifier()
+	}
+	compiler.expectSymbol(";")
+}
+
+func (compiler *shaderCompiler) findFunction(name string, paramTypes []glslType) *function {
+	for _, candidate := range compiler.functions[name] {
+		if len(candidate.paramTypes) == len(paramTypes) {
+			matching := true
+			for index, paramType := range paramTypes {
+				matching = matching && (candidate.paramTypes[index] == paramType)
+			}
+			if matching {
+				return candidate
+			}
+		}
+	}
+	return nil
+}
+
+func (compiler *shaderCompiler) functionDefinition(returnType glslType, name string) {
+	compiler.expectSymbol("(")
+	var paramNames []string
+	var paramTypes []glslType
+	if compiler.isKeyword("void") && (compiler.peekAt(1).text == ")") {
+		compiler.next()
+	}
+	for !compiler.acceptSymbol(")") {
+		if len(paramTypes) > 0 {
+			compiler.expectSymbol(",")
+		}
+		for compiler.isKeyword("in") || compiler.isKeyword("const") || isPrecisionQualifier(compiler.peek().text) {
+			compiler.next()
+		}
+		if compiler.isKeyword("out") || compiler.isKeyword("inout") {
+			compiler.fail("output parameters are not supported")
+		}
+		paramTypes = append(paramTypes, compiler.expectType())
+		paramName := ""
+		if compiler.peek().kind == tokenIdentifier {
+			paramName = compiler.expectIdentifier()
+		}
+		paramNames = append(paramNames, paramName)
+	}
+
+	fn := compiler.findFunction(name, paramTypes)
+	if fn == nil {
+		fn = &function{name: name, returnType: returnType, paramTypes: paramTypes}
+		compiler.functions[name] = append(compiler.functions[name], fn)
+	} else if fn.returnType != returnType {
+		compiler.fail("conflicting return type for function '%s'", name)
+	}
+	if compiler.acceptSymbol(";") {
+		return
+	}
+	if fn.body != nil {
+		compiler.fail("redefinition of function '%s'", name)
+	}
+
+	compiler.current = fn
+	fn.localCount = 0
+	compiler.pushScope()
+	for index, paramName := range paramNames {
+		compiler.declareLocal(paramName, paramTypes[index], false)
+	}
+	compiler.expectSymbol("{")
+	body := compiler.blockBody()
+	compiler.popScope()
+	compiler.current = nil
+
+	fn.body = func(f *frame) flow {
+		return body(f)
+	}
+	if name == "main" {
+		if (returnType != typeVoid) || (len(paramTypes) != 0) {
+			compiler.fail("main function must be 'void main()'")
+		}
+		compiler.shader.main = fn
+	}
+}
+
+func (compiler *shaderCompiler) pushScope() {
+	compiler.scopes = append(compiler.scopes, make(map[string]*variable))
+}
+
+func (compiler *shaderCompiler) popScope() {
+	compiler.scopes = compiler.scopes[:len(compiler.scopes)-1]
+}
+
+func (compiler *shaderCompiler) declareLocal(name string, t glslType, readOnly bool) *variable {
+	scope := compiler.scopes[len(compiler.scopes)-1]
+	if _, existing := scope[name]; existing {
+		compiler.fail("redefinition of '%s'", name)
+	}
+	entry := &variable{name: name, t: t, slot: compiler.current.localCount, readOnly: readOnly}
+	compiler.current.localCount++
+	if name != "" {
+		scope[name] = entry
+	}
+	return entry
+}
+
+func (compiler *shaderCompiler) lookup(name string) *variable {
+	for index := len(compiler.scopes) - 1; index >= 0; index-- {
+		if entry, existing := compiler.scopes[index][name]; existing {
+			return entry
+		}
+	}
+	return compiler.globals[name]
+}
+
+func sequence(statements []statement) statement {
+	return func(f *frame) flow {
+		for _, stmt := range statements {
+			if result := stmt(f); result != flowNext {
+				return result
+			}
+			if f.inv.discarded {
+				return flowDiscard
+			}
+		}
+		return flowNext
+	}
+}
+
+// blockBody parses statements until the closing brace.
+func (compiler *shaderCompiler) blockBody() statement {
+	var statements []statement
+	for !compiler.acceptSymbol("}") {
+		if compiler.peek().kind == tokenEnd {
+			compiler.fail("unexpected end of source in block")
+		}
+		statements = append(statements, compiler.statement())
+	}
+	return sequence(statements)
+}
+
+func (compiler *shaderCompiler) isDeclarationStart() bool {
+	offset := 0
+	for {
+		text := compiler.peekAt(offset).text
+		if (text == "const") || isPrecisionQualifier(text) {
+			offset++
+		} else {
+			break
+		}
+	}
+	return compiler.isTypeName(compiler.peekAt(offset)) && (compiler.peekAt(offset+1).kind == tokenIdentifier)
+}
+
+func (compiler *shaderCompiler) statement() statement {
+	switch {
+	case compiler.acceptSymbol(";"):
+		return func(*frame) flow { return flowNext }
+	case compiler.acceptSymbol("{"):
+		compiler.pushScope()
+		body := compiler.blockBody()
+		compiler.popScope()
+		return body
+	case compiler.isKeyword("if"):
+		return compiler.ifStatement()
+	case compiler.isKeyword("for"):
+		return compiler.forStatement()
+	case compiler.isKeyword("while"):
+		return compiler.whileStatement()
+	case compiler.isKeyword("return"):
+		return compiler.returnStatement()
+	case compiler.isKeyword("discard"):
+		compiler.next()
+		compiler.expectSymbol(";")
+		return func(f *frame) flow {
+			f.inv.discarded = true
+			return flowDiscard
+		}
+	case compiler.isKeyword("break"):
+		compiler.next()
+		compiler.expectSymbol(";")
+		return func(*frame) flow { return flowBreak }
+	case compiler.isKeyword("continue"):
+		compiler.next()
+		compiler.expectSymbol(";")
+		return func(*frame) flow { return flowContinue }
+	case compiler.isDeclarationStart():
+		return compiler.localDeclaration()
+	}
+	expr := compiler.expression()
+	compiler.expectSymbol(";")
+	return func(f *frame) flow {
+		expr.eval(f)
+		return flowNext
+	}
+}
+
+func (compiler *shaderCompiler) localDeclaration() statement {
+	readOnly := false
+	for compiler.isKeyword("const") || isPrecisionQualifier(compiler.peek().text) {
+		readOnly = readOnly || compiler.isKeyword("const")
+		compiler.next()
+	}
+	t := compiler.expectType()
+	if t == typeVoid {
+		compiler.fail("variables can not be declared void")
+	}
+	var statements []statement
+	for {
+		name := compiler.expectIdentifier()
+		var init *expression
+		if compiler.acceptSymbol("=") {
+			converted := compiler.convertTo(compiler.assignment(), t)
+			init = &converted
+		} else if readOnly {
+			compiler.fail("const variable '%s' requires an initializer", name)
+		}
+		entry := compiler.declareLocal(name, t, readOnly)
+		slot := entry.slot
+		if init != nil {
+			initEval := init.eval
+			statements = append(statements, func(f *frame) flow {
+				f.locals[slot] = initEval(f)
+				return flowNext
+			})
+		} else {
+			statements = append(statements, func(f *frame) flow {
+				f.locals[slot] = value{}
+				return flowNext
+			})
+		}
+		if !compiler.acceptSymbol(",") {
+			break
+		}
+	}
+	compiler.expectSymbol(";")
+	return sequence(statements)
+}
+
+func (compiler *shaderCompiler) condition() expression {
+	compiler.expectSymbol("(")
+	cond := compiler.expression()
+	compiler.expectSymbol(")")
+	if cond.t != typeBool {
+		compiler.fail("condition must be bool, is %v", cond.t)
+	}
+	return cond
+}
+
+func (compiler *shaderCompiler) scopedStatement() statement {
+	compiler.pushScope()
+	stmt := compiler.statement()
+	compiler.popScope()
+	return stmt
+}
+
+func (compiler *shaderCompiler) ifStatement() statement {
+	compiler.next()
+	cond := compiler.condition()
+	thenBranch := compiler.scopedStatement()
+	elseBranch := func(*frame) flow { return flowNext }
+	if compiler.isKeyword("else") {
+		compiler.next()
+		elseBranch = compiler.scopedStatement()
+	}
+	return func(f *frame) flow {
+		result := cond.eval(f)
+		if result.isTrue() {
+			return thenBranch(f)
+		}
+		return elseBranch(f)
+	}
+}
+
+func loop(cond *expression, step *expression, body statement) statement {
+	return func(f *frame) flow {
+		for {
+			if cond != nil {
+				result := cond.eval(f)
+				if !result.isTrue() {
+					return flowNext
+				}
+			}
+			switch body(f) {
+			case flowBreak:
+				return flowNext
+			case flowReturn:
+				return flowReturn
+			case flowDiscard:
+				return flowDiscard
+			}
+			if step != nil {
+				step.eval(f)
+			}
+		}
+	}
+}
+
+func (compiler *shaderCompiler) forStatement() statement {
+	compiler.next()
+	compiler.expectSymbol("(")
+	compiler.pushScope()
+	defer compiler.popScope()
+
+	init := compiler.statement()
+	var cond *expression
+	if !compiler.isSymbol(";") {
+		parsed := compiler.expression()
+		if parsed.t != typeBool {
+			compiler.fail("condition must be bool, is %v", parsed.t)
+		}
+		cond = &parsed
+	}
+	compiler.expectSymbol(";")
+	var step *expression
+	if !compiler.isSymbol(")") {
+		parsed := compiler.expression()
+		step = &parsed
+	}
+	compiler.expectSymbol(")")
+	body := compiler.scopedStatement()
+	looped := loop(cond, step, body)
+
+	return func(f *frame) flow {
+		init(f)
+		return looped(f)
+	}
+}
+
+func (compiler *shaderCompiler) whileStatement() statement {
+	compiler.next()
+	cond := compiler.condition()
+	body := compiler.scopedStatement()
+	return loop(&cond, nil, body)
+}
+
+func (compiler *shaderCompiler) returnStatement() statement {
+	compiler.next()
+	fn := compiler.current
+	if compiler.acceptSymbol(";") {
+		if fn.returnType != typeVoid {
+			compiler.fail("function '%s' must return a value", fn.name)
+		}
+		return func(*frame) flow { return flowReturn }
+	}
+	if fn.returnType == typeVoid {
+		compiler.fail("void function '%s' can not return a value", fn.name)
+	}
+	result := compiler.convertTo(compiler.expression(), fn.returnType)
+	compiler.expectSymbol(";")
+	return func(f *frame) flow {
+		f.result = result.eval(f)
+		return flowReturn
+	}
+}
+
+// convertTo verifies the expression is of given type.
+func (compiler *shaderCompiler) convertTo(expr expression, t glslType) expression {
+	if expr.t != t {
+		compiler.fail("can not convert from %v to %v", expr.t, t)
+	}
+	return expr
+}
+
+func (compiler *shaderCompiler) expression() expression {
+	expr := compiler.assignment()
+	for compiler.acceptSymbol(",") {
+		first := expr
+		second := compiler.assignment()
+		expr = expression{t: second.t, eval: func(f *frame) value {
+			first.eval(f)
+			return second.eval(f)
+		}}
+	}
+	return expr
+}
+
+var compoundOperators = map[string]string{"+=": "+", "-=": "-", "*=": "*", "/=": "/"}
+
+func (compiler *shaderCompiler) assignment() expression {
+	target := compiler.ternary()
+	tok := compiler.peek()
+	if tok.kind != tokenSymbol {
+		return target
+	}
+	if tok.text == "=" {
+		compiler.next()
+		compiler.requireLValue(target)
+		source := compiler.convertTo(compiler.assignment(), target.t)
+		return expression{t: target.t, eval: func(f *frame) value {
+			result := source.eval(f)
+			target.store(f, result)
+			return result
+		}}
+	}
+	if op, isCompound := compoundOperators[tok.text]; isCompound {
+		compiler.next()
+		compiler.requireLValue(target)
+		source := compiler.assignment()
+		combined := compiler.convertTo(compiler.arithmetic(op, target, source), target.t)
+		return expression{t: target.t, eval: func(f *frame) value {
+			result := combined.eval(f)
+			target.store(f, result)
+			return result
+		}}
+	}
+	return target
+}
+
+func (compiler *shaderCompiler) requireLValue(expr expression) {
+	if expr.store == nil {
+		compiler.fail("assignment to non-writable expression")
+	}
+}
+
+func (compiler *shaderCompiler) ternary() expression {
+	cond := compiler.logicalOr()
+	if !compiler.acceptSymbol("?") {
+		return cond
+	}
+	if cond.t != typeBool {
+		compiler.fail("condition must be bool, is %v", cond.t)
+	}
+	whenTrue := compiler.expression()
+	compiler.expectSymbol(":")
+	whenFalse := compiler.convertTo(compiler.assignment(), whenTrue.t)
+	return expression{t: whenTrue.t, eval: func(f *frame) value {
+		result := cond.eval(f)
+		if result.isTrue() {
+			return whenTrue.eval(f)
+		}
+		return whenFalse.eval(f)
+	}}
+}
+
+func (compiler *shaderCompiler) logicalOr() expression {
+	left := compiler.logicalAnd()
+	for compiler.acceptSymbol("||") {
+		first := compiler.convertTo(left, typeBool)
+		second := compiler.convertTo(compiler.logicalAnd(), typeBool)
+		left = expression{t: typeBool, eval: func(f *frame) value {
+			result := first.eval(f)
+			if result.isTrue() {
+				return result
+			}
+			result = second.eval(f)
+			return boolValue(result.isTrue())
+		}}
+	}
+	return left
+}
+
+func (compiler *shaderCompiler) logicalAnd() expression {
+	left := compiler.equality()
+	for compiler.acceptSymbol("&&") {
+		first := compiler.convertTo(left, typeBool)
+		second := compiler.convertTo(compiler.equality(), typeBool)
+		left = expression{t: typeBool, eval: func(f *frame) value {
+			result := first.eval(f)
+			if !result.isTrue() {
+				return result
+			}
+			result = second.eval(f)
+			return boolValue(result.isTrue())
+		}}
+	}
+	return left
+}
+
+func (compiler *shaderCompiler) equality() expression {
+	left := compiler.relational()
+	for compiler.isSymbol("==") || compiler.isSymbol("!=") {
+		negate := compiler.next().text == "!="
+		first := left
+		second := compiler.convertTo(compiler.relational(), first.t)
+		count := first.t.components()
+		left = expression{t: typeBool, eval: func(f *frame) value {
+			a := first.eval(f)
+			b := second.eval(f)
+			equal := true
+			for index := 0; index < count; index++ {
+				equal = equal && (a[index] == b[index])
+			}
+			return boolValue(equal != negate)
+		}}
+	}
+	return left
+}
+
+func (compiler *shaderCompiler) relational() expression {
+	left := compiler.additive()
+	for compiler.isSymbol("<") || compiler.isSymbol(">") || compiler.isSymbol("<=") || compiler.isSymbol(">=") {
+		op := compiler.next().text
+		first := compiler.convertTo(left, typeFloat)
+		second := compiler.convertTo(compiler.additive(), typeFloat)
+		var compare func(a, b float32) bool
+		switch op {
+		case "<":
+			compare = func(a, b float32) bool { return a < b }
+		case ">":
+			compare = func(a, b float32) bool { return a > b }
+		case "<=":
+			compare = func(a, b float32) bool { return a <= b }
+		default:
+			compare = func(a, b float32) bool { return a >= b }
+		}
+		left = expression{t: typeBool, eval: func(f *frame) value {
+			a := first.eval(f)
+			b := second.eval(f)
+			return boolValue(compare(a[0], b[0]))
+		}}
+	}
+	return left
+}
+
+func (compiler *shaderCompiler) additive() expression {
+	left := compiler.multiplicative()
+	for compiler.isSymbol("+") || compiler.isSymbol("-") {
+		op := compiler.next().text
+		left = compiler.arithmetic(op, left, compiler.multiplicative())
+	}
+	return left
+}
+
+func (compiler *shaderCompiler) multiplicative() expression {
+	left := compiler.unary()
+	for compiler.isSymbol("*") || compiler.isSymbol("/") {
+		op := compiler.next().text
+		left = compiler.arithmetic(op, left, compiler.unary())
+	}
+	return left
+}
+
+func arithmeticOperation(op string) func(a, b float32) float32 {
+	switch op {
+	case "+":
+		return func(a, b float32) float32 { return a + b }
+	case "-":
+		return func(a, b float32) float32 { return a - b }
+	case "*":
+		return func(a, b float32) float32 { return a * b }
+	}
+	return func(a, b float32) float32 { return a / b }
+}
+
+// arithmetic creates a binary arithmetic expression, including linear algebraic products.
+func (compiler *shaderCompiler) arithmetic(op string, left, right expression) expression {
+	if !left.t.isNumeric() || !right.t.isNumeric() {
+		compiler.fail("operator '%s' not defined for %v and %v", op, left.t, right.t)
+	}
+	if op == "*" {
+		switch {
+		case (left.t == typeMat4) && (right.t == typeVec4):
+			return expression{t: typeVec4, eval: func(f *frame) value {
+				m := left.eval(f)
+				v := right.eval(f)
+				return matrixTimesVector(&m, &v)
+			}}
+		case (left.t == typeVec4) && (right.t == typeMat4):
+			return expression{t: typeVec4, eval: func(f *frame) value {
+				v := left.eval(f)
+				m := right.eval(f)
+				return vectorTimesMatrix(&v, &m)
+			}}
+		case (left.t == typeMat4) && (right.t == typeMat4):
+			return expression{t: typeMat4, eval: func(f *frame) value {
+				a := left.eval(f)
+				b := right.eval(f)
+				return matrixTimesMatrix(&a, &b)
+			}}
+		}
+	}
+
+	operation := arithmeticOperation(op)
+	switch {
+	case left.t == right.t:
+		count := left.t.components()
+		return expression{t: left.t, eval: func(f *frame) (result value) {
+			a := left.eval(f)
+			b := right.eval(f)
+			for index := 0; index < count; index++ {
+				result[index] = operation(a[index], b[index])
+			}
+			return
+		}}
+	case left.t == typeFloat:
+		count := right.t.components()
+		return expression{t: right.t, eval: func(f *frame) (result value) {
+			a := left.eval(f)
+			b := right.eval(f)
+			for index := 0; index < count; index++ {
+				result[index] = operation(a[0], b[index])
+			}
+			return
+		}}
+	case right.t == typeFloat:
+		count := left.t.components()
+		return expression{t: left.t, eval: func(f *frame) (result value) {
+			a := left.eval(f)
+			b := right.eval(f)
+			for index := 0; index < count; index++ {
+				result[index] = operation(a[index], b[0])
+			}
+			return
+		}}
+	}
+	compiler.fail("operator '%s' not defined for %v and %v", op, left.t, right.t)
+	return expression{}
+}
+
+func matrixTimesVector(m, v *value) (result value) {
+	for row := 0; row < 4; row++ {
+		sum := float32(0.0)
+		for column := 0; column < 4; column++ {
+			sum += m[column*4+row] * v[column]
+		}
+		result[row] = sum
+	}
+	return
+}
+
+func vectorTimesMatrix(v, m *value) (result value) {
+	for column := 0; column < 4; column++ {
+		sum := float32(0.0)
+		for row := 0; row < 4; row++ {
+			sum += v[row] * m[column*4+row]
+		}
+		result[column] = sum
+	}
+	return
+}
+
+func matrixTimesMatrix(a, b *value) (result value) {
+	for column := 0; column < 4; column++ {
+		for row := 0; row < 4; row++ {
+			sum := float32(0.0)
+			for inner := 0; inner < 4; inner++ {
+				sum += a[inner*4+row] * b[column*4+inner]
+			}
+			result[column*4+row] = sum
+		}
+	}
+	return
+}
+
+func (compiler *shaderCompiler) unary() expression {
+	switch {
+	case compiler.acceptSymbol("+"):
+		operand := compiler.unary()
+		if !operand.t.isNumeric() {
+			compiler.fail("operator '+' not defined for %v", operand.t)
+		}
+		return operand
+	case compiler.acceptSymbol("-"):
+		operand := compiler.unary()
+		if !operand.t.isNumeric() {
+			compiler.fail("operator '-' not defined for %v", operand.t)
+		}
+		count := operand.t.components()
+		return expression{t: operand.t, eval: func(f *frame) (result value) {
+			a := operand.eval(f)
+			for index := 0; index < count; index++ {
+				result[index] = -a[index]
+			}
+			return
+		}}
+	case compiler.acceptSymbol("!"):
+		operand := compiler.convertTo(compiler.unary(), typeBool)
+		return expression{t: typeBool, eval: func(f *frame) value {
+			a := operand.eval(f)
+			return boolValue(!a.isTrue())
+		}}
+	case compiler.isSymbol("++") || compiler.isSymbol("--"):
+		op := compiler.next().text
+		operand := compiler.unary()
+		return compiler.increment(operand, op, true)
+	}
+	return compiler.postfix()
+}
+
+func (compiler *shaderCompiler) increment(operand expression, op string, prefix bool) expression {
+	compiler.requireLValue(operand)
+	if !operand.t.isNumeric() {
+		compiler.fail("operator '%s' not defined for %v", op, operand.t)
+	}
+	delta := float32(1.0)
+	if op == "--" {
+		delta = -1.0
+	}
+	count := operand.t.components()
+	return expression{t: operand.t, eval: func(f *frame) value {
+		old := operand.eval(f)
+		updated := old
+		for index := 0; index < count; index++ {
+			updated[index] += delta
+		}
+		operand.store(f, updated)
+		if prefix {
+			return updated
+		}
+		return old
+	}}
+}
+
+func (compiler *shaderCompiler) postfix() expression {
+	expr := compiler.primary()
+	for {
+		switch {
+		case compiler.acceptSymbol("."):
+			expr = compiler.swizzle(expr, compiler.expectIdentifier())
+		case compiler.acceptSymbol("["):
+			index := compiler.convertTo(compiler.expression(), typeFloat)
+			compiler.expectSymbol("]")
+			expr = compiler.indexed(expr, index)
+		case compiler.isSymbol("++") || compiler.isSymbol("--"):
+			op := compiler.next().text
+			expr = compiler.increment(expr, op, false)
+		default:
+			return expr
+		}
+	}
+}
+
+func swizzleIndex(char byte) (index int, set int) {
+	sets := []string{"xyzw", "rgba", "stpq"}
+	for setIndex, chars := range sets {
+		for charIndex := 0; charIndex < len(chars); charIndex++ {
+			if chars[charIndex] == char {
+				return charIndex, setIndex
+			}
+		}
+	}
+	return -1, -1
+}
+
+func (compiler *shaderCompiler) swizzle(base expression, selection string) expression {
+	if !base.t.isGeneric() {
+		compiler.fail("can not select fields of %v", base.t)
+	}
+	if (len(selection) < 1) || (len(selection) > 4) {
+		compiler.fail("invalid swizzle '%s'", selection)
+	}
+	indices := make([]int, len(selection))
+	usedSet := -1
+	unique := true
+	used := make(map[int]bool)
+	for position := 0; position < len(selection); position++ {
+		index, set := swizzleIndex(selection[position])
+		if (index < 0) || (index >= base.t.components()) || ((usedSet >= 0) && (set != usedSet)) {
+			compiler.fail("invalid swizzle '%s' for %v", selection, base.t)
+		}
+		usedSet = set
+		unique = unique && !used[index]
+		used[index] = true
+		indices[position] = index
+	}
+	t, _ := vectorType(len(indices))
+	expr := expression{t: t, eval: func(f *frame) (result value) {
+		source := base.eval(f)
+		for position, index := range indices {
+			result[position] = source[index]
+		}
+		return
+	}}
+	if (base.store != nil) && unique {
+		expr.store = func(f *frame, val value) {
+			target := base.eval(f)
+			for position, index := range indices {
+				target[index] = val[position]
+			}
+			base.store(f, target)
+		}
+	}
+	return expr
+}
+
+func (compiler *shaderCompiler) indexed(base expression, index expression) expression {
+	var t glslType
+	var stride, limit int
+	switch {
+	case base.t.isVector():
+		t, stride, limit = typeFloat, 1, base.t.components()
+	case base.t == typeMat4:
+		t, stride, limit = typeVec4, 4, 4
+	default:
+		compiler.fail("can not index %v", base.t)
+	}
+	offsetOf := func(f *frame) int {
+		raw := index.eval(f)
+		position := int(raw[0])
+		if position < 0 {
+			position = 0
+		} else if position >= limit {
+			position = limit - 1
+		}
+		return position * stride
+	}
+	expr := expression{t: t, eval: func(f *frame) (result value) {
+		source := base.eval(f)
+		offset := offsetOf(f)
+		copy(result[:stride], source[offset:offset+stride])
+		return
+	}}
+	if base.store != nil {
+		expr.store = func(f *frame, val value) {
+			target := base.eval(f)
+			offset := offsetOf(f)
+			copy(target[offset:offset+stride], val[:stride])
+			base.store(f, target)
+		}
+	}
+	return expr
+}
+
+func (compiler *shaderCompiler) primary() expression {
+	tok := compiler.peek()
+	switch {
+	case tok.kind == tokenNumber:
+		compiler.next()
+		text := tok.text
+		for (len(text) > 0) && ((text[len(text)-1] == 'f') || (text[len(text)-1] == 'F') ||
+			(text[len(text)-1] == 'u') || (text[len(text)-1] == 'U')) {
+			text = text[:len(text)-1]
+		}
+		parsed, err := strconv.ParseFloat(text, 32)
+		if err != nil {
+			compiler.fail("invalid number %v", tok)
+		}
+		var constant value
+		constant[0] = float32(parsed)
+		return expression{t: typeFloat, eval: func(*frame) value { return constant }}
+	case compiler.acceptSymbol("("):
+		expr := compiler.expression()
+		compiler.expectSymbol(")")
+		return expr
+	case (tok.text == "true") || (tok.text == "false"):
+		compiler.next()
+		constant := boolValue(tok.text == "true")
+		return expression{t: typeBool, eval: func(*frame) value { return constant }}
+	case tok.kind == tokenIdentifier:
+		compiler.next()
+		if compiler.isSymbol("(") {
+			return compiler.call(tok.text)
+		}
+		return compiler.variableReference(tok.text)
+	}
+	compiler.fail("unexpected %v", tok)
+	return expression{}
+}
+
+func (compiler *shaderCompiler) variableReference(name string) expression {
+	entry := compiler.lookup(name)
+	if entry == nil {
+		compiler.fail("undeclared identifier '%s'", name)
+	}
+	slot := entry.slot
+	var expr expression
+	if entry.global {
+		expr = expression{t: entry.t, eval: func(f *frame) value { return f.globals[slot] }}
+		if !entry.readOnly {
+			expr.store = func(f *frame, val value) { f.globals[slot] = val }
+		}
+	} else {
+		expr = expression{t: entry.t, eval: func(f *frame) value { return f.locals[slot] }}
+		if !entry.readOnly {
+			expr.store = func(f *frame, val value) { f.locals[slot] = val }
+		}
+	}
+	return expr
+}
+
+func (compiler *shaderCompiler) arguments() []expression {
+	var args []expression
+	compiler.expectSymbol("(")
+	if compiler.isKeyword("void") && (compiler.peekAt(1).text == ")") {
+		compiler.next()
+	}
+	for !compiler.acceptSymbol(")") {
+		if len(args) > 0 {
+			compiler.expectSymbol(",")
+		}
+		args = append(args, compiler.assignment())
+	}
+	return args
+}
+
+func (compiler *shaderCompiler) call(name string) expression {
+	args := compiler.arguments()
+	if t, isType := typesByName[name]; isType {
+		return compiler.constructor(name, t, args)
+	}
+	argTypes := make([]glslType, len(args))
+	for index, arg := range args {
+		argTypes[index] = arg.t
+	}
+	if fn := compiler.findFunction(name, argTypes); fn != nil {
+		return compiler.userCall(fn, args)
+	}
+	if builtIn, known := builtInFunctions[name]; known {
+		expr, err := builtIn(args)
+		if err != nil {
+			compiler.fail("%s(): %v", name, err)
+		}
+		return expr
+	}
+	compiler.fail("no matching function '%s' for arguments %v", name, argTypes)
+	return expression{}
+}
+
+func (compiler *shaderCompiler) userCall(fn *function, args []expression) expression {
+	return expression{t: fn.returnType, eval: func(f *frame) value {
+		callee := &frame{globals: f.globals, locals: make([]value, fn.localCount), inv: f.inv}
+		for index, arg := range args {
+			callee.locals[index] = arg.eval(f)
+		}
+		fn.body(callee)
+		return callee.result
+	}}
+}
+
+func (compiler *shaderCompiler) constructor(name string, t glslType, args []expression) expression {
+	if (t == typeVoid) || (t == typeSampler2D) {
+		compiler.fail("can not construct %v", t)
+	}
+	if len(args) == 0 {
+		compiler.fail("constructor %s requires arguments", name)
+	}
+	for _, arg := range args {
+		if (arg.t == typeVoid) || (arg.t == typeSampler2D) {
+			compiler.fail("invalid argument of type %v for constructor %s", arg.t, name)
+		}
+	}
+	size := t.components()
+	if (len(args) == 1) && (args[0].t.components() == 1) {
+		arg := args[0]
+		switch {
+		case t == typeBool:
+			return expression{t: t, eval: func(f *frame) value {
+				a := arg.eval(f)
+				return boolValue(a[0] != 0.0)
+			}}
+		case name == "int":
+			return expression{t: t, eval: func(f *frame) (result value) {
+				a := arg.eval(f)
+				result[0] = float32(int32(a[0]))
+				return
+			}}
+		case t == typeMat4:
+			return expression{t: t, eval: func(f *frame) (result value) {
+				a := arg.eval(f)
+				for index := 0; index < 4; index++ {
+					result[index*5] = a[0]
+				}
+				return
+			}}
+		}
+		return expression{t: t, eval: func(f *frame) (result value) {
+			a := arg.eval(f)
+			for index := 0; index < size; index++ {
+				result[index] = a[0]
+			}
+			return
+		}}
+	}
+
+	available := 0
+	for index, arg := range args {
+		if available >= size {
+			compiler.fail("too many arguments for constructor %s", name)
+		}
+		if (arg.t == typeMat4) && ((t != typeMat4) || (index > 0)) && (len(args) > 1) {
+			compiler.fail("matrix arguments can not be combined for constructor %s", name)
+		}
+		available += arg.t.components()
+	}
+	if available < size {
+		compiler.fail("not enough data for constructor %s", name)
+	}
+	return expression{t: t, eval: func(f *frame) (result value) {
+		position := 0
+		for _, arg := range args {
+			a := arg.eval(f)
+			count := arg.t.components()
+			for index := 0; (index < count) && (position < size); index++ {
+				result[position] = a[index]
+				position++
+			}
+		}
+		return
+	}}
+}
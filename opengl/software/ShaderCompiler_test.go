@@ -0,0 +1,111 @@
+package software
+
+import (
+	check "gopkg.in/check.v1"
+)
+
+type ShaderCompilerSuite struct {
+}
+
+var _ = check.Suite(&ShaderCompilerSuite{})
+
+func (suite *ShaderCompilerSuite) evaluate(c *check.C, source string) value {
+	shader, err := compileShader(source, nil)
+	c.Assert(err, check.IsNil)
+	globals := make([]value, shader.globalCount)
+	inv := &invocation{sampler: func(unit int, s, t float32) [4]float32 { return [4]float32{s, t, float32(unit), 1.0} }}
+	shader.run(globals, inv)
+	return globals[shader.global("result").slot]
+}
+
+func (suite *ShaderCompilerSuite) TestArithmeticFollowsPrecedence(c *check.C) {
+	result := suite.evaluate(c, `out float result; void main() { result = 1.0 + 2.0 * 3.0 - 4.0 / 2.0; }`)
+
+	c.Check(result[0], check.Equals, float32(5.0))
+}
+
+func (suite *ShaderCompilerSuite) TestSwizzlesCanBeReadAndWritten(c *check.C) {
+	result := suite.evaluate(c, `out vec4 result;
+void main() {
+	vec3 base = vec3(1.0, 2.0, 3.0);
+	result = vec4(base.zyx, 4.0);
+	result.xw = vec2(10.0);
+}`)
+
+	c.Check(result[:4], check.DeepEquals, []float32{10.0, 2.0, 1.0, 10.0})
+}
+
+func (suite *ShaderCompilerSuite) TestUserFunctionsCanBeCalled(c *check.C) {
+	result := suite.evaluate(c, `out float result;
+float modulo(float value, float divisor) {
+	return value - divisor * floor(value / divisor);
+}
+void main() {
+	result = modulo(7.0, 3.0);
+}`)
+
+	c.Check(result[0], check.Equals, float32(1.0))
+}
+
+func (suite *ShaderCompilerSuite) TestConditionsSupportLogicalOperators(c *check.C) {
+	result := suite.evaluate(c, `out float result;
+void main() {
+	float a = 2.0;
+	if ((a < 1.0) || !(a >= 2.0)) {
+		result = 1.0;
+	} else if ((a > 1.0) && (a == 2.0)) {
+		result = 2.0;
+	} else {
+		result = 3.0;
+	}
+}`)
+
+	c.Check(result[0], check.Equals, float32(2.0))
+}
+
+func (suite *ShaderCompilerSuite) TestMatrixTimesVectorUsesColumnMajorOrder(c *check.C) {
+	result := suite.evaluate(c, `out vec4 result;
+void main() {
+	mat4 m = mat4(1.0);
+	m[3] = vec4(5.0, 6.0, 7.0, 1.0);
+	result = m * vec4(1.0, 1.0, 1.0, 1.0);
+}`)
+
+	c.Check(result[:4], check.DeepEquals, []float32{6.0, 7.0, 8.0, 1.0})
+}
+
+func (suite *ShaderCompilerSuite) TestGlobalConstantsAreInitialized(c *check.C) {
+	result := suite.evaluate(c, `out vec4 result;
+void main() {
+	vec4 K = vec4(1.0, 2.0 / 4.0, 1.0 / 4.0, 3.0);
+	result = clamp(K.wzyx, 0.0, 1.0);
+}`)
+
+	c.Check(result[:4], check.DeepEquals, []float32{1.0, 0.25, 0.5, 1.0})
+}
+
+func (suite *ShaderCompilerSuite) TestTextureUsesSampler(c *check.C) {
+	result := suite.evaluate(c, `uniform sampler2D palette; out vec4 result;
+void main() {
+	result = texture(palette, vec2(0.25, 0.5));
+}`)
+
+	c.Check(result[:4], check.DeepEquals, []float32{0.25, 0.5, 0.0, 1.0})
+}
+
+func (suite *ShaderCompilerSuite) TestDiscardStopsExecution(c *check.C) {
+	shader, err := compileShader(`out float result; void main() { result = 1.0; discard; result = 2.0; }`, nil)
+	c.Assert(err, check.IsNil)
+	globals := make([]value, shader.globalCount)
+
+	kept := shader.run(globals, &invocation{})
+
+	c.Check(kept, check.Equals, false)
+	c.Check(globals[shader.global("result").slot][0], check.Equals, float32(1.0))
+}
+
+func (suite *ShaderCompilerSuite) TestTypeErrorsAreReported(c *check.C) {
+	_, err := compileShader(`out float result; void main() { result = vec2(1.0); }`, nil)
+
+	c.Check(err, check.ErrorMatches, "line 1: can not convert from vec2 to float")
+}
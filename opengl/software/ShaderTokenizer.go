@@ -0,0 +1,122 @@
+package software
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenIdentifier
+	tokenNumber
+	tokenSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+func (tok token) String() string {
+	if tok.kind == tokenEnd {
+		return "end of source"
+	}
+	return fmt.Sprintf("'%s'", tok.text)
+}
+
+// symbols lists all operators and punctuation, longest first.
+var symbols = []string{
+	"+=", "-=", "*=", "/=", "==", "!=", "<=", ">=", "&&", "||", "++", "--",
+	"+", "-", "*", "/", "=", "<", ">", "!", "(", ")", "{", "}", "[", "]", ",", ";", ".", "?", ":"}
+
+func tokenize(source string) (tokens []token, err error) {
+	line := 1
+	pos := 0
+	length := len(source)
+
+	for (pos < length) && (err == nil) {
+		char := source[pos]
+		switch {
+		case char == '\n':
+			line++
+			pos++
+		case (char == ' ') || (char == '\t') || (char == '\r'):
+			pos++
+		case char == '#':
+			for (pos < length) && (source[pos] != '\n') {
+				pos++
+			}
+		case strings.HasPrefix(source[pos:], "//"):
+			for (pos < length) && (source[pos] != '\n') {
+				pos++
+			}
+		case strings.HasPrefix(source[pos:], "/*"):
+			end := strings.Index(source[pos+2:], "*/")
+			if end < 0 {
+				err = fmt.Errorf("line %d: unterminated comment", line)
+			} else {
+				line += strings.Count(source[pos:pos+2+end], "\n")
+				pos += end + 4
+			}
+		case isIdentifierStart(char):
+			start := pos
+			for (pos < length) && (isIdentifierStart(source[pos]) || isDigit(source[pos])) {
+				pos++
+			}
+			tokens = append(tokens, token{kind: tokenIdentifier, text: source[start:pos], line: line})
+		case isDigit(char) || ((char == '.') && (pos+1 < length) && isDigit(source[pos+1])):
+			start := pos
+			pos = scanNumber(source, pos)
+			tokens = append(tokens, token{kind: tokenNumber, text: source[start:pos], line: line})
+		default:
+			symbol := ""
+			for _, candidate := range symbols {
+				if strings.HasPrefix(source[pos:], candidate) {
+					symbol = candidate
+					break
+				}
+			}
+			if symbol == "" {
+				err = fmt.Errorf("line %d: unexpected character %q", line, rune(char))
+			} else {
+				tokens = append(tokens, token{kind: tokenSymbol, text: symbol, line: line})
+				pos += len(symbol)
+			}
+		}
+	}
+	tokens = append(tokens, token{kind: tokenEnd, line: line})
+
+	return
+}
+
+func scanNumber(source string, pos int) int {
+	length := len(source)
+	for (pos < length) && (isDigit(source[pos]) || (source[pos] == '.')) {
+		pos++
+	}
+	if (pos < length) && ((source[pos] == 'e') || (source[pos] == 'E')) {
+		pos++
+		if (pos < length) && ((source[pos] == '+') || (source[pos] == '-')) {
+			pos++
+		}
+		for (pos < length) && isDigit(source[pos]) {
+			pos++
+		}
+	}
+	if (pos < length) && ((source[pos] == 'f') || (source[pos] == 'F') || (source[pos] == 'u') || (source[pos] == 'U')) {
+		pos++
+	}
+	return pos
+}
+
+func isIdentifierStart(char byte) bool {
+	return (char == '_') || unicode.IsLetter(rune(char))
+}
+
+func isDigit(char byte) bool {
+	return (char >= '0') && (char <= '9')
+}
@@ -0,0 +1,107 @@
+package software
+
+import (
+	"fmt"
+)
+
+// glslType identifies the static type of a shader expression.
+// Integer types are represented by floats; the supported shaders only use
+// them for constants.
+type glslType int
+
+const (
+	typeVoid glslType = iota
+	typeBool
+	typeFloat
+	typeVec2
+	typeVec3
+	typeVec4
+	typeMat4
+	typeSampler2D
+)
+
+var typesByName = map[string]glslType{
+	"void":      typeVoid,
+	"bool":      typeBool,
+	"int":       typeFloat,
+	"float":     typeFloat,
+	"vec2":      typeVec2,
+	"vec3":      typeVec3,
+	"vec4":      typeVec4,
+	"mat4":      typeMat4,
+	"sampler2D": typeSampler2D}
+
+var typeNames = map[glslType]string{
+	typeVoid:      "void",
+	typeBool:      "bool",
+	typeFloat:     "float",
+	typeVec2:      "vec2",
+	typeVec3:      "vec3",
+	typeVec4:      "vec4",
+	typeMat4:      "mat4",
+	typeSampler2D: "sampler2D"}
+
+func (t glslType) String() string {
+	return typeNames[t]
+}
+
+// components returns the number of float values the type occupies.
+func (t glslType) components() int {
+	switch t {
+	case typeVec2:
+		return 2
+	case typeVec3:
+		return 3
+	case typeVec4:
+		return 4
+	case typeMat4:
+		return 16
+	case typeVoid:
+		return 0
+	}
+	return 1
+}
+
+func (t glslType) isNumeric() bool {
+	return (t == typeFloat) || t.isVector() || (t == typeMat4)
+}
+
+func (t glslType) isVector() bool {
+	return (t == typeVec2) || (t == typeVec3) || (t == typeVec4)
+}
+
+// isGeneric returns true for the types the component-wise built-in functions accept.
+func (t glslType) isGeneric() bool {
+	return (t == typeFloat) || t.isVector()
+}
+
+func vectorType(size int) (t glslType, err error) {
+	switch size {
+	case 1:
+		t = typeFloat
+	case 2:
+		t = typeVec2
+	case 3:
+		t = typeVec3
+	case 4:
+		t = typeVec4
+	default:
+		err = fmt.Errorf("no vector type with %d components", size)
+	}
+	return
+}
+
+// value is the runtime representation of any shader value.
+// Matrices are stored in column-major order; booleans are non-zero floats.
+type value [16]float32
+
+func boolValue(flag bool) (result value) {
+	if flag {
+		result[0] = 1.0
+	}
+	return
+}
+
+func (val *value) isTrue() bool {
+	return val[0] != 0.0
+}
@@ -0,0 +1,64 @@
+package software
+
+import (
+	"math"
+
+	"github.com/inkyblackness/shocked-client/opengl"
+)
+
+// texture is a two-dimensional RGBA texture. Only the base level is kept,
+// mipmaps are not necessary as sampling is always done on the base level.
+type texture struct {
+	width  int
+	height int
+	pixels []byte
+
+	wrapS int32
+	wrapT int32
+}
+
+func newTexture() *texture {
+	return &texture{wrapS: opengl.REPEAT, wrapT: opengl.REPEAT}
+}
+
+// wrap maps given coordinate into the range [0, size) according to the wrap mode.
+func wrap(coord float32, size int, mode int32) int {
+	switch mode {
+	case opengl.CLAMP_TO_EDGE:
+		if coord < 0.0 {
+			coord = 0.0
+		} else if coord > 1.0 {
+			coord = 1.0
+		}
+	case opengl.MIRRORED_REPEAT:
+		period := coord - 2.0*float32(math.Floor(float64(coord)/2.0))
+		if period > 1.0 {
+			period = 2.0 - period
+		}
+		coord = period
+	default:
+		coord -= float32(math.Floor(float64(coord)))
+	}
+	index := int(math.Floor(float64(coord * float32(size))))
+	if index >= size {
+		index = size - 1
+	} else if index < 0 {
+		index = 0
+	}
+	return index
+}
+
+// sample returns the color at given texture coordinates, using nearest filtering.
+func (tex *texture) sample(s, t float32) (color [4]float32) {
+	if (tex.width == 0) || (tex.height == 0) || (len(tex.pixels) < tex.width*tex.height*4) {
+		color[3] = 1.0
+		return
+	}
+	x := wrap(s, tex.width, tex.wrapS)
+	y := wrap(t, tex.height, tex.wrapT)
+	offset := (y*tex.width + x) * 4
+	for index := 0; index < 4; index++ {
+		color[index] = float32(tex.pixels[offset+index]) / 255.0
+	}
+	return
+}
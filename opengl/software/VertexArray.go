@@ -0,0 +1,136 @@
+package software
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/inkyblackness/shocked-client/opengl"
+)
+
+const maxVertexAttribs = 16
+
+// buffer is the storage of a buffer object. Data is kept in little endian byte order.
+type buffer struct {
+	data []byte
+}
+
+// vertexAttrib describes where the values of one vertex attribute come from.
+type vertexAttrib struct {
+	enabled    bool
+	buffer     *buffer
+	size       int
+	attribType uint32
+	normalized bool
+	stride     int
+	offset     int
+}
+
+// vertexArray is a vertex array object.
+type vertexArray struct {
+	attribs [maxVertexAttribs]vertexAttrib
+}
+
+func attribTypeSize(attribType uint32) int {
+	switch attribType {
+	case opengl.BYTE, opengl.UNSIGNED_BYTE:
+		return 1
+	case opengl.SHORT, opengl.UNSIGNED_SHORT:
+		return 2
+	case opengl.INT, opengl.UNSIGNED_INT, opengl.FLOAT:
+		return 4
+	}
+	return 0
+}
+
+// fetch reads the value of the attribute for the given vertex.
+// Components not provided by the attribute are filled with the defaults (0, 0, 0, 1).
+func (attrib *vertexAttrib) fetch(vertex int) (result value) {
+	result[3] = 1.0
+	if !attrib.enabled || (attrib.buffer == nil) {
+		return
+	}
+	componentSize := attribTypeSize(attrib.attribType)
+	stride := attrib.stride
+	if stride == 0 {
+		stride = componentSize * attrib.size
+	}
+	start := attrib.offset + vertex*stride
+	data := attrib.buffer.data
+	for index := 0; index < attrib.size; index++ {
+		offset := start + index*componentSize
+		if offset+componentSize > len(data) {
+			return
+		}
+		raw := data[offset : offset+componentSize]
+		var component float32
+		switch attrib.attribType {
+		case opengl.FLOAT:
+			component = math.Float32frombits(binary.LittleEndian.Uint32(raw))
+		case opengl.UNSIGNED_BYTE:
+			component = normalizedInteger(float32(raw[0]), 255.0, attrib.normalized)
+		case opengl.BYTE:
+			component = normalizedInteger(float32(int8(raw[0])), 127.0, attrib.normalized)
+		case opengl.UNSIGNED_SHORT:
+			component = normalizedInteger(float32(binary.LittleEndian.Uint16(raw)), 65535.0, attrib.normalized)
+		case opengl.SHORT:
+			component = normalizedInteger(float32(int16(binary.LittleEndian.Uint16(raw))), 32767.0, attrib.normalized)
+		case opengl.UNSIGNED_INT:
+			component = normalizedInteger(float32(binary.LittleEndian.Uint32(raw)), 4294967295.0, attrib.normalized)
+		case opengl.INT:
+			component = normalizedInteger(float32(int32(binary.LittleEndian.Uint32(raw))), 2147483647.0, attrib.normalized)
+		}
+		result[index] = component
+	}
+	return
+}
+
+func normalizedInteger(raw float32, max float32, normalized bool) float32 {
+	if normalized {
+		return raw / max
+	}
+	return raw
+}
+
+// bufferBytes converts the data given to BufferData into a byte slice.
+// It returns false if the type of data is not supported.
+func bufferBytes(data interface{}) ([]byte, bool) {
+	switch typed := data.(type) {
+	case nil:
+		return nil, true
+	case []byte:
+		result := make([]byte, len(typed))
+		copy(result, typed)
+		return result, true
+	case []float32:
+		result := make([]byte, len(typed)*4)
+		for index, entry := range typed {
+			binary.LittleEndian.PutUint32(result[index*4:], math.Float32bits(entry))
+		}
+		return result, true
+	case []uint16:
+		result := make([]byte, len(typed)*2)
+		for index, entry := range typed {
+			binary.LittleEndian.PutUint16(result[index*2:], entry)
+		}
+		return result, true
+	case []int16:
+		result := make([]byte, len(typed)*2)
+		for index, entry := range typed {
+			binary.LittleEndian.PutUint16(result[index*2:], uint16(entry))
+		}
+		return result, true
+	case []uint32:
+		result := make([]byte, len(typed)*4)
+		for index, entry := range typed {
+			binary.LittleEndian.PutUint32(result[index*4:], entry)
+		}
+		return result, true
+	case []int32:
+		result := make([]byte, len(typed)*4)
+		for index, entry := range typed {
+			binary.LittleEndian.PutUint32(result[index*4:], uint32(entry))
+		}
+		return result, true
+	}
+	return nil, false
+}
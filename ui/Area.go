@@ -191,6 +191,7 @@ func (area *Area) hasAreaFocus(child *Area) bool {
 
 // RequestFocus sets this area (and all of its parents) first in receiving events.
 // Any previously focused area not in the parent list of this area will lose its focus.
+// Areas losing their focus receive an event of type events.FocusLostEventType.
 func (area *Area) RequestFocus() {
 	area.loseFocus()
 	if area.parent != nil {
@@ -199,11 +200,13 @@ func (area *Area) RequestFocus() {
 }
 
 func (area *Area) requestFocusFor(child *Area) {
-	area.RequestFocus()
-	if (area.focusedArea != child) && (area.focusedArea != nil) {
-		area.focusedArea.loseFocus()
+	if area.parent != nil {
+		area.parent.requestFocusFor(area)
+	}
+	if area.focusedArea != child {
+		area.loseFocus()
+		area.focusedArea = child
 	}
-	area.focusedArea = child
 }
 
 // ReleaseFocus lets this area (and any of its children) lose focus.
@@ -216,7 +219,9 @@ func (area *Area) ReleaseFocus() {
 
 func (area *Area) loseFocus() {
 	if area.focusedArea != nil {
-		area.focusedArea.loseFocus()
+		child := area.focusedArea
+		child.loseFocus()
 		area.focusedArea = nil
+		child.tryEventHandlerFor(events.NewFocusEvent(events.FocusLostEventType))
 	}
 }
@@ -314,6 +314,46 @@ func (suite *AreaSuite) TestRequestFocusClearsFocusForPreviouslyFocusedDescendan
 	c.Check(leftChildArea.HasFocus(), check.Equals, false)
 }
 
+func (suite *AreaSuite) TestFocusLostIsReportedToAreaLosingFocus(c *check.C) {
+	area := suite.builder.Build()
+	lost := make(map[string]int)
+	childBuilder := func(id string) *Area {
+		builder := NewAreaBuilder()
+		builder.SetParent(area)
+		builder.OnEvent(events.FocusLostEventType, func(*Area, events.Event) bool {
+			lost[id]++
+			return true
+		})
+		return builder.Build()
+	}
+	leftArea := childBuilder("left")
+	rightArea := childBuilder("right")
+
+	leftArea.RequestFocus()
+	rightArea.RequestFocus()
+	rightArea.ReleaseFocus()
+
+	c.Check(lost, check.DeepEquals, map[string]int{"left": 1, "right": 1})
+}
+
+func (suite *AreaSuite) TestFocusLostIsNotReportedWhenFocusIsRequestedAgain(c *check.C) {
+	area := suite.builder.Build()
+	lost := 0
+	builder := NewAreaBuilder()
+	builder.SetParent(area)
+	builder.OnEvent(events.FocusLostEventType, func(*Area, events.Event) bool {
+		lost++
+		return true
+	})
+	subArea := builder.Build()
+
+	subArea.RequestFocus()
+	subArea.RequestFocus()
+
+	c.Check(lost, check.Equals, 0)
+	c.Check(subArea.HasFocus(), check.Equals, true)
+}
+
 func (suite *AreaSuite) TestHandleEventForwardsEventToAreaWithFocusBeforeHandlingItself(c *check.C) {
 	event1 := &testingEvent{events.EventType("TestingEvent")}
 	var subArea *Area
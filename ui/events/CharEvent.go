@@ -0,0 +1,31 @@
+package events
+
+// CharEventType is the name for events where a printable character was typed.
+const CharEventType = EventType("key.char")
+
+// CharEvent is used to inform about typed characters. Like key events, they
+// are handled by the area that currently has the focus.
+type CharEvent struct {
+	eventType EventType
+
+	char rune
+}
+
+// NewCharEvent returns a new instance of a character event.
+func NewCharEvent(char rune) *CharEvent {
+	event := &CharEvent{
+		eventType: CharEventType,
+		char:      char}
+
+	return event
+}
+
+// EventType implements the Event interface.
+func (event *CharEvent) EventType() EventType {
+	return event.eventType
+}
+
+// Char returns the typed character.
+func (event *CharEvent) Char() rune {
+	return event.char
+}
@@ -0,0 +1,21 @@
+package events
+
+// FocusLostEventType is the name for events where an area lost its focus.
+const FocusLostEventType = EventType("ui.focus.lost")
+
+// FocusEvent is used to inform an area about a change of its focus.
+type FocusEvent struct {
+	eventType EventType
+}
+
+// NewFocusEvent returns a new instance of a focus event.
+func NewFocusEvent(eventType EventType) *FocusEvent {
+	event := &FocusEvent{eventType: eventType}
+
+	return event
+}
+
+// EventType implements the Event interface.
+func (event *FocusEvent) EventType() EventType {
+	return event.eventType
+}
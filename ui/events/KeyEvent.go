@@ -0,0 +1,38 @@
+package events
+
+// KeyEventType is the name for events where a named key was pressed.
+const KeyEventType = EventType("key.press")
+
+// KeyEvent is used to inform about pressed keys. Keys are not positional;
+// They are handled by the area that currently has the focus.
+type KeyEvent struct {
+	eventType EventType
+
+	key      int
+	modifier uint32
+}
+
+// NewKeyEvent returns a new instance of a key event.
+func NewKeyEvent(key int, modifier uint32) *KeyEvent {
+	event := &KeyEvent{
+		eventType: KeyEventType,
+		key:       key,
+		modifier:  modifier}
+
+	return event
+}
+
+// EventType implements the Event interface.
+func (event *KeyEvent) EventType() EventType {
+	return event.eventType
+}
+
+// Key returns the identifier of the pressed key.
+func (event *KeyEvent) Key() int {
+	return event.key
+}
+
+// Modifier returns a bitmask of currently active keyboard modifier.
+func (event *KeyEvent) Modifier() uint32 {
+	return event.modifier
+}